@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -74,7 +75,7 @@ func main() {
 	// Create ConfigInjectionService for injecting Claude configs into containers
 	// Note: We need to create a docker client for the injection service
 	// The ContainerService will create its own docker client internally
-	configInjectionService := services.NewConfigInjectionServiceWithNewClient(configTemplateService)
+	configInjectionService := services.NewConfigInjectionServiceWithNewClient(configTemplateService, cfg.InjectionExecConcurrency, cfg.InjectionToolCheck)
 
 	containerService, err := services.NewContainerService(db, cfg, claudeConfigService, githubService, configProfileService, configInjectionService)
 	if err != nil {
@@ -93,6 +94,14 @@ func main() {
 		log.Fatalf("Failed to initialize terminal service: %v", err)
 	}
 	defer terminalService.Close()
+	if err := terminalService.SetWebSocketSettings(terminal.WebSocketSettings{
+		WriteWait:      cfg.WSWriteWait,
+		PongWait:       cfg.WSPongWait,
+		PingPeriod:     cfg.WSPingPeriod,
+		MaxMessageSize: int64(cfg.WSTerminalMaxMessageSize),
+	}); err != nil {
+		log.Printf("Warning: ignoring terminal WebSocket settings: %v", err)
+	}
 
 	// Initialize monitoring service
 	monitoringService := services.NewMonitoringService(db, terminalService)
@@ -103,6 +112,11 @@ func main() {
 	if err != nil {
 		log.Printf("Warning: Failed to initialize Docker event listener: %v", err)
 	} else {
+		dockerEventListener.SetPauseHandler(func(dockerID string, paused bool) {
+			if err := containerService.ApplyDockerPauseEvent(dockerID, paused); err != nil && !errors.Is(err, services.ErrContainerNotFound) {
+				log.Printf("Warning: Failed to record pause state for %s: %v", dockerID, err)
+			}
+		})
 		if err := dockerEventListener.Start(); err != nil {
 			log.Printf("Warning: Failed to start Docker event listener: %v", err)
 		} else {
@@ -115,10 +129,40 @@ func main() {
 
 	// Initialize Headless manager
 	headlessManager := headless.NewHeadlessManager(db, monitoringService.GetManager())
+	headlessManager.SetWatchdogInterval(cfg.HeadlessWatchdogInterval)
+	headlessManager.SetStallTimeout(cfg.HeadlessStallTimeout)
+	headlessManager.SetDisconnectCancel(cfg.HeadlessCancelOnDisconnect, cfg.HeadlessDisconnectGrace)
+	headlessManager.SetPricingTable(headless.NewPricingTable(cfg.ModelPricing))
+	headlessManager.SetEventStore(cfg.HeadlessMaxTurnEvents, cfg.HeadlessEventRetention)
+	if err := headlessManager.SetPlatformOverrides(headless.PlatformOverrides{
+		Model:              cfg.HeadlessForceModel,
+		MaxTurns:           cfg.HeadlessMaxTurnsCap,
+		MaxDurationMinutes: cfg.HeadlessMaxDurationCap,
+	}); err != nil {
+		log.Printf("Warning: ignoring headless platform overrides: %v", err)
+	}
 	defer headlessManager.Close()
 
 	// Initialize Mode manager
 	modeManager := mode.NewModeManager(terminalService, headlessManager, monitoringService.GetManager())
+	modeManager.SetProcessKiller(containerService.KillClaudeProcesses)
+	// Record mode switches in the container log so they appear in the activity timeline
+	modeManager.SetOnModeSwitch(func(containerID uint, m mode.ContainerMode, closedSessions int) {
+		containerService.RecordModeSwitch(context.Background(), containerID, string(m), closedSessions)
+	})
+
+	// Delete containers whose TTL has passed; active sessions may extend or block it per config
+	containerService.SetActiveSessionChecker(func(containerID uint, dockerID string) bool {
+		inventory := modeManager.Inventory(containerID, dockerID)
+		return inventory.TerminalSessionCount > 0 || inventory.RunningConversationID != 0
+	})
+	containerService.StartTTLReaper(cfg.ContainerTTLCheckInterval)
+
+	// Switching a container's env profile closes its headless sessions before restarting Claude
+	containerService.SetHeadlessSessionCloser(headlessManager.CloseSessionsForContainer)
+
+	// Activate proxies of containers created while Traefik was unavailable once it recovers
+	containerService.StartProxyReconciler(cfg.TraefikReconcileInterval)
 
 	// Log startup info (without sensitive credentials)
 	log.Printf("Admin user: %s (password configured via .env)", cfg.AdminUsername)
@@ -128,42 +172,83 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	
-	router := gin.Default()
+	router := gin.New()
+
+	// Correlation ID for every request (X-Request-ID), included in access and service logs
+	router.Use(middleware.RequestID(), gin.LoggerWithFormatter(middleware.AccessLogFormatter), gin.Recovery())
 
 	// CORS middleware
-	router.Use(middleware.CORS())
+	router.Use(middleware.CORS(middleware.NewCORSPolicy(cfg)))
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	settingsHandler := handlers.NewSettingsHandler(githubService, claudeConfigService)
 	configProfileHandler := handlers.NewConfigProfileHandler(configProfileService)
 	configTemplateHandler := handlers.NewConfigTemplateHandler(configTemplateService)
+	configTemplateHandler.SetUsageService(services.NewTemplateUsageService(db))
+	templateBundleService := services.NewTemplateBundleService(db, configTemplateService)
+	templateBundleHandler := handlers.NewTemplateBundleHandler(templateBundleService, containerService)
+	databaseMaintenanceService := services.NewDatabaseMaintenanceService(db)
+	databaseMaintenanceService.StartScheduledVacuum(cfg.DBVacuumInterval)
+	defer databaseMaintenanceService.Close()
+	databaseHandler := handlers.NewDatabaseHandler(databaseMaintenanceService)
+	auditService := services.NewAuditService(db)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	backupHandler := handlers.NewBackupHandler(services.NewBackupService(db, configTemplateService, templateBundleService, configProfileService, containerService))
 	repoHandler := handlers.NewRepositoryHandler(githubService, configProfileService)
 	containerHandler := handlers.NewContainerHandler(containerService, terminalService, configProfileService)
 	fileHandler := handlers.NewFileHandler(fileService)
 	terminalHandler := handlers.NewTerminalHandler(terminalService, containerService, authService)
 	portHandler := handlers.NewPortHandler(portService)
 	proxyHandler := handlers.NewProxyHandler(containerService, db)
-	automationLogsHandler := handlers.NewAutomationLogsHandler(db)
+	automationLogsHandler := handlers.NewAutomationLogsHandler(db, monitoringService.GetStrategyEngine().LogBroadcaster())
 	monitoringHandler := handlers.NewMonitoringHandler(monitoringService)
 	taskQueueHandler := handlers.NewTaskQueueHandler(services.NewTaskQueueService(db))
+	promptTemplateService := services.NewPromptTemplateService(db)
+	promptTemplateHandler := handlers.NewPromptTemplateHandler(promptTemplateService)
+	containerProfileHandler := handlers.NewContainerProfileHandler(services.NewContainerProfileService(db))
 	headlessHandler := handlers.NewHeadlessHandler(headlessManager, modeManager, containerService, authService)
+	headlessHandler.SetPromptTemplateService(promptTemplateService)
+	if err := headlessHandler.SetWebSocketSettings(terminal.WebSocketSettings{
+		WriteWait:      cfg.WSWriteWait,
+		PongWait:       cfg.WSPongWait,
+		PingPeriod:     cfg.WSPingPeriod,
+		MaxMessageSize: int64(cfg.WSHeadlessMaxMessageSize),
+	}); err != nil {
+		log.Printf("Warning: ignoring headless WebSocket settings: %v", err)
+	}
+	containerStatsHandler := handlers.NewContainerStatsHandler(containerService, authService)
+	containerCommandService := services.NewContainerCommandService(db, containerService)
+	containerExecHandler := handlers.NewContainerExecHandler(containerService, containerCommandService)
 
-	// Health check endpoint (for Docker healthcheck / load balancers)
+	// Health check endpoint (for Docker healthcheck / load balancers).
+	// The API keeps serving without Docker, so an unreachable daemon is reported as degraded, not as a failure.
 	router.GET("/api/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		dockerStatus := containerService.DockerStatus(c.Request.Context())
+		status := "ok"
+		if !dockerStatus.Available {
+			status = "degraded"
+		}
+		c.JSON(http.StatusOK, gin.H{"status": status, "docker": dockerStatus})
 	})
 
 	// Public routes (with rate limiting for login)
 	router.POST("/api/auth/login", middleware.LoginRateLimit(), authHandler.Login)
 	router.POST("/api/auth/logout", authHandler.Logout)
 
+	// Request body limits per endpoint class; oversized bodies get 413 before they are read
+	uploadBodyLimit := middleware.MaxBodySize(int64(cfg.MaxUploadBodyMB) << 20)
+	archiveBodyLimit := middleware.MaxBodySize(int64(cfg.MaxArchiveBodyMB) << 20)
+
 	// Protected routes
 	protected := router.Group("/api")
 	protected.Use(middleware.JWTAuth(authService))
+	// Privileged actions are recorded in the audit log once handled
+	protected.Use(middleware.Audit(auditService))
 	{
 		// Auth routes
 		protected.GET("/auth/verify", authHandler.Verify)
+		protected.POST("/auth/refresh", authHandler.Refresh)
 		
 		// Settings routes (legacy)
 		protected.GET("/settings/github", settingsHandler.GetGitHubConfig)
@@ -177,6 +262,18 @@ func main() {
 		// Claude config template routes
 		configTemplateHandler.RegisterRoutes(protected)
 
+		// Template bundle routes (includes /containers/:id/apply-bundle/:bundleId)
+		templateBundleHandler.RegisterRoutes(protected)
+
+		// Backup export/import routes
+		backupHandler.RegisterRoutes(protected, archiveBodyLimit)
+
+		// Database maintenance
+		databaseHandler.RegisterRoutes(protected)
+
+		// Audit log of privileged actions
+		auditHandler.RegisterRoutes(protected)
+
 		// Repository routes
 		protected.GET("/repos/remote", repoHandler.ListRemoteRepositories)
 		protected.POST("/repos/clone", repoHandler.CloneRepository)
@@ -185,19 +282,60 @@ func main() {
 
 		// Container routes
 		protected.GET("/containers", containerHandler.ListContainers)
+		protected.GET("/containers/init-timings", containerHandler.GetInitTimingStats)
+		protected.GET("/resource-profiles", containerHandler.ListResourceProfiles)
 		protected.POST("/containers", containerHandler.CreateContainer)
+		protected.POST("/containers/validate", containerHandler.ValidateContainer)
 		protected.GET("/containers/:id", containerHandler.GetContainer)
+		protected.PATCH("/containers/:id", containerHandler.UpdateContainerDetails)
 		protected.GET("/containers/:id/status", containerHandler.GetContainerStatus)
 		protected.GET("/containers/:id/logs", containerHandler.GetContainerLogs)
+		protected.GET("/containers/:id/docker-logs", containerHandler.StreamDockerLogs)
+		protected.GET("/containers/:id/init-console", containerHandler.StreamInitConsole)
+		protected.GET("/containers/:id/timings", containerHandler.GetContainerTimings)
+		protected.GET("/containers/:id/timeline", containerHandler.GetContainerTimeline)
+		protected.GET("/containers/:id/inspect", containerHandler.InspectContainer)
+		protected.GET("/containers/:id/env", containerHandler.GetContainerEnv)
 		protected.GET("/containers/:id/api-config", containerHandler.GetContainerApiConfig)
+		protected.GET("/containers/:id/claude-config", containerHandler.GetContainerClaudeConfig)
+		protected.POST("/containers/:id/mcp/:name/test", containerHandler.TestMCPServer)
+		protected.POST("/containers/:id/copy-configs-from/:sourceId", containerHandler.CopyConfigsFrom)
+		protected.POST("/containers/:id/config-profile/:profileId", containerHandler.SwitchConfigProfile)
+		protected.GET("/containers/:id/claude-snapshot", containerHandler.GetClaudeSnapshot)
+		protected.GET("/containers/:id/claude-version", containerHandler.GetClaudeVersion)
+		protected.POST("/containers/:id/claude-snapshot", archiveBodyLimit, containerHandler.RestoreClaudeSnapshot)
+		protected.POST("/containers/:id/workspace/restore", containerHandler.RestoreWorkspace)
+		protected.GET("/workspace-archives", containerHandler.ListWorkspaceArchives)
+		protected.GET("/workspace-archives/:id/download", containerHandler.DownloadWorkspaceArchive)
+		protected.DELETE("/workspace-archives/:id", containerHandler.DeleteWorkspaceArchive)
 		protected.GET("/containers/:id/models", containerHandler.GetContainerModels)
 		protected.POST("/containers/:id/start", containerHandler.StartContainer)
 		protected.POST("/containers/:id/stop", containerHandler.StopContainer)
+		protected.POST("/containers/:id/pause", containerHandler.PauseContainer)
+		protected.POST("/containers/:id/resume", containerHandler.ResumeContainer)
+		protected.POST("/containers/:id/recreate", containerHandler.RecreateContainer)
+		protected.GET("/containers/:id/code-server", containerHandler.GetCodeServerAccess)
+		protected.POST("/containers/:id/code-server/rotate-password", containerHandler.RotateCodeServerPassword)
+		protected.PUT("/containers/:id/resources", containerHandler.UpdateContainerResources)
 		protected.POST("/containers/:id/inject-configs", containerHandler.InjectConfigs)
+		protected.GET("/containers/:id/configs/:templateId/diff", containerHandler.DiffTemplate)
+		protected.POST("/claude-configs/:id/propagate", containerHandler.PropagateTemplate)
+		protected.POST("/containers/:id/clone-repo", containerHandler.CloneRepo)
+		protected.POST("/containers/:id/cancel-init", containerHandler.CancelInit)
+		protected.GET("/containers/:id/processes", containerHandler.ListProcesses)
+		protected.POST("/containers/:id/processes/:pid/kill", containerHandler.KillProcess)
+		protected.POST("/containers/:id/exec", containerExecHandler.Exec)
+		protected.GET("/containers/:id/commands", containerExecHandler.ListCommands)
+		protected.POST("/containers/:id/commands", containerExecHandler.CreateCommand)
+		protected.GET("/containers/:id/commands/:name", containerExecHandler.GetCommand)
+		protected.PUT("/containers/:id/commands/:name", containerExecHandler.UpdateCommand)
+		protected.DELETE("/containers/:id/commands/:name", containerExecHandler.DeleteCommand)
+		protected.POST("/containers/:id/commands/:name/run", containerExecHandler.RunCommand)
 		protected.DELETE("/containers/:id", containerHandler.DeleteContainer)
 
 		// Docker container management (all containers including orphaned)
 		protected.GET("/docker/containers", containerHandler.ListDockerContainers)
+		protected.POST("/docker/containers/prune", containerHandler.PruneDockerContainers)
 		protected.POST("/docker/containers/:dockerId/stop", containerHandler.StopDockerContainer)
 		protected.DELETE("/docker/containers/:dockerId", containerHandler.RemoveDockerContainer)
 
@@ -210,7 +348,7 @@ func main() {
 		// File routes
 		protected.GET("/files/:id/list", fileHandler.ListDirectory)
 		protected.GET("/files/:id/download", fileHandler.DownloadFile)
-		protected.POST("/files/:id/upload", fileHandler.UploadFile)
+		protected.POST("/files/:id/upload", uploadBodyLimit, fileHandler.UploadFile)
 		protected.DELETE("/files/:id", fileHandler.DeleteFile)
 		protected.POST("/files/:id/mkdir", fileHandler.CreateDirectory)
 
@@ -221,6 +359,7 @@ func main() {
 		protected.GET("/logs/automation", automationLogsHandler.ListLogs)
 		protected.GET("/logs/automation/stats", automationLogsHandler.GetLogStats)
 		protected.GET("/logs/automation/export", automationLogsHandler.ExportLogs)
+		protected.GET("/logs/automation/stream", automationLogsHandler.StreamLogs)
 		protected.DELETE("/logs/automation/cleanup", automationLogsHandler.DeleteOldLogs)
 		protected.GET("/logs/automation/:id", automationLogsHandler.GetLog)
 		protected.GET("/logs/automation/container/:containerId", automationLogsHandler.GetLogsByContainer)
@@ -231,17 +370,35 @@ func main() {
 		// Task queue routes
 		taskQueueHandler.RegisterRoutes(protected)
 
+		// Headless prompt template library
+		promptTemplateHandler.RegisterRoutes(protected)
+
+		// Container profiles (named creation defaults)
+		containerProfileHandler.RegisterRoutes(protected)
+
 		// Headless conversation routes
+		protected.GET("/headless/pricing", headlessHandler.GetModelPricing)
+		protected.GET("/headless/sessions", headlessHandler.ListActiveSessions)
+		protected.GET("/containers/:id/mode", headlessHandler.GetContainerMode)
+		protected.POST("/containers/:id/mode/reset", headlessHandler.ResetContainerMode)
 		protected.GET("/containers/:id/headless/conversations", headlessHandler.ListConversations)
+		protected.GET("/containers/:id/headless/conversations/tags", headlessHandler.ListConversationTags)
+		protected.GET("/containers/:id/headless/conversations/latest", headlessHandler.GetLatestConversation)
 		protected.GET("/containers/:id/headless/conversations/:conversationId", headlessHandler.GetConversation)
 		protected.DELETE("/containers/:id/headless/conversations/:conversationId", headlessHandler.DeleteConversation)
+		protected.PUT("/containers/:id/headless/conversations/:conversationId/system-prompt", headlessHandler.UpdateConversationSystemPrompt)
+		protected.PUT("/containers/:id/headless/conversations/:conversationId/tags", headlessHandler.UpdateConversationTags)
+		protected.GET("/containers/:id/headless/conversations/:conversationId/changes", headlessHandler.GetConversationChanges)
 		protected.GET("/containers/:id/headless/conversations/:conversationId/turns", headlessHandler.GetConversationTurns)
+		protected.GET("/containers/:id/headless/conversations/:conversationId/turns/:turnId/events", headlessHandler.GetTurnEvents)
 	}
 
-	// WebSocket routes (with JWT query param auth)
-	router.GET("/api/ws/terminal/:id", terminalHandler.HandleWebSocket)
+	// WebSocket routes (JWT via the cc-auth subprotocol, cookie or query param)
+	// Terminal sessions authenticate in the handler, so the audit middleware is added here
+	router.GET("/api/ws/terminal/:id", middleware.Audit(auditService), terminalHandler.HandleWebSocket)
 	router.GET("/api/ws/headless/:containerId", headlessHandler.HandleHeadlessWebSocket)
 	router.GET("/api/ws/headless/conversation/:conversationId", headlessHandler.HandleConversationWebSocket)
+	router.GET("/api/ws/containers/:id/stats", containerStatsHandler.HandleStatsWebSocket)
 
 	// Proxy routes (with flexible auth - supports header, cookie, or query param)
 	proxyGroup := router.Group("/api/proxy")
@@ -10,6 +10,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"cc-platform/internal/constants"
 )
 
 // Config holds all configuration for the application
@@ -18,6 +20,10 @@ type Config struct {
 	Port          int    // Server port
 	DatabasePath  string
 	JWTSecret     string
+	AccessTokenTTL    time.Duration // Lifetime of issued JWTs
+	SlidingExpiration bool          // Re-issue a token on activity once half its lifetime has passed
+	LoginMaxFailures     int           // Failed logins per username+IP before the pair is locked
+	LoginLockoutDuration time.Duration // How long a locked username+IP pair must wait
 	EncryptionKey string
 	AdminUsername string
 	AdminPassword string
@@ -29,9 +35,95 @@ type Config struct {
 	TraefikDashboardPort  int  // 0 = auto-assign
 	TraefikPortRangeStart int
 	TraefikPortRangeEnd   int
+	TraefikReconcileInterval time.Duration // How often pending proxies are rechecked against Traefik
 	
 	// Code-server subdomain settings
 	CodeServerBaseDomain string // e.g., "code.example.com" - containers will be {name}.{base-domain}
+	// Host ports code-server is mapped to without subdomain routing (inclusive)
+	CodeServerPortRangeStart int
+	CodeServerPortRangeEnd   int
+
+	// WebhookSecret signs outgoing container init callbacks (HMAC-SHA256); callbacks are refused when empty
+	WebhookSecret string
+	// Callback hosts that may be private, loopback or link-local addresses
+	CallbackAllowedHosts []string
+
+	// CORS settings
+	AllowedOrigins      []string // Browser origins allowed to call the API with credentials
+	CORSAllowAllOrigins bool     // Allow every origin (local development only)
+
+	// Host directory mounts (opt-in)
+	HostMountsEnabled  bool     // Allow containers to mount host directories
+	HostMountAllowlist []string // Host directories (and their subdirectories) that may be mounted
+
+	// Registries containers may use custom images from; custom images are refused when empty
+	RegistryAllowlist []string
+
+	// Git hosts repositories may be cloned from; github.com, gitlab.com and bitbucket.org when empty
+	GitRepoHosts []string
+
+	// Periodic SQLite VACUUM (0 = only on request)
+	DBVacuumInterval time.Duration
+
+	// Container resource profiles ("name=cpu:memoryMB"); built-in profiles are used when empty
+	ResourceProfiles       []string
+	DefaultResourceProfile string // Profile applied when a container names none
+
+	// Headless session watchdog
+	HeadlessWatchdogInterval time.Duration // How often running sessions are checked for a dead Claude process
+	HeadlessStallTimeout     time.Duration // Treat a running session with no output for this long as dead (0 = off)
+	// Cancel a running turn once every client has disconnected and none reconnects within the grace period;
+	// conversations can override the default with cancel_on_disconnect
+	HeadlessCancelOnDisconnect bool
+	HeadlessDisconnectGrace    time.Duration
+
+	// Model prices ("model=input:output" in USD per million tokens) used when a turn reports no cost
+	ModelPricing []string
+
+	// Platform-wide headless overrides applied after the client request
+	HeadlessForceModel     string // Model every headless session uses regardless of the client (empty = client choice)
+	HeadlessMaxTurnsCap    int    // Highest turn limit a conversation may run with (0 = none)
+	HeadlessMaxDurationCap int    // Highest duration limit in minutes a conversation may run with (0 = none)
+
+	// Storage of the raw stream events of headless turns
+	HeadlessMaxTurnEvents  int           // Most events recorded per turn (0 = no limit)
+	HeadlessEventRetention time.Duration // Delete events of turns that finished longer ago than this (0 = keep)
+
+	// Keepalive of terminal and headless WebSocket connections
+	WSWriteWait              time.Duration // Time allowed to write a message
+	WSPongWait               time.Duration // Drop connections that send no pong or message for this long
+	WSPingPeriod             time.Duration // How often pings are sent; must be less than WSPongWait
+	WSTerminalMaxMessageSize int           // Largest terminal message accepted from clients, in bytes
+	WSHeadlessMaxMessageSize int           // Largest headless message accepted from clients, in bytes
+
+	// Server-side bare clone cache that containers clone repositories from
+	RepoCacheDir             string        // Cache directory, also mounted into containers (empty = disabled)
+	RepoCacheRefreshInterval time.Duration // How often cached repositories are fetched from their remotes
+
+	// Container time-to-live reaper
+	ContainerTTLCheckInterval  time.Duration // How often containers are checked for an expired TTL
+	ContainerTTLWarning        time.Duration // Send the expiry warning callback this long before deletion (0 = none)
+	ContainerTTLActiveSessions string        // ignore, extend or block deletion while sessions are active
+
+	// What container creation does when a selected config template is missing: reject or warn
+	TemplatePrecheck string
+
+	// How many config injection exec calls may run at once across all containers (0 = no limit)
+	InjectionExecConcurrency int
+
+	// How injection checks skill allowed_tools and stdio MCP commands: off, warn or strict
+	InjectionToolCheck string
+
+	// How long a container creation Idempotency-Key is remembered
+	IdempotencyKeyTTL time.Duration
+
+	// Warm pool of pre-created idle containers (0 = disabled)
+	WarmPoolSize           int
+	WarmPoolRefillInterval time.Duration
+
+	// Request body limits in MB per endpoint class (0 = no limit)
+	MaxUploadBodyMB  int // File uploads
+	MaxArchiveBodyMB int // Uploaded archives such as ~/.claude snapshots and backup imports
 }
 
 // Load loads configuration from environment variables
@@ -47,6 +139,10 @@ func Load() *Config {
 		Port:          getEnvInt("PORT", 8080),
 		DatabasePath:  getEnv("DATABASE_PATH", "./data/cc-platform.db"),
 		JWTSecret:     getEnv("JWT_SECRET", ""),
+		AccessTokenTTL:    getEnvDuration("ACCESS_TOKEN_TTL", 24*time.Hour),
+		SlidingExpiration: getEnvBool("JWT_SLIDING_EXPIRATION", false),
+		LoginMaxFailures:     getEnvInt("LOGIN_MAX_FAILURES", 5),
+		LoginLockoutDuration: getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
 		EncryptionKey: getEnv("ENCRYPTION_KEY", ""),
 		AdminUsername: getEnv("ADMIN_USERNAME", ""),
 		AdminPassword: getEnv("ADMIN_PASSWORD", ""),
@@ -58,9 +154,90 @@ func Load() *Config {
 		TraefikDashboardPort:  getEnvInt("TRAEFIK_DASHBOARD_PORT", 0),
 		TraefikPortRangeStart: getEnvInt("TRAEFIK_PORT_RANGE_START", 30001),
 		TraefikPortRangeEnd:   getEnvInt("TRAEFIK_PORT_RANGE_END", 30020),
+		TraefikReconcileInterval: getEnvDuration("TRAEFIK_RECONCILE_INTERVAL", 30*time.Second),
 		
 		// Code-server subdomain (e.g., "code.example.com" -> {container}.code.example.com)
 		CodeServerBaseDomain:  getEnv("CODE_SERVER_BASE_DOMAIN", ""),
+		CodeServerPortRangeStart: getEnvInt("CODE_SERVER_PORT_RANGE_START", 18443),
+		CodeServerPortRangeEnd:   getEnvInt("CODE_SERVER_PORT_RANGE_END", 18543),
+
+		// Secret shared with init callback receivers to verify signatures
+		WebhookSecret: getEnv("WEBHOOK_SECRET", ""),
+		// Callbacks to internal addresses are refused unless their host is listed
+		CallbackAllowedHosts: getEnvList("CALLBACK_ALLOWED_HOSTS"),
+
+		// CORS allowlist; allowing every origin needs an explicit flag
+		AllowedOrigins:      getEnvList("ALLOWED_ORIGINS"),
+		CORSAllowAllOrigins: getEnvBool("CORS_ALLOW_ALL_ORIGINS", false),
+
+		// Host mounts are refused unless explicitly enabled
+		HostMountsEnabled:  getEnvBool("HOST_MOUNTS_ENABLED", false),
+		HostMountAllowlist: getEnvList("HOST_MOUNT_ALLOWLIST"),
+
+		// Custom container images are refused unless their registry is allowlisted
+		RegistryAllowlist: getEnvList("REGISTRY_ALLOWLIST"),
+
+		// Repository URLs must point at one of these git hosts
+		GitRepoHosts: getEnvList("GIT_REPO_HOSTS"),
+
+		// Reclaim space freed by deleted rows; off unless set
+		DBVacuumInterval: getEnvDuration("DB_VACUUM_INTERVAL", 0),
+
+		// Named CPU/memory presets for new containers
+		ResourceProfiles:       getEnvList("RESOURCE_PROFILES"),
+		DefaultResourceProfile: getEnv("DEFAULT_RESOURCE_PROFILE", ""),
+
+		// Reaping of headless sessions whose Claude process died
+		HeadlessWatchdogInterval: getEnvDuration("HEADLESS_WATCHDOG_INTERVAL", 30*time.Second),
+		HeadlessStallTimeout:     getEnvDuration("HEADLESS_STALL_TIMEOUT", 0),
+		ModelPricing:             getEnvList("MODEL_PRICING"),
+
+		// Turns keep running after every client disconnects unless cancelling is enabled
+		HeadlessCancelOnDisconnect: getEnvBool("HEADLESS_CANCEL_ON_DISCONNECT", false),
+		HeadlessDisconnectGrace:    getEnvDuration("HEADLESS_DISCONNECT_GRACE", 30*time.Second),
+
+		// Forced model and conversation limit caps for all headless sessions
+		HeadlessForceModel:     getEnv("HEADLESS_FORCE_MODEL", ""),
+		HeadlessMaxTurnsCap:    getEnvInt("HEADLESS_MAX_TURNS_CAP", 0),
+		HeadlessMaxDurationCap: getEnvInt("HEADLESS_MAX_DURATION_CAP", 0),
+
+		// Raw turn events are kept for replay until they expire
+		HeadlessMaxTurnEvents:  getEnvInt("HEADLESS_MAX_TURN_EVENTS", 5000),
+		HeadlessEventRetention: getEnvDuration("HEADLESS_EVENT_RETENTION", 30*24*time.Hour),
+
+		// Shorter timings keep connections alive behind proxies with aggressive idle timeouts
+		WSWriteWait:              getEnvDuration("WS_WRITE_WAIT", 10*time.Second),
+		WSPongWait:               getEnvDuration("WS_PONG_WAIT", 60*time.Second),
+		WSPingPeriod:             getEnvDuration("WS_PING_PERIOD", 54*time.Second),
+		WSTerminalMaxMessageSize: getEnvInt("WS_TERMINAL_MAX_MESSAGE_SIZE", 8192),
+		WSHeadlessMaxMessageSize: getEnvInt("WS_HEADLESS_MAX_MESSAGE_SIZE", 16*1024),
+
+		// Repository clone cache is off unless a directory is given
+		RepoCacheDir:             getEnv("REPO_CACHE_DIR", ""),
+		RepoCacheRefreshInterval: getEnvDuration("REPO_CACHE_REFRESH_INTERVAL", time.Hour),
+
+		// Deletion of containers created with a TTL
+		ContainerTTLCheckInterval:  getEnvDuration("CONTAINER_TTL_CHECK_INTERVAL", time.Minute),
+		ContainerTTLWarning:        getEnvDuration("CONTAINER_TTL_WARNING", 10*time.Minute),
+		ContainerTTLActiveSessions: strings.ToLower(getEnv("CONTAINER_TTL_ACTIVE_SESSIONS", "ignore")),
+
+		// Selected templates are resolved before a container is created
+		TemplatePrecheck: strings.ToLower(getEnv("TEMPLATE_PRECHECK", "reject")),
+
+		// Bounds Docker exec load from large injections and propagations
+		InjectionExecConcurrency: getEnvInt("INJECTION_EXEC_CONCURRENCY", 4),
+		InjectionToolCheck:       getEnv("INJECTION_TOOL_CHECK", "warn"),
+
+		// Retried container creations with the same Idempotency-Key return the first container
+		IdempotencyKeyTTL: getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+
+		// Default container creations claim an idle pool container instead of starting cold
+		WarmPoolSize:           getEnvInt("WARM_POOL_SIZE", 0),
+		WarmPoolRefillInterval: getEnvDuration("WARM_POOL_REFILL_INTERVAL", 30*time.Second),
+
+		// Oversized bodies are rejected with 413 before they are read into memory
+		MaxUploadBodyMB:  getEnvInt("MAX_UPLOAD_BODY_MB", 512),
+		MaxArchiveBodyMB: getEnvInt("MAX_ARCHIVE_BODY_MB", constants.ClaudeSnapshotMaxBodyBytes>>20),
 	}
 
 	// Generate JWT secret if not provided
@@ -173,6 +350,17 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvList returns a comma-separated variable as a list, skipping empty items
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if i, err := strconv.Atoi(value); err == nil {
@@ -182,6 +370,17 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvDuration parses a Go duration (e.g. "12h", "90m"); invalid or non-positive values use the default
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("Warning: invalid %s %q, using %s", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
 func generateRandomString(length int) string {
 	bytes := make([]byte, length)
 	if _, err := rand.Read(bytes); err != nil {
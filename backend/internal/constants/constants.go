@@ -30,6 +30,22 @@ const (
 	CodeServerStartTimeout = 30 * time.Second
 	// CodeServerStartDelay is the delay before starting code-server after container start
 	CodeServerStartDelay = 2 * time.Second
+	// ExecDefaultTimeout is the timeout for API exec requests that don't specify one
+	ExecDefaultTimeout = 60 * time.Second
+	// ExecMaxTimeout is the longest timeout an API exec request may ask for
+	ExecMaxTimeout = 30 * time.Minute
+	// InitCallbackTimeout is the timeout for a single init callback delivery attempt
+	InitCallbackTimeout = 10 * time.Second
+	// InitCallbackRetryDelay is the delay before the first callback retry; it doubles per attempt
+	InitCallbackRetryDelay = 2 * time.Second
+	// InitCallbackMaxAttempts is how many times an init callback is delivered before giving up
+	InitCallbackMaxAttempts = 4
+	// ContainerTTLMaxMinutes is the longest time-to-live a container may be created with (30 days)
+	ContainerTTLMaxMinutes = 30 * 24 * 60
+	// ContainerTTLExtension is how far an expiry is pushed back when active sessions extend it
+	ContainerTTLExtension = 15 * time.Minute
+	// IdempotencyKeyMaxLength is the longest Idempotency-Key accepted on container creation
+	IdempotencyKeyMaxLength = 255
 )
 
 // ===========================================
@@ -43,6 +59,12 @@ const (
 	WebSocketPongTimeout = 60 * time.Second
 	// WebSocketWriteTimeout is the timeout for write operations
 	WebSocketWriteTimeout = 10 * time.Second
+	// ContainerStatsInterval is how often live container stats are pushed to clients
+	ContainerStatsInterval = 2 * time.Second
+	// SSEHeartbeatInterval is how often an idle Server-Sent Events stream sends a keep-alive comment
+	SSEHeartbeatInterval = 15 * time.Second
+	// AutomationLogStreamBuffer is how many automation logs are queued per stream subscriber
+	AutomationLogStreamBuffer = 64
 )
 
 // ===========================================
@@ -94,3 +116,99 @@ const (
 	// ContainerNameMaxLength is the maximum length for container names
 	ContainerNameMaxLength = 63
 )
+
+// ===========================================
+// Headless Conversations
+// ===========================================
+
+const (
+	// HeadlessSystemPromptMaxBytes is the maximum size of a conversation's system prompt
+	HeadlessSystemPromptMaxBytes = 32 * 1024
+	// HeadlessMaxAttachedFiles is the maximum number of files a single prompt may reference
+	HeadlessMaxAttachedFiles = 20
+	// HeadlessAttachedFilesCheckTimeout bounds the in-container check that referenced files exist
+	HeadlessAttachedFilesCheckTimeout = 10 * time.Second
+	// HeadlessGitCommandTimeout bounds git commands run in a conversation's work directory
+	HeadlessGitCommandTimeout = 10 * time.Second
+	// HeadlessMaxConversationTags is the maximum number of tags on a conversation
+	HeadlessMaxConversationTags = 20
+	// HeadlessConversationTagMaxLength is the maximum length of a conversation tag in characters
+	HeadlessConversationTagMaxLength = 32
+	// HeadlessMaxExtraCLIFlags is the maximum number of pass-through Claude CLI flags on a conversation
+	HeadlessMaxExtraCLIFlags = 20
+	// HeadlessExtraCLIValueMaxLength is the maximum length of a pass-through flag's value
+	HeadlessExtraCLIValueMaxLength = 1024
+	// HeadlessMaxTurnsLimit is the highest turn limit a conversation may set
+	HeadlessMaxTurnsLimit = 10000
+	// HeadlessMaxDurationMinutesLimit is the longest wall-clock limit a conversation may set (30 days)
+	HeadlessMaxDurationMinutesLimit = 30 * 24 * 60
+)
+
+// ===========================================
+// Init Console
+// ===========================================
+
+const (
+	// InitConsoleReplayEvents is how many recent Claude init events are replayed to a newly attached client
+	InitConsoleReplayEvents = 500
+	// InitConsoleStreamBuffer is how many init console events are queued per attached client
+	InitConsoleStreamBuffer = 256
+	// InitConsoleMaxLogBytes caps how much raw Claude init output is kept for the container logs
+	InitConsoleMaxLogBytes = 16 * 1024
+)
+
+// ===========================================
+// Pre-init Script
+// ===========================================
+
+const (
+	// PreInitScriptMaxBytes is the maximum size of a container's pre-init script
+	PreInitScriptMaxBytes = 64 * 1024
+	// PreInitScriptMaxLogBytes caps how much script output is copied into container logs
+	PreInitScriptMaxLogBytes = 16 * 1024
+)
+
+// ===========================================
+// Template Propagation
+// ===========================================
+
+const (
+	// TemplatePropagationConcurrency is how many containers a template is re-injected into at once
+	TemplatePropagationConcurrency = 4
+)
+
+// ===========================================
+// Docker Logs
+// ===========================================
+
+const (
+	// DockerLogsDefaultTail is how many lines of Docker container output are sent when no tail is given
+	DockerLogsDefaultTail = 200
+)
+
+// ===========================================
+// Claude Directory Snapshots
+// ===========================================
+
+const (
+	// ClaudeSnapshotMaxBytes caps the total size of the files in a ~/.claude snapshot, and of an uploaded archive
+	ClaudeSnapshotMaxBytes = 256 * 1024 * 1024
+	// ClaudeSnapshotMaxBodyBytes caps a snapshot upload request, leaving room for multipart framing
+	// around an archive at the size limit
+	ClaudeSnapshotMaxBodyBytes = ClaudeSnapshotMaxBytes + 1<<20
+	// ClaudeSnapshotMaxEntries caps how many files and directories a ~/.claude snapshot may hold
+	ClaudeSnapshotMaxEntries = 50000
+)
+
+// ===========================================
+// Workspace Archives
+// ===========================================
+
+const (
+	// WorkspaceArchiveDirName is the retention directory for preserved workspaces, under DATA_DIR
+	WorkspaceArchiveDirName = "workspace-archives"
+	// WorkspaceArchiveMaxBytes caps the total size of the files in a preserved workspace
+	WorkspaceArchiveMaxBytes = 4 * 1024 * 1024 * 1024
+	// WorkspaceExportTimeout bounds archiving a workspace before its container is deleted
+	WorkspaceExportTimeout = 10 * time.Minute
+)
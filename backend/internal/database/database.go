@@ -37,6 +37,7 @@ func Initialize(dbPath string) (*gorm.DB, error) {
 		&models.ClaudeConfig{},
 		&models.ContainerLog{},
 		&models.ContainerPort{},
+		&models.WorkspaceArchive{},
 		&models.TerminalSession{},
 		&models.TerminalHistory{},
 		// PTY Automation Monitoring models
@@ -50,10 +51,20 @@ func Initialize(dbPath string) (*gorm.DB, error) {
 		&models.HeadlessEvent{},
 		// Multi-Configuration Profile models
 		&models.GitHubToken{},
+		&models.RegistryCredential{},
 		&models.EnvVarsProfile{},
 		&models.StartupCommandProfile{},
 		// Claude Config Management models
 		&models.ClaudeConfigTemplate{},
+		&models.TemplateBundle{},
+		&models.PromptTemplate{},
+		&models.ContainerProfile{},
+		&models.ContainerCommand{},
+		&models.IdempotencyKey{},
+		// Auth models
+		&models.RevokedToken{},
+		// Audit log
+		&models.AuditLog{},
 	); err != nil {
 		return nil, err
 	}
@@ -63,6 +74,11 @@ func Initialize(dbPath string) (*gorm.DB, error) {
 		log.Printf("Warning: config profile migration failed: %v", err)
 	}
 
+	// Backfill template IDs for containers created before they were tracked
+	if err := migrateSelectedTemplateIDs(db); err != nil {
+		log.Printf("Warning: selected template ID migration failed: %v", err)
+	}
+
 	return db, nil
 }
 
@@ -150,3 +166,58 @@ func migrateConfigProfiles(db *gorm.DB) error {
 	log.Println("Config profiles migration completed")
 	return nil
 }
+
+// migrateSelectedTemplateIDs fills in SelectedTemplateIDs for containers that only recorded
+// injected template names. A name is mapped only when exactly one template currently has it.
+// The names stay in injection_status, which remains the human-readable injection report, so
+// there is no legacy column to drop afterwards.
+func migrateSelectedTemplateIDs(db *gorm.DB) error {
+	var migrationFlag models.GlobalAutomationConfig
+	result := db.Where("key = ?", "selected_template_ids_migration_completed").First(&migrationFlag)
+	if result.Error == nil && migrationFlag.Value == "true" {
+		return nil
+	}
+
+	var templates []models.ClaudeConfigTemplate
+	if err := db.Find(&templates).Error; err != nil {
+		return err
+	}
+	idsByName := make(map[string][]uint)
+	for _, template := range templates {
+		idsByName[template.Name] = append(idsByName[template.Name], template.ID)
+	}
+
+	var containers []models.Container
+	if err := db.Where("injection_status IS NOT NULL AND injection_status != ''").Find(&containers).Error; err != nil {
+		return err
+	}
+
+	for _, container := range containers {
+		if len(container.SelectedTemplateIDs) > 0 || container.InjectionStatus == nil {
+			continue
+		}
+		var ids models.TemplateIDList
+		for _, name := range container.InjectionStatus.Successful {
+			matches := idsByName[name]
+			if len(matches) != 1 {
+				log.Printf("Container %s: cannot map template '%s' to an ID (%d matches)", container.Name, name, len(matches))
+				continue
+			}
+			ids = append(ids, matches[0])
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		if err := db.Model(&models.Container{}).Where("id = ?", container.ID).
+			Update("selected_template_ids", ids).Error; err != nil {
+			log.Printf("Failed to backfill template IDs for container %s: %v", container.Name, err)
+		}
+	}
+
+	db.Create(&models.GlobalAutomationConfig{
+		Key:   "selected_template_ids_migration_completed",
+		Value: "true",
+	})
+	log.Println("Selected template ID migration completed")
+	return nil
+}
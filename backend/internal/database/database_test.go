@@ -0,0 +1,84 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMigrateSelectedTemplateIDs_BackfillsLegacyContainers(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Container{}, &models.ClaudeConfigTemplate{}, &models.GlobalAutomationConfig{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	templates := []models.ClaudeConfigTemplate{
+		{Name: "rules", ConfigType: models.ConfigTypeClaudeMD},
+		{Name: "github", ConfigType: models.ConfigTypeMCP},
+		{Name: "review", ConfigType: models.ConfigTypeSkill},
+		{Name: "review", ConfigType: models.ConfigTypeCommand}, // Ambiguous: the name is reused
+	}
+	for i := range templates {
+		if err := db.Create(&templates[i]).Error; err != nil {
+			t.Fatalf("failed to create template: %v", err)
+		}
+	}
+
+	// Legacy rows only recorded injected template names
+	legacy := models.Container{Name: "legacy", DockerID: "legacy", InjectionStatus: &models.InjectionStatus{
+		Successful: []string{"rules", "github", "review", "deleted"},
+	}}
+	current := models.Container{Name: "current", DockerID: "current", SelectedTemplateIDs: models.TemplateIDList{templates[0].ID},
+		InjectionStatus: &models.InjectionStatus{Successful: []string{"rules", "github"}}}
+	unmapped := models.Container{Name: "unmapped", DockerID: "unmapped", InjectionStatus: &models.InjectionStatus{
+		Successful: []string{"review"},
+	}}
+	for _, c := range []*models.Container{&legacy, &current, &unmapped} {
+		if err := db.Create(c).Error; err != nil {
+			t.Fatalf("failed to create container: %v", err)
+		}
+	}
+
+	if err := migrateSelectedTemplateIDs(db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	selected := func(id uint) models.TemplateIDList {
+		var c models.Container
+		if err := db.First(&c, id).Error; err != nil {
+			t.Fatalf("failed to load container: %v", err)
+		}
+		return c.SelectedTemplateIDs
+	}
+	if got, want := selected(legacy.ID), (models.TemplateIDList{templates[0].ID, templates[1].ID}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected uniquely named templates to be backfilled as %v, got %v", want, got)
+	}
+	if got, want := selected(current.ID), (models.TemplateIDList{templates[0].ID}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected existing selections to be left alone, got %v", got)
+	}
+	if got := selected(unmapped.ID); len(got) != 0 {
+		t.Errorf("Expected ambiguous names to be skipped, got %v", got)
+	}
+
+	// A second run is a no-op once the completion flag is stored
+	if err := db.Model(&models.Container{}).Where("id = ?", unmapped.ID).
+		Update("injection_status", &models.InjectionStatus{Successful: []string{"rules"}}).Error; err != nil {
+		t.Fatalf("failed to update container: %v", err)
+	}
+	if err := migrateSelectedTemplateIDs(db); err != nil {
+		t.Fatalf("second migration failed: %v", err)
+	}
+	if got := selected(unmapped.ID); len(got) != 0 {
+		t.Errorf("Expected the migration to run only once, got %v", got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -14,6 +15,7 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 )
 
@@ -27,36 +29,52 @@ const (
 	developerExtraPath      = "/home/developer/.npm-global/bin:/home/developer/.local/bin"
 )
 
-// Client wraps the Docker SDK client
+// Client wraps the Docker SDK client. The SDK client is swapped out by Reconnect,
+// so methods reach it through api().
 type Client struct {
-	cli *client.Client
+	cli atomic.Pointer[client.Client]
 }
 
 // NewClient creates a new Docker client
 func NewClient() (*Client, error) {
+	cli, err := newSDKClient()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{}
+	c.cli.Store(cli)
+	return c, nil
+}
+
+func newSDKClient() (*client.Client, error) {
 	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Docker client: %w", err)
 	}
+	return cli, nil
+}
 
-	return &Client{cli: cli}, nil
+// api returns the current SDK client
+func (c *Client) api() *client.Client {
+	return c.cli.Load()
 }
 
 // Close closes the Docker client
 func (c *Client) Close() error {
-	return c.cli.Close()
+	return c.api().Close()
 }
 
 // Ping checks if Docker daemon is accessible
 func (c *Client) Ping(ctx context.Context) error {
-	_, err := c.cli.Ping(ctx)
+	_, err := c.api().Ping(ctx)
 	return err
 }
 
 // BaseImageExists checks if the base image exists
 func (c *Client) BaseImageExists(ctx context.Context) bool {
 	imageName := fmt.Sprintf("%s:%s", BaseImageName, BaseImageTag)
-	_, _, err := c.cli.ImageInspectWithRaw(ctx, imageName)
+	_, _, err := c.api().ImageInspectWithRaw(ctx, imageName)
 	return err == nil
 }
 
@@ -87,7 +105,7 @@ func (c *Client) BuildBaseImage(ctx context.Context, dockerfilePath string) erro
 		Remove:     true,
 	}
 
-	resp, err := c.cli.ImageBuild(ctx, buildContext, buildOptions)
+	resp, err := c.api().ImageBuild(ctx, buildContext, buildOptions)
 	if err != nil {
 		return fmt.Errorf("failed to build image: %w", err)
 	}
@@ -102,19 +120,6 @@ func (c *Client) BuildBaseImage(ctx context.Context, dockerfilePath string) erro
 	return nil
 }
 
-// PullImage pulls an image from registry
-func (c *Client) PullImage(ctx context.Context, imageName string) error {
-	resp, err := c.cli.ImagePull(ctx, imageName, types.ImagePullOptions{})
-	if err != nil {
-		return err
-	}
-	defer resp.Close()
-
-	// Read pull output
-	_, err = io.Copy(io.Discard, resp)
-	return err
-}
-
 // CreateContainer creates a new container
 func (c *Client) CreateContainer(ctx context.Context, config *ContainerConfig) (string, error) {
 	// Select image based on code-server requirement
@@ -123,6 +128,9 @@ func (c *Client) CreateContainer(ctx context.Context, config *ContainerConfig) (
 		imageTag = BaseImageWithCodeServer
 	}
 	imageName := fmt.Sprintf("%s:%s", BaseImageName, imageTag)
+	if config.Image != "" {
+		imageName = config.Image
+	}
 
 	// Build exposed ports and port bindings
 	exposedPorts := nat.PortSet{}
@@ -139,12 +147,9 @@ func (c *Client) CreateContainer(ctx context.Context, config *ContainerConfig) (
 		}
 	}
 
-	// Determine user: root or developer (default)
+	// Determine user: root, an explicit user, or developer (default)
 	// Note: The base image creates a user called 'developer' with home at /home/developer
-	user := "developer"
-	if config.RunAsRoot {
-		user = "root"
-	}
+	user := containerUser(config)
 	workingDir := config.WorkingDir
 	if workingDir == "" {
 		workingDir = "/workspace"
@@ -167,13 +172,14 @@ func (c *Client) CreateContainer(ctx context.Context, config *ContainerConfig) (
 
 	// Build host config with security settings
 	hostConfig := &container.HostConfig{
-		Binds:        config.Binds,
-		SecurityOpt:  config.SecurityOpt,
-		CapDrop:      config.CapDrop,
-		CapAdd:       config.CapAdd,
-		Resources:    config.Resources,
-		NetworkMode:  container.NetworkMode(config.NetworkMode),
-		PortBindings: portBindings,
+		Binds:         config.Binds,
+		SecurityOpt:   config.SecurityOpt,
+		CapDrop:       config.CapDrop,
+		CapAdd:        config.CapAdd,
+		Resources:     config.Resources,
+		NetworkMode:   container.NetworkMode(config.NetworkMode),
+		PortBindings:  portBindings,
+		RestartPolicy: config.RestartPolicy,
 	}
 
 	// Network config - connect to traefik network only if explicitly requested
@@ -187,7 +193,7 @@ func (c *Client) CreateContainer(ctx context.Context, config *ContainerConfig) (
 	}
 
 	// Create container
-	resp, err := c.cli.ContainerCreate(ctx, containerConfig, hostConfig, networkingConfig, nil, config.Name)
+	resp, err := c.api().ContainerCreate(ctx, containerConfig, hostConfig, networkingConfig, nil, config.Name)
 	if err != nil {
 		return "", fmt.Errorf("failed to create container: %w", err)
 	}
@@ -197,7 +203,7 @@ func (c *Client) CreateContainer(ctx context.Context, config *ContainerConfig) (
 
 // StartContainer starts a container
 func (c *Client) StartContainer(ctx context.Context, containerID string) error {
-	return c.cli.ContainerStart(ctx, containerID, container.StartOptions{})
+	return c.api().ContainerStart(ctx, containerID, container.StartOptions{})
 }
 
 // StopContainer stops a container
@@ -206,12 +212,72 @@ func (c *Client) StopContainer(ctx context.Context, containerID string, timeout
 	if timeout != nil {
 		stopOptions.Timeout = timeout
 	}
-	return c.cli.ContainerStop(ctx, containerID, stopOptions)
+	return c.api().ContainerStop(ctx, containerID, stopOptions)
+}
+
+// PauseContainer freezes all processes in a container
+func (c *Client) PauseContainer(ctx context.Context, containerID string) error {
+	return c.api().ContainerPause(ctx, containerID)
+}
+
+// UnpauseContainer resumes a paused container
+func (c *Client) UnpauseContainer(ctx context.Context, containerID string) error {
+	return c.api().ContainerUnpause(ctx, containerID)
+}
+
+// RenameContainer gives a container a new name
+func (c *Client) RenameContainer(ctx context.Context, containerID, newName string) error {
+	return c.api().ContainerRename(ctx, containerID, newName)
+}
+
+// UpdateContainerResources changes the CPU and memory limits of an existing container in place
+func (c *Client) UpdateContainerResources(ctx context.Context, containerID string, resources container.Resources) error {
+	_, err := c.api().ContainerUpdate(ctx, containerID, container.UpdateConfig{Resources: resources})
+	return err
+}
+
+// StreamLogs copies a container's stdout and stderr to w until the logs end or ctx is cancelled.
+// With Follow set it keeps streaming new output as the container writes it.
+func (c *Client) StreamLogs(ctx context.Context, containerID string, opts container.LogsOptions, w io.Writer) error {
+	info, err := c.api().ContainerInspect(ctx, containerID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	opts.ShowStdout = true
+	opts.ShowStderr = true
+	reader, err := c.api().ContainerLogs(ctx, containerID, opts)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// The log reader only notices cancellation on its next read, so close it right away
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			reader.Close()
+		case <-done:
+		}
+	}()
+
+	// Without a TTY, stdout and stderr arrive multiplexed
+	if info.Config != nil && info.Config.Tty {
+		_, err = io.Copy(w, reader)
+	} else {
+		_, err = stdcopy.StdCopy(w, w, reader)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
 }
 
 // RemoveContainer removes a container
 func (c *Client) RemoveContainer(ctx context.Context, containerID string, force bool) error {
-	return c.cli.ContainerRemove(ctx, containerID, container.RemoveOptions{
+	return c.api().ContainerRemove(ctx, containerID, container.RemoveOptions{
 		Force:         force,
 		RemoveVolumes: true,
 	})
@@ -223,7 +289,7 @@ func (c *Client) RemoveVolumes(ctx context.Context, volumeNames ...string) error
 		if volumeName == "" {
 			continue
 		}
-		if err := c.cli.VolumeRemove(ctx, volumeName, true); err != nil {
+		if err := c.api().VolumeRemove(ctx, volumeName, true); err != nil {
 			if strings.Contains(strings.ToLower(err.Error()), "no such volume") {
 				continue
 			}
@@ -235,16 +301,31 @@ func (c *Client) RemoveVolumes(ctx context.Context, volumeNames ...string) error
 
 // GetContainerStatus gets the status of a container
 func (c *Client) GetContainerStatus(ctx context.Context, containerID string) (string, error) {
-	info, err := c.cli.ContainerInspect(ctx, containerID)
+	info, err := c.api().ContainerInspect(ctx, containerID)
 	if err != nil {
 		return "", err
 	}
 	return info.State.Status, nil
 }
 
+// InspectContainer returns the full Docker inspect data of a container
+func (c *Client) InspectContainer(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	return c.api().ContainerInspect(ctx, containerID)
+}
+
+// StreamContainerStats opens the live stats stream of a container.
+// The stream emits one JSON-encoded types.StatsJSON per sample; callers must close it.
+func (c *Client) StreamContainerStats(ctx context.Context, containerID string) (io.ReadCloser, error) {
+	stats, err := c.api().ContainerStats(ctx, containerID, true)
+	if err != nil {
+		return nil, err
+	}
+	return stats.Body, nil
+}
+
 // GetContainerIP gets the IP address of a container in the bridge network
 func (c *Client) GetContainerIP(ctx context.Context, containerID string) (string, error) {
-	info, err := c.cli.ContainerInspect(ctx, containerID)
+	info, err := c.api().ContainerInspect(ctx, containerID)
 	if err != nil {
 		return "", err
 	}
@@ -271,7 +352,7 @@ func (c *Client) GetContainerIP(ctx context.Context, containerID string) (string
 
 // ListContainers lists all containers managed by cc-platform
 func (c *Client) ListContainers(ctx context.Context) ([]types.Container, error) {
-	return c.cli.ContainerList(ctx, container.ListOptions{
+	return c.api().ContainerList(ctx, container.ListOptions{
 		All: true,
 		Filters: filters.NewArgs(
 			filters.Arg("label", "cc-platform.managed=true"),
@@ -281,7 +362,7 @@ func (c *Client) ListContainers(ctx context.Context) ([]types.Container, error)
 
 // ListAllContainers lists all containers (for admin/debug purposes)
 func (c *Client) ListAllContainers(ctx context.Context) ([]types.Container, error) {
-	return c.cli.ContainerList(ctx, container.ListOptions{
+	return c.api().ContainerList(ctx, container.ListOptions{
 		All: true,
 	})
 }
@@ -289,7 +370,7 @@ func (c *Client) ListAllContainers(ctx context.Context) ([]types.Container, erro
 // ExecInContainer executes a command in a container
 func (c *Client) ExecInContainer(ctx context.Context, containerID string, cmd []string) (string, error) {
 	// First, inspect the container to get the user setting
-	containerInfo, err := c.cli.ContainerInspect(ctx, containerID)
+	containerInfo, err := c.api().ContainerInspect(ctx, containerID)
 	if err != nil {
 		return "", fmt.Errorf("failed to inspect container: %w", err)
 	}
@@ -301,10 +382,7 @@ func (c *Client) ExecInContainer(ctx context.Context, containerID string, cmd []
 	}
 
 	// Set HOME environment variable based on user
-	homeDir := "/root"
-	if user != "root" && user != "0" {
-		homeDir = fmt.Sprintf("/home/%s", user)
-	}
+	homeDir := HomeDirForUser(user)
 
 	execConfig := types.ExecConfig{
 		Cmd:          cmd,
@@ -314,12 +392,12 @@ func (c *Client) ExecInContainer(ctx context.Context, containerID string, cmd []
 		Env:          buildExecEnv(containerInfo.Config.Env, homeDir),
 	}
 
-	execID, err := c.cli.ContainerExecCreate(ctx, containerID, execConfig)
+	execID, err := c.api().ContainerExecCreate(ctx, containerID, execConfig)
 	if err != nil {
 		return "", err
 	}
 
-	resp, err := c.cli.ContainerExecAttach(ctx, execID.ID, types.ExecStartCheck{})
+	resp, err := c.api().ContainerExecAttach(ctx, execID.ID, types.ExecStartCheck{})
 	if err != nil {
 		return "", err
 	}
@@ -333,9 +411,21 @@ func (c *Client) ExecInContainer(ctx context.Context, containerID string, cmd []
 	return string(output), nil
 }
 
+// CopyFromContainer returns a tar archive of the file or directory at path inside a container
+func (c *Client) CopyFromContainer(ctx context.Context, containerID, path string) (io.ReadCloser, error) {
+	reader, _, err := c.api().CopyFromContainer(ctx, containerID, path)
+	return reader, err
+}
+
+// CopyToContainer extracts a tar archive into dir inside a container. Extracted files are
+// owned by the container's user rather than root.
+func (c *Client) CopyToContainer(ctx context.Context, containerID, dir string, content io.Reader) error {
+	return c.api().CopyToContainer(ctx, containerID, dir, content, types.CopyToContainerOptions{CopyUIDGID: true})
+}
+
 // ExecAsRoot executes a command in a container as root user
 func (c *Client) ExecAsRoot(ctx context.Context, containerID string, cmd []string) (string, error) {
-	containerInfo, err := c.cli.ContainerInspect(ctx, containerID)
+	containerInfo, err := c.api().ContainerInspect(ctx, containerID)
 	if err != nil {
 		return "", fmt.Errorf("failed to inspect container: %w", err)
 	}
@@ -345,15 +435,15 @@ func (c *Client) ExecAsRoot(ctx context.Context, containerID string, cmd []strin
 		AttachStdout: true,
 		AttachStderr: true,
 		User:         "root",
-		Env:          buildExecEnv(containerInfo.Config.Env, "/root"),
+		Env:          buildExecEnv(containerInfo.Config.Env, rootHomeDir),
 	}
 
-	execID, err := c.cli.ContainerExecCreate(ctx, containerID, execConfig)
+	execID, err := c.api().ContainerExecCreate(ctx, containerID, execConfig)
 	if err != nil {
 		return "", err
 	}
 
-	resp, err := c.cli.ContainerExecAttach(ctx, execID.ID, types.ExecStartCheck{})
+	resp, err := c.api().ContainerExecAttach(ctx, execID.ID, types.ExecStartCheck{})
 	if err != nil {
 		return "", err
 	}
@@ -367,6 +457,68 @@ func (c *Client) ExecAsRoot(ctx context.Context, containerID string, cmd []strin
 	return string(output), nil
 }
 
+// ExecStream executes a command in a container and copies stdout/stderr to the given
+// writers as output arrives, instead of buffering it. It returns the command's exit code.
+// Cancelling ctx detaches from the exec; the process itself may keep running.
+func (c *Client) ExecStream(ctx context.Context, containerID string, cmd []string, stdout, stderr io.Writer) (int, error) {
+	containerInfo, err := c.api().ContainerInspect(ctx, containerID)
+	if err != nil {
+		return -1, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	user := containerInfo.Config.User
+	if user == "" {
+		user = "root"
+	}
+	homeDir := HomeDirForUser(user)
+
+	execConfig := types.ExecConfig{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+		User:         user,
+		Env:          buildExecEnv(containerInfo.Config.Env, homeDir),
+	}
+
+	execID, err := c.api().ContainerExecCreate(ctx, containerID, execConfig)
+	if err != nil {
+		return -1, err
+	}
+
+	resp, err := c.api().ContainerExecAttach(ctx, execID.ID, types.ExecStartCheck{})
+	if err != nil {
+		return -1, err
+	}
+	defer resp.Close()
+
+	// The hijacked connection ignores ctx, so close it explicitly on cancellation
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			resp.Close()
+		case <-done:
+		}
+	}()
+
+	if _, err := stdcopy.StdCopy(stdout, stderr, resp.Reader); err != nil {
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		return -1, err
+	}
+	if ctx.Err() != nil {
+		return -1, ctx.Err()
+	}
+
+	inspect, err := c.api().ContainerExecInspect(ctx, execID.ID)
+	if err != nil {
+		return -1, fmt.Errorf("failed to inspect exec: %w", err)
+	}
+	return inspect.ExitCode, nil
+}
+
 func buildExecEnv(baseEnv []string, homeDir string) []string {
 	envMap := make(map[string]string, len(baseEnv)+4)
 	for _, entry := range baseEnv {
@@ -383,7 +535,10 @@ func buildExecEnv(baseEnv []string, homeDir string) []string {
 	}
 
 	envMap["HOME"] = homeDir
-	envMap["CC_CONFIG_HOME"] = developerHomeDir
+	// Containers running as a custom user carry their own config home in the container env
+	if envMap[configHomeEnvVar] == "" {
+		envMap[configHomeEnvVar] = developerHomeDir
+	}
 	envMap["NPM_CONFIG_PREFIX"] = developerNpmPrefix
 	envMap["PATH"] = developerExtraPath + ":" + currentPath
 
@@ -409,7 +564,10 @@ type ContainerConfig struct {
 	Labels        map[string]string // Container labels (for Traefik routing)
 	UseTraefikNet bool              // Connect to traefik-net network
 	UseCodeServer bool              // Use image with code-server
+	Image         string            // Custom image instead of the platform base image
 	RunAsRoot     bool              // Run as root user (default: false, runs as dev user)
+	RunAsUser     string            // Run as this user (username or uid[:gid]); ignored when RunAsRoot is set
+	RestartPolicy container.RestartPolicy
 }
 
 // createBuildContext creates a tar archive of the build context
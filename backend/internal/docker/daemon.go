@@ -0,0 +1,80 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// ErrDaemonUnavailable is returned when the Docker daemon cannot be reached
+var ErrDaemonUnavailable = errors.New("Docker daemon is unavailable")
+
+// daemonCheckTimeout bounds a single daemon ping
+const daemonCheckTimeout = 5 * time.Second
+
+// DaemonStatus is the result of a daemon health check
+type DaemonStatus struct {
+	Available  bool      `json:"available"`
+	APIVersion string    `json:"api_version,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// IsDaemonUnavailable reports whether err means the daemon could not be reached, as opposed
+// to the daemon answering with an error
+func IsDaemonUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrDaemonUnavailable) || client.IsErrConnectionFailed(err) {
+		return true
+	}
+	// The SDK reports dial failures other than a refused connection as "error during connect"
+	return strings.Contains(err.Error(), "error during connect")
+}
+
+// CheckDaemon pings the daemon. When it cannot be reached the SDK client is recreated and
+// the ping retried once, so a restarted daemon, possibly with a new API version, is picked up.
+func (c *Client) CheckDaemon(ctx context.Context) DaemonStatus {
+	apiVersion, err := c.ping(ctx)
+	if IsDaemonUnavailable(err) {
+		if c.Reconnect() == nil {
+			apiVersion, err = c.ping(ctx)
+		}
+	}
+
+	status := DaemonStatus{CheckedAt: time.Now()}
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Available = true
+	status.APIVersion = apiVersion
+	return status
+}
+
+func (c *Client) ping(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, daemonCheckTimeout)
+	defer cancel()
+	ping, err := c.api().Ping(ctx)
+	if err != nil {
+		return "", err
+	}
+	return ping.APIVersion, nil
+}
+
+// Reconnect replaces the SDK client with a fresh one. Requests already in flight finish on
+// the old client; its idle connections are closed.
+func (c *Client) Reconnect() error {
+	cli, err := newSDKClient()
+	if err != nil {
+		return err
+	}
+	if old := c.cli.Swap(cli); old != nil {
+		old.Close()
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/client"
+)
+
+// fakeDaemon answers pings like a Docker daemon with the given API version
+func fakeDaemon(t *testing.T, apiVersion string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", apiVersion)
+		if strings.HasSuffix(r.URL.Path, "/_ping") {
+			w.Write([]byte("OK"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func dockerHost(server *httptest.Server) string {
+	return "tcp://" + strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestIsDaemonUnavailable(t *testing.T) {
+	down := client.ErrorConnectionFailed("unix:///var/run/docker.sock")
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{down, true},
+		{fmt.Errorf("failed to inspect container: %w", down), true},
+		{fmt.Errorf("wrapped: %w", ErrDaemonUnavailable), true},
+		{errors.New("error during connect: Get \"http://docker/v1.44/containers/json\": dial tcp: lookup docker: no such host"), true},
+		{errors.New("Error response from daemon: No such container: abc"), false},
+	}
+	for _, tc := range cases {
+		if got := IsDaemonUnavailable(tc.err); got != tc.want {
+			t.Errorf("IsDaemonUnavailable(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestCheckDaemon_DownAndReconnect(t *testing.T) {
+	first := fakeDaemon(t, "1.44")
+	t.Setenv("DOCKER_HOST", dockerHost(first))
+	t.Setenv("DOCKER_TLS_VERIFY", "")
+	t.Setenv("DOCKER_CERT_PATH", "")
+
+	c, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	if status := c.CheckDaemon(ctx); !status.Available || status.APIVersion != "1.44" {
+		t.Fatalf("Expected the daemon to be available, got %+v", status)
+	}
+
+	// The daemon goes away: operations fail with a connection error and the check reports it
+	first.Close()
+	if _, err := c.ListContainers(ctx); !IsDaemonUnavailable(err) {
+		t.Errorf("Expected a daemon-unavailable error, got %v", err)
+	}
+	status := c.CheckDaemon(ctx)
+	if status.Available || status.Error == "" {
+		t.Errorf("Expected the daemon to be reported down, got %+v", status)
+	}
+
+	// It comes back (here on a new address with a newer API): the check reconnects
+	second := fakeDaemon(t, "1.45")
+	t.Setenv("DOCKER_HOST", dockerHost(second))
+	if status := c.CheckDaemon(ctx); !status.Available || status.APIVersion != "1.45" {
+		t.Errorf("Expected the check to reconnect to the restarted daemon, got %+v", status)
+	}
+}
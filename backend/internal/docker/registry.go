@@ -0,0 +1,97 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/errdefs"
+)
+
+// ErrRegistryAuth is returned when a registry refuses a pull because credentials are missing or wrong
+var ErrRegistryAuth = errors.New("registry authentication failed")
+
+// registryAuthErrorMarkers are fragments of the messages registries and the daemon use for
+// refused pulls. The daemon reports most of them as plain errors without a typed status.
+var registryAuthErrorMarkers = []string{
+	"unauthorized",
+	"authentication required",
+	"access denied",
+	"access forbidden",
+	"denied:",
+	"no basic auth credentials",
+	"may require 'docker login'",
+}
+
+// RegistryAuth encodes a username and password for server as an X-Registry-Auth value
+func RegistryAuth(server, username, password string) (string, error) {
+	return registry.EncodeAuthConfig(registry.AuthConfig{
+		Username:      username,
+		Password:      password,
+		ServerAddress: server,
+	})
+}
+
+// ImageRegistry parses an image reference the way Docker does and returns its registry host;
+// references without a host belong to docker.io
+func ImageRegistry(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", err
+	}
+	return reference.Domain(named), nil
+}
+
+// isRegistryAuthError reports whether a pull failed because the registry refused the credentials
+func isRegistryAuthError(err error) bool {
+	if errdefs.IsUnauthorized(err) || errdefs.IsForbidden(err) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range registryAuthErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// readPullProgress drains a pull's JSON progress stream. Errors after the pull has started
+// only show up in the stream, as a message with an error field.
+func readPullProgress(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+	}
+}
+
+// PullImage pulls an image from its registry. registryAuth is an X-Registry-Auth value from
+// RegistryAuth, or empty for an anonymous pull. Refused pulls are reported as ErrRegistryAuth.
+func (c *Client) PullImage(ctx context.Context, imageName, registryAuth string) error {
+	resp, err := c.api().ImagePull(ctx, imageName, types.ImagePullOptions{RegistryAuth: registryAuth})
+	if err == nil {
+		err = readPullProgress(resp)
+		resp.Close()
+	}
+	if err != nil && isRegistryAuthError(err) {
+		return fmt.Errorf("%w: %v", ErrRegistryAuth, err)
+	}
+	return err
+}
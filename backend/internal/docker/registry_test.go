@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegistryAuth_EncodesCredentials(t *testing.T) {
+	header, err := RegistryAuth("registry.example.com:5000", "deploy", "s3cr3t/+=")
+	if err != nil {
+		t.Fatalf("RegistryAuth failed: %v", err)
+	}
+	if strings.ContainsAny(header, "+/") {
+		t.Errorf("Expected URL-safe base64, got %q", header)
+	}
+
+	data, err := base64.URLEncoding.DecodeString(header)
+	if err != nil {
+		t.Fatalf("Header is not URL-safe base64: %v", err)
+	}
+	var auth struct {
+		Username      string `json:"username"`
+		Password      string `json:"password"`
+		ServerAddress string `json:"serveraddress"`
+	}
+	if err := json.Unmarshal(data, &auth); err != nil {
+		t.Fatalf("Header does not decode to JSON: %v", err)
+	}
+	if auth.Username != "deploy" || auth.Password != "s3cr3t/+=" || auth.ServerAddress != "registry.example.com:5000" {
+		t.Errorf("Unexpected auth config: %+v", auth)
+	}
+}
+
+func TestImageRegistry(t *testing.T) {
+	testCases := map[string]string{
+		"ubuntu:22.04":                         "docker.io",
+		"library/ubuntu":                       "docker.io",
+		"myorg/tools:1.0":                      "docker.io",
+		"ghcr.io/myorg/dev:latest":             "ghcr.io",
+		"registry.example.com:5000/team/image": "registry.example.com:5000",
+		"localhost/dev":                        "localhost",
+	}
+	for image, want := range testCases {
+		got, err := ImageRegistry(image)
+		if err != nil {
+			t.Errorf("ImageRegistry(%q) failed: %v", image, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ImageRegistry(%q) = %q, want %q", image, got, want)
+		}
+	}
+
+	for _, image := range []string{"", "bad image", "-rm"} {
+		if _, err := ImageRegistry(image); err == nil {
+			t.Errorf("Expected %q to be rejected", image)
+		}
+	}
+}
+
+func TestIsRegistryAuthError(t *testing.T) {
+	refused := []string{
+		"Error response from daemon: pull access denied for private/img, repository does not exist or may require 'docker login': denied: requested access to the resource is denied",
+		"Error response from daemon: Head \"https://ghcr.io/v2/x/manifests/latest\": unauthorized",
+		"no basic auth credentials",
+	}
+	for _, msg := range refused {
+		if !isRegistryAuthError(errors.New(msg)) {
+			t.Errorf("Expected %q to be an auth error", msg)
+		}
+	}
+	if isRegistryAuthError(errors.New("manifest for ubuntu:nope not found: manifest unknown")) {
+		t.Error("Expected a missing tag not to be an auth error")
+	}
+}
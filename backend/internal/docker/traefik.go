@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/errdefs"
+)
+
+// TraefikNetwork is the network Traefik reaches proxied containers on
+const TraefikNetwork = "traefik-net"
+
+// TraefikStatus reports whether Traefik can route to containers
+type TraefikStatus struct {
+	NetworkExists bool // traefik-net exists, so containers can join it
+	Running       bool // A running Traefik container is attached to traefik-net
+}
+
+// TraefikStatus checks for the traefik-net network and a running Traefik container on it.
+// Both the container started by AUTO_START_TRAEFIK and one deployed separately qualify.
+func (c *Client) TraefikStatus(ctx context.Context) (TraefikStatus, error) {
+	var status TraefikStatus
+	if _, err := c.api().NetworkInspect(ctx, TraefikNetwork, types.NetworkInspectOptions{}); err != nil {
+		if errdefs.IsNotFound(err) {
+			return status, nil
+		}
+		return status, err
+	}
+	status.NetworkExists = true
+
+	containers, err := c.api().ContainerList(ctx, container.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("network", TraefikNetwork)),
+	})
+	if err != nil {
+		return status, err
+	}
+	for _, ctr := range containers {
+		if strings.Contains(strings.ToLower(ctr.Image), "traefik") {
+			status.Running = true
+			break
+		}
+	}
+	return status, nil
+}
+
+// ConnectTraefikNetwork attaches a container to traefik-net unless it is already attached
+func (c *Client) ConnectTraefikNetwork(ctx context.Context, containerID string) error {
+	info, err := c.api().ContainerInspect(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	if info.NetworkSettings != nil {
+		if _, ok := info.NetworkSettings.Networks[TraefikNetwork]; ok {
+			return nil
+		}
+	}
+	return c.api().NetworkConnect(ctx, TraefikNetwork, containerID, nil)
+}
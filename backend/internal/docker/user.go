@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	rootHomeDir      = "/root"
+	developerUser    = "developer"
+	developerUID     = "1000"
+	configHomeEnvVar = "CC_CONFIG_HOME"
+)
+
+// HomeDirForUser returns the home directory used for a container user given as a
+// username or uid[:gid]. Users without an entry in the base image get /home/<name>.
+func HomeDirForUser(user string) string {
+	name := strings.TrimSpace(user)
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+	switch name {
+	case "", "root", "0":
+		return rootHomeDir
+	case developerUser, developerUID:
+		return developerHomeDir
+	default:
+		return fmt.Sprintf("/home/%s", name)
+	}
+}
+
+// UserHomeEnv returns the environment that points HOME and the config injection home
+// at the given user's home directory
+func UserHomeEnv(user string) []string {
+	home := HomeDirForUser(user)
+	return []string{"HOME=" + home, configHomeEnvVar + "=" + home}
+}
+
+// containerUser returns the user a container is created with: the explicit user when
+// given, otherwise root or the base image's developer user
+func containerUser(config *ContainerConfig) string {
+	if config.RunAsRoot {
+		return "root"
+	}
+	if config.RunAsUser != "" {
+		return config.RunAsUser
+	}
+	return developerUser
+}
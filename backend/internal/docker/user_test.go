@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHomeDirForUser(t *testing.T) {
+	testCases := map[string]string{
+		"":          "/root",
+		"root":      "/root",
+		"0:0":       "/root",
+		"developer": "/home/developer",
+		"1000:1000": "/home/developer",
+		"alice":     "/home/alice",
+		"1001:1001": "/home/1001",
+	}
+	for user, want := range testCases {
+		if got := HomeDirForUser(user); got != want {
+			t.Errorf("HomeDirForUser(%q) = %q, want %q", user, got, want)
+		}
+	}
+}
+
+func TestContainerUser(t *testing.T) {
+	if got := containerUser(&ContainerConfig{}); got != "developer" {
+		t.Errorf("Expected the image's developer user by default, got %q", got)
+	}
+	if got := containerUser(&ContainerConfig{RunAsUser: "1001:1001"}); got != "1001:1001" {
+		t.Errorf("Expected the explicit user, got %q", got)
+	}
+	if got := containerUser(&ContainerConfig{RunAsRoot: true, RunAsUser: "alice"}); got != "root" {
+		t.Errorf("Expected RunAsRoot to win, got %q", got)
+	}
+}
+
+// injectionHome resolves the ${CC_CONFIG_HOME:-$HOME} prefix used by config injection
+func injectionHome(env []string) string {
+	vars := map[string]string{}
+	for _, entry := range env {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			vars[key] = value
+		}
+	}
+	if vars[configHomeEnvVar] != "" {
+		return vars[configHomeEnvVar]
+	}
+	return vars["HOME"]
+}
+
+func TestBuildExecEnv_InjectionHomeFollowsRunAsUser(t *testing.T) {
+	containerEnv := append([]string{"PATH=/usr/bin"}, UserHomeEnv("1001:1001")...)
+
+	// Injection runs both as the container user and through ExecAsRoot
+	for _, homeDir := range []string{HomeDirForUser("1001:1001"), rootHomeDir} {
+		if got := injectionHome(buildExecEnv(containerEnv, homeDir)); got != "/home/1001" {
+			t.Errorf("Expected injection paths under /home/1001 (exec HOME %s), got %q", homeDir, got)
+		}
+	}
+
+	if got := injectionHome(buildExecEnv([]string{"PATH=/usr/bin"}, rootHomeDir)); got != developerHomeDir {
+		t.Errorf("Expected injection paths under %s by default, got %q", developerHomeDir, got)
+	}
+}
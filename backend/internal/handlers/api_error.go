@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Stable error codes for failures that aren't tied to a service sentinel error
+const (
+	ErrCodeInvalidRequest     = "INVALID_REQUEST"
+	ErrCodeInvalidID          = "INVALID_ID"
+	ErrCodeInternal           = "INTERNAL_ERROR"
+	ErrCodeDockerUnavailable  = "DOCKER_UNAVAILABLE"
+	ErrCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	ErrCodeUpstream           = "UPSTREAM_ERROR"
+)
+
+// APIError is the body of an error response: {"error": {"code": ..., "message": ..., "details": ...}}.
+// Code is stable for clients to branch on; Message is for humans and may change.
+type APIError struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// serviceErrorMapping ties a service sentinel error to its code and HTTP status. An empty
+// message means the error's own text is sent.
+type serviceErrorMapping struct {
+	err     error
+	status  int
+	code    string
+	message string
+}
+
+// serviceErrorMappings is checked in order with errors.Is, so wrapped errors match too
+var serviceErrorMappings = []serviceErrorMapping{
+	{services.ErrDockerUnavailable, http.StatusServiceUnavailable, ErrCodeDockerUnavailable, dockerUnavailableMessage},
+
+	// Containers
+	{services.ErrContainerNotFound, http.StatusNotFound, "CONTAINER_NOT_FOUND", "Container not found"},
+	{services.ErrContainerAlreadyExists, http.StatusConflict, "CONTAINER_ALREADY_EXISTS", ""},
+	{services.ErrContainerNotReady, http.StatusBadRequest, "CONTAINER_NOT_READY", "Container initialization not complete. Please wait for initialization to finish."},
+	{services.ErrContainerPaused, http.StatusConflict, "CONTAINER_PAUSED", "Container is paused"},
+	{services.ErrContainerNotRunning, http.StatusConflict, "CONTAINER_NOT_RUNNING", "Container is not running"},
+	{services.ErrInvalidContainerName, http.StatusBadRequest, "INVALID_CONTAINER_NAME", ""},
+	{services.ErrNoGitHubTokenConfigured, http.StatusBadRequest, "GITHUB_TOKEN_NOT_CONFIGURED", "GitHub token not configured. Please configure it in Settings."},
+	{services.ErrInvalidCPULimit, http.StatusBadRequest, "INVALID_CPU_LIMIT", ""},
+	{services.ErrInvalidMemoryLimit, http.StatusBadRequest, "INVALID_MEMORY_LIMIT", ""},
+	{services.ErrInvalidCPUPeriod, http.StatusBadRequest, "INVALID_CPU_PERIOD", ""},
+	{services.ErrInvalidGPUCount, http.StatusBadRequest, "INVALID_GPU_COUNT", ""},
+	{services.ErrPreInitScriptTooLarge, http.StatusBadRequest, "PRE_INIT_SCRIPT_TOO_LARGE", ""},
+	{services.ErrYoloNotConfirmed, http.StatusBadRequest, "YOLO_NOT_CONFIRMED", ""},
+	{services.ErrPreInitScriptNotConfirmed, http.StatusBadRequest, "PRE_INIT_SCRIPT_NOT_CONFIRMED", ""},
+	{services.ErrInvalidCallbackURL, http.StatusBadRequest, "INVALID_CALLBACK_URL", ""},
+	{services.ErrWebhookSecretNotConfigured, http.StatusBadRequest, "WEBHOOK_SECRET_NOT_CONFIGURED", ""},
+	{services.ErrInvalidHostMount, http.StatusBadRequest, "INVALID_HOST_MOUNT", ""},
+	{services.ErrHostMountsDisabled, http.StatusForbidden, "HOST_MOUNTS_DISABLED", "Host mounts are disabled on this server"},
+	{services.ErrUnknownResourceProfile, http.StatusBadRequest, "UNKNOWN_RESOURCE_PROFILE", ""},
+	{services.ErrInvalidRestartPolicy, http.StatusBadRequest, "INVALID_RESTART_POLICY", ""},
+	{services.ErrInvalidInitStrategy, http.StatusBadRequest, "INVALID_INIT_STRATEGY", ""},
+	{services.ErrInvalidRunAsUser, http.StatusBadRequest, "INVALID_RUN_AS_USER", ""},
+	{services.ErrInvalidTTL, http.StatusBadRequest, "INVALID_TTL", ""},
+	{services.ErrInvalidCodeServerAuth, http.StatusBadRequest, "INVALID_CODE_SERVER_AUTH", ""},
+	{services.ErrInvalidCodeServerExtension, http.StatusBadRequest, "INVALID_CODE_SERVER_EXTENSION", ""},
+	{services.ErrInvalidImage, http.StatusBadRequest, "INVALID_IMAGE", ""},
+	{services.ErrInvalidClaudeVersion, http.StatusBadRequest, "INVALID_CLAUDE_VERSION", ""},
+	{services.ErrInvalidClaudeOverride, http.StatusBadRequest, "INVALID_CLAUDE_OVERRIDE", ""},
+	{services.ErrInvalidGitRepo, http.StatusBadRequest, "INVALID_GIT_REPO", ""},
+	{services.ErrNoDetailChanges, http.StatusBadRequest, "NO_DETAIL_CHANGES", ""},
+	{services.ErrInvalidContainerDetails, http.StatusBadRequest, "INVALID_CONTAINER_DETAILS", ""},
+	{services.ErrRegistryNotAllowed, http.StatusForbidden, "REGISTRY_NOT_ALLOWED", ""},
+	{services.ErrRegistryAuthFailed, http.StatusBadGateway, "REGISTRY_AUTH_FAILED", ""},
+	{services.ErrContainerProfileNotFound, http.StatusNotFound, "CONTAINER_PROFILE_NOT_FOUND", ""},
+	{services.ErrInvalidIdempotencyKey, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", ""},
+	{services.ErrIdempotencyKeyMismatch, http.StatusConflict, "IDEMPOTENCY_KEY_MISMATCH", ""},
+	{services.ErrIdempotencyKeyInProgress, http.StatusConflict, "IDEMPOTENCY_KEY_IN_PROGRESS", ""},
+	{services.ErrInitNotRunning, http.StatusConflict, "INIT_NOT_RUNNING", "Container initialization is not running"},
+	{services.ErrContainerCommandNotFound, http.StatusNotFound, "CONTAINER_COMMAND_NOT_FOUND", ""},
+	{services.ErrDuplicateContainerCommand, http.StatusConflict, "DUPLICATE_CONTAINER_COMMAND", ""},
+	{services.ErrInvalidContainerCommand, http.StatusBadRequest, "INVALID_CONTAINER_COMMAND", ""},
+
+	// Config templates
+	{services.ErrTemplateNotFound, http.StatusNotFound, "TEMPLATE_NOT_FOUND", ""},
+	{services.ErrDuplicateTemplateName, http.StatusConflict, "DUPLICATE_TEMPLATE_NAME", ""},
+	{services.ErrInvalidConfigType, http.StatusBadRequest, "INVALID_CONFIG_TYPE", ""},
+	{services.ErrInvalidSkillName, http.StatusBadRequest, "INVALID_SKILL_NAME", ""},
+	{services.ErrInvalidSkillFile, http.StatusBadRequest, "INVALID_SKILL_FILE", ""},
+	{services.ErrInvalidTemplateSelection, http.StatusBadRequest, "INVALID_TEMPLATE_SELECTION", ""},
+	{services.ErrTemplateDiffUnsupported, http.StatusBadRequest, "TEMPLATE_DIFF_UNSUPPORTED", ""},
+
+	// Ports
+	{services.ErrPortAlreadyExists, http.StatusConflict, "PORT_ALREADY_EXISTS", "Port already exists"},
+	{services.ErrMCPServerNotFound, http.StatusNotFound, "MCP_SERVER_NOT_FOUND", ""},
+	{services.ErrCodeServerPortsExhausted, http.StatusServiceUnavailable, "CODE_SERVER_PORTS_EXHAUSTED", ""},
+	{services.ErrPortNotFound, http.StatusNotFound, "PORT_NOT_FOUND", "Port not found"},
+
+	// Database maintenance
+	{services.ErrDatabaseBusy, http.StatusConflict, "DATABASE_BUSY", ""},
+}
+
+// writeAPIError answers with the standard error envelope
+func writeAPIError(c *gin.Context, status int, code, message string) {
+	writeAPIErrorDetails(c, status, code, message, nil)
+}
+
+// writeAPIErrorDetails answers with the standard error envelope carrying extra details
+func writeAPIErrorDetails(c *gin.Context, status int, code, message string, details map[string]interface{}) {
+	c.JSON(status, gin.H{"error": APIError{Code: code, Message: message, Details: details}})
+}
+
+// writeInvalidRequest answers 400 for a body that could not be decoded, keeping the cause as a detail
+func writeInvalidRequest(c *gin.Context, message string, err error) {
+	var details map[string]interface{}
+	if err != nil {
+		details = map[string]interface{}{"reason": err.Error()}
+	}
+	writeAPIErrorDetails(c, http.StatusBadRequest, ErrCodeInvalidRequest, message, details)
+}
+
+// serviceErrorStatus returns the HTTP status, code and message for a service error. Errors
+// without a mapping are internal errors.
+func serviceErrorStatus(err error) (int, string, string) {
+	for _, m := range serviceErrorMappings {
+		if errors.Is(err, m.err) {
+			message := m.message
+			if message == "" {
+				message = err.Error()
+			}
+			return m.status, m.code, message
+		}
+	}
+	return http.StatusInternalServerError, ErrCodeInternal, err.Error()
+}
+
+// writeServiceError answers with the status and code mapped to a service error
+func writeServiceError(c *gin.Context, err error) {
+	status, code, message := serviceErrorStatus(err)
+	writeAPIError(c, status, code, message)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiErrorResponse decodes the standard error envelope
+type apiErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// recordServiceError runs writeServiceError and decodes what it answered
+func recordServiceError(t *testing.T, err error) (int, APIError) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	writeServiceError(c, err)
+
+	var response apiErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not an error envelope: %s", w.Body.String())
+	}
+	return w.Code, response.Error
+}
+
+func TestWriteServiceError_Codes(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{services.ErrContainerNotFound, http.StatusNotFound, "CONTAINER_NOT_FOUND"},
+		{services.ErrContainerNotReady, http.StatusBadRequest, "CONTAINER_NOT_READY"},
+		{services.ErrContainerNotRunning, http.StatusConflict, "CONTAINER_NOT_RUNNING"},
+		{services.ErrContainerPaused, http.StatusConflict, "CONTAINER_PAUSED"},
+		{services.ErrContainerAlreadyExists, http.StatusConflict, "CONTAINER_ALREADY_EXISTS"},
+		{services.ErrInvalidContainerName, http.StatusBadRequest, "INVALID_CONTAINER_NAME"},
+		{services.ErrNoGitHubTokenConfigured, http.StatusBadRequest, "GITHUB_TOKEN_NOT_CONFIGURED"},
+		{services.ErrHostMountsDisabled, http.StatusForbidden, "HOST_MOUNTS_DISABLED"},
+		{services.ErrRegistryAuthFailed, http.StatusBadGateway, "REGISTRY_AUTH_FAILED"},
+		{services.ErrIdempotencyKeyMismatch, http.StatusConflict, "IDEMPOTENCY_KEY_MISMATCH"},
+		{services.ErrTemplateNotFound, http.StatusNotFound, "TEMPLATE_NOT_FOUND"},
+		{services.ErrDuplicateTemplateName, http.StatusConflict, "DUPLICATE_TEMPLATE_NAME"},
+		{services.ErrInvalidConfigType, http.StatusBadRequest, "INVALID_CONFIG_TYPE"},
+		{services.ErrInvalidSkillName, http.StatusBadRequest, "INVALID_SKILL_NAME"},
+		{services.ErrInvalidTemplateSelection, http.StatusBadRequest, "INVALID_TEMPLATE_SELECTION"},
+		{services.ErrPortAlreadyExists, http.StatusConflict, "PORT_ALREADY_EXISTS"},
+		{services.ErrPortNotFound, http.StatusNotFound, "PORT_NOT_FOUND"},
+		{services.ErrDockerUnavailable, http.StatusServiceUnavailable, ErrCodeDockerUnavailable},
+		{errors.New("disk full"), http.StatusInternalServerError, ErrCodeInternal},
+	}
+	for _, tc := range cases {
+		status, apiErr := recordServiceError(t, tc.err)
+		if status != tc.status || apiErr.Code != tc.code || apiErr.Message == "" {
+			t.Errorf("%v: got %d %+v, want %d %s", tc.err, status, apiErr, tc.status, tc.code)
+		}
+	}
+}
+
+func TestWriteServiceError_EveryMappingHasUniqueCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, m := range serviceErrorMappings {
+		if seen[m.code] {
+			t.Errorf("code %s is used for more than one error", m.code)
+		}
+		seen[m.code] = true
+
+		status, apiErr := recordServiceError(t, m.err)
+		if status != m.status || apiErr.Code != m.code {
+			t.Errorf("%v: got %d %s, want %d %s", m.err, status, apiErr.Code, m.status, m.code)
+		}
+	}
+}
+
+func TestWriteServiceError_WrappedErrorKeepsMessage(t *testing.T) {
+	status, apiErr := recordServiceError(t, fmt.Errorf("%w: name has spaces", services.ErrInvalidContainerName))
+	if status != http.StatusBadRequest || apiErr.Code != "INVALID_CONTAINER_NAME" ||
+		apiErr.Message != "invalid container name: name has spaces" {
+		t.Errorf("Unexpected response: %d %+v", status, apiErr)
+	}
+
+	// Errors with a fixed message don't leak the wrapped context
+	_, apiErr = recordServiceError(t, fmt.Errorf("lookup 7: %w", services.ErrContainerNotFound))
+	if apiErr.Message != "Container not found" {
+		t.Errorf("Expected the fixed message, got %q", apiErr.Message)
+	}
+}
+
+func TestWriteInvalidRequest_Details(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	writeInvalidRequest(c, "Invalid request body", errors.New("unexpected EOF"))
+
+	var response apiErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if w.Code != http.StatusBadRequest || response.Error.Code != ErrCodeInvalidRequest ||
+		response.Error.Details["reason"] != "unexpected EOF" {
+		t.Errorf("Unexpected response: %d %s", w.Code, w.Body.String())
+	}
+}
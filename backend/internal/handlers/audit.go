@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler exposes the audit log of privileged actions
+type AuditHandler struct {
+	service *services.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(service *services.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// RegisterRoutes registers the audit log routes
+func (h *AuditHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/audit", h.ListAuditLogs)
+}
+
+// parseAuditFilter reads the audit log filter from the query
+func parseAuditFilter(c *gin.Context) (services.AuditFilter, error) {
+	filter := services.AuditFilter{
+		Actor:  c.Query("actor"),
+		Action: c.Query("action"),
+	}
+
+	if v := c.Query("container_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid container_id %q", v)
+		}
+		containerID := uint(id)
+		filter.ContainerID = &containerID
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := parseLogTime(v, false)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = &t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := parseLogTime(v, true)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = &t
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return filter, fmt.Errorf("from must not be after to")
+	}
+
+	for name, target := range map[string]*int{"page": &filter.Page, "page_size": &filter.PageSize} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return filter, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*target = n
+		}
+	}
+	return filter, nil
+}
+
+// ListAuditLogs returns audit log entries, newest first
+// GET /api/audit?container_id=&actor=&action=&from=&to=&page=&page_size=
+// from and to take Unix seconds, RFC3339 or YYYY-MM-DD; page_size defaults to 100 (max 1000)
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		writeInvalidRequest(c, err.Error(), nil)
+		return
+	}
+
+	page, err := h.service.List(filter)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
@@ -1,21 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"os"
+	"strconv"
+	"time"
 
+	"cc-platform/internal/middleware"
 	"cc-platform/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
-const (
-	// Cookie name for JWT token
-	TokenCookieName = "cc_token"
-	// Cookie max age in seconds (24 hours)
-	TokenCookieMaxAge = 86400
-)
-
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
 	authService *services.AuthService
@@ -39,30 +35,6 @@ type LoginResponse struct {
 	Message string `json:"message"`
 }
 
-// isSecureRequest checks if the request should use secure cookies
-// It considers both the environment and the actual request protocol
-func isSecureRequest(c *gin.Context) bool {
-	// In development mode, never use secure cookies
-	if os.Getenv("ENVIRONMENT") != "production" {
-		return false
-	}
-
-	// Check if request came over HTTPS (directly or via proxy)
-	// X-Forwarded-Proto is set by reverse proxies (nginx, etc.)
-	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "https" {
-		return true
-	}
-
-	// Check the actual request scheme
-	if c.Request.TLS != nil {
-		return true
-	}
-
-	// In production but not HTTPS, still don't set Secure flag
-	// This allows HTTP deployments to work (not recommended but functional)
-	return false
-}
-
 // Login handles user login
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
@@ -71,45 +43,74 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.authService.Login(req.Username, req.Password)
+	token, err := h.authService.Login(req.Username, req.Password, c.ClientIP())
 	if err != nil {
 		if err == services.ErrInvalidCredentials {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 			return
 		}
+		if errors.Is(err, services.ErrLoginLocked) {
+			// Round up so clients never retry a moment too early
+			retryAfter := int((h.authService.LoginLockedFor(req.Username, c.ClientIP()) + time.Second - 1) / time.Second)
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusLocked, gin.H{
+				"error":       "Too many failed login attempts, please try again later",
+				"retry_after": retryAfter,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	// Set httpOnly cookie with the token
-	// Secure flag is set based on actual request protocol
-	secure := isSecureRequest(c)
-	c.SetSameSite(http.SameSiteLaxMode)
-	c.SetCookie(
-		TokenCookieName,    // name
-		token,              // value
-		TokenCookieMaxAge,  // maxAge (24 hours)
-		"/",                // path
-		"",                 // domain (empty = current domain)
-		secure,             // secure (HTTPS only when actually using HTTPS)
-		true,               // httpOnly (not accessible via JavaScript)
-	)
+	// Set httpOnly cookie with the token; it lives as long as the token itself
+	middleware.SetTokenCookie(c, token, int(h.authService.TokenTTL().Seconds()))
 
 	c.JSON(http.StatusOK, LoginResponse{Message: "Login successful"})
 }
 
+// RefreshResponse represents the token refresh response
+type RefreshResponse struct {
+	Message   string    `json:"message"`
+	Token     string    `json:"token,omitempty"` // Only returned to clients using the Authorization header
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Refresh exchanges the current, unexpired token for a new one and revokes the old token
+// POST /api/auth/refresh
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	token := c.GetString(middleware.TokenContextKey)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	newToken, expiresAt, err := h.authService.RefreshToken(token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidToken) || errors.Is(err, services.ErrTokenExpired) || errors.Is(err, services.ErrTokenRevoked) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	middleware.SetTokenCookie(c, newToken, int(time.Until(expiresAt).Seconds()))
+
+	resp := RefreshResponse{Message: "Token refreshed", ExpiresAt: expiresAt}
+	if !c.GetBool(middleware.TokenFromCookieContextKey) {
+		resp.Token = newToken
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 // Logout handles user logout
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Clear the cookie by setting maxAge to -1
-	c.SetCookie(
-		TokenCookieName,
-		"",
-		-1,
-		"/",
-		"",
-		isSecureRequest(c),
-		true,
-	)
+	middleware.SetTokenCookie(c, "", -1)
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
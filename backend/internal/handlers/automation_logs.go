@@ -1,24 +1,48 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"cc-platform/internal/constants"
 	"cc-platform/internal/models"
+	"cc-platform/internal/monitoring"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// automationLogSubscriber delivers newly written automation logs
+type automationLogSubscriber interface {
+	Subscribe(containerID uint) (<-chan models.AutomationLog, func())
+}
+
 // AutomationLogsHandler handles automation log API requests.
 type AutomationLogsHandler struct {
-	db *gorm.DB
+	db                *gorm.DB
+	broadcaster       automationLogSubscriber
+	heartbeatInterval time.Duration
 }
 
 // NewAutomationLogsHandler creates a new automation logs handler.
-func NewAutomationLogsHandler(db *gorm.DB) *AutomationLogsHandler {
-	return &AutomationLogsHandler{db: db}
+// broadcaster feeds the live stream endpoint; nil leaves it unavailable.
+func NewAutomationLogsHandler(db *gorm.DB, broadcaster *monitoring.AutomationLogBroadcaster) *AutomationLogsHandler {
+	h := &AutomationLogsHandler{
+		db:                db,
+		heartbeatInterval: constants.SSEHeartbeatInterval,
+	}
+	// Assign only a non-nil pointer so the nil check in the stream sees a nil interface
+	if broadcaster != nil {
+		h.broadcaster = broadcaster
+	}
+	return h
 }
 
 // LogsResponse represents the response for listing logs.
@@ -30,23 +54,151 @@ type LogsResponse struct {
 	TotalPages int                    `json:"total_pages"`
 }
 
+// automationLogFilter holds the filters shared by list, stats and export.
+type automationLogFilter struct {
+	containerID *uint64
+	strategy    string
+	result      string
+	level       string
+	from        *time.Time
+	until       *time.Time
+}
+
+// automationLevelResults maps a log level to the results it covers.
+// Automation logs have no level column, so the level is derived from the result.
+var automationLevelResults = map[string][]string{
+	models.LogLevelInfo:  {models.AutomationResultSuccess},
+	models.LogLevelWarn:  {models.AutomationResultSkipped},
+	models.LogLevelError: {models.AutomationResultFailed},
+}
+
+// automationLogLevel returns the level derived from an automation result.
+func automationLogLevel(result string) string {
+	switch result {
+	case models.AutomationResultFailed:
+		return models.LogLevelError
+	case models.AutomationResultSkipped:
+		return models.LogLevelWarn
+	default:
+		return models.LogLevelInfo
+	}
+}
+
+// parseLogTime parses a filter timestamp given as Unix seconds, RFC3339 or YYYY-MM-DD.
+// A bare date used as an upper bound covers the whole day.
+func parseLogTime(value string, endOfDay bool) (time.Time, error) {
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(ts, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use Unix seconds, RFC3339 or YYYY-MM-DD", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// parseAutomationLogFilter reads the shared filter query params.
+// "to" is accepted as an alias of "until".
+func parseAutomationLogFilter(c *gin.Context) (automationLogFilter, error) {
+	var filter automationLogFilter
+
+	if v := c.Query("container_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid container_id %q", v)
+		}
+		filter.containerID = &id
+	}
+
+	filter.strategy = c.Query("strategy")
+	filter.result = c.Query("result")
+
+	if v := strings.ToLower(c.Query("level")); v != "" {
+		if _, ok := automationLevelResults[v]; !ok {
+			return filter, fmt.Errorf("invalid level %q: must be info, warn or error", v)
+		}
+		filter.level = v
+	}
+
+	if v := c.Query("from"); v != "" {
+		t, err := parseLogTime(v, false)
+		if err != nil {
+			return filter, err
+		}
+		filter.from = &t
+	}
+
+	until := c.Query("until")
+	if until == "" {
+		until = c.Query("to")
+	}
+	if until != "" {
+		t, err := parseLogTime(until, true)
+		if err != nil {
+			return filter, err
+		}
+		filter.until = &t
+	}
+
+	if filter.from != nil && filter.until != nil && filter.from.After(*filter.until) {
+		return filter, fmt.Errorf("from must not be after until")
+	}
+
+	return filter, nil
+}
+
+// apply adds the filter conditions to query.
+func (f automationLogFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.containerID != nil {
+		query = query.Where("container_id = ?", *f.containerID)
+	}
+	if f.strategy != "" {
+		query = query.Where("strategy_type = ?", f.strategy)
+	}
+	if f.result != "" {
+		query = query.Where("result = ?", f.result)
+	}
+	if f.level != "" {
+		query = query.Where("result IN ?", automationLevelResults[f.level])
+	}
+	if f.from != nil {
+		query = query.Where("created_at >= ?", *f.from)
+	}
+	if f.until != nil {
+		query = query.Where("created_at <= ?", *f.until)
+	}
+	return query
+}
+
+// filteredQuery returns a new automation log query with the filter applied.
+func (h *AutomationLogsHandler) filteredQuery(filter automationLogFilter) *gorm.DB {
+	return filter.apply(h.db.Model(&models.AutomationLog{}))
+}
+
 // ListLogs returns automation logs with filtering and pagination.
 // GET /api/logs/automation
 // Query params:
 // - container_id: filter by container ID
 // - strategy: filter by strategy type
 // - result: filter by result (success, failed, skipped)
-// - from: filter logs from this timestamp (Unix)
-// - to: filter logs until this timestamp (Unix)
+// - level: filter by level derived from result (info, warn, error)
+// - from: filter logs from this time (Unix, RFC3339 or YYYY-MM-DD)
+// - until: filter logs until this time (Unix, RFC3339 or YYYY-MM-DD; "to" also accepted)
 // - page: page number (default 1)
 // - page_size: items per page (default 20, max 100)
 func (h *AutomationLogsHandler) ListLogs(c *gin.Context) {
-	// Parse query parameters
-	containerIDStr := c.Query("container_id")
-	strategy := c.Query("strategy")
-	result := c.Query("result")
-	fromStr := c.Query("from")
-	toStr := c.Query("to")
+	filter, err := parseAutomationLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	pageStr := c.DefaultQuery("page", "1")
 	pageSizeStr := c.DefaultQuery("page_size", "20")
 
@@ -64,44 +216,9 @@ func (h *AutomationLogsHandler) ListLogs(c *gin.Context) {
 		pageSize = 100
 	}
 
-	// Build query
-	query := h.db.Model(&models.AutomationLog{})
-
-	// Apply filters
-	if containerIDStr != "" {
-		containerID, err := strconv.ParseUint(containerIDStr, 10, 64)
-		if err == nil {
-			query = query.Where("container_id = ?", containerID)
-		}
-	}
-
-	if strategy != "" {
-		query = query.Where("strategy_type = ?", strategy)
-	}
-
-	if result != "" {
-		query = query.Where("result = ?", result)
-	}
-
-	if fromStr != "" {
-		fromTimestamp, err := strconv.ParseInt(fromStr, 10, 64)
-		if err == nil {
-			fromTime := time.Unix(fromTimestamp, 0)
-			query = query.Where("created_at >= ?", fromTime)
-		}
-	}
-
-	if toStr != "" {
-		toTimestamp, err := strconv.ParseInt(toStr, 10, 64)
-		if err == nil {
-			toTime := time.Unix(toTimestamp, 0)
-			query = query.Where("created_at <= ?", toTime)
-		}
-	}
-
 	// Get total count
 	var total int64
-	if err := query.Count(&total).Error; err != nil {
+	if err := h.filteredQuery(filter).Count(&total).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count logs"})
 		return
 	}
@@ -112,7 +229,7 @@ func (h *AutomationLogsHandler) ListLogs(c *gin.Context) {
 
 	// Get logs
 	var logs []models.AutomationLog
-	if err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&logs).Error; err != nil {
+	if err := h.filteredQuery(filter).Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&logs).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch logs"})
 		return
 	}
@@ -126,6 +243,71 @@ func (h *AutomationLogsHandler) ListLogs(c *gin.Context) {
 	})
 }
 
+// StreamLogs pushes new automation logs to the client as Server-Sent Events.
+// Each log is sent as a "log" event with the JSON entry as data; idle streams get a heartbeat comment.
+// GET /api/logs/automation/stream
+// Query params:
+// - container_id: only stream logs for this container
+func (h *AutomationLogsHandler) StreamLogs(c *gin.Context) {
+	var containerID uint
+	if v := c.Query("container_id"); v != "" {
+		id, err := parseID(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+			return
+		}
+		containerID = id
+	}
+
+	if h.broadcaster == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Log streaming is not available"})
+		return
+	}
+
+	logs, unsubscribe := h.broadcaster.Subscribe(containerID)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stop nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// An initial comment lets the client know the subscription is active
+	if _, err := io.WriteString(c.Writer, ": connected\n\n"); err != nil {
+		return
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case entry, ok := <-logs:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(&entry)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: log\ndata: %s\n\n", entry.ID, data); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
 // GetLog returns a single automation log by ID.
 // GET /api/logs/automation/:id
 func (h *AutomationLogsHandler) GetLog(c *gin.Context) {
@@ -184,9 +366,14 @@ func (h *AutomationLogsHandler) GetLogsByContainer(c *gin.Context) {
 }
 
 // GetLogStats returns statistics about automation logs.
+// It accepts the same filters as ListLogs so the numbers match the filtered list.
 // GET /api/logs/automation/stats
 func (h *AutomationLogsHandler) GetLogStats(c *gin.Context) {
-	containerIDStr := c.Query("container_id")
+	filter, err := parseAutomationLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	type StrategyStats struct {
 		StrategyType string `json:"strategy_type"`
@@ -195,37 +382,29 @@ func (h *AutomationLogsHandler) GetLogStats(c *gin.Context) {
 		FailedCount  int64  `json:"failed_count"`
 	}
 
-	query := h.db.Model(&models.AutomationLog{})
-	if containerIDStr != "" {
-		containerID, err := strconv.ParseUint(containerIDStr, 10, 64)
-		if err == nil {
-			query = query.Where("container_id = ?", containerID)
-		}
-	}
-
 	// Get total count
 	var totalCount int64
-	query.Count(&totalCount)
+	h.filteredQuery(filter).Count(&totalCount)
 
 	// Get counts by strategy
 	var strategyStats []StrategyStats
-	h.db.Model(&models.AutomationLog{}).
-		Select("strategy_type, COUNT(*) as count, "+
-			"SUM(CASE WHEN result = 'success' THEN 1 ELSE 0 END) as success_count, "+
+	h.filteredQuery(filter).
+		Select("strategy_type, COUNT(*) as count, " +
+			"SUM(CASE WHEN result = 'success' THEN 1 ELSE 0 END) as success_count, " +
 			"SUM(CASE WHEN result = 'failed' THEN 1 ELSE 0 END) as failed_count").
 		Group("strategy_type").
 		Scan(&strategyStats)
 
-	// Get recent activity (last 24 hours)
+	// Get recent activity (last 24 hours) within the filter
 	var recentCount int64
-	h.db.Model(&models.AutomationLog{}).
+	h.filteredQuery(filter).
 		Where("created_at >= ?", time.Now().Add(-24*time.Hour)).
 		Count(&recentCount)
 
 	c.JSON(http.StatusOK, gin.H{
-		"total_count":     totalCount,
-		"recent_count":    recentCount,
-		"strategy_stats":  strategyStats,
+		"total_count":    totalCount,
+		"recent_count":   recentCount,
+		"strategy_stats": strategyStats,
 	})
 }
 
@@ -252,51 +431,146 @@ func (h *AutomationLogsHandler) DeleteOldLogs(c *gin.Context) {
 	})
 }
 
-// ExportLogs exports logs as JSON.
+// automationLogCSVHeader lists the columns of a CSV export.
+var automationLogCSVHeader = []string{
+	"id", "created_at", "container_id", "session_id", "strategy_type",
+	"action_taken", "result", "level", "command", "error_message",
+}
+
+// automationLogCSVRecord formats a log as a CSV row matching automationLogCSVHeader.
+func automationLogCSVRecord(entry *models.AutomationLog) []string {
+	return []string{
+		strconv.FormatUint(uint64(entry.ID), 10),
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatUint(uint64(entry.ContainerID), 10),
+		entry.SessionID,
+		entry.StrategyType,
+		entry.ActionTaken,
+		entry.Result,
+		automationLogLevel(entry.Result),
+		entry.Command,
+		entry.ErrorMessage,
+	}
+}
+
+// exportFlushEvery is how many rows are written between flushes while exporting.
+const exportFlushEvery = 200
+
+// ExportLogs exports logs as JSON or CSV.
+// Rows are streamed from the database as they are read instead of being loaded at once.
 // GET /api/logs/automation/export
+// Query params: the ListLogs filters, plus format (json or csv, default json)
 func (h *AutomationLogsHandler) ExportLogs(c *gin.Context) {
-	containerIDStr := c.Query("container_id")
-	fromStr := c.Query("from")
-	toStr := c.Query("to")
+	filter, err := parseAutomationLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	query := h.db.Model(&models.AutomationLog{})
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
 
-	if containerIDStr != "" {
-		containerID, err := strconv.ParseUint(containerIDStr, 10, 64)
-		if err == nil {
-			query = query.Where("container_id = ?", containerID)
-		}
+	rows, err := h.filteredQuery(filter).Order("created_at DESC").Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export logs"})
+		return
 	}
+	defer rows.Close()
 
-	if fromStr != "" {
-		fromTimestamp, err := strconv.ParseInt(fromStr, 10, 64)
-		if err == nil {
-			fromTime := time.Unix(fromTimestamp, 0)
-			query = query.Where("created_at >= ?", fromTime)
+	// Set headers for file download
+	c.Header("Content-Disposition", "attachment; filename=automation_logs."+format)
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		c.Header("Content-Type", "application/json")
+	}
+	c.Status(http.StatusOK)
+
+	next := func(entry *models.AutomationLog) (bool, error) {
+		if !rows.Next() {
+			return false, rows.Err()
 		}
+		*entry = models.AutomationLog{}
+		return true, h.db.ScanRows(rows, entry)
+	}
+
+	if format == "csv" {
+		err = writeAutomationLogsCSV(c.Writer, next)
+	} else {
+		err = writeAutomationLogsJSON(c.Writer, next)
+	}
+	if err != nil {
+		// Headers are already sent; the truncated body is all we can report
+		log.Printf("[AutomationLogsHandler] Export failed: %v", err)
+	}
+}
+
+// writeAutomationLogsCSV writes a header row followed by one row per log.
+func writeAutomationLogsCSV(w gin.ResponseWriter, next func(*models.AutomationLog) (bool, error)) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(automationLogCSVHeader); err != nil {
+		return err
 	}
 
-	if toStr != "" {
-		toTimestamp, err := strconv.ParseInt(toStr, 10, 64)
-		if err == nil {
-			toTime := time.Unix(toTimestamp, 0)
-			query = query.Where("created_at <= ?", toTime)
+	var entry models.AutomationLog
+	for count := 1; ; count++ {
+		ok, err := next(&entry)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if err := writer.Write(automationLogCSVRecord(&entry)); err != nil {
+			return err
+		}
+		if count%exportFlushEvery == 0 {
+			writer.Flush()
+			w.Flush()
 		}
 	}
 
-	var logs []models.AutomationLog
-	if err := query.Order("created_at DESC").Limit(10000).Find(&logs).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export logs"})
-		return
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeAutomationLogsJSON writes {"exported_at":..., "logs":[...], "count":N}.
+// The count comes last because it is only known once every row has been written.
+func writeAutomationLogsJSON(w gin.ResponseWriter, next func(*models.AutomationLog) (bool, error)) error {
+	exportedAt, _ := json.Marshal(time.Now().Format(time.RFC3339))
+	if _, err := fmt.Fprintf(w, `{"exported_at":%s,"logs":[`, exportedAt); err != nil {
+		return err
 	}
 
-	// Set headers for file download
-	c.Header("Content-Disposition", "attachment; filename=automation_logs.json")
-	c.Header("Content-Type", "application/json")
+	var entry models.AutomationLog
+	count := 0
+	for {
+		ok, err := next(&entry)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		data, err := json.Marshal(&entry)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			data = append([]byte{','}, data...)
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushEvery == 0 {
+			w.Flush()
+		}
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"exported_at": time.Now().Format(time.RFC3339),
-		"count":       len(logs),
-		"logs":        logs,
-	})
+	_, err := fmt.Fprintf(w, `],"count":%d}`, count)
+	return err
 }
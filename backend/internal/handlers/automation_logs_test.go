@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+	"cc-platform/internal/monitoring"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var automationLogsTestDBCounter int
+
+// setupAutomationLogsTest creates a router backed by an in-memory database seeded with logs
+func setupAutomationLogsTest(t *testing.T, logs []models.AutomationLog) *gin.Engine {
+	t.Helper()
+
+	automationLogsTestDBCounter++
+	dbName := fmt.Sprintf("file:automationlogs%d?mode=memory&cache=shared", automationLogsTestDBCounter)
+	db, err := gorm.Open(sqlite.Open(dbName), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.AutomationLog{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	for i := range logs {
+		if err := db.Create(&logs[i]).Error; err != nil {
+			t.Fatalf("failed to seed log: %v", err)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewAutomationLogsHandler(db, nil)
+	router.GET("/api/logs/automation", handler.ListLogs)
+	router.GET("/api/logs/automation/stats", handler.GetLogStats)
+	router.GET("/api/logs/automation/export", handler.ExportLogs)
+	return router
+}
+
+func automationLogAt(containerID uint, result string, at time.Time) models.AutomationLog {
+	entry := models.AutomationLog{
+		ContainerID:  containerID,
+		StrategyType: models.StrategyWebhook,
+		ActionTaken:  "notify",
+		Result:       result,
+	}
+	entry.CreatedAt = at
+	return entry
+}
+
+func seededAutomationLogs() []models.AutomationLog {
+	base := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	return []models.AutomationLog{
+		automationLogAt(1, models.AutomationResultSuccess, base.AddDate(0, 0, -2)),
+		automationLogAt(1, models.AutomationResultFailed, base.AddDate(0, 0, -1)),
+		automationLogAt(2, models.AutomationResultFailed, base),
+		automationLogAt(1, models.AutomationResultSkipped, base.AddDate(0, 0, 1)),
+	}
+}
+
+func getAutomationLogs(t *testing.T, router *gin.Engine, url string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	return w
+}
+
+func TestListLogs_FiltersByRangeAndLevel(t *testing.T) {
+	router := setupAutomationLogsTest(t, seededAutomationLogs())
+
+	tests := []struct {
+		name  string
+		query string
+		want  int64
+	}{
+		{"date range", "from=2024-03-09&until=2024-03-10", 2},
+		{"rfc3339 upper bound", "until=2024-03-09T12:00:00Z", 2},
+		{"unix lower bound", fmt.Sprintf("from=%d", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC).Unix()), 2},
+		{"legacy to param", "to=2024-03-08T12:00:00Z", 1},
+		{"level error", "level=error", 2},
+		{"level and container", "level=error&container_id=1", 1},
+		{"level warn", "level=warn", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := getAutomationLogs(t, router, "/api/logs/automation?"+tt.query)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var resp LogsResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Total != tt.want || len(resp.Logs) != int(tt.want) {
+				t.Errorf("Expected %d logs, got total=%d len=%d", tt.want, resp.Total, len(resp.Logs))
+			}
+		})
+	}
+}
+
+func TestListLogs_RejectsInvalidFilters(t *testing.T) {
+	router := setupAutomationLogsTest(t, nil)
+
+	for _, query := range []string{"level=debug", "from=yesterday", "container_id=abc", "from=2024-03-10&until=2024-03-01"} {
+		w := getAutomationLogs(t, router, "/api/logs/automation?"+query)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %q, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestGetLogStats_UsesSameRange(t *testing.T) {
+	router := setupAutomationLogsTest(t, seededAutomationLogs())
+
+	w := getAutomationLogs(t, router, "/api/logs/automation/stats?from=2024-03-09&until=2024-03-10")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+
+	var resp struct {
+		TotalCount    int64 `json:"total_count"`
+		StrategyStats []struct {
+			Count       int64 `json:"count"`
+			FailedCount int64 `json:"failed_count"`
+		} `json:"strategy_stats"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.TotalCount != 2 {
+		t.Errorf("Expected total_count 2, got %d", resp.TotalCount)
+	}
+	if len(resp.StrategyStats) != 1 || resp.StrategyStats[0].Count != 2 || resp.StrategyStats[0].FailedCount != 2 {
+		t.Errorf("Expected strategy stats to match the range, got %+v", resp.StrategyStats)
+	}
+}
+
+func TestExportLogs_CSV(t *testing.T) {
+	logs := seededAutomationLogs()
+	logs[1].Command = "echo \"hi\", there"
+	logs[1].ErrorMessage = "line one\nline two"
+	router := setupAutomationLogsTest(t, logs)
+
+	w := getAutomationLogs(t, router, "/api/logs/automation/export?format=csv&container_id=1&level=error")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Expected CSV content type, got %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "automation_logs.csv") {
+		t.Errorf("Expected csv filename, got %q", cd)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("Export is not valid CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected header and 1 row, got %d records", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(automationLogCSVHeader, ",") {
+		t.Errorf("Unexpected header: %v", records[0])
+	}
+
+	row := records[1]
+	if row[1] != "2024-03-09T12:00:00Z" || row[6] != models.AutomationResultFailed || row[7] != models.LogLevelError {
+		t.Errorf("Unexpected row: %v", row)
+	}
+	if row[8] != "echo \"hi\", there" || row[9] != "line one\nline two" {
+		t.Errorf("Expected quoted fields to round-trip, got %q / %q", row[8], row[9])
+	}
+}
+
+func TestExportLogs_JSON(t *testing.T) {
+	router := setupAutomationLogsTest(t, seededAutomationLogs())
+
+	w := getAutomationLogs(t, router, "/api/logs/automation/export?until=2024-03-09")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+
+	var resp struct {
+		ExportedAt string                 `json:"exported_at"`
+		Count      int                    `json:"count"`
+		Logs       []models.AutomationLog `json:"logs"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Export is not valid JSON: %v\n%s", err, w.Body.String())
+	}
+	if resp.Count != 2 || len(resp.Logs) != 2 || resp.ExportedAt == "" {
+		t.Errorf("Expected 2 logs, got count=%d len=%d", resp.Count, len(resp.Logs))
+	}
+	if !resp.Logs[0].CreatedAt.After(resp.Logs[1].CreatedAt) {
+		t.Error("Expected newest log first")
+	}
+
+	w = getAutomationLogs(t, router, "/api/logs/automation/export?format=xml")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown format, got %d", w.Code)
+	}
+}
+
+// readSSEEvent reads lines until a complete event (ending with a blank line) has been received
+func readSSEEvent(t *testing.T, reader *bufio.Reader) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read SSE stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestStreamLogs_EmitsFilteredEntriesAndHeartbeats(t *testing.T) {
+	broadcaster := monitoring.NewAutomationLogBroadcaster()
+	handler := &AutomationLogsHandler{broadcaster: broadcaster, heartbeatInterval: 50 * time.Millisecond}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/logs/automation/stream", handler.StreamLogs)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/logs/automation/stream?container_id=2")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %q", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+
+	if first := readSSEEvent(t, reader); len(first) != 1 || first[0] != ": connected" {
+		t.Fatalf("Expected connected comment, got %v", first)
+	}
+
+	other := automationLogAt(1, models.AutomationResultSuccess, time.Now())
+	other.ID = 10
+	broadcaster.Publish(other)
+	wanted := automationLogAt(2, models.AutomationResultFailed, time.Now())
+	wanted.ID = 11
+	broadcaster.Publish(wanted)
+
+	event := readSSEEvent(t, reader)
+	if len(event) != 3 || event[0] != "id: 11" || event[1] != "event: log" {
+		t.Fatalf("Expected log event for container 2, got %v", event)
+	}
+	var entry models.AutomationLog
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(event[2], "data: ")), &entry); err != nil {
+		t.Fatalf("invalid event data: %v", err)
+	}
+	if entry.ContainerID != 2 || entry.Result != models.AutomationResultFailed {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+
+	if heartbeat := readSSEEvent(t, reader); len(heartbeat) != 1 || heartbeat[0] != ": heartbeat" {
+		t.Errorf("Expected heartbeat comment, got %v", heartbeat)
+	}
+
+	// Disconnecting must remove the subscriber
+	resp.Body.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for broadcaster.SubscriberCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected subscriber to be removed after disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStreamLogs_NilBroadcasterIsUnavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/logs/automation/stream", NewAutomationLogsHandler(nil, nil).StreamLogs)
+
+	w := getAutomationLogs(t, router, "/api/logs/automation/stream")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 without a broadcaster, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cc-platform/internal/middleware"
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BackupHandler handles backup export and import
+type BackupHandler struct {
+	service *services.BackupService
+}
+
+// NewBackupHandler creates a new BackupHandler
+func NewBackupHandler(service *services.BackupService) *BackupHandler {
+	return &BackupHandler{service: service}
+}
+
+// RegisterRoutes registers the backup routes; importBodyLimit caps the size of an imported backup
+func (h *BackupHandler) RegisterRoutes(rg *gin.RouterGroup, importBodyLimit gin.HandlerFunc) {
+	rg.GET("/export/backup", h.ExportBackup)
+	rg.POST("/import/backup", importBodyLimit, h.ImportBackup)
+}
+
+// ExportBackup downloads templates, bundles, command profiles and container specs as one JSON document
+// GET /api/export/backup
+func (h *BackupHandler) ExportBackup(c *gin.Context) {
+	backup, err := h.service.Export()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export backup: " + err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("cc-platform-backup-%s.json", backup.ExportedAt.Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.JSON(http.StatusOK, backup)
+}
+
+// ImportBackup restores a backup and reports the outcome per section
+// POST /api/import/backup
+func (h *BackupHandler) ImportBackup(c *gin.Context) {
+	var backup services.Backup
+	if err := c.ShouldBindJSON(&backup); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": middleware.BodyTooLargeMessage(maxBytesErr.Limit)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.Import(c.Request.Context(), &backup)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidBackup) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import backup: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestImportBackup_BodyTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewBackupHandler(nil).RegisterRoutes(router.Group("/api"), middleware.MaxBodySize(1024))
+
+	// Without a Content-Length the limit is hit while the JSON is decoded
+	body := `{"templates": [{"name": "` + strings.Repeat("x", 4096) + `"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/import/backup", strings.NewReader(body))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "1024 byte limit") {
+		t.Errorf("Expected 413 with the limit, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -34,6 +35,14 @@ func (h *ConfigProfileHandler) RegisterRoutes(rg *gin.RouterGroup) {
 		tokens.PUT("/:id/default", h.SetDefaultGitHubToken)
 	}
 
+	// Private registry logins for custom container images
+	registries := rg.Group("/registry-credentials")
+	{
+		registries.GET("", h.ListRegistryCredentials)
+		registries.POST("", h.SaveRegistryCredential)
+		registries.DELETE("/:id", h.DeleteRegistryCredential)
+	}
+
 	// Env Profiles
 	envProfiles := rg.Group("/env-profiles")
 	{
@@ -150,6 +159,65 @@ func (h *ConfigProfileHandler) SetDefaultGitHubToken(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Default token set successfully"})
 }
 
+// ==================== Registry Credential Handlers ====================
+
+// ListRegistryCredentials returns all registry credentials without their passwords
+// GET /api/settings/registry-credentials
+func (h *ConfigProfileHandler) ListRegistryCredentials(c *gin.Context) {
+	creds, err := h.service.ListRegistryCredentials()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list registry credentials"})
+		return
+	}
+	c.JSON(http.StatusOK, creds)
+}
+
+// SaveRegistryCredential stores the login for an allowlisted registry, replacing an existing one
+// POST /api/settings/registry-credentials
+func (h *ConfigProfileHandler) SaveRegistryCredential(c *gin.Context) {
+	var input services.SaveRegistryCredentialInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: registry, username and password are required"})
+		return
+	}
+
+	cred, err := h.service.SaveRegistryCredential(input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRegistryCredential):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrRegistryNotAllowed):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save registry credential"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, cred)
+}
+
+// DeleteRegistryCredential deletes a registry credential
+// DELETE /api/settings/registry-credentials/:id
+func (h *ConfigProfileHandler) DeleteRegistryCredential(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.service.DeleteRegistryCredential(uint(id)); err != nil {
+		if errors.Is(err, services.ErrRegistryCredentialNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Registry credential not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete registry credential"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Registry credential deleted successfully"})
+}
+
 // ==================== Env Profile Handlers ====================
 
 // ListEnvProfiles returns all environment variable profiles
@@ -15,6 +15,7 @@ import (
 // ConfigTemplateHandler handles Claude config template endpoints
 type ConfigTemplateHandler struct {
 	service services.ConfigTemplateService
+	usage   *services.TemplateUsageService
 }
 
 // NewConfigTemplateHandler creates a new ConfigTemplateHandler
@@ -24,13 +25,22 @@ func NewConfigTemplateHandler(service services.ConfigTemplateService) *ConfigTem
 	}
 }
 
+// SetUsageService sets the usage service that reports how templates are used by containers
+func (h *ConfigTemplateHandler) SetUsageService(usage *services.TemplateUsageService) {
+	h.usage = usage
+}
+
 // RegisterRoutes registers all config template routes
 func (h *ConfigTemplateHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	configs := rg.Group("/claude-configs")
 	{
 		configs.POST("", h.CreateTemplate)
 		configs.GET("", h.ListTemplates)
+		configs.DELETE("", h.DeleteTemplatesByType)
+		configs.POST("/bulk-delete", h.BulkDeleteTemplates)
+		configs.GET("/types", h.ListTypes)
 		configs.GET("/:id", h.GetTemplate)
+		configs.GET("/:id/usage", h.GetTemplateUsage)
 		configs.PUT("/:id", h.UpdateTemplate)
 		configs.DELETE("/:id", h.DeleteTemplate)
 	}
@@ -41,37 +51,28 @@ func (h *ConfigTemplateHandler) RegisterRoutes(rg *gin.RouterGroup) {
 func (h *ConfigTemplateHandler) CreateTemplate(c *gin.Context) {
 	var input services.CreateConfigTemplateInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		writeInvalidRequest(c, "Invalid request body: "+err.Error(), err)
 		return
 	}
 
 	template, err := h.service.Create(input)
 	if err != nil {
-		// Handle specific error types
-		if errors.Is(err, services.ErrInvalidConfigType) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		if errors.Is(err, services.ErrDuplicateTemplateName) || strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
-		}
-		// Validation errors (MCP JSON, etc.)
-		if strings.Contains(err.Error(), "invalid MCP configuration") ||
-			strings.Contains(err.Error(), "invalid frontmatter") ||
-			strings.Contains(err.Error(), "content cannot be empty") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template: " + err.Error()})
+		writeTemplateError(c, err, "Failed to create template: "+err.Error())
 		return
 	}
 
 	c.JSON(http.StatusCreated, template)
 }
 
-// ListTemplates returns all config templates, optionally filtered by type
-// GET /api/claude-configs?type=SKILL
+// ListTypes returns every config type with its content format and validation hints
+// GET /api/claude-configs/types
+func (h *ConfigTemplateHandler) ListTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, services.ConfigTypeSchemas())
+}
+
+// ListTemplates returns all config templates with their usage, optionally filtered by type or
+// to templates no container uses
+// GET /api/claude-configs?type=SKILL&unused=true
 func (h *ConfigTemplateHandler) ListTemplates(c *gin.Context) {
 	var configType *models.ConfigType
 
@@ -80,7 +81,7 @@ func (h *ConfigTemplateHandler) ListTemplates(c *gin.Context) {
 	if typeParam != "" {
 		ct := models.ConfigType(typeParam)
 		if !ct.IsValid() {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid config_type, must be one of: CLAUDE_MD, SKILL, MCP, COMMAND"})
+			writeServiceError(c, services.ErrInvalidConfigType)
 			return
 		}
 		configType = &ct
@@ -88,33 +89,65 @@ func (h *ConfigTemplateHandler) ListTemplates(c *gin.Context) {
 
 	templates, err := h.service.List(configType)
 	if err != nil {
-		if errors.Is(err, services.ErrInvalidConfigType) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeTemplateError(c, err, "Failed to list templates")
+		return
+	}
+
+	if h.usage != nil {
+		usage, err := h.usage.ListUsage()
+		if err != nil {
+			writeAPIError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to load template usage")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list templates"})
-		return
+		unusedOnly := c.Query("unused") == "true"
+		filtered := make([]models.ClaudeConfigTemplate, 0, len(templates))
+		for _, template := range templates {
+			template.Usage = usage[template.ID]
+			if unusedOnly && (template.Usage == nil || !template.Usage.Unused) {
+				continue
+			}
+			filtered = append(filtered, template)
+		}
+		templates = filtered
 	}
 
 	c.JSON(http.StatusOK, templates)
 }
 
+// GetTemplateUsage returns how many containers use a template and how often it was injected
+// GET /api/claude-configs/:id/usage
+func (h *ConfigTemplateHandler) GetTemplateUsage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid ID")
+		return
+	}
+	if h.usage == nil {
+		writeAPIError(c, http.StatusServiceUnavailable, ErrCodeServiceUnavailable, "Template usage is not available")
+		return
+	}
+
+	usage, err := h.usage.GetUsage(uint(id))
+	if err != nil {
+		writeTemplateError(c, err, "Failed to get template usage")
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
 // GetTemplate returns a single config template by ID
 // GET /api/claude-configs/:id
 func (h *ConfigTemplateHandler) GetTemplate(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid ID")
 		return
 	}
 
 	template, err := h.service.GetByID(uint(id))
 	if err != nil {
-		if errors.Is(err, services.ErrTemplateNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get template"})
+		writeTemplateError(c, err, "Failed to get template")
 		return
 	}
 
@@ -126,34 +159,19 @@ func (h *ConfigTemplateHandler) GetTemplate(c *gin.Context) {
 func (h *ConfigTemplateHandler) UpdateTemplate(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid ID")
 		return
 	}
 
 	var input services.UpdateConfigTemplateInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		writeInvalidRequest(c, "Invalid request body: "+err.Error(), err)
 		return
 	}
 
 	template, err := h.service.Update(uint(id), input)
 	if err != nil {
-		if errors.Is(err, services.ErrTemplateNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
-			return
-		}
-		if errors.Is(err, services.ErrDuplicateTemplateName) || strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
-		}
-		// Validation errors
-		if strings.Contains(err.Error(), "invalid MCP configuration") ||
-			strings.Contains(err.Error(), "invalid frontmatter") ||
-			strings.Contains(err.Error(), "content cannot be empty") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update template"})
+		writeTemplateError(c, err, "Failed to update template")
 		return
 	}
 
@@ -165,18 +183,126 @@ func (h *ConfigTemplateHandler) UpdateTemplate(c *gin.Context) {
 func (h *ConfigTemplateHandler) DeleteTemplate(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid ID")
 		return
 	}
 
 	if err := h.service.Delete(uint(id)); err != nil {
-		if errors.Is(err, services.ErrTemplateNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		writeTemplateError(c, err, "Failed to delete template")
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// Bulk delete result values reported per template ID
+const (
+	BulkDeleteResultDeleted  = "deleted"
+	BulkDeleteResultNotFound = "not_found"
+	BulkDeleteResultFailed   = "failed"
+)
+
+// BulkDeleteTemplatesRequest represents the request to delete multiple templates
+type BulkDeleteTemplatesRequest struct {
+	IDs []uint `json:"ids" binding:"required"`
+}
+
+// BulkDeleteTemplatesResponse reports the outcome for each requested template ID
+type BulkDeleteTemplatesResponse struct {
+	Results map[uint]string `json:"results"`
+	Deleted int             `json:"deleted"`
+}
+
+// BulkDeleteTemplates deletes multiple config templates by ID
+// Missing IDs are reported as not_found and do not abort the remaining deletions
+// POST /api/claude-configs/bulk-delete
+func (h *ConfigTemplateHandler) BulkDeleteTemplates(c *gin.Context) {
+	var req BulkDeleteTemplatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeInvalidRequest(c, "Invalid request body, ids array required", err)
+		return
+	}
+
+	if len(req.IDs) == 0 {
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "At least one template ID is required")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.deleteTemplates(req.IDs))
+}
+
+// DeleteTemplatesByType deletes every template of a single config type
+// Requires confirm=true to guard against accidental mass deletion
+// DELETE /api/claude-configs?type=SKILL&confirm=true
+func (h *ConfigTemplateHandler) DeleteTemplatesByType(c *gin.Context) {
+	typeParam := c.Query("type")
+	if typeParam == "" {
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "type query parameter is required")
+		return
+	}
+
+	configType := models.ConfigType(typeParam)
+	if !configType.IsValid() {
+		writeServiceError(c, services.ErrInvalidConfigType)
+		return
+	}
+
+	if c.Query("confirm") != "true" {
+		writeAPIError(c, http.StatusBadRequest, "CONFIRMATION_REQUIRED", "Deleting all templates of a type requires confirm=true")
+		return
+	}
+
+	templates, err := h.service.List(&configType)
+	if err != nil {
+		writeAPIError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to list templates")
+		return
+	}
+
+	ids := make([]uint, len(templates))
+	for i, template := range templates {
+		ids[i] = template.ID
+	}
+
+	c.JSON(http.StatusOK, h.deleteTemplates(ids))
+}
+
+// deleteTemplates deletes each template independently and collects per-ID results
+func (h *ConfigTemplateHandler) deleteTemplates(ids []uint) BulkDeleteTemplatesResponse {
+	response := BulkDeleteTemplatesResponse{
+		Results: make(map[uint]string, len(ids)),
+	}
+
+	for _, id := range ids {
+		if err := h.service.Delete(id); err != nil {
+			if errors.Is(err, services.ErrTemplateNotFound) {
+				response.Results[id] = BulkDeleteResultNotFound
+			} else {
+				response.Results[id] = BulkDeleteResultFailed
+			}
+			continue
+		}
+		response.Results[id] = BulkDeleteResultDeleted
+		response.Deleted++
+	}
+
+	return response
+}
+
+// writeTemplateError answers with the code of a template service error. Content validation
+// errors carry no sentinel, so they are recognized by their message.
+func writeTemplateError(c *gin.Context, err error, fallback string) {
+	if status, code, message := serviceErrorStatus(err); code != ErrCodeInternal {
+		writeAPIError(c, status, code, message)
+		return
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "already exists"):
+		writeAPIError(c, http.StatusConflict, "DUPLICATE_TEMPLATE_NAME", msg)
+	case strings.Contains(msg, "invalid MCP configuration"), strings.Contains(msg, "invalid frontmatter"),
+		strings.Contains(msg, "content cannot be empty"):
+		writeAPIError(c, http.StatusBadRequest, "INVALID_TEMPLATE_CONTENT", msg)
+	default:
+		writeAPIError(c, http.StatusInternalServerError, ErrCodeInternal, fallback)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"sync"
 	"testing"
 
@@ -334,10 +335,10 @@ func TestCreateTemplate_ValidationError(t *testing.T) {
 				t.Errorf("Expected status %d, got %d. Body: %s", http.StatusBadRequest, w.Code, w.Body.String())
 			}
 
-			var response map[string]string
+			var response apiErrorResponse
 			json.Unmarshal(w.Body.Bytes(), &response)
-			if response["error"] == "" {
-				t.Error("Expected error message in response")
+			if response.Error.Code == "" || response.Error.Message == "" {
+				t.Error("Expected error code and message in response")
 			}
 		})
 	}
@@ -544,10 +545,10 @@ func TestListTemplates_InvalidTypeFilter(t *testing.T) {
 		t.Errorf("Expected status %d, got %d. Body: %s", http.StatusBadRequest, w.Code, w.Body.String())
 	}
 
-	var response map[string]string
+	var response apiErrorResponse
 	json.Unmarshal(w.Body.Bytes(), &response)
-	if response["error"] == "" {
-		t.Error("Expected error message in response")
+	if response.Error.Code != "INVALID_CONFIG_TYPE" {
+		t.Errorf("Expected INVALID_CONFIG_TYPE, got %q", response.Error.Code)
 	}
 }
 
@@ -608,10 +609,10 @@ func TestGetTemplate_NotFound(t *testing.T) {
 		t.Errorf("Expected status %d, got %d. Body: %s", http.StatusNotFound, w.Code, w.Body.String())
 	}
 
-	var response map[string]string
+	var response apiErrorResponse
 	json.Unmarshal(w.Body.Bytes(), &response)
-	if response["error"] != "template not found" {
-		t.Errorf("Expected error 'template not found', got %q", response["error"])
+	if response.Error.Code != "TEMPLATE_NOT_FOUND" || response.Error.Message != "template not found" {
+		t.Errorf("Expected TEMPLATE_NOT_FOUND, got %+v", response.Error)
 	}
 }
 
@@ -737,10 +738,10 @@ func TestUpdateTemplate_NotFound(t *testing.T) {
 		t.Errorf("Expected status %d, got %d. Body: %s", http.StatusNotFound, w.Code, w.Body.String())
 	}
 
-	var response map[string]string
+	var response apiErrorResponse
 	json.Unmarshal(w.Body.Bytes(), &response)
-	if response["error"] != "template not found" {
-		t.Errorf("Expected error 'template not found', got %q", response["error"])
+	if response.Error.Code != "TEMPLATE_NOT_FOUND" || response.Error.Message != "template not found" {
+		t.Errorf("Expected TEMPLATE_NOT_FOUND, got %+v", response.Error)
 	}
 }
 
@@ -839,10 +840,10 @@ func TestDeleteTemplate_NotFound(t *testing.T) {
 		t.Errorf("Expected status %d, got %d. Body: %s", http.StatusNotFound, w.Code, w.Body.String())
 	}
 
-	var response map[string]string
+	var response apiErrorResponse
 	json.Unmarshal(w.Body.Bytes(), &response)
-	if response["error"] != "template not found" {
-		t.Errorf("Expected error 'template not found', got %q", response["error"])
+	if response.Error.Code != "TEMPLATE_NOT_FOUND" || response.Error.Message != "template not found" {
+		t.Errorf("Expected TEMPLATE_NOT_FOUND, got %+v", response.Error)
 	}
 }
 
@@ -901,3 +902,145 @@ func TestDeleteTemplate_VerifyListAfterDelete(t *testing.T) {
 		t.Errorf("Expected remaining template to be 'template2', got %q", response[0].Name)
 	}
 }
+
+// ============================================================================
+// Bulk Delete Tests
+// ============================================================================
+
+// TestBulkDeleteTemplates_MixedExistingAndMissing tests that missing IDs don't abort the batch
+func TestBulkDeleteTemplates_MixedExistingAndMissing(t *testing.T) {
+	router, service := setupTestRouterWithMock()
+
+	service.Create(services.CreateConfigTemplateInput{
+		Name:       "bulk1",
+		ConfigType: models.ConfigTypeClaudeMD,
+		Content:    "# Content 1",
+	})
+	service.Create(services.CreateConfigTemplateInput{
+		Name:       "bulk2",
+		ConfigType: models.ConfigTypeCommand,
+		Content:    "# Content 2",
+	})
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"ids": []uint{1, 999, 2}})
+	req, _ := http.NewRequest("POST", "/api/claude-configs/bulk-delete", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response BulkDeleteTemplatesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Deleted != 2 {
+		t.Errorf("Expected 2 deleted, got %d", response.Deleted)
+	}
+	expected := map[uint]string{
+		1:   BulkDeleteResultDeleted,
+		2:   BulkDeleteResultDeleted,
+		999: BulkDeleteResultNotFound,
+	}
+	for id, want := range expected {
+		if got := response.Results[id]; got != want {
+			t.Errorf("Expected result %q for ID %d, got %q", want, id, got)
+		}
+	}
+
+	if _, err := service.GetByID(2); !errors.Is(err, services.ErrTemplateNotFound) {
+		t.Errorf("Expected template 2 to be deleted after missing ID 999")
+	}
+}
+
+// TestBulkDeleteTemplates_EmptyIDs tests 400 response for an empty ID list
+func TestBulkDeleteTemplates_EmptyIDs(t *testing.T) {
+	router, _ := setupTestRouterWithMock()
+
+	req, _ := http.NewRequest("POST", "/api/claude-configs/bulk-delete", bytes.NewBufferString(`{"ids": []}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d. Body: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+}
+
+// TestDeleteTemplatesByType_RequiresConfirm tests that type-wide deletion needs confirm=true
+func TestDeleteTemplatesByType_RequiresConfirm(t *testing.T) {
+	router, service := setupTestRouterWithMock()
+
+	service.Create(services.CreateConfigTemplateInput{
+		Name:       "skill1",
+		ConfigType: models.ConfigTypeSkill,
+		Content:    "# Skill",
+	})
+	service.Create(services.CreateConfigTemplateInput{
+		Name:       "md1",
+		ConfigType: models.ConfigTypeClaudeMD,
+		Content:    "# MD",
+	})
+
+	req, _ := http.NewRequest("DELETE", "/api/claude-configs?type=SKILL", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d without confirm, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/claude-configs?type=SKILL&confirm=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	remaining, _ := service.List(nil)
+	if len(remaining) != 1 || remaining[0].ConfigType != models.ConfigTypeClaudeMD {
+		t.Errorf("Expected only the CLAUDE_MD template to remain, got %+v", remaining)
+	}
+}
+
+// ============================================================================
+// GET /api/claude-configs/types Tests
+// ============================================================================
+
+// TestListTypes_AllValidTypes tests that every valid config type is described
+func TestListTypes_AllValidTypes(t *testing.T) {
+	router, _ := setupTestRouterWithMock()
+
+	req, _ := http.NewRequest("GET", "/api/claude-configs/types", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response []services.ConfigTypeSchema
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	valid := models.ValidConfigTypes()
+	if len(response) != len(valid) {
+		t.Fatalf("Expected %d types, got %d: %+v", len(valid), len(response), response)
+	}
+	for i, configType := range valid {
+		schema := response[i]
+		if schema.Type != configType || schema.Label == "" || schema.Format == "" || len(schema.Hints) == 0 {
+			t.Errorf("Unexpected schema for %s: %+v", configType, schema)
+		}
+		if configType == models.ConfigTypeMCP && !reflect.DeepEqual(schema.RequiredFields, []string{"command", "args"}) {
+			t.Errorf("Expected MCP to require command and args, got %v", schema.RequiredFields)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,12 +11,21 @@ import (
 	"strconv"
 	"time"
 
+	"cc-platform/internal/constants"
+	"cc-platform/internal/middleware"
 	"cc-platform/internal/services"
 	"cc-platform/internal/terminal"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// IdempotencyKeyHeader makes a retried container creation return the container of the first request
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses that returned an already created container
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
 // ContainerHandler handles container endpoints
 type ContainerHandler struct {
 	containerService     *services.ContainerService
@@ -37,9 +48,16 @@ type PortMappingRequest struct {
 	HostPort      int `json:"host_port"`
 }
 
+// HostMountRequest represents a host directory mount in the request (read-only unless read_write is set)
+type HostMountRequest struct {
+	HostPath      string `json:"host_path"`
+	ContainerPath string `json:"container_path"`
+	ReadWrite     bool   `json:"read_write,omitempty"`
+}
+
 // ProxyConfigRequest represents Traefik proxy configuration in the request
 type ProxyConfigRequest struct {
-	Enabled     bool   `json:"enabled"`                // Enable Traefik proxy
+	Enabled     *bool  `json:"enabled,omitempty"`      // Enable Traefik proxy (default: the container profile's setting, else off)
 	Domain      string `json:"domain,omitempty"`       // Subdomain for domain-based access
 	Port        int    `json:"port,omitempty"`         // Direct port access (9001-9010)
 	ServicePort int    `json:"service_port,omitempty"` // Container internal service port
@@ -47,19 +65,32 @@ type ProxyConfigRequest struct {
 
 // CreateContainerRequest represents the request to create a container
 type CreateContainerRequest struct {
-	Name             string               `json:"name" binding:"required"`
-	GitRepoURL       string               `json:"git_repo_url,omitempty"` // GitHub repo URL (optional when SkipGitRepo=true)
-	GitRepoName      string               `json:"git_repo_name,omitempty"`
-	SkipClaudeInit   bool                 `json:"skip_claude_init,omitempty"`   // Skip Claude Code initialization
-	MemoryLimit      int64                `json:"memory_limit,omitempty"`       // Memory limit in MB (0 = default 2048MB)
-	CPULimit         float64              `json:"cpu_limit,omitempty"`          // CPU limit in cores (0 = default 1)
-	MemoryUnlimited  bool                 `json:"memory_unlimited,omitempty"`   // Disable Docker memory limit
-	CPUUnlimited     bool                 `json:"cpu_unlimited,omitempty"`      // Disable Docker CPU quota
-	GPUEnabled       bool                 `json:"gpu_enabled,omitempty"`        // Enable GPU passthrough
-	GPUCount         int                  `json:"gpu_count,omitempty"`          // -1 means all GPUs
-	PortMappings     []PortMappingRequest `json:"port_mappings,omitempty"`      // Legacy port mappings
-	Proxy            ProxyConfigRequest   `json:"proxy,omitempty"`              // Traefik proxy configuration
-	EnableCodeServer bool                 `json:"enable_code_server,omitempty"` // Enable code-server (Web VS Code)
+	Name                 string               `json:"name" binding:"required"`
+	GitRepoURL           string               `json:"git_repo_url,omitempty"` // GitHub repo URL (optional when SkipGitRepo=true)
+	GitRepoName          string               `json:"git_repo_name,omitempty"`
+	SkipClaudeInit       bool                 `json:"skip_claude_init,omitempty"`       // Deprecated: use init_strategy
+	InitStrategy         string               `json:"init_strategy,omitempty"`          // claude, script, both or none
+	MemoryLimit          int64                `json:"memory_limit,omitempty"`           // Memory limit in MB (0 = default 2048MB)
+	CPULimit             float64              `json:"cpu_limit,omitempty"`              // CPU limit in cores (0 = default 1)
+	MemoryUnlimited      bool                 `json:"memory_unlimited,omitempty"`       // Disable Docker memory limit
+	CPUUnlimited         bool                 `json:"cpu_unlimited,omitempty"`          // Disable Docker CPU quota
+	ResourceProfile      string               `json:"resource_profile,omitempty"`       // Named CPU/memory preset
+	GPUEnabled           bool                 `json:"gpu_enabled,omitempty"`            // Enable GPU passthrough
+	GPUCount             int                  `json:"gpu_count,omitempty"`              // -1 means all GPUs
+	RestartPolicy        string               `json:"restart_policy,omitempty"`         // no, on-failure or always
+	RestartMaxRetries    int                  `json:"restart_max_retries,omitempty"`    // Max restarts for on-failure (0 = unlimited)
+	TTLMinutes           int                  `json:"ttl_minutes,omitempty"`            // Delete the container this many minutes after creation (0 = never)
+	Image                string               `json:"image,omitempty"`                  // Custom image from an allowlisted registry (default: platform base image)
+	ClaudeVersion        string               `json:"claude_version,omitempty"`         // Exact Claude CLI version installed during init (default: the image's)
+	CloneLFS             *bool                `json:"clone_lfs,omitempty"`              // Pull Git LFS files when the repository uses LFS (default: true)
+	ClaudeAPIBase        string               `json:"claude_api_base,omitempty"`        // Overrides the env profile's ANTHROPIC_BASE_URL
+	ClaudeModel          string               `json:"claude_model,omitempty"`           // Overrides the env profile's ANTHROPIC_MODEL
+	ClaudeAuthToken      string               `json:"claude_auth_token,omitempty"`      // Sets ANTHROPIC_AUTH_TOKEN in place of the profile's API key; stored encrypted
+	PortMappings         []PortMappingRequest `json:"port_mappings,omitempty"`          // Legacy port mappings
+	Proxy                ProxyConfigRequest   `json:"proxy,omitempty"`                  // Traefik proxy configuration
+	EnableCodeServer     *bool                `json:"enable_code_server,omitempty"`     // Enable code-server (default: the container profile's setting, else off)
+	CodeServerAuth       string               `json:"code_server_auth,omitempty"`       // none (default) or password
+	CodeServerExtensions []string             `json:"code_server_extensions,omitempty"` // Extension IDs installed after code-server starts
 	// Configuration profile references (nil/0 = use default)
 	GitHubTokenID           *uint `json:"github_token_id,omitempty"`
 	EnvVarsProfileID        *uint `json:"env_vars_profile_id,omitempty"`
@@ -74,16 +105,26 @@ type CreateContainerRequest struct {
 	SelectedGeminiEnvs   []uint `json:"selected_gemini_envs,omitempty"`   // Multiple Gemini Env template IDs (optional)
 	AutoInjectAllSkills  bool   `json:"auto_inject_all_skills,omitempty"` // Automatically inject all SKILL templates
 	// Container creation options
-	SkipGitRepo    bool `json:"skip_git_repo,omitempty"`    // Allow creating container without GitHub repository
-	EnableYoloMode bool `json:"enable_yolo_mode,omitempty"` // Enable YOLO mode (--dangerously-skip-permissions)
-	RunAsRoot      bool `json:"run_as_root,omitempty"`      // Run container as root user (default: false)
+	SkipGitRepo    bool   `json:"skip_git_repo,omitempty"`    // Allow creating container without GitHub repository
+	EnableYoloMode bool   `json:"enable_yolo_mode,omitempty"` // Enable YOLO mode (--dangerously-skip-permissions)
+	ConfirmYolo    bool   `json:"confirm_yolo,omitempty"`     // Acknowledges the risks of YOLO mode; required with enable_yolo_mode or pre_init_script
+	RunAsRoot      bool   `json:"run_as_root,omitempty"`      // Run container as root user (default: false)
+	RunAsUser      string `json:"run_as_user,omitempty"`      // Run as this username or uid[:gid] (default: the image's user)
+	// Bash script run after clone and before Claude init (optional)
+	PreInitScript string `json:"pre_init_script,omitempty"`
+	// URL notified with a signed POST when initialization finishes (optional)
+	InitCallbackURL string `json:"init_callback_url,omitempty"`
+	// Host directories to mount; only allowed when enabled and allowlisted in server config
+	HostMounts []HostMountRequest `json:"host_mounts,omitempty"`
+	// Container profile whose defaults fill the fields left empty (optional)
+	ProfileID *uint `json:"profile_id,omitempty"`
 }
 
 // ListContainers lists all containers
 func (h *ContainerHandler) ListContainers(c *gin.Context) {
 	containers, err := h.containerService.ListContainers()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list containers"})
+		writeAPIError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to list containers")
 		return
 	}
 
@@ -100,10 +141,63 @@ func (h *ContainerHandler) ListContainers(c *gin.Context) {
 func (h *ContainerHandler) CreateContainer(c *gin.Context) {
 	var req CreateContainerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		writeInvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	input := req.toInput()
+	// The audit entry of the creation names YOLO mode, so it shows who enabled it
+	if input.EnableYoloMode {
+		c.Set(middleware.AuditTargetKey, "yolo_mode")
+	}
+
+	container, replayed, err := h.containerService.CreateContainerIdempotent(c.Request.Context(),
+		c.GetString("username"), c.GetHeader(IdempotencyKeyHeader), input)
+	if err != nil {
+		// A profile named in the request is part of its body, not the resource being addressed
+		if errors.Is(err, services.ErrContainerProfileNotFound) {
+			writeAPIError(c, http.StatusBadRequest, "CONTAINER_PROFILE_NOT_FOUND", err.Error())
+			return
+		}
+		writeServiceError(c, err)
 		return
 	}
 
+	c.Set(middleware.AuditContainerIDKey, container.ID)
+
+	if replayed {
+		c.Header(IdempotentReplayedHeader, "true")
+		c.JSON(http.StatusOK, gin.H{
+			"container": services.ToContainerInfo(container),
+			"message":   "Container already created for this idempotency key",
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"container": services.ToContainerInfo(container),
+		"message":   "Container created and initialization started",
+	})
+}
+
+// ValidateContainer dry-runs the checks of CreateContainer and lists every problem without creating anything
+// POST /api/containers/validate
+func (h *ContainerHandler) ValidateContainer(c *gin.Context) {
+	// Decoded without binding rules so a missing name is reported as a problem like any other
+	var req CreateContainerRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		writeInvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	problems := h.containerService.ValidateCreateInput(req.toInput())
+	c.JSON(http.StatusOK, gin.H{
+		"valid":    len(problems) == 0,
+		"problems": problems,
+	})
+}
+
+// toInput converts a create request into the service input
+func (req CreateContainerRequest) toInput() services.CreateContainerInput {
 	// Convert port mappings
 	portMappings := make([]services.PortMapping, len(req.PortMappings))
 	for i, pm := range req.PortMappings {
@@ -113,19 +207,41 @@ func (h *ContainerHandler) CreateContainer(c *gin.Context) {
 		}
 	}
 
-	input := services.CreateContainerInput{
+	hostMounts := make([]services.HostMountInput, len(req.HostMounts))
+	for i, m := range req.HostMounts {
+		hostMounts[i] = services.HostMountInput{
+			HostPath:      m.HostPath,
+			ContainerPath: m.ContainerPath,
+			ReadWrite:     m.ReadWrite,
+		}
+	}
+
+	return services.CreateContainerInput{
 		Name:                    req.Name,
 		GitRepoURL:              req.GitRepoURL,
 		GitRepoName:             req.GitRepoName,
 		SkipClaudeInit:          req.SkipClaudeInit,
+		InitStrategy:            req.InitStrategy,
 		MemoryLimit:             req.MemoryLimit,
 		CPULimit:                req.CPULimit,
 		MemoryUnlimited:         req.MemoryUnlimited,
 		CPUUnlimited:            req.CPUUnlimited,
+		ResourceProfile:         req.ResourceProfile,
 		GPUEnabled:              req.GPUEnabled,
 		GPUCount:                req.GPUCount,
+		RestartPolicy:           req.RestartPolicy,
+		RestartMaxRetries:       req.RestartMaxRetries,
+		TTLMinutes:              req.TTLMinutes,
+		Image:                   req.Image,
+		ClaudeVersion:           req.ClaudeVersion,
+		CloneLFS:                req.CloneLFS,
+		ClaudeAPIBase:           req.ClaudeAPIBase,
+		ClaudeModel:             req.ClaudeModel,
+		ClaudeAuthToken:         req.ClaudeAuthToken,
 		PortMappings:            portMappings,
 		EnableCodeServer:        req.EnableCodeServer,
+		CodeServerAuth:          req.CodeServerAuth,
+		CodeServerExtensions:    req.CodeServerExtensions,
 		GitHubTokenID:           req.GitHubTokenID,
 		EnvVarsProfileID:        req.EnvVarsProfileID,
 		StartupCommandProfileID: req.StartupCommandProfileID,
@@ -145,43 +261,35 @@ func (h *ContainerHandler) CreateContainer(c *gin.Context) {
 		SelectedGeminiEnvs:   req.SelectedGeminiEnvs,
 		AutoInjectAllSkills:  req.AutoInjectAllSkills,
 		// Container creation options
-		SkipGitRepo:    req.SkipGitRepo,
-		EnableYoloMode: req.EnableYoloMode,
-		RunAsRoot:      req.RunAsRoot,
-	}
-
-	container, err := h.containerService.CreateContainer(c.Request.Context(), input)
-	if err != nil {
-		switch err {
-		case services.ErrNoGitHubTokenConfigured:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "GitHub token not configured. Please configure it in Settings."})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
-		return
+		SkipGitRepo:     req.SkipGitRepo,
+		EnableYoloMode:  req.EnableYoloMode,
+		ConfirmYolo:     req.ConfirmYolo,
+		RunAsRoot:       req.RunAsRoot,
+		RunAsUser:       req.RunAsUser,
+		PreInitScript:   req.PreInitScript,
+		InitCallbackURL: req.InitCallbackURL,
+		HostMounts:      hostMounts,
+		ProfileID:       req.ProfileID,
 	}
+}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"container": services.ToContainerInfo(container),
-		"message":   "Container created and initialization started",
-	})
+// ListResourceProfiles lists the named CPU/memory presets for new containers
+// GET /api/resource-profiles
+func (h *ContainerHandler) ListResourceProfiles(c *gin.Context) {
+	c.JSON(http.StatusOK, h.containerService.ListResourceProfiles())
 }
 
 // GetContainer gets a container by ID
 func (h *ContainerHandler) GetContainer(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
 	container, err := h.containerService.GetContainer(id)
 	if err != nil {
-		if err == services.ErrContainerNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get container"})
+		writeServiceError(c, err)
 		return
 	}
 
@@ -192,19 +300,12 @@ func (h *ContainerHandler) GetContainer(c *gin.Context) {
 func (h *ContainerHandler) StartContainer(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
 	if err := h.containerService.StartContainer(c.Request.Context(), id); err != nil {
-		switch err {
-		case services.ErrContainerNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
-		case services.ErrContainerNotReady:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Container initialization not complete. Please wait for initialization to finish."})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		writeServiceError(c, err)
 		return
 	}
 
@@ -215,7 +316,7 @@ func (h *ContainerHandler) StartContainer(c *gin.Context) {
 func (h *ContainerHandler) StopContainer(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
@@ -245,11 +346,7 @@ func (h *ContainerHandler) StopContainer(c *gin.Context) {
 	select {
 	case err := <-errChan:
 		if err != nil {
-			if err == services.ErrContainerNotFound {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"message": "Container stopped successfully"})
@@ -259,11 +356,12 @@ func (h *ContainerHandler) StopContainer(c *gin.Context) {
 	}
 }
 
-// DeleteContainer deletes a container
+// DeleteContainer deletes a container. With ?preserve_workspace=true the work directory is
+// archived first and the archive is returned; the container is kept if archiving fails.
 func (h *ContainerHandler) DeleteContainer(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
@@ -273,30 +371,58 @@ func (h *ContainerHandler) DeleteContainer(c *gin.Context) {
 		h.terminalService.CloseSessionsForContainer(id)
 	}
 
+	preserve := c.Query("preserve_workspace") == "true"
+
 	// Use a background context with timeout for Docker delete operation
-	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
-	defer cancel()
+	timeout := 45 * time.Second
+	if preserve {
+		timeout += constants.WorkspaceExportTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 
 	// Run delete operation in goroutine and return early if it takes too long
-	errChan := make(chan error, 1)
+	type deleteResult struct {
+		archive *services.WorkspaceArchiveResponse
+		err     error
+	}
+	resultChan := make(chan deleteResult, 1)
 	go func() {
-		errChan <- h.containerService.DeleteContainer(ctx, id)
+		defer cancel()
+		var result deleteResult
+		if preserve {
+			result.archive, result.err = h.containerService.DeleteContainerPreservingWorkspace(ctx, id)
+		} else {
+			result.err = h.containerService.DeleteContainer(ctx, id)
+		}
+		resultChan <- result
 	}()
 
 	// Wait for delete to complete or timeout after 10 seconds for HTTP response
 	select {
-	case err := <-errChan:
-		if err != nil {
-			if err == services.ErrContainerNotFound {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+	case result := <-resultChan:
+		if result.err != nil {
+			if errors.Is(result.err, services.ErrContainerNotFound) {
+				writeServiceError(c, result.err)
 				return
 			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			if preserve && result.archive == nil {
+				writeWorkspaceArchiveError(c, result.err)
+				return
+			}
+			writeServiceError(c, result.err)
+			return
+		}
+		if preserve {
+			c.JSON(http.StatusOK, gin.H{"message": "Container deleted successfully", "archive": result.archive})
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"message": "Container deleted successfully"})
 	case <-time.After(10 * time.Second):
 		// Return success early - the delete operation will continue in background
+		if preserve {
+			c.JSON(http.StatusAccepted, gin.H{"message": "Workspace export and container delete initiated, the archive will appear under workspace archives when done"})
+			return
+		}
 		c.JSON(http.StatusAccepted, gin.H{"message": "Container delete initiated, please refresh to check status"})
 	}
 }
@@ -305,24 +431,21 @@ func (h *ContainerHandler) DeleteContainer(c *gin.Context) {
 func (h *ContainerHandler) GetContainerStatus(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
 	container, err := h.containerService.GetContainer(id)
 	if err != nil {
-		if err == services.ErrContainerNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get container"})
+		writeServiceError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status":       container.Status,
-		"init_status":  container.InitStatus,
-		"init_message": container.InitMessage,
+		"status":        container.Status,
+		"init_status":   container.InitStatus,
+		"init_message":  container.InitMessage,
+		"init_progress": container.InitProgress,
 	})
 }
 
@@ -330,7 +453,7 @@ func (h *ContainerHandler) GetContainerStatus(c *gin.Context) {
 func (h *ContainerHandler) GetContainerLogs(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
@@ -344,7 +467,7 @@ func (h *ContainerHandler) GetContainerLogs(c *gin.Context) {
 
 	logs, err := h.containerService.GetContainerLogs(id, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get logs"})
+		writeAPIError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to get logs")
 		return
 	}
 
@@ -352,10 +475,16 @@ func (h *ContainerHandler) GetContainerLogs(c *gin.Context) {
 }
 
 // ListDockerContainers lists all Docker containers (including orphaned ones)
+// GET /api/docker/containers?managed=true|false&state=running&name=web
 func (h *ContainerHandler) ListDockerContainers(c *gin.Context) {
-	containers, err := h.containerService.ListDockerContainers(c.Request.Context())
+	filter, err := services.NewDockerContainerFilter(c.Query("managed"), c.Query("state"), c.Query("name"))
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	containers, err := h.containerService.ListDockerContainers(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServerError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, containers)
@@ -365,12 +494,12 @@ func (h *ContainerHandler) ListDockerContainers(c *gin.Context) {
 func (h *ContainerHandler) StopDockerContainer(c *gin.Context) {
 	dockerID := c.Param("dockerId")
 	if dockerID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Docker container ID required"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Docker container ID required")
 		return
 	}
 
 	if err := h.containerService.StopDockerContainer(c.Request.Context(), dockerID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServerError(c, err)
 		return
 	}
 
@@ -381,12 +510,12 @@ func (h *ContainerHandler) StopDockerContainer(c *gin.Context) {
 func (h *ContainerHandler) RemoveDockerContainer(c *gin.Context) {
 	dockerID := c.Param("dockerId")
 	if dockerID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Docker container ID required"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Docker container ID required")
 		return
 	}
 
 	if err := h.containerService.RemoveDockerContainer(c.Request.Context(), dockerID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServerError(c, err)
 		return
 	}
 
@@ -411,28 +540,24 @@ type InjectConfigsRequest struct {
 func (h *ContainerHandler) InjectConfigs(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
 	var req InjectConfigsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body, template_ids array required"})
+		writeInvalidRequest(c, "Invalid request body, template_ids array required", err)
 		return
 	}
 
 	if len(req.TemplateIDs) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one template ID is required"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, "At least one template ID is required")
 		return
 	}
 
 	status, err := h.containerService.InjectConfigs(c.Request.Context(), id, req.TemplateIDs)
 	if err != nil {
-		if err == services.ErrContainerNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 
@@ -447,18 +572,18 @@ func (h *ContainerHandler) InjectConfigs(c *gin.Context) {
 func (h *ContainerHandler) GetContainerApiConfig(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
 	if h.configProfileService == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Config profile service not available"})
+		writeAPIError(c, http.StatusInternalServerError, ErrCodeServiceUnavailable, "Config profile service not available")
 		return
 	}
 
 	apiConfig, err := h.configProfileService.GetApiConfigByContainerID(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
 		return
 	}
 
@@ -470,18 +595,18 @@ func (h *ContainerHandler) GetContainerApiConfig(c *gin.Context) {
 func (h *ContainerHandler) GetContainerModels(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
 	if h.configProfileService == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Config profile service not available"})
+		writeAPIError(c, http.StatusInternalServerError, ErrCodeServiceUnavailable, "Config profile service not available")
 		return
 	}
 
 	apiConfig, err := h.configProfileService.GetApiConfigByContainerID(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
 		return
 	}
 
@@ -493,14 +618,14 @@ func (h *ContainerHandler) GetContainerModels(c *gin.Context) {
 	// Build models URL from API URL
 	modelsURL, err := buildModelsURL(apiConfig.ApiUrl)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid API URL: %v", err)})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidRequest, fmt.Sprintf("Invalid API URL: %v", err))
 		return
 	}
 
 	// Create HTTP request to fetch models
 	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", modelsURL, nil)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create request: %v", err)})
+		writeAPIError(c, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("Failed to create request: %v", err))
 		return
 	}
 
@@ -511,7 +636,7 @@ func (h *ContainerHandler) GetContainerModels(c *gin.Context) {
 	client := &http.Client{Timeout: 30 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to fetch models: %v", err)})
+		writeAPIError(c, http.StatusBadGateway, ErrCodeUpstream, fmt.Sprintf("Failed to fetch models: %v", err))
 		return
 	}
 	defer resp.Body.Close()
@@ -519,13 +644,13 @@ func (h *ContainerHandler) GetContainerModels(c *gin.Context) {
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to read response: %v", err)})
+		writeAPIError(c, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("Failed to read response: %v", err))
 		return
 	}
 
 	// If the upstream request failed, return the error
 	if resp.StatusCode != http.StatusOK {
-		c.JSON(resp.StatusCode, gin.H{"error": string(body)})
+		writeAPIError(c, resp.StatusCode, ErrCodeUpstream, string(body))
 		return
 	}
 
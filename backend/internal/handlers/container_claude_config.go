@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetContainerClaudeConfig returns the Claude configuration Claude will load inside the container
+// GET /api/containers/:id/claude-config
+func (h *ContainerHandler) GetContainerClaudeConfig(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	config, err := h.containerService.GetClaudeConfig(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrContainerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+		case errors.Is(err, services.ErrContainerPaused):
+			c.JSON(http.StatusConflict, gin.H{"error": "Container is paused"})
+		case errors.Is(err, services.ErrContainerNotRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": "Container is not running"})
+		default:
+			writeServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// TestMCPServer launches or probes an MCP server configured in the container and reports
+// whether it starts or responds, with its stderr on failure
+// POST /api/containers/:id/mcp/:name/test?timeout=15
+func (h *ContainerHandler) TestMCPServer(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		writeInvalidRequest(c, "Invalid container ID", nil)
+		return
+	}
+
+	timeout := services.DefaultMCPTestTimeout
+	if v := c.Query("timeout"); v != "" {
+		timeout, err = strconv.Atoi(v)
+		if err != nil || timeout < 1 || timeout > services.MaxMCPTestTimeout {
+			writeInvalidRequest(c, fmt.Sprintf("timeout must be between 1 and %d seconds", services.MaxMCPTestTimeout), nil)
+			return
+		}
+	}
+
+	result, err := h.containerService.TestMCPServer(c.Request.Context(), id, c.Param("name"), time.Duration(timeout)*time.Second)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/middleware"
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetClaudeSnapshot downloads the container's ~/.claude directory as a gzipped tar archive
+// GET /api/containers/:id/claude-snapshot
+func (h *ContainerHandler) GetClaudeSnapshot(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	data, err := h.containerService.SnapshotClaudeDir(c.Request.Context(), id)
+	if err != nil {
+		writeClaudeSnapshotError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("claude-snapshot-%d-%s.tar.gz", id, time.Now().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/gzip", data)
+}
+
+// RestoreClaudeSnapshot restores a ~/.claude snapshot into the container. The archive is sent
+// as the request body or as the "file" field of a multipart form; ?replace=true removes the
+// existing ~/.claude first.
+// POST /api/containers/:id/claude-snapshot
+func (h *ContainerHandler) RestoreClaudeSnapshot(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	// The archive body limit middleware may set a lower cap; this one holds even when it is disabled
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, constants.ClaudeSnapshotMaxBodyBytes)
+	var archive io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			writeClaudeSnapshotError(c, fmt.Errorf("%w: %w", services.ErrInvalidClaudeSnapshot, err))
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			writeServerError(c, err)
+			return
+		}
+		defer f.Close()
+		archive = f
+	}
+
+	result, err := h.containerService.RestoreClaudeDir(c.Request.Context(), id, archive, c.Query("replace") == "true")
+	if err != nil {
+		writeClaudeSnapshotError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// writeClaudeSnapshotError maps snapshot errors to status codes
+func writeClaudeSnapshotError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, services.ErrContainerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+	case errors.Is(err, services.ErrClaudeDirNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrContainerPaused):
+		c.JSON(http.StatusConflict, gin.H{"error": "Container is paused"})
+	case errors.Is(err, services.ErrContainerNotRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": "Container is not running"})
+	case errors.As(err, &maxBytesErr):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": middleware.BodyTooLargeMessage(maxBytesErr.Limit)})
+	case errors.Is(err, services.ErrClaudeSnapshotTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Snapshot exceeds %d bytes", constants.ClaudeSnapshotMaxBytes)})
+	case errors.Is(err, services.ErrInvalidClaudeSnapshot):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		writeServerError(c, err)
+	}
+}
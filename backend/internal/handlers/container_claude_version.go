@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetClaudeVersion reports the Claude CLI version installed in the container and the pinned
+// version, if any. Images without the CLI report installed=false.
+// GET /api/containers/:id/claude-version
+func (h *ContainerHandler) GetClaudeVersion(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	info, err := h.containerService.GetClaudeVersion(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrContainerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+		case errors.Is(err, services.ErrContainerPaused):
+			c.JSON(http.StatusConflict, gin.H{"error": "Container is paused"})
+		case errors.Is(err, services.ErrContainerNotRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": "Container is not running"})
+		default:
+			writeServerError(c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CloneRepo clones a repository into a running container that was created without one
+// POST /api/containers/:id/clone-repo
+func (h *ContainerHandler) CloneRepo(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	var req services.CloneRepoInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	container, err := h.containerService.CloneRepoIntoContainer(c.Request.Context(), id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrContainerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+		case errors.Is(err, services.ErrContainerNotRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": "Container is not running"})
+		case errors.Is(err, services.ErrRepoAlreadyPresent), errors.Is(err, services.ErrInitInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidCloneRequest):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrNoGitHubTokenConfigured):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "GitHub token not configured. Please configure it in Settings."})
+		default:
+			writeServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"container": services.ToContainerInfo(container),
+		"message":   "Clone started",
+	})
+}
+
+// CancelInit cancels a container's running initialization, killing an in-flight clone
+// POST /api/containers/:id/cancel-init
+func (h *ContainerHandler) CancelInit(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
+		return
+	}
+
+	container, err := h.containerService.CancelInit(c.Request.Context(), id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"container": services.ToContainerInfo(container),
+		"message":   "Initialization cancelled",
+	})
+}
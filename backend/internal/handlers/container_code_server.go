@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCodeServerAccess returns the code-server URL and, with password auth, its password
+// GET /api/containers/:id/code-server
+func (h *ContainerHandler) GetCodeServerAccess(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	access, err := h.containerService.GetCodeServerAccess(id)
+	if err != nil {
+		writeCodeServerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, access)
+}
+
+// RotateCodeServerPassword generates a new code-server password and restarts code-server with it
+// POST /api/containers/:id/code-server/rotate-password
+func (h *ContainerHandler) RotateCodeServerPassword(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	access, err := h.containerService.RotateCodeServerPassword(c.Request.Context(), id)
+	if err != nil {
+		writeCodeServerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, access)
+}
+
+func writeCodeServerError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrContainerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+	case errors.Is(err, services.ErrCodeServerDisabled):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCodeServerNoPassword):
+		c.JSON(http.StatusConflict, gin.H{"error": "code-server runs with --auth none; create the container with code_server_auth \"password\" to use a password"})
+	default:
+		writeServerError(c, err)
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"cc-platform/internal/middleware"
+	"cc-platform/internal/models"
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// containerCommandStore keeps the saved commands of containers and runs them
+type containerCommandStore interface {
+	List(containerID uint) ([]models.ContainerCommand, error)
+	Get(containerID uint, name string) (*models.ContainerCommand, error)
+	Create(containerID uint, input services.ContainerCommandInput) (*models.ContainerCommand, error)
+	Update(containerID uint, name string, input services.ContainerCommandInput) (*models.ContainerCommand, error)
+	Delete(containerID uint, name string) error
+	Timeout(command *models.ContainerCommand) time.Duration
+	Run(ctx context.Context, command *models.ContainerCommand, stdout, stderr io.Writer) (int, error)
+}
+
+// ListCommands lists the saved commands of a container with their latest run
+// GET /api/containers/:id/commands
+func (h *ContainerExecHandler) ListCommands(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		writeInvalidRequest(c, "Invalid container ID", nil)
+		return
+	}
+
+	commands, err := h.commands.List(id)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"commands": commands})
+}
+
+// GetCommand gets a saved command of a container by name
+// GET /api/containers/:id/commands/:name
+func (h *ContainerExecHandler) GetCommand(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		writeInvalidRequest(c, "Invalid container ID", nil)
+		return
+	}
+
+	command, err := h.commands.Get(id, c.Param("name"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, command)
+}
+
+// CreateCommand saves a command for a container
+// POST /api/containers/:id/commands
+func (h *ContainerExecHandler) CreateCommand(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		writeInvalidRequest(c, "Invalid container ID", nil)
+		return
+	}
+
+	var input services.ContainerCommandInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInvalidRequest(c, "Invalid request body", err)
+		return
+	}
+	// Only the name is recorded; the command itself stays out of the audit log
+	c.Set(middleware.AuditTargetKey, input.Name)
+
+	command, err := h.commands.Create(id, input)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, command)
+}
+
+// UpdateCommand replaces the name, command and timeout of a saved command
+// PUT /api/containers/:id/commands/:name
+func (h *ContainerExecHandler) UpdateCommand(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		writeInvalidRequest(c, "Invalid container ID", nil)
+		return
+	}
+
+	var input services.ContainerCommandInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		writeInvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	command, err := h.commands.Update(id, c.Param("name"), input)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, command)
+}
+
+// DeleteCommand deletes a saved command of a container
+// DELETE /api/containers/:id/commands/:name
+func (h *ContainerExecHandler) DeleteCommand(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		writeInvalidRequest(c, "Invalid container ID", nil)
+		return
+	}
+
+	if err := h.commands.Delete(id, c.Param("name")); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Command deleted"})
+}
+
+// RunCommand runs a saved command in a running container and streams its output as NDJSON,
+// like POST /api/containers/:id/exec. The exit code and output tail are saved with the command.
+// POST /api/containers/:id/commands/:name/run
+func (h *ContainerExecHandler) RunCommand(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		writeInvalidRequest(c, "Invalid container ID", nil)
+		return
+	}
+
+	command, err := h.commands.Get(id, c.Param("name"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	streamExec(c, h.commands.Timeout(command), func(ctx context.Context, stdout, stderr io.Writer) (int, error) {
+		return h.commands.Run(ctx, command, stdout, stderr)
+	})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeCommandStore looks up commands in a map and runs them with a fake exec
+type fakeCommandStore struct {
+	commands map[string]*models.ContainerCommand
+	exec     *fakeExecStreamer
+	ran      *models.ContainerCommand
+}
+
+func (f *fakeCommandStore) List(containerID uint) ([]models.ContainerCommand, error) {
+	return nil, nil
+}
+
+func (f *fakeCommandStore) Get(containerID uint, name string) (*models.ContainerCommand, error) {
+	cmd, ok := f.commands[name]
+	if !ok || cmd.ContainerID != containerID {
+		return nil, fmt.Errorf("%w: '%s'", services.ErrContainerCommandNotFound, name)
+	}
+	return cmd, nil
+}
+
+func (f *fakeCommandStore) Create(containerID uint, input services.ContainerCommandInput) (*models.ContainerCommand, error) {
+	return nil, services.ErrInvalidContainerCommand
+}
+
+func (f *fakeCommandStore) Update(containerID uint, name string, input services.ContainerCommandInput) (*models.ContainerCommand, error) {
+	return nil, services.ErrContainerCommandNotFound
+}
+
+func (f *fakeCommandStore) Delete(containerID uint, name string) error {
+	return services.ErrContainerCommandNotFound
+}
+
+func (f *fakeCommandStore) Timeout(command *models.ContainerCommand) time.Duration {
+	return time.Minute
+}
+
+func (f *fakeCommandStore) Run(ctx context.Context, command *models.ContainerCommand, stdout, stderr io.Writer) (int, error) {
+	f.ran = command
+	return f.exec.ExecStream(ctx, command.ContainerID, command.Command, stdout, stderr)
+}
+
+func setupCommandsRouter(store *fakeCommandStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := &ContainerExecHandler{commands: store}
+	router.POST("/api/containers/:id/commands/:name/run", handler.RunCommand)
+	return router
+}
+
+func TestRunCommand_StreamsSavedCommandByName(t *testing.T) {
+	store := &fakeCommandStore{
+		commands: map[string]*models.ContainerCommand{
+			"build": {ContainerID: 1, Name: "build", Command: models.CommandArgList{"make", "build"}},
+		},
+		exec: &fakeExecStreamer{stdout: []string{"compiling\n"}, exitCode: 0},
+	}
+	router := setupCommandsRouter(store)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/containers/1/commands/build/run", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("Expected an NDJSON stream, got %d: %s", w.Code, w.Body.String())
+	}
+	frames := readExecFrames(t, w.Body)
+	if len(frames) != 2 || frames[0].Data != "compiling\n" || frames[1].Type != "exit" || *frames[1].ExitCode != 0 {
+		t.Errorf("Unexpected frames: %+v", frames)
+	}
+	if store.ran == nil || !reflect.DeepEqual(store.exec.gotCmd, []string{"make", "build"}) {
+		t.Errorf("Expected the saved command to run, got %v", store.exec.gotCmd)
+	}
+
+	// An unknown name is a 404 in the standard error envelope, not a stream
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/containers/1/commands/deploy/run", nil))
+	var body struct {
+		Error APIError `json:"error"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusNotFound || body.Error.Code != "CONTAINER_COMMAND_NOT_FOUND" {
+		t.Errorf("Expected 404 CONTAINER_COMMAND_NOT_FOUND, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// A stopped container is reported before anything is streamed
+	store.exec.err = services.ErrContainerNotRunning
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/containers/1/commands/build/run", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a stopped container, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SwitchConfigProfile makes an env profile the active Claude config of a running container
+// without recreating it
+// POST /api/containers/:id/config-profile/:profileId
+func (h *ContainerHandler) SwitchConfigProfile(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+	profileID, err := parseID(c.Param("profileId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile ID"})
+		return
+	}
+
+	result, err := h.containerService.SwitchConfigProfile(c.Request.Context(), id, profileID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrContainerNotFound), errors.Is(err, services.ErrProfileNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrContainerPaused), errors.Is(err, services.ErrContainerNotRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			writeServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
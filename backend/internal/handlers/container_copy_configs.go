@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CopyConfigsFrom copies CLAUDE.md, skills, commands, agents and MCP servers from the live
+// ~/.claude of another running container into this one
+// POST /api/containers/:id/copy-configs-from/:sourceId
+func (h *ContainerHandler) CopyConfigsFrom(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+	sourceID, err := parseID(c.Param("sourceId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid source container ID"})
+		return
+	}
+
+	status, err := h.containerService.CopyClaudeConfigFrom(c.Request.Context(), id, sourceID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCopyConfigsSameContainer):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrContainerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrContainerPaused), errors.Is(err, services.ErrContainerNotRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			writeServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"injection_status": status})
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateContainerDetails edits the notes and metadata of a container
+// PATCH /api/containers/:id
+func (h *ContainerHandler) UpdateContainerDetails(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
+		return
+	}
+
+	var req services.UpdateContainerDetailsInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeInvalidRequest(c, "Invalid request body", err)
+		return
+	}
+
+	container, err := h.containerService.UpdateContainerDetails(id, req)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, services.ToContainerInfo(container))
+}
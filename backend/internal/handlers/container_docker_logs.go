@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dockerLogsWriter sends log output as a chunked plain-text response, flushing every write.
+// Headers are only sent with the first chunk so earlier errors can still be answered as JSON.
+type dockerLogsWriter struct {
+	w       gin.ResponseWriter
+	started bool
+}
+
+func (d *dockerLogsWriter) start() {
+	if d.started {
+		return
+	}
+	d.w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	d.w.Header().Set("Cache-Control", "no-cache")
+	d.w.Header().Set("X-Content-Type-Options", "nosniff")
+	// Stop nginx from buffering the stream
+	d.w.Header().Set("X-Accel-Buffering", "no")
+	d.w.WriteHeader(http.StatusOK)
+	d.started = true
+}
+
+func (d *dockerLogsWriter) Write(p []byte) (int, error) {
+	d.start()
+	n, err := d.w.Write(p)
+	d.w.Flush()
+	return n, err
+}
+
+// StreamDockerLogs streams the stdout/stderr of the container's processes from the Docker logs.
+// Query parameters: follow=true keeps the stream open, tail=N (or "all") limits the backlog,
+// since accepts an RFC 3339 time, Unix timestamp or duration such as "10m", and
+// timestamps=true prefixes each line with its time.
+// GET /api/containers/:id/docker-logs
+func (h *ContainerHandler) StreamDockerLogs(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	opts := services.DockerLogsOptions{
+		Follow:     c.Query("follow") == "true",
+		Tail:       c.Query("tail"),
+		Since:      c.Query("since"),
+		Timestamps: c.Query("timestamps") == "true",
+	}
+
+	// The request context ends the Docker log stream when the client disconnects
+	writer := &dockerLogsWriter{w: c.Writer}
+	err = h.containerService.StreamDockerLogs(c.Request.Context(), id, opts, writer)
+	if err == nil {
+		// Send headers even when there was no output
+		writer.start()
+		return
+	}
+	if writer.started {
+		fmt.Fprintf(writer, "\n[error reading logs: %v]\n", err)
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrContainerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+	case errors.Is(err, services.ErrInvalidLogsOption):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrContainerNotRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": "Container has no Docker container"})
+	default:
+		writeServerError(c, err)
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetContainerEnv lists the env vars of a container from its Docker config, with secrets masked
+// GET /api/containers/:id/env
+func (h *ContainerHandler) GetContainerEnv(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	env, err := h.containerService.GetContainerEnv(c.Request.Context(), id)
+	if err != nil {
+		if err == services.ErrContainerNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+			return
+		}
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"env": env})
+}
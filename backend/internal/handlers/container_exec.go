@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// containerExecStreamer runs a command in a container and streams its output
+type containerExecStreamer interface {
+	ExecStream(ctx context.Context, id uint, cmd []string, stdout, stderr io.Writer) (int, error)
+}
+
+// ContainerExecHandler handles ad-hoc and saved command execution inside containers
+type ContainerExecHandler struct {
+	execStreamer containerExecStreamer
+	commands     containerCommandStore
+}
+
+// NewContainerExecHandler creates a new ContainerExecHandler
+func NewContainerExecHandler(containerService *services.ContainerService, commandService *services.ContainerCommandService) *ContainerExecHandler {
+	return &ContainerExecHandler{execStreamer: containerService, commands: commandService}
+}
+
+// ExecRequest represents a command to run inside a container
+type ExecRequest struct {
+	Command        []string `json:"command" binding:"required"`
+	TimeoutSeconds int      `json:"timeout_seconds"`
+}
+
+// ExecFrame is one line of the NDJSON exec response.
+// Output frames carry stream/data; the final frame has type "exit" and the exit code.
+type ExecFrame struct {
+	Type     string `json:"type"`             // output | exit | error
+	Stream   string `json:"stream,omitempty"` // stdout | stderr
+	Data     string `json:"data,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// execFrameWriter serializes output chunks as NDJSON frames and flushes them immediately
+type execFrameWriter struct {
+	mu      sync.Mutex
+	w       gin.ResponseWriter
+	started bool
+}
+
+func (f *execFrameWriter) writeFrame(frame ExecFrame) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.started {
+		f.w.Header().Set("Content-Type", "application/x-ndjson")
+		f.w.Header().Set("Cache-Control", "no-cache")
+		f.w.Header().Set("X-Content-Type-Options", "nosniff")
+		f.w.WriteHeader(http.StatusOK)
+		f.started = true
+	}
+
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	if _, err := f.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	f.w.Flush()
+	return nil
+}
+
+// execStreamWriter adapts one output stream to the frame writer. A chunk can end inside a
+// multi-byte UTF-8 character; those bytes are held back until the rest arrives.
+type execStreamWriter struct {
+	frames  *execFrameWriter
+	stream  string
+	pending []byte
+}
+
+func (s *execStreamWriter) Write(p []byte) (int, error) {
+	data := append(s.pending, p...)
+	cut := len(data) - incompleteRuneTail(data)
+	s.pending = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return len(p), nil
+	}
+	if err := s.frames.writeFrame(ExecFrame{Type: "output", Stream: s.stream, Data: string(data[:cut])}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// flush writes bytes still held back once the stream has ended
+func (s *execStreamWriter) flush() {
+	if len(s.pending) == 0 {
+		return
+	}
+	s.frames.writeFrame(ExecFrame{Type: "output", Stream: s.stream, Data: string(s.pending)})
+	s.pending = nil
+}
+
+// incompleteRuneTail returns how many trailing bytes of p begin a UTF-8 character that isn't complete yet
+func incompleteRuneTail(p []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(p); i++ {
+		if utf8.RuneStart(p[len(p)-i]) {
+			if utf8.FullRune(p[len(p)-i:]) {
+				return 0
+			}
+			return i
+		}
+	}
+	return 0
+}
+
+// Exec runs a command in a running container and streams stdout/stderr as NDJSON
+// POST /api/containers/:id/exec
+func (h *ContainerExecHandler) Exec(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	var req ExecRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body, command array required"})
+		return
+	}
+	if len(req.Command) == 0 || req.Command[0] == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Command cannot be empty"})
+		return
+	}
+
+	timeout := constants.ExecDefaultTimeout
+	if req.TimeoutSeconds < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "timeout_seconds cannot be negative"})
+		return
+	}
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+		if timeout > constants.ExecMaxTimeout {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("timeout_seconds cannot exceed %d", int(constants.ExecMaxTimeout.Seconds()))})
+			return
+		}
+	}
+
+	streamExec(c, timeout, func(ctx context.Context, stdout, stderr io.Writer) (int, error) {
+		return h.execStreamer.ExecStream(ctx, id, req.Command, stdout, stderr)
+	})
+}
+
+// streamExec runs an exec bounded by timeout and streams its output as NDJSON frames ending
+// with the exit code. An error before any output is answered as a regular JSON error.
+func streamExec(c *gin.Context, timeout time.Duration, run func(ctx context.Context, stdout, stderr io.Writer) (int, error)) {
+	// The request context also ends the exec when the client disconnects
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	frames := &execFrameWriter{w: c.Writer}
+	stdout := &execStreamWriter{frames: frames, stream: "stdout"}
+	stderr := &execStreamWriter{frames: frames, stream: "stderr"}
+	exitCode, err := run(ctx, stdout, stderr)
+	stdout.flush()
+	stderr.flush()
+
+	if err != nil {
+		// Nothing streamed yet: answer with a regular JSON error
+		if !frames.started {
+			switch {
+			case errors.Is(err, services.ErrContainerNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+				return
+			case errors.Is(err, services.ErrContainerNotRunning):
+				c.JSON(http.StatusConflict, gin.H{"error": "Container is not running"})
+				return
+			case errors.Is(err, services.ErrDockerUnavailable):
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": dockerUnavailableMessage})
+				return
+			}
+		}
+		message := err.Error()
+		if errors.Is(err, context.DeadlineExceeded) {
+			message = fmt.Sprintf("exec timed out after %s", timeout)
+		}
+		frames.writeFrame(ExecFrame{Type: "error", Error: message})
+		return
+	}
+
+	frames.writeFrame(ExecFrame{Type: "exit", ExitCode: &exitCode})
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeExecStreamer replays canned output or blocks until the exec context ends
+type fakeExecStreamer struct {
+	stdout   []string
+	stderr   string
+	exitCode int
+	err      error
+	block    bool
+	gotCmd   []string
+}
+
+func (f *fakeExecStreamer) ExecStream(ctx context.Context, id uint, cmd []string, stdout, stderr io.Writer) (int, error) {
+	f.gotCmd = cmd
+	if f.err != nil {
+		return -1, f.err
+	}
+	for _, chunk := range f.stdout {
+		stdout.Write([]byte(chunk))
+	}
+	if f.stderr != "" {
+		stderr.Write([]byte(f.stderr))
+	}
+	if f.block {
+		<-ctx.Done()
+		return -1, ctx.Err()
+	}
+	return f.exitCode, nil
+}
+
+func performExec(t *testing.T, streamer containerExecStreamer, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := &ContainerExecHandler{execStreamer: streamer}
+	router.POST("/api/containers/:id/exec", handler.Exec)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/containers/1/exec", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func readExecFrames(t *testing.T, body *bytes.Buffer) []ExecFrame {
+	t.Helper()
+	var frames []ExecFrame
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var frame ExecFrame
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			t.Fatalf("invalid NDJSON frame %q: %v", scanner.Text(), err)
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func TestExec_StreamsOutputWithExitCodeTrailer(t *testing.T) {
+	streamer := &fakeExecStreamer{stdout: []string{"line 1\n", "line 2\n"}, stderr: "warn\n", exitCode: 3}
+	w := performExec(t, streamer, `{"command": ["sh", "-c", "make test"]}`)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Expected NDJSON content type, got %q", ct)
+	}
+	if len(streamer.gotCmd) != 3 || streamer.gotCmd[2] != "make test" {
+		t.Errorf("Command not passed through: %v", streamer.gotCmd)
+	}
+
+	frames := readExecFrames(t, w.Body)
+	if len(frames) != 4 {
+		t.Fatalf("Expected 4 frames, got %d: %+v", len(frames), frames)
+	}
+	if frames[0].Stream != "stdout" || frames[0].Data != "line 1\n" {
+		t.Errorf("Unexpected first frame: %+v", frames[0])
+	}
+	if frames[2].Stream != "stderr" || frames[2].Data != "warn\n" {
+		t.Errorf("Unexpected stderr frame: %+v", frames[2])
+	}
+	last := frames[3]
+	if last.Type != "exit" || last.ExitCode == nil || *last.ExitCode != 3 {
+		t.Errorf("Expected exit trailer with code 3, got %+v", last)
+	}
+}
+
+func TestExec_KeepsMultiByteCharactersWhole(t *testing.T) {
+	// "日本" split inside both characters, then a dangling lead byte at the end of the stream
+	streamer := &fakeExecStreamer{stdout: []string{"a\xe6\x97", "\xa5\xe6", "\x9c\xac\n", "\xe6"}}
+	w := performExec(t, streamer, `{"command": ["cat", "notes.txt"]}`)
+
+	frames := readExecFrames(t, w.Body)
+	var data []string
+	for _, frame := range frames {
+		if frame.Type == "output" {
+			data = append(data, frame.Data)
+		}
+	}
+	if !reflect.DeepEqual(data, []string{"a", "日", "本\n", "\ufffd"}) {
+		t.Errorf("Unexpected output frames: %q", data)
+	}
+	if last := frames[len(frames)-1]; last.Type != "exit" {
+		t.Errorf("Expected the exit trailer last, got %+v", last)
+	}
+}
+
+func TestExec_TimeoutEndsStreamWithError(t *testing.T) {
+	streamer := &fakeExecStreamer{stdout: []string{"working\n"}, block: true}
+	w := performExec(t, streamer, `{"command": ["sleep", "100"], "timeout_seconds": 1}`)
+
+	frames := readExecFrames(t, w.Body)
+	if len(frames) != 2 {
+		t.Fatalf("Expected output and error frames, got %+v", frames)
+	}
+	if frames[1].Type != "error" || frames[1].Error != "exec timed out after 1s" {
+		t.Errorf("Expected timeout error frame, got %+v", frames[1])
+	}
+}
+
+func TestExec_RejectsStoppedContainer(t *testing.T) {
+	w := performExec(t, &fakeExecStreamer{err: services.ErrContainerNotRunning}, `{"command": ["ls"]}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for stopped container, got %d", w.Code)
+	}
+}
+
+func TestExec_ValidatesRequest(t *testing.T) {
+	testCases := map[string]string{
+		"missing command":  `{}`,
+		"empty command":    `{"command": []}`,
+		"negative timeout": `{"command": ["ls"], "timeout_seconds": -1}`,
+		"timeout too long": `{"command": ["ls"], "timeout_seconds": 999999}`,
+	}
+	for name, body := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if w := performExec(t, &fakeExecStreamer{}, body); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400, got %d", w.Code)
+			}
+		})
+	}
+}
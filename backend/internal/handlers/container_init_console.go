@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// initConsoleFallbackLogs is how many container logs are sent when no init is running
+const initConsoleFallbackLogs = 200
+
+// StreamInitConsole streams the live output of the container's Claude init as Server-Sent Events.
+// While init runs, earlier events are replayed and new ones follow as "claude", "output" and
+// finally "done" events. When no init is running (finished, or the server restarted), the
+// buffered container logs are sent as "log" events followed by "done". Either way the stream
+// opens with a "progress" event carrying the stored init progress; while init runs, later
+// progress changes follow as further "progress" events.
+// GET /api/containers/:id/init-console
+func (h *ContainerHandler) StreamInitConsole(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+	container, err := h.containerService.GetContainer(id)
+	if err != nil {
+		if errors.Is(err, services.ErrContainerNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+			return
+		}
+		writeServerError(c, err)
+		return
+	}
+
+	replay, events, unsubscribe, live := h.containerService.SubscribeInitConsole(id)
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stop nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	progress := container.InitProgress
+	if !writeInitConsoleEvent(c.Writer, services.InitConsoleEvent{Type: services.InitConsoleEventProgress, Progress: &progress, Timestamp: time.Now()}) {
+		return
+	}
+
+	if !live {
+		writeInitConsoleFallback(c, h.containerService, id)
+		return
+	}
+
+	for _, evt := range replay {
+		if !writeInitConsoleEvent(c.Writer, evt) {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(constants.SSEHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			if !writeInitConsoleEvent(c.Writer, evt) {
+				return
+			}
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// writeInitConsoleFallback sends the buffered container logs, oldest first, then a done event
+func writeInitConsoleFallback(c *gin.Context, containerService *services.ContainerService, id uint) {
+	logs, err := containerService.GetContainerLogs(id, initConsoleFallbackLogs)
+	if err != nil {
+		logs = nil
+	}
+	for i := len(logs) - 1; i >= 0; i-- {
+		data, err := json.Marshal(&logs[i])
+		if err != nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(c.Writer, "event: log\ndata: %s\n\n", data); err != nil {
+			return
+		}
+	}
+	writeInitConsoleEvent(c.Writer, services.InitConsoleEvent{Type: services.InitConsoleEventDone, Timestamp: time.Now()})
+	c.Writer.Flush()
+}
+
+// writeInitConsoleEvent writes one console event; it returns false once the client is gone
+func writeInitConsoleEvent(w io.Writer, evt services.InitConsoleEvent) bool {
+	data, err := json.Marshal(&evt)
+	if err != nil {
+		return true
+	}
+	if evt.Seq > 0 {
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evt.Seq, evt.Type, data)
+	} else {
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, data)
+	}
+	return err == nil
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InspectContainer returns sanitized Docker inspect details for debugging
+// GET /api/containers/:id/inspect
+func (h *ContainerHandler) InspectContainer(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	info, err := h.containerService.InspectContainer(c.Request.Context(), id)
+	if err != nil {
+		if err == services.ErrContainerNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+			return
+		}
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
@@ -79,7 +79,7 @@ func newMockConfigInjectionService(templateService services.ConfigTemplateServic
 	}
 }
 
-func (m *mockConfigInjectionService) InjectConfigs(ctx context.Context, containerID string, templateIDs []uint) (*models.InjectionStatus, error) {
+func (m *mockConfigInjectionService) InjectConfigs(ctx context.Context, containerID string, workDir string, templateIDs []uint) (*models.InjectionStatus, error) {
 	m.injectedConfigs[containerID] = templateIDs
 
 	status := &models.InjectionStatus{
@@ -128,11 +128,11 @@ func (m *mockConfigInjectionService) InjectClaudeMD(ctx context.Context, contain
 	return nil
 }
 
-func (m *mockConfigInjectionService) InjectSkill(ctx context.Context, containerID string, name string, content string) error {
+func (m *mockConfigInjectionService) InjectSkill(ctx context.Context, containerID string, name string, content string, files models.SkillFiles) error {
 	return nil
 }
 
-func (m *mockConfigInjectionService) InjectMCP(ctx context.Context, containerID string, configs []services.MCPServerConfig) error {
+func (m *mockConfigInjectionService) InjectMCP(ctx context.Context, containerID string, workDir string, configs []services.MCPServerConfig) error {
 	return nil
 }
 
@@ -196,7 +196,7 @@ func (m *mockContainerServiceForIntegration) CreateContainer(ctx context.Context
 
 	// Inject configs if any templates were selected
 	if len(templateIDs) > 0 && m.configInjectionService != nil {
-		injectionStatus, err := m.configInjectionService.InjectConfigs(ctx, dockerID, templateIDs)
+		injectionStatus, err := m.configInjectionService.InjectConfigs(ctx, dockerID, "", templateIDs)
 		if err != nil {
 			// Log error but don't fail container creation
 			container.InjectionStatus = &models.InjectionStatus{
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PauseContainer freezes a running container without stopping its processes
+// POST /api/containers/:id/pause
+func (h *ContainerHandler) PauseContainer(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	if err := h.containerService.PauseContainer(c.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, services.ErrContainerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+		case errors.Is(err, services.ErrContainerPaused):
+			c.JSON(http.StatusConflict, gin.H{"error": "Container is already paused"})
+		case errors.Is(err, services.ErrContainerNotRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": "Container is not running"})
+		default:
+			writeServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Container paused successfully"})
+}
+
+// ResumeContainer unpauses a paused container
+// POST /api/containers/:id/resume
+func (h *ContainerHandler) ResumeContainer(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	if err := h.containerService.ResumeContainer(c.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, services.ErrContainerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+		case errors.Is(err, services.ErrContainerNotPaused):
+			c.JSON(http.StatusConflict, gin.H{"error": "Container is not paused"})
+		default:
+			writeServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Container resumed successfully"})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KillProcessRequest represents the optional body of a process kill request
+type KillProcessRequest struct {
+	Signal string `json:"signal"`
+}
+
+// ListProcesses lists the processes running inside a container
+// GET /api/containers/:id/processes
+func (h *ContainerHandler) ListProcesses(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	processes, err := h.containerService.ListProcesses(c.Request.Context(), id)
+	if err != nil {
+		writeProcessError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"processes": processes})
+}
+
+// KillProcess sends a signal (TERM by default) to a process inside a container
+// POST /api/containers/:id/processes/:pid/kill
+func (h *ContainerHandler) KillProcess(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	pid, err := strconv.Atoi(c.Param("pid"))
+	if err != nil || pid <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid process ID"})
+		return
+	}
+
+	var req KillProcessRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+	}
+	if req.Signal == "" {
+		req.Signal = c.Query("signal")
+	}
+
+	if err := h.containerService.KillProcess(c.Request.Context(), id, pid, req.Signal); err != nil {
+		writeProcessError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Signal sent", "pid": pid})
+}
+
+// writeProcessError maps process management errors to HTTP responses
+func writeProcessError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrContainerNotFound), errors.Is(err, services.ErrProcessNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrContainerNotRunning),
+		errors.Is(err, services.ErrInvalidPID),
+		errors.Is(err, services.ErrInvalidSignal):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		writeServerError(c, err)
+	}
+}
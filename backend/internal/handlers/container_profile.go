@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContainerProfileHandler handles named container creation defaults
+type ContainerProfileHandler struct {
+	service *services.ContainerProfileService
+}
+
+// NewContainerProfileHandler creates a new ContainerProfileHandler
+func NewContainerProfileHandler(service *services.ContainerProfileService) *ContainerProfileHandler {
+	return &ContainerProfileHandler{service: service}
+}
+
+// RegisterRoutes registers all container profile routes
+func (h *ContainerProfileHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	profiles := rg.Group("/container-profiles")
+	{
+		profiles.POST("", h.CreateContainerProfile)
+		profiles.GET("", h.ListContainerProfiles)
+		profiles.GET("/:id", h.GetContainerProfile)
+		profiles.PUT("/:id", h.UpdateContainerProfile)
+		profiles.DELETE("/:id", h.DeleteContainerProfile)
+	}
+}
+
+// CreateContainerProfile creates a new container profile
+// POST /api/container-profiles
+func (h *ContainerProfileHandler) CreateContainerProfile(c *gin.Context) {
+	var input services.ContainerProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	profile, err := h.service.Create(input)
+	if err != nil {
+		writeContainerProfileError(c, err, "Failed to create container profile")
+		return
+	}
+
+	c.JSON(http.StatusCreated, profile)
+}
+
+// ListContainerProfiles lists container profiles, optionally only those with a tag
+// GET /api/container-profiles?tag=
+func (h *ContainerProfileHandler) ListContainerProfiles(c *gin.Context) {
+	profiles, err := h.service.List(c.Query("tag"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list container profiles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profiles)
+}
+
+// GetContainerProfile gets a container profile by ID
+// GET /api/container-profiles/:id
+func (h *ContainerProfileHandler) GetContainerProfile(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	profile, err := h.service.GetByID(id)
+	if err != nil {
+		writeContainerProfileError(c, err, "Failed to get container profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateContainerProfile replaces a container profile
+// PUT /api/container-profiles/:id
+func (h *ContainerProfileHandler) UpdateContainerProfile(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var input services.ContainerProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	profile, err := h.service.Update(id, input)
+	if err != nil {
+		writeContainerProfileError(c, err, "Failed to update container profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeleteContainerProfile deletes a container profile
+// DELETE /api/container-profiles/:id
+func (h *ContainerProfileHandler) DeleteContainerProfile(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writeContainerProfileError(c, err, "Failed to delete container profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Container profile deleted"})
+}
+
+// writeContainerProfileError maps container profile service errors to HTTP responses
+func writeContainerProfileError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrContainerProfileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "container profile not found"})
+	case errors.Is(err, services.ErrInvalidContainerProfile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDuplicateContainerProfile):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PropagateTemplate re-injects a template into every running container that uses it
+// POST /api/claude-configs/:id/propagate
+func (h *ContainerHandler) PropagateTemplate(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	results, err := h.containerService.PropagateTemplate(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrTemplateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			return
+		}
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// DiffTemplate previews what re-injecting a template would change in the container as a
+// unified diff against the file currently there
+// GET /api/containers/:id/configs/:templateId/diff
+func (h *ContainerHandler) DiffTemplate(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		writeInvalidRequest(c, "Invalid container ID", nil)
+		return
+	}
+	templateID, err := parseID(c.Param("templateId"))
+	if err != nil {
+		writeInvalidRequest(c, "Invalid template ID", nil)
+		return
+	}
+
+	diff, err := h.containerService.DiffTemplate(c.Request.Context(), id, templateID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
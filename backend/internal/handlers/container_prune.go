@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PruneDockerContainersRequest confirms removing orphaned containers or asks for a dry run
+type PruneDockerContainersRequest struct {
+	Confirm bool `json:"confirm"`
+	DryRun  bool `json:"dry_run"`
+}
+
+// PruneDockerContainers stops and removes platform containers that have no database record
+// POST /api/docker/containers/prune
+func (h *ContainerHandler) PruneDockerContainers(c *gin.Context) {
+	var req PruneDockerContainersRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	result, err := h.containerService.PruneOrphanedContainers(c.Request.Context(), req.Confirm, req.DryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrPruneNotConfirmed) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Pruning removes orphaned containers; set \"confirm\": true to proceed or \"dry_run\": true to preview"})
+			return
+		}
+		writeServerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecreateContainerRequest confirms a destructive recreate
+type RecreateContainerRequest struct {
+	Confirm bool `json:"confirm"`
+}
+
+// RecreateContainer deletes a container and creates a fresh one with the same name and settings
+// POST /api/containers/:id/recreate
+func (h *ContainerHandler) RecreateContainer(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	var req RecreateContainerRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	container, err := h.containerService.RecreateContainer(c.Request.Context(), id, req.Confirm)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrContainerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+		case errors.Is(err, services.ErrRecreateNotConfirmed):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Recreating deletes the container's data; set \"confirm\": true to proceed"})
+		case errors.Is(err, services.ErrInitInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err == services.ErrNoGitHubTokenConfigured:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "GitHub token not configured. Please configure it in Settings."})
+		case errors.Is(err, services.ErrPreInitScriptTooLarge), errors.Is(err, services.ErrInvalidCallbackURL), errors.Is(err, services.ErrWebhookSecretNotConfigured),
+			errors.Is(err, services.ErrInvalidHostMount):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrHostMountsDisabled):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Host mounts are disabled on this server"})
+		case errors.Is(err, services.ErrRegistryNotAllowed):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrRegistryAuthFailed):
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		default:
+			writeServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"container": services.ToContainerInfo(container),
+		"message":   "Container recreated and initialization started",
+	})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateContainerResources changes CPU/memory limits of a running container in place
+// PUT /api/containers/:id/resources
+func (h *ContainerHandler) UpdateContainerResources(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	var req services.UpdateResourcesInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	container, err := h.containerService.UpdateContainerResources(c.Request.Context(), id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrContainerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+		case errors.Is(err, services.ErrNoResourceChanges), errors.Is(err, services.ErrInvalidCPULimit),
+			errors.Is(err, services.ErrInvalidMemoryLimit):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrContainerPaused):
+			c.JSON(http.StatusConflict, gin.H{"error": "Container is paused"})
+		case errors.Is(err, services.ErrContainerNotRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": "Container is not running"})
+		case errors.Is(err, services.ErrResourceUpdateFailed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			writeServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, services.ToContainerInfo(container))
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/services"
+
+	"github.com/docker/docker/api/types"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// containerStatsStreamer opens a live Docker stats stream for a container
+type containerStatsStreamer interface {
+	OpenStatsStream(ctx context.Context, containerID uint) (io.ReadCloser, error)
+}
+
+// ContainerStatsHandler streams live container resource usage over WebSocket
+type ContainerStatsHandler struct {
+	statsStreamer containerStatsStreamer
+	authService   *services.AuthService
+	interval      time.Duration
+}
+
+// NewContainerStatsHandler creates a new ContainerStatsHandler
+func NewContainerStatsHandler(containerService *services.ContainerService, authService *services.AuthService) *ContainerStatsHandler {
+	return &ContainerStatsHandler{
+		statsStreamer: containerService,
+		authService:   authService,
+		interval:      constants.ContainerStatsInterval,
+	}
+}
+
+// containerStatsMessage is a message pushed to stats WebSocket clients
+type containerStatsMessage struct {
+	Type    string      `json:"type"` // stats | closed | error
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// HandleStatsWebSocket streams CPU, memory and network samples for a running container
+// GET /api/ws/containers/:id/stats
+func (h *ContainerStatsHandler) HandleStatsWebSocket(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	if !authorizeWebSocket(c, h.authService, "ContainerStatsHandler") {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := h.statsStreamer.OpenStatsStream(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrContainerNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+		case errors.Is(err, services.ErrContainerNotRunning):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Container is not running"})
+		default:
+			writeServerError(c, err)
+		}
+		return
+	}
+	// Closing the body is what stops the Docker stats stream
+	defer stream.Close()
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, webSocketResponseHeader(c.Request))
+	if err != nil {
+		log.Printf("[ContainerStatsHandler] Failed to upgrade connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	var (
+		mu     sync.Mutex
+		latest *services.ContainerStatsSample
+	)
+
+	// Decode frames as fast as Docker emits them; only the newest one is forwarded
+	streamDone := make(chan error, 1)
+	go func() {
+		decoder := json.NewDecoder(stream)
+		for {
+			var frame types.StatsJSON
+			if err := decoder.Decode(&frame); err != nil {
+				streamDone <- err
+				return
+			}
+			sample := services.ParseStatsSample(&frame)
+			mu.Lock()
+			latest = &sample
+			mu.Unlock()
+		}
+	}()
+
+	// Reading is only used to notice client disconnects and pongs
+	clientGone := make(chan struct{})
+	conn.SetReadDeadline(time.Now().Add(constants.WebSocketPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(constants.WebSocketPongTimeout))
+		return nil
+	})
+	go func() {
+		defer close(clientGone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(msg containerStatsMessage) error {
+		conn.SetWriteDeadline(time.Now().Add(constants.WebSocketWriteTimeout))
+		return conn.WriteJSON(msg)
+	}
+
+	sendLatest := func() error {
+		mu.Lock()
+		sample := latest
+		latest = nil
+		mu.Unlock()
+		if sample == nil {
+			return nil
+		}
+		return write(containerStatsMessage{Type: "stats", Payload: sample})
+	}
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	pingTicker := time.NewTicker(constants.WebSocketPingInterval)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := sendLatest(); err != nil {
+				return
+			}
+		case <-pingTicker.C:
+			conn.SetWriteDeadline(time.Now().Add(constants.WebSocketWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case err := <-streamDone:
+			// Docker ends the stream when the container stops
+			sendLatest()
+			reason := "container_stopped"
+			if err != nil && !errors.Is(err, io.EOF) {
+				reason = err.Error()
+			}
+			write(containerStatsMessage{Type: "closed", Payload: gin.H{"reason": reason}})
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason),
+				time.Now().Add(constants.WebSocketWriteTimeout))
+			return
+		case <-clientGone:
+			return
+		}
+	}
+}
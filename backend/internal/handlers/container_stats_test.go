@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cc-platform/internal/services"
+
+	"github.com/docker/docker/api/types"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// fakeStatsStream is an io.ReadCloser fed with Docker stats frames by the test
+type fakeStatsStream struct {
+	reader *io.PipeReader
+	writer *io.PipeWriter
+	mu     sync.Mutex
+	closed bool
+}
+
+func newFakeStatsStream() *fakeStatsStream {
+	r, w := io.Pipe()
+	return &fakeStatsStream{reader: r, writer: w}
+}
+
+func (f *fakeStatsStream) Read(p []byte) (int, error) { return f.reader.Read(p) }
+
+func (f *fakeStatsStream) Close() error {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	f.writer.Close()
+	return f.reader.Close()
+}
+
+func (f *fakeStatsStream) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+func (f *fakeStatsStream) emit(t *testing.T, frame types.StatsJSON) {
+	t.Helper()
+	data, _ := json.Marshal(frame)
+	if _, err := f.writer.Write(append(data, '\n')); err != nil {
+		t.Fatalf("failed to emit frame: %v", err)
+	}
+}
+
+// fakeStatsStreamer hands out a single fake stream, standing in for the Docker client
+type fakeStatsStreamer struct {
+	stream *fakeStatsStream
+	err    error
+}
+
+func (f *fakeStatsStreamer) OpenStatsStream(ctx context.Context, containerID uint) (io.ReadCloser, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.stream, nil
+}
+
+func sampleStatsFrame(totalUsage, systemUsage uint64) types.StatsJSON {
+	var frame types.StatsJSON
+	frame.Read = time.Now()
+	frame.CPUStats.CPUUsage.TotalUsage = totalUsage
+	frame.CPUStats.SystemUsage = systemUsage
+	frame.CPUStats.OnlineCPUs = 2
+	frame.PreCPUStats.CPUUsage.TotalUsage = totalUsage - 100
+	frame.PreCPUStats.SystemUsage = systemUsage - 1000
+	frame.MemoryStats.Usage = 300
+	frame.MemoryStats.Limit = 1000
+	frame.MemoryStats.Stats = map[string]uint64{"inactive_file": 100}
+	frame.Networks = map[string]types.NetworkStats{
+		"eth0": {RxBytes: 10, TxBytes: 20},
+		"eth1": {RxBytes: 1, TxBytes: 2},
+	}
+	return frame
+}
+
+func setupStatsTestServer(streamer containerStatsStreamer) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := &ContainerStatsHandler{statsStreamer: streamer, interval: 20 * time.Millisecond}
+	router.GET("/api/ws/containers/:id/stats", handler.HandleStatsWebSocket)
+	return httptest.NewServer(router)
+}
+
+func dialStats(t *testing.T, server *httptest.Server) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws/containers/1/stats"
+	header := http.Header{"Origin": []string{"http://localhost:5173"}}
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatalf("failed to dial stats websocket: %v", err)
+	}
+	return conn
+}
+
+func TestParseStatsSample_ComputesUsage(t *testing.T) {
+	frame := sampleStatsFrame(1100, 11000)
+	sample := services.ParseStatsSample(&frame)
+
+	if sample.CPUPercent != 20 {
+		t.Errorf("Expected CPU 20%%, got %v", sample.CPUPercent)
+	}
+	if sample.MemoryUsage != 200 || sample.MemoryPercent != 20 {
+		t.Errorf("Expected memory 200 (20%%), got %d (%v%%)", sample.MemoryUsage, sample.MemoryPercent)
+	}
+	if sample.NetworkRx != 11 || sample.NetworkTx != 22 {
+		t.Errorf("Expected network rx=11 tx=22, got rx=%d tx=%d", sample.NetworkRx, sample.NetworkTx)
+	}
+}
+
+func TestHandleStatsWebSocket_StreamsSamplesUntilContainerStops(t *testing.T) {
+	stream := newFakeStatsStream()
+	server := setupStatsTestServer(&fakeStatsStreamer{stream: stream})
+	defer server.Close()
+
+	conn := dialStats(t, server)
+	defer conn.Close()
+
+	stream.emit(t, sampleStatsFrame(1100, 11000))
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg struct {
+		Type    string                        `json:"type"`
+		Payload services.ContainerStatsSample `json:"payload"`
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("failed to read stats message: %v", err)
+	}
+	if msg.Type != "stats" || msg.Payload.CPUPercent != 20 {
+		t.Errorf("Unexpected stats message: %+v", msg)
+	}
+
+	// Docker closes the stream when the container stops
+	stream.writer.Close()
+
+	var closed containerStatsMessage
+	if err := conn.ReadJSON(&closed); err != nil {
+		t.Fatalf("failed to read closed message: %v", err)
+	}
+	if closed.Type != "closed" {
+		t.Errorf("Expected closed message, got %+v", closed)
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("Expected normal close, got %v", err)
+	}
+}
+
+func TestHandleStatsWebSocket_ClientDisconnectClosesStream(t *testing.T) {
+	stream := newFakeStatsStream()
+	server := setupStatsTestServer(&fakeStatsStreamer{stream: stream})
+	defer server.Close()
+
+	conn := dialStats(t, server)
+	conn.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !stream.isClosed() {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected Docker stats stream to be closed after client disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHandleStatsWebSocket_ContainerNotRunning(t *testing.T) {
+	server := setupStatsTestServer(&fakeStatsStreamer{err: services.ErrContainerNotRunning})
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/ws/containers/1/stats")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for stopped container, got %d", resp.StatusCode)
+	}
+}
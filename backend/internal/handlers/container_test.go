@@ -115,7 +115,7 @@ type testContainerHandler struct {
 func (h *testContainerHandler) CreateContainer(c *gin.Context) {
 	var req CreateContainerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		writeInvalidRequest(c, "Invalid request body", err)
 		return
 	}
 
@@ -133,7 +133,7 @@ func (h *testContainerHandler) CreateContainer(c *gin.Context) {
 
 	container, err := h.mockService.CreateContainer(c.Request.Context(), input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 
@@ -146,17 +146,13 @@ func (h *testContainerHandler) CreateContainer(c *gin.Context) {
 func (h *testContainerHandler) GetContainer(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
 	container, err := h.mockService.GetContainer(id)
 	if err != nil {
-		if err == services.ErrContainerNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get container"})
+		writeServiceError(c, err)
 		return
 	}
 
@@ -500,9 +496,10 @@ func TestGetContainer_NotFound(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, w.Code, "Expected status 404, got %d. Body: %s", w.Code, w.Body.String())
 
-	var response map[string]string
+	var response apiErrorResponse
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(t, "Container not found", response["error"])
+	assert.Equal(t, "CONTAINER_NOT_FOUND", response.Error.Code)
+	assert.Equal(t, "Container not found", response.Error.Message)
 }
 
 // TestGetContainer_InvalidID tests 400 response for invalid ID format
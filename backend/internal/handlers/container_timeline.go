@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetContainerTimeline returns a container's activity timeline, oldest event first
+// GET /api/containers/:id/timeline?type=status,mode_switch&limit=100&offset=0
+func (h *ContainerHandler) GetContainerTimeline(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	types, err := services.ParseTimelineTypes(c.Query("type"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query := services.TimelineQuery{Types: types}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if query.Limit, err = strconv.Atoi(limitStr); err != nil || query.Limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if query.Offset, err = strconv.Atoi(offsetStr); err != nil || query.Offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+			return
+		}
+	}
+
+	timeline, err := h.containerService.GetTimeline(id, query)
+	if err != nil {
+		if err == services.ErrContainerNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+			return
+		}
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetContainerTimings returns how long each initialization stage of a container took
+// GET /api/containers/:id/timings
+func (h *ContainerHandler) GetContainerTimings(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	timing, err := h.containerService.GetInitTiming(id)
+	if err != nil {
+		if err == services.ErrContainerNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+			return
+		}
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timing)
+}
+
+// GetInitTimingStats returns per-stage initialization durations aggregated across containers
+// GET /api/containers/init-timings
+func (h *ContainerHandler) GetInitTimingStats(c *gin.Context) {
+	stats, err := h.containerService.GetInitTimingStats()
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stages": stats})
+}
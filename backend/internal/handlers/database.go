@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DatabaseHandler handles database maintenance
+type DatabaseHandler struct {
+	service *services.DatabaseMaintenanceService
+}
+
+// NewDatabaseHandler creates a new DatabaseHandler
+func NewDatabaseHandler(service *services.DatabaseMaintenanceService) *DatabaseHandler {
+	return &DatabaseHandler{service: service}
+}
+
+// RegisterRoutes registers the database maintenance routes
+func (h *DatabaseHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.POST("/admin/db/vacuum", h.Vacuum)
+}
+
+// Vacuum compacts the SQLite database and reports the reclaimed space
+// POST /api/admin/db/vacuum
+func (h *DatabaseHandler) Vacuum(c *gin.Context) {
+	result, err := h.service.Vacuum(c.Request.Context())
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
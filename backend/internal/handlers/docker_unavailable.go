@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dockerUnavailableMessage tells clients the failure is temporary and not caused by their request
+const dockerUnavailableMessage = "Docker daemon is unavailable; try again once it is back"
+
+// writeServerError answers 503 when the Docker daemon cannot be reached and 500 otherwise
+func writeServerError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrDockerUnavailable) {
+		writeAPIError(c, http.StatusServiceUnavailable, ErrCodeDockerUnavailable, dockerUnavailableMessage)
+		return
+	}
+	writeAPIError(c, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"io"
 	"log"
 	"mime/multipart"
@@ -8,6 +9,7 @@ import (
 	pathpkg "path"
 	"strings"
 
+	"cc-platform/internal/middleware"
 	"cc-platform/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -118,6 +120,11 @@ func (h *FileHandler) UploadFile(c *gin.Context) {
 	}
 
 	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": middleware.BodyTooLargeMessage(maxBytesErr.Limit)})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart form"})
 		return
 	}
@@ -222,6 +229,7 @@ func (h *FileHandler) CreateDirectory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Path is required"})
 		return
 	}
+	c.Set(middleware.AuditTargetKey, req.Path)
 
 	err = h.fileService.CreateDirectory(c.Request.Context(), containerID, req.Path)
 	if err != nil {
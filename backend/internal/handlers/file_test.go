@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUploadFile_BodyTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/files/:id/upload", middleware.MaxBodySize(1024), NewFileHandler(nil).UploadFile)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "big.txt")
+	part.Write(bytes.Repeat([]byte("x"), 4096))
+	writer.Close()
+
+	// Without a Content-Length the limit is hit while the multipart form is parsed
+	req := httptest.NewRequest(http.MethodPost, "/files/1/upload", &body)
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "1024 byte limit") {
+		t.Errorf("Expected 413 with the limit, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -3,17 +3,20 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 	"sync"
 	"time"
 
+	"cc-platform/internal/constants"
 	"cc-platform/internal/headless"
-	"cc-platform/internal/middleware"
 	"cc-platform/internal/mode"
 	"cc-platform/internal/models"
 	"cc-platform/internal/services"
+	"cc-platform/internal/terminal"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -21,13 +24,13 @@ import (
 )
 
 const (
-	headlessWriteWait       = 10 * time.Second
-	headlessPongWait        = 60 * time.Second
-	headlessPingPeriod      = (headlessPongWait * 9) / 10
-	headlessMaxMessage      = 16 * 1024
+	headlessMaxMessage      = 16 * 1024 // 默认的客户端消息大小上限
 	headlessSendEnqueueWait = 250 * time.Millisecond
 	defaultHistoryLimit     = 10 // 默认加载历史轮次数量
 	defaultHistoryLimitOld  = 3  // 旧版 container 模式默认加载数量
+
+	currentTurnReplayPageSize     = 50                    // 重连补发当前轮次事件的分页大小
+	currentTurnReplayPollInterval = 20 * time.Millisecond // 补发时等待发送队列腾出空间的轮询间隔
 )
 
 // HeadlessHandler 处理 Headless WebSocket 端点
@@ -36,6 +39,8 @@ type HeadlessHandler struct {
 	modeManager      *mode.ModeManager
 	containerService *services.ContainerService
 	authService      *services.AuthService
+	promptTemplates  *services.PromptTemplateService
+	wsSettings       terminal.WebSocketSettings
 }
 
 // NewHeadlessHandler 创建新的 HeadlessHandler
@@ -50,7 +55,28 @@ func NewHeadlessHandler(
 		modeManager:      modeManager,
 		containerService: containerService,
 		authService:      authService,
+		wsSettings:       terminal.DefaultWebSocketSettings(headlessMaxMessage),
+	}
+}
+
+// SetWebSocketSettings 设置 WebSocket 的心跳间隔、读写超时和消息大小上限，非法设置会被拒绝并保留当前值
+func (h *HeadlessHandler) SetWebSocketSettings(settings terminal.WebSocketSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
 	}
+	h.wsSettings = settings
+	return nil
+}
+
+// SetPromptTemplateService 设置 prompt 模板库，用于渲染引用 template_id 的 prompt
+func (h *HeadlessHandler) SetPromptTemplateService(promptTemplates *services.PromptTemplateService) {
+	h.promptTemplates = promptTemplates
+}
+
+// isContainerPaused 检查容器是否已暂停；暂停的容器无法执行新的 prompt
+func (h *HeadlessHandler) isContainerPaused(containerID uint) bool {
+	container, err := h.containerService.GetContainer(containerID)
+	return err == nil && container.Status == models.ContainerStatusPaused
 }
 
 // HandleHeadlessWebSocket 处理 Headless WebSocket 连接
@@ -74,38 +100,22 @@ func (h *HeadlessHandler) HandleHeadlessWebSocket(c *gin.Context) {
 		return
 	}
 
+	if container.Status == models.ContainerStatusPaused {
+		c.JSON(http.StatusConflict, gin.H{"error": "Container is paused"})
+		return
+	}
 	if container.Status != models.ContainerStatusRunning {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Container is not running"})
 		return
 	}
 
-	// 认证
-	clientIP := c.ClientIP()
-	isInternalRequest := isDockerInternalIP(clientIP)
-
-	if !isInternalRequest {
-		var token string
-		if cookieToken, err := c.Cookie(middleware.TokenCookieName); err == nil && cookieToken != "" {
-			token = cookieToken
-		}
-		if token == "" {
-			token = c.Query("token")
-		}
-		if token == "" {
-			log.Printf("[HeadlessHandler] Missing auth token from %s (origin: %s)", c.ClientIP(), c.GetHeader("Origin"))
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing authentication token"})
-			return
-		}
-		_, err = h.authService.VerifyToken(token)
-		if err != nil {
-			log.Printf("[HeadlessHandler] Invalid auth token from %s (origin: %s): %v", c.ClientIP(), c.GetHeader("Origin"), err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
-			return
-		}
+	// 认证：子协议、cookie 或 query 中的 token
+	if !authorizeWebSocket(c, h.authService, "HeadlessHandler") {
+		return
 	}
 
 	// 升级 WebSocket
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, webSocketResponseHeader(c.Request))
 	if err != nil {
 		log.Printf("[HeadlessHandler] Failed to upgrade connection from %s (origin: %s): %v", c.ClientIP(), c.GetHeader("Origin"), err)
 		return
@@ -113,12 +123,7 @@ func (h *HeadlessHandler) HandleHeadlessWebSocket(c *gin.Context) {
 	defer conn.Close()
 
 	// 配置 WebSocket 读写超时
-	conn.SetReadLimit(headlessMaxMessage)
-	conn.SetReadDeadline(time.Now().Add(headlessPongWait))
-	conn.SetPongHandler(func(string) error {
-		conn.SetReadDeadline(time.Now().Add(headlessPongWait))
-		return nil
-	})
+	h.wsSettings.Apply(conn)
 
 	// 生成客户端 ID
 	clientID := uuid.New().String()
@@ -135,6 +140,7 @@ func (h *HeadlessHandler) HandleHeadlessWebSocket(c *gin.Context) {
 		workDir:     container.WorkDir,
 		sendChan:    make(chan *headless.HeadlessResponse, 100),
 		done:        make(chan struct{}),
+		resume:      parseResumeParams(c),
 	}
 
 	// 启动发送 goroutine
@@ -156,6 +162,7 @@ type headlessClient struct {
 	done        chan struct{}
 	session     *headless.HeadlessSession
 	eventChan   chan *headless.StreamEvent
+	resume      resumeParams
 	mu          sync.Mutex
 }
 
@@ -172,6 +179,12 @@ func (c *headlessClient) handleConnection() {
 	if session != nil {
 		c.session = session
 
+		// 断线重连：只补发 last_seq 之后的事件
+		if c.resumeSession(session) {
+			c.readPump()
+			return
+		}
+
 		// 如果会话正在运行，先订阅以避免丢事件
 		if session.IsRunning() {
 			c.subscribeToSession(session)
@@ -223,24 +236,11 @@ func (c *headlessClient) sendHistory(session *headless.HeadlessSession) {
 
 	// 如果会话正在运行，发送当前轮次的已有事件
 	if session.IsRunning() && session.GetCurrentTurnID() > 0 {
-		events, err := historyManager.GetCurrentTurnEvents(session.GetCurrentTurnID())
+		err := replayCurrentTurnEvents(historyManager, session, c.resume, c.sendChan, c.done, func(evt *headless.StreamEvent, seq uint64) {
+			c.sendSeqResponse(headless.HeadlessResponseTypeEvent, evt, seq)
+		})
 		if err != nil {
 			log.Printf("[HeadlessHandler] Failed to get current turn events: %v", err)
-			return
-		}
-
-		for _, event := range events {
-			// 解析原始 JSON 为 StreamEvent
-			var evt headless.StreamEvent
-			if err := json.Unmarshal([]byte(event.RawJSON), &evt); err == nil {
-				c.sendResponse(headless.HeadlessResponseTypeEvent, &evt)
-				continue
-			}
-
-			// fallback：处理非 JSON 行（stderr/纯文本）
-			if fallbackEvt, _ := headless.ParseStreamLine(event.RawJSON); fallbackEvt != nil {
-				c.sendResponse(headless.HeadlessResponseTypeEvent, fallbackEvt)
-			}
 		}
 	}
 }
@@ -289,47 +289,64 @@ func (c *headlessClient) subscribeToSession(session *headless.HeadlessSession) {
 	}
 
 	c.eventChan = session.AddClient(c.clientID)
+	c.forwardEvents(c.eventChan)
+}
+
+// resumeSession 按连接参数 last_seq 断线重连：会话未变且缓冲包含之后的全部事件时，
+// 只补发这些事件并继续订阅，返回 true；否则返回 false，由调用方加载完整历史
+func (c *headlessClient) resumeSession(session *headless.HeadlessSession) bool {
+	if !c.resume.ok || (c.resume.sessionID != "" && c.resume.sessionID != session.ID) {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch, replay, ok := session.AddClientAfter(c.clientID, c.resume.lastSeq)
+	if !ok {
+		return false
+	}
+	c.eventChan = ch
+
+	c.sendResponse(headless.HeadlessResponseTypeSessionInfo, session.GetSessionInfo())
+	for _, evt := range replay {
+		c.sendStreamEvent(evt)
+	}
+	c.forwardEvents(ch)
+	return true
+}
 
-	// 使用 ready channel 确保 goroutine 已启动
+// forwardEvents 启动事件转发 goroutine，返回前确保 goroutine 已启动
+func (c *headlessClient) forwardEvents(eventChan chan *headless.StreamEvent) {
 	ready := make(chan struct{})
 
-	// 启动事件转发 goroutine
 	go func() {
 		// 通知调用者 goroutine 已启动
 		close(ready)
 
 		for {
 			select {
-			case evt, ok := <-c.eventChan:
+			case evt, ok := <-eventChan:
 				if !ok {
 					return
 				}
-				// 检查是否为特殊元事件
-				if evt.Type == "turn_complete" {
-					var payload headless.TurnCompletePayload
-					if evt.Result != "" && json.Unmarshal([]byte(evt.Result), &payload) == nil {
-						c.sendResponse(headless.HeadlessResponseTypeTurnComplete, &payload)
-					} else {
-						c.sendResponse(headless.HeadlessResponseTypeTurnComplete, evt.Result)
-					}
-				} else if evt.Type == "queue_update" {
-					var payload headless.QueueUpdatePayload
-					if evt.Result != "" && json.Unmarshal([]byte(evt.Result), &payload) == nil {
-						c.sendResponse(headless.HeadlessResponseTypeQueueUpdate, &payload)
-					}
-				} else {
-					c.sendResponse(headless.HeadlessResponseTypeEvent, evt)
-				}
+				c.sendStreamEvent(evt)
 			case <-c.done:
 				return
 			}
 		}
 	}()
 
-	// 等待 goroutine 启动完成
 	<-ready
 }
 
+// sendStreamEvent 发送会话事件，附带事件序号
+func (c *headlessClient) sendStreamEvent(evt *headless.StreamEvent) {
+	if respType, payload, ok := streamEventResponse(evt); ok {
+		c.sendSeqResponse(respType, payload, evt.Seq)
+	}
+}
+
 // unsubscribeFromSession 取消订阅会话输出
 func (c *headlessClient) unsubscribeFromSession() {
 	c.mu.Lock()
@@ -369,7 +386,7 @@ func (c *headlessClient) readPump() {
 		}
 
 		// 收到任意消息都延长读超时，避免依赖 pong
-		c.conn.SetReadDeadline(time.Now().Add(headlessPongWait))
+		c.conn.SetReadDeadline(time.Now().Add(c.handler.wsSettings.PongWait))
 
 		// 处理消息
 		c.handleMessage(&req)
@@ -378,7 +395,7 @@ func (c *headlessClient) readPump() {
 
 // writePump 发送消息到客户端
 func (c *headlessClient) writePump() {
-	ticker := time.NewTicker(headlessPingPeriod)
+	ticker := time.NewTicker(c.handler.wsSettings.PingPeriod)
 	defer ticker.Stop()
 
 	for {
@@ -387,14 +404,14 @@ func (c *headlessClient) writePump() {
 			if !ok {
 				return
 			}
-			c.conn.SetWriteDeadline(time.Now().Add(headlessWriteWait))
+			c.conn.SetWriteDeadline(time.Now().Add(c.handler.wsSettings.WriteWait))
 			if err := c.conn.WriteJSON(resp); err != nil {
 				log.Printf("[HeadlessHandler] Client %s write error: %v", c.clientID, err)
 				return
 			}
 		case <-ticker.C:
 			// 发送 ping
-			c.conn.SetWriteDeadline(time.Now().Add(headlessWriteWait))
+			c.conn.SetWriteDeadline(time.Now().Add(c.handler.wsSettings.WriteWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
@@ -413,6 +430,12 @@ func (c *headlessClient) handleMessage(req *headless.HeadlessRequest) {
 		c.handlePrompt(req)
 	case headless.HeadlessRequestTypeCancel:
 		c.handleCancel(req)
+	case headless.HeadlessRequestTypeCancelTurn:
+		c.handleCancelTurn(req)
+	case headless.HeadlessRequestTypeResetContext:
+		c.handleResetContext(req)
+	case headless.HeadlessRequestTypeCompact:
+		c.handleCompact(req)
 	case headless.HeadlessRequestTypeStopSession:
 		c.handleStopSession(req)
 	case headless.HeadlessRequestTypeLoadMore:
@@ -431,9 +454,311 @@ func (c *headlessClient) handleMessage(req *headless.HeadlessRequest) {
 	}
 }
 
+// promptFilePaths 读取 prompt 请求中的 file_paths（可选）
+func promptFilePaths(req *headless.HeadlessRequest) ([]string, error) {
+	raw, ok := req.Payload["file_paths"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("file_paths must be an array of strings")
+	}
+	filePaths := make([]string, 0, len(items))
+	for _, item := range items {
+		p, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("file_paths must be an array of strings")
+		}
+		filePaths = append(filePaths, p)
+	}
+	return filePaths, nil
+}
+
+// promptText 返回请求的 prompt：带 template_id 时用 variables 渲染 prompt 模板，否则取 prompt 字段
+func (h *HeadlessHandler) promptText(req *headless.HeadlessRequest) (string, error) {
+	rawID, ok := req.Payload["template_id"]
+	if !ok || rawID == nil {
+		prompt, _ := req.Payload["prompt"].(string)
+		if prompt == "" {
+			return "", fmt.Errorf("Missing prompt")
+		}
+		return prompt, nil
+	}
+
+	id, ok := rawID.(float64)
+	if !ok || id <= 0 || id != float64(uint(id)) {
+		return "", fmt.Errorf("template_id must be a positive integer")
+	}
+	vars := make(map[string]string)
+	if raw, ok := req.Payload["variables"]; ok && raw != nil {
+		items, ok := raw.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("variables must be an object of strings")
+		}
+		for name, value := range items {
+			s, ok := value.(string)
+			if !ok {
+				return "", fmt.Errorf("variables must be an object of strings")
+			}
+			vars[name] = s
+		}
+	}
+	if h.promptTemplates == nil {
+		return "", fmt.Errorf("prompt templates are not available")
+	}
+	prompt, err := h.promptTemplates.Render(uint(id), vars)
+	if err != nil {
+		return "", err
+	}
+	if prompt == "" {
+		return "", fmt.Errorf("Missing prompt")
+	}
+	return prompt, nil
+}
+
+// resumeParams 断线重连参数（连接 URL 的 last_seq 和 session_id）
+type resumeParams struct {
+	ok        bool   // 是否带有有效的 last_seq
+	lastSeq   uint64 // 客户端收到的最后一个事件序号
+	sessionID string // 客户端之前连接的会话 ID，不同则无法按序号补发
+}
+
+// resumeAfterSeq 返回补发当前轮次事件时可跳过的序号：客户端带有同一会话的 last_seq 时，
+// 已收到的事件无需再从数据库补发；否则返回 0 补发全部
+func resumeAfterSeq(resume resumeParams, session *headless.HeadlessSession) uint64 {
+	if !resume.ok || (resume.sessionID != "" && resume.sessionID != session.ID) {
+		return 0
+	}
+	if resume.lastSeq > session.LastEventSeq() {
+		// 序号超出当前会话范围（如服务重启后序号重新计数），不可信
+		return 0
+	}
+	return resume.lastSeq
+}
+
+// replayCurrentTurnEvents 分页补发当前轮次已有的事件。每页发送前等待发送队列腾出一页的空间，
+// 避免事件很多时队列写满导致补发事件被丢弃；连接关闭时提前返回
+func replayCurrentTurnEvents(historyManager *headless.HeadlessHistoryManager, session *headless.HeadlessSession, resume resumeParams,
+	sendChan chan *headless.HeadlessResponse, done chan struct{}, send func(evt *headless.StreamEvent, seq uint64)) error {
+	pageSize := currentTurnReplayPageSize
+	if room := cap(sendChan) / 2; room > 0 && room < pageSize {
+		pageSize = room
+	}
+
+	afterSeq := resumeAfterSeq(resume, session)
+	return historyManager.StreamCurrentTurnEvents(session.GetCurrentTurnID(), afterSeq, pageSize, func(events []models.HeadlessEvent) bool {
+		if !waitSendRoom(sendChan, len(events), done) {
+			return false
+		}
+
+		for _, event := range events {
+			// 解析原始 JSON 为 StreamEvent
+			// 带上持久化的序号，客户端可据此丢弃订阅后已收到的重复事件
+			var evt headless.StreamEvent
+			if err := json.Unmarshal([]byte(event.RawJSON), &evt); err == nil {
+				send(&evt, event.Seq)
+				continue
+			}
+
+			// fallback：处理非 JSON 行（stderr/纯文本）
+			if fallbackEvt, _ := headless.ParseStreamLine(event.RawJSON); fallbackEvt != nil {
+				send(fallbackEvt, event.Seq)
+			}
+		}
+		return true
+	})
+}
+
+// waitSendRoom 等待发送队列至少有 n 个空位；连接关闭时返回 false
+func waitSendRoom(sendChan chan *headless.HeadlessResponse, n int, done chan struct{}) bool {
+	ticker := time.NewTicker(currentTurnReplayPollInterval)
+	defer ticker.Stop()
+
+	for cap(sendChan)-len(sendChan) < n {
+		select {
+		case <-done:
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// parseResumeParams 读取断线重连参数；last_seq 缺失或无效时按新连接处理
+func parseResumeParams(c *gin.Context) resumeParams {
+	lastSeq, err := strconv.ParseUint(c.Query("last_seq"), 10, 64)
+	if err != nil {
+		return resumeParams{}
+	}
+	return resumeParams{ok: true, lastSeq: lastSeq, sessionID: c.Query("session_id")}
+}
+
+// streamEventResponse 将会话事件转换为响应类型和负载，元事件解析为对应负载
+func streamEventResponse(evt *headless.StreamEvent) (string, interface{}, bool) {
+	switch evt.Type {
+	case "turn_complete":
+		var payload headless.TurnCompletePayload
+		if evt.Result != "" && json.Unmarshal([]byte(evt.Result), &payload) == nil {
+			return headless.HeadlessResponseTypeTurnComplete, &payload, true
+		}
+		return headless.HeadlessResponseTypeTurnComplete, evt.Result, true
+	case "queue_update":
+		var payload headless.QueueUpdatePayload
+		if evt.Result != "" && json.Unmarshal([]byte(evt.Result), &payload) == nil {
+			return headless.HeadlessResponseTypeQueueUpdate, &payload, true
+		}
+		return "", nil, false
+	case "session_dead":
+		var payload headless.SessionDeadPayload
+		if evt.Result != "" && json.Unmarshal([]byte(evt.Result), &payload) == nil {
+			return headless.HeadlessResponseTypeSessionDead, &payload, true
+		}
+		return "", nil, false
+	case "session_stopped":
+		var payload headless.SessionStoppedPayload
+		if evt.Result != "" && json.Unmarshal([]byte(evt.Result), &payload) == nil {
+			return headless.HeadlessResponseTypeSessionStopped, &payload, true
+		}
+		return "", nil, false
+	default:
+		return headless.HeadlessResponseTypeEvent, evt, true
+	}
+}
+
+// sendPromptErrorCode 将发送 prompt 的错误转换为错误码：文件引用问题属于无效请求，达到停止条件单独区分
+func sendPromptErrorCode(err error) string {
+	if errors.Is(err, headless.ErrInvalidFilePath) ||
+		errors.Is(err, headless.ErrTooManyFiles) ||
+		errors.Is(err, headless.ErrFileNotFound) {
+		return headless.ErrorCodeInvalidRequest
+	}
+	if errors.Is(err, headless.ErrConversationLimitReached) {
+		return headless.ErrorCodeLimitReached
+	}
+	return headless.ErrorCodeProcessFailed
+}
+
+// compactErrorCode 将压缩错误映射为错误码
+func compactErrorCode(err error) string {
+	if errors.Is(err, headless.ErrCompactionUnsupported) || errors.Is(err, headless.ErrNothingToCompact) {
+		return headless.ErrorCodeInvalidRequest
+	}
+	return headless.ErrorCodeProcessFailed
+}
+
+// startSystemPrompt 读取 start 请求中的 system_prompt；provided 为 false 表示请求未携带该字段
+func startSystemPrompt(req *headless.HeadlessRequest) (systemPrompt string, provided bool, err error) {
+	systemPrompt, provided = req.Payload["system_prompt"].(string)
+	if provided && len(systemPrompt) > constants.HeadlessSystemPromptMaxBytes {
+		return "", false, fmt.Errorf("%w: max %d bytes", headless.ErrSystemPromptTooLarge, constants.HeadlessSystemPromptMaxBytes)
+	}
+	return systemPrompt, provided, nil
+}
+
+// startExtraArgs 读取并校验 start 请求中的 extra_args；provided 为 false 表示请求未携带该字段
+func startExtraArgs(req *headless.HeadlessRequest) (extraArgs []string, provided bool, err error) {
+	raw, ok := req.Payload["extra_args"]
+	if !ok || raw == nil {
+		return nil, false, nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, false, fmt.Errorf("extra_args must be an array of strings")
+	}
+	for _, item := range items {
+		arg, ok := item.(string)
+		if !ok {
+			return nil, false, fmt.Errorf("extra_args must be an array of strings")
+		}
+		extraArgs = append(extraArgs, arg)
+	}
+	if extraArgs, err = headless.NormalizeCLIArgs(extraArgs); err != nil {
+		return nil, false, err
+	}
+	return extraArgs, true, nil
+}
+
+// startLimits 读取 start 请求中的 max_turns 和 max_duration_minutes，未携带的字段返回 nil
+func startLimits(req *headless.HeadlessRequest) (maxTurns, maxDurationMinutes *int, err error) {
+	read := func(key string, max int) (*int, error) {
+		raw, ok := req.Payload[key]
+		if !ok || raw == nil {
+			return nil, nil
+		}
+		n, ok := raw.(float64)
+		if !ok || n < 0 || n > float64(max) || n != float64(int(n)) {
+			return nil, fmt.Errorf("%w: %s must be an integer between 0 and %d", headless.ErrInvalidConversationLimits, key, max)
+		}
+		v := int(n)
+		return &v, nil
+	}
+	if maxTurns, err = read("max_turns", constants.HeadlessMaxTurnsLimit); err != nil {
+		return nil, nil, err
+	}
+	if maxDurationMinutes, err = read("max_duration_minutes", constants.HeadlessMaxDurationMinutesLimit); err != nil {
+		return nil, nil, err
+	}
+	return maxTurns, maxDurationMinutes, nil
+}
+
+// startCancelOnDisconnect 读取 start 请求中的 cancel_on_disconnect；provided 为 false 表示请求未携带该字段
+func startCancelOnDisconnect(req *headless.HeadlessRequest) (enabled bool, provided bool, err error) {
+	raw, ok := req.Payload["cancel_on_disconnect"]
+	if !ok || raw == nil {
+		return false, false, nil
+	}
+	if enabled, ok = raw.(bool); !ok {
+		return false, false, fmt.Errorf("cancel_on_disconnect must be a boolean")
+	}
+	return enabled, true, nil
+}
+
 // handleStart 处理创建会话请求
 func (c *headlessClient) handleStart(req *headless.HeadlessRequest) {
 	forceNew, _ := req.Payload["force_new"].(bool)
+	systemPrompt, hasSystemPrompt, err := startSystemPrompt(req)
+	if err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+	extraArgs, hasExtraArgs, err := startExtraArgs(req)
+	if err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+	maxTurns, maxDurationMinutes, err := startLimits(req)
+	if err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+	cancelOnDisconnect, hasCancelOnDisconnect, err := startCancelOnDisconnect(req)
+	if err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+	applySystemPrompt := func(session *headless.HeadlessSession) {
+		if hasSystemPrompt {
+			if err := c.handler.headlessManager.SetConversationSystemPrompt(session.ConversationID, systemPrompt); err != nil {
+				log.Printf("[HeadlessHandler] Failed to set system prompt for conversation %d: %v", session.ConversationID, err)
+			}
+		}
+		if hasExtraArgs {
+			if err := c.handler.headlessManager.SetConversationExtraArgs(session.ConversationID, extraArgs); err != nil {
+				log.Printf("[HeadlessHandler] Failed to set extra args for conversation %d: %v", session.ConversationID, err)
+			}
+		}
+		if maxTurns != nil || maxDurationMinutes != nil {
+			if err := c.handler.headlessManager.SetConversationLimits(session.ConversationID, maxTurns, maxDurationMinutes); err != nil {
+				log.Printf("[HeadlessHandler] Failed to set limits for conversation %d: %v", session.ConversationID, err)
+			}
+		}
+		if hasCancelOnDisconnect {
+			if err := c.handler.headlessManager.SetConversationCancelOnDisconnect(session.ConversationID, cancelOnDisconnect); err != nil {
+				log.Printf("[HeadlessHandler] Failed to set cancel_on_disconnect for conversation %d: %v", session.ConversationID, err)
+			}
+		}
+	}
 
 	// force_new 明确要求关闭旧 session，避免前端重新连接后混入旧会话事件。
 	if forceNew {
@@ -443,6 +768,7 @@ func (c *headlessClient) handleStart(req *headless.HeadlessRequest) {
 		c.killClaudeProcesses()
 	} else if c.session != nil && !c.session.IsClosed() {
 		// 非 force_new 时复用当前 session，避免重复创建并导致多会话混乱。
+		applySystemPrompt(c.session)
 		c.sendResponse(headless.HeadlessResponseTypeSessionInfo, c.session.GetSessionInfo())
 		c.subscribeToSession(c.session)
 		return
@@ -476,6 +802,7 @@ func (c *headlessClient) handleStart(req *headless.HeadlessRequest) {
 		c.sendError(headless.ErrorCodeInternalError, err.Error())
 		return
 	}
+	applySystemPrompt(session)
 
 	// 设置监控
 	if err := c.handler.headlessManager.SetupMonitoringForSession(session); err != nil {
@@ -498,9 +825,14 @@ func (c *headlessClient) handlePrompt(req *headless.HeadlessRequest) {
 		return
 	}
 
-	prompt, ok := req.Payload["prompt"].(string)
-	if !ok || prompt == "" {
-		c.sendError(headless.ErrorCodeInvalidRequest, "Missing prompt")
+	prompt, err := c.handler.promptText(req)
+	if err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+
+	if c.handler.isContainerPaused(c.containerID) {
+		c.sendError(headless.ErrorCodeContainerPaused, "Container is paused")
 		return
 	}
 
@@ -515,6 +847,12 @@ func (c *headlessClient) handlePrompt(req *headless.HeadlessRequest) {
 		model = m
 	}
 
+	filePaths, err := promptFilePaths(req)
+	if err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+
 	// 确保已订阅（必须在 SendPrompt 之前完成）
 	c.subscribeToSession(c.session)
 
@@ -522,9 +860,9 @@ func (c *headlessClient) handlePrompt(req *headless.HeadlessRequest) {
 	// 这是一个简单的同步机制，确保事件监听已经就绪
 	time.Sleep(10 * time.Millisecond)
 
-	// 发送 prompt（带 model 参数）
-	if err := c.handler.headlessManager.SendPromptWithModel(c.session.ID, prompt, source, model); err != nil {
-		c.sendError(headless.ErrorCodeProcessFailed, err.Error())
+	// 发送 prompt（带 model 参数和引用的文件）
+	if err := c.handler.headlessManager.SendPromptWithFiles(c.session.ID, prompt, source, model, filePaths); err != nil {
+		c.sendError(sendPromptErrorCode(err), err.Error())
 		return
 	}
 }
@@ -542,6 +880,61 @@ func (c *headlessClient) handleCancel(req *headless.HeadlessRequest) {
 	}
 }
 
+// handleCancelTurn 处理取消指定轮次请求
+func (c *headlessClient) handleCancelTurn(req *headless.HeadlessRequest) {
+	if c.session == nil {
+		c.sendError(headless.ErrorCodeSessionNotFound, "No active session")
+		return
+	}
+	turnID, ok := req.Payload["turn_id"].(float64)
+	if !ok || turnID <= 0 {
+		c.sendError(headless.ErrorCodeInvalidRequest, "Missing or invalid turn_id")
+		return
+	}
+
+	result, err := c.handler.headlessManager.CancelTurn(c.session.ID, uint(turnID))
+	if err != nil {
+		c.sendError(headless.ErrorCodeInternalError, err.Error())
+		return
+	}
+	c.sendResponse(headless.HeadlessResponseTypeCancelTurnResult, result)
+}
+
+// handleCompact 处理上下文压缩请求：以 /compact 轮次用摘要替换之前的上下文
+func (c *headlessClient) handleCompact(req *headless.HeadlessRequest) {
+	if c.session == nil {
+		c.sendError(headless.ErrorCodeSessionNotFound, "No active session")
+		return
+	}
+
+	if c.handler.isContainerPaused(c.containerID) {
+		c.sendError(headless.ErrorCodeContainerPaused, "Container is paused")
+		return
+	}
+
+	c.subscribeToSession(c.session)
+	time.Sleep(10 * time.Millisecond)
+
+	if err := c.handler.headlessManager.Compact(c.session.ID); err != nil {
+		c.sendError(compactErrorCode(err), err.Error())
+	}
+}
+
+// handleResetContext 处理重置上下文请求：保留对话历史，下一轮从全新的 Claude 上下文开始
+func (c *headlessClient) handleResetContext(req *headless.HeadlessRequest) {
+	if c.session == nil {
+		c.sendError(headless.ErrorCodeSessionNotFound, "No active session")
+		return
+	}
+
+	result, err := c.handler.headlessManager.ResetContext(c.session.ID)
+	if err != nil {
+		c.sendError(headless.ErrorCodeInternalError, err.Error())
+		return
+	}
+	c.sendResponse(headless.HeadlessResponseTypeContextReset, result)
+}
+
 // handleStopSession 处理停止整个会话请求
 func (c *headlessClient) handleStopSession(req *headless.HeadlessRequest) {
 	requestedID := requestedSessionID(req)
@@ -701,6 +1094,11 @@ func (c *headlessClient) handleEditQueued(req *headless.HeadlessRequest) {
 
 // sendResponse 发送响应
 func (c *headlessClient) sendResponse(respType string, payload interface{}) {
+	c.sendSeqResponse(respType, payload, 0)
+}
+
+// sendSeqResponse 发送带会话事件序号的响应
+func (c *headlessClient) sendSeqResponse(respType string, payload interface{}, seq uint64) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("[HeadlessHandler] Client %s send channel closed while sending %s: %v", c.clientID, respType, r)
@@ -714,6 +1112,7 @@ func (c *headlessClient) sendResponse(respType string, payload interface{}) {
 	case c.sendChan <- &headless.HeadlessResponse{
 		Type:    respType,
 		Payload: payload,
+		Seq:     seq,
 	}:
 	case <-c.done:
 		log.Printf("[HeadlessHandler] Client %s closed before sending %s", c.clientID, respType)
@@ -735,8 +1134,7 @@ func (c *headlessClient) killClaudeProcesses() {
 	defer cancel()
 
 	// Best-effort kill: do not fail request if command not available
-	cmd := []string{"sh", "-c", "pkill -f 'claude' 2>/dev/null || true"}
-	if _, err := c.handler.containerService.ExecInContainer(ctx, c.containerID, cmd); err != nil {
+	if err := c.handler.containerService.KillClaudeProcesses(ctx, c.containerID); err != nil {
 		log.Printf("[HeadlessHandler] Failed to kill claude processes in container %d: %v", c.containerID, err)
 	}
 }
@@ -744,19 +1142,26 @@ func (c *headlessClient) killClaudeProcesses() {
 // convertTurnToInfo 转换 HeadlessTurn 为 TurnInfo
 func convertTurnToInfo(turn *models.HeadlessTurn) headless.TurnInfo {
 	info := headless.TurnInfo{
-		ID:                turn.ID,
-		TurnIndex:         turn.TurnIndex,
-		UserPrompt:        turn.UserPrompt,
-		PromptSource:      turn.PromptSource,
-		AssistantResponse: turn.AssistantResponse,
-		Model:             turn.ModelName,
-		InputTokens:       turn.InputTokens,
-		OutputTokens:      turn.OutputTokens,
-		CostUSD:           turn.CostUSD,
-		DurationMS:        turn.DurationMS,
-		State:             turn.State,
-		ErrorMessage:      turn.ErrorMessage,
-		CreatedAt:         turn.CreatedAt.Format(time.RFC3339),
+		ID:                  turn.ID,
+		TurnIndex:           turn.TurnIndex,
+		UserPrompt:          turn.UserPrompt,
+		PromptSource:        turn.PromptSource,
+		AttachedFiles:       turn.AttachedFiles,
+		AssistantResponse:   turn.AssistantResponse,
+		Model:               turn.ModelName,
+		InputTokens:         turn.InputTokens,
+		OutputTokens:        turn.OutputTokens,
+		CostUSD:             turn.CostUSD,
+		CostSource:          turn.CostSource,
+		DurationMS:          turn.DurationMS,
+		State:               turn.State,
+		ErrorMessage:        turn.ErrorMessage,
+		ContextResetAfter:   turn.ContextResetAfter,
+		ContextTokensBefore: turn.ContextTokensBefore,
+		ContextTokensAfter:  turn.ContextTokensAfter,
+		CreatedAt:           turn.CreatedAt.Format(time.RFC3339),
+		EventsTruncated:     turn.EventsTruncated,
+		EventsPruned:        turn.EventsPruned,
 	}
 
 	if turn.CompletedAt != nil {
@@ -765,24 +1170,28 @@ func convertTurnToInfo(turn *models.HeadlessTurn) headless.TurnInfo {
 
 	if len(turn.Events) > 0 {
 		info.Events = make([]headless.EventInfo, len(turn.Events))
-		for i, event := range turn.Events {
-			info.Events[i] = headless.EventInfo{
-				ID:           event.ID,
-				EventIndex:   event.EventIndex,
-				EventType:    event.EventType,
-				EventSubtype: event.EventSubtype,
-				RawJSON:      event.RawJSON,
-				CreatedAt:    event.CreatedAt.Format(time.RFC3339),
-			}
+		for i := range turn.Events {
+			info.Events[i] = convertEventToInfo(&turn.Events[i])
 		}
 	}
 
 	return info
 }
 
+func convertEventToInfo(event *models.HeadlessEvent) headless.EventInfo {
+	return headless.EventInfo{
+		ID:           event.ID,
+		EventIndex:   event.EventIndex,
+		EventType:    event.EventType,
+		EventSubtype: event.EventSubtype,
+		RawJSON:      event.RawJSON,
+		CreatedAt:    event.CreatedAt.Format(time.RFC3339),
+	}
+}
+
 // ==================== HTTP API Handlers ====================
 
-// ListConversations 列出容器的所有对话
+// ListConversations 列出容器的所有对话，?tag= 只返回带有该标签的对话
 func (h *HeadlessHandler) ListConversations(c *gin.Context) {
 	containerIDStr := c.Param("id")
 	containerID, err := strconv.ParseUint(containerIDStr, 10, 32)
@@ -797,7 +1206,8 @@ func (h *HeadlessHandler) ListConversations(c *gin.Context) {
 		return
 	}
 
-	conversations, err := historyManager.ListConversationsForContainer(uint(containerID))
+	filter := headless.ConversationFilter{Tag: c.Query("tag")}
+	conversations, err := historyManager.ListConversationsForContainer(uint(containerID), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -805,68 +1215,107 @@ func (h *HeadlessHandler) ListConversations(c *gin.Context) {
 
 	// 转换为 API 响应格式
 	result := make([]headless.ConversationInfo, len(conversations))
-	for i, conv := range conversations {
-		// 获取对话的轮次数量
-		turnCount := 0
-		if turns, _, err := historyManager.GetRecentTurns(conv.ID, 1000); err == nil {
-			turnCount = len(turns)
-		}
-
-		// 生成标题（使用第一轮的 user_prompt 或默认标题）
-		title := ""
-		if turns, _, err := historyManager.GetRecentTurns(conv.ID, 1); err == nil && len(turns) > 0 {
-			prompt := turns[0].UserPrompt
-			if len(prompt) > 50 {
-				title = prompt[:50] + "..."
-			} else {
-				title = prompt
-			}
-		}
-
-		// 检查对话是否正在运行
-		isRunning := h.headlessManager.IsConversationRunning(conv.ID)
-
-		result[i] = headless.ConversationInfo{
-			ID:              conv.ID,
-			ContainerID:     conv.ContainerID,
-			SessionID:       conv.SessionID,
-			ClaudeSessionID: conv.ClaudeSessionID,
-			Title:           title,
-			State:           conv.State,
-			IsRunning:       isRunning,
-			TotalTurns:      turnCount,
-			CreatedAt:       conv.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:       conv.UpdatedAt.Format(time.RFC3339),
-		}
+	for i := range conversations {
+		result[i] = h.conversationInfo(historyManager, &conversations[i])
 	}
 
 	c.JSON(http.StatusOK, result)
 }
 
-// GetConversationStatus 获取对话运行状态
-func (h *HeadlessHandler) GetConversationStatus(c *gin.Context) {
-	conversationIDStr := c.Param("conversationId")
-	conversationID, err := strconv.ParseUint(conversationIDStr, 10, 32)
+// GetLatestConversation 获取容器最近活跃的对话及其运行状态，客户端重新打开容器时据此自动恢复
+// GET /api/containers/:id/headless/conversations/latest
+func (h *HeadlessHandler) GetLatestConversation(c *gin.Context) {
+	containerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
 		return
 	}
 
-	isRunning := h.headlessManager.IsConversationRunning(uint(conversationID))
-
-	c.JSON(http.StatusOK, gin.H{
-		"conversation_id": conversationID,
-		"is_running":      isRunning,
-	})
-}
-
-// HandleConversationWebSocket 处理基于 conversationId 的 WebSocket 连接
-func (h *HeadlessHandler) HandleConversationWebSocket(c *gin.Context) {
-	// 获取 conversation ID
-	conversationIDStr := c.Param("conversationId")
-	conversationID, err := strconv.ParseUint(conversationIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+	historyManager := h.headlessManager.GetHistoryManager()
+	if historyManager == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "History manager not available"})
+		return
+	}
+
+	conversation, err := historyManager.GetLatestConversation(uint(containerID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if conversation == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No conversation found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.conversationInfo(historyManager, conversation))
+}
+
+// conversationInfo 将对话转换为 API 响应格式，附带轮次数量、标题和运行状态
+func (h *HeadlessHandler) conversationInfo(historyManager *headless.HeadlessHistoryManager, conv *models.HeadlessConversation) headless.ConversationInfo {
+	// 获取对话的轮次数量
+	turnCount := 0
+	if turns, _, err := historyManager.GetRecentTurns(conv.ID, 1000); err == nil {
+		turnCount = len(turns)
+	}
+
+	// 生成标题（使用第一轮的 user_prompt 或默认标题）
+	title := ""
+	if turns, _, err := historyManager.GetRecentTurns(conv.ID, 1); err == nil && len(turns) > 0 {
+		prompt := turns[0].UserPrompt
+		if len(prompt) > 50 {
+			title = prompt[:50] + "..."
+		} else {
+			title = prompt
+		}
+	}
+
+	// 检查对话是否正在运行
+	isRunning := h.headlessManager.IsConversationRunning(conv.ID)
+
+	return headless.ConversationInfo{
+		ID:                 conv.ID,
+		ContainerID:        conv.ContainerID,
+		SessionID:          conv.SessionID,
+		ClaudeSessionID:    conv.ClaudeSessionID,
+		Title:              title,
+		SystemPrompt:       conv.SystemPrompt,
+		ExtraArgs:          conv.ExtraArgs,
+		MaxTurns:           conv.MaxTurns,
+		MaxDurationMinutes: conv.MaxDurationMinutes,
+		CancelOnDisconnect: conv.CancelOnDisconnect,
+		Tags:               conv.Tags,
+		State:              conv.State,
+		IsRunning:          isRunning,
+		TotalTurns:         turnCount,
+		CreatedAt:          conv.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          conv.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// GetConversationStatus 获取对话运行状态
+func (h *HeadlessHandler) GetConversationStatus(c *gin.Context) {
+	conversationIDStr := c.Param("conversationId")
+	conversationID, err := strconv.ParseUint(conversationIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return
+	}
+
+	isRunning := h.headlessManager.IsConversationRunning(uint(conversationID))
+
+	c.JSON(http.StatusOK, gin.H{
+		"conversation_id": conversationID,
+		"is_running":      isRunning,
+	})
+}
+
+// HandleConversationWebSocket 处理基于 conversationId 的 WebSocket 连接
+func (h *HeadlessHandler) HandleConversationWebSocket(c *gin.Context) {
+	// 获取 conversation ID
+	conversationIDStr := c.Param("conversationId")
+	conversationID, err := strconv.ParseUint(conversationIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
 		return
 	}
 
@@ -894,38 +1343,22 @@ func (h *HeadlessHandler) HandleConversationWebSocket(c *gin.Context) {
 		return
 	}
 
+	if container.Status == models.ContainerStatusPaused {
+		c.JSON(http.StatusConflict, gin.H{"error": "Container is paused"})
+		return
+	}
 	if container.Status != models.ContainerStatusRunning {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Container is not running"})
 		return
 	}
 
-	// 认证
-	clientIP := c.ClientIP()
-	isInternalRequest := isDockerInternalIP(clientIP)
-
-	if !isInternalRequest {
-		var token string
-		if cookieToken, err := c.Cookie(middleware.TokenCookieName); err == nil && cookieToken != "" {
-			token = cookieToken
-		}
-		if token == "" {
-			token = c.Query("token")
-		}
-		if token == "" {
-			log.Printf("[HeadlessHandler] Missing auth token from %s (origin: %s)", c.ClientIP(), c.GetHeader("Origin"))
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing authentication token"})
-			return
-		}
-		_, err = h.authService.VerifyToken(token)
-		if err != nil {
-			log.Printf("[HeadlessHandler] Invalid auth token from %s (origin: %s): %v", c.ClientIP(), c.GetHeader("Origin"), err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
-			return
-		}
+	// 认证：子协议、cookie 或 query 中的 token
+	if !authorizeWebSocket(c, h.authService, "HeadlessHandler") {
+		return
 	}
 
 	// 升级 WebSocket
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, webSocketResponseHeader(c.Request))
 	if err != nil {
 		log.Printf("[HeadlessHandler] Failed to upgrade connection from %s (origin: %s): %v", c.ClientIP(), c.GetHeader("Origin"), err)
 		return
@@ -933,12 +1366,7 @@ func (h *HeadlessHandler) HandleConversationWebSocket(c *gin.Context) {
 	defer conn.Close()
 
 	// 配置 WebSocket 读写超时
-	conn.SetReadLimit(headlessMaxMessage)
-	conn.SetReadDeadline(time.Now().Add(headlessPongWait))
-	conn.SetPongHandler(func(string) error {
-		conn.SetReadDeadline(time.Now().Add(headlessPongWait))
-		return nil
-	})
+	h.wsSettings.Apply(conn)
 
 	// 生成客户端 ID
 	clientID := uuid.New().String()
@@ -956,6 +1384,7 @@ func (h *HeadlessHandler) HandleConversationWebSocket(c *gin.Context) {
 		workDir:        container.WorkDir,
 		sendChan:       make(chan *headless.HeadlessResponse, 100),
 		done:           make(chan struct{}),
+		resume:         parseResumeParams(c),
 	}
 
 	// 启动发送 goroutine
@@ -978,6 +1407,7 @@ type conversationClient struct {
 	done           chan struct{}
 	session        *headless.HeadlessSession
 	eventChan      chan *headless.StreamEvent
+	resume         resumeParams
 	mu             sync.Mutex
 }
 
@@ -994,6 +1424,12 @@ func (c *conversationClient) handleConnection() {
 	if session != nil {
 		c.session = session
 
+		// 断线重连：只补发 last_seq 之后的事件
+		if c.resumeSession(session) {
+			c.readPump()
+			return
+		}
+
 		// 如果会话正在运行，先订阅以避免丢事件
 		if session.IsRunning() {
 			c.subscribeToSession(session)
@@ -1077,24 +1513,11 @@ func (c *conversationClient) sendHistory(session *headless.HeadlessSession) {
 
 	// 如果会话正在运行，发送当前轮次的已有事件
 	if session.IsRunning() && session.GetCurrentTurnID() > 0 {
-		events, err := historyManager.GetCurrentTurnEvents(session.GetCurrentTurnID())
+		err := replayCurrentTurnEvents(historyManager, session, c.resume, c.sendChan, c.done, func(evt *headless.StreamEvent, seq uint64) {
+			c.sendSeqResponse(headless.HeadlessResponseTypeEvent, evt, seq)
+		})
 		if err != nil {
 			log.Printf("[HeadlessHandler] Failed to get current turn events: %v", err)
-			return
-		}
-
-		for _, event := range events {
-			// 解析原始 JSON 为 StreamEvent
-			var evt headless.StreamEvent
-			if err := json.Unmarshal([]byte(event.RawJSON), &evt); err == nil {
-				c.sendResponse(headless.HeadlessResponseTypeEvent, &evt)
-				continue
-			}
-
-			// fallback：处理非 JSON 行（stderr/纯文本）
-			if fallbackEvt, _ := headless.ParseStreamLine(event.RawJSON); fallbackEvt != nil {
-				c.sendResponse(headless.HeadlessResponseTypeEvent, fallbackEvt)
-			}
 		}
 	}
 }
@@ -1109,47 +1532,64 @@ func (c *conversationClient) subscribeToSession(session *headless.HeadlessSessio
 	}
 
 	c.eventChan = session.AddClient(c.clientID)
+	c.forwardEvents(c.eventChan)
+}
+
+// resumeSession 按连接参数 last_seq 断线重连：会话未变且缓冲包含之后的全部事件时，
+// 只补发这些事件并继续订阅，返回 true；否则返回 false，由调用方加载完整历史
+func (c *conversationClient) resumeSession(session *headless.HeadlessSession) bool {
+	if !c.resume.ok || (c.resume.sessionID != "" && c.resume.sessionID != session.ID) {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	// 使用 ready channel 确保 goroutine 已启动
+	ch, replay, ok := session.AddClientAfter(c.clientID, c.resume.lastSeq)
+	if !ok {
+		return false
+	}
+	c.eventChan = ch
+
+	c.sendResponse(headless.HeadlessResponseTypeSessionInfo, session.GetSessionInfo())
+	for _, evt := range replay {
+		c.sendStreamEvent(evt)
+	}
+	c.forwardEvents(ch)
+	return true
+}
+
+// forwardEvents 启动事件转发 goroutine，返回前确保 goroutine 已启动
+func (c *conversationClient) forwardEvents(eventChan chan *headless.StreamEvent) {
 	ready := make(chan struct{})
 
-	// 启动事件转发 goroutine
 	go func() {
 		// 通知调用者 goroutine 已启动
 		close(ready)
 
 		for {
 			select {
-			case evt, ok := <-c.eventChan:
+			case evt, ok := <-eventChan:
 				if !ok {
 					return
 				}
-				// 检查是否为特殊元事件
-				if evt.Type == "turn_complete" {
-					var payload headless.TurnCompletePayload
-					if evt.Result != "" && json.Unmarshal([]byte(evt.Result), &payload) == nil {
-						c.sendResponse(headless.HeadlessResponseTypeTurnComplete, &payload)
-					} else {
-						c.sendResponse(headless.HeadlessResponseTypeTurnComplete, evt.Result)
-					}
-				} else if evt.Type == "queue_update" {
-					var payload headless.QueueUpdatePayload
-					if evt.Result != "" && json.Unmarshal([]byte(evt.Result), &payload) == nil {
-						c.sendResponse(headless.HeadlessResponseTypeQueueUpdate, &payload)
-					}
-				} else {
-					c.sendResponse(headless.HeadlessResponseTypeEvent, evt)
-				}
+				c.sendStreamEvent(evt)
 			case <-c.done:
 				return
 			}
 		}
 	}()
 
-	// 等待 goroutine 启动完成
 	<-ready
 }
 
+// sendStreamEvent 发送会话事件，附带事件序号
+func (c *conversationClient) sendStreamEvent(evt *headless.StreamEvent) {
+	if respType, payload, ok := streamEventResponse(evt); ok {
+		c.sendSeqResponse(respType, payload, evt.Seq)
+	}
+}
+
 // unsubscribeFromSession 取消订阅会话输出
 func (c *conversationClient) unsubscribeFromSession() {
 	c.mu.Lock()
@@ -1189,7 +1629,7 @@ func (c *conversationClient) readPump() {
 		}
 
 		// 收到任意消息都延长读超时
-		c.conn.SetReadDeadline(time.Now().Add(headlessPongWait))
+		c.conn.SetReadDeadline(time.Now().Add(c.handler.wsSettings.PongWait))
 
 		// 处理消息
 		c.handleMessage(&req)
@@ -1198,7 +1638,7 @@ func (c *conversationClient) readPump() {
 
 // writePump 发送消息到客户端
 func (c *conversationClient) writePump() {
-	ticker := time.NewTicker(headlessPingPeriod)
+	ticker := time.NewTicker(c.handler.wsSettings.PingPeriod)
 	defer ticker.Stop()
 
 	for {
@@ -1207,14 +1647,14 @@ func (c *conversationClient) writePump() {
 			if !ok {
 				return
 			}
-			c.conn.SetWriteDeadline(time.Now().Add(headlessWriteWait))
+			c.conn.SetWriteDeadline(time.Now().Add(c.handler.wsSettings.WriteWait))
 			if err := c.conn.WriteJSON(resp); err != nil {
 				log.Printf("[HeadlessHandler] Client %s write error: %v", c.clientID, err)
 				return
 			}
 		case <-ticker.C:
 			// 发送 ping
-			c.conn.SetWriteDeadline(time.Now().Add(headlessWriteWait))
+			c.conn.SetWriteDeadline(time.Now().Add(c.handler.wsSettings.WriteWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
@@ -1233,6 +1673,12 @@ func (c *conversationClient) handleMessage(req *headless.HeadlessRequest) {
 		c.handlePrompt(req)
 	case headless.HeadlessRequestTypeCancel:
 		c.handleCancel(req)
+	case headless.HeadlessRequestTypeCancelTurn:
+		c.handleCancelTurn(req)
+	case headless.HeadlessRequestTypeResetContext:
+		c.handleResetContext(req)
+	case headless.HeadlessRequestTypeCompact:
+		c.handleCompact(req)
 	case headless.HeadlessRequestTypeStopSession:
 		c.handleStopSession(req)
 	case headless.HeadlessRequestTypeLoadMore:
@@ -1253,6 +1699,47 @@ func (c *conversationClient) handleMessage(req *headless.HeadlessRequest) {
 func (c *conversationClient) handleStart(req *headless.HeadlessRequest) {
 	forceNew, _ := req.Payload["force_new"].(bool)
 
+	// 携带 system_prompt 时先持久化，恢复或新建的会话都会使用新值
+	if systemPrompt, ok, err := startSystemPrompt(req); err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	} else if ok {
+		if err := c.handler.headlessManager.SetConversationSystemPrompt(c.conversationID, systemPrompt); err != nil {
+			c.sendError(headless.ErrorCodeInternalError, err.Error())
+			return
+		}
+	}
+	// 携带 extra_args 时同样先持久化
+	if extraArgs, ok, err := startExtraArgs(req); err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	} else if ok {
+		if err := c.handler.headlessManager.SetConversationExtraArgs(c.conversationID, extraArgs); err != nil {
+			c.sendError(headless.ErrorCodeInternalError, err.Error())
+			return
+		}
+	}
+	// 携带停止条件时同样先持久化
+	if maxTurns, maxDurationMinutes, err := startLimits(req); err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	} else if maxTurns != nil || maxDurationMinutes != nil {
+		if err := c.handler.headlessManager.SetConversationLimits(c.conversationID, maxTurns, maxDurationMinutes); err != nil {
+			c.sendError(headless.ErrorCodeInternalError, err.Error())
+			return
+		}
+	}
+	// 携带 cancel_on_disconnect 时同样先持久化
+	if enabled, ok, err := startCancelOnDisconnect(req); err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	} else if ok {
+		if err := c.handler.headlessManager.SetConversationCancelOnDisconnect(c.conversationID, enabled); err != nil {
+			c.sendError(headless.ErrorCodeInternalError, err.Error())
+			return
+		}
+	}
+
 	// 检查是否已有会话
 	if c.session != nil && !c.session.IsClosed() {
 		if !forceNew {
@@ -1327,9 +1814,14 @@ func (c *conversationClient) handlePrompt(req *headless.HeadlessRequest) {
 		return
 	}
 
-	prompt, ok := req.Payload["prompt"].(string)
-	if !ok || prompt == "" {
-		c.sendError(headless.ErrorCodeInvalidRequest, "Missing prompt")
+	prompt, err := c.handler.promptText(req)
+	if err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+
+	if c.handler.isContainerPaused(c.containerID) {
+		c.sendError(headless.ErrorCodeContainerPaused, "Container is paused")
 		return
 	}
 
@@ -1344,13 +1836,19 @@ func (c *conversationClient) handlePrompt(req *headless.HeadlessRequest) {
 		model = m
 	}
 
+	filePaths, err := promptFilePaths(req)
+	if err != nil {
+		c.sendError(headless.ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+
 	// 确保已订阅
 	c.subscribeToSession(c.session)
 	time.Sleep(10 * time.Millisecond)
 
-	// 发送 prompt（带 model 参数）
-	if err := c.handler.headlessManager.SendPromptWithModel(c.session.ID, prompt, source, model); err != nil {
-		c.sendError(headless.ErrorCodeProcessFailed, err.Error())
+	// 发送 prompt（带 model 参数和引用的文件）
+	if err := c.handler.headlessManager.SendPromptWithFiles(c.session.ID, prompt, source, model, filePaths); err != nil {
+		c.sendError(sendPromptErrorCode(err), err.Error())
 		return
 	}
 }
@@ -1368,6 +1866,61 @@ func (c *conversationClient) handleCancel(req *headless.HeadlessRequest) {
 	}
 }
 
+// handleCancelTurn 处理取消指定轮次请求
+func (c *conversationClient) handleCancelTurn(req *headless.HeadlessRequest) {
+	if c.session == nil {
+		c.sendError(headless.ErrorCodeSessionNotFound, "No active session")
+		return
+	}
+	turnID, ok := req.Payload["turn_id"].(float64)
+	if !ok || turnID <= 0 {
+		c.sendError(headless.ErrorCodeInvalidRequest, "Missing or invalid turn_id")
+		return
+	}
+
+	result, err := c.handler.headlessManager.CancelTurn(c.session.ID, uint(turnID))
+	if err != nil {
+		c.sendError(headless.ErrorCodeInternalError, err.Error())
+		return
+	}
+	c.sendResponse(headless.HeadlessResponseTypeCancelTurnResult, result)
+}
+
+// handleCompact 处理上下文压缩请求：以 /compact 轮次用摘要替换之前的上下文
+func (c *conversationClient) handleCompact(req *headless.HeadlessRequest) {
+	if c.session == nil {
+		c.sendError(headless.ErrorCodeSessionNotFound, "No active session")
+		return
+	}
+
+	if c.handler.isContainerPaused(c.containerID) {
+		c.sendError(headless.ErrorCodeContainerPaused, "Container is paused")
+		return
+	}
+
+	c.subscribeToSession(c.session)
+	time.Sleep(10 * time.Millisecond)
+
+	if err := c.handler.headlessManager.Compact(c.session.ID); err != nil {
+		c.sendError(compactErrorCode(err), err.Error())
+	}
+}
+
+// handleResetContext 处理重置上下文请求：保留对话历史，下一轮从全新的 Claude 上下文开始
+func (c *conversationClient) handleResetContext(req *headless.HeadlessRequest) {
+	if c.session == nil {
+		c.sendError(headless.ErrorCodeSessionNotFound, "No active session")
+		return
+	}
+
+	result, err := c.handler.headlessManager.ResetContext(c.session.ID)
+	if err != nil {
+		c.sendError(headless.ErrorCodeInternalError, err.Error())
+		return
+	}
+	c.sendResponse(headless.HeadlessResponseTypeContextReset, result)
+}
+
 // handleStopSession 处理停止整个会话请求
 func (c *conversationClient) handleStopSession(req *headless.HeadlessRequest) {
 	requestedID := requestedSessionID(req)
@@ -1485,6 +2038,11 @@ func (c *conversationClient) handleEditQueued(req *headless.HeadlessRequest) {
 
 // sendResponse 发送响应
 func (c *conversationClient) sendResponse(respType string, payload interface{}) {
+	c.sendSeqResponse(respType, payload, 0)
+}
+
+// sendSeqResponse 发送带会话事件序号的响应
+func (c *conversationClient) sendSeqResponse(respType string, payload interface{}, seq uint64) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Printf("[HeadlessHandler] Client %s send channel closed while sending %s: %v", c.clientID, respType, r)
@@ -1498,6 +2056,7 @@ func (c *conversationClient) sendResponse(respType string, payload interface{})
 	case c.sendChan <- &headless.HeadlessResponse{
 		Type:    respType,
 		Payload: payload,
+		Seq:     seq,
 	}:
 	case <-c.done:
 		log.Printf("[HeadlessHandler] Client %s closed before sending %s", c.clientID, respType)
@@ -1518,8 +2077,7 @@ func (c *conversationClient) killClaudeProcesses() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cmd := []string{"sh", "-c", "pkill -f 'claude' 2>/dev/null || true"}
-	if _, err := c.handler.containerService.ExecInContainer(ctx, c.containerID, cmd); err != nil {
+	if err := c.handler.containerService.KillClaudeProcesses(ctx, c.containerID); err != nil {
 		log.Printf("[HeadlessHandler] Failed to kill claude processes in container %d: %v", c.containerID, err)
 	}
 }
@@ -1560,6 +2118,197 @@ func (h *HeadlessHandler) GetConversation(c *gin.Context) {
 	c.JSON(http.StatusOK, conversation)
 }
 
+// SystemPromptRequest 更新对话级系统提示词的请求体
+type SystemPromptRequest struct {
+	SystemPrompt string `json:"system_prompt"`
+}
+
+// UpdateConversationSystemPrompt 更新对话级系统提示词（空字符串表示清除），运行中的会话从下一轮开始生效
+// PUT /api/containers/:id/headless/conversations/:conversationId/system-prompt
+func (h *HeadlessHandler) UpdateConversationSystemPrompt(c *gin.Context) {
+	containerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	conversationID, err := strconv.ParseUint(c.Param("conversationId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return
+	}
+
+	var req SystemPromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	// 只能修改属于该容器的对话
+	conversation, err := h.headlessManager.GetHistoryManager().GetConversationByID(uint(conversationID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if conversation == nil || conversation.ContainerID != uint(containerID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	if err := h.headlessManager.SetConversationSystemPrompt(uint(conversationID), req.SystemPrompt); err != nil {
+		switch {
+		case errors.Is(err, headless.ErrSystemPromptTooLarge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, headless.ErrConversationNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"conversation_id": conversationID,
+		"system_prompt":   req.SystemPrompt,
+	})
+}
+
+// ConversationTagsRequest 更新对话标签的请求体
+type ConversationTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// UpdateConversationTags 替换对话标签（空列表表示清除）
+// PUT /api/containers/:id/headless/conversations/:conversationId/tags
+func (h *HeadlessHandler) UpdateConversationTags(c *gin.Context) {
+	if _, err := strconv.ParseUint(c.Param("id"), 10, 32); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	conversationID, err := strconv.ParseUint(c.Param("conversationId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return
+	}
+
+	var req ConversationTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	tags, err := h.headlessManager.SetConversationTags(uint(conversationID), req.Tags)
+	if err != nil {
+		switch {
+		case errors.Is(err, headless.ErrInvalidTag):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, headless.ErrConversationNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"conversation_id": conversationID,
+		"tags":            tags,
+	})
+}
+
+// ListConversationTags 列出容器对话使用过的所有标签
+// GET /api/containers/:id/headless/conversations/tags
+func (h *HeadlessHandler) ListConversationTags(c *gin.Context) {
+	containerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	tags, err := h.headlessManager.GetHistoryManager().ListConversationTags(uint(containerID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// GetModelPricing 返回计算轮次费用使用的定价表（美元 / 百万 token），供前端在执行前估算费用
+// GET /api/headless/pricing
+func (h *HeadlessHandler) GetModelPricing(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"unit":          "usd_per_million_tokens",
+		"default_model": headless.DefaultPricingModel,
+		"prices":        h.headlessManager.PricingTable().Prices(),
+	})
+}
+
+// ListActiveSessions 列出所有容器中未关闭的 Headless 会话，可用 container_id 过滤
+// GET /api/headless/sessions
+func (h *HeadlessHandler) ListActiveSessions(c *gin.Context) {
+	var containerID uint64
+	if raw := c.Query("container_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+			return
+		}
+		containerID = id
+	}
+
+	sessions := h.headlessManager.ListActiveSessions(uint(containerID))
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// GetContainerMode 获取容器当前模式以及活跃的终端和 Headless 会话
+// GET /api/containers/:id/mode
+func (h *HeadlessHandler) GetContainerMode(c *gin.Context) {
+	containerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	container, err := h.containerService.GetContainer(uint(containerID))
+	if err != nil {
+		if errors.Is(err, services.ErrContainerNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.modeManager.Inventory(container.ID, container.DockerID))
+}
+
+// ResetContainerMode 强制关闭容器的所有终端和 Headless 会话、结束残留的 claude 进程并清除模式
+// POST /api/containers/:id/mode/reset
+func (h *HeadlessHandler) ResetContainerMode(c *gin.Context) {
+	containerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	container, err := h.containerService.GetContainer(uint(containerID))
+	if err != nil {
+		if errors.Is(err, services.ErrContainerNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.modeManager.ResetMode(container.ID, container.DockerID))
+}
+
 // DeleteConversation 删除对话
 func (h *HeadlessHandler) DeleteConversation(c *gin.Context) {
 	containerIDStr := c.Param("id")
@@ -1596,6 +2345,62 @@ func (h *HeadlessHandler) DeleteConversation(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Conversation deleted"})
 }
 
+// GetConversationChanges 获取对话期间工作目录中的文件变更
+// GET /api/containers/:id/headless/conversations/:conversationId/changes
+func (h *HeadlessHandler) GetConversationChanges(c *gin.Context) {
+	containerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	conversationID, err := strconv.ParseUint(c.Param("conversationId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return
+	}
+
+	conversation, err := h.headlessManager.GetHistoryManager().GetConversationByID(uint(conversationID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if conversation == nil || conversation.ContainerID != uint(containerID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	container, err := h.containerService.GetContainer(uint(containerID))
+	if err != nil {
+		if errors.Is(err, services.ErrContainerNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if container.Status == models.ContainerStatusPaused {
+		c.JSON(http.StatusConflict, gin.H{"error": "Container is paused"})
+		return
+	}
+	if container.Status != models.ContainerStatusRunning {
+		c.JSON(http.StatusConflict, gin.H{"error": "Container is not running"})
+		return
+	}
+
+	changes, err := h.headlessManager.GetConversationChanges(c.Request.Context(), container.DockerID, uint(conversationID))
+	if err != nil {
+		if errors.Is(err, headless.ErrConversationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
 // GetConversationTurns 获取对话的轮次列表
 func (h *HeadlessHandler) GetConversationTurns(c *gin.Context) {
 	containerIDStr := c.Param("id")
@@ -1666,3 +2471,83 @@ func (h *HeadlessHandler) GetConversationTurns(c *gin.Context) {
 		"has_more": hasMore,
 	})
 }
+
+// GetTurnEvents 分页回放轮次保存的原始流事件（包括已结束的轮次），用于离线渲染完整的工具调用细节；
+// after 为上一页最后一个事件的 event_index
+// GET /api/containers/:id/headless/conversations/:conversationId/turns/:turnId/events?after=&limit=
+func (h *HeadlessHandler) GetTurnEvents(c *gin.Context) {
+	containerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+	conversationID, err := strconv.ParseUint(c.Param("conversationId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return
+	}
+	turnID, err := strconv.ParseUint(c.Param("turnId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid turn ID"})
+		return
+	}
+
+	after := -1
+	if v := c.Query("after"); v != "" {
+		if after, err = strconv.Atoi(v); err != nil || after < -1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "after must be an event index"})
+			return
+		}
+	}
+	limit := 200
+	if v := c.Query("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > headless.MaxTurnEventsPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", headless.MaxTurnEventsPageSize)})
+			return
+		}
+	}
+
+	historyManager := h.headlessManager.GetHistoryManager()
+	if historyManager == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "History manager not available"})
+		return
+	}
+
+	conversation, err := historyManager.GetConversationByID(uint(conversationID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if conversation == nil || conversation.ContainerID != uint(containerID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+	turn, err := historyManager.GetTurnByID(uint(turnID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if turn == nil || turn.ConversationID != conversation.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Turn not found"})
+		return
+	}
+
+	events, hasMore, err := historyManager.GetTurnEventsPage(turn.ID, after, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	infos := make([]headless.EventInfo, len(events))
+	for i := range events {
+		infos[i] = convertEventToInfo(&events[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"turn_id":          turn.ID,
+		"state":            turn.State,
+		"events_truncated": turn.EventsTruncated,
+		"events_pruned":    turn.EventsPruned,
+		"events":           infos,
+		"has_more":         hasMore,
+	})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"cc-platform/internal/headless"
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestReplayCurrentTurnEvents_WaitsForSendRoom(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:headlessreplaydb?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.HeadlessConversation{}, &models.HeadlessTurn{}, &models.HeadlessEvent{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	historyManager := headless.NewHeadlessHistoryManager(db)
+	conv, err := historyManager.CreateConversation("replay-session", 1)
+	if err != nil {
+		t.Fatalf("CreateConversation failed: %v", err)
+	}
+	turn, err := historyManager.StartTurn(conv.ID, "long turn", models.HeadlessPromptSourceUser, nil)
+	if err != nil {
+		t.Fatalf("StartTurn failed: %v", err)
+	}
+	const total = 120
+	for i := 1; i <= total; i++ {
+		raw := fmt.Sprintf(`{"type":"assistant","session_id":"e%d"}`, i)
+		if err := historyManager.AppendEvent(turn.ID, models.HeadlessEventTypeAssistant, "", raw, uint64(i)); err != nil {
+			t.Fatalf("AppendEvent failed: %v", err)
+		}
+	}
+
+	session := headless.NewHeadlessSession("replay-session", 1, "docker", "/workspace", historyManager)
+	session.SetCurrentTurnID(turn.ID)
+
+	// A small queue drained slowly: the replay must wait for room instead of overflowing it
+	sendChan := make(chan *headless.HeadlessResponse, 8)
+	done := make(chan struct{})
+	received := make(chan []uint64)
+	go func() {
+		var seqs []uint64
+		for resp := range sendChan {
+			seqs = append(seqs, resp.Seq)
+			time.Sleep(time.Millisecond)
+		}
+		received <- seqs
+	}()
+
+	dropped := 0
+	err = replayCurrentTurnEvents(historyManager, session, resumeParams{}, sendChan, done, func(evt *headless.StreamEvent, seq uint64) {
+		select {
+		case sendChan <- &headless.HeadlessResponse{Type: headless.HeadlessResponseTypeEvent, Payload: evt, Seq: seq}:
+		default:
+			dropped++
+		}
+	})
+	close(sendChan)
+	if err != nil {
+		t.Fatalf("replayCurrentTurnEvents failed: %v", err)
+	}
+
+	seqs := <-received
+	if dropped != 0 || len(seqs) != total {
+		t.Fatalf("Expected %d events without drops, got %d (%d dropped)", total, len(seqs), dropped)
+	}
+	for i, seq := range seqs {
+		if seq != uint64(i+1) {
+			t.Fatalf("Expected seq %d at %d, got %d", i+1, i, seq)
+		}
+	}
+
+	// A closed connection stops the replay while it waits for room
+	full := make(chan *headless.HeadlessResponse, 1)
+	full <- &headless.HeadlessResponse{}
+	close(done)
+	sent := 0
+	err = replayCurrentTurnEvents(historyManager, session, resumeParams{}, full, done, func(*headless.StreamEvent, uint64) { sent++ })
+	if err != nil || sent != 0 {
+		t.Errorf("Expected no events on a closed connection, sent %d (%v)", sent, err)
+	}
+}
+
+func TestResumeAfterSeq(t *testing.T) {
+	session := headless.NewHeadlessSession("s1", 1, "docker", "/workspace", nil)
+
+	if seq := resumeAfterSeq(resumeParams{}, session); seq != 0 {
+		t.Errorf("Expected 0 without last_seq, got %d", seq)
+	}
+	if seq := resumeAfterSeq(resumeParams{ok: true, lastSeq: 0, sessionID: "s1"}, session); seq != 0 {
+		t.Errorf("Expected 0 for last_seq 0, got %d", seq)
+	}
+	// Sequence numbers beyond the session's latest event belong to another session
+	if seq := resumeAfterSeq(resumeParams{ok: true, lastSeq: 5, sessionID: "s1"}, session); seq != 0 {
+		t.Errorf("Expected 0 for an unknown sequence number, got %d", seq)
+	}
+	if seq := resumeAfterSeq(resumeParams{ok: true, lastSeq: 5, sessionID: "other"}, session); seq != 0 {
+		t.Errorf("Expected 0 for another session, got %d", seq)
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/headless"
+	"cc-platform/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestUpdateConversationSystemPrompt_RequiresOwningContainer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open("file:headlesspromptdb?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.HeadlessConversation{}, &models.HeadlessTurn{}, &models.HeadlessEvent{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	manager := headless.NewHeadlessManager(db, nil)
+	conv, err := manager.GetHistoryManager().CreateConversation("prompt-session", 1)
+	if err != nil {
+		t.Fatalf("CreateConversation failed: %v", err)
+	}
+	h := &HeadlessHandler{headlessManager: manager}
+	router := gin.New()
+	router.PUT("/api/containers/:id/headless/conversations/:conversationId/system-prompt", h.UpdateConversationSystemPrompt)
+
+	put := func(containerID string) int {
+		req := httptest.NewRequest(http.MethodPut,
+			"/api/containers/"+containerID+"/headless/conversations/"+fmt.Sprint(conv.ID)+"/system-prompt",
+			strings.NewReader(`{"system_prompt": "Be brief."}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Another container's ID must not reach the conversation
+	if code := put("2"); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for another container, got %d", code)
+	}
+	if got, _ := manager.GetHistoryManager().GetConversationByID(conv.ID); got.SystemPrompt != "" {
+		t.Errorf("Expected the prompt to be unchanged, got %q", got.SystemPrompt)
+	}
+
+	if code := put("1"); code != http.StatusOK {
+		t.Fatalf("Expected 200 for the owning container, got %d", code)
+	}
+	if got, _ := manager.GetHistoryManager().GetConversationByID(conv.ID); got.SystemPrompt != "Be brief." {
+		t.Errorf("Expected the prompt to be saved, got %q", got.SystemPrompt)
+	}
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
+	"cc-platform/internal/middleware"
 	"cc-platform/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -31,13 +33,13 @@ type AddPortRequest struct {
 func (h *PortHandler) ListPorts(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
 	ports, err := h.portService.ListPorts(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list ports"})
+		writeAPIError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to list ports")
 		return
 	}
 
@@ -48,13 +50,13 @@ func (h *PortHandler) ListPorts(c *gin.Context) {
 func (h *PortHandler) AddPort(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
 	var req AddPortRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		writeInvalidRequest(c, "Invalid request body", err)
 		return
 	}
 
@@ -63,13 +65,10 @@ func (h *PortHandler) AddPort(c *gin.Context) {
 		protocol = "http"
 	}
 
+	c.Set(middleware.AuditTargetKey, strconv.Itoa(req.Port))
 	port, err := h.portService.AddPort(id, req.Port, req.Name, protocol, false)
 	if err != nil {
-		if err == services.ErrPortAlreadyExists {
-			c.JSON(http.StatusConflict, gin.H{"error": "Port already exists"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 
@@ -80,23 +79,19 @@ func (h *PortHandler) AddPort(c *gin.Context) {
 func (h *PortHandler) RemovePort(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		writeAPIError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid container ID")
 		return
 	}
 
 	portStr := c.Param("port")
 	port, err := parseID(portStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid port"})
+		writeAPIError(c, http.StatusBadRequest, "INVALID_PORT", "Invalid port")
 		return
 	}
 
 	if err := h.portService.RemovePort(id, int(port)); err != nil {
-		if err == services.ErrPortNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Port not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 
@@ -107,7 +102,7 @@ func (h *PortHandler) RemovePort(c *gin.Context) {
 func (h *PortHandler) ListAllPorts(c *gin.Context) {
 	ports, err := h.portService.ListAllPorts()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list ports"})
+		writeAPIError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to list ports")
 		return
 	}
 
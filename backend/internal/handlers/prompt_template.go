@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromptTemplateHandler handles the headless prompt template library
+type PromptTemplateHandler struct {
+	service *services.PromptTemplateService
+}
+
+// NewPromptTemplateHandler creates a new PromptTemplateHandler
+func NewPromptTemplateHandler(service *services.PromptTemplateService) *PromptTemplateHandler {
+	return &PromptTemplateHandler{service: service}
+}
+
+// RegisterRoutes registers all prompt template routes
+func (h *PromptTemplateHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	templates := rg.Group("/prompt-templates")
+	{
+		templates.POST("", h.CreatePromptTemplate)
+		templates.GET("", h.ListPromptTemplates)
+		templates.GET("/:id", h.GetPromptTemplate)
+		templates.PUT("/:id", h.UpdatePromptTemplate)
+		templates.DELETE("/:id", h.DeletePromptTemplate)
+		templates.POST("/:id/render", h.RenderPromptTemplate)
+	}
+}
+
+// CreatePromptTemplate creates a new prompt template
+// POST /api/prompt-templates
+func (h *PromptTemplateHandler) CreatePromptTemplate(c *gin.Context) {
+	var input services.PromptTemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	template, err := h.service.Create(input)
+	if err != nil {
+		writePromptTemplateError(c, err, "Failed to create prompt template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// ListPromptTemplates lists prompt templates, optionally only those with a tag
+// GET /api/prompt-templates?tag=
+func (h *PromptTemplateHandler) ListPromptTemplates(c *gin.Context) {
+	templates, err := h.service.List(c.Query("tag"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list prompt templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetPromptTemplate gets a prompt template by ID
+// GET /api/prompt-templates/:id
+func (h *PromptTemplateHandler) GetPromptTemplate(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	template, err := h.service.GetByID(id)
+	if err != nil {
+		writePromptTemplateError(c, err, "Failed to get prompt template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// UpdatePromptTemplate replaces a prompt template
+// PUT /api/prompt-templates/:id
+func (h *PromptTemplateHandler) UpdatePromptTemplate(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var input services.PromptTemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	template, err := h.service.Update(id, input)
+	if err != nil {
+		writePromptTemplateError(c, err, "Failed to update prompt template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeletePromptTemplate deletes a prompt template
+// DELETE /api/prompt-templates/:id
+func (h *PromptTemplateHandler) DeletePromptTemplate(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		writePromptTemplateError(c, err, "Failed to delete prompt template")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Prompt template deleted successfully"})
+}
+
+// RenderPromptTemplate renders a prompt template with variable values without sending it
+// POST /api/prompt-templates/:id/render
+func (h *PromptTemplateHandler) RenderPromptTemplate(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var input struct {
+		Variables map[string]string `json:"variables"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	prompt, err := h.service.Render(id, input.Variables)
+	if err != nil {
+		writePromptTemplateError(c, err, "Failed to render prompt template")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"prompt": prompt})
+}
+
+// writePromptTemplateError maps prompt template service errors to HTTP responses
+func writePromptTemplateError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrPromptTemplateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "prompt template not found"})
+	case errors.Is(err, services.ErrInvalidPromptTemplate), errors.Is(err, services.ErrMissingTemplateVariable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDuplicatePromptTemplate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
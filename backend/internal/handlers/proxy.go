@@ -54,6 +54,10 @@ func (h *ProxyHandler) ProxyRequest(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Container not found: %v", err)})
 		return
 	}
+	if container.Status == models.ContainerStatusPaused {
+		c.JSON(http.StatusConflict, gin.H{"error": "Container is paused"})
+		return
+	}
 	
 	// Get container IP (prefers traefik-net, falls back to bridge)
 	containerIP, err := h.containerService.GetContainerIP(c.Request.Context(), container.ID)
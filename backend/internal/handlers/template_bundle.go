@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateBundleHandler handles template bundle endpoints
+type TemplateBundleHandler struct {
+	service          services.TemplateBundleService
+	containerService *services.ContainerService
+}
+
+// NewTemplateBundleHandler creates a new TemplateBundleHandler
+func NewTemplateBundleHandler(service services.TemplateBundleService, containerService *services.ContainerService) *TemplateBundleHandler {
+	return &TemplateBundleHandler{
+		service:          service,
+		containerService: containerService,
+	}
+}
+
+// RegisterRoutes registers all template bundle routes
+func (h *TemplateBundleHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	bundles := rg.Group("/config-bundles")
+	{
+		bundles.POST("", h.CreateBundle)
+		bundles.GET("", h.ListBundles)
+		bundles.GET("/:id", h.GetBundle)
+		bundles.PUT("/:id", h.UpdateBundle)
+		bundles.DELETE("/:id", h.DeleteBundle)
+	}
+	rg.POST("/containers/:id/apply-bundle/:bundleId", h.ApplyBundle)
+}
+
+// CreateBundle creates a new template bundle
+// POST /api/config-bundles
+func (h *TemplateBundleHandler) CreateBundle(c *gin.Context) {
+	var input services.CreateTemplateBundleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	bundle, err := h.service.Create(input)
+	if err != nil {
+		h.writeBundleError(c, err, "Failed to create bundle")
+		return
+	}
+
+	c.JSON(http.StatusCreated, bundle)
+}
+
+// ListBundles lists all template bundles
+// GET /api/config-bundles
+func (h *TemplateBundleHandler) ListBundles(c *gin.Context) {
+	bundles, err := h.service.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list bundles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, bundles)
+}
+
+// GetBundle gets a template bundle by ID
+// GET /api/config-bundles/:id
+func (h *TemplateBundleHandler) GetBundle(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	bundle, err := h.service.GetByID(id)
+	if err != nil {
+		h.writeBundleError(c, err, "Failed to get bundle")
+		return
+	}
+
+	c.JSON(http.StatusOK, bundle)
+}
+
+// UpdateBundle updates a template bundle
+// PUT /api/config-bundles/:id
+func (h *TemplateBundleHandler) UpdateBundle(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var input services.UpdateTemplateBundleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	bundle, err := h.service.Update(id, input)
+	if err != nil {
+		h.writeBundleError(c, err, "Failed to update bundle")
+		return
+	}
+
+	c.JSON(http.StatusOK, bundle)
+}
+
+// DeleteBundle deletes a template bundle
+// DELETE /api/config-bundles/:id
+func (h *TemplateBundleHandler) DeleteBundle(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		h.writeBundleError(c, err, "Failed to delete bundle")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bundle deleted successfully"})
+}
+
+// ApplyBundle injects every template in a bundle into a running container
+// POST /api/containers/:id/apply-bundle/:bundleId
+func (h *TemplateBundleHandler) ApplyBundle(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+
+	bundleID, err := parseID(c.Param("bundleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bundle ID"})
+		return
+	}
+
+	bundle, err := h.service.GetByID(bundleID)
+	if err != nil {
+		h.writeBundleError(c, err, "Failed to get bundle")
+		return
+	}
+
+	templateIDs, warnings := h.service.ResolveTemplateIDs(bundle)
+
+	status, err := h.containerService.InjectConfigsWithWarnings(c.Request.Context(), id, templateIDs, warnings)
+	if err != nil {
+		if err == services.ErrContainerNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bundle applied",
+		"bundle":  bundle.Name,
+		"status":  status,
+	})
+}
+
+// writeBundleError maps bundle service errors to HTTP responses
+func (h *TemplateBundleHandler) writeBundleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrBundleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "bundle not found"})
+	case errors.Is(err, services.ErrInvalidBundleName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDuplicateBundleName) || strings.Contains(err.Error(), "already exists"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -17,11 +17,8 @@ import (
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		origin := r.Header.Get("Origin")
-		// Use the same origin whitelist as CORS middleware
-		return middleware.IsOriginAllowed(origin)
-	},
+	// Use the same origin allowlist as the CORS middleware
+	CheckOrigin: middleware.CheckWebSocketOrigin,
 }
 
 // Docker internal network ranges
@@ -116,52 +113,29 @@ func (h *TerminalHandler) HandleWebSocket(c *gin.Context) {
 		}
 		containerID = container.ID
 	}
+	c.Set(middleware.AuditContainerIDKey, containerID)
 
+	if container.Status == models.ContainerStatusPaused {
+		c.JSON(http.StatusConflict, gin.H{"error": "Container is paused"})
+		return
+	}
 	if container.Status != models.ContainerStatusRunning {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Container is not running"})
 		return
 	}
 
-	// Check if request is from internal Docker network (container-to-host communication)
-	// These requests come from VS Code extension running inside containers
-	clientIP := c.ClientIP()
-	isInternalRequest := isDockerInternalIP(clientIP)
-
-	// Authenticate via multiple sources:
-	// 1. Internal Docker network - skip auth for container-internal requests
-	// 2. Cookie (cc_token) - automatically sent with WebSocket for same-origin
-	// 3. Query parameter (token) - fallback for cross-origin or explicit token
-	
-	if !isInternalRequest {
-		var token string
-
-		// Try cookie first (httpOnly cookie is sent automatically with WebSocket)
-		if cookieToken, err := c.Cookie(middleware.TokenCookieName); err == nil && cookieToken != "" {
-			token = cookieToken
-		}
-
-		// Fallback to query parameter
-		if token == "" {
-			token = c.Query("token")
-		}
-
-		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing authentication token"})
-			return
-		}
-
-		_, err = h.authService.VerifyToken(token)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
-			return
-		}
+	// Requests from the internal Docker network (the VS Code extension running inside
+	// containers) skip auth; everyone else needs a token from the auth subprotocol, the
+	// cc_token cookie or the token query parameter
+	if !authorizeWebSocket(c, h.authService, "TerminalHandler") {
+		return
 	}
 
 	// Get optional session ID for reconnection
 	sessionID := c.Query("session")
 
 	// Upgrade to WebSocket
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, webSocketResponseHeader(c.Request))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upgrade connection"})
 		return
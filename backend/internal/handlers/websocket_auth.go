@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"cc-platform/internal/middleware"
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketAuthProtocol is the Sec-WebSocket-Protocol entry that announces a token. Browsers
+// send the token as the entry right after it: new WebSocket(url, ["cc-auth", token]). Unlike
+// a ?token= query parameter, the header does not end up in access logs and proxy logs.
+const WebSocketAuthProtocol = "cc-auth"
+
+// webSocketProtocolToken returns the token offered through the auth subprotocol, if any
+func webSocketProtocolToken(r *http.Request) string {
+	protocols := websocket.Subprotocols(r)
+	for i, protocol := range protocols {
+		if protocol == WebSocketAuthProtocol && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
+	}
+	return ""
+}
+
+// webSocketResponseHeader echoes the auth subprotocol back when the client offered it.
+// Browsers close the connection if none of their subprotocols is accepted; the token itself
+// is never echoed.
+func webSocketResponseHeader(r *http.Request) http.Header {
+	for _, protocol := range websocket.Subprotocols(r) {
+		if protocol == WebSocketAuthProtocol {
+			return http.Header{"Sec-WebSocket-Protocol": []string{WebSocketAuthProtocol}}
+		}
+	}
+	return nil
+}
+
+// authorizeWebSocket applies the WebSocket auth rules shared by the terminal, headless and stats
+// handlers: requests from the Docker network skip auth, everyone else needs a valid token from
+// the auth subprotocol, the cookie or the query parameter, in that order.
+// It writes the error response itself and returns false when the request must be rejected.
+func authorizeWebSocket(c *gin.Context, authService *services.AuthService, logPrefix string) bool {
+	if isDockerInternalIP(c.ClientIP()) {
+		return true
+	}
+
+	token := webSocketProtocolToken(c.Request)
+	if token == "" {
+		if cookieToken, err := c.Cookie(middleware.TokenCookieName); err == nil && cookieToken != "" {
+			token = cookieToken
+		}
+	}
+	if token == "" {
+		token = c.Query("token")
+	}
+	if token == "" {
+		log.Printf("[%s] Missing auth token from %s (origin: %s)", logPrefix, c.ClientIP(), c.GetHeader("Origin"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing authentication token"})
+		return false
+	}
+	if authService == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return false
+	}
+	claims, err := authService.VerifyToken(token)
+	if err != nil {
+		log.Printf("[%s] Invalid auth token from %s (origin: %s): %v", logPrefix, c.ClientIP(), c.GetHeader("Origin"), err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication token"})
+		return false
+	}
+	// The actor of audited WebSocket routes
+	c.Set("username", claims.Username)
+	return true
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cc-platform/internal/config"
+	"cc-platform/internal/models"
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var wsAuthTestDBCounter int
+
+// setupWebSocketAuthTest serves the stats WebSocket with auth enabled and returns a valid token
+func setupWebSocketAuthTest(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	wsAuthTestDBCounter++
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:wsauthdb%d?mode=memory&cache=shared", wsAuthTestDBCounter)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.RevokedToken{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	authService, err := services.NewAuthService(db, &config.Config{
+		JWTSecret:      "test-jwt-secret-32-bytes-long!!",
+		AdminUsername:  "admin",
+		AdminPassword:  "testpassword123",
+		AccessTokenTTL: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("failed to create auth service: %v", err)
+	}
+	token, err := authService.Login("admin", "testpassword123", "203.0.113.5")
+	if err != nil {
+		t.Fatalf("failed to log in: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := &ContainerStatsHandler{statsStreamer: &fakeStatsStreamer{stream: newFakeStatsStream()}, authService: authService, interval: time.Hour}
+	router.GET("/api/ws/containers/:id/stats", handler.HandleStatsWebSocket)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, token
+}
+
+// dialWithAuth dials from an external address so the request is not treated as internal
+func dialWithAuth(server *httptest.Server, query string, protocols []string) (*websocket.Conn, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws/containers/1/stats" + query
+	header := http.Header{"Origin": []string{"http://localhost:5173"}, "X-Forwarded-For": []string{"203.0.113.5"}}
+	dialer := websocket.Dialer{Subprotocols: protocols, HandshakeTimeout: 2 * time.Second}
+	return dialer.Dial(url, header)
+}
+
+func TestWebSocketAuth_Subprotocol(t *testing.T) {
+	server, token := setupWebSocketAuthTest(t)
+
+	conn, _, err := dialWithAuth(server, "", []string{WebSocketAuthProtocol, token})
+	if err != nil {
+		t.Fatalf("Expected the subprotocol token to be accepted: %v", err)
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != WebSocketAuthProtocol {
+		t.Errorf("Expected %q to be echoed back, got %q", WebSocketAuthProtocol, conn.Subprotocol())
+	}
+
+	_, resp, err := dialWithAuth(server, "", []string{WebSocketAuthProtocol, "not-a-token"})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an invalid subprotocol token, got %v", err)
+	}
+	// The marker without a token is no credential
+	_, resp, err = dialWithAuth(server, "", []string{WebSocketAuthProtocol})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %v", err)
+	}
+}
+
+func TestWebSocketAuth_QueryToken(t *testing.T) {
+	server, token := setupWebSocketAuthTest(t)
+
+	conn, _, err := dialWithAuth(server, "?token="+token, nil)
+	if err != nil {
+		t.Fatalf("Expected the query token to be accepted: %v", err)
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != "" {
+		t.Errorf("Expected no subprotocol, got %q", conn.Subprotocol())
+	}
+
+	_, resp, err := dialWithAuth(server, "?token=not-a-token", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an invalid query token, got %v", err)
+	}
+}
+
+func TestWebSocketAuth_SubprotocolPreferredOverQuery(t *testing.T) {
+	server, token := setupWebSocketAuthTest(t)
+
+	// A valid header wins over a stale query token
+	conn, _, err := dialWithAuth(server, "?token=stale", []string{WebSocketAuthProtocol, token})
+	if err != nil {
+		t.Fatalf("Expected the subprotocol token to be used: %v", err)
+	}
+	conn.Close()
+
+	// An invalid header is not rescued by a valid query token
+	_, resp, err := dialWithAuth(server, "?token="+token, []string{WebSocketAuthProtocol, "stale"})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected the subprotocol token to take precedence, got %v", err)
+	}
+}
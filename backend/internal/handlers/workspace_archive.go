@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListWorkspaceArchives lists workspaces preserved when their containers were deleted
+// GET /api/workspace-archives
+func (h *ContainerHandler) ListWorkspaceArchives(c *gin.Context) {
+	archives, err := h.containerService.ListWorkspaceArchives()
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, archives)
+}
+
+// DownloadWorkspaceArchive downloads a preserved workspace as a gzipped tar archive
+// GET /api/workspace-archives/:id/download
+func (h *ContainerHandler) DownloadWorkspaceArchive(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid archive ID"})
+		return
+	}
+
+	archive, f, err := h.containerService.OpenWorkspaceArchive(id)
+	if err != nil {
+		writeWorkspaceArchiveError(c, err)
+		return
+	}
+	defer f.Close()
+
+	filename := strings.TrimSuffix(archive.FileName, ".tar.gz")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "workspace-"+filename+".tar.gz"))
+	c.DataFromReader(http.StatusOK, archive.SizeBytes, "application/gzip", f, nil)
+}
+
+// DeleteWorkspaceArchive deletes a preserved workspace
+// DELETE /api/workspace-archives/:id
+func (h *ContainerHandler) DeleteWorkspaceArchive(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid archive ID"})
+		return
+	}
+	if err := h.containerService.DeleteWorkspaceArchive(id); err != nil {
+		writeWorkspaceArchiveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Workspace archive deleted"})
+}
+
+// RestoreWorkspaceRequest selects the preserved workspace to restore
+type RestoreWorkspaceRequest struct {
+	ArchiveID uint `json:"archive_id" binding:"required"`
+}
+
+// RestoreWorkspace extracts a preserved workspace into the container's work directory
+// POST /api/containers/:id/workspace/restore
+func (h *ContainerHandler) RestoreWorkspace(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid container ID"})
+		return
+	}
+	var req RestoreWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.containerService.RestoreWorkspace(c.Request.Context(), id, req.ArchiveID)
+	if err != nil {
+		writeWorkspaceArchiveError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// writeWorkspaceArchiveError maps workspace archive errors to status codes
+func writeWorkspaceArchiveError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrContainerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+	case errors.Is(err, services.ErrWorkspaceArchiveNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrContainerPaused):
+		c.JSON(http.StatusConflict, gin.H{"error": "Container is paused"})
+	case errors.Is(err, services.ErrContainerNotRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": "Container is not running"})
+	case errors.Is(err, services.ErrWorkspaceTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidWorkspaceArchive):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		writeServerError(c, err)
+	}
+}
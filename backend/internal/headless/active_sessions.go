@@ -0,0 +1,62 @@
+package headless
+
+import (
+	"sort"
+	"time"
+)
+
+// ActiveSessionSummary 活跃 Headless 会话的概要，供运维查看全局运行情况
+type ActiveSessionSummary struct {
+	SessionID          string        `json:"session_id"`
+	ContainerID        uint          `json:"container_id"`
+	ConversationID     uint          `json:"conversation_id"`
+	State              HeadlessState `json:"state"`
+	CurrentTurnID      uint          `json:"current_turn_id,omitempty"`
+	Model              string        `json:"model,omitempty"`
+	ClientCount        int           `json:"client_count"`
+	CreatedAt          time.Time     `json:"created_at"`
+	LastActiveAt       time.Time     `json:"last_active_at"`
+	ElapsedSeconds     int64         `json:"elapsed_seconds"`                // 会话创建至今的时长
+	TurnElapsedSeconds int64         `json:"turn_elapsed_seconds,omitempty"` // 当前轮次已运行的时长（仅运行中）
+}
+
+// ListActiveSessions 返回内存中所有未关闭的会话，containerID 为 0 时不按容器过滤。
+// 结果按容器 ID、创建时间排序
+func (m *HeadlessManager) ListActiveSessions(containerID uint) []ActiveSessionSummary {
+	now := time.Now()
+	summaries := make([]ActiveSessionSummary, 0)
+	for _, session := range m.GetAllSessions() {
+		if session.IsClosed() || (containerID != 0 && session.ContainerID != containerID) {
+			continue
+		}
+		summaries = append(summaries, session.summary(now))
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].ContainerID != summaries[j].ContainerID {
+			return summaries[i].ContainerID < summaries[j].ContainerID
+		}
+		return summaries[i].CreatedAt.Before(summaries[j].CreatedAt)
+	})
+	return summaries
+}
+
+// summary 生成会话在 now 时刻的概要
+func (s *HeadlessSession) summary(now time.Time) ActiveSessionSummary {
+	summary := ActiveSessionSummary{
+		SessionID:      s.ID,
+		ContainerID:    s.ContainerID,
+		ConversationID: s.ConversationID,
+		State:          s.GetState(),
+		CurrentTurnID:  s.GetCurrentTurnID(),
+		Model:          s.Model,
+		ClientCount:    s.GetClientCount(),
+		CreatedAt:      s.CreatedAt,
+		LastActiveAt:   s.GetLastActive(),
+		ElapsedSeconds: int64(now.Sub(s.CreatedAt).Seconds()),
+	}
+	if summary.State == HeadlessStateRunning {
+		summary.TurnElapsedSeconds = int64(now.Sub(s.responseBuilder.StartedAt()).Seconds())
+	}
+	return summary
+}
@@ -0,0 +1,48 @@
+package headless
+
+import (
+	"testing"
+)
+
+func TestHeadlessManager_ListActiveSessions(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	running, turn := startRunningTurn(t, mgr, 7)
+	running.Model = "claude-sonnet-4"
+	idle, err := mgr.CreateSession(3, "docker-idle", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	closed, err := mgr.CreateSession(3, "docker-closed", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	closed.SetState(HeadlessStateClosed)
+
+	all := mgr.ListActiveSessions(0)
+	if len(all) != 2 {
+		t.Fatalf("expected 2 active sessions, got %+v", all)
+	}
+	if all[0].SessionID != idle.ID || all[1].SessionID != running.ID {
+		t.Errorf("expected sessions ordered by container, got %s, %s", all[0].SessionID, all[1].SessionID)
+	}
+
+	got := all[1]
+	if got.State != HeadlessStateRunning || got.CurrentTurnID != turn.ID || got.Model != "claude-sonnet-4" ||
+		got.ConversationID != running.ConversationID || got.ContainerID != 7 {
+		t.Errorf("unexpected summary for the running session: %+v", got)
+	}
+	if all[0].State != HeadlessStateIdle || all[0].CurrentTurnID != 0 || all[0].TurnElapsedSeconds != 0 {
+		t.Errorf("unexpected summary for the idle session: %+v", all[0])
+	}
+
+	filtered := mgr.ListActiveSessions(7)
+	if len(filtered) != 1 || filtered[0].SessionID != running.ID {
+		t.Errorf("expected only container 7's session, got %+v", filtered)
+	}
+	if none := mgr.ListActiveSessions(99); len(none) != 0 {
+		t.Errorf("expected no sessions for an unknown container, got %+v", none)
+	}
+}
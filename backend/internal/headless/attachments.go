@@ -0,0 +1,104 @@
+package headless
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+
+	"cc-platform/internal/constants"
+)
+
+var (
+	// ErrInvalidFilePath 文件路径不合法（为空、绝对路径、逃逸出工作目录或包含空白字符）
+	ErrInvalidFilePath = errors.New("invalid file path")
+	// ErrTooManyFiles 引用的文件数量超过限制
+	ErrTooManyFiles = errors.New("too many files")
+	// ErrFileNotFound 引用的文件在容器工作目录中不存在
+	ErrFileNotFound = errors.New("file not found")
+)
+
+// checkAttachedFilesScript 在工作目录中解析每个文件的真实路径，
+// 输出不存在的文件（missing:）和经符号链接逃逸出工作目录的文件（outside:）
+const checkAttachedFilesScript = `root=$(pwd -P); for f in "$@"; do r=$(realpath -e -- "$f" 2>/dev/null); if [ -z "$r" ] || [ ! -f "$r" ]; then echo "missing:$f"; else case "$r" in "$root"/*) ;; *) echo "outside:$f";; esac; fi; done`
+
+// NormalizeAttachedFiles 校验并规范化 prompt 引用的文件路径。
+// 路径必须相对于工作目录且不能逃逸出工作目录；重复路径只保留一次。
+func NormalizeAttachedFiles(filePaths []string) ([]string, error) {
+	if len(filePaths) > constants.HeadlessMaxAttachedFiles {
+		return nil, fmt.Errorf("%w: %d (max %d)", ErrTooManyFiles, len(filePaths), constants.HeadlessMaxAttachedFiles)
+	}
+
+	normalized := make([]string, 0, len(filePaths))
+	seen := make(map[string]bool, len(filePaths))
+	for _, p := range filePaths {
+		clean, err := normalizeAttachedFile(p)
+		if err != nil {
+			return nil, err
+		}
+		if seen[clean] {
+			continue
+		}
+		seen[clean] = true
+		normalized = append(normalized, clean)
+	}
+	return normalized, nil
+}
+
+// normalizeAttachedFile 规范化单个路径
+func normalizeAttachedFile(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return "", fmt.Errorf("%w: empty path", ErrInvalidFilePath)
+	}
+	// @ 引用以空白字符结束，路径中不能包含空白字符
+	if strings.IndexFunc(p, unicode.IsSpace) >= 0 || strings.ContainsRune(p, 0) {
+		return "", fmt.Errorf("%w: %q contains whitespace", ErrInvalidFilePath, p)
+	}
+	if path.IsAbs(p) {
+		return "", fmt.Errorf("%w: %q must be relative to the work directory", ErrInvalidFilePath, p)
+	}
+
+	clean := path.Clean(p)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("%w: %q escapes the work directory", ErrInvalidFilePath, p)
+	}
+	return clean, nil
+}
+
+// BuildPromptWithFiles 将引用的文件以 @path 形式放在 prompt 之前，由 Claude CLI 读取文件内容
+func BuildPromptWithFiles(prompt string, files []string) string {
+	if len(files) == 0 {
+		return prompt
+	}
+
+	refs := make([]string, len(files))
+	for i, f := range files {
+		refs[i] = "@" + f
+	}
+	return strings.Join(refs, " ") + "\n\n" + prompt
+}
+
+// checkAttachedFiles 在容器中确认文件存在且位于工作目录内
+func checkAttachedFiles(ctx context.Context, exec workDirExecutor, dockerID, workDir string, files []string) error {
+	output, err := exec(ctx, dockerID, workDir, append([]string{"sh", "-c", checkAttachedFilesScript, "sh"}, files...))
+	if err != nil {
+		return fmt.Errorf("failed to check attached files: %w", err)
+	}
+	return parseAttachedFilesCheck(output)
+}
+
+// parseAttachedFilesCheck 将检查脚本的输出转换为错误
+func parseAttachedFilesCheck(output string) error {
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		switch {
+		case strings.HasPrefix(line, "missing:"):
+			return fmt.Errorf("%w: %s", ErrFileNotFound, strings.TrimPrefix(line, "missing:"))
+		case strings.HasPrefix(line, "outside:"):
+			return fmt.Errorf("%w: %q escapes the work directory", ErrInvalidFilePath, strings.TrimPrefix(line, "outside:"))
+		}
+	}
+	return nil
+}
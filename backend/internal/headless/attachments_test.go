@@ -0,0 +1,150 @@
+package headless
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+)
+
+func TestNormalizeAttachedFiles(t *testing.T) {
+	got, err := NormalizeAttachedFiles([]string{"src/main.go", " ./README.md ", "src/../src/main.go", "docs//guide.md"})
+	if err != nil {
+		t.Fatalf("NormalizeAttachedFiles error: %v", err)
+	}
+	want := []string{"src/main.go", "README.md", "docs/guide.md"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeAttachedFiles() = %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeAttachedFiles_RejectsInvalidPaths(t *testing.T) {
+	cases := []string{
+		"",
+		"/etc/passwd",
+		"..",
+		"../secret.txt",
+		"src/../../secret.txt",
+		".",
+		"my file.txt",
+		"tab\tname.txt",
+	}
+	for _, p := range cases {
+		if _, err := NormalizeAttachedFiles([]string{p}); !errors.Is(err, ErrInvalidFilePath) {
+			t.Errorf("NormalizeAttachedFiles(%q) error = %v, want ErrInvalidFilePath", p, err)
+		}
+	}
+}
+
+func TestNormalizeAttachedFiles_TooMany(t *testing.T) {
+	paths := make([]string, constants.HeadlessMaxAttachedFiles+1)
+	for i := range paths {
+		paths[i] = "file.txt"
+	}
+	if _, err := NormalizeAttachedFiles(paths); !errors.Is(err, ErrTooManyFiles) {
+		t.Errorf("expected ErrTooManyFiles, got %v", err)
+	}
+}
+
+func TestBuildPromptWithFiles(t *testing.T) {
+	if got := BuildPromptWithFiles("explain this", nil); got != "explain this" {
+		t.Errorf("prompt without files should be unchanged, got %q", got)
+	}
+
+	got := BuildPromptWithFiles("explain this", []string{"src/main.go", "README.md"})
+	want := "@src/main.go @README.md\n\nexplain this"
+	if got != want {
+		t.Errorf("BuildPromptWithFiles() = %q, want %q", got, want)
+	}
+}
+
+func TestParseAttachedFilesCheck(t *testing.T) {
+	if err := parseAttachedFilesCheck(""); err != nil {
+		t.Errorf("empty output should pass, got %v", err)
+	}
+	if err := parseAttachedFilesCheck("missing:src/gone.go\n"); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("expected ErrFileNotFound, got %v", err)
+	}
+	if err := parseAttachedFilesCheck("outside:link-to-etc\n"); !errors.Is(err, ErrInvalidFilePath) {
+		t.Errorf("expected ErrInvalidFilePath, got %v", err)
+	}
+}
+
+func TestHeadlessManager_SendPromptWithFilesRecordsTurnFiles(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	var checked []string
+	mgr.execWorkDir = func(ctx context.Context, dockerID, workDir string, cmd []string) (string, error) {
+		if cmd[2] == checkAttachedFilesScript {
+			checked = cmd[4:] // sh -c <script> sh <files...>
+		}
+		return "", nil
+	}
+
+	session, err := mgr.CreateSession(9, "docker-9", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	// 运行中的会话会将 prompt 排队，不会启动 Claude 进程
+	session.SetState(HeadlessStateRunning)
+
+	err = mgr.SendPromptWithFiles(session.ID, "review", models.HeadlessPromptSourceUser, "", []string{"./src/main.go", "src/main.go"})
+	if err != nil {
+		t.Fatalf("SendPromptWithFiles error: %v", err)
+	}
+	if !reflect.DeepEqual(checked, []string{"src/main.go"}) {
+		t.Errorf("checker got %q, want normalized paths", checked)
+	}
+
+	pending, err := mgr.GetHistoryManager().GetPendingTurns(session.ConversationID)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected 1 pending turn, got %d (err %v)", len(pending), err)
+	}
+	if pending[0].UserPrompt != "review" {
+		t.Errorf("UserPrompt should be stored without file references, got %q", pending[0].UserPrompt)
+	}
+	if !reflect.DeepEqual([]string(pending[0].AttachedFiles), []string{"src/main.go"}) {
+		t.Errorf("AttachedFiles = %q, want [src/main.go]", pending[0].AttachedFiles)
+	}
+}
+
+func TestHeadlessManager_SendPromptWithFilesRejectsBadFiles(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	mgr.execWorkDir = func(ctx context.Context, dockerID, workDir string, cmd []string) (string, error) {
+		if cmd[2] != checkAttachedFilesScript {
+			return "", nil
+		}
+		if files := cmd[4:]; strings.Contains(files[0], "missing") {
+			return "missing:" + files[0] + "\n", nil
+		}
+		t.Fatalf("checker should not run for invalid paths, got %q", cmd)
+		return "", nil
+	}
+
+	session, err := mgr.CreateSession(10, "docker-10", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	session.SetState(HeadlessStateRunning)
+
+	if err := mgr.SendPromptWithFiles(session.ID, "p", "", "", []string{"../escape"}); !errors.Is(err, ErrInvalidFilePath) {
+		t.Errorf("expected ErrInvalidFilePath, got %v", err)
+	}
+	if err := mgr.SendPromptWithFiles(session.ID, "p", "", "", []string{"missing.go"}); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("expected ErrFileNotFound, got %v", err)
+	}
+
+	pending, _ := mgr.GetHistoryManager().GetPendingTurns(session.ConversationID)
+	if len(pending) != 0 {
+		t.Errorf("rejected prompts should not be queued, got %d turns", len(pending))
+	}
+}
@@ -0,0 +1,255 @@
+package headless
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"cc-platform/internal/constants"
+)
+
+// 变更范围
+const (
+	// ChangesScopeConversation 相对于对话开始时的基线提交（包括对话期间的提交和未提交的修改）
+	ChangesScopeConversation = "conversation"
+	// ChangesScopeUncommitted 没有可用的基线，只统计相对于 HEAD 的未提交修改
+	ChangesScopeUncommitted = "uncommitted"
+)
+
+// 文件变更状态
+const (
+	FileChangeAdded     = "added"
+	FileChangeModified  = "modified"
+	FileChangeDeleted   = "deleted"
+	FileChangeUntracked = "untracked"
+)
+
+// 变更脚本输出中的分段标记
+const (
+	changesMarkerNotGit      = "__CC_NOT_GIT__"
+	changesMarkerBaseMissing = "__CC_BASE_MISSING__"
+	changesMarkerBase        = "__CC_BASE__"
+	changesMarkerNameStatus  = "__CC_NAME_STATUS__"
+	changesMarkerNumstat     = "__CC_NUMSTAT__"
+	changesMarkerStatus      = "__CC_STATUS__"
+)
+
+// gitHeadScript 输出工作目录的 HEAD 提交；非 git 目录或空仓库输出为空
+const gitHeadScript = `git rev-parse --verify -q HEAD 2>/dev/null || true`
+
+// conversationChangesScript 统计工作目录相对于基线（$1，为空时使用 HEAD）的变更
+var conversationChangesScript = fmt.Sprintf(`g() { git -c core.quotePath=false "$@"; }
+if ! g rev-parse --is-inside-work-tree >/dev/null 2>&1; then echo %[1]s; exit 0; fi
+base="$1"
+if [ -n "$base" ] && ! g cat-file -e "$base^{commit}" 2>/dev/null; then echo %[2]s; base=""; fi
+[ -n "$base" ] || base=$(g rev-parse --verify -q HEAD 2>/dev/null)
+echo "%[3]s $base"
+if [ -n "$base" ]; then
+  echo %[4]s; g diff --name-status --no-renames "$base"
+  echo %[5]s; g diff --numstat --no-renames "$base"
+fi
+echo %[6]s; g status --porcelain --untracked-files=all --no-renames`,
+	changesMarkerNotGit, changesMarkerBaseMissing, changesMarkerBase,
+	changesMarkerNameStatus, changesMarkerNumstat, changesMarkerStatus)
+
+// ChangedFile 单个文件的变更
+type ChangedFile struct {
+	Path    string `json:"path"`
+	Status  string `json:"status"` // added | modified | deleted | untracked
+	Added   int    `json:"added"`
+	Deleted int    `json:"deleted"`
+	Binary  bool   `json:"binary,omitempty"`
+}
+
+// ConversationChanges 对话期间工作目录的变更汇总
+type ConversationChanges struct {
+	ConversationID  uint          `json:"conversation_id"`
+	WorkDir         string        `json:"work_dir"`
+	IsGitRepo       bool          `json:"is_git_repo"`
+	Scope           string        `json:"scope,omitempty"` // conversation | uncommitted
+	BaselineCommit  string        `json:"baseline_commit,omitempty"`
+	BaselineMissing bool          `json:"baseline_missing,omitempty"` // 记录的基线提交已不存在（例如被 rebase）
+	Files           []ChangedFile `json:"files"`
+	TotalAdded      int           `json:"total_added"`
+	TotalDeleted    int           `json:"total_deleted"`
+}
+
+// recordConversationBaseline 记录对话开始时的工作目录和 git HEAD；非 git 目录只记录工作目录
+func (m *HeadlessManager) recordConversationBaseline(conversationID uint, dockerID, workDir string) {
+	if workDir == "" {
+		workDir = defaultWorkDir
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.HeadlessGitCommandTimeout)
+	defer cancel()
+
+	output, err := m.execWorkDir(ctx, dockerID, workDir, []string{"sh", "-c", gitHeadScript})
+	if err != nil {
+		log.Printf("[HeadlessManager] Failed to read git baseline for conversation %d in %s: %v", conversationID, workDir, err)
+	}
+	baseline := parseGitHead(output)
+
+	if err := m.historyManager.UpdateConversationBaseline(conversationID, workDir, baseline); err != nil {
+		log.Printf("[HeadlessManager] Failed to record baseline for conversation %d: %v", conversationID, err)
+	}
+}
+
+// GetConversationChanges 统计对话工作目录中的文件变更。
+// 对话开始时记录了基线提交时，统计相对于基线的全部变更；否则只统计未提交的修改。
+func (m *HeadlessManager) GetConversationChanges(ctx context.Context, dockerID string, conversationID uint) (*ConversationChanges, error) {
+	conversation, err := m.historyManager.GetConversationByID(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation == nil {
+		return nil, ErrConversationNotFound
+	}
+
+	workDir := conversation.WorkDir
+	if workDir == "" {
+		workDir = defaultWorkDir
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, constants.HeadlessGitCommandTimeout)
+	defer cancel()
+
+	output, err := m.execWorkDir(ctx, dockerID, workDir, []string{"sh", "-c", conversationChangesScript, "sh", conversation.BaselineCommit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect changes: %w", err)
+	}
+
+	changes := parseConversationChanges(output, conversation.BaselineCommit)
+	changes.ConversationID = conversationID
+	changes.WorkDir = workDir
+	return changes, nil
+}
+
+// parseGitHead 校验 git rev-parse 输出的提交哈希
+func parseGitHead(output string) string {
+	commit := strings.TrimSpace(output)
+	if len(commit) != 40 && len(commit) != 64 {
+		return ""
+	}
+	if _, err := strconv.ParseUint(commit[:16], 16, 64); err != nil {
+		return ""
+	}
+	return commit
+}
+
+// parseConversationChanges 解析变更脚本的输出
+func parseConversationChanges(output, baseline string) *ConversationChanges {
+	changes := &ConversationChanges{Files: []ChangedFile{}}
+
+	files := make(map[string]*ChangedFile)
+	var order []string
+	fileFor := func(path, status string) *ChangedFile {
+		if f, ok := files[path]; ok {
+			return f
+		}
+		files[path] = &ChangedFile{Path: path, Status: status}
+		order = append(order, path)
+		return files[path]
+	}
+
+	section := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case line == changesMarkerNotGit:
+			return changes
+		case line == changesMarkerBaseMissing:
+			changes.BaselineMissing = true
+			continue
+		case strings.HasPrefix(line, changesMarkerBase):
+			changes.IsGitRepo = true
+			base := strings.TrimSpace(strings.TrimPrefix(line, changesMarkerBase))
+			if baseline != "" && base == baseline {
+				changes.Scope = ChangesScopeConversation
+				changes.BaselineCommit = baseline
+			} else {
+				changes.Scope = ChangesScopeUncommitted
+			}
+			continue
+		case line == changesMarkerNameStatus, line == changesMarkerNumstat, line == changesMarkerStatus:
+			section = line
+			continue
+		case line == "":
+			continue
+		}
+
+		switch section {
+		case changesMarkerNameStatus:
+			// <status>\t<path>
+			parts := strings.SplitN(line, "\t", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			fileFor(unquoteGitPath(parts[1]), fileChangeStatus(parts[0][0]))
+		case changesMarkerNumstat:
+			// <added>\t<deleted>\t<path>，二进制文件的行数为 "-"
+			parts := strings.SplitN(line, "\t", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			f := fileFor(unquoteGitPath(parts[2]), FileChangeModified)
+			if parts[0] == "-" || parts[1] == "-" {
+				f.Binary = true
+				continue
+			}
+			f.Added, _ = strconv.Atoi(parts[0])
+			f.Deleted, _ = strconv.Atoi(parts[1])
+		case changesMarkerStatus:
+			// XY <path>；与基线比较时已统计的文件只补充未跟踪文件
+			if len(line) < 4 {
+				continue
+			}
+			code, path := line[:2], unquoteGitPath(line[3:])
+			if code == "??" {
+				fileFor(path, FileChangeUntracked)
+				continue
+			}
+			if _, ok := files[path]; ok {
+				continue
+			}
+			status := code[0]
+			if status == ' ' {
+				status = code[1]
+			}
+			fileFor(path, fileChangeStatus(status))
+		}
+	}
+
+	for _, path := range order {
+		f := files[path]
+		changes.TotalAdded += f.Added
+		changes.TotalDeleted += f.Deleted
+		changes.Files = append(changes.Files, *f)
+	}
+	return changes
+}
+
+// fileChangeStatus 将 git 状态字母转换为变更状态
+func fileChangeStatus(code byte) string {
+	switch code {
+	case 'A':
+		return FileChangeAdded
+	case 'D':
+		return FileChangeDeleted
+	case '?':
+		return FileChangeUntracked
+	default:
+		return FileChangeModified
+	}
+}
+
+// unquoteGitPath 去掉 git 为特殊字符路径添加的引号
+func unquoteGitPath(path string) string {
+	if len(path) >= 2 && strings.HasPrefix(path, `"`) && strings.HasSuffix(path, `"`) {
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			return unquoted
+		}
+	}
+	return path
+}
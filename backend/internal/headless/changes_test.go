@@ -0,0 +1,183 @@
+package headless
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testBaseline = "0123456789abcdef0123456789abcdef01234567"
+
+func TestParseGitHead(t *testing.T) {
+	if got := parseGitHead(testBaseline + "\n"); got != testBaseline {
+		t.Errorf("parseGitHead() = %q, want %q", got, testBaseline)
+	}
+	for _, output := range []string{"", "fatal: not a git repository", "HEAD\n"} {
+		if got := parseGitHead(output); got != "" {
+			t.Errorf("parseGitHead(%q) = %q, want empty", output, got)
+		}
+	}
+}
+
+func TestParseConversationChanges_SinceBaseline(t *testing.T) {
+	output := strings.Join([]string{
+		changesMarkerBase + " " + testBaseline,
+		changesMarkerNameStatus,
+		"M\tsrc/main.go",
+		"A\tsrc/new.go",
+		"D\told.txt",
+		"M\tlogo.png",
+		changesMarkerNumstat,
+		"10\t2\tsrc/main.go",
+		"30\t0\tsrc/new.go",
+		"0\t5\told.txt",
+		"-\t-\tlogo.png",
+		changesMarkerStatus,
+		" M src/main.go",
+		"?? notes/todo.md",
+		`?? "docs/caf\303\251.md"`,
+		"",
+	}, "\n")
+
+	changes := parseConversationChanges(output, testBaseline)
+	if !changes.IsGitRepo || changes.Scope != ChangesScopeConversation || changes.BaselineCommit != testBaseline {
+		t.Fatalf("unexpected summary %+v", changes)
+	}
+
+	want := []ChangedFile{
+		{Path: "src/main.go", Status: FileChangeModified, Added: 10, Deleted: 2},
+		{Path: "src/new.go", Status: FileChangeAdded, Added: 30},
+		{Path: "old.txt", Status: FileChangeDeleted, Deleted: 5},
+		{Path: "logo.png", Status: FileChangeModified, Binary: true},
+		{Path: "notes/todo.md", Status: FileChangeUntracked},
+		{Path: "docs/café.md", Status: FileChangeUntracked},
+	}
+	if !reflect.DeepEqual(changes.Files, want) {
+		t.Errorf("Files = %+v\nwant %+v", changes.Files, want)
+	}
+	if changes.TotalAdded != 40 || changes.TotalDeleted != 7 {
+		t.Errorf("totals = +%d -%d, want +40 -7", changes.TotalAdded, changes.TotalDeleted)
+	}
+}
+
+func TestParseConversationChanges_WithoutBaseline(t *testing.T) {
+	output := strings.Join([]string{
+		changesMarkerBaseMissing,
+		changesMarkerBase + " fedcba9876543210fedcba9876543210fedcba98",
+		changesMarkerNameStatus,
+		"M\tREADME.md",
+		changesMarkerNumstat,
+		"1\t1\tREADME.md",
+		changesMarkerStatus,
+		"M  README.md",
+	}, "\n")
+
+	changes := parseConversationChanges(output, testBaseline)
+	if changes.Scope != ChangesScopeUncommitted || changes.BaselineCommit != "" || !changes.BaselineMissing {
+		t.Errorf("unexpected summary %+v", changes)
+	}
+	if len(changes.Files) != 1 || changes.Files[0].Added != 1 {
+		t.Errorf("unexpected files %+v", changes.Files)
+	}
+}
+
+func TestParseConversationChanges_EmptyRepository(t *testing.T) {
+	// 没有任何提交的仓库只有 status 输出
+	output := strings.Join([]string{
+		changesMarkerBase + " ",
+		changesMarkerStatus,
+		"A  main.go",
+		"?? go.mod",
+	}, "\n")
+
+	changes := parseConversationChanges(output, "")
+	want := []ChangedFile{
+		{Path: "main.go", Status: FileChangeAdded},
+		{Path: "go.mod", Status: FileChangeUntracked},
+	}
+	if !changes.IsGitRepo || !reflect.DeepEqual(changes.Files, want) {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+}
+
+func TestParseConversationChanges_NotGitRepo(t *testing.T) {
+	changes := parseConversationChanges(changesMarkerNotGit+"\n", "")
+	if changes.IsGitRepo || changes.Scope != "" {
+		t.Errorf("unexpected summary %+v", changes)
+	}
+	if changes.Files == nil || len(changes.Files) != 0 {
+		t.Errorf("Files should be an empty list, got %#v", changes.Files)
+	}
+}
+
+func TestHeadlessManager_ConversationChangesUseRecordedBaseline(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	var changesCmd []string
+	var changesWorkDir string
+	mgr.execWorkDir = func(ctx context.Context, dockerID, workDir string, cmd []string) (string, error) {
+		switch cmd[2] {
+		case gitHeadScript:
+			return testBaseline + "\n", nil
+		case conversationChangesScript:
+			changesCmd, changesWorkDir = cmd, workDir
+			return changesMarkerBase + " " + testBaseline + "\n" + changesMarkerStatus + "\n?? new.txt\n", nil
+		}
+		return "", nil
+	}
+
+	session, err := mgr.CreateSession(31, "docker-31", "/workspace/project")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+
+	conv, _ := mgr.GetHistoryManager().GetConversationByID(session.ConversationID)
+	if conv.BaselineCommit != testBaseline || conv.WorkDir != "/workspace/project" {
+		t.Fatalf("baseline not recorded: commit=%q workdir=%q", conv.BaselineCommit, conv.WorkDir)
+	}
+
+	changes, err := mgr.GetConversationChanges(context.Background(), "docker-31", session.ConversationID)
+	if err != nil {
+		t.Fatalf("GetConversationChanges error: %v", err)
+	}
+	if changesWorkDir != "/workspace/project" || changesCmd[len(changesCmd)-1] != testBaseline {
+		t.Errorf("changes should run in the conversation workdir against the baseline, got %q in %s", changesCmd, changesWorkDir)
+	}
+	if changes.Scope != ChangesScopeConversation || len(changes.Files) != 1 || changes.Files[0].Path != "new.txt" {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+}
+
+func TestHeadlessManager_ConversationChangesNonGitWorkDir(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	mgr.execWorkDir = func(ctx context.Context, dockerID, workDir string, cmd []string) (string, error) {
+		if cmd[2] == conversationChangesScript {
+			return changesMarkerNotGit + "\n", nil
+		}
+		return "", nil
+	}
+
+	session, err := mgr.CreateSession(32, "docker-32", "")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+
+	changes, err := mgr.GetConversationChanges(context.Background(), "docker-32", session.ConversationID)
+	if err != nil {
+		t.Fatalf("GetConversationChanges error: %v", err)
+	}
+	if changes.IsGitRepo || changes.WorkDir != defaultWorkDir {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+
+	if _, err := mgr.GetConversationChanges(context.Background(), "docker-32", 999999); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("expected ErrConversationNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package headless
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cc-platform/internal/constants"
+)
+
+// ErrInvalidCLIFlag 额外 CLI 参数不在白名单中或值无效
+var ErrInvalidCLIFlag = errors.New("invalid claude CLI flag")
+
+// cliFlagSpec 白名单中一个参数的取值校验
+type cliFlagSpec struct {
+	validate func(value string) error
+}
+
+var (
+	// cliToolListPattern 工具列表，如 "Read,Grep" 或 "Bash(git log:*) Edit"
+	cliToolListPattern = regexp.MustCompile(`^[A-Za-z0-9_*:.,()/@ -]+$`)
+	// cliModelPattern 模型名称，如 claude-sonnet-4-20250514
+	cliModelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+// allowedCLIFlags 可透传给 Claude CLI 的参数白名单，所有参数都需要一个值。
+//
+// 只收录只影响本轮行为范围的参数：
+//   - --max-turns：限制单次执行的 agent 轮数
+//   - --allowedTools / --disallowedTools（及连字符写法）：收窄或放开工具
+//   - --add-dir：额外允许访问的容器内目录
+//   - --fallback-model：主模型过载时使用的模型
+//
+// 未列出的参数一律拒绝。平台自己设置的参数（--model、--resume、--output-format、--verbose、
+// -p、--append-system-prompt、--dangerously-skip-permissions）不能被覆盖；--mcp-config、
+// --settings、--permission-mode 等可以加载任意程序或改变权限模型的参数也不允许透传。
+var allowedCLIFlags = map[string]cliFlagSpec{
+	"--max-turns":        {validate: validateCLIMaxTurns},
+	"--allowedTools":     {validate: validateCLIToolList},
+	"--allowed-tools":    {validate: validateCLIToolList},
+	"--disallowedTools":  {validate: validateCLIToolList},
+	"--disallowed-tools": {validate: validateCLIToolList},
+	"--add-dir":          {validate: validateCLIDir},
+	"--fallback-model":   {validate: validateCLIModel},
+}
+
+func validateCLIMaxTurns(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > 1000 {
+		return fmt.Errorf("must be an integer between 1 and 1000")
+	}
+	return nil
+}
+
+func validateCLIToolList(value string) error {
+	if !cliToolListPattern.MatchString(value) {
+		return fmt.Errorf("must be a list of tool names")
+	}
+	return nil
+}
+
+func validateCLIDir(value string) error {
+	if !strings.HasPrefix(value, "/") {
+		return fmt.Errorf("must be an absolute path inside the container")
+	}
+	for _, r := range value {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("must not contain control characters")
+		}
+	}
+	return nil
+}
+
+func validateCLIModel(value string) error {
+	if !cliModelPattern.MatchString(value) {
+		return fmt.Errorf("must be a model name")
+	}
+	return nil
+}
+
+// NormalizeCLIArgs 校验额外 CLI 参数并规范化为 "--flag value" 成对的列表。
+// 支持 "--flag value" 和 "--flag=value" 两种写法；值不能以 "-" 开头，避免吞掉后续参数。
+func NormalizeCLIArgs(args []string) ([]string, error) {
+	var normalized []string
+	flags := 0
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimSpace(args[i])
+		if arg == "" {
+			continue
+		}
+		flag, value, hasValue := strings.Cut(arg, "=")
+		spec, ok := allowedCLIFlags[flag]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not allowed", ErrInvalidCLIFlag, flag)
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%w: %s requires a value", ErrInvalidCLIFlag, flag)
+			}
+			i++
+			value = args[i]
+		}
+		value = strings.TrimSpace(value)
+		if value == "" || strings.HasPrefix(value, "-") {
+			return nil, fmt.Errorf("%w: %s requires a value", ErrInvalidCLIFlag, flag)
+		}
+		if len(value) > constants.HeadlessExtraCLIValueMaxLength {
+			return nil, fmt.Errorf("%w: %s value is longer than %d characters", ErrInvalidCLIFlag, flag, constants.HeadlessExtraCLIValueMaxLength)
+		}
+		if err := spec.validate(value); err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidCLIFlag, flag, err)
+		}
+		flags++
+		if flags > constants.HeadlessMaxExtraCLIFlags {
+			return nil, fmt.Errorf("%w: at most %d flags", ErrInvalidCLIFlag, constants.HeadlessMaxExtraCLIFlags)
+		}
+		normalized = append(normalized, flag, value)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,104 @@
+package headless
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCLIArgs_Allowed(t *testing.T) {
+	got, err := NormalizeCLIArgs([]string{
+		"--max-turns", "5",
+		"--allowedTools=Read,Grep",
+		"--disallowed-tools", "Bash(rm:*)",
+		"--add-dir=/data/shared",
+		"--fallback-model", "claude-sonnet-4-20250514",
+	})
+	if err != nil {
+		t.Fatalf("NormalizeCLIArgs error: %v", err)
+	}
+	want := []string{
+		"--max-turns", "5",
+		"--allowedTools", "Read,Grep",
+		"--disallowed-tools", "Bash(rm:*)",
+		"--add-dir", "/data/shared",
+		"--fallback-model", "claude-sonnet-4-20250514",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("NormalizeCLIArgs() = %q, want %q", got, want)
+	}
+
+	if got, err := NormalizeCLIArgs(nil); err != nil || len(got) != 0 {
+		t.Fatalf("empty args should normalize to nothing, got %q, %v", got, err)
+	}
+}
+
+func TestNormalizeCLIArgs_Rejected(t *testing.T) {
+	tooMany := make([]string, 0, 42)
+	for i := 0; i < 21; i++ {
+		tooMany = append(tooMany, "--max-turns", "1")
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"not allowlisted", []string{"--mcp-config", "/tmp/mcp.json"}},
+		{"platform flag", []string{"--model", "claude-opus-4"}},
+		{"permission mode", []string{"--permission-mode=default"}},
+		{"missing value", []string{"--max-turns"}},
+		{"value looks like a flag", []string{"--add-dir", "--mcp-config"}},
+		{"max turns out of range", []string{"--max-turns", "0"}},
+		{"max turns not a number", []string{"--max-turns=lots"}},
+		{"relative dir", []string{"--add-dir", "data"}},
+		{"bad tool list", []string{"--allowedTools", "Read;rm -rf /"}},
+		{"value too long", []string{"--fallback-model", strings.Repeat("a", 2000)}},
+		{"too many flags", tooMany},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NormalizeCLIArgs(tt.args); !errors.Is(err, ErrInvalidCLIFlag) {
+				t.Fatalf("expected ErrInvalidCLIFlag, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHeadlessManager_ExtraArgsPersistAcrossSessions(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, err := mgr.CreateSession(9, "docker-9", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	conversationID := session.ConversationID
+
+	if err := mgr.SetConversationExtraArgs(conversationID, []string{"--bad-flag", "x"}); !errors.Is(err, ErrInvalidCLIFlag) {
+		t.Fatalf("expected ErrInvalidCLIFlag, got %v", err)
+	}
+	if err := mgr.SetConversationExtraArgs(conversationID, []string{"--max-turns=3"}); err != nil {
+		t.Fatalf("SetConversationExtraArgs error: %v", err)
+	}
+	want := []string{"--max-turns", "3"}
+	if !reflect.DeepEqual(session.GetExtraArgs(), want) {
+		t.Errorf("running session should pick up the new args, got %q", session.GetExtraArgs())
+	}
+
+	if err := mgr.CloseSessionByConversationID(conversationID); err != nil {
+		t.Fatalf("CloseSessionByConversationID error: %v", err)
+	}
+	resumed, err := mgr.CreateSessionForConversation(9, "docker-9", "/app", conversationID)
+	if err != nil {
+		t.Fatalf("CreateSessionForConversation error: %v", err)
+	}
+	if !reflect.DeepEqual(resumed.GetExtraArgs(), want) {
+		t.Errorf("resumed session should restore the args, got %q", resumed.GetExtraArgs())
+	}
+
+	if err := mgr.SetConversationExtraArgs(999999, nil); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("expected ErrConversationNotFound, got %v", err)
+	}
+}
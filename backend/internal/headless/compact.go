@@ -0,0 +1,46 @@
+package headless
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cc-platform/internal/models"
+)
+
+// CompactPrompt Claude Code 的上下文压缩命令：用摘要替换之前的上下文
+const CompactPrompt = "/compact"
+
+var (
+	// ErrCompactionUnsupported 当前模型不支持上下文压缩
+	ErrCompactionUnsupported = errors.New("context compaction is not supported by the current model")
+	// ErrNothingToCompact 会话还没有可压缩的 Claude 上下文（尚无轮次或刚重置过）
+	ErrNothingToCompact = errors.New("no claude context to compact")
+)
+
+// CompactionSupported 判断模型是否支持 /compact。未指定模型时使用 Claude Code 的默认 Claude 模型。
+func CompactionSupported(model string) bool {
+	model = strings.ToLower(strings.TrimSpace(model))
+	switch model {
+	case "", "default", "sonnet", "opus", "haiku", "opusplan":
+		return true
+	}
+	return strings.HasPrefix(model, "claude-")
+}
+
+// Compact 压缩会话的 Claude 上下文：以 compact 来源提交 /compact 轮次，
+// 会话忙时与普通消息一样排队。与 ResetContext 不同，压缩后的上下文保留了之前对话的摘要。
+func (m *HeadlessManager) Compact(sessionID string) error {
+	session, ok := m.GetSession(sessionID)
+	if !ok {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	if !CompactionSupported(session.Model) {
+		return fmt.Errorf("%w: %s", ErrCompactionUnsupported, session.Model)
+	}
+	if session.ClaudeSessionID == "" {
+		return ErrNothingToCompact
+	}
+
+	return m.SendPromptWithFiles(sessionID, CompactPrompt, models.HeadlessPromptSourceCompact, "", nil)
+}
@@ -0,0 +1,89 @@
+package headless
+
+import "time"
+
+// DefaultDisconnectGrace 最后一个客户端断开后等待重连的默认时间，超时后才取消执行中的轮次
+const DefaultDisconnectGrace = 30 * time.Second
+
+// disconnectCancelMessage 因客户端全部断开而取消的轮次记录的错误信息
+const disconnectCancelMessage = "Execution cancelled: all clients disconnected"
+
+// SetDisconnectCancel 设置客户端全部断开时是否取消执行中的轮次（对话未单独设置时的默认值）
+// 以及等待重连的宽限期，grace <= 0 时使用 DefaultDisconnectGrace。只影响之后创建的会话。
+func (m *HeadlessManager) SetDisconnectCancel(enabled bool, grace time.Duration) {
+	if grace <= 0 {
+		grace = DefaultDisconnectGrace
+	}
+	m.cancelOnDisconnect = enabled
+	m.disconnectGrace = grace
+}
+
+// SetConversationCancelOnDisconnect 设置对话在客户端全部断开时是否取消执行中的轮次并持久化
+func (m *HeadlessManager) SetConversationCancelOnDisconnect(conversationID uint, enabled bool) error {
+	return m.historyManager.UpdateConversationCancelOnDisconnect(conversationID, enabled)
+}
+
+// attachDisconnectCancel 让会话在客户端全部断开时按对话设置（未设置时按平台默认值）决定是否取消执行中的轮次
+func (m *HeadlessManager) attachDisconnectCancel(session *HeadlessSession) {
+	session.disconnectGrace = m.disconnectGrace
+	if session.disconnectGrace <= 0 {
+		session.disconnectGrace = DefaultDisconnectGrace
+	}
+	defaultEnabled := m.cancelOnDisconnect
+	session.cancelOnDisconnect = func() bool {
+		conversation, err := m.historyManager.GetConversationByID(session.ConversationID)
+		if err != nil || conversation == nil || conversation.CancelOnDisconnect == nil {
+			return defaultEnabled
+		}
+		return *conversation.CancelOnDisconnect
+	}
+}
+
+// scheduleDisconnectCancelLocked 在最后一个客户端断开时调用：会话正在执行时，宽限期后仍无客户端
+// 重连则取消断开时的轮次。调用方需持有 clientsMu。
+func (s *HeadlessSession) scheduleDisconnectCancelLocked() {
+	if s.cancelOnDisconnect == nil || s.GetState() != HeadlessStateRunning {
+		return
+	}
+	s.stopDisconnectCancelLocked()
+
+	turnID := s.GetCurrentTurnID()
+	s.disconnectGen++
+	gen := s.disconnectGen
+	s.disconnectTimer = time.AfterFunc(s.disconnectGrace, func() {
+		s.cancelAfterDisconnect(gen, turnID)
+	})
+	s.logger().Printf("All clients disconnected during turn %d, cancelling in %s unless one reconnects", turnID, s.disconnectGrace)
+}
+
+// stopDisconnectCancelLocked 取消等待中的断开取消（客户端重连），调用方需持有 clientsMu
+func (s *HeadlessSession) stopDisconnectCancelLocked() {
+	if s.disconnectTimer != nil {
+		s.disconnectTimer.Stop()
+		s.disconnectTimer = nil
+	}
+}
+
+// cancelAfterDisconnect 宽限期结束：没有客户端重连且对话允许时取消断开时的轮次。
+// 轮次已结束或已开始下一轮时 cancelTurn 不做任何操作。
+func (s *HeadlessSession) cancelAfterDisconnect(gen uint64, turnID uint) {
+	s.clientsMu.Lock()
+	if s.disconnectTimer == nil || s.disconnectGen != gen || len(s.clients) > 0 {
+		s.clientsMu.Unlock()
+		return
+	}
+	s.disconnectTimer = nil
+	s.clientsMu.Unlock()
+
+	if !s.cancelOnDisconnect() {
+		return
+	}
+	result, err := s.cancelTurn(turnID, disconnectCancelMessage)
+	if err != nil {
+		s.logger().Printf("Failed to cancel turn %d after clients disconnected: %v", turnID, err)
+		return
+	}
+	if result.Cancelled {
+		s.logger().Printf("Cancelled turn %d: no client reconnected within %s", turnID, s.disconnectGrace)
+	}
+}
@@ -0,0 +1,120 @@
+package headless
+
+import (
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+const testDisconnectGrace = 20 * time.Millisecond
+
+// waitForTurnState 等待轮次进入指定状态
+func waitForTurnState(t *testing.T, mgr *HeadlessManager, turnID uint, state string) *models.HeadlessTurn {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		turn, err := mgr.GetHistoryManager().GetTurnByID(turnID)
+		if err != nil {
+			t.Fatalf("GetTurnByID error: %v", err)
+		}
+		if turn.State == state {
+			return turn
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("turn state = %q, want %q", turn.State, state)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// assertStillRunning 等待宽限期过去后确认轮次仍在执行
+func assertStillRunning(t *testing.T, mgr *HeadlessManager, session *HeadlessSession, turnID uint) {
+	t.Helper()
+	time.Sleep(5 * testDisconnectGrace)
+	if session.GetState() != HeadlessStateRunning {
+		t.Errorf("session state = %q, want running", session.GetState())
+	}
+	if turn, _ := mgr.GetHistoryManager().GetTurnByID(turnID); turn.State != models.HeadlessTurnStateRunning {
+		t.Errorf("turn state = %q, want running", turn.State)
+	}
+}
+
+func TestDisconnectCancel_NoReconnectCancelsTurn(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+	mgr.SetDisconnectCancel(true, testDisconnectGrace)
+
+	session, turn := startRunningTurn(t, mgr, 70)
+	session.AddClient("client-1")
+	session.AddClient("client-2")
+
+	// 还有客户端在线时不取消
+	session.RemoveClient("client-1")
+	assertStillRunning(t, mgr, session, turn.ID)
+
+	session.RemoveClient("client-2")
+	stored := waitForTurnState(t, mgr, turn.ID, models.HeadlessTurnStateError)
+	if stored.ErrorMessage != disconnectCancelMessage {
+		t.Errorf("error message = %q, want %q", stored.ErrorMessage, disconnectCancelMessage)
+	}
+	if session.GetState() == HeadlessStateRunning {
+		t.Error("session should no longer be running")
+	}
+}
+
+func TestDisconnectCancel_ReconnectWithinGraceKeepsTurn(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+	mgr.SetDisconnectCancel(true, testDisconnectGrace)
+
+	session, turn := startRunningTurn(t, mgr, 71)
+	session.AddClient("client-1")
+	session.RemoveClient("client-1")
+	session.AddClient("client-2")
+
+	assertStillRunning(t, mgr, session, turn.ID)
+}
+
+func TestDisconnectCancel_ConversationSettingOverridesDefault(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	// 平台默认开启，对话关闭：保持原有行为
+	mgr.SetDisconnectCancel(true, testDisconnectGrace)
+	session, turn := startRunningTurn(t, mgr, 72)
+	if err := mgr.SetConversationCancelOnDisconnect(session.ConversationID, false); err != nil {
+		t.Fatalf("SetConversationCancelOnDisconnect error: %v", err)
+	}
+	if session.GetSessionInfo().CancelOnDisconnect {
+		t.Error("session info should report the conversation setting")
+	}
+	session.AddClient("client-1")
+	session.RemoveClient("client-1")
+	assertStillRunning(t, mgr, session, turn.ID)
+
+	// 平台默认关闭，对话开启
+	mgr.SetDisconnectCancel(false, testDisconnectGrace)
+	session, turn = startRunningTurn(t, mgr, 73)
+	if err := mgr.SetConversationCancelOnDisconnect(session.ConversationID, true); err != nil {
+		t.Fatalf("SetConversationCancelOnDisconnect error: %v", err)
+	}
+	session.AddClient("client-1")
+	session.RemoveClient("client-1")
+	waitForTurnState(t, mgr, turn.ID, models.HeadlessTurnStateError)
+}
+
+func TestDisconnectCancel_OffByDefault(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+	mgr.disconnectGrace = testDisconnectGrace
+
+	session, turn := startRunningTurn(t, mgr, 74)
+	session.AddClient("client-1")
+	session.RemoveClient("client-1")
+	assertStillRunning(t, mgr, session, turn.ID)
+}
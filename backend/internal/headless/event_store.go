@@ -0,0 +1,107 @@
+package headless
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+const (
+	// DefaultMaxTurnEvents 每轮默认最多记录的原始事件数
+	DefaultMaxTurnEvents = 5000
+	// DefaultEventRetention 已结束轮次的原始事件默认保留时长
+	DefaultEventRetention = 30 * 24 * time.Hour
+	// MaxTurnEventsPageSize 回放单页最多返回的事件数
+	MaxTurnEventsPageSize = 1000
+
+	// eventPruneBatchSize 每批清理事件的轮次数
+	eventPruneBatchSize = 200
+)
+
+// SetEventStore 设置原始事件的留存策略：maxTurnEvents 为每轮最多记录的事件数（0 表示不限制），
+// retention 为已结束轮次的事件保留时长（0 表示永久保留），过期事件在定期清理时删除
+func (m *HeadlessManager) SetEventStore(maxTurnEvents int, retention time.Duration) {
+	if maxTurnEvents < 0 {
+		maxTurnEvents = DefaultMaxTurnEvents
+	}
+	if retention < 0 {
+		retention = 0
+	}
+	m.historyManager.SetMaxTurnEvents(maxTurnEvents)
+	m.eventRetention = retention
+}
+
+// pruneExpiredEvents 删除超过保留期的已结束轮次的原始事件
+func (m *HeadlessManager) pruneExpiredEvents() {
+	if m.eventRetention <= 0 {
+		return
+	}
+	pruned, err := m.historyManager.PruneEvents(time.Now().Add(-m.eventRetention))
+	if err != nil {
+		log.Printf("[HeadlessManager] Failed to prune headless events: %v", err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("[HeadlessManager] Pruned events of %d headless turns", pruned)
+	}
+}
+
+// SetMaxTurnEvents 设置每轮最多记录的原始事件数，0 表示不限制
+func (m *HeadlessHistoryManager) SetMaxTurnEvents(limit int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxTurnEvents = limit
+}
+
+// GetTurnEventsPage 按 event_index 升序分页读取轮次的原始事件（用于回放）：
+// 返回 event_index 大于 afterIndex 的至多 limit 个事件及之后是否还有更多
+func (m *HeadlessHistoryManager) GetTurnEventsPage(turnID uint, afterIndex, limit int) ([]models.HeadlessEvent, bool, error) {
+	var events []models.HeadlessEvent
+
+	if err := m.db.Where("turn_id = ? AND event_index > ?", turnID, afterIndex).
+		Order("event_index ASC").
+		Limit(limit + 1).
+		Find(&events).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to get turn events: %w", err)
+	}
+
+	hasMore := len(events) > limit
+	if hasMore {
+		events = events[:limit]
+	}
+	return events, hasMore, nil
+}
+
+// PruneEvents 彻底删除在 before 之前结束的轮次的原始事件，并标记这些轮次的事件已清理；
+// 轮次本身和聚合响应保留。返回被清理的轮次数
+func (m *HeadlessHistoryManager) PruneEvents(before time.Time) (int64, error) {
+	var total int64
+	for {
+		var turnIDs []uint
+		if err := m.db.Model(&models.HeadlessTurn{}).
+			Where("completed_at IS NOT NULL AND completed_at < ? AND events_pruned = ?", before, false).
+			Order("id").
+			Limit(eventPruneBatchSize).
+			Pluck("id", &turnIDs).Error; err != nil {
+			return total, fmt.Errorf("failed to find expired turns: %w", err)
+		}
+		if len(turnIDs) == 0 {
+			return total, nil
+		}
+
+		if err := m.db.Unscoped().Where("turn_id IN ?", turnIDs).Delete(&models.HeadlessEvent{}).Error; err != nil {
+			return total, fmt.Errorf("failed to prune events: %w", err)
+		}
+		if err := m.db.Model(&models.HeadlessTurn{}).Where("id IN ?", turnIDs).
+			Update("events_pruned", true).Error; err != nil {
+			return total, fmt.Errorf("failed to mark pruned turns: %w", err)
+		}
+		total += int64(len(turnIDs))
+
+		if len(turnIDs) < eventPruneBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package headless
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func TestHistoryManager_EventStoreReplay(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := NewHeadlessHistoryManager(db)
+
+	conv, _ := mgr.CreateConversation("session-event-store", 9101)
+	turn, err := mgr.StartTurn(conv.ID, "write the report", "user", nil)
+	if err != nil {
+		t.Fatalf("StartTurn error: %v", err)
+	}
+
+	large := fmt.Sprintf(`{"type":"user","message":{"content":[{"type":"tool_result","content":%q}]}}`, strings.Repeat("line of tool output\n", 500))
+	raws := []string{
+		`{"type":"system","subtype":"init"}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Write","input":{"file_path":"report.md"}}]}}`,
+		large,
+		`{"type":"result","subtype":"success"}`,
+	}
+	types := []string{"system", "assistant", "user", "result"}
+	for i, raw := range raws {
+		if err := mgr.AppendEvent(turn.ID, types[i], "", raw, uint64(i+1)); err != nil {
+			t.Fatalf("AppendEvent error: %v", err)
+		}
+	}
+	if err := mgr.CompleteTurn(turn.ID, "done", "claude-sonnet-4", 10, 20, 0, "", 1000); err != nil {
+		t.Fatalf("CompleteTurn error: %v", err)
+	}
+
+	// The large event is stored compressed; skipping hooks reads it as stored
+	var stored models.HeadlessEvent
+	db.Session(&gorm.Session{SkipHooks: true}).Where("turn_id = ? AND event_index = 2", turn.ID).First(&stored)
+	if stored.Encoding != models.HeadlessEventEncodingGzip || len(stored.RawJSON) >= len(large) {
+		t.Errorf("Expected the large event to be compressed, got encoding %q and %d bytes", stored.Encoding, len(stored.RawJSON))
+	}
+
+	// Replaying the finished turn in pages returns every event unchanged
+	var replayed []models.HeadlessEvent
+	after := -1
+	for {
+		page, hasMore, err := mgr.GetTurnEventsPage(turn.ID, after, 3)
+		if err != nil {
+			t.Fatalf("GetTurnEventsPage error: %v", err)
+		}
+		replayed = append(replayed, page...)
+		if !hasMore {
+			break
+		}
+		after = page[len(page)-1].EventIndex
+	}
+	if len(replayed) != len(raws) {
+		t.Fatalf("Expected %d events, got %d", len(raws), len(replayed))
+	}
+	for i, event := range replayed {
+		if event.EventIndex != i || event.RawJSON != raws[i] || event.EventType != types[i] || event.Seq != uint64(i+1) {
+			t.Errorf("Event %d was not replayed faithfully: index %d type %q seq %d raw %.60q",
+				i, event.EventIndex, event.EventType, event.Seq, event.RawJSON)
+		}
+	}
+
+	// Turn history decodes the events too
+	turns, _, _ := mgr.GetRecentTurns(conv.ID, 1)
+	if len(turns) != 1 || len(turns[0].Events) != len(raws) || turns[0].Events[2].RawJSON != large {
+		t.Errorf("Expected recent turns to carry the decoded events")
+	}
+}
+
+func TestHistoryManager_EventLimitAndPrune(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := NewHeadlessHistoryManager(db)
+	mgr.SetMaxTurnEvents(2)
+
+	conv, _ := mgr.CreateConversation("session-event-limit", 9102)
+	turn, _ := mgr.StartTurn(conv.ID, "long turn", "user", nil)
+	for i := 0; i < 4; i++ {
+		if err := mgr.AppendEvent(turn.ID, "assistant", "", fmt.Sprintf(`{"n":%d}`, i), uint64(i+1)); err != nil {
+			t.Fatalf("AppendEvent error: %v", err)
+		}
+	}
+	events, _ := mgr.GetTurnEvents(turn.ID)
+	got, _ := mgr.GetTurnByID(turn.ID)
+	if len(events) != 2 || !got.EventsTruncated {
+		t.Errorf("Expected 2 stored events and a truncated turn, got %d events (truncated %v)", len(events), got.EventsTruncated)
+	}
+	mgr.CompleteTurn(turn.ID, "done", "", 0, 0, 0, "", 0)
+
+	recent, _ := mgr.StartTurn(conv.ID, "recent turn", "user", nil)
+	mgr.AppendEvent(recent.ID, "assistant", "", `{"n":0}`, 5)
+	mgr.CompleteTurn(recent.ID, "done", "", 0, 0, 0, "", 0)
+	running, _ := mgr.StartTurn(conv.ID, "running turn", "user", nil)
+	mgr.AppendEvent(running.ID, "assistant", "", `{"n":0}`, 6)
+
+	old := time.Now().Add(-48 * time.Hour)
+	db.Model(&models.HeadlessTurn{}).Where("id = ?", turn.ID).Update("completed_at", old)
+
+	pruned, err := mgr.PruneEvents(time.Now().Add(-24 * time.Hour))
+	if err != nil || pruned != 1 {
+		t.Fatalf("Expected 1 pruned turn, got %d (%v)", pruned, err)
+	}
+	var remaining int64
+	db.Unscoped().Model(&models.HeadlessEvent{}).Where("turn_id = ?", turn.ID).Count(&remaining)
+	got, _ = mgr.GetTurnByID(turn.ID)
+	if remaining != 0 || !got.EventsPruned || got.AssistantResponse != "done" {
+		t.Errorf("Expected the old turn's events to be deleted and the turn kept, got %d events (pruned %v)", remaining, got.EventsPruned)
+	}
+	for _, id := range []uint{recent.ID, running.ID} {
+		if events, _ := mgr.GetTurnEvents(id); len(events) != 1 {
+			t.Errorf("Expected turn %d to keep its events, got %d", id, len(events))
+		}
+	}
+
+	if pruned, _ := mgr.PruneEvents(time.Now().Add(-24 * time.Hour)); pruned != 0 {
+		t.Errorf("Expected nothing left to prune, got %d", pruned)
+	}
+}
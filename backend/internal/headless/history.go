@@ -3,6 +3,7 @@ package headless
 import (
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -16,6 +17,8 @@ import (
 type HeadlessHistoryManager struct {
 	db *gorm.DB
 	mu sync.Mutex
+
+	maxTurnEvents int // 每轮最多记录的原始事件数，0 表示不限制
 }
 
 const maxInsertRetries = 3
@@ -33,7 +36,8 @@ func isUniqueConstraintError(err error) bool {
 // NewHeadlessHistoryManager 创建新的历史管理器
 func NewHeadlessHistoryManager(db *gorm.DB) *HeadlessHistoryManager {
 	return &HeadlessHistoryManager{
-		db: db,
+		db:            db,
+		maxTurnEvents: DefaultMaxTurnEvents,
 	}
 }
 
@@ -107,7 +111,7 @@ func (m *HeadlessHistoryManager) UpdateConversationSessionID(conversationID uint
 }
 
 // StartTurn 开始新的轮次
-func (m *HeadlessHistoryManager) StartTurn(conversationID uint, prompt string, source string) (*models.HeadlessTurn, error) {
+func (m *HeadlessHistoryManager) StartTurn(conversationID uint, prompt string, source string, attachedFiles []string) (*models.HeadlessTurn, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -129,6 +133,7 @@ func (m *HeadlessHistoryManager) StartTurn(conversationID uint, prompt string, s
 				TurnIndex:      maxIndex + 1,
 				UserPrompt:     prompt,
 				PromptSource:   source,
+				AttachedFiles:  attachedFiles,
 				State:          models.HeadlessTurnStateRunning,
 			}
 
@@ -158,8 +163,9 @@ func (m *HeadlessHistoryManager) StartTurn(conversationID uint, prompt string, s
 	return nil, fmt.Errorf("failed to create turn after retries: %w", lastErr)
 }
 
-// AppendEvent 追加事件到轮次
-func (m *HeadlessHistoryManager) AppendEvent(turnID uint, eventType, eventSubtype, rawJSON string) error {
+// AppendEvent 追加事件到轮次，seq 为事件在会话内的序号（0 表示未分配）。
+// 较大的事件压缩存储；轮次的事件数达到上限后不再记录，只标记该轮事件不完整。
+func (m *HeadlessHistoryManager) AppendEvent(turnID uint, eventType, eventSubtype, rawJSON string, seq uint64) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -173,12 +179,21 @@ func (m *HeadlessHistoryManager) AppendEvent(turnID uint, eventType, eventSubtyp
 				Select("COALESCE(MAX(event_index), -1)").
 				Scan(&maxIndex)
 
+			if m.maxTurnEvents > 0 && maxIndex+1 >= m.maxTurnEvents {
+				return tx.Model(&models.HeadlessTurn{}).
+					Where("id = ? AND events_truncated = ?", turnID, false).
+					Update("events_truncated", true).Error
+			}
+
+			stored, encoding := models.EncodeHeadlessEventJSON(rawJSON)
 			event := &models.HeadlessEvent{
 				TurnID:       turnID,
 				EventIndex:   maxIndex + 1,
 				EventType:    eventType,
 				EventSubtype: eventSubtype,
-				RawJSON:      rawJSON,
+				RawJSON:      stored,
+				Seq:          seq,
+				Encoding:     encoding,
 			}
 
 			if err := tx.Create(event).Error; err != nil {
@@ -200,7 +215,7 @@ func (m *HeadlessHistoryManager) AppendEvent(turnID uint, eventType, eventSubtyp
 }
 
 // CompleteTurn 完成轮次
-func (m *HeadlessHistoryManager) CompleteTurn(turnID uint, assistantResponse string, model string, inputTokens, outputTokens int, costUSD float64, durationMS int64) error {
+func (m *HeadlessHistoryManager) CompleteTurn(turnID uint, assistantResponse string, model string, inputTokens, outputTokens int, costUSD float64, costSource string, durationMS int64) error {
 	now := time.Now()
 	updates := map[string]interface{}{
 		"state":              models.HeadlessTurnStateCompleted,
@@ -209,6 +224,7 @@ func (m *HeadlessHistoryManager) CompleteTurn(turnID uint, assistantResponse str
 		"input_tokens":       inputTokens,
 		"output_tokens":      outputTokens,
 		"cost_usd":           costUSD,
+		"cost_source":        costSource,
 		"duration_ms":        durationMS,
 		"completed_at":       &now,
 	}
@@ -274,7 +290,7 @@ func (m *HeadlessHistoryManager) FixStaleTurns(conversationID uint) {
 }
 
 // CreatePendingTurn 创建排队中的轮次（state=pending）
-func (m *HeadlessHistoryManager) CreatePendingTurn(conversationID uint, prompt string, source string) (*models.HeadlessTurn, error) {
+func (m *HeadlessHistoryManager) CreatePendingTurn(conversationID uint, prompt string, source string, attachedFiles []string) (*models.HeadlessTurn, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -294,6 +310,7 @@ func (m *HeadlessHistoryManager) CreatePendingTurn(conversationID uint, prompt s
 				TurnIndex:      maxIndex + 1,
 				UserPrompt:     prompt,
 				PromptSource:   source,
+				AttachedFiles:  attachedFiles,
 				State:          models.HeadlessTurnStatePending,
 			}
 
@@ -500,9 +517,41 @@ func (m *HeadlessHistoryManager) GetTurnEvents(turnID uint) ([]models.HeadlessEv
 	return events, nil
 }
 
-// GetCurrentTurnEvents 获取当前轮次已有的事件（用于重连时补发）
-func (m *HeadlessHistoryManager) GetCurrentTurnEvents(turnID uint) ([]models.HeadlessEvent, error) {
-	return m.GetTurnEvents(turnID)
+// GetCurrentTurnEvents 分页获取当前轮次已有的事件（用于重连时补发）：按 event_index 升序返回
+// event_index 大于 afterIndex 且序号大于 afterSeq 的至多 limit 个事件。
+// 从头读取时 afterIndex 传 -1；afterSeq 为 0 时不按序号过滤。
+func (m *HeadlessHistoryManager) GetCurrentTurnEvents(turnID uint, afterSeq uint64, afterIndex, limit int) ([]models.HeadlessEvent, error) {
+	var events []models.HeadlessEvent
+
+	query := m.db.Where("turn_id = ? AND event_index > ?", turnID, afterIndex)
+	if afterSeq > 0 {
+		query = query.Where("seq > ?", afterSeq)
+	}
+	if err := query.Order("event_index ASC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to get current turn events: %w", err)
+	}
+
+	return events, nil
+}
+
+// StreamCurrentTurnEvents 按页读取当前轮次 afterSeq 之后的事件，每页至多 pageSize 个，依次交给 fn，
+// 避免一次性加载事件很多的轮次；fn 返回 false 时停止读取
+func (m *HeadlessHistoryManager) StreamCurrentTurnEvents(turnID uint, afterSeq uint64, pageSize int, fn func([]models.HeadlessEvent) bool) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("invalid page size: %d", pageSize)
+	}
+
+	afterIndex := -1
+	for {
+		events, err := m.GetCurrentTurnEvents(turnID, afterSeq, afterIndex, pageSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 || !fn(events) || len(events) < pageSize {
+			return nil
+		}
+		afterIndex = events[len(events)-1].EventIndex
+	}
 }
 
 // GetTurnByID 根据 ID 获取轮次
@@ -531,6 +580,54 @@ func (m *HeadlessHistoryManager) GetLatestTurn(conversationID uint) (*models.Hea
 	return &turn, nil
 }
 
+// MarkContextReset 在最近一个非排队轮次上记录上下文重置边界，没有轮次时返回 nil
+func (m *HeadlessHistoryManager) MarkContextReset(conversationID uint) (*models.HeadlessTurn, error) {
+	var turn models.HeadlessTurn
+	if err := m.db.Where("conversation_id = ? AND state != ?", conversationID, models.HeadlessTurnStatePending).
+		Order("turn_index DESC").
+		First(&turn).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest turn: %w", err)
+	}
+	if err := m.db.Model(&turn).Update("context_reset_after", true).Error; err != nil {
+		return nil, fmt.Errorf("failed to mark context reset: %w", err)
+	}
+	return &turn, nil
+}
+
+// RecordCompaction 为压缩轮次记录压缩前后的上下文 token 数，非压缩轮次不做任何操作。
+// before 为 0（Claude 未报告）时使用上一个已完成轮次的输入 token 数。
+func (m *HeadlessHistoryManager) RecordCompaction(turnID uint, before, after int) error {
+	var turn models.HeadlessTurn
+	if err := m.db.First(&turn, turnID).Error; err != nil {
+		return fmt.Errorf("failed to get turn: %w", err)
+	}
+	if turn.PromptSource != models.HeadlessPromptSourceCompact {
+		return nil
+	}
+
+	if before == 0 {
+		var previous models.HeadlessTurn
+		err := m.db.Where("conversation_id = ? AND turn_index < ? AND state = ?", turn.ConversationID, turn.TurnIndex, models.HeadlessTurnStateCompleted).
+			Order("turn_index DESC").
+			First(&previous).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to get previous turn: %w", err)
+		}
+		before = previous.InputTokens
+	}
+
+	if err := m.db.Model(&turn).Updates(map[string]interface{}{
+		"context_tokens_before": before,
+		"context_tokens_after":  after,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to record compaction: %w", err)
+	}
+	return nil
+}
+
 // GetConversationForContainer 获取容器的活跃对话
 func (m *HeadlessHistoryManager) GetConversationForContainer(containerID uint) (*models.HeadlessConversation, error) {
 	var conversation models.HeadlessConversation
@@ -546,6 +643,28 @@ func (m *HeadlessHistoryManager) GetConversationForContainer(containerID uint) (
 	return &conversation, nil
 }
 
+// GetLatestConversation 获取容器最近活跃的对话（用于重新打开容器时自动恢复）：
+// 按最后一个轮次的更新时间排序，没有轮次的对话按创建时间，不存在时返回 nil
+func (m *HeadlessHistoryManager) GetLatestConversation(containerID uint) (*models.HeadlessConversation, error) {
+	lastTurns := m.db.Model(&models.HeadlessTurn{}).
+		Select("conversation_id, MAX(updated_at) AS last_active_at").
+		Group("conversation_id")
+
+	var conversation models.HeadlessConversation
+	if err := m.db.Select("headless_conversations.*").
+		Joins("LEFT JOIN (?) AS last_turns ON last_turns.conversation_id = headless_conversations.id", lastTurns).
+		Where("headless_conversations.container_id = ?", containerID).
+		Order("COALESCE(last_turns.last_active_at, headless_conversations.created_at) DESC").
+		Order("headless_conversations.id DESC").
+		First(&conversation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest conversation: %w", err)
+	}
+	return &conversation, nil
+}
+
 // CloseConversation 关闭对话
 func (m *HeadlessHistoryManager) CloseConversation(conversationID uint) error {
 	return m.UpdateConversationState(conversationID, models.HeadlessConversationStateClosed)
@@ -561,15 +680,59 @@ func (m *HeadlessHistoryManager) UpdateTurnAssistantResponse(turnID uint, respon
 	return nil
 }
 
-// ListConversationsForContainer 获取容器的所有对话列表
-func (m *HeadlessHistoryManager) ListConversationsForContainer(containerID uint) ([]models.HeadlessConversation, error) {
+// ConversationFilter 对话列表筛选条件，零值表示不筛选
+type ConversationFilter struct {
+	Tag string // 只返回带有该标签的对话
+}
+
+// ListConversationsForContainer 获取容器的对话列表
+func (m *HeadlessHistoryManager) ListConversationsForContainer(containerID uint, filter ConversationFilter) ([]models.HeadlessConversation, error) {
 	var conversations []models.HeadlessConversation
 	if err := m.db.Where("container_id = ?", containerID).
 		Order("updated_at DESC").
 		Find(&conversations).Error; err != nil {
 		return nil, fmt.Errorf("failed to list conversations: %w", err)
 	}
-	return conversations, nil
+
+	// 标签以 JSON 存储，在内存中筛选
+	tag := strings.ToLower(strings.TrimSpace(filter.Tag))
+	if tag == "" {
+		return conversations, nil
+	}
+	filtered := conversations[:0]
+	for _, conv := range conversations {
+		if slices.Contains(conv.Tags, tag) {
+			filtered = append(filtered, conv)
+		}
+	}
+	return filtered, nil
+}
+
+// ListConversationTags 获取容器所有对话使用过的标签（去重并排序）
+func (m *HeadlessHistoryManager) ListConversationTags(containerID uint) ([]string, error) {
+	var conversations []models.HeadlessConversation
+	if err := m.db.Select("tags").
+		Where("container_id = ? AND tags IS NOT NULL AND tags <> ''", containerID).
+		Find(&conversations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list conversation tags: %w", err)
+	}
+
+	tags := []string{}
+	for _, conv := range conversations {
+		tags = append(tags, conv.Tags...)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags), nil
+}
+
+// UpdateConversationTags 更新对话标签
+func (m *HeadlessHistoryManager) UpdateConversationTags(conversationID uint, tags []string) error {
+	if err := m.db.Model(&models.HeadlessConversation{}).
+		Where("id = ?", conversationID).
+		Update("tags", models.HeadlessTagList(tags)).Error; err != nil {
+		return fmt.Errorf("failed to update conversation tags: %w", err)
+	}
+	return nil
 }
 
 // DeleteConversation 删除对话及其所有轮次和事件
@@ -604,6 +767,90 @@ func (m *HeadlessHistoryManager) DeleteConversation(conversationID uint) error {
 	})
 }
 
+// UpdateConversationSystemPrompt 更新对话级系统提示词
+func (m *HeadlessHistoryManager) UpdateConversationSystemPrompt(conversationID uint, systemPrompt string) error {
+	if err := m.db.Model(&models.HeadlessConversation{}).
+		Where("id = ?", conversationID).
+		Update("system_prompt", systemPrompt).Error; err != nil {
+		return fmt.Errorf("failed to update conversation system prompt: %w", err)
+	}
+	return nil
+}
+
+// UpdateConversationExtraArgs 更新对话的额外 CLI 参数
+func (m *HeadlessHistoryManager) UpdateConversationExtraArgs(conversationID uint, extraArgs []string) error {
+	if err := m.db.Model(&models.HeadlessConversation{}).
+		Where("id = ?", conversationID).
+		Update("extra_args", models.HeadlessArgList(extraArgs)).Error; err != nil {
+		return fmt.Errorf("failed to update conversation extra args: %w", err)
+	}
+	return nil
+}
+
+// UpdateConversationLimits 更新对话的停止条件
+func (m *HeadlessHistoryManager) UpdateConversationLimits(conversationID uint, maxTurns, maxDurationMinutes int) error {
+	if err := m.db.Model(&models.HeadlessConversation{}).
+		Where("id = ?", conversationID).
+		Updates(map[string]interface{}{
+			"max_turns":            maxTurns,
+			"max_duration_minutes": maxDurationMinutes,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update conversation limits: %w", err)
+	}
+	return nil
+}
+
+// UpdateConversationCancelOnDisconnect 更新对话在客户端全部断开时是否取消执行中的轮次
+func (m *HeadlessHistoryManager) UpdateConversationCancelOnDisconnect(conversationID uint, enabled bool) error {
+	if err := m.db.Model(&models.HeadlessConversation{}).
+		Where("id = ?", conversationID).
+		Update("cancel_on_disconnect", enabled).Error; err != nil {
+		return fmt.Errorf("failed to update conversation cancel_on_disconnect: %w", err)
+	}
+	return nil
+}
+
+// CountLimitedTurns 统计计入轮数限制的轮次（压缩轮次不计入），includePending 为 true 时包含排队中的轮次
+func (m *HeadlessHistoryManager) CountLimitedTurns(conversationID uint, includePending bool) (int64, error) {
+	query := m.db.Model(&models.HeadlessTurn{}).
+		Where("conversation_id = ? AND prompt_source <> ?", conversationID, models.HeadlessPromptSourceCompact)
+	if !includePending {
+		query = query.Where("state <> ?", models.HeadlessTurnStatePending)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count turns: %w", err)
+	}
+	return count, nil
+}
+
+// FailPendingTurns 将对话中所有排队中的轮次标记为失败
+func (m *HeadlessHistoryManager) FailPendingTurns(conversationID uint, errorMessage string) error {
+	if err := m.db.Model(&models.HeadlessTurn{}).
+		Where("conversation_id = ? AND state = ?", conversationID, models.HeadlessTurnStatePending).
+		Updates(map[string]interface{}{
+			"state":         models.HeadlessTurnStateError,
+			"error_message": errorMessage,
+			"completed_at":  time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("failed to fail pending turns: %w", err)
+	}
+	return nil
+}
+
+// UpdateConversationBaseline 记录对话开始时的工作目录和 git 基线提交
+func (m *HeadlessHistoryManager) UpdateConversationBaseline(conversationID uint, workDir, baselineCommit string) error {
+	if err := m.db.Model(&models.HeadlessConversation{}).
+		Where("id = ?", conversationID).
+		Updates(map[string]interface{}{
+			"work_dir":        workDir,
+			"baseline_commit": baselineCommit,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update conversation baseline: %w", err)
+	}
+	return nil
+}
+
 // UpdateConversationTitle 更新对话标题
 func (m *HeadlessHistoryManager) UpdateConversationTitle(conversationID uint, title string) error {
 	if err := m.db.Model(&models.HeadlessConversation{}).
@@ -1,8 +1,14 @@
 package headless
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"cc-platform/internal/constants"
 	"cc-platform/internal/models"
 
 	"github.com/glebarez/sqlite"
@@ -31,6 +37,17 @@ func setupHeadlessTestDB(t *testing.T) *gorm.DB {
 	return db
 }
 
+// newTestHeadlessManager 创建不访问 Docker 的 HeadlessManager
+func newTestHeadlessManager(t *testing.T, db *gorm.DB) *HeadlessManager {
+	t.Helper()
+
+	mgr := NewHeadlessManager(db, nil)
+	mgr.execWorkDir = func(ctx context.Context, dockerID, workDir string, cmd []string) (string, error) {
+		return "", nil
+	}
+	return mgr
+}
+
 func TestHistoryManager_CreateAndGetConversation(t *testing.T) {
 	db := setupHeadlessTestDB(t)
 	mgr := NewHeadlessHistoryManager(db)
@@ -61,7 +78,7 @@ func TestHistoryManager_StartTurnAndAppendEvent(t *testing.T) {
 		t.Fatalf("CreateConversation error: %v", err)
 	}
 
-	turn, err := mgr.StartTurn(conv.ID, "hello", models.HeadlessPromptSourceUser)
+	turn, err := mgr.StartTurn(conv.ID, "hello", models.HeadlessPromptSourceUser, nil)
 	if err != nil {
 		t.Fatalf("StartTurn error: %v", err)
 	}
@@ -69,10 +86,10 @@ func TestHistoryManager_StartTurnAndAppendEvent(t *testing.T) {
 		t.Fatalf("expected TurnIndex 0, got %d", turn.TurnIndex)
 	}
 
-	if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeAssistant, "", `{"type":"assistant"}`); err != nil {
+	if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeAssistant, "", `{"type":"assistant"}`, 0); err != nil {
 		t.Fatalf("AppendEvent error: %v", err)
 	}
-	if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeResult, "", `{"type":"result"}`); err != nil {
+	if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeResult, "", `{"type":"result"}`, 0); err != nil {
 		t.Fatalf("AppendEvent error: %v", err)
 	}
 
@@ -97,12 +114,12 @@ func TestHistoryManager_CompleteAndFailTurn(t *testing.T) {
 		t.Fatalf("CreateConversation error: %v", err)
 	}
 
-	turn, err := mgr.StartTurn(conv.ID, "go", models.HeadlessPromptSourceUser)
+	turn, err := mgr.StartTurn(conv.ID, "go", models.HeadlessPromptSourceUser, nil)
 	if err != nil {
 		t.Fatalf("StartTurn error: %v", err)
 	}
 
-	if err := mgr.CompleteTurn(turn.ID, "ok", "model", 1, 2, 0.01, 100); err != nil {
+	if err := mgr.CompleteTurn(turn.ID, "ok", "model", 1, 2, 0.01, CostSourceReported, 100); err != nil {
 		t.Fatalf("CompleteTurn error: %v", err)
 	}
 
@@ -122,7 +139,7 @@ func TestHistoryManager_CompleteAndFailTurn(t *testing.T) {
 		t.Fatalf("expected idle, got %s", convAfter.State)
 	}
 
-	turn2, err := mgr.StartTurn(conv.ID, "fail", models.HeadlessPromptSourceUser)
+	turn2, err := mgr.StartTurn(conv.ID, "fail", models.HeadlessPromptSourceUser, nil)
 	if err != nil {
 		t.Fatalf("StartTurn error: %v", err)
 	}
@@ -150,29 +167,29 @@ func TestHistoryManager_RecentAndBeforeTurns(t *testing.T) {
 
 	var turns []models.HeadlessTurn
 	for i := 0; i < 5; i++ {
-		turn, err := mgr.StartTurn(conv.ID, "p", models.HeadlessPromptSourceUser)
+		turn, err := mgr.StartTurn(conv.ID, "p", models.HeadlessPromptSourceUser, nil)
 		if err != nil {
 			t.Fatalf("StartTurn error: %v", err)
 		}
 		switch i {
 		case 1:
-			if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeAssistant, "", `{"step":1}`); err != nil {
+			if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeAssistant, "", `{"step":1}`, 0); err != nil {
 				t.Fatalf("AppendEvent error: %v", err)
 			}
-			if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeResult, "", `{"step":2}`); err != nil {
+			if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeResult, "", `{"step":2}`, 0); err != nil {
 				t.Fatalf("AppendEvent error: %v", err)
 			}
 		case 2:
-			if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeAssistant, "", `{"step":"a"}`); err != nil {
+			if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeAssistant, "", `{"step":"a"}`, 0); err != nil {
 				t.Fatalf("AppendEvent error: %v", err)
 			}
-			if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeAssistant, "", `{"step":"b"}`); err != nil {
+			if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeAssistant, "", `{"step":"b"}`, 0); err != nil {
 				t.Fatalf("AppendEvent error: %v", err)
 			}
-			if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeResult, "", `{"step":"c"}`); err != nil {
+			if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeResult, "", `{"step":"c"}`, 0); err != nil {
 				t.Fatalf("AppendEvent error: %v", err)
 			}
-			if err := mgr.CompleteTurn(turn.ID, "summary-2", "model", 3, 5, 0.02, 120); err != nil {
+			if err := mgr.CompleteTurn(turn.ID, "summary-2", "model", 3, 5, 0.02, CostSourceReported, 120); err != nil {
 				t.Fatalf("CompleteTurn error: %v", err)
 			}
 		}
@@ -223,3 +240,183 @@ func TestHistoryManager_RecentAndBeforeTurns(t *testing.T) {
 		t.Fatalf("expected assistant_response in before turns, got %q", before[1].AssistantResponse)
 	}
 }
+
+func TestHeadlessManager_SystemPromptPersistsAcrossSessions(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, err := mgr.CreateSession(7, "docker-7", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	conversationID := session.ConversationID
+
+	if err := mgr.SetConversationSystemPrompt(conversationID, "Always respond in Chinese."); err != nil {
+		t.Fatalf("SetConversationSystemPrompt error: %v", err)
+	}
+	if session.GetSystemPrompt() != "Always respond in Chinese." {
+		t.Errorf("running session should pick up the new prompt, got %q", session.GetSystemPrompt())
+	}
+
+	conv, err := mgr.GetHistoryManager().GetConversationByID(conversationID)
+	if err != nil || conv == nil {
+		t.Fatalf("GetConversationByID error: %v", err)
+	}
+	if conv.SystemPrompt != "Always respond in Chinese." {
+		t.Errorf("system prompt not persisted, got %q", conv.SystemPrompt)
+	}
+
+	// A resumed conversation restores the stored prompt
+	if err := mgr.CloseSessionByConversationID(conversationID); err != nil {
+		t.Fatalf("CloseSessionByConversationID error: %v", err)
+	}
+	resumed, err := mgr.CreateSessionForConversation(7, "docker-7", "/app", conversationID)
+	if err != nil {
+		t.Fatalf("CreateSessionForConversation error: %v", err)
+	}
+	if resumed.GetSystemPrompt() != "Always respond in Chinese." {
+		t.Errorf("resumed session should restore the prompt, got %q", resumed.GetSystemPrompt())
+	}
+}
+
+func TestHeadlessManager_SetSystemPromptValidation(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	if err := mgr.SetConversationSystemPrompt(999999, "x"); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("expected ErrConversationNotFound, got %v", err)
+	}
+
+	conv, err := mgr.GetHistoryManager().CreateConversation("session-sp", 8)
+	if err != nil {
+		t.Fatalf("CreateConversation error: %v", err)
+	}
+	tooLarge := strings.Repeat("a", constants.HeadlessSystemPromptMaxBytes+1)
+	if err := mgr.SetConversationSystemPrompt(conv.ID, tooLarge); !errors.Is(err, ErrSystemPromptTooLarge) {
+		t.Errorf("expected ErrSystemPromptTooLarge, got %v", err)
+	}
+}
+
+func TestHistoryManager_StreamCurrentTurnEvents(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := NewHeadlessHistoryManager(db)
+
+	conv, err := mgr.CreateConversation("session-paged", 8)
+	if err != nil {
+		t.Fatalf("CreateConversation error: %v", err)
+	}
+	turn, err := mgr.StartTurn(conv.ID, "long turn", models.HeadlessPromptSourceUser, nil)
+	if err != nil {
+		t.Fatalf("StartTurn error: %v", err)
+	}
+	for i := 1; i <= 230; i++ {
+		if err := mgr.AppendEvent(turn.ID, models.HeadlessEventTypeAssistant, "", `{"type":"assistant"}`, uint64(i)); err != nil {
+			t.Fatalf("AppendEvent error: %v", err)
+		}
+	}
+
+	collect := func(afterSeq uint64, stopAfter int) ([]int, []uint64) {
+		var chunks []int
+		var seqs []uint64
+		err := mgr.StreamCurrentTurnEvents(turn.ID, afterSeq, 50, func(events []models.HeadlessEvent) bool {
+			chunks = append(chunks, len(events))
+			for _, event := range events {
+				seqs = append(seqs, event.Seq)
+			}
+			return stopAfter == 0 || len(chunks) < stopAfter
+		})
+		if err != nil {
+			t.Fatalf("StreamCurrentTurnEvents error: %v", err)
+		}
+		return chunks, seqs
+	}
+	checkSeqs := func(seqs []uint64, first uint64) {
+		t.Helper()
+		for i, seq := range seqs {
+			if seq != first+uint64(i) {
+				t.Fatalf("expected seq %d at %d, got %d", first+uint64(i), i, seq)
+			}
+		}
+	}
+
+	chunks, seqs := collect(0, 0)
+	if fmt.Sprint(chunks) != "[50 50 50 50 30]" {
+		t.Fatalf("unexpected chunks: %v", chunks)
+	}
+	checkSeqs(seqs, 1)
+
+	// 重连时只补发 last_seq 之后的事件
+	chunks, seqs = collect(180, 0)
+	if len(chunks) != 1 || len(seqs) != 50 {
+		t.Fatalf("expected one chunk of 50 events after seq 180, got %v", chunks)
+	}
+	checkSeqs(seqs, 181)
+
+	// 回调返回 false 时停止读取
+	if chunks, seqs = collect(0, 2); len(chunks) != 2 || len(seqs) != 100 {
+		t.Fatalf("expected to stop after 2 chunks, got %v", chunks)
+	}
+
+	if err := mgr.StreamCurrentTurnEvents(turn.ID, 0, 0, func([]models.HeadlessEvent) bool { return true }); err == nil {
+		t.Fatalf("expected error for zero page size")
+	}
+}
+
+func TestHistoryManager_GetLatestConversation(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := NewHeadlessHistoryManager(db)
+	base := time.Now().Add(-time.Hour)
+
+	newConversation := func(containerID uint, createdAt time.Time) *models.HeadlessConversation {
+		conv, err := mgr.CreateConversation(fmt.Sprintf("latest-%d-%d", containerID, createdAt.UnixNano()), containerID)
+		if err != nil {
+			t.Fatalf("CreateConversation error: %v", err)
+		}
+		db.Model(conv).UpdateColumns(map[string]interface{}{"created_at": createdAt, "updated_at": createdAt})
+		return conv
+	}
+	addTurn := func(conv *models.HeadlessConversation, at time.Time) *models.HeadlessTurn {
+		turn, err := mgr.StartTurn(conv.ID, "p", models.HeadlessPromptSourceUser, nil)
+		if err != nil {
+			t.Fatalf("StartTurn error: %v", err)
+		}
+		db.Model(turn).UpdateColumn("updated_at", at)
+		// 对话本身的更新时间不代表活跃（如修改标签），固定为创建时间
+		db.Model(conv).UpdateColumn("updated_at", conv.CreatedAt)
+		return turn
+	}
+
+	if conv, err := mgr.GetLatestConversation(91); err != nil || conv != nil {
+		t.Fatalf("expected no conversation, got %v (%v)", conv, err)
+	}
+
+	active := newConversation(91, base)
+	addTurn(active, base.Add(30*time.Minute))
+	fresh := newConversation(91, base.Add(20*time.Minute))
+	older := newConversation(91, base.Add(5*time.Minute))
+	addTurn(older, base.Add(10*time.Minute))
+	// 已删除的轮次不计入活跃时间
+	deletedTurn := addTurn(older, base.Add(40*time.Minute))
+	db.Delete(deletedTurn)
+	// 其他容器的对话不参与选择
+	other := newConversation(92, base.Add(50*time.Minute))
+	addTurn(other, base.Add(50*time.Minute))
+
+	latest, err := mgr.GetLatestConversation(91)
+	if err != nil {
+		t.Fatalf("GetLatestConversation error: %v", err)
+	}
+	if latest == nil || latest.ID != active.ID {
+		t.Fatalf("expected conversation %d with the latest turn, got %+v", active.ID, latest)
+	}
+
+	// 删除后选择下一个：没有轮次的对话按创建时间排序
+	if err := mgr.DeleteConversation(active.ID); err != nil {
+		t.Fatalf("DeleteConversation error: %v", err)
+	}
+	if latest, _ = mgr.GetLatestConversation(91); latest == nil || latest.ID != fresh.ID {
+		t.Fatalf("expected conversation %d without turns, got %+v", fresh.ID, latest)
+	}
+}
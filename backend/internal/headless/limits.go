@@ -0,0 +1,184 @@
+package headless
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+)
+
+var (
+	// ErrConversationLimitReached 对话已达到停止条件，不再接受新的 prompt
+	ErrConversationLimitReached = errors.New("conversation limit reached")
+	// ErrInvalidConversationLimits 停止条件的取值超出范围
+	ErrInvalidConversationLimits = errors.New("invalid conversation limits")
+)
+
+// 会话自动停止的原因
+const (
+	StopReasonMaxTurns    = "max_turns"
+	StopReasonMaxDuration = "max_duration"
+)
+
+// SessionStoppedPayload 会话因达到停止条件而自动关闭的通知
+type SessionStoppedPayload struct {
+	SessionID      string `json:"session_id"`
+	ConversationID uint   `json:"conversation_id"`
+	Reason         string `json:"reason"` // max_turns | max_duration
+	Message        string `json:"message"`
+	StoppedAt      string `json:"stopped_at"`
+}
+
+// stopCondition 检查对话是否应停止，turns 为计入限制的轮数；未触发时返回空的 reason
+type stopCondition func(conv *models.HeadlessConversation, turns int64, now time.Time) (reason, message string)
+
+// stopConditions 依次检查的停止条件，任一触发即停止。新的条件（如费用预算）追加到这里即可。
+var stopConditions = []stopCondition{maxTurnsReached, maxDurationReached}
+
+func maxTurnsReached(conv *models.HeadlessConversation, turns int64, _ time.Time) (string, string) {
+	if conv.MaxTurns <= 0 || turns < int64(conv.MaxTurns) {
+		return "", ""
+	}
+	return StopReasonMaxTurns, fmt.Sprintf("conversation reached its limit of %d turns", conv.MaxTurns)
+}
+
+func maxDurationReached(conv *models.HeadlessConversation, _ int64, now time.Time) (string, string) {
+	if conv.MaxDurationMinutes <= 0 {
+		return "", ""
+	}
+	limit := time.Duration(conv.MaxDurationMinutes) * time.Minute
+	if now.Sub(conv.CreatedAt) < limit {
+		return "", ""
+	}
+	return StopReasonMaxDuration, fmt.Sprintf("conversation reached its limit of %d minutes", conv.MaxDurationMinutes)
+}
+
+// conversationStopReason 返回第一个触发的停止条件
+func conversationStopReason(conv *models.HeadlessConversation, turns int64, now time.Time) (string, string) {
+	for _, cond := range stopConditions {
+		if reason, message := cond(conv, turns, now); reason != "" {
+			return reason, message
+		}
+	}
+	return "", ""
+}
+
+// validateConversationLimits 校验停止条件，0 表示不限制
+func validateConversationLimits(maxTurns, maxDurationMinutes int) error {
+	if maxTurns < 0 || maxTurns > constants.HeadlessMaxTurnsLimit {
+		return fmt.Errorf("%w: max_turns must be between 0 and %d", ErrInvalidConversationLimits, constants.HeadlessMaxTurnsLimit)
+	}
+	if maxDurationMinutes < 0 || maxDurationMinutes > constants.HeadlessMaxDurationMinutesLimit {
+		return fmt.Errorf("%w: max_duration_minutes must be between 0 and %d", ErrInvalidConversationLimits, constants.HeadlessMaxDurationMinutesLimit)
+	}
+	return nil
+}
+
+// SetConversationLimits 设置对话的停止条件并持久化，nil 表示保持原值、0 表示不限制。
+// 新条件在下一次发送 prompt 和下一轮结束时生效。
+func (m *HeadlessManager) SetConversationLimits(conversationID uint, maxTurns, maxDurationMinutes *int) error {
+	conversation, err := m.historyManager.GetConversationByID(conversationID)
+	if err != nil {
+		return err
+	}
+	if conversation == nil {
+		return ErrConversationNotFound
+	}
+
+	turns, duration := conversation.MaxTurns, conversation.MaxDurationMinutes
+	if maxTurns != nil {
+		turns = *maxTurns
+	}
+	if maxDurationMinutes != nil {
+		duration = *maxDurationMinutes
+	}
+	if err := validateConversationLimits(turns, duration); err != nil {
+		return err
+	}
+	// 对话级设置不能超过平台上限
+	if cappedTurns, cappedDuration := m.overrides.capLimits(turns, duration); cappedTurns != turns || cappedDuration != duration {
+		log.Printf("[HeadlessManager] Platform override: conversation %d limits %d turns / %d minutes capped to %d / %d",
+			conversationID, turns, duration, cappedTurns, cappedDuration)
+		turns, duration = cappedTurns, cappedDuration
+	}
+	return m.historyManager.UpdateConversationLimits(conversationID, turns, duration)
+}
+
+// checkConversationLimits 在接受新 prompt 前检查停止条件，排队中的轮次也计入轮数
+func (m *HeadlessManager) checkConversationLimits(conversationID uint) error {
+	conversation, err := m.historyManager.GetConversationByID(conversationID)
+	if err != nil || conversation == nil {
+		return err
+	}
+	conversation = m.overrides.capConversation(conversation)
+	if conversation.MaxTurns <= 0 && conversation.MaxDurationMinutes <= 0 {
+		return nil
+	}
+	turns, err := m.historyManager.CountLimitedTurns(conversationID, true)
+	if err != nil {
+		return err
+	}
+	if reason, message := conversationStopReason(conversation, turns, time.Now()); reason != "" {
+		return fmt.Errorf("%w: %s", ErrConversationLimitReached, message)
+	}
+	return nil
+}
+
+// attachStopConditions 让会话在每轮结束时检查停止条件，触发后由管理器关闭会话
+func (m *HeadlessManager) attachStopConditions(session *HeadlessSession) {
+	session.checkStop = func() (string, string) {
+		conversation, err := m.historyManager.GetConversationByID(session.ConversationID)
+		if err != nil || conversation == nil {
+			return "", ""
+		}
+		conversation = session.overrides.capConversation(conversation)
+		if conversation.MaxTurns <= 0 && conversation.MaxDurationMinutes <= 0 {
+			return "", ""
+		}
+		// 只统计已开始的轮次，排队中的轮次在限制内时仍可继续执行
+		turns, err := m.historyManager.CountLimitedTurns(session.ConversationID, false)
+		if err != nil {
+			session.logger().Printf("Failed to count turns for stop check: %v", err)
+			return "", ""
+		}
+		return conversationStopReason(conversation, turns, time.Now())
+	}
+	session.onStop = func(message string) {
+		if err := m.historyManager.FailPendingTurns(session.ConversationID, message); err != nil {
+			session.logger().Printf("Failed to drop queued turns: %v", err)
+		}
+		if err := m.CloseSession(session.ID); err != nil {
+			session.logger().Printf("Failed to close stopped session: %v", err)
+		}
+	}
+}
+
+// stopForLimit 通知客户端会话已达到停止条件，然后在后台关闭会话
+func (s *HeadlessSession) stopForLimit(reason, message string) {
+	s.logger().Printf("Stopping session: %s", message)
+
+	payload := &SessionStoppedPayload{
+		SessionID:      s.ID,
+		ConversationID: s.ConversationID,
+		Reason:         reason,
+		Message:        message,
+		StoppedAt:      time.Now().Format(time.RFC3339),
+	}
+	stoppedEvent := &StreamEvent{
+		Type:   "session_stopped",
+		IsMeta: true,
+		Raw:    fmt.Sprintf(`{"type":"session_stopped","session_id":%q}`, s.ID),
+	}
+	if b, err := json.Marshal(payload); err == nil {
+		stoppedEvent.Result = string(b)
+	}
+	s.broadcastToClients(stoppedEvent)
+
+	if s.onStop != nil {
+		go s.onStop(message)
+	}
+}
@@ -0,0 +1,212 @@
+package headless
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+// runTurnToCompletion 在会话上执行一轮并立即完成（不启动 Claude 进程）
+func runTurnToCompletion(t *testing.T, mgr *HeadlessManager, session *HeadlessSession, prompt string) {
+	t.Helper()
+
+	turn, err := mgr.GetHistoryManager().StartTurn(session.ConversationID, prompt, models.HeadlessPromptSourceUser, nil)
+	if err != nil {
+		t.Fatalf("StartTurn error: %v", err)
+	}
+	session.SetCurrentTurnID(turn.ID)
+	session.SetState(HeadlessStateRunning)
+	session.OnTurnComplete(true, "")
+}
+
+// waitForSessionRemoved 等待管理器在后台关闭并移除会话
+func waitForSessionRemoved(t *testing.T, mgr *HeadlessManager, sessionID string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := mgr.GetSession(sessionID); !ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("session %s was not closed", sessionID)
+}
+
+func TestHeadlessManager_MaxTurnsStopsSession(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, err := mgr.CreateSession(31, "docker-limits", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	maxTurns := 2
+	if err := mgr.SetConversationLimits(session.ConversationID, &maxTurns, nil); err != nil {
+		t.Fatalf("SetConversationLimits error: %v", err)
+	}
+	events := session.AddClient("limits-client")
+
+	runTurnToCompletion(t, mgr, session, "first")
+	if session.IsClosed() {
+		t.Fatal("session should stay open below the limit")
+	}
+
+	// 第二轮执行中时排队的 prompt 超出上限，被拒绝
+	turn, err := mgr.GetHistoryManager().StartTurn(session.ConversationID, "second", models.HeadlessPromptSourceUser, nil)
+	if err != nil {
+		t.Fatalf("StartTurn error: %v", err)
+	}
+	session.SetCurrentTurnID(turn.ID)
+	session.SetState(HeadlessStateRunning)
+	if err := mgr.SendPrompt(session.ID, "third", ""); !errors.Is(err, ErrConversationLimitReached) {
+		t.Fatalf("expected ErrConversationLimitReached while queueing, got %v", err)
+	}
+
+	// 执行中的轮次正常完成，然后会话自动关闭
+	session.OnTurnComplete(true, "")
+	waitForSessionRemoved(t, mgr, session.ID)
+
+	stored, err := mgr.GetHistoryManager().GetTurnByID(turn.ID)
+	if err != nil {
+		t.Fatalf("GetTurnByID error: %v", err)
+	}
+	if stored.State != models.HeadlessTurnStateCompleted {
+		t.Errorf("running turn should finish, got state %q", stored.State)
+	}
+
+	var stopped *SessionStoppedPayload
+	for evt := range events {
+		if evt.Type == "session_stopped" {
+			stopped = &SessionStoppedPayload{}
+			if err := json.Unmarshal([]byte(evt.Result), stopped); err != nil {
+				t.Fatalf("invalid session_stopped payload: %v", err)
+			}
+		}
+	}
+	if stopped == nil || stopped.Reason != StopReasonMaxTurns || stopped.ConversationID != session.ConversationID {
+		t.Fatalf("expected a max_turns session_stopped event, got %+v", stopped)
+	}
+
+	// 恢复的对话仍然拒绝新的 prompt
+	resumed, err := mgr.CreateSessionForConversation(31, "docker-limits", "/app", session.ConversationID)
+	if err != nil {
+		t.Fatalf("CreateSessionForConversation error: %v", err)
+	}
+	if err := mgr.SendPrompt(resumed.ID, "again", ""); !errors.Is(err, ErrConversationLimitReached) {
+		t.Errorf("expected ErrConversationLimitReached after resuming, got %v", err)
+	}
+
+	// 提高上限后可以继续
+	maxTurns = 5
+	if err := mgr.SetConversationLimits(session.ConversationID, &maxTurns, nil); err != nil {
+		t.Fatalf("SetConversationLimits error: %v", err)
+	}
+	if err := mgr.checkConversationLimits(session.ConversationID); err != nil {
+		t.Errorf("raised limit should accept prompts, got %v", err)
+	}
+}
+
+func TestHeadlessManager_MaxTurnsDropsQueuedTurns(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, _ := startRunningTurn(t, mgr, 32)
+	if err := mgr.SendPrompt(session.ID, "queued", ""); err != nil {
+		t.Fatalf("SendPrompt error: %v", err)
+	}
+
+	// 压缩轮次不计入限制
+	hm := mgr.GetHistoryManager()
+	if _, err := hm.CreatePendingTurn(session.ConversationID, "/compact", models.HeadlessPromptSourceCompact, nil); err != nil {
+		t.Fatalf("CreatePendingTurn error: %v", err)
+	}
+	if n, _ := hm.CountLimitedTurns(session.ConversationID, true); n != 2 {
+		t.Fatalf("CountLimitedTurns(includePending) = %d, want 2", n)
+	}
+	if n, _ := hm.CountLimitedTurns(session.ConversationID, false); n != 1 {
+		t.Fatalf("CountLimitedTurns = %d, want 1", n)
+	}
+
+	// 排队之后才降低上限：执行中的轮次结束后停止，排队的轮次不再执行
+	maxTurns := 1
+	if err := mgr.SetConversationLimits(session.ConversationID, &maxTurns, nil); err != nil {
+		t.Fatalf("SetConversationLimits error: %v", err)
+	}
+	session.OnTurnComplete(true, "")
+	waitForSessionRemoved(t, mgr, session.ID)
+
+	pending, err := hm.GetPendingTurns(session.ConversationID)
+	if err != nil {
+		t.Fatalf("GetPendingTurns error: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("queued turns should be dropped, %d still pending", len(pending))
+	}
+}
+
+func TestHeadlessManager_MaxDurationRefusesPrompts(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, err := mgr.CreateSession(33, "docker-limits", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	maxDuration := 30
+	if err := mgr.SetConversationLimits(session.ConversationID, nil, &maxDuration); err != nil {
+		t.Fatalf("SetConversationLimits error: %v", err)
+	}
+	if err := mgr.checkConversationLimits(session.ConversationID); err != nil {
+		t.Fatalf("fresh conversation should accept prompts, got %v", err)
+	}
+
+	db.Model(&models.HeadlessConversation{}).Where("id = ?", session.ConversationID).
+		Update("created_at", time.Now().Add(-time.Hour))
+
+	if err := mgr.SendPrompt(session.ID, "late", ""); !errors.Is(err, ErrConversationLimitReached) {
+		t.Fatalf("expected ErrConversationLimitReached, got %v", err)
+	}
+
+	// 时长到期前开始的轮次完成后会话关闭
+	runTurnToCompletion(t, mgr, session, "already running")
+	waitForSessionRemoved(t, mgr, session.ID)
+}
+
+func TestHeadlessManager_SetConversationLimitsValidation(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	conv, err := mgr.GetHistoryManager().CreateConversation("session-limits", 34)
+	if err != nil {
+		t.Fatalf("CreateConversation error: %v", err)
+	}
+	negative := -1
+	if err := mgr.SetConversationLimits(conv.ID, &negative, nil); !errors.Is(err, ErrInvalidConversationLimits) {
+		t.Errorf("expected ErrInvalidConversationLimits, got %v", err)
+	}
+	if err := mgr.SetConversationLimits(999999, nil, nil); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("expected ErrConversationNotFound, got %v", err)
+	}
+
+	// nil 保持原值
+	maxTurns, maxDuration := 3, 60
+	if err := mgr.SetConversationLimits(conv.ID, &maxTurns, &maxDuration); err != nil {
+		t.Fatalf("SetConversationLimits error: %v", err)
+	}
+	cleared := 0
+	if err := mgr.SetConversationLimits(conv.ID, &cleared, nil); err != nil {
+		t.Fatalf("SetConversationLimits error: %v", err)
+	}
+	stored, _ := mgr.GetHistoryManager().GetConversationByID(conv.ID)
+	if stored.MaxTurns != 0 || stored.MaxDurationMinutes != 60 {
+		t.Errorf("limits = %d turns / %d minutes, want 0 / 60", stored.MaxTurns, stored.MaxDurationMinutes)
+	}
+}
@@ -2,11 +2,13 @@ package headless
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"cc-platform/internal/constants"
 	"cc-platform/internal/models"
 	"cc-platform/internal/monitoring"
 
@@ -14,6 +16,13 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrConversationNotFound 对话不存在
+	ErrConversationNotFound = errors.New("conversation not found")
+	// ErrSystemPromptTooLarge 系统提示词超过长度限制
+	ErrSystemPromptTooLarge = errors.New("system prompt too large")
+)
+
 // HeadlessManager 管理所有 Headless 会话
 type HeadlessManager struct {
 	db                   *gorm.DB
@@ -22,11 +31,29 @@ type HeadlessManager struct {
 	mu                   sync.RWMutex
 	monitoringMgr        *monitoring.Manager
 	historyManager       *HeadlessHistoryManager
+	execWorkDir          workDirExecutor   // 在容器工作目录中执行命令（测试中可替换）
+	pricing              *PricingTable     // 计算轮次费用的定价表
+	overrides            PlatformOverrides // 平台级强制模型和对话限制上限
 
 	// 清理配置
 	idleTimeout   time.Duration // 空闲超时时间
 	cleanupTicker *time.Ticker
 	cleanupDone   chan struct{}
+
+	// 会话存活检查（回收进程意外终止的会话）
+	watchdogInterval time.Duration
+	stallTimeout     time.Duration  // 运行中无输出的超时时间，0 表示不检查
+	checkProcess     processChecker // 检查 Claude 进程是否存活（测试中可替换）
+	watchdogTicker   *time.Ticker
+	watchdogSuspects map[string]uint // 上次检查时判定为已终止的会话 -> 当时的轮次
+	watchdogMu       sync.Mutex
+
+	// 客户端全部断开时取消执行中的轮次（对话未单独设置时的默认值）及等待重连的宽限期
+	cancelOnDisconnect bool
+	disconnectGrace    time.Duration
+
+	// 已结束轮次的原始事件保留时长，0 表示永久保留
+	eventRetention time.Duration
 }
 
 // NewHeadlessManager 创建新的 HeadlessManager
@@ -37,17 +64,24 @@ func NewHeadlessManager(db *gorm.DB, monitoringMgr *monitoring.Manager) *Headles
 		conversationSessions: make(map[uint]string),
 		monitoringMgr:        monitoringMgr,
 		historyManager:       NewHeadlessHistoryManager(db),
+		execWorkDir:          execInWorkDir,
+		pricing:              NewPricingTable(nil),
 		idleTimeout:          30 * time.Minute, // 默认 30 分钟空闲超时
 		cleanupDone:          make(chan struct{}),
+		watchdogInterval:     DefaultWatchdogInterval,
+		disconnectGrace:      DefaultDisconnectGrace,
+		eventRetention:       DefaultEventRetention,
+		checkProcess:         checkDockerExecAlive,
 	}
 
-	// 启动清理 goroutine
+	// 启动清理和存活检查 goroutine
 	hm.startCleanup()
+	hm.startWatchdog()
 
 	return hm
 }
 
-// startCleanup 启动定期清理
+// startCleanup 启动定期清理（空闲会话和过期的原始事件）
 func (m *HeadlessManager) startCleanup() {
 	m.cleanupTicker = time.NewTicker(5 * time.Minute)
 
@@ -56,6 +90,7 @@ func (m *HeadlessManager) startCleanup() {
 			select {
 			case <-m.cleanupTicker.C:
 				m.cleanupIdleSessions()
+				m.pruneExpiredEvents()
 			case <-m.cleanupDone:
 				return
 			}
@@ -96,14 +131,15 @@ func (m *HeadlessManager) cleanupIdleSessions() {
 
 // CreateSession 创建新的 Headless 会话
 func (m *HeadlessManager) CreateSession(containerID uint, dockerID, workDir string) (*HeadlessSession, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	// 生成会话 ID
 	sessionID := uuid.New().String()
 
 	// 创建会话
 	session := NewHeadlessSession(sessionID, containerID, dockerID, workDir, m.historyManager)
+	session.pricing = m.pricing
+	m.applyOverrides(session)
+	m.attachStopConditions(session)
+	m.attachDisconnectCancel(session)
 
 	// 创建数据库对话记录
 	conversation, err := m.historyManager.CreateConversation(sessionID, containerID)
@@ -112,9 +148,14 @@ func (m *HeadlessManager) CreateSession(containerID uint, dockerID, workDir stri
 	}
 	session.SetConversationID(conversation.ID)
 
+	// 记录对话开始时的 git 基线，用于统计本次对话的文件变更（在容器中执行，不持有锁）
+	m.recordConversationBaseline(conversation.ID, dockerID, workDir)
+
 	// 保存会话
+	m.mu.Lock()
 	m.sessions[sessionID] = session
 	m.conversationSessions[conversation.ID] = sessionID
+	m.mu.Unlock()
 
 	log.Printf("[HeadlessManager] Created session %s for container %d, conversation %d", sessionID, containerID, conversation.ID)
 
@@ -138,7 +179,11 @@ func (m *HeadlessManager) CreateSessionForConversation(containerID uint, dockerI
 
 	// 创建会话
 	session := NewHeadlessSession(sessionID, containerID, dockerID, workDir, m.historyManager)
+	session.pricing = m.pricing
+	m.applyOverrides(session)
 	session.SetConversationID(conversationID)
+	m.attachStopConditions(session)
+	m.attachDisconnectCancel(session)
 
 	// 从数据库加载已有的 ClaudeSessionID，用于 --resume 恢复历史会话上下文
 	conversation, err := m.historyManager.GetConversationByID(conversationID)
@@ -146,6 +191,11 @@ func (m *HeadlessManager) CreateSessionForConversation(containerID uint, dockerI
 		session.ClaudeSessionID = conversation.ClaudeSessionID
 		log.Printf("[HeadlessManager] Restored ClaudeSessionID %s for conversation %d (will use --resume)", conversation.ClaudeSessionID, conversationID)
 	}
+	// 恢复对话级系统提示词，保证恢复后的对话保持相同人设
+	if err == nil && conversation != nil {
+		session.SetSystemPrompt(conversation.SystemPrompt)
+		session.SetExtraArgs(conversation.ExtraArgs)
+	}
 
 	// 更新数据库对话记录的 session_id
 	if err := m.historyManager.UpdateConversationSessionID(conversationID, sessionID); err != nil {
@@ -161,6 +211,57 @@ func (m *HeadlessManager) CreateSessionForConversation(containerID uint, dockerI
 	return session, nil
 }
 
+// SetConversationSystemPrompt 设置对话级系统提示词并持久化。
+// 如果对话有运行中的会话，新提示词从下一轮开始生效；空字符串表示清除。
+func (m *HeadlessManager) SetConversationSystemPrompt(conversationID uint, systemPrompt string) error {
+	if len(systemPrompt) > constants.HeadlessSystemPromptMaxBytes {
+		return fmt.Errorf("%w: %d bytes (max %d)", ErrSystemPromptTooLarge, len(systemPrompt), constants.HeadlessSystemPromptMaxBytes)
+	}
+
+	conversation, err := m.historyManager.GetConversationByID(conversationID)
+	if err != nil {
+		return err
+	}
+	if conversation == nil {
+		return ErrConversationNotFound
+	}
+
+	if err := m.historyManager.UpdateConversationSystemPrompt(conversationID, systemPrompt); err != nil {
+		return err
+	}
+
+	if session := m.GetSessionByConversationID(conversationID); session != nil {
+		session.SetSystemPrompt(systemPrompt)
+	}
+	return nil
+}
+
+// SetConversationExtraArgs 按白名单校验额外 CLI 参数并持久化，恢复对话时继续使用。
+// 如果对话有运行中的会话，新参数从下一轮开始生效；空列表表示清除。
+func (m *HeadlessManager) SetConversationExtraArgs(conversationID uint, extraArgs []string) error {
+	normalized, err := NormalizeCLIArgs(extraArgs)
+	if err != nil {
+		return err
+	}
+
+	conversation, err := m.historyManager.GetConversationByID(conversationID)
+	if err != nil {
+		return err
+	}
+	if conversation == nil {
+		return ErrConversationNotFound
+	}
+
+	if err := m.historyManager.UpdateConversationExtraArgs(conversationID, normalized); err != nil {
+		return err
+	}
+
+	if session := m.GetSessionByConversationID(conversationID); session != nil {
+		session.SetExtraArgs(normalized)
+	}
+	return nil
+}
+
 // GetSession 根据 sessionID 获取会话
 func (m *HeadlessManager) GetSession(sessionID string) (*HeadlessSession, bool) {
 	m.mu.RLock()
@@ -328,6 +429,12 @@ func (m *HeadlessManager) SendPrompt(sessionID, prompt string, source string) er
 // SendPromptWithModel 发送 prompt 到会话（带模型参数）
 // 如果 session 正在运行，消息会被加入队列等待执行
 func (m *HeadlessManager) SendPromptWithModel(sessionID, prompt string, source string, model string) error {
+	return m.SendPromptWithFiles(sessionID, prompt, source, model, nil)
+}
+
+// SendPromptWithFiles 发送 prompt 到会话，并引用工作目录中的文件。
+// 文件路径必须相对于工作目录且存在于容器中，执行时以 @path 形式放在 prompt 之前。
+func (m *HeadlessManager) SendPromptWithFiles(sessionID, prompt string, source string, model string, filePaths []string) error {
 	session, ok := m.GetSession(sessionID)
 	if !ok {
 		return fmt.Errorf("session not found: %s", sessionID)
@@ -337,18 +444,41 @@ func (m *HeadlessManager) SendPromptWithModel(sessionID, prompt string, source s
 		return fmt.Errorf("session is closed")
 	}
 
+	files, err := NormalizeAttachedFiles(filePaths)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), constants.HeadlessAttachedFilesCheckTimeout)
+		err := checkAttachedFiles(ctx, m.execWorkDir, session.DockerID, session.WorkDir, files)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
 	if source == "" {
 		source = models.HeadlessPromptSourceUser
 	}
 
-	// 设置模型（如果提供）
+	// 达到轮数或时长上限后不再接受新的 prompt（包括排队）
+	if err := m.checkConversationLimits(session.ConversationID); err != nil {
+		return err
+	}
+
+	// 设置模型（如果提供），平台强制模型优先于客户端请求
+	effectiveModel, overridden := m.overrides.effectiveModel(model)
+	if overridden {
+		session.logger().Printf("Platform override: model %s requested, using %s", model, effectiveModel)
+	}
+	model = effectiveModel
 	if model != "" {
 		session.Model = model
 	}
 
 	// 如果 session 正在运行，将消息加入队列
 	if session.GetState() == HeadlessStateRunning {
-		_, err := m.historyManager.CreatePendingTurn(session.ConversationID, prompt, source)
+		_, err := m.historyManager.CreatePendingTurn(session.ConversationID, prompt, source, files)
 		if err != nil {
 			return fmt.Errorf("failed to queue prompt: %w", err)
 		}
@@ -359,7 +489,7 @@ func (m *HeadlessManager) SendPromptWithModel(sessionID, prompt string, source s
 	}
 
 	// Session 空闲，直接执行
-	turn, err := m.historyManager.StartTurn(session.ConversationID, prompt, source)
+	turn, err := m.historyManager.StartTurn(session.ConversationID, prompt, source, files)
 	if err != nil {
 		return fmt.Errorf("failed to start turn: %w", err)
 	}
@@ -370,7 +500,7 @@ func (m *HeadlessManager) SendPromptWithModel(sessionID, prompt string, source s
 
 	// 启动 Claude 进程
 	ctx := context.Background()
-	if err := session.StartClaudeProcess(ctx, prompt); err != nil {
+	if err := session.StartClaudeProcess(ctx, BuildPromptWithFiles(prompt, files)); err != nil {
 		// 标记轮次失败
 		m.historyManager.FailTurn(turn.ID, err.Error())
 		return fmt.Errorf("failed to start claude process: %w", err)
@@ -389,6 +519,26 @@ func (m *HeadlessManager) CancelExecution(sessionID string) error {
 	return session.CancelExecution()
 }
 
+// CancelTurn 取消会话中指定的轮次；该轮次不是正在执行的轮次时不做任何操作
+func (m *HeadlessManager) CancelTurn(sessionID string, turnID uint) (*CancelTurnResultPayload, error) {
+	session, ok := m.GetSession(sessionID)
+	if !ok {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	return session.CancelTurn(turnID)
+}
+
+// ResetContext 重置会话的 Claude 上下文，保留对话历史
+func (m *HeadlessManager) ResetContext(sessionID string) (*ContextResetPayload, error) {
+	session, ok := m.GetSession(sessionID)
+	if !ok {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	return session.ResetContext()
+}
+
 // GetHistoryManager 获取历史管理器
 func (m *HeadlessManager) GetHistoryManager() *HeadlessHistoryManager {
 	return m.historyManager
@@ -418,10 +568,13 @@ func (m *HeadlessManager) GetSessionCount() int {
 func (m *HeadlessManager) Close() error {
 	log.Printf("[HeadlessManager] Closing manager")
 
-	// 停止清理 goroutine
+	// 停止清理和存活检查 goroutine
 	if m.cleanupTicker != nil {
 		m.cleanupTicker.Stop()
 	}
+	if m.watchdogTicker != nil {
+		m.watchdogTicker.Stop()
+	}
 	close(m.cleanupDone)
 
 	// 关闭所有会话
@@ -442,6 +595,18 @@ func (m *HeadlessManager) Close() error {
 	return nil
 }
 
+// SetPricingTable 设置计算轮次费用的定价表，只影响之后创建的会话
+func (m *HeadlessManager) SetPricingTable(table *PricingTable) {
+	if table != nil {
+		m.pricing = table
+	}
+}
+
+// PricingTable 返回当前的定价表
+func (m *HeadlessManager) PricingTable() *PricingTable {
+	return m.pricing
+}
+
 // SetIdleTimeout 设置空闲超时时间
 func (m *HeadlessManager) SetIdleTimeout(timeout time.Duration) {
 	m.idleTimeout = timeout
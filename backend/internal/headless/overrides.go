@@ -0,0 +1,87 @@
+package headless
+
+import (
+	"fmt"
+
+	"cc-platform/internal/models"
+)
+
+// PlatformOverrides 平台级强制设置，在客户端请求之后应用，客户端无法绕过
+type PlatformOverrides struct {
+	Model              string // 所有会话强制使用的模型，空表示使用客户端请求的模型
+	MaxTurns           int    // 对话轮数上限，0 表示不限制
+	MaxDurationMinutes int    // 对话时长上限（分钟），0 表示不限制
+}
+
+// SetPlatformOverrides 设置平台级强制设置，只影响之后创建的会话；取值无效时返回错误并保持原设置
+func (m *HeadlessManager) SetPlatformOverrides(overrides PlatformOverrides) error {
+	if overrides.Model != "" {
+		if err := validateCLIModel(overrides.Model); err != nil {
+			return fmt.Errorf("%w: forced model %v", ErrInvalidCLIFlag, err)
+		}
+	}
+	if err := validateConversationLimits(overrides.MaxTurns, overrides.MaxDurationMinutes); err != nil {
+		return err
+	}
+	m.overrides = overrides
+	return nil
+}
+
+// PlatformOverrides 返回当前的平台级强制设置
+func (m *HeadlessManager) PlatformOverrides() PlatformOverrides {
+	return m.overrides
+}
+
+// applyOverrides 把平台设置交给新建的会话，强制模型从第一轮开始生效
+func (m *HeadlessManager) applyOverrides(session *HeadlessSession) {
+	session.overrides = m.overrides
+	if m.overrides.Model != "" {
+		session.Model = m.overrides.Model
+	}
+}
+
+// effectiveModel 返回实际使用的模型，overridden 表示客户端请求的模型被强制模型替换
+func (o PlatformOverrides) effectiveModel(requested string) (model string, overridden bool) {
+	if o.Model == "" {
+		return requested, false
+	}
+	return o.Model, requested != "" && requested != o.Model
+}
+
+// capLimit 把对话的限制收紧到平台上限以内，cap 为 0 表示没有上限；value 为 0（不限制）时取上限
+func capLimit(value, cap int) int {
+	if cap <= 0 {
+		return value
+	}
+	if value <= 0 || value > cap {
+		return cap
+	}
+	return value
+}
+
+// capLimits 返回收紧到平台上限以内的轮数和时长限制
+func (o PlatformOverrides) capLimits(maxTurns, maxDurationMinutes int) (int, int) {
+	return capLimit(maxTurns, o.MaxTurns), capLimit(maxDurationMinutes, o.MaxDurationMinutes)
+}
+
+// capConversation 返回应用平台上限后的对话副本，用于检查停止条件
+func (o PlatformOverrides) capConversation(conv *models.HeadlessConversation) *models.HeadlessConversation {
+	capped := *conv
+	capped.MaxTurns, capped.MaxDurationMinutes = o.capLimits(conv.MaxTurns, conv.MaxDurationMinutes)
+	return &capped
+}
+
+// filterExtraArgs 强制模型时去掉 --fallback-model，避免切换到其他模型
+func (o PlatformOverrides) filterExtraArgs(args []string) []string {
+	if o.Model == "" {
+		return args
+	}
+	filtered := make([]string, 0, len(args))
+	for i := 0; i < len(args); i += 2 {
+		if args[i] == "--fallback-model" {
+			continue
+		}
+		filtered = append(filtered, args[i:min(i+2, len(args))]...)
+	}
+	return filtered
+}
@@ -0,0 +1,119 @@
+package headless
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestHeadlessManager_SetPlatformOverridesValidation(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	if err := mgr.SetPlatformOverrides(PlatformOverrides{Model: "--evil"}); !errors.Is(err, ErrInvalidCLIFlag) {
+		t.Errorf("expected ErrInvalidCLIFlag for a bad model, got %v", err)
+	}
+	if err := mgr.SetPlatformOverrides(PlatformOverrides{MaxTurns: -1}); !errors.Is(err, ErrInvalidConversationLimits) {
+		t.Errorf("expected ErrInvalidConversationLimits for a negative cap, got %v", err)
+	}
+	if got := mgr.PlatformOverrides(); got != (PlatformOverrides{}) {
+		t.Errorf("invalid overrides should not be applied, got %+v", got)
+	}
+}
+
+func TestHeadlessManager_ForcedModelOverridesClient(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+	if err := mgr.SetPlatformOverrides(PlatformOverrides{Model: "claude-haiku-4"}); err != nil {
+		t.Fatalf("SetPlatformOverrides error: %v", err)
+	}
+
+	session, _ := startRunningTurn(t, mgr, 51)
+	if session.Model != "claude-haiku-4" {
+		t.Errorf("new session model = %q, want the forced model", session.Model)
+	}
+
+	// 会话运行中时 prompt 进入队列，模型在入队前已确定
+	if err := mgr.SendPromptWithModel(session.ID, "next", "", "claude-opus-4"); err != nil {
+		t.Fatalf("SendPromptWithModel error: %v", err)
+	}
+	if session.Model != "claude-haiku-4" {
+		t.Errorf("session model = %q after a client request, want the forced model", session.Model)
+	}
+
+	session.ExtraArgs = []string{"--max-turns", "5", "--fallback-model", "claude-opus-4"}
+	args := session.buildClaudeArgs("hi")
+	for i, arg := range args {
+		if arg == "--fallback-model" {
+			t.Errorf("forced model should drop --fallback-model, got %q", args)
+		}
+		if arg == "--model" && args[i+1] != "claude-haiku-4" {
+			t.Errorf("--model %s, want the forced model", args[i+1])
+		}
+	}
+
+	info := session.GetSessionInfo()
+	if info.Model != "claude-haiku-4" || !info.ModelForced {
+		t.Errorf("session info should report the forced model, got %+v", info)
+	}
+}
+
+func TestHeadlessManager_LimitCapsClampConversations(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+	if err := mgr.SetPlatformOverrides(PlatformOverrides{MaxTurns: 5, MaxDurationMinutes: 60}); err != nil {
+		t.Fatalf("SetPlatformOverrides error: %v", err)
+	}
+
+	session, err := mgr.CreateSession(52, "docker-caps", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+
+	// 未设置限制的对话也受平台上限约束
+	info := session.GetSessionInfo()
+	if info.MaxTurns != 5 || info.MaxDurationMinutes != 60 {
+		t.Errorf("effective limits = %d / %d, want the caps 5 / 60", info.MaxTurns, info.MaxDurationMinutes)
+	}
+
+	// 超过上限的客户端设置被收紧，低于上限的保持不变
+	maxTurns, maxDuration := 50, 30
+	if err := mgr.SetConversationLimits(session.ConversationID, &maxTurns, &maxDuration); err != nil {
+		t.Fatalf("SetConversationLimits error: %v", err)
+	}
+	stored, _ := mgr.GetHistoryManager().GetConversationByID(session.ConversationID)
+	if stored.MaxTurns != 5 || stored.MaxDurationMinutes != 30 {
+		t.Errorf("stored limits = %d / %d, want 5 / 30", stored.MaxTurns, stored.MaxDurationMinutes)
+	}
+
+	unlimited := 0
+	if err := mgr.SetConversationLimits(session.ConversationID, &unlimited, nil); err != nil {
+		t.Fatalf("SetConversationLimits error: %v", err)
+	}
+	stored, _ = mgr.GetHistoryManager().GetConversationByID(session.ConversationID)
+	if stored.MaxTurns != 5 {
+		t.Errorf("clearing the limit stored %d turns, want the cap 5", stored.MaxTurns)
+	}
+
+	// 数据库中已有的超限值在检查停止条件时同样被收紧
+	db.Model(stored).Update("max_turns", 0)
+	for i := 0; i < 5; i++ {
+		runTurnToCompletion(t, mgr, session, "turn")
+	}
+	if err := mgr.checkConversationLimits(session.ConversationID); !errors.Is(err, ErrConversationLimitReached) {
+		t.Errorf("expected the cap to stop the conversation, got %v", err)
+	}
+}
+
+func TestPlatformOverrides_FilterExtraArgs(t *testing.T) {
+	args := []string{"--fallback-model", "claude-opus-4", "--max-turns", "3"}
+	if got := (PlatformOverrides{}).filterExtraArgs(args); !reflect.DeepEqual(got, args) {
+		t.Errorf("no forced model should keep every arg, got %q", got)
+	}
+	if got := (PlatformOverrides{Model: "claude-haiku-4"}).filterExtraArgs(args); !reflect.DeepEqual(got, []string{"--max-turns", "3"}) {
+		t.Errorf("filterExtraArgs() = %q", got)
+	}
+}
@@ -197,6 +197,11 @@ func IsSystemEvent(evt *StreamEvent) bool {
 	return evt != nil && evt.Type == StreamEventTypeSystem
 }
 
+// IsCompactBoundaryEvent 检查是否为上下文压缩边界事件
+func IsCompactBoundaryEvent(evt *StreamEvent) bool {
+	return evt != nil && evt.Type == StreamEventTypeSystem && evt.Subtype == "compact_boundary" && evt.CompactMetadata != nil
+}
+
 // IsAssistantEvent 检查是否为助手事件
 func IsAssistantEvent(evt *StreamEvent) bool {
 	return evt != nil && evt.Type == StreamEventTypeAssistant
@@ -0,0 +1,144 @@
+package headless
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 轮次费用来源
+const (
+	CostSourceReported = "reported" // stream 的 result 事件上报的费用
+	CostSourceComputed = "computed" // 按定价表和 token 数计算的费用
+)
+
+// DefaultPricingModel 定价表中兜底条目的名称，用于未匹配到任何模型的情况
+const DefaultPricingModel = "default"
+
+// ModelPrice 单个模型的定价（美元 / 百万 token）
+type ModelPrice struct {
+	Model         string  `json:"model"` // 模型名前缀，如 "claude-sonnet-4" 匹配 "claude-sonnet-4-5-20250929"
+	InputPerMTok  float64 `json:"input_per_mtok"`
+	OutputPerMTok float64 `json:"output_per_mtok"`
+}
+
+// Cost 计算给定 token 数的费用（美元）
+func (p ModelPrice) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.InputPerMTok + float64(outputTokens)*p.OutputPerMTok) / 1_000_000
+}
+
+// builtinModelPrices 内置定价，MODEL_PRICING 中的同名条目会覆盖
+var builtinModelPrices = []ModelPrice{
+	{Model: DefaultPricingModel, InputPerMTok: 3, OutputPerMTok: 15},
+	{Model: "claude-opus-4", InputPerMTok: 15, OutputPerMTok: 75},
+	{Model: "claude-opus-4-5", InputPerMTok: 5, OutputPerMTok: 25},
+	{Model: "claude-sonnet-4", InputPerMTok: 3, OutputPerMTok: 15},
+	{Model: "claude-haiku-4-5", InputPerMTok: 1, OutputPerMTok: 5},
+	{Model: "claude-3-7-sonnet", InputPerMTok: 3, OutputPerMTok: 15},
+	{Model: "claude-3-5-sonnet", InputPerMTok: 3, OutputPerMTok: 15},
+	{Model: "claude-3-5-haiku", InputPerMTok: 0.8, OutputPerMTok: 4},
+	{Model: "claude-3-haiku", InputPerMTok: 0.25, OutputPerMTok: 1.25},
+}
+
+// PricingTable 按模型名前缀查找定价的表
+type PricingTable struct {
+	prices []ModelPrice // 按模型名排序，不含兜底条目
+	def    *ModelPrice  // 兜底定价，nil 表示未匹配的模型不计算费用
+}
+
+// NewPricingTable 在内置定价的基础上应用 "model=input:output" 条目（美元 / 百万 token）。
+// 无效条目记录日志后跳过，避免拼写错误导致服务无法启动。
+func NewPricingTable(entries []string) *PricingTable {
+	byModel := make(map[string]ModelPrice)
+	for _, p := range builtinModelPrices {
+		byModel[p.Model] = p
+	}
+	for _, entry := range entries {
+		price, err := parseModelPrice(entry)
+		if err != nil {
+			log.Printf("Warning: ignoring model price %q: %v", entry, err)
+			continue
+		}
+		byModel[price.Model] = price
+	}
+
+	t := &PricingTable{}
+	for model, price := range byModel {
+		if model == DefaultPricingModel {
+			price := price
+			t.def = &price
+			continue
+		}
+		t.prices = append(t.prices, price)
+	}
+	sort.Slice(t.prices, func(i, j int) bool { return t.prices[i].Model < t.prices[j].Model })
+	return t
+}
+
+// parseModelPrice 解析 "model=input:output" 条目，如 "claude-sonnet-4=3:15"
+func parseModelPrice(entry string) (ModelPrice, error) {
+	model, rates, ok := strings.Cut(entry, "=")
+	model = strings.ToLower(strings.TrimSpace(model))
+	if !ok || model == "" {
+		return ModelPrice{}, fmt.Errorf("expected model=input:output")
+	}
+	inStr, outStr, ok := strings.Cut(rates, ":")
+	if !ok {
+		return ModelPrice{}, fmt.Errorf("expected model=input:output")
+	}
+	in, err := strconv.ParseFloat(strings.TrimSpace(inStr), 64)
+	if err != nil || in < 0 {
+		return ModelPrice{}, fmt.Errorf("invalid input price %q", inStr)
+	}
+	out, err := strconv.ParseFloat(strings.TrimSpace(outStr), 64)
+	if err != nil || out < 0 {
+		return ModelPrice{}, fmt.Errorf("invalid output price %q", outStr)
+	}
+	return ModelPrice{Model: model, InputPerMTok: in, OutputPerMTok: out}, nil
+}
+
+// Lookup 返回模型名前缀匹配最长的定价，未匹配时返回兜底定价
+func (t *PricingTable) Lookup(model string) (ModelPrice, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	var best *ModelPrice
+	for i := range t.prices {
+		p := &t.prices[i]
+		if strings.HasPrefix(model, p.Model) && (best == nil || len(p.Model) > len(best.Model)) {
+			best = p
+		}
+	}
+	if best != nil {
+		return *best, true
+	}
+	if t.def != nil {
+		return *t.def, true
+	}
+	return ModelPrice{}, false
+}
+
+// Prices 返回全部定价，兜底条目排在最前
+func (t *PricingTable) Prices() []ModelPrice {
+	prices := make([]ModelPrice, 0, len(t.prices)+1)
+	if t.def != nil {
+		prices = append(prices, *t.def)
+	}
+	return append(prices, t.prices...)
+}
+
+// TurnCost 决定轮次费用：优先使用 stream 上报的费用，否则按定价表计算。
+// 两者都没有时返回 0 和空来源。
+func (t *PricingTable) TurnCost(model string, inputTokens, outputTokens int, reported float64) (float64, string) {
+	if reported > 0 {
+		return reported, CostSourceReported
+	}
+	if t == nil {
+		return 0, ""
+	}
+	price, ok := t.Lookup(model)
+	if !ok {
+		return 0, ""
+	}
+	return price.Cost(inputTokens, outputTokens), CostSourceComputed
+}
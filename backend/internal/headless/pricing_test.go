@@ -0,0 +1,101 @@
+package headless
+
+import (
+	"math"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func TestPricingTable_Lookup(t *testing.T) {
+	table := NewPricingTable([]string{"claude-sonnet-4=2:10", "my-proxy-model=1:2", "broken", "bad=x:1"})
+
+	cases := map[string]float64{
+		"claude-sonnet-4-5-20250929": 2,   // 覆盖内置定价
+		"claude-opus-4-5-20251101":   5,   // 最长前缀优先于 claude-opus-4
+		"claude-opus-4-1-20250805":   15,  // 只匹配 claude-opus-4
+		"Claude-3-5-Haiku-20241022":  0.8, // 不区分大小写
+		"my-proxy-model":             1,   // 新增条目
+		"unknown-model":              3,   // 兜底定价
+	}
+	for model, want := range cases {
+		price, ok := table.Lookup(model)
+		if !ok || price.InputPerMTok != want {
+			t.Errorf("Lookup(%q) = %+v, %v; want input price %v", model, price, ok, want)
+		}
+	}
+
+	prices := table.Prices()
+	if prices[0].Model != DefaultPricingModel {
+		t.Errorf("expected the default price first, got %q", prices[0].Model)
+	}
+	for _, p := range prices {
+		if p.Model == "broken" || p.Model == "bad" {
+			t.Errorf("expected invalid entry %q to be skipped", p.Model)
+		}
+	}
+}
+
+func TestPricingTable_TurnCost(t *testing.T) {
+	table := NewPricingTable(nil)
+
+	cost, source := table.TurnCost("claude-sonnet-4-5", 1000, 500, 0.42)
+	if cost != 0.42 || source != CostSourceReported {
+		t.Errorf("expected the reported cost to win, got %v (%s)", cost, source)
+	}
+
+	cost, source = table.TurnCost("claude-opus-4-5", 1_000_000, 100_000, 0)
+	if math.Abs(cost-7.5) > 1e-9 || source != CostSourceComputed {
+		t.Errorf("expected 5 + 2.5 USD computed, got %v (%s)", cost, source)
+	}
+
+	// 没有定价表时无法计算
+	var none *PricingTable
+	if cost, source := none.TurnCost("claude-sonnet-4", 1000, 1000, 0); cost != 0 || source != "" {
+		t.Errorf("expected no cost without a pricing table, got %v (%s)", cost, source)
+	}
+}
+
+func TestHeadlessSession_TurnCostSource(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+	mgr.SetPricingTable(NewPricingTable([]string{"claude-sonnet-4=3:15"}))
+
+	session, err := mgr.CreateSession(31, "docker-cost", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	history := mgr.GetHistoryManager()
+
+	runTurn := func(events ...*StreamEvent) models.HeadlessTurn {
+		t.Helper()
+		turn, err := history.StartTurn(session.ConversationID, "hi", models.HeadlessPromptSourceUser, nil)
+		if err != nil {
+			t.Fatalf("StartTurn error: %v", err)
+		}
+		session.SetCurrentTurnID(turn.ID)
+		for _, evt := range events {
+			session.OnStreamEvent(evt)
+		}
+		session.OnTurnComplete(true, "")
+		var stored models.HeadlessTurn
+		db.First(&stored, turn.ID)
+		return stored
+	}
+
+	usage := &StreamEvent{Type: StreamEventTypeAssistant, Model: "claude-sonnet-4-5",
+		Message: &MessagePayload{Usage: &UsageInfo{InputTokens: 2000, OutputTokens: 1000}}}
+
+	// result 事件上报了费用
+	reported := runTurn(usage, &StreamEvent{Type: StreamEventTypeResult, TotalCost: 0.05})
+	if reported.CostUSD != 0.05 || reported.CostSource != CostSourceReported {
+		t.Errorf("expected the reported cost, got %v (%s)", reported.CostUSD, reported.CostSource)
+	}
+
+	// 未上报时按定价表计算：2000 * 3 + 1000 * 15 = 21000 / 1e6
+	computed := runTurn(usage, &StreamEvent{Type: StreamEventTypeResult})
+	if math.Abs(computed.CostUSD-0.021) > 1e-9 || computed.CostSource != CostSourceComputed {
+		t.Errorf("expected a computed cost of 0.021, got %v (%s)", computed.CostUSD, computed.CostSource)
+	}
+}
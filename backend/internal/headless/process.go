@@ -150,8 +150,8 @@ func (s *HeadlessSession) StartClaudeProcess(ctx context.Context, prompt string)
 	readCtx, cancel := context.WithCancel(ctx)
 	s.cancelRead = cancel
 
-	// 启动输出读取 goroutine - 使用 TTY 模式的直接读取
-	go s.readDockerOutputTTY(readCtx, &claudeAttachResp)
+	// 启动输出读取 goroutine - 使用 TTY 模式的直接读取，输出归属于启动时的上下文代数
+	go s.readDockerOutputTTY(readCtx, &claudeAttachResp, s.contextGeneration())
 
 	// 启动进程状态检查 goroutine
 	go s.waitDockerExec(ctx, cli, execResp.ID)
@@ -180,6 +180,15 @@ func (s *HeadlessSession) buildClaudeArgs(prompt string) []string {
 		args = append(args, "--model", s.Model)
 	}
 
+	// 对话级系统提示词：追加而不是替换，保留 Claude Code 自带的工具说明。
+	// 每轮都要传入，--resume 不会记住上一轮的系统提示词。
+	if systemPrompt := s.GetSystemPrompt(); systemPrompt != "" {
+		args = append(args, "--append-system-prompt", systemPrompt)
+	}
+
+	// 对话级额外参数，保存时已按白名单校验
+	args = append(args, s.overrides.filterExtraArgs(s.GetExtraArgs())...)
+
 	// 如果有 session_id，使用 resume
 	if s.ClaudeSessionID != "" {
 		args = append(args, "--resume", s.ClaudeSessionID)
@@ -193,7 +202,7 @@ func (s *HeadlessSession) buildClaudeArgs(prompt string) []string {
 
 // readDockerOutputTTY 从 Docker hijacked 连接读取 TTY 输出
 // 当 Tty=true 时，stdout 和 stderr 合并输出，直接读取即可
-func (s *HeadlessSession) readDockerOutputTTY(ctx context.Context, resp *types.HijackedResponse) {
+func (s *HeadlessSession) readDockerOutputTTY(ctx context.Context, resp *types.HijackedResponse, gen uint64) {
 	defer func() {
 		resp.Close()
 		log.Printf("[HeadlessSession %s] Docker TTY output reader closed", s.ID)
@@ -212,7 +221,7 @@ func (s *HeadlessSession) readDockerOutputTTY(ctx context.Context, resp *types.H
 			log.Printf("[HeadlessSession %s] Context cancelled, stopping TTY output read", s.ID)
 			// 处理缓冲区中剩余的数据
 			if lineBuf.Len() > 0 {
-				s.processLine(lineBuf.String(), &lineCount)
+				s.processLine(lineBuf.String(), &lineCount, gen)
 			}
 			return
 		default:
@@ -226,7 +235,7 @@ func (s *HeadlessSession) readDockerOutputTTY(ctx context.Context, resp *types.H
 			}
 			// 处理缓冲区中剩余的数据
 			if lineBuf.Len() > 0 {
-				s.processLine(lineBuf.String(), &lineCount)
+				s.processLine(lineBuf.String(), &lineCount, gen)
 			}
 			log.Printf("[HeadlessSession %s] TTY EOF reached, total lines: %d", s.ID, lineCount)
 			return
@@ -244,14 +253,14 @@ func (s *HeadlessSession) readDockerOutputTTY(ctx context.Context, resp *types.H
 					lineBuf.WriteString(line)
 					break
 				}
-				s.processLine(line, &lineCount)
+				s.processLine(line, &lineCount, gen)
 			}
 		}
 	}
 }
 
-// processLine 处理单行输出
-func (s *HeadlessSession) processLine(line string, lineCount *int) {
+// processLine 处理第 gen 代上下文进程的单行输出
+func (s *HeadlessSession) processLine(line string, lineCount *int, gen uint64) {
 	line = strings.TrimSpace(line)
 	if line == "" {
 		return
@@ -271,7 +280,9 @@ func (s *HeadlessSession) processLine(line string, lineCount *int) {
 	}
 
 	log.Printf("[HeadlessSession %s] Parsed event type: %s, isValidJSON: %v", s.ID, evt.Type, isValidJSON)
-	s.OnStreamEvent(evt)
+	if !s.onStreamEvent(evt, gen) {
+		return
+	}
 
 	if IsResultEvent(evt) {
 		log.Printf("[HeadlessSession %s] Result event received, turn completing", s.ID)
@@ -650,6 +661,87 @@ func (s *HeadlessSession) terminateDockerExecProcess(reason string) {
 
 // CancelExecution 取消当前执行
 func (s *HeadlessSession) CancelExecution() error {
+	s.cancelMu.Lock()
+	defer s.cancelMu.Unlock()
+
+	return s.cancelExecutionLocked(cancelledByUserMessage)
+}
+
+// CancelTurn 仅当 turnID 是正在执行的轮次时取消执行。
+// 重连后的客户端可能持有旧的轮次 ID，此时不会误取消更新的轮次，而是返回未取消的原因。
+func (s *HeadlessSession) CancelTurn(turnID uint) (*CancelTurnResultPayload, error) {
+	return s.cancelTurn(turnID, cancelledByUserMessage)
+}
+
+// cancelTurn 取消指定的执行中轮次，message 记录为该轮次的错误信息
+func (s *HeadlessSession) cancelTurn(turnID uint, message string) (*CancelTurnResultPayload, error) {
+	s.cancelMu.Lock()
+	defer s.cancelMu.Unlock()
+
+	result := &CancelTurnResultPayload{TurnID: turnID}
+	if s.GetState() != HeadlessStateRunning {
+		result.Reason = CancelTurnReasonNotRunning
+		return result, nil
+	}
+	if currentTurnID := s.GetCurrentTurnID(); currentTurnID != turnID {
+		result.CurrentTurnID = currentTurnID
+		result.Reason = CancelTurnReasonTurnMismatch
+		return result, nil
+	}
+
+	if err := s.cancelExecutionLocked(message); err != nil {
+		return nil, err
+	}
+	result.Cancelled = true
+	return result, nil
+}
+
+// ResetContext 丢弃 Claude 会话上下文但保留对话历史：下一轮不再 --resume，从全新上下文开始。
+// 正在执行的轮次会被中止。
+func (s *HeadlessSession) ResetContext() (*ContextResetPayload, error) {
+	s.cancelMu.Lock()
+	defer s.cancelMu.Unlock()
+
+	if s.IsClosed() {
+		return nil, fmt.Errorf("session is closed")
+	}
+
+	// 先清除会话 ID 并记录边界：中止当前轮次后排队的消息会立即开始执行，必须使用新上下文。
+	// 同时递增上下文代数，被中止进程的迟到事件不会再把旧的 session_id 写回
+	s.contextMu.Lock()
+	s.contextGen++
+	s.SetClaudeSessionID("")
+	s.contextMu.Unlock()
+	payload := &ContextResetPayload{
+		ConversationID: s.ConversationID,
+		ResetAt:        time.Now().Format(time.RFC3339),
+	}
+	if s.historyManager != nil && s.ConversationID > 0 {
+		turn, err := s.historyManager.MarkContextReset(s.ConversationID)
+		if err != nil {
+			s.logger().Printf("Failed to record context reset: %v", err)
+		} else if turn != nil {
+			payload.AfterTurnID = turn.ID
+			payload.AfterTurnIndex = turn.TurnIndex
+		}
+	}
+
+	if s.GetState() == HeadlessStateRunning {
+		if err := s.cancelExecutionLocked(cancelledByUserMessage); err != nil {
+			return nil, err
+		}
+		payload.Cancelled = true
+	}
+
+	s.logger().Printf("Claude context reset")
+	return payload, nil
+}
+
+// cancelledByUserMessage 用户取消的轮次记录的错误信息
+const cancelledByUserMessage = "Execution cancelled by user"
+
+// cancelExecutionLocked 取消执行并以 message 标记轮次失败，调用方需持有 cancelMu
+func (s *HeadlessSession) cancelExecutionLocked(message string) error {
 	if s.GetState() != HeadlessStateRunning {
 		return fmt.Errorf("session is not running")
 	}
@@ -675,7 +767,7 @@ func (s *HeadlessSession) CancelExecution() error {
 	}
 
 	// 标记轮次失败
-	s.OnTurnComplete(false, "Execution cancelled by user")
+	s.OnTurnComplete(false, message)
 
 	return nil
 }
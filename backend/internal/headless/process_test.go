@@ -0,0 +1,356 @@
+package headless
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func TestBuildClaudeArgs_WithoutSystemPrompt(t *testing.T) {
+	s := &HeadlessSession{}
+
+	got := s.buildClaudeArgs("hello")
+	want := []string{"--output-format", "stream-json", "--verbose", "--dangerously-skip-permissions", "-p", "hello"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("buildClaudeArgs() = %q, want %q", got, want)
+	}
+}
+
+// 轮次启动时读取提示词与 API 并发修改提示词不应产生数据竞争（go test -race）
+func TestBuildClaudeArgs_ConcurrentSystemPromptUpdate(t *testing.T) {
+	s := &HeadlessSession{}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			s.SetSystemPrompt("prompt")
+			s.SetExtraArgs([]string{"--max-turns", "5"})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		s.buildClaudeArgs("hello")
+	}
+	wg.Wait()
+
+	if got := s.GetSystemPrompt(); got != "prompt" {
+		t.Errorf("GetSystemPrompt() = %q, want %q", got, "prompt")
+	}
+}
+
+func TestBuildClaudeArgs_AppendsSystemPrompt(t *testing.T) {
+	s := &HeadlessSession{
+		Model:           "claude-sonnet-4-20250514",
+		ClaudeSessionID: "claude-session-1",
+		SystemPrompt:    "Always respond in Chinese.\nFollow our style guide.",
+	}
+
+	got := s.buildClaudeArgs("hello")
+	want := []string{
+		"--output-format", "stream-json",
+		"--verbose",
+		"--dangerously-skip-permissions",
+		"--model", "claude-sonnet-4-20250514",
+		"--append-system-prompt", "Always respond in Chinese.\nFollow our style guide.",
+		"--resume", "claude-session-1",
+		"-p", "hello",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("buildClaudeArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildClaudeArgs_AppendsExtraArgs(t *testing.T) {
+	s := &HeadlessSession{
+		ClaudeSessionID: "claude-session-1",
+		SystemPrompt:    "Be brief.",
+		ExtraArgs:       []string{"--max-turns", "5", "--add-dir", "/data"},
+	}
+
+	got := s.buildClaudeArgs("hello")
+	want := []string{
+		"--output-format", "stream-json",
+		"--verbose",
+		"--dangerously-skip-permissions",
+		"--append-system-prompt", "Be brief.",
+		"--max-turns", "5",
+		"--add-dir", "/data",
+		"--resume", "claude-session-1",
+		"-p", "hello",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("buildClaudeArgs() = %q, want %q", got, want)
+	}
+}
+
+// startRunningTurn 创建一个处于执行中的会话和轮次（不启动 Claude 进程）
+func startRunningTurn(t *testing.T, mgr *HeadlessManager, containerID uint) (*HeadlessSession, *models.HeadlessTurn) {
+	t.Helper()
+
+	session, err := mgr.CreateSession(containerID, "docker-cancel", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	turn, err := mgr.GetHistoryManager().StartTurn(session.ConversationID, "long task", models.HeadlessPromptSourceUser, nil)
+	if err != nil {
+		t.Fatalf("StartTurn error: %v", err)
+	}
+	session.SetCurrentTurnID(turn.ID)
+	session.SetState(HeadlessStateRunning)
+	return session, turn
+}
+
+func TestHeadlessManager_CancelTurnMatchingTurn(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, turn := startRunningTurn(t, mgr, 21)
+
+	result, err := mgr.CancelTurn(session.ID, turn.ID)
+	if err != nil {
+		t.Fatalf("CancelTurn error: %v", err)
+	}
+	if !result.Cancelled || result.Reason != "" {
+		t.Errorf("expected turn to be cancelled, got %+v", result)
+	}
+
+	stored, err := mgr.GetHistoryManager().GetTurnByID(turn.ID)
+	if err != nil {
+		t.Fatalf("GetTurnByID error: %v", err)
+	}
+	if stored.State != models.HeadlessTurnStateError {
+		t.Errorf("cancelled turn state = %q, want %q", stored.State, models.HeadlessTurnStateError)
+	}
+}
+
+func TestHeadlessManager_CancelTurnStaleTurnIsNoop(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, turn := startRunningTurn(t, mgr, 22)
+
+	// 重连的客户端持有上一轮的 ID
+	result, err := mgr.CancelTurn(session.ID, turn.ID-1)
+	if err != nil {
+		t.Fatalf("CancelTurn error: %v", err)
+	}
+	if result.Cancelled {
+		t.Fatal("a stale turn ID must not cancel the running turn")
+	}
+	if result.Reason != CancelTurnReasonTurnMismatch || result.CurrentTurnID != turn.ID {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if session.GetState() != HeadlessStateRunning || session.GetCurrentTurnID() != turn.ID {
+		t.Error("running turn should be left untouched")
+	}
+
+	stored, _ := mgr.GetHistoryManager().GetTurnByID(turn.ID)
+	if stored.State != models.HeadlessTurnStateRunning {
+		t.Errorf("turn state = %q, want running", stored.State)
+	}
+}
+
+func TestHeadlessManager_CancelTurnIdleSession(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, err := mgr.CreateSession(23, "docker-cancel", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+
+	result, err := mgr.CancelTurn(session.ID, 1)
+	if err != nil {
+		t.Fatalf("CancelTurn error: %v", err)
+	}
+	if result.Cancelled || result.Reason != CancelTurnReasonNotRunning {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	if _, err := mgr.CancelTurn("missing-session", 1); err == nil {
+		t.Error("expected error for unknown session")
+	}
+}
+
+func TestHeadlessManager_ResetContextStartsFreshContext(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, err := mgr.CreateSession(24, "docker-reset", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	history := mgr.GetHistoryManager()
+	turn, err := history.StartTurn(session.ConversationID, "remember the number 42", models.HeadlessPromptSourceUser, nil)
+	if err != nil {
+		t.Fatalf("StartTurn error: %v", err)
+	}
+	if err := history.CompleteTurn(turn.ID, "OK", "", 0, 0, 0, "", 0); err != nil {
+		t.Fatalf("CompleteTurn error: %v", err)
+	}
+	session.SetClaudeSessionID("claude-session-1")
+
+	result, err := mgr.ResetContext(session.ID)
+	if err != nil {
+		t.Fatalf("ResetContext error: %v", err)
+	}
+	if result.Cancelled || result.AfterTurnID != turn.ID || result.ConversationID != session.ConversationID {
+		t.Errorf("unexpected reset result %+v", result)
+	}
+
+	// 重置后的下一轮不能 --resume 之前的 Claude 会话
+	for _, arg := range session.buildClaudeArgs("what number?") {
+		if arg == "--resume" || arg == "claude-session-1" {
+			t.Fatalf("post-reset prompt still resumes the old context: %q", session.buildClaudeArgs("what number?"))
+		}
+	}
+	conversation, err := history.GetConversationByID(session.ConversationID)
+	if err != nil {
+		t.Fatalf("GetConversationByID error: %v", err)
+	}
+	if conversation.ClaudeSessionID != "" {
+		t.Errorf("stored claude session id = %q, want empty so restored sessions start fresh", conversation.ClaudeSessionID)
+	}
+
+	// 历史保留，并在重置前的最后一轮记录边界
+	turns, _, err := history.GetRecentTurns(session.ConversationID, 10)
+	if err != nil {
+		t.Fatalf("GetRecentTurns error: %v", err)
+	}
+	if len(turns) != 1 || turns[0].UserPrompt != "remember the number 42" || !turns[0].ContextResetAfter {
+		t.Errorf("expected the turn to be kept and marked as the reset boundary, got %+v", turns)
+	}
+}
+
+func TestHeadlessManager_ResetContextCancelsRunningTurn(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, turn := startRunningTurn(t, mgr, 25)
+	session.SetClaudeSessionID("claude-session-2")
+
+	result, err := mgr.ResetContext(session.ID)
+	if err != nil {
+		t.Fatalf("ResetContext error: %v", err)
+	}
+	if !result.Cancelled || result.AfterTurnID != turn.ID {
+		t.Errorf("expected the running turn to be cancelled and marked, got %+v", result)
+	}
+	if session.IsRunning() || session.ClaudeSessionID != "" {
+		t.Errorf("expected a stopped session without a Claude session id, got state %s id %q", session.GetState(), session.ClaudeSessionID)
+	}
+
+	// 被中止进程在重置后才读到的输出不会写回旧的 session_id，也不会完成新的轮次
+	staleGen := session.contextGeneration() - 1
+	lineCount := 0
+	session.processLine(`{"type":"result","subtype":"success","session_id":"claude-session-2"}`, &lineCount, staleGen)
+	if session.ClaudeSessionID != "" {
+		t.Errorf("stale event restored claude session id %q", session.ClaudeSessionID)
+	}
+	conversation, _ := mgr.GetHistoryManager().GetConversationByID(session.ConversationID)
+	if conversation.ClaudeSessionID != "" {
+		t.Errorf("stale event stored claude session id %q", conversation.ClaudeSessionID)
+	}
+
+	// 重置后启动的进程正常采纳新的 session_id
+	session.processLine(`{"type":"system","subtype":"init","session_id":"claude-session-3"}`, &lineCount, session.contextGeneration())
+	if session.ClaudeSessionID != "claude-session-3" {
+		t.Errorf("expected the new context's session id, got %q", session.ClaudeSessionID)
+	}
+
+	if _, err := mgr.ResetContext("missing-session"); err == nil {
+		t.Error("expected error for unknown session")
+	}
+}
+
+func TestHeadlessManager_CompactRecordsCompactionTurn(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, turn := startRunningTurn(t, mgr, 26)
+	if err := mgr.Compact(session.ID); !errors.Is(err, ErrNothingToCompact) {
+		t.Fatalf("expected ErrNothingToCompact before the first turn finished, got %v", err)
+	}
+	session.SetClaudeSessionID("claude-session-3")
+
+	// 会话忙时压缩请求排队
+	if err := mgr.Compact(session.ID); err != nil {
+		t.Fatalf("Compact error: %v", err)
+	}
+	history := mgr.GetHistoryManager()
+	if err := history.CompleteTurn(turn.ID, "done", "", 1200, 80, 0, "", 0); err != nil {
+		t.Fatalf("CompleteTurn error: %v", err)
+	}
+	compactTurn, err := history.PopNextPendingTurn(session.ConversationID)
+	if err != nil {
+		t.Fatalf("PopNextPendingTurn error: %v", err)
+	}
+	if compactTurn.UserPrompt != CompactPrompt || compactTurn.PromptSource != models.HeadlessPromptSourceCompact {
+		t.Fatalf("expected a queued compact turn, got %+v", compactTurn)
+	}
+
+	// 模拟 Claude 的压缩输出：compact_boundary 报告压缩前 token 数，result 的输出即摘要
+	session.SetCurrentTurnID(compactTurn.ID)
+	session.SetState(HeadlessStateRunning)
+	for _, line := range []string{
+		`{"type":"system","subtype":"compact_boundary","session_id":"claude-session-3","compact_metadata":{"trigger":"manual","pre_tokens":45000}}`,
+		`{"type":"result","subtype":"success","session_id":"claude-session-3","usage":{"input_tokens":12,"output_tokens":900}}`,
+	} {
+		evt, ok := ParseStreamLine(line)
+		if !ok {
+			t.Fatalf("failed to parse %s", line)
+		}
+		session.OnStreamEvent(evt)
+	}
+	session.OnTurnComplete(true, "")
+
+	recorded, err := history.GetTurnByID(compactTurn.ID)
+	if err != nil {
+		t.Fatalf("GetTurnByID error: %v", err)
+	}
+	if recorded.State != models.HeadlessTurnStateCompleted || recorded.ContextTokensBefore != 45000 || recorded.ContextTokensAfter != 900 {
+		t.Errorf("expected a completed compaction turn 45000 -> 900 tokens, got %+v", recorded)
+	}
+
+	// compact_boundary 没有报告时使用上一轮的输入 token 数
+	if err := history.RecordCompaction(compactTurn.ID, 0, 900); err != nil {
+		t.Fatalf("RecordCompaction error: %v", err)
+	}
+	recorded, _ = history.GetTurnByID(compactTurn.ID)
+	if recorded.ContextTokensBefore != 1200 {
+		t.Errorf("ContextTokensBefore = %d, want 1200 from the previous turn", recorded.ContextTokensBefore)
+	}
+
+	// 普通轮次不记录压缩信息
+	if err := history.RecordCompaction(turn.ID, 100, 10); err != nil {
+		t.Fatalf("RecordCompaction error: %v", err)
+	}
+	if plain, _ := history.GetTurnByID(turn.ID); plain.ContextTokensBefore != 0 || plain.ContextTokensAfter != 0 {
+		t.Errorf("expected no compaction data on a user turn, got %+v", plain)
+	}
+}
+
+func TestCompactionSupported(t *testing.T) {
+	for model, want := range map[string]bool{
+		"":                         true,
+		"sonnet":                   true,
+		"claude-sonnet-4-20250514": true,
+		"Claude-Opus-4-1":          true,
+		"deepseek-chat":            false,
+		"gpt-4o":                   false,
+	} {
+		if got := CompactionSupported(model); got != want {
+			t.Errorf("CompactionSupported(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
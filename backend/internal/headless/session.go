@@ -5,12 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"os/exec"
 	"strings"
 	"sync"
 	"time"
 
+	"cc-platform/internal/logging"
 	"cc-platform/internal/monitoring"
 
 	"github.com/docker/docker/api/types"
@@ -19,13 +19,18 @@ import (
 
 // HeadlessSession 表示一个 Headless 模式的 Claude 会话
 type HeadlessSession struct {
-	ID              string // 会话唯一标识（由后端生成）
-	ContainerID     uint   // 关联的容器 ID
-	DockerID        string // Docker 容器 ID
-	ClaudeSessionID string // Claude 返回的 session_id（用于 --resume）
-	WorkDir         string // 工作目录
-	ConversationID  uint   // 数据库中的对话 ID
-	Model           string // 模型名称（如 claude-sonnet-4-20250514）
+	ID              string   // 会话唯一标识（由后端生成）
+	ContainerID     uint     // 关联的容器 ID
+	DockerID        string   // Docker 容器 ID
+	ClaudeSessionID string   // Claude 返回的 session_id（用于 --resume）
+	WorkDir         string   // 工作目录
+	ConversationID  uint     // 数据库中的对话 ID
+	Model           string   // 模型名称（如 claude-sonnet-4-20250514）
+	SystemPrompt    string   // 对话级系统提示词（追加在 Claude Code 默认系统提示词之后，受 cliMu 保护）
+	ExtraArgs       []string // 透传给 Claude CLI 的额外参数（已按白名单校验，受 cliMu 保护）
+
+	// 对话设置可能在轮次启动时被并发修改，cliMu 保护 SystemPrompt 和 ExtraArgs
+	cliMu sync.RWMutex
 
 	// 进程管理 (exec.Command 方式，保留兼容)
 	cmd    *exec.Cmd      // Claude 进程
@@ -47,6 +52,17 @@ type HeadlessSession struct {
 	CurrentTurnID uint // 当前轮次 ID
 	turnMu        sync.RWMutex
 
+	// 串行化取消操作，避免检查当前轮次与取消之间被其他取消打断
+	cancelMu sync.Mutex
+
+	// Claude 上下文代数，ResetContext 时递增；进程输出按启动时的代数处理，重置前的迟到事件被丢弃。
+	// contextMu 保护代数，并使代数检查与 session_id 的采纳不可分割
+	contextGen uint64
+	contextMu  sync.Mutex
+
+	// 当前轮次 compact_boundary 事件报告的压缩前 token 数（受 turnMu 保护）
+	compactPreTokens int
+
 	// 输出通道
 	OutputChan chan *StreamEvent // 解析后的事件流
 	DoneChan   chan struct{}     // 进程结束信号
@@ -55,6 +71,17 @@ type HeadlessSession struct {
 	clients   map[string]chan *StreamEvent
 	clientsMu sync.RWMutex
 
+	// 客户端全部断开时取消执行中轮次的宽限期和判断（由管理器设置），等待中的计时器及其代数受 clientsMu 保护
+	disconnectGrace    time.Duration
+	cancelOnDisconnect func() bool
+	disconnectTimer    *time.Timer
+	disconnectGen      uint64
+
+	// 事件序号和最近事件缓冲，断线重连时按 last_seq 补发（eventsMu 同时串行化广播）
+	eventSeq     uint64
+	recentEvents []*StreamEvent
+	eventsMu     sync.Mutex
+
 	// 上下文
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -68,6 +95,16 @@ type HeadlessSession struct {
 	// 响应聚合
 	responseBuilder *ResponseBuilder
 
+	// 定价表，stream 未上报费用时用于计算轮次费用
+	pricing *PricingTable
+
+	// 平台级强制设置（由管理器在创建时设置）
+	overrides PlatformOverrides
+
+	// 停止条件：每轮结束时检查，触发后通知客户端并调用 onStop 关闭会话（由管理器设置）
+	checkStop func() (reason, message string)
+	onStop    func(message string)
+
 	// 创建时间
 	CreatedAt time.Time
 	// 最后活跃时间
@@ -75,12 +112,16 @@ type HeadlessSession struct {
 	lastActiveMu sync.RWMutex
 }
 
+// eventReplayBufferSize 每个会话保留的最近事件数，用于断线重连补发
+const eventReplayBufferSize = 1000
+
 // ResponseBuilder 用于聚合流式响应
 type ResponseBuilder struct {
 	texts        []string
 	model        string
 	inputTokens  int
 	outputTokens int
+	reportedCost float64 // result 事件上报的费用，0 表示未上报
 	startTime    time.Time
 	mu           sync.Mutex
 }
@@ -120,6 +161,34 @@ func (rb *ResponseBuilder) UpdateUsage(usage *UsageInfo) {
 	rb.outputTokens = usage.OutputTokens
 }
 
+// SetReportedCost 记录 result 事件上报的费用
+func (rb *ResponseBuilder) SetReportedCost(evt *StreamEvent) {
+	cost := evt.TotalCost
+	if cost <= 0 {
+		cost = evt.Cost
+	}
+	if cost <= 0 {
+		return
+	}
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	rb.reportedCost = cost
+}
+
+// ReportedCost 返回上报的费用，0 表示未上报
+func (rb *ResponseBuilder) ReportedCost() float64 {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.reportedCost
+}
+
+// StartedAt 返回构建器最近一次重置（即当前轮次开始）的时间
+func (rb *ResponseBuilder) StartedAt() time.Time {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.startTime
+}
+
 // Build 构建最终响应
 func (rb *ResponseBuilder) Build() (response string, model string, inputTokens, outputTokens int, durationMS int64) {
 	rb.mu.Lock()
@@ -148,6 +217,7 @@ func (rb *ResponseBuilder) Reset() {
 	rb.model = ""
 	rb.inputTokens = 0
 	rb.outputTokens = 0
+	rb.reportedCost = 0
 	rb.startTime = time.Now()
 }
 
@@ -179,6 +249,13 @@ func NewHeadlessSession(
 	}
 }
 
+// logger 返回带 session_id 和 container_id 字段的结构化日志器
+func (s *HeadlessSession) logger() logging.Logger {
+	return logging.New("HeadlessSession").
+		With(logging.KeySessionID, s.ID).
+		With(logging.KeyContainerID, s.ContainerID)
+}
+
 // GetState 获取会话状态
 func (s *HeadlessSession) GetState() HeadlessState {
 	s.stateMu.RLock()
@@ -191,7 +268,7 @@ func (s *HeadlessSession) SetState(state HeadlessState) {
 	s.stateMu.Lock()
 	defer s.stateMu.Unlock()
 	s.State = state
-	log.Printf("[HeadlessSession %s] State changed to: %s", s.ID, state)
+	s.logger().Printf("State changed to: %s", state)
 }
 
 // GetCurrentTurnID 获取当前轮次 ID
@@ -230,7 +307,8 @@ func (s *HeadlessSession) AddClient(clientID string) chan *StreamEvent {
 	// 创建带缓冲的 channel
 	ch := make(chan *StreamEvent, 100)
 	s.clients[clientID] = ch
-	log.Printf("[HeadlessSession %s] Client added: %s, total clients: %d", s.ID, clientID, len(s.clients))
+	s.stopDisconnectCancelLocked()
+	s.logger().Printf("Client added: %s, total clients: %d", clientID, len(s.clients))
 	return ch
 }
 
@@ -242,7 +320,10 @@ func (s *HeadlessSession) RemoveClient(clientID string) {
 	if ch, ok := s.clients[clientID]; ok {
 		close(ch)
 		delete(s.clients, clientID)
-		log.Printf("[HeadlessSession %s] Client removed: %s, remaining clients: %d", s.ID, clientID, len(s.clients))
+		s.logger().Printf("Client removed: %s, remaining clients: %d", clientID, len(s.clients))
+		if len(s.clients) == 0 {
+			s.scheduleDisconnectCancelLocked()
+		}
 	}
 }
 
@@ -253,8 +334,52 @@ func (s *HeadlessSession) GetClientCount() int {
 	return len(s.clients)
 }
 
-// broadcastToClients 广播事件到所有客户端
+// AddClientAfter 断线重连时添加客户端订阅，并返回序号大于 lastSeq 的已广播事件。
+// 缓冲已不包含 lastSeq 之后的全部事件（或 lastSeq 不属于本会话）时不添加订阅并返回 false，
+// 调用方应退回到完整的历史加载。补发事件与订阅在同一把锁下获取，不会遗漏或重复。
+func (s *HeadlessSession) AddClientAfter(clientID string, lastSeq uint64) (chan *StreamEvent, []*StreamEvent, bool) {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	if lastSeq > s.eventSeq {
+		return nil, nil, false
+	}
+	oldest := s.eventSeq + 1
+	if len(s.recentEvents) > 0 {
+		oldest = s.recentEvents[0].Seq
+	}
+	if lastSeq+1 < oldest {
+		return nil, nil, false
+	}
+
+	replay := make([]*StreamEvent, 0, s.eventSeq-lastSeq)
+	for _, evt := range s.recentEvents {
+		if evt.Seq > lastSeq {
+			replay = append(replay, evt)
+		}
+	}
+	return s.AddClient(clientID), replay, true
+}
+
+// LastEventSeq 返回已广播的最后一个事件序号
+func (s *HeadlessSession) LastEventSeq() uint64 {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+	return s.eventSeq
+}
+
+// broadcastToClients 为事件分配序号、放入重连缓冲并广播到所有客户端
 func (s *HeadlessSession) broadcastToClients(event *StreamEvent) {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	s.eventSeq++
+	event.Seq = s.eventSeq
+	s.recentEvents = append(s.recentEvents, event)
+	if len(s.recentEvents) > eventReplayBufferSize {
+		s.recentEvents = append(s.recentEvents[:0:0], s.recentEvents[len(s.recentEvents)-eventReplayBufferSize:]...)
+	}
+
 	s.clientsMu.RLock()
 	defer s.clientsMu.RUnlock()
 
@@ -264,7 +389,7 @@ func (s *HeadlessSession) broadcastToClients(event *StreamEvent) {
 		case ch <- event:
 			timer.Stop()
 		case <-timer.C:
-			log.Printf("[HeadlessSession %s] Client %s channel blocked, dropping event", s.ID, clientID)
+			s.logger().Printf("Client %s channel blocked, dropping event", clientID)
 		}
 	}
 }
@@ -274,6 +399,34 @@ func (s *HeadlessSession) SetConversationID(conversationID uint) {
 	s.ConversationID = conversationID
 }
 
+// GetSystemPrompt 获取对话级系统提示词
+func (s *HeadlessSession) GetSystemPrompt() string {
+	s.cliMu.RLock()
+	defer s.cliMu.RUnlock()
+	return s.SystemPrompt
+}
+
+// SetSystemPrompt 设置对话级系统提示词，从下一轮开始生效
+func (s *HeadlessSession) SetSystemPrompt(systemPrompt string) {
+	s.cliMu.Lock()
+	defer s.cliMu.Unlock()
+	s.SystemPrompt = systemPrompt
+}
+
+// GetExtraArgs 获取额外 CLI 参数
+func (s *HeadlessSession) GetExtraArgs() []string {
+	s.cliMu.RLock()
+	defer s.cliMu.RUnlock()
+	return s.ExtraArgs
+}
+
+// SetExtraArgs 设置额外 CLI 参数，从下一轮开始生效
+func (s *HeadlessSession) SetExtraArgs(extraArgs []string) {
+	s.cliMu.Lock()
+	defer s.cliMu.Unlock()
+	s.ExtraArgs = extraArgs
+}
+
 // SetClaudeSessionID 设置 Claude 会话 ID
 func (s *HeadlessSession) SetClaudeSessionID(claudeSessionID string) {
 	s.ClaudeSessionID = claudeSessionID
@@ -281,7 +434,7 @@ func (s *HeadlessSession) SetClaudeSessionID(claudeSessionID string) {
 	// 更新数据库
 	if s.historyManager != nil && s.ConversationID > 0 {
 		if err := s.historyManager.UpdateClaudeSessionID(s.ConversationID, claudeSessionID); err != nil {
-			log.Printf("[HeadlessSession %s] Failed to update claude session id: %v", s.ID, err)
+			s.logger().Printf("Failed to update claude session id: %v", err)
 		}
 	}
 }
@@ -298,7 +451,7 @@ func (s *HeadlessSession) GetMonitoringSession() *monitoring.MonitoringSession {
 
 // Close 关闭会话
 func (s *HeadlessSession) Close() error {
-	log.Printf("[HeadlessSession %s] Closing session", s.ID)
+	s.logger().Printf("Closing session")
 
 	// 取消上下文
 	s.cancel()
@@ -322,7 +475,7 @@ func (s *HeadlessSession) Close() error {
 	// 终止进程 (exec.Command 方式)
 	if s.cmd != nil && s.cmd.Process != nil {
 		if err := s.cmd.Process.Kill(); err != nil {
-			log.Printf("[HeadlessSession %s] Failed to kill process: %v", s.ID, err)
+			s.logger().Printf("Failed to kill process: %v", err)
 		}
 	}
 
@@ -361,7 +514,7 @@ func (s *HeadlessSession) Close() error {
 		s.historyManager.CloseConversation(s.ConversationID)
 	}
 
-	log.Printf("[HeadlessSession %s] Session closed", s.ID)
+	s.logger().Printf("Session closed")
 	return nil
 }
 
@@ -382,24 +535,59 @@ func (s *HeadlessSession) IsClosed() bool {
 
 // GetSessionInfo 获取会话信息
 func (s *HeadlessSession) GetSessionInfo() *SessionInfoPayload {
-	return &SessionInfoPayload{
-		SessionID:       s.ID,
-		ClaudeSessionID: s.ClaudeSessionID,
-		State:           s.GetState(),
-		ConversationID:  s.ConversationID,
-		CurrentTurnID:   s.GetCurrentTurnID(),
+	info := &SessionInfoPayload{
+		SessionID:        s.ID,
+		ClaudeSessionID:  s.ClaudeSessionID,
+		State:            s.GetState(),
+		ConversationID:   s.ConversationID,
+		CurrentTurnID:    s.GetCurrentTurnID(),
+		SystemPrompt:     s.GetSystemPrompt(),
+		ExtraArgs:        s.GetExtraArgs(),
+		CompactSupported: CompactionSupported(s.Model),
+		LastSeq:          s.LastEventSeq(),
+		Model:            s.Model,
+		ModelForced:      s.overrides.Model != "",
+	}
+	if s.historyManager != nil {
+		if conv, err := s.historyManager.GetConversationByID(s.ConversationID); err == nil && conv != nil {
+			info.MaxTurns, info.MaxDurationMinutes = s.overrides.capLimits(conv.MaxTurns, conv.MaxDurationMinutes)
+		}
 	}
+	if s.cancelOnDisconnect != nil {
+		info.CancelOnDisconnect = s.cancelOnDisconnect()
+	}
+	return info
+}
+
+// contextGeneration 返回当前 Claude 上下文代数
+func (s *HeadlessSession) contextGeneration() uint64 {
+	s.contextMu.Lock()
+	defer s.contextMu.Unlock()
+	return s.contextGen
 }
 
-// OnStreamEvent 处理流式事件
+// OnStreamEvent 处理当前上下文的流式事件
 func (s *HeadlessSession) OnStreamEvent(evt *StreamEvent) {
-	// 更新最后活跃时间
-	s.UpdateLastActive()
+	s.onStreamEvent(evt, s.contextGeneration())
+}
 
+// onStreamEvent 处理属于第 gen 代上下文的流式事件，返回事件是否被处理。
+// ResetContext 之后旧进程的迟到事件被丢弃，避免把旧的 session_id 写回
+func (s *HeadlessSession) onStreamEvent(evt *StreamEvent, gen uint64) bool {
+	s.contextMu.Lock()
+	if gen != s.contextGen {
+		s.contextMu.Unlock()
+		s.logger().Printf("Dropping %s event from before the context reset", evt.Type)
+		return false
+	}
 	// 提取 session_id（首轮）
 	if s.ClaudeSessionID == "" && evt.SessionID != "" {
 		s.SetClaudeSessionID(evt.SessionID)
 	}
+	s.contextMu.Unlock()
+
+	// 更新最后活跃时间
+	s.UpdateLastActive()
 
 	// 更新模型和 usage
 	if evt.Model != "" {
@@ -408,6 +596,16 @@ func (s *HeadlessSession) OnStreamEvent(evt *StreamEvent) {
 	if usage := ExtractUsageInfo(evt); usage != nil {
 		s.responseBuilder.UpdateUsage(usage)
 	}
+	if evt.Type == StreamEventTypeResult {
+		s.responseBuilder.SetReportedCost(evt)
+	}
+
+	// 记录压缩边界报告的压缩前 token 数
+	if IsCompactBoundaryEvent(evt) {
+		s.turnMu.Lock()
+		s.compactPreTokens = evt.CompactMetadata.PreTokens
+		s.turnMu.Unlock()
+	}
 
 	// 只从 assistant 类型的事件中提取文本内容
 	if evt.Type == StreamEventTypeAssistant {
@@ -416,6 +614,9 @@ func (s *HeadlessSession) OnStreamEvent(evt *StreamEvent) {
 		}
 	}
 
+	// 广播到客户端（同时分配事件序号）
+	s.broadcastToClients(evt)
+
 	// 持久化事件
 	if s.historyManager != nil && s.GetCurrentTurnID() > 0 {
 		if err := s.historyManager.AppendEvent(
@@ -423,36 +624,39 @@ func (s *HeadlessSession) OnStreamEvent(evt *StreamEvent) {
 			evt.Type,
 			evt.Subtype,
 			evt.Raw,
+			evt.Seq,
 		); err != nil {
-			log.Printf("[HeadlessSession %s] Failed to append event: %v", s.ID, err)
+			s.logger().Printf("Failed to append event: %v", err)
 		}
 	}
 
-	// 广播到客户端
-	s.broadcastToClients(evt)
-
 	// 触发监控回调
 	if s.monitoringSession != nil {
 		// 将事件序列化为文本，更新上下文缓冲区
 		s.monitoringSession.OnOutput([]byte(evt.Raw))
 	}
+	return true
 }
 
 // OnTurnComplete 轮次完成处理
 func (s *HeadlessSession) OnTurnComplete(success bool, errorMsg string) {
 	turnID := s.GetCurrentTurnID()
-	log.Printf("[HeadlessSession %s] OnTurnComplete called: success=%v, errorMsg=%s, turnID=%d", s.ID, success, errorMsg, turnID)
+	s.logger().Printf("OnTurnComplete called: success=%v, errorMsg=%s, turnID=%d", success, errorMsg, turnID)
 
 	if turnID == 0 {
-		log.Printf("[HeadlessSession %s] OnTurnComplete: turnID is 0, skipping", s.ID)
+		s.logger().Printf("OnTurnComplete: turnID is 0, skipping")
 		return
 	}
 
 	// 构建响应
 	response, model, inputTokens, outputTokens, durationMS := s.responseBuilder.Build()
+	s.turnMu.Lock()
+	compactPreTokens := s.compactPreTokens
+	s.compactPreTokens = 0
+	s.turnMu.Unlock()
 
-	// 计算费用（简化计算，实际应根据模型定价）
-	costUSD := float64(inputTokens)*0.000003 + float64(outputTokens)*0.000015
+	// 费用优先使用 stream 上报的值，未上报时按定价表计算
+	costUSD, costSource := s.pricing.TurnCost(model, inputTokens, outputTokens, s.responseBuilder.ReportedCost())
 
 	if s.historyManager != nil {
 		if success {
@@ -463,13 +667,18 @@ func (s *HeadlessSession) OnTurnComplete(success bool, errorMsg string) {
 				inputTokens,
 				outputTokens,
 				costUSD,
+				costSource,
 				durationMS,
 			); err != nil {
-				log.Printf("[HeadlessSession %s] Failed to complete turn: %v", s.ID, err)
+				s.logger().Printf("Failed to complete turn: %v", err)
+			}
+			// 压缩轮次：摘要即压缩后的上下文
+			if err := s.historyManager.RecordCompaction(turnID, compactPreTokens, outputTokens); err != nil {
+				s.logger().Printf("Failed to record compaction: %v", err)
 			}
 		} else {
 			if err := s.historyManager.FailTurn(turnID, errorMsg); err != nil {
-				log.Printf("[HeadlessSession %s] Failed to fail turn: %v", s.ID, err)
+				s.logger().Printf("Failed to fail turn: %v", err)
 			}
 		}
 	}
@@ -497,6 +706,7 @@ func (s *HeadlessSession) OnTurnComplete(success bool, errorMsg string) {
 		InputTokens:  inputTokens,
 		OutputTokens: outputTokens,
 		CostUSD:      costUSD,
+		CostSource:   costSource,
 		DurationMS:   durationMS,
 		State:        state,
 		ErrorMessage: errorMsg,
@@ -523,12 +733,20 @@ func (s *HeadlessSession) OnTurnComplete(success bool, errorMsg string) {
 		completeEvent.Result = fmt.Sprintf("%+v", completePayload)
 	}
 
-	log.Printf("[HeadlessSession %s] Broadcasting turn_complete event: turnID=%d, state=%s", s.ID, turnID, completePayload.State)
+	s.logger().Printf("Broadcasting turn_complete event: turnID=%d, state=%s", turnID, completePayload.State)
 	s.broadcastToClients(completeEvent)
 
 	// 重置 CurrentTurnID，防止重复触发
 	s.SetCurrentTurnID(0)
 
+	// 达到停止条件时不再执行排队的消息，直接关闭会话
+	if s.checkStop != nil {
+		if reason, message := s.checkStop(); reason != "" {
+			s.stopForLimit(reason, message)
+			return
+		}
+	}
+
 	// 自动执行队列中的下一个消息
 	go s.ProcessNextQueuedTurn()
 }
@@ -540,18 +758,19 @@ func (s *HeadlessSession) BroadcastQueueUpdate(hm *HeadlessHistoryManager) {
 	}
 	pendingTurns, err := hm.GetPendingTurns(s.ConversationID)
 	if err != nil {
-		log.Printf("[HeadlessSession %s] Failed to get pending turns for broadcast: %v", s.ID, err)
+		s.logger().Printf("Failed to get pending turns for broadcast: %v", err)
 		return
 	}
 
 	queuedInfos := make([]QueuedTurnInfo, len(pendingTurns))
 	for i, turn := range pendingTurns {
 		queuedInfos[i] = QueuedTurnInfo{
-			TurnID:    turn.ID,
-			TurnIndex: turn.TurnIndex,
-			Prompt:    turn.UserPrompt,
-			Source:    turn.PromptSource,
-			State:     turn.State,
+			TurnID:        turn.ID,
+			TurnIndex:     turn.TurnIndex,
+			Prompt:        turn.UserPrompt,
+			Source:        turn.PromptSource,
+			AttachedFiles: turn.AttachedFiles,
+			State:         turn.State,
 		}
 	}
 
@@ -585,7 +804,7 @@ func (s *HeadlessSession) ProcessNextQueuedTurn() {
 
 	nextTurn, err := s.historyManager.PopNextPendingTurn(s.ConversationID)
 	if err != nil {
-		log.Printf("[HeadlessSession %s] Failed to pop next queued turn: %v", s.ID, err)
+		s.logger().Printf("Failed to pop next queued turn: %v", err)
 		return
 	}
 	if nextTurn == nil {
@@ -594,7 +813,7 @@ func (s *HeadlessSession) ProcessNextQueuedTurn() {
 		return
 	}
 
-	log.Printf("[HeadlessSession %s] Auto-dequeuing turn %d: %s", s.ID, nextTurn.ID, nextTurn.UserPrompt)
+	s.logger().Printf("Auto-dequeuing turn %d: %s", nextTurn.ID, nextTurn.UserPrompt)
 
 	s.SetCurrentTurnID(nextTurn.ID)
 	s.responseBuilder.Reset()
@@ -606,8 +825,8 @@ func (s *HeadlessSession) ProcessNextQueuedTurn() {
 
 	// 启动 Claude 进程
 	ctx := context.Background()
-	if err := s.StartClaudeProcess(ctx, nextTurn.UserPrompt); err != nil {
-		log.Printf("[HeadlessSession %s] Failed to start claude for queued turn %d: %v", s.ID, nextTurn.ID, err)
+	if err := s.StartClaudeProcess(ctx, BuildPromptWithFiles(nextTurn.UserPrompt, nextTurn.AttachedFiles)); err != nil {
+		s.logger().Printf("Failed to start claude for queued turn %d: %v", nextTurn.ID, err)
 		s.historyManager.FailTurn(nextTurn.ID, err.Error())
 		s.SetState(HeadlessStateError)
 		s.SetCurrentTurnID(0)
@@ -0,0 +1,114 @@
+package headless
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// streamTestEvent 模拟一条 assistant 输出
+func streamTestEvent(i int) *StreamEvent {
+	raw := fmt.Sprintf(`{"type":"assistant","n":%d}`, i)
+	return &StreamEvent{Type: StreamEventTypeAssistant, Raw: raw}
+}
+
+// receiveSeqs 从订阅 channel 读取 n 个事件的序号
+func receiveSeqs(t *testing.T, ch chan *StreamEvent, n int) []uint64 {
+	t.Helper()
+	seqs := make([]uint64, 0, n)
+	for len(seqs) < n {
+		select {
+		case evt := <-ch:
+			seqs = append(seqs, evt.Seq)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out after %d of %d events", len(seqs), n)
+		}
+	}
+	return seqs
+}
+
+func TestHeadlessSession_ReconnectWithLastSeqReplaysMissedEvents(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, turn := startRunningTurn(t, mgr, 60)
+	first := session.AddClient("client-1")
+	for i := 1; i <= 3; i++ {
+		session.OnStreamEvent(streamTestEvent(i))
+	}
+	received := receiveSeqs(t, first, 3)
+	if fmt.Sprint(received) != "[1 2 3]" {
+		t.Fatalf("expected sequences [1 2 3], got %v", received)
+	}
+	lastSeq := received[len(received)-1]
+
+	// 连接断开期间的事件（包括元事件）只进入缓冲
+	session.RemoveClient("client-1")
+	session.OnStreamEvent(streamTestEvent(4))
+	session.BroadcastQueueUpdate(mgr.GetHistoryManager())
+	session.OnStreamEvent(streamTestEvent(5))
+
+	ch, replay, ok := session.AddClientAfter("client-2", lastSeq)
+	if !ok {
+		t.Fatalf("expected the buffer to cover last_seq %d", lastSeq)
+	}
+	var replayed []uint64
+	for _, evt := range replay {
+		replayed = append(replayed, evt.Seq)
+	}
+	if fmt.Sprint(replayed) != "[4 5 6]" || replay[1].Type != "queue_update" {
+		t.Fatalf("expected to replay [4 5 6] with the queue update second, got %v", replayed)
+	}
+
+	// 重连后的事件从订阅 channel 到达，且不与补发重复
+	session.OnStreamEvent(streamTestEvent(6))
+	if live := receiveSeqs(t, ch, 1); live[0] != 7 {
+		t.Errorf("expected the next live event to be 7, got %v", live)
+	}
+	if got := session.GetSessionInfo().LastSeq; got != 7 {
+		t.Errorf("session info last_seq = %d, want 7", got)
+	}
+
+	// 持久化的事件带有相同的序号，供完整历史加载时去重
+	events, err := mgr.GetHistoryManager().GetTurnEvents(turn.ID)
+	if err != nil {
+		t.Fatalf("GetTurnEvents error: %v", err)
+	}
+	var persisted []uint64
+	for _, e := range events {
+		persisted = append(persisted, e.Seq)
+	}
+	if fmt.Sprint(persisted) != "[1 2 3 4 6 7]" {
+		t.Errorf("expected persisted sequences [1 2 3 4 6 7], got %v", persisted)
+	}
+}
+
+func TestHeadlessSession_ReconnectFallsBackWhenBufferMissesEvents(t *testing.T) {
+	session := NewHeadlessSession("seq-session", 1, "docker", "/app", nil)
+
+	if _, replay, ok := session.AddClientAfter("fresh", 0); !ok || len(replay) != 0 {
+		t.Fatalf("expected an empty replay for a session without events, got %v, %v", replay, ok)
+	}
+	session.RemoveClient("fresh")
+
+	for i := 0; i < eventReplayBufferSize+5; i++ {
+		session.broadcastToClients(streamTestEvent(i))
+	}
+
+	// 最早的 5 个事件已被挤出缓冲，last_seq=4 之后的事件无法完整补发
+	if _, _, ok := session.AddClientAfter("stale", 4); ok {
+		t.Errorf("expected reconnect from seq 4 to fall back to full history")
+	}
+	_, replay, ok := session.AddClientAfter("recent", 5)
+	if !ok || len(replay) != eventReplayBufferSize || replay[0].Seq != 6 {
+		t.Errorf("expected to replay the whole buffer from seq 6, got %d events (ok=%v)", len(replay), ok)
+	}
+	// 来自其他会话的更大序号
+	if _, _, ok := session.AddClientAfter("other", uint64(eventReplayBufferSize+100)); ok {
+		t.Errorf("expected a last_seq beyond the session's events to fall back")
+	}
+	if session.GetClientCount() != 1 {
+		t.Errorf("expected only the resumed client to be subscribed, got %d", session.GetClientCount())
+	}
+}
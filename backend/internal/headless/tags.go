@@ -0,0 +1,62 @@
+package headless
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"cc-platform/internal/constants"
+)
+
+// ErrInvalidTag 标签不合法（为空、过长、包含空白或逗号，或数量超过限制）
+var ErrInvalidTag = errors.New("invalid tag")
+
+// NormalizeConversationTags 校验并规范化对话标签：去除首尾空白、转为小写、去重并保持原有顺序
+func NormalizeConversationTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, fmt.Errorf("%w: empty tag", ErrInvalidTag)
+		}
+		if utf8.RuneCountInString(tag) > constants.HeadlessConversationTagMaxLength {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTag, tag, constants.HeadlessConversationTagMaxLength)
+		}
+		if strings.ContainsFunc(tag, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) || r == ',' }) {
+			return nil, fmt.Errorf("%w: %q must not contain whitespace or commas", ErrInvalidTag, tag)
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > constants.HeadlessMaxConversationTags {
+		return nil, fmt.Errorf("%w: %d tags (max %d)", ErrInvalidTag, len(normalized), constants.HeadlessMaxConversationTags)
+	}
+	return normalized, nil
+}
+
+// SetConversationTags 替换对话的标签（空列表表示清除），返回规范化后的标签
+func (m *HeadlessManager) SetConversationTags(conversationID uint, tags []string) ([]string, error) {
+	normalized, err := NormalizeConversationTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, err := m.historyManager.GetConversationByID(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation == nil {
+		return nil, ErrConversationNotFound
+	}
+
+	if err := m.historyManager.UpdateConversationTags(conversationID, normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
@@ -0,0 +1,90 @@
+package headless
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/constants"
+)
+
+func TestNormalizeConversationTags(t *testing.T) {
+	got, err := NormalizeConversationTags([]string{" Bugfix ", "review", "bugfix", "前端"})
+	if err != nil {
+		t.Fatalf("NormalizeConversationTags error: %v", err)
+	}
+	if want := []string{"bugfix", "review", "前端"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeConversationTags() = %q, want %q", got, want)
+	}
+
+	tooMany := make([]string, constants.HeadlessMaxConversationTags+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag%d", i)
+	}
+	for name, tags := range map[string][]string{
+		"empty":      {"  "},
+		"whitespace": {"two words"},
+		"comma":      {"a,b"},
+		"too long":   {strings.Repeat("x", constants.HeadlessConversationTagMaxLength+1)},
+		"too many":   tooMany,
+	} {
+		if _, err := NormalizeConversationTags(tags); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("%s: expected ErrInvalidTag, got %v", name, err)
+		}
+	}
+}
+
+func TestHeadlessManager_ConversationTagsFilter(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+	history := mgr.GetHistoryManager()
+
+	tagged, _ := history.CreateConversation("tags-1", 80)
+	other, _ := history.CreateConversation("tags-2", 80)
+	history.CreateConversation("tags-3", 80)
+	elsewhere, _ := history.CreateConversation("tags-4", 81)
+
+	if tags, err := mgr.SetConversationTags(tagged.ID, []string{"Bugfix", "review"}); err != nil || !reflect.DeepEqual(tags, []string{"bugfix", "review"}) {
+		t.Fatalf("SetConversationTags = %q, %v", tags, err)
+	}
+	if _, err := mgr.SetConversationTags(other.ID, []string{"review"}); err != nil {
+		t.Fatalf("SetConversationTags error: %v", err)
+	}
+	if _, err := mgr.SetConversationTags(elsewhere.ID, []string{"bugfix", "infra"}); err != nil {
+		t.Fatalf("SetConversationTags error: %v", err)
+	}
+	if _, err := mgr.SetConversationTags(99999, []string{"x"}); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("expected ErrConversationNotFound, got %v", err)
+	}
+
+	// 标签筛选只作用于当前容器，且大小写不敏感
+	list, err := history.ListConversationsForContainer(80, ConversationFilter{Tag: "BUGFIX"})
+	if err != nil {
+		t.Fatalf("ListConversationsForContainer error: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != tagged.ID {
+		t.Errorf("expected only the tagged conversation, got %+v", list)
+	}
+	if list, _ := history.ListConversationsForContainer(80, ConversationFilter{}); len(list) != 3 {
+		t.Errorf("expected all 3 conversations without a filter, got %d", len(list))
+	}
+
+	tags, err := history.ListConversationTags(80)
+	if err != nil {
+		t.Fatalf("ListConversationTags error: %v", err)
+	}
+	if want := []string{"bugfix", "review"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("ListConversationTags() = %q, want %q", tags, want)
+	}
+
+	// 清除标签
+	if _, err := mgr.SetConversationTags(tagged.ID, nil); err != nil {
+		t.Fatalf("SetConversationTags error: %v", err)
+	}
+	if list, _ := history.ListConversationsForContainer(80, ConversationFilter{Tag: "bugfix"}); len(list) != 0 {
+		t.Errorf("expected no conversations after clearing tags, got %+v", list)
+	}
+}
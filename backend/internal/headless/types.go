@@ -12,23 +12,34 @@ const (
 
 // StreamEvent 表示 Claude stream-json 输出的一个事件
 type StreamEvent struct {
-	Type      string          `json:"type"`                  // system | assistant | user | result
-	Subtype   string          `json:"subtype,omitempty"`     // 子类型
-	SessionID string          `json:"session_id,omitempty"`  // Claude 会话 ID
-	Model     string          `json:"model,omitempty"`       // 模型名称
-	CWD       string          `json:"cwd,omitempty"`         // 当前工作目录
-	Tools     []string        `json:"tools,omitempty"`       // 可用工具列表
-	Message   *MessagePayload `json:"message,omitempty"`     // 消息内容
-	Result    string          `json:"result,omitempty"`      // 结果文本
-	Error     string          `json:"error,omitempty"`       // 错误信息
-	IsError   bool            `json:"is_error,omitempty"`    // 是否为错误
-	Usage     *UsageInfo      `json:"usage,omitempty"`       // Token 使用信息
-	Cost      float64         `json:"cost_usd,omitempty"`    // 费用（美元）
-	Duration  int64           `json:"duration_ms,omitempty"` // 持续时间（毫秒）
+	Type      string          `json:"type"`                     // system | assistant | user | result
+	Subtype   string          `json:"subtype,omitempty"`        // 子类型
+	SessionID string          `json:"session_id,omitempty"`     // Claude 会话 ID
+	Model     string          `json:"model,omitempty"`          // 模型名称
+	CWD       string          `json:"cwd,omitempty"`            // 当前工作目录
+	Tools     []string        `json:"tools,omitempty"`          // 可用工具列表
+	Message   *MessagePayload `json:"message,omitempty"`        // 消息内容
+	Result    string          `json:"result,omitempty"`         // 结果文本
+	Error     string          `json:"error,omitempty"`          // 错误信息
+	IsError   bool            `json:"is_error,omitempty"`       // 是否为错误
+	Usage     *UsageInfo      `json:"usage,omitempty"`          // Token 使用信息
+	Cost      float64         `json:"cost_usd,omitempty"`       // 费用（美元，旧版字段）
+	TotalCost float64         `json:"total_cost_usd,omitempty"` // result 事件上报的本轮费用（美元）
+	Duration  int64           `json:"duration_ms,omitempty"`    // 持续时间（毫秒）
+
+	// compact_boundary 系统事件携带的压缩信息
+	CompactMetadata *CompactMetadata `json:"compact_metadata,omitempty"`
 
 	// 内部字段
 	Raw    string `json:"-"`                 // 原始 JSON 行
 	IsMeta bool   `json:"is_meta,omitempty"` // 是否为元数据事件
+	Seq    uint64 `json:"-"`                 // 会话内的事件序号，广播时分配，随响应外层发送
+}
+
+// CompactMetadata 上下文压缩边界信息
+type CompactMetadata struct {
+	Trigger   string `json:"trigger"`    // manual | auto
+	PreTokens int    `json:"pre_tokens"` // 压缩前的上下文 token 数
 }
 
 // MessagePayload 表示消息负载
@@ -84,8 +95,9 @@ type HeadlessRequest struct {
 
 // HeadlessResponse 服务器 → 客户端的消息
 type HeadlessResponse struct {
-	Type    string      `json:"type"`    // 消息类型
-	Payload interface{} `json:"payload"` // 消息负载
+	Type    string      `json:"type"`          // 消息类型
+	Payload interface{} `json:"payload"`       // 消息负载
+	Seq     uint64      `json:"seq,omitempty"` // 会话事件序号，重连时作为 last_seq 传回
 }
 
 // 客户端请求类型常量
@@ -96,6 +108,8 @@ const (
 	HeadlessRequestTypePrompt = "headless_prompt"
 	// HeadlessRequestTypeCancel 取消执行
 	HeadlessRequestTypeCancel = "headless_cancel"
+	// HeadlessRequestTypeCancelTurn 取消指定轮次（仅当该轮次正在执行时）
+	HeadlessRequestTypeCancelTurn = "cancel_turn"
 	// HeadlessRequestTypeStopSession 停止当前会话
 	HeadlessRequestTypeStopSession = "headless_stop_session"
 	// HeadlessRequestTypeLoadMore 加载更多历史
@@ -108,6 +122,10 @@ const (
 	HeadlessRequestTypeDeleteQueued = "delete_queued"
 	// HeadlessRequestTypeEditQueued 编辑排队中的消息
 	HeadlessRequestTypeEditQueued = "edit_queued"
+	// HeadlessRequestTypeResetContext 重置 Claude 上下文（保留对话历史）
+	HeadlessRequestTypeResetContext = "reset_context"
+	// HeadlessRequestTypeCompact 压缩 Claude 上下文（保留摘要）
+	HeadlessRequestTypeCompact = "compact"
 )
 
 // 服务器响应类型常量
@@ -134,15 +152,34 @@ const (
 	HeadlessResponseTypePong = "pong"
 	// HeadlessResponseTypeQueueUpdate 队列变更通知
 	HeadlessResponseTypeQueueUpdate = "queue_update"
+	// HeadlessResponseTypeCancelTurnResult 取消指定轮次的结果
+	HeadlessResponseTypeCancelTurnResult = "cancel_turn_result"
+	// HeadlessResponseTypeContextReset 上下文已重置（标记重置边界）
+	HeadlessResponseTypeContextReset = "context_reset"
+	// HeadlessResponseTypeSessionDead Claude 进程意外终止，会话已回收
+	HeadlessResponseTypeSessionDead = "session_dead"
+	// HeadlessResponseTypeSessionStopped 对话达到轮数或时长上限，会话已自动关闭
+	HeadlessResponseTypeSessionStopped = "session_stopped"
 )
 
 // SessionInfoPayload 会话信息负载
 type SessionInfoPayload struct {
-	SessionID       string        `json:"session_id"`
-	ClaudeSessionID string        `json:"claude_session_id,omitempty"`
-	State           HeadlessState `json:"state"`
-	ConversationID  uint          `json:"conversation_id"`
-	CurrentTurnID   uint          `json:"current_turn_id,omitempty"`
+	SessionID        string        `json:"session_id"`
+	ClaudeSessionID  string        `json:"claude_session_id,omitempty"`
+	State            HeadlessState `json:"state"`
+	ConversationID   uint          `json:"conversation_id"`
+	CurrentTurnID    uint          `json:"current_turn_id,omitempty"`
+	SystemPrompt     string        `json:"system_prompt,omitempty"`
+	ExtraArgs        []string      `json:"extra_args,omitempty"`
+	CompactSupported bool          `json:"compact_supported"` // 当前模型是否支持上下文压缩
+	LastSeq          uint64        `json:"last_seq"`          // 已广播的最后一个事件序号
+	// 生效的模型和停止条件（已应用平台强制设置）
+	Model              string `json:"model,omitempty"`
+	ModelForced        bool   `json:"model_forced,omitempty"` // 模型由平台强制，客户端请求的模型会被忽略
+	MaxTurns           int    `json:"max_turns,omitempty"`
+	MaxDurationMinutes int    `json:"max_duration_minutes,omitempty"`
+	// 客户端全部断开后是否取消执行中的轮次（已应用平台默认值）
+	CancelOnDisconnect bool `json:"cancel_on_disconnect"`
 }
 
 // HistoryPayload 历史记录负载
@@ -153,21 +190,28 @@ type HistoryPayload struct {
 
 // TurnInfo 轮次信息（用于前端展示）
 type TurnInfo struct {
-	ID                uint        `json:"id"`
-	TurnIndex         int         `json:"turn_index"`
-	UserPrompt        string      `json:"user_prompt"`
-	PromptSource      string      `json:"prompt_source"`
-	AssistantResponse string      `json:"assistant_response,omitempty"`
-	Model             string      `json:"model,omitempty"`
-	InputTokens       int         `json:"input_tokens"`
-	OutputTokens      int         `json:"output_tokens"`
-	CostUSD           float64     `json:"cost_usd"`
-	DurationMS        int64       `json:"duration_ms"`
-	State             string      `json:"state"`
-	ErrorMessage      string      `json:"error_message,omitempty"`
-	CreatedAt         string      `json:"created_at"`
-	CompletedAt       string      `json:"completed_at,omitempty"`
-	Events            []EventInfo `json:"events,omitempty"`
+	ID                  uint        `json:"id"`
+	TurnIndex           int         `json:"turn_index"`
+	UserPrompt          string      `json:"user_prompt"`
+	PromptSource        string      `json:"prompt_source"`
+	AttachedFiles       []string    `json:"attached_files,omitempty"`
+	AssistantResponse   string      `json:"assistant_response,omitempty"`
+	Model               string      `json:"model,omitempty"`
+	InputTokens         int         `json:"input_tokens"`
+	OutputTokens        int         `json:"output_tokens"`
+	CostUSD             float64     `json:"cost_usd"`
+	CostSource          string      `json:"cost_source,omitempty"`
+	DurationMS          int64       `json:"duration_ms"`
+	State               string      `json:"state"`
+	ErrorMessage        string      `json:"error_message,omitempty"`
+	ContextResetAfter   bool        `json:"context_reset_after,omitempty"`
+	ContextTokensBefore int         `json:"context_tokens_before,omitempty"`
+	ContextTokensAfter  int         `json:"context_tokens_after,omitempty"`
+	CreatedAt           string      `json:"created_at"`
+	CompletedAt         string      `json:"completed_at,omitempty"`
+	EventsTruncated     bool        `json:"events_truncated,omitempty"` // 达到每轮事件上限，之后的事件未保存
+	EventsPruned        bool        `json:"events_pruned,omitempty"`    // 事件已超过保留期被清理
+	Events              []EventInfo `json:"events,omitempty"`
 }
 
 // EventInfo 事件信息（用于前端展示）
@@ -188,11 +232,37 @@ type TurnCompletePayload struct {
 	InputTokens  int     `json:"input_tokens"`
 	OutputTokens int     `json:"output_tokens"`
 	CostUSD      float64 `json:"cost_usd"`
+	CostSource   string  `json:"cost_source,omitempty"` // reported | computed
 	DurationMS   int64   `json:"duration_ms"`
 	State        string  `json:"state"`
 	ErrorMessage string  `json:"error_message,omitempty"`
 }
 
+// CancelTurn 未取消的原因
+const (
+	// CancelTurnReasonNotRunning 会话当前没有正在执行的轮次
+	CancelTurnReasonNotRunning = "not_running"
+	// CancelTurnReasonTurnMismatch 正在执行的是另一个轮次
+	CancelTurnReasonTurnMismatch = "turn_mismatch"
+)
+
+// CancelTurnResultPayload 取消指定轮次的结果负载
+type CancelTurnResultPayload struct {
+	TurnID        uint   `json:"turn_id"`
+	Cancelled     bool   `json:"cancelled"`
+	CurrentTurnID uint   `json:"current_turn_id,omitempty"` // 正在执行的轮次（未取消时）
+	Reason        string `json:"reason,omitempty"`          // not_running | turn_mismatch
+}
+
+// ContextResetPayload 上下文重置负载，AfterTurnID 为边界前的最后一轮（对话还没有轮次时为 0）
+type ContextResetPayload struct {
+	ConversationID uint   `json:"conversation_id"`
+	AfterTurnID    uint   `json:"after_turn_id,omitempty"`
+	AfterTurnIndex int    `json:"after_turn_index"`
+	Cancelled      bool   `json:"cancelled"` // 是否中止了正在执行的轮次
+	ResetAt        string `json:"reset_at"`
+}
+
 // ErrorPayload 错误负载
 type ErrorPayload struct {
 	Code    string `json:"code"`
@@ -218,37 +288,56 @@ type LoadMorePayload struct {
 
 // ConversationInfo 对话信息（用于 API 响应）
 type ConversationInfo struct {
-	ID              uint   `json:"id"`
-	ContainerID     uint   `json:"container_id"`
-	SessionID       string `json:"session_id"`
-	ClaudeSessionID string `json:"claude_session_id,omitempty"`
-	Title           string `json:"title,omitempty"`
-	State           string `json:"state"`
-	IsRunning       bool   `json:"is_running"` // 后端会话是否正在运行
-	TotalTurns      int    `json:"total_turns"`
-	CreatedAt       string `json:"created_at"`
-	UpdatedAt       string `json:"updated_at"`
+	ID                 uint     `json:"id"`
+	ContainerID        uint     `json:"container_id"`
+	SessionID          string   `json:"session_id"`
+	ClaudeSessionID    string   `json:"claude_session_id,omitempty"`
+	Title              string   `json:"title,omitempty"`
+	SystemPrompt       string   `json:"system_prompt,omitempty"`
+	ExtraArgs          []string `json:"extra_args,omitempty"`
+	MaxTurns           int      `json:"max_turns,omitempty"`
+	MaxDurationMinutes int      `json:"max_duration_minutes,omitempty"`
+	CancelOnDisconnect *bool    `json:"cancel_on_disconnect,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
+	State              string   `json:"state"`
+	IsRunning          bool     `json:"is_running"` // 后端会话是否正在运行
+	TotalTurns         int      `json:"total_turns"`
+	CreatedAt          string   `json:"created_at"`
+	UpdatedAt          string   `json:"updated_at"`
 }
 
 // PromptPayload 发送 prompt 请求负载
 type PromptPayload struct {
-	Prompt string `json:"prompt"`
-	Source string `json:"source,omitempty"` // user | strategy | monitoring
-	Model  string `json:"model,omitempty"`  // Model name (e.g., claude-sonnet-4-20250514)
+	Prompt    string   `json:"prompt"`
+	Source    string   `json:"source,omitempty"`     // user | strategy | monitoring
+	Model     string   `json:"model,omitempty"`      // Model name (e.g., claude-sonnet-4-20250514)
+	FilePaths []string `json:"file_paths,omitempty"` // 引用的文件（相对于工作目录）
+	// 引用 prompt 模板时由服务端用 Variables 渲染，Prompt 可省略
+	TemplateID uint              `json:"template_id,omitempty"`
+	Variables  map[string]string `json:"variables,omitempty"`
 }
 
 // StartPayload 创建会话请求负载
 type StartPayload struct {
-	WorkDir string `json:"work_dir,omitempty"`
+	WorkDir      string `json:"work_dir,omitempty"`
+	SystemPrompt string `json:"system_prompt,omitempty"` // 对话级系统提示词；提供时覆盖已保存的值（空字符串表示清除）
+	// 透传给 Claude CLI 的额外参数，如 ["--max-turns", "5"]；只接受白名单中的参数，提供时覆盖已保存的值
+	ExtraArgs []string `json:"extra_args,omitempty"`
+	// 停止条件（0 表示不限制）：达到后拒绝新的 prompt，执行中的轮次结束后关闭会话；提供时覆盖已保存的值
+	MaxTurns           *int `json:"max_turns,omitempty"`
+	MaxDurationMinutes *int `json:"max_duration_minutes,omitempty"`
+	// 客户端全部断开且宽限期内无人重连时取消执行中的轮次；提供时覆盖已保存的值，未设置时使用平台默认值
+	CancelOnDisconnect *bool `json:"cancel_on_disconnect,omitempty"`
 }
 
 // QueuedTurnInfo 队列中的轮次信息
 type QueuedTurnInfo struct {
-	TurnID    uint   `json:"turn_id"`
-	TurnIndex int    `json:"turn_index"`
-	Prompt    string `json:"prompt"`
-	Source    string `json:"source"`
-	State     string `json:"state"` // pending | running
+	TurnID        uint     `json:"turn_id"`
+	TurnIndex     int      `json:"turn_index"`
+	Prompt        string   `json:"prompt"`
+	Source        string   `json:"source"`
+	AttachedFiles []string `json:"attached_files,omitempty"`
+	State         string   `json:"state"` // pending | running
 }
 
 // QueueUpdatePayload 队列变更通知负载
@@ -263,5 +352,7 @@ const (
 	ErrorCodeSessionBusy     = "session_busy"
 	ErrorCodeProcessFailed   = "process_failed"
 	ErrorCodeModeConflict    = "mode_conflict"
+	ErrorCodeContainerPaused = "container_paused"
+	ErrorCodeLimitReached    = "limit_reached" // 对话已达到轮数或时长上限
 	ErrorCodeInternalError   = "internal_error"
 )
@@ -0,0 +1,164 @@
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/docker/docker/errdefs"
+)
+
+// DefaultWatchdogInterval 默认的会话存活检查间隔
+const DefaultWatchdogInterval = 30 * time.Second
+
+// watchdogCheckTimeout 单次进程存活检查的超时时间
+const watchdogCheckTimeout = 10 * time.Second
+
+// processChecker 检查会话的 Claude 进程是否仍在运行（测试中可替换）
+type processChecker func(ctx context.Context, s *HeadlessSession) (alive bool, err error)
+
+// SessionDeadPayload 会话的 Claude 进程意外终止的通知
+type SessionDeadPayload struct {
+	SessionID  string `json:"session_id"`
+	TurnID     uint   `json:"turn_id,omitempty"`
+	Reason     string `json:"reason"`
+	DetectedAt string `json:"detected_at"`
+}
+
+// checkDockerExecAlive 通过 Docker exec inspect 判断 Claude 进程是否存活。
+// 没有进程、exec 已不存在或已退出都视为已终止；Docker 不可用时返回错误（无法判断）。
+func checkDockerExecAlive(ctx context.Context, s *HeadlessSession) (bool, error) {
+	if s.dockerClient == nil || s.execID == "" {
+		return false, nil
+	}
+	inspect, err := s.dockerClient.ContainerExecInspect(ctx, s.execID)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return inspect.Running, nil
+}
+
+// startWatchdog 启动会话存活检查
+func (m *HeadlessManager) startWatchdog() {
+	m.watchdogTicker = time.NewTicker(m.watchdogInterval)
+
+	go func() {
+		for {
+			select {
+			case <-m.watchdogTicker.C:
+				m.checkRunningSessions()
+			case <-m.cleanupDone:
+				return
+			}
+		}
+	}()
+}
+
+// SetWatchdogInterval 设置会话存活检查间隔
+func (m *HeadlessManager) SetWatchdogInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	m.watchdogInterval = interval
+	if m.watchdogTicker != nil {
+		m.watchdogTicker.Reset(interval)
+	}
+}
+
+// SetStallTimeout 设置无事件超时：运行中的会话超过该时间没有任何输出即视为已终止，0 表示不检查
+func (m *HeadlessManager) SetStallTimeout(timeout time.Duration) {
+	m.stallTimeout = timeout
+}
+
+// checkRunningSessions 检查所有运行中的会话。进程需连续两次被判定为已终止才会回收，
+// 避免与进程正常退出后的轮次完成处理竞争。
+func (m *HeadlessManager) checkRunningSessions() {
+	m.mu.RLock()
+	var running []*HeadlessSession
+	for _, session := range m.sessions {
+		if session.IsRunning() {
+			running = append(running, session)
+		}
+	}
+	m.mu.RUnlock()
+
+	suspects := make(map[string]uint, len(running))
+	for _, session := range running {
+		reason := m.deadReason(session)
+		if reason == "" {
+			continue
+		}
+		turnID := session.GetCurrentTurnID()
+		m.watchdogMu.Lock()
+		previous, seen := m.watchdogSuspects[session.ID]
+		m.watchdogMu.Unlock()
+		if seen && previous == turnID && session.IsRunning() {
+			session.ReapDead(reason)
+			continue
+		}
+		suspects[session.ID] = turnID
+	}
+
+	m.watchdogMu.Lock()
+	m.watchdogSuspects = suspects
+	m.watchdogMu.Unlock()
+}
+
+// deadReason 返回会话被判定为已终止的原因，存活或无法判断时返回空字符串
+func (m *HeadlessManager) deadReason(session *HeadlessSession) string {
+	if m.stallTimeout > 0 {
+		if idle := time.Since(session.GetLastActive()); idle > m.stallTimeout {
+			return fmt.Sprintf("no output from Claude for %s", idle.Round(time.Second))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), watchdogCheckTimeout)
+	defer cancel()
+	alive, err := m.checkProcess(ctx, session)
+	if err != nil {
+		log.Printf("[HeadlessManager] Failed to check process of session %s: %v", session.ID, err)
+		return ""
+	}
+	if !alive {
+		return "Claude process is no longer running"
+	}
+	return ""
+}
+
+// ReapDead 回收进程意外终止或停滞的会话：通知客户端、终止容器内的 exec、释放进程资源并将当前轮次标记为失败
+func (s *HeadlessSession) ReapDead(reason string) {
+	s.cancelMu.Lock()
+	defer s.cancelMu.Unlock()
+
+	if !s.IsRunning() {
+		return
+	}
+	turnID := s.GetCurrentTurnID()
+	s.logger().Printf("Claude process died (turn %d): %s", turnID, reason)
+
+	payload := &SessionDeadPayload{
+		SessionID:  s.ID,
+		TurnID:     turnID,
+		Reason:     reason,
+		DetectedAt: time.Now().Format(time.RFC3339),
+	}
+	deadEvent := &StreamEvent{
+		Type:   "session_dead",
+		IsMeta: true,
+		Raw:    fmt.Sprintf(`{"type":"session_dead","session_id":%q}`, s.ID),
+	}
+	if b, err := json.Marshal(payload); err == nil {
+		deadEvent.Result = string(b)
+	}
+	s.broadcastToClients(deadEvent)
+
+	// 停滞的进程可能仍在运行：与取消一样终止容器内的 Claude exec，再释放资源并将轮次标记为失败
+	if err := s.cancelExecutionLocked("Claude process died unexpectedly: " + reason); err != nil {
+		s.logger().Printf("Failed to stop dead Claude process: %v", err)
+	}
+}
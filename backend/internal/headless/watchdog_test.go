@@ -0,0 +1,94 @@
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+func TestHeadlessManager_WatchdogReapsDeadSession(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	dead, deadTurn := startRunningTurn(t, mgr, 40)
+	alive, _ := startRunningTurn(t, mgr, 41)
+	mgr.checkProcess = func(ctx context.Context, s *HeadlessSession) (bool, error) {
+		return s.ID == alive.ID, nil
+	}
+	events := dead.AddClient("watcher")
+
+	// 第一次检查只记录嫌疑，避免与正常退出的轮次完成处理竞争
+	mgr.checkRunningSessions()
+	if !dead.IsRunning() {
+		t.Fatalf("session reaped after a single failed check")
+	}
+
+	mgr.checkRunningSessions()
+	if dead.GetState() != HeadlessStateError || dead.GetCurrentTurnID() != 0 {
+		t.Errorf("expected the dead session to be in the error state without a current turn, got %s / %d", dead.GetState(), dead.GetCurrentTurnID())
+	}
+	if !alive.IsRunning() {
+		t.Errorf("expected the live session to keep running, got %s", alive.GetState())
+	}
+
+	turn, err := mgr.GetHistoryManager().GetTurnByID(deadTurn.ID)
+	if err != nil {
+		t.Fatalf("GetTurnByID error: %v", err)
+	}
+	if turn.State != models.HeadlessTurnStateError || !strings.Contains(turn.ErrorMessage, "died unexpectedly") {
+		t.Errorf("expected the turn to fail with a clear message, got %s %q", turn.State, turn.ErrorMessage)
+	}
+
+	// 客户端先收到 session_dead，再收到失败的 turn_complete
+	var types []string
+	timeout := time.After(time.Second)
+	for len(types) < 2 {
+		select {
+		case evt := <-events:
+			types = append(types, evt.Type)
+			if evt.Type == "session_dead" {
+				var payload SessionDeadPayload
+				if err := json.Unmarshal([]byte(evt.Result), &payload); err != nil || payload.TurnID != deadTurn.ID || payload.SessionID != dead.ID {
+					t.Errorf("unexpected session_dead payload %s (%v)", evt.Result, err)
+				}
+			}
+		case <-timeout:
+			t.Fatalf("expected 2 events, got %v", types)
+		}
+	}
+	if types[0] != "session_dead" || types[1] != "turn_complete" {
+		t.Errorf("events = %v, want [session_dead turn_complete]", types)
+	}
+}
+
+func TestHeadlessManager_WatchdogSkipsUncertainChecks(t *testing.T) {
+	db := setupHeadlessTestDB(t)
+	mgr := newTestHeadlessManager(t, db)
+	defer mgr.Close()
+
+	session, _ := startRunningTurn(t, mgr, 42)
+	mgr.checkProcess = func(ctx context.Context, s *HeadlessSession) (bool, error) {
+		return false, errors.New("docker unavailable")
+	}
+
+	mgr.checkRunningSessions()
+	mgr.checkRunningSessions()
+	if !session.IsRunning() {
+		t.Errorf("session reaped although liveness could not be determined")
+	}
+
+	// 开启无输出超时后，长时间没有事件的会话视为已终止
+	mgr.SetStallTimeout(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	mgr.checkRunningSessions()
+	mgr.checkRunningSessions()
+	if session.GetState() != HeadlessStateError {
+		t.Errorf("expected a stalled session to be reaped, got %s", session.GetState())
+	}
+}
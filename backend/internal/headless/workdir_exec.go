@@ -0,0 +1,52 @@
+package headless
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// defaultWorkDir 未指定工作目录时使用的目录
+const defaultWorkDir = "/app"
+
+// workDirExecutor 在容器工作目录中执行命令并返回 stdout
+type workDirExecutor func(ctx context.Context, dockerID, workDir string, cmd []string) (string, error)
+
+// execInWorkDir 以容器默认用户在工作目录中执行命令，返回 stdout（不检查退出码）
+func execInWorkDir(ctx context.Context, dockerID, workDir string, cmd []string) (string, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return "", fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer cli.Close()
+
+	if workDir == "" {
+		workDir = defaultWorkDir
+	}
+
+	execResp, err := cli.ContainerExecCreate(ctx, dockerID, types.ExecConfig{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+		WorkingDir:   workDir,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	attachResp, err := cli.ContainerExecAttach(ctx, execResp.ID, types.ExecStartCheck{})
+	if err != nil {
+		return "", fmt.Errorf("failed to attach exec: %w", err)
+	}
+	defer attachResp.Close()
+
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, attachResp.Reader); err != nil {
+		return "", fmt.Errorf("failed to read exec output: %w", err)
+	}
+	return stdout.String(), nil
+}
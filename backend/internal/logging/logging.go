@@ -0,0 +1,107 @@
+// Package logging provides request-scoped, key=value structured logging on top of the standard logger.
+//
+// Log lines look like:
+//
+//	[ContainerService] Container started request_id=3f2c... container_id=12
+//
+// so they can be grepped by request_id, container_id or session_id.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Field keys shared across the codebase
+const (
+	KeyRequestID   = "request_id"
+	KeyContainerID = "container_id"
+	KeySessionID   = "session_id"
+)
+
+type contextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, or "" if there is none
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+type field struct {
+	key   string
+	value string
+}
+
+// Logger writes log lines with a component prefix and trailing key=value fields.
+// Loggers are immutable; With returns a new Logger.
+type Logger struct {
+	component string
+	fields    []field
+}
+
+// New returns a Logger whose lines are prefixed with [component]
+func New(component string) Logger {
+	return Logger{component: component}
+}
+
+// FromContext returns a Logger for component carrying the request ID from ctx, if any
+func FromContext(ctx context.Context, component string) Logger {
+	l := New(component)
+	if id := RequestID(ctx); id != "" {
+		l = l.With(KeyRequestID, id)
+	}
+	return l
+}
+
+// With returns a Logger that appends key=value to every line
+func (l Logger) With(key string, value interface{}) Logger {
+	fields := make([]field, len(l.fields), len(l.fields)+1)
+	copy(fields, l.fields)
+	l.fields = append(fields, field{key: key, value: fmt.Sprint(value)})
+	return l
+}
+
+// Printf logs a formatted message followed by the logger's fields
+func (l Logger) Printf(format string, args ...interface{}) {
+	log.Print(l.format(fmt.Sprintf(format, args...)))
+}
+
+// format builds the full log line for msg
+func (l Logger) format(msg string) string {
+	var b strings.Builder
+	if l.component != "" {
+		b.WriteString("[")
+		b.WriteString(l.component)
+		b.WriteString("] ")
+	}
+	b.WriteString(msg)
+	for _, f := range l.fields {
+		b.WriteString(" ")
+		b.WriteString(f.key)
+		b.WriteString("=")
+		b.WriteString(quoteValue(f.value))
+	}
+	return b.String()
+}
+
+// quoteValue quotes values that would otherwise break key=value parsing
+func quoteValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+		return strconv.Quote(v)
+	}
+	return v
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDRoundTrip(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+	if got := RequestID(ctx); got != "req-1" {
+		t.Errorf("Expected req-1, got %q", got)
+	}
+	if got := RequestID(context.Background()); got != "" {
+		t.Errorf("Expected empty request ID, got %q", got)
+	}
+	if got := RequestID(WithRequestID(context.Background(), "")); got != "" {
+		t.Errorf("Empty ID should not be stored, got %q", got)
+	}
+}
+
+func TestLoggerFormat(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "abc")
+	l := FromContext(ctx, "ContainerService").With(KeyContainerID, 12).With("note", "two words")
+
+	want := `[ContainerService] Container started request_id=abc container_id=12 note="two words"`
+	if got := l.format("Container started"); got != want {
+		t.Errorf("format() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLoggerWithDoesNotMutateParent(t *testing.T) {
+	base := New("X").With("a", 1)
+	first := base.With("b", 2)
+	second := base.With("c", 3)
+
+	if got := first.format("m"); got != "[X] m a=1 b=2" {
+		t.Errorf("Unexpected first logger output: %s", got)
+	}
+	if got := second.format("m"); got != "[X] m a=1 c=3" {
+		t.Errorf("Unexpected second logger output: %s", got)
+	}
+}
+
+func TestLoggerPrintf(t *testing.T) {
+	var buf bytes.Buffer
+	original := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(original)
+
+	New("HeadlessSession").With(KeySessionID, "s-1").Printf("Closing %s", "session")
+
+	if !strings.Contains(buf.String(), "[HeadlessSession] Closing session session_id=s-1") {
+		t.Errorf("Unexpected log output: %q", buf.String())
+	}
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+
+	"cc-platform/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// AuditContainerIDKey is the gin context key a handler sets to the ID of a container it created,
+	// since the route of an audited create has no container ID to read
+	AuditContainerIDKey = "audit_container_id"
+	// AuditTargetKey is the gin context key a handler sets to the target of an audited request
+	// when it is only known from the body, which the middleware doesn't read
+	AuditTargetKey = "audit_target"
+)
+
+// maxAuditTargetLength matches the size of the AuditLog target column
+const maxAuditTargetLength = 500
+
+// AuditRecorder stores audit log entries
+type AuditRecorder interface {
+	Record(ctx context.Context, entry *models.AuditLog)
+}
+
+// auditRoute names the action of an audited route and what it applies to within the container
+type auditRoute struct {
+	action string
+	target func(c *gin.Context) string
+	// noContainer marks routes whose :id isn't a container ID
+	noContainer bool
+}
+
+// auditTargetParam reads the target from a path parameter
+func auditTargetParam(name string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		return c.Param(name)
+	}
+}
+
+// auditTargetPath reads the target from the path of a file operation. The path is given in the
+// query, or in the form for uploads; file contents are never read.
+func auditTargetPath(c *gin.Context) string {
+	if path := c.Query("path"); path != "" {
+		return path
+	}
+	if c.Request.MultipartForm != nil {
+		if values := c.Request.MultipartForm.Value["path"]; len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// auditTargetTemplate names the template of a template-wide action
+func auditTargetTemplate(c *gin.Context) string {
+	return "template " + c.Param("id")
+}
+
+// auditedRoutes lists the privileged routes recorded in the audit log, keyed by method and route pattern.
+// A terminal WebSocket is recorded when its session ends, since the handler runs for the whole session.
+var auditedRoutes = map[string]auditRoute{
+	"POST /api/containers":                                 {action: models.AuditActionContainerCreate},
+	"DELETE /api/containers/:id":                           {action: models.AuditActionContainerDelete},
+	"POST /api/containers/:id/start":                       {action: models.AuditActionContainerStart},
+	"POST /api/containers/:id/stop":                        {action: models.AuditActionContainerStop},
+	"POST /api/containers/:id/recreate":                    {action: models.AuditActionContainerRecreate},
+	"POST /api/containers/:id/pause":                       {action: models.AuditActionContainerPause},
+	"POST /api/containers/:id/resume":                      {action: models.AuditActionContainerResume},
+	"PUT /api/containers/:id/resources":                    {action: models.AuditActionContainerResize},
+	"POST /api/containers/:id/cancel-init":                 {action: models.AuditActionInitCancel},
+	"POST /api/containers/:id/mode/reset":                  {action: models.AuditActionModeReset},
+	"POST /api/containers/:id/exec":                        {action: models.AuditActionContainerExec},
+	"POST /api/containers/:id/commands/:name/run":          {action: models.AuditActionContainerExec, target: auditTargetParam("name")},
+	"POST /api/containers/:id/commands":                    {action: models.AuditActionCommandCreate},
+	"PUT /api/containers/:id/commands/:name":               {action: models.AuditActionCommandUpdate, target: auditTargetParam("name")},
+	"DELETE /api/containers/:id/commands/:name":            {action: models.AuditActionCommandDelete, target: auditTargetParam("name")},
+	"POST /api/containers/:id/processes/:pid/kill":         {action: models.AuditActionProcessKill, target: auditTargetParam("pid")},
+	"GET /api/ws/terminal/:id":                             {action: models.AuditActionTerminalOpen},
+	"POST /api/containers/:id/ports":                       {action: models.AuditActionPortAdd},
+	"DELETE /api/containers/:id/ports/:port":               {action: models.AuditActionPortRemove, target: auditTargetParam("port")},
+	"POST /api/files/:id/upload":                           {action: models.AuditActionFileUpload, target: auditTargetPath},
+	"DELETE /api/files/:id":                                {action: models.AuditActionFileDelete, target: auditTargetPath},
+	"POST /api/files/:id/mkdir":                            {action: models.AuditActionFileMkdir},
+	"POST /api/containers/:id/inject-configs":              {action: models.AuditActionConfigInject},
+	"POST /api/claude-configs/:id/propagate":               {action: models.AuditActionConfigPropagate, target: auditTargetTemplate, noContainer: true},
+	"POST /api/containers/:id/copy-configs-from/:sourceId": {action: models.AuditActionConfigCopy, target: auditTargetParam("sourceId")},
+	"POST /api/containers/:id/config-profile/:profileId":   {action: models.AuditActionConfigProfile, target: auditTargetParam("profileId")},
+	"POST /api/containers/:id/claude-snapshot":             {action: models.AuditActionConfigRestore},
+	"POST /api/containers/:id/mcp/:name/test":              {action: models.AuditActionMCPTest, target: auditTargetParam("name")},
+	"POST /api/containers/:id/code-server/rotate-password": {action: models.AuditActionCodeServerRotate},
+	"POST /api/containers/:id/clone-repo":                  {action: models.AuditActionRepoClone},
+	"POST /api/containers/:id/workspace/restore":           {action: models.AuditActionWorkspaceRestore},
+	"DELETE /api/docker/containers/:dockerId":              {action: models.AuditActionDockerRemove, target: auditTargetParam("dockerId")},
+	"POST /api/docker/containers/prune":                    {action: models.AuditActionDockerPrune},
+	"POST /api/docker/containers/:dockerId/stop":           {action: models.AuditActionDockerStop, target: auditTargetParam("dockerId")},
+	"POST /api/import/backup":                              {action: models.AuditActionBackupImport, noContainer: true},
+}
+
+// auditContainerID returns the container an audited request applied to, if any
+func auditContainerID(c *gin.Context, route auditRoute) *uint {
+	if id, ok := c.Get(AuditContainerIDKey); ok {
+		if id, ok := id.(uint); ok {
+			return &id
+		}
+	}
+	if route.noContainer {
+		return nil
+	}
+	if id, err := strconv.ParseUint(c.Param("id"), 10, 64); err == nil {
+		containerID := uint(id)
+		return &containerID
+	}
+	return nil
+}
+
+// Audit returns a middleware that records the privileged routes in auditedRoutes once they
+// have been handled, whether they succeeded or not. It must run after JWTAuth, which sets the
+// actor. Only the route pattern, path parameters and file paths are recorded, never a body.
+func Audit(recorder AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		audited, ok := auditedRoutes[c.Request.Method+" "+route]
+		if !ok || recorder == nil {
+			c.Next()
+			return
+		}
+
+		c.Next()
+
+		entry := &models.AuditLog{
+			Actor:       c.GetString("username"),
+			Action:      audited.action,
+			ContainerID: auditContainerID(c, audited),
+			Method:      c.Request.Method,
+			Route:       route,
+			Status:      c.Writer.Status(),
+			ClientIP:    c.ClientIP(),
+			RequestID:   c.GetString(RequestIDContextKey),
+		}
+		if target := c.GetString(AuditTargetKey); target != "" {
+			entry.Target = target
+		} else if audited.target != nil {
+			entry.Target = audited.target(c)
+		}
+		if len(entry.Target) > maxAuditTargetLength {
+			entry.Target = entry.Target[:maxAuditTargetLength]
+		}
+		recorder.Record(c.Request.Context(), entry)
+	}
+}
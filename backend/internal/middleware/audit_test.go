@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/models"
+	"cc-platform/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupAuditRouter(t *testing.T) (*gin.Engine, *services.AuditService) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.AuditLog{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	audit := services.NewAuditService(db)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api")
+	api.Use(RequestID(), func(c *gin.Context) {
+		c.Set("username", c.GetHeader("X-Test-User"))
+	}, Audit(audit))
+	api.POST("/containers", func(c *gin.Context) {
+		c.Set(AuditContainerIDKey, uint(7))
+		c.Status(http.StatusCreated)
+	})
+	api.GET("/containers/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.POST("/containers/:id/exec", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.DELETE("/files/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	api.POST("/claude-configs/:id/propagate", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router, audit
+}
+
+func TestAudit_RecordsPrivilegedActions(t *testing.T) {
+	router, audit := setupAuditRouter(t)
+	send := func(method, target, user, body string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("X-Test-User", user)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	send(http.MethodPost, "/api/containers", "alice", `{"name":"web"}`)
+	send(http.MethodPost, "/api/containers/7/exec", "alice", `{"command":"export TOKEN=s3cret"}`)
+	send(http.MethodDelete, "/api/files/7?path=/workspace/app/.env", "bob", "")
+	send(http.MethodPost, "/api/claude-configs/3/propagate", "bob", "")
+	send(http.MethodGet, "/api/containers/7", "alice", "")
+
+	page, err := audit.List(services.AuditFilter{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if page.Total != 4 {
+		t.Fatalf("Expected 4 audit rows, got %d: %+v", page.Total, page.Entries)
+	}
+	byAction := make(map[string]models.AuditLog)
+	for _, entry := range page.Entries {
+		byAction[entry.Action] = entry
+		if entry.RequestID == "" || entry.ClientIP == "" {
+			t.Errorf("Expected request ID and client IP on %+v", entry)
+		}
+		if strings.Contains(entry.Target, "s3cret") {
+			t.Errorf("Request body leaked into %+v", entry)
+		}
+	}
+
+	if e := byAction[models.AuditActionContainerCreate]; e.Actor != "alice" || e.ContainerID == nil || *e.ContainerID != 7 || e.Status != http.StatusCreated {
+		t.Errorf("Unexpected create row: %+v", e)
+	}
+	if e := byAction[models.AuditActionContainerExec]; e.ContainerID == nil || *e.ContainerID != 7 || e.Route != "/api/containers/:id/exec" || e.Target != "" {
+		t.Errorf("Unexpected exec row: %+v", e)
+	}
+	if e := byAction[models.AuditActionFileDelete]; e.Actor != "bob" || e.Target != "/workspace/app/.env" || e.Status != http.StatusNotFound {
+		t.Errorf("Unexpected file delete row: %+v", e)
+	}
+	if e := byAction[models.AuditActionConfigPropagate]; e.ContainerID != nil || e.Target != "template 3" {
+		t.Errorf("Unexpected propagate row: %+v", e)
+	}
+
+	// Filters
+	containerID := uint(7)
+	if page, _ := audit.List(services.AuditFilter{ContainerID: &containerID, Actor: "alice"}); page.Total != 2 {
+		t.Errorf("Expected 2 rows for alice on container 7, got %d", page.Total)
+	}
+	if page, _ := audit.List(services.AuditFilter{Actor: "bob", PageSize: 1}); page.Total != 2 || len(page.Entries) != 1 {
+		t.Errorf("Expected a page of 1 out of 2 rows for bob, got %d of %d", len(page.Entries), page.Total)
+	}
+}
+
+func TestAudit_RecordsLifecycleRoutes(t *testing.T) {
+	router, audit := setupAuditRouter(t)
+	api := router.Group("/api")
+	api.Use(func(c *gin.Context) { c.Set("username", "alice") }, Audit(audit))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.POST("/containers/:id/pause", ok)
+	api.POST("/containers/:id/mode/reset", ok)
+	api.DELETE("/containers/:id/ports/:port", ok)
+	api.POST("/import/backup", ok)
+	api.POST("/containers/:id/commands", func(c *gin.Context) {
+		c.Set(AuditTargetKey, "build")
+		c.Status(http.StatusCreated)
+	})
+	api.DELETE("/containers/:id/commands/:name", ok)
+	router.GET("/api/ws/terminal/:id", Audit(audit), func(c *gin.Context) {
+		// The Docker ID form of the route resolves the container in the handler
+		c.Set("username", "bob")
+		c.Set(AuditContainerIDKey, uint(9))
+	})
+
+	for _, r := range [][2]string{
+		{http.MethodPost, "/api/containers/7/pause"},
+		{http.MethodPost, "/api/containers/7/mode/reset"},
+		{http.MethodDelete, "/api/containers/7/ports/3000"},
+		{http.MethodPost, "/api/import/backup"},
+		{http.MethodGet, "/api/ws/terminal/abc123"},
+		{http.MethodPost, "/api/containers/7/commands"},
+		{http.MethodDelete, "/api/containers/7/commands/build"},
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(r[0], r[1], nil))
+	}
+
+	page, err := audit.List(services.AuditFilter{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	byAction := make(map[string]models.AuditLog)
+	for _, entry := range page.Entries {
+		byAction[entry.Action] = entry
+	}
+	if page.Total != 7 || len(byAction) != 7 {
+		t.Fatalf("Expected 7 audited actions, got %+v", page.Entries)
+	}
+	if e := byAction[models.AuditActionPortRemove]; e.ContainerID == nil || *e.ContainerID != 7 || e.Target != "3000" {
+		t.Errorf("Unexpected port remove row: %+v", e)
+	}
+	if e := byAction[models.AuditActionBackupImport]; e.ContainerID != nil || e.Actor != "alice" {
+		t.Errorf("Unexpected backup import row: %+v", e)
+	}
+	for _, action := range []string{models.AuditActionCommandCreate, models.AuditActionCommandDelete} {
+		if e := byAction[action]; e.ContainerID == nil || *e.ContainerID != 7 || e.Target != "build" {
+			t.Errorf("Unexpected %s row: %+v", action, e)
+		}
+	}
+	if e := byAction[models.AuditActionTerminalOpen]; e.ContainerID == nil || *e.ContainerID != 9 || e.Actor != "bob" {
+		t.Errorf("Unexpected terminal row: %+v", e)
+	}
+}
@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"net/http"
+	"os"
 	"strings"
+	"time"
 
 	"cc-platform/internal/services"
 
@@ -12,16 +14,61 @@ import (
 const (
 	// Cookie name for JWT token
 	TokenCookieName = "cc_token"
+	// RefreshedTokenHeader carries a re-issued token to clients that authenticate with the Authorization header
+	RefreshedTokenHeader = "X-Refreshed-Token"
+	// Context keys for the raw token and whether it came from the cookie
+	TokenContextKey           = "token"
+	TokenFromCookieContextKey = "token_from_cookie"
 )
 
+// IsSecureRequest checks if the request should use secure cookies
+// It considers both the environment and the actual request protocol
+func IsSecureRequest(c *gin.Context) bool {
+	// In development mode, never use secure cookies
+	if os.Getenv("ENVIRONMENT") != "production" {
+		return false
+	}
+
+	// Check if request came over HTTPS (directly or via proxy)
+	// X-Forwarded-Proto is set by reverse proxies (nginx, etc.)
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "https" {
+		return true
+	}
+
+	// Check the actual request scheme
+	if c.Request.TLS != nil {
+		return true
+	}
+
+	// In production but not HTTPS, still don't set Secure flag
+	// This allows HTTP deployments to work (not recommended but functional)
+	return false
+}
+
+// SetTokenCookie stores a JWT in the httpOnly auth cookie; a negative maxAge clears it
+func SetTokenCookie(c *gin.Context, token string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(
+		TokenCookieName,    // name
+		token,              // value
+		maxAge,             // maxAge in seconds
+		"/",                // path
+		"",                 // domain (empty = current domain)
+		IsSecureRequest(c), // secure (HTTPS only when actually using HTTPS)
+		true,               // httpOnly (not accessible via JavaScript)
+	)
+}
+
 // JWTAuth returns a middleware that validates JWT tokens from Cookie or Authorization header
 func JWTAuth(authService *services.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var token string
+		fromCookie := false
 
 		// Try Cookie first (preferred for httpOnly security)
 		if cookieToken, err := c.Cookie(TokenCookieName); err == nil && cookieToken != "" {
 			token = cookieToken
+			fromCookie = true
 		}
 
 		// Fallback to Authorization header
@@ -51,6 +98,17 @@ func JWTAuth(authService *services.AuthService) gin.HandlerFunc {
 		// Store claims in context for later use
 		c.Set("claims", claims)
 		c.Set("username", claims.Username)
+		c.Set(TokenContextKey, token)
+		c.Set(TokenFromCookieContextKey, fromCookie)
+
+		// Sliding expiration: hand out a fresh token once the current one is past half its lifetime
+		if refreshed, expiresAt, err := authService.SlideToken(claims); err == nil && refreshed != "" {
+			if fromCookie {
+				SetTokenCookie(c, refreshed, int(time.Until(expiresAt).Seconds()))
+			} else {
+				c.Header(RefreshedTokenHeader, refreshed)
+			}
+		}
 
 		c.Next()
 	}
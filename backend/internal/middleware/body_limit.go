@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBodySize returns a middleware that limits request bodies to limit bytes (0 = no limit).
+// A declared Content-Length over the limit is rejected with 413 before any of the body is read.
+// Other bodies are wrapped in http.MaxBytesReader, so reading past the limit fails with
+// *http.MaxBytesError and the handler responds with 413 itself.
+func MaxBodySize(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			c.Header("Connection", "close")
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": BodyTooLargeMessage(limit)})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// BodyTooLargeMessage is the error message for a request body over limit bytes
+func BodyTooLargeMessage(limit int64) string {
+	return fmt.Sprintf("Request body exceeds the %d byte limit", limit)
+}
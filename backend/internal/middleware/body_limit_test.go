@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// countingReader records how many bytes of a request body were read
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func setupBodyLimitRouter(limit int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/upload", MaxBodySize(limit), func(c *gin.Context) {
+		data, err := io.ReadAll(c.Request.Body)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": BodyTooLargeMessage(maxBytesErr.Limit)})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"bytes": len(data)})
+	})
+	return router
+}
+
+func TestMaxBodySize_RejectsDeclaredLengthBeforeReading(t *testing.T) {
+	router := setupBodyLimitRouter(1024)
+	body := &countingReader{r: strings.NewReader(strings.Repeat("x", 4096))}
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.ContentLength = 4096
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected 413, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "1024 byte limit") {
+		t.Errorf("Expected the limit in the message, got %s", w.Body.String())
+	}
+	if body.read != 0 {
+		t.Errorf("Expected the body to be left unread, read %d bytes", body.read)
+	}
+}
+
+func TestMaxBodySize_StopsReadingUndeclaredLength(t *testing.T) {
+	router := setupBodyLimitRouter(1024)
+	body := &countingReader{r: strings.NewReader(strings.Repeat("x", 1<<20))}
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected 413, got %d", w.Code)
+	}
+	// MaxBytesReader stops once the limit is passed instead of consuming the whole body
+	if body.read > 64*1024 {
+		t.Errorf("Expected reading to stop near the limit, read %d bytes", body.read)
+	}
+}
+
+func TestMaxBodySize_AllowsBodiesWithinLimit(t *testing.T) {
+	for _, limit := range []int64{1024, 0} {
+		router := setupBodyLimitRouter(limit)
+		req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("x", 1024)))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"bytes":1024`) {
+			t.Errorf("Limit %d: expected the body to be accepted, got %d %s", limit, w.Code, w.Body.String())
+		}
+	}
+}
@@ -1,71 +1,140 @@
 package middleware
 
 import (
-	"os"
+	"log"
+	"net/http"
+	"net/url"
 	"strings"
+	"sync/atomic"
+
+	"cc-platform/internal/config"
 
 	"github.com/gin-gonic/gin"
 )
 
-// allowAllOrigins indicates if all origins should be allowed
-var allowAllOrigins = false
+// devOrigins are allowed outside production so the Vite and CRA dev servers work out of the box
+var devOrigins = []string{
+	"http://localhost:3000",
+	"http://localhost:5173",
+	"http://127.0.0.1:3000",
+	"http://127.0.0.1:5173",
+}
 
-// getAllowedOrigins returns the list of allowed origins based on environment
-func getAllowedOrigins() map[string]bool {
-	// Check for wildcard first
-	if customOrigins := os.Getenv("ALLOWED_ORIGINS"); customOrigins == "*" {
-		allowAllOrigins = true
-		return nil
+const (
+	corsAllowMethods  = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowHeaders  = "Origin, Content-Type, Authorization, X-Requested-With, Last-Event-ID, Idempotency-Key, " + RequestIDHeader
+	corsExposeHeaders = "Retry-After, Idempotent-Replayed, " + RefreshedTokenHeader + ", " + RequestIDHeader
+	corsMaxAge        = "86400"
+)
+
+// CORSPolicy decides which browser origins may make credentialed requests to the API
+type CORSPolicy struct {
+	allowAll bool
+	origins  map[string]bool
+}
+
+// activeCORSPolicy is the policy installed by CORS, shared with the WebSocket origin check
+var activeCORSPolicy atomic.Pointer[CORSPolicy]
+
+// NewCORSPolicy builds the origin allowlist from ALLOWED_ORIGINS.
+// Allowing every origin requires CORS_ALLOW_ALL_ORIGINS=true and is meant for local development only.
+func NewCORSPolicy(cfg *config.Config) *CORSPolicy {
+	policy := &CORSPolicy{
+		allowAll: cfg.CORSAllowAllOrigins,
+		origins:  make(map[string]bool),
 	}
 
-	// Default allowed origins for development
-	allowed := map[string]bool{
-		"http://localhost:3000":  true,
-		"http://localhost:5173":  true,
-		"http://127.0.0.1:3000":  true,
-		"http://127.0.0.1:5173":  true,
+	if policy.allowAll && cfg.Environment == "production" {
+		log.Println("Warning: CORS_ALLOW_ALL_ORIGINS is enabled in production; any website can make authenticated requests")
 	}
 
-	// Add custom origins from environment variable (comma-separated)
-	if customOrigins := os.Getenv("ALLOWED_ORIGINS"); customOrigins != "" {
-		for _, origin := range strings.Split(customOrigins, ",") {
-			origin = strings.TrimSpace(origin)
-			if origin != "" {
-				allowed[origin] = true
-			}
+	if cfg.Environment != "production" {
+		for _, origin := range devOrigins {
+			policy.origins[origin] = true
 		}
 	}
 
-	return allowed
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			log.Println("Warning: ALLOWED_ORIGINS=* is ignored; set CORS_ALLOW_ALL_ORIGINS=true to allow every origin")
+			continue
+		}
+		if normalized := normalizeOrigin(origin); normalized != "" {
+			policy.origins[normalized] = true
+		}
+	}
+
+	return policy
+}
+
+// AllowsOrigin reports whether origin is allowlisted (or the policy allows all origins)
+func (p *CORSPolicy) AllowsOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	return p.allowAll || p.origins[normalizeOrigin(origin)]
 }
 
-// CORS returns a middleware that handles CORS with origin whitelist
-func CORS() gin.HandlerFunc {
-	allowedOrigins := getAllowedOrigins()
+// allowsRequest reports whether a request's Origin may talk to this server.
+// Requests without an Origin (non-browser clients) and same-host origins are always allowed.
+func (p *CORSPolicy) allowsRequest(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || isSameHostOrigin(origin, r.Host) {
+		return true
+	}
+	return p.AllowsOrigin(origin)
+}
+
+// normalizeOrigin lowercases an origin and strips any trailing slash so configured values match browser headers
+func normalizeOrigin(origin string) string {
+	return strings.TrimRight(strings.ToLower(strings.TrimSpace(origin)), "/")
+}
+
+// isSameHostOrigin reports whether origin points at the host the request was sent to
+func isSameHostOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, host)
+}
+
+// CORS returns a middleware that reflects only allowed origins and answers preflight requests.
+// Credentials are allowed, so the wildcard "*" is never sent; the request origin is echoed instead.
+// Disallowed preflights are refused with 403; other requests from disallowed origins get no CORS headers,
+// so browsers block the response.
+func CORS(policy *CORSPolicy) gin.HandlerFunc {
+	activeCORSPolicy.Store(policy)
 
 	return func(c *gin.Context) {
 		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
 
-		// Allow all origins if wildcard is set
-		if allowAllOrigins {
-			if origin != "" {
-				c.Header("Access-Control-Allow-Origin", origin)
-			} else {
-				c.Header("Access-Control-Allow-Origin", "*")
-			}
-			c.Header("Access-Control-Allow-Credentials", "true")
-		} else if origin != "" && allowedOrigins[origin] {
-			// Check if origin is in whitelist
+		// Responses differ per origin; keep caches from serving one origin's headers to another
+		c.Writer.Header().Add("Vary", "Origin")
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		allowed := policy.allowsRequest(c.Request)
+
+		if preflight && !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Origin not allowed"})
+			return
+		}
+
+		if allowed {
 			c.Header("Access-Control-Allow-Origin", origin)
 			c.Header("Access-Control-Allow-Credentials", "true")
+			c.Header("Access-Control-Expose-Headers", corsExposeHeaders)
 		}
 
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
-		c.Header("Access-Control-Max-Age", "86400")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", corsAllowMethods)
+			c.Header("Access-Control-Allow-Headers", corsAllowHeaders)
+			c.Header("Access-Control-Max-Age", corsMaxAge)
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
@@ -73,10 +142,12 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
-// IsOriginAllowed checks if an origin is in the whitelist
-func IsOriginAllowed(origin string) bool {
-	if allowAllOrigins {
-		return true
+// CheckWebSocketOrigin is a websocket.Upgrader CheckOrigin function using the active CORS policy
+func CheckWebSocketOrigin(r *http.Request) bool {
+	policy := activeCORSPolicy.Load()
+	if policy == nil {
+		// CORS not installed (e.g. in tests): fall back to the development defaults
+		policy = NewCORSPolicy(&config.Config{})
 	}
-	return getAllowedOrigins()[origin]
+	return policy.allowsRequest(r)
 }
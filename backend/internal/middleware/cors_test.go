@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cc-platform/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupCORSRouter(cfg *config.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORS(NewCORSPolicy(cfg)))
+	router.GET("/api/ping", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	return router
+}
+
+func doCORSRequest(router *gin.Engine, method, origin string, preflight bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "http://api.example.com/api/ping", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if preflight {
+		req.Header.Set("Access-Control-Request-Method", "GET")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSAllowedOriginGetsHeaders(t *testing.T) {
+	router := setupCORSRouter(&config.Config{
+		Environment:    "production",
+		AllowedOrigins: []string{"https://app.example.com/"},
+	})
+
+	w := doCORSRequest(router, http.MethodGet, "https://app.example.com", false)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Expected origin to be reflected, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Expected credentials to be allowed, got %q", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("Expected Vary: Origin, got %q", got)
+	}
+}
+
+func TestCORSDisallowedOriginGetsNoHeaders(t *testing.T) {
+	router := setupCORSRouter(&config.Config{
+		Environment:    "production",
+		AllowedOrigins: []string{"https://app.example.com"},
+	})
+
+	w := doCORSRequest(router, http.MethodGet, "https://evil.example.com", false)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Disallowed origin must not be reflected, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Disallowed origin must not get credentials, got %q", got)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := setupCORSRouter(&config.Config{
+		Environment:    "production",
+		AllowedOrigins: []string{"https://app.example.com"},
+	})
+
+	w := doCORSRequest(router, http.MethodOptions, "https://app.example.com", true)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Allowed preflight should return 204, got %d", w.Code)
+	}
+	if w.Header().Get("Access-Control-Allow-Methods") == "" || w.Header().Get("Access-Control-Allow-Headers") == "" {
+		t.Error("Preflight response should list allowed methods and headers")
+	}
+
+	w = doCORSRequest(router, http.MethodOptions, "https://evil.example.com", true)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Disallowed preflight should return 403, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Disallowed preflight must not reflect origin, got %q", got)
+	}
+}
+
+func TestCORSWildcardRequiresFlag(t *testing.T) {
+	router := setupCORSRouter(&config.Config{
+		Environment:    "development",
+		AllowedOrigins: []string{"*"},
+	})
+	w := doCORSRequest(router, http.MethodGet, "https://anything.example.com", false)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("ALLOWED_ORIGINS=* alone must not allow every origin, got %q", got)
+	}
+
+	router = setupCORSRouter(&config.Config{
+		Environment:         "development",
+		CORSAllowAllOrigins: true,
+	})
+	w = doCORSRequest(router, http.MethodGet, "https://anything.example.com", false)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://anything.example.com" {
+		t.Errorf("Wildcard mode should reflect the origin (never *), got %q", got)
+	}
+}
+
+func TestCORSDevOriginsOnlyOutsideProduction(t *testing.T) {
+	dev := NewCORSPolicy(&config.Config{Environment: "development"})
+	if !dev.AllowsOrigin("http://localhost:5173") {
+		t.Error("Dev server origin should be allowed in development")
+	}
+
+	prod := NewCORSPolicy(&config.Config{Environment: "production"})
+	if prod.AllowsOrigin("http://localhost:5173") {
+		t.Error("Dev server origin should not be allowed in production")
+	}
+}
+
+func TestCheckWebSocketOrigin(t *testing.T) {
+	setupCORSRouter(&config.Config{
+		Environment:    "production",
+		AllowedOrigins: []string{"https://app.example.com"},
+	})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},                          // non-browser client
+		{"https://api.example.com", true},   // same host
+		{"https://app.example.com", true},   // allowlisted
+		{"https://evil.example.com", false}, // everything else
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://api.example.com/api/ws/terminal/1", nil)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		if got := CheckWebSocketOrigin(req); got != tt.want {
+			t.Errorf("CheckWebSocketOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
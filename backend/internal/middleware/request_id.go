@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cc-platform/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestIDHeader carries the correlation ID on requests and responses
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDContextKey is the gin context key holding the request ID
+	RequestIDContextKey = "request_id"
+)
+
+// validRequestID limits client-supplied IDs to characters that are safe in logs and headers
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID returns a middleware that assigns each request a correlation ID.
+// A well-formed X-Request-ID from the client (or an upstream proxy) is kept; otherwise a new one is generated.
+// The ID is echoed in the response header and stored in both the gin context and the request context.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.New().String()
+		}
+
+		c.Set(RequestIDContextKey, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+
+		c.Next()
+	}
+}
+
+// AccessLogFormatter is gin's access log line with the request ID appended
+func AccessLogFormatter(param gin.LogFormatterParams) string {
+	requestID, _ := param.Keys[RequestIDContextKey].(string)
+	line := fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v request_id=%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		param.Path,
+		requestID,
+	)
+	if param.ErrorMessage != "" {
+		line += " error=" + strconv.Quote(strings.TrimSpace(param.ErrorMessage))
+	}
+	return line + "\n"
+}
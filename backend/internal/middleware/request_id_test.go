@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupRequestIDRouter() (*gin.Engine, *string) {
+	gin.SetMode(gin.TestMode)
+	var seen string
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/ping", func(c *gin.Context) {
+		seen = logging.RequestID(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	return router, &seen
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	router, seen := setupRequestIDRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	id := w.Header().Get(RequestIDHeader)
+	if id == "" {
+		t.Fatal("Expected a generated X-Request-ID response header")
+	}
+	if *seen != id {
+		t.Errorf("Request context ID %q should match response header %q", *seen, id)
+	}
+}
+
+func TestRequestIDPropagated(t *testing.T) {
+	router, seen := setupRequestIDRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(RequestIDHeader, "upstream-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get(RequestIDHeader); got != "upstream-123" {
+		t.Errorf("Expected incoming ID to be echoed, got %q", got)
+	}
+	if *seen != "upstream-123" {
+		t.Errorf("Expected incoming ID in request context, got %q", *seen)
+	}
+}
+
+func TestRequestIDRejectsMalformed(t *testing.T) {
+	router, _ := setupRequestIDRouter()
+
+	for _, bad := range []string{"has space", "line\nbreak", strings.Repeat("a", 129)} {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(RequestIDHeader, bad)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if got := w.Header().Get(RequestIDHeader); got == bad || got == "" {
+			t.Errorf("Malformed ID %q should be replaced, got %q", bad, got)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package mode
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
+	"time"
 
 	"cc-platform/internal/headless"
 	"cc-platform/internal/monitoring"
@@ -32,6 +35,23 @@ type ModeManager struct {
 
 	// 模式切换回调
 	onModeSwitch func(containerID uint, mode ContainerMode, closedSessions int)
+
+	// 结束容器内残留的 claude 进程（重置模式时使用）
+	killProcesses func(ctx context.Context, containerID uint) error
+}
+
+// processKillTimeout 重置模式时结束 claude 进程的超时时间
+const processKillTimeout = 15 * time.Second
+
+// ResetResult 重置容器模式的结果
+type ResetResult struct {
+	ContainerID            uint          `json:"container_id"`
+	ClosedTerminalSessions int           `json:"closed_terminal_sessions"`
+	ClosedHeadlessSessions int           `json:"closed_headless_sessions"`
+	ClosedSessions         int           `json:"closed_sessions"`
+	ProcessesKilled        bool          `json:"processes_killed"`
+	KillError              string        `json:"kill_error,omitempty"`
+	Mode                   ContainerMode `json:"mode"` // 重置后的模式（未记录模式时的默认值）
 }
 
 // NewModeManager 创建新的 ModeManager
@@ -53,6 +73,31 @@ func (m *ModeManager) SetOnModeSwitch(callback func(containerID uint, mode Conta
 	m.onModeSwitch = callback
 }
 
+// HeadlessSessionSummary Headless 会话概要
+type HeadlessSessionSummary struct {
+	SessionID      string                 `json:"session_id"`
+	ConversationID uint                   `json:"conversation_id"`
+	State          headless.HeadlessState `json:"state"`
+	CurrentTurnID  uint                   `json:"current_turn_id,omitempty"`
+}
+
+// ModeInventory 容器当前模式及关联会话
+type ModeInventory struct {
+	ContainerID           uint                     `json:"container_id"`
+	Mode                  ContainerMode            `json:"mode"`
+	ModeRecorded          bool                     `json:"mode_recorded"` // false 表示尚未切换过模式，Mode 为默认值
+	TerminalSessionIDs    []string                 `json:"terminal_session_ids"`
+	TerminalSessionCount  int                      `json:"terminal_session_count"`
+	HeadlessSessions      []HeadlessSessionSummary `json:"headless_sessions"`
+	HeadlessSessionCount  int                      `json:"headless_session_count"`
+	RunningConversationID uint                     `json:"running_conversation_id,omitempty"` // 正在执行轮次的对话
+}
+
+// SetProcessKiller 设置结束容器内 claude 进程的函数
+func (m *ModeManager) SetProcessKiller(killer func(ctx context.Context, containerID uint) error) {
+	m.killProcesses = killer
+}
+
 // GetMode 获取容器当前模式
 func (m *ModeManager) GetMode(containerID uint) ContainerMode {
 	m.mu.RLock()
@@ -194,6 +239,96 @@ func (m *ModeManager) EnsureMode(containerID uint, dockerID string, targetMode C
 	}
 }
 
+// Inventory 获取容器当前模式以及活跃的终端和 Headless 会话
+func (m *ModeManager) Inventory(containerID uint, dockerID string) *ModeInventory {
+	m.mu.RLock()
+	_, recorded := m.containerModes[containerID]
+	m.mu.RUnlock()
+
+	inventory := &ModeInventory{
+		ContainerID:        containerID,
+		Mode:               m.GetMode(containerID),
+		ModeRecorded:       recorded,
+		TerminalSessionIDs: []string{},
+		HeadlessSessions:   []HeadlessSessionSummary{},
+	}
+
+	if m.terminalService != nil && dockerID != "" {
+		for _, info := range m.terminalService.GetSessionsForContainer(dockerID) {
+			inventory.TerminalSessionIDs = append(inventory.TerminalSessionIDs, info.ID)
+		}
+		sort.Strings(inventory.TerminalSessionIDs)
+	}
+	inventory.TerminalSessionCount = len(inventory.TerminalSessionIDs)
+
+	if m.headlessManager != nil {
+		for _, session := range m.headlessManager.GetAllSessionsForContainer(containerID) {
+			summary := HeadlessSessionSummary{
+				SessionID:      session.ID,
+				ConversationID: session.ConversationID,
+				State:          session.GetState(),
+				CurrentTurnID:  session.GetCurrentTurnID(),
+			}
+			inventory.HeadlessSessions = append(inventory.HeadlessSessions, summary)
+			if summary.State == headless.HeadlessStateRunning {
+				inventory.RunningConversationID = summary.ConversationID
+			}
+		}
+		sort.Slice(inventory.HeadlessSessions, func(i, j int) bool {
+			return inventory.HeadlessSessions[i].ConversationID < inventory.HeadlessSessions[j].ConversationID
+		})
+	}
+	inventory.HeadlessSessionCount = len(inventory.HeadlessSessions)
+
+	return inventory
+}
+
+// ResetMode 强制恢复卡在模式切换之间的容器：关闭容器的所有终端和 Headless 会话、
+// 清理监控会话、结束残留的 claude 进程，并清除记录的模式。结束进程失败不影响会话清理。
+func (m *ModeManager) ResetMode(containerID uint, dockerID string) *ResetResult {
+	log.Printf("[ModeManager] Resetting mode for container %d", containerID)
+
+	result := &ResetResult{ContainerID: containerID}
+
+	if m.terminalService != nil {
+		if dockerID != "" {
+			result.ClosedTerminalSessions = m.terminalService.CloseSessionsForDockerID(dockerID)
+		}
+		result.ClosedTerminalSessions += m.terminalService.CloseSessionsForContainer(containerID)
+	}
+	if m.headlessManager != nil {
+		result.ClosedHeadlessSessions = m.headlessManager.CloseSessionsForContainer(containerID)
+	}
+	if m.monitoringMgr != nil {
+		m.monitoringMgr.RemoveAllSessionsForContainer(containerID)
+	}
+	result.ClosedSessions = result.ClosedTerminalSessions + result.ClosedHeadlessSessions
+
+	if m.killProcesses != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), processKillTimeout)
+		err := m.killProcesses(ctx, containerID)
+		cancel()
+		if err != nil {
+			log.Printf("[ModeManager] Failed to kill claude processes in container %d: %v", containerID, err)
+			result.KillError = err.Error()
+		} else {
+			result.ProcessesKilled = true
+		}
+	}
+
+	m.ClearMode(containerID)
+	result.Mode = m.GetMode(containerID)
+
+	if m.onModeSwitch != nil {
+		m.onModeSwitch(containerID, result.Mode, result.ClosedSessions)
+	}
+
+	log.Printf("[ModeManager] Container %d mode reset, closed %d terminal and %d headless sessions",
+		containerID, result.ClosedTerminalSessions, result.ClosedHeadlessSessions)
+
+	return result
+}
+
 // ClearMode 清除容器模式（容器删除时调用）
 func (m *ModeManager) ClearMode(containerID uint) {
 	m.mu.Lock()
@@ -1,6 +1,17 @@
 package mode
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cc-platform/internal/headless"
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
 
 func TestModeManager_SwitchingAndCallbacks(t *testing.T) {
 	mgr := NewModeManager(nil, nil, nil)
@@ -66,3 +77,122 @@ func TestModeManager_EnsureMode(t *testing.T) {
 		t.Fatalf("expected 0 closed sessions, got %d", closed)
 	}
 }
+
+func newTestHeadlessManager(t *testing.T) *headless.HeadlessManager {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:modetest?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.HeadlessConversation{}, &models.HeadlessTurn{}, &models.HeadlessEvent{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	mgr := headless.NewHeadlessManager(db, nil)
+	t.Cleanup(func() { mgr.Close() })
+	return mgr
+}
+
+func TestModeManager_ResetModeCountsClosedSessions(t *testing.T) {
+	headlessMgr := newTestHeadlessManager(t)
+	for _, containerID := range []uint{3, 3, 4} {
+		if _, err := headlessMgr.CreateSession(containerID, "", "/app"); err != nil {
+			t.Fatalf("CreateSession error: %v", err)
+		}
+	}
+
+	mgr := NewModeManager(nil, headlessMgr, nil)
+	if _, err := mgr.SwitchToHeadless(3, "docker-3"); err != nil {
+		t.Fatalf("SwitchToHeadless error: %v", err)
+	}
+	var killed []uint
+	mgr.SetProcessKiller(func(ctx context.Context, containerID uint) error {
+		killed = append(killed, containerID)
+		return nil
+	})
+	var cbClosed int
+	mgr.SetOnModeSwitch(func(containerID uint, mode ContainerMode, closedSessions int) {
+		cbClosed = closedSessions
+	})
+
+	result := mgr.ResetMode(3, "docker-3")
+	if result.ClosedHeadlessSessions != 2 || result.ClosedTerminalSessions != 0 || result.ClosedSessions != 2 {
+		t.Errorf("expected 2 closed headless sessions, got %+v", result)
+	}
+	if !result.ProcessesKilled || len(killed) != 1 || killed[0] != 3 {
+		t.Errorf("expected claude processes to be killed once for container 3, got %+v (killed %v)", result, killed)
+	}
+	if cbClosed != 2 {
+		t.Errorf("callback closedSessions = %d, want 2", cbClosed)
+	}
+	if _, ok := mgr.GetAllModes()[3]; ok || result.Mode != ModeTUI {
+		t.Errorf("expected the stored mode to be cleared, got modes %v and result mode %s", mgr.GetAllModes(), result.Mode)
+	}
+	if got := len(headlessMgr.GetAllSessions()); got != 1 {
+		t.Errorf("expected the other container's session to survive, %d sessions left", got)
+	}
+
+	// 再次重置没有可关闭的会话；结束进程失败只记录错误
+	mgr.SetProcessKiller(func(ctx context.Context, containerID uint) error {
+		return errors.New("container is not running")
+	})
+	result = mgr.ResetMode(3, "docker-3")
+	if result.ClosedSessions != 0 || result.ProcessesKilled || result.KillError == "" {
+		t.Errorf("expected nothing closed and the kill error reported, got %+v", result)
+	}
+}
+
+func TestModeManager_Inventory(t *testing.T) {
+	headlessMgr := newTestHeadlessManager(t)
+	idle, err := headlessMgr.CreateSession(5, "", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	running, err := headlessMgr.CreateSession(5, "", "/app")
+	if err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+	running.SetState(headless.HeadlessStateRunning)
+	running.SetCurrentTurnID(12)
+	if _, err := headlessMgr.CreateSession(6, "", "/app"); err != nil {
+		t.Fatalf("CreateSession error: %v", err)
+	}
+
+	mgr := NewModeManager(nil, headlessMgr, nil)
+
+	// 尚未切换过模式：默认 TUI
+	inventory := mgr.Inventory(7, "docker-7")
+	if inventory.Mode != ModeTUI || inventory.ModeRecorded || inventory.HeadlessSessionCount != 0 || inventory.TerminalSessionCount != 0 {
+		t.Errorf("expected an empty default inventory, got %+v", inventory)
+	}
+
+	if _, err := mgr.SwitchToHeadless(5, "docker-5"); err != nil {
+		t.Fatalf("SwitchToHeadless error: %v", err)
+	}
+	inventory = mgr.Inventory(5, "docker-5")
+	if inventory.Mode != ModeHeadless || !inventory.ModeRecorded {
+		t.Errorf("expected recorded headless mode, got %+v", inventory)
+	}
+	if inventory.HeadlessSessionCount != 2 || len(inventory.HeadlessSessions) != 2 {
+		t.Fatalf("expected the container's 2 headless sessions, got %+v", inventory.HeadlessSessions)
+	}
+	if first := inventory.HeadlessSessions[0]; first.SessionID != idle.ID || first.State != headless.HeadlessStateIdle {
+		t.Errorf("expected the idle session first, got %+v", first)
+	}
+	if second := inventory.HeadlessSessions[1]; second.SessionID != running.ID || second.CurrentTurnID != 12 {
+		t.Errorf("expected the running session with its turn, got %+v", second)
+	}
+	if inventory.RunningConversationID != running.ConversationID {
+		t.Errorf("RunningConversationID = %d, want %d", inventory.RunningConversationID, running.ConversationID)
+	}
+
+	// 关闭的会话不计入
+	if err := headlessMgr.CloseSession(idle.ID); err != nil {
+		t.Fatalf("CloseSession error: %v", err)
+	}
+	if got := mgr.Inventory(5, "docker-5").HeadlessSessionCount; got != 1 {
+		t.Errorf("expected 1 headless session after closing one, got %d", got)
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Audited actions
+const (
+	AuditActionContainerCreate   = "container.create"
+	AuditActionContainerDelete   = "container.delete"
+	AuditActionContainerStart    = "container.start"
+	AuditActionContainerStop     = "container.stop"
+	AuditActionContainerRecreate = "container.recreate"
+	AuditActionContainerExec     = "container.exec"
+	AuditActionContainerPause    = "container.pause"
+	AuditActionContainerResume   = "container.resume"
+	AuditActionContainerResize   = "container.update_resources"
+	AuditActionInitCancel        = "container.cancel_init"
+	AuditActionModeReset         = "mode.reset"
+	AuditActionTerminalOpen      = "terminal.open"
+	AuditActionCommandCreate     = "command.create"
+	AuditActionCommandUpdate     = "command.update"
+	AuditActionCommandDelete     = "command.delete"
+	AuditActionProcessKill       = "process.kill"
+	AuditActionFileUpload        = "file.upload"
+	AuditActionFileDelete        = "file.delete"
+	AuditActionFileMkdir         = "file.mkdir"
+	AuditActionConfigInject      = "config.inject"
+	AuditActionConfigPropagate   = "config.propagate"
+	AuditActionConfigCopy        = "config.copy"
+	AuditActionConfigProfile     = "config.switch_profile"
+	AuditActionConfigRestore     = "config.restore_snapshot"
+	AuditActionMCPTest           = "mcp.test"
+	AuditActionCodeServerRotate  = "code_server.rotate_password"
+	AuditActionRepoClone         = "repo.clone"
+	AuditActionWorkspaceRestore  = "workspace.restore"
+	AuditActionDockerRemove      = "docker.remove"
+	AuditActionDockerPrune       = "docker.prune"
+	AuditActionDockerStop        = "docker.stop"
+	AuditActionPortAdd           = "port.add"
+	AuditActionPortRemove        = "port.remove"
+	AuditActionBackupImport      = "backup.import"
+)
+
+// AuditLog records a privileged action taken through the API: who did it, to which container,
+// from where and whether it succeeded. Request bodies are never stored, so commands, file
+// contents and secrets stay out of the log.
+type AuditLog struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+	Actor       string    `gorm:"size:100;index" json:"actor"` // Username from the JWT
+	Action      string    `gorm:"size:50;index" json:"action"`
+	ContainerID *uint     `gorm:"index" json:"container_id,omitempty"`
+	Target      string    `gorm:"size:500" json:"target,omitempty"` // What the action applied to within the container, e.g. a file path
+	Method      string    `gorm:"size:10" json:"method"`
+	Route       string    `gorm:"size:200" json:"route"` // Route pattern, never the raw URL, which can carry a token
+	Status      int       `json:"status"`
+	ClientIP    string    `gorm:"size:64" json:"client_ip"`
+	RequestID   string    `gorm:"size:128" json:"request_id,omitempty"`
+}
+
+// TableName specifies the table name for AuditLog
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
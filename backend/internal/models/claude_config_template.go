@@ -62,6 +62,23 @@ type ClaudeConfigTemplate struct {
 	// ArchiveData contains base64-encoded zip file data when IsArchive is true
 	// The zip should contain the skill folder structure (SKILL.md + scripts/resources)
 	ArchiveData string `gorm:"type:longtext" json:"archive_data,omitempty"`
+	// Files holds supporting files written next to SKILL.md, keyed by path relative to the skill directory
+	// Only applicable for ConfigTypeSkill; archive skills carry their files in the archive
+	Files SkillFiles `gorm:"type:text" json:"files,omitempty"`
+	// Injection counters, reported through Usage
+	InjectionCount int64      `gorm:"not null;default:0" json:"-"`
+	LastInjectedAt *time.Time `json:"-"`
+	// Usage is filled in by the API and not stored
+	Usage *TemplateUsage `gorm:"-" json:"usage,omitempty"`
+}
+
+// TemplateUsage summarizes how a config template is used across containers
+type TemplateUsage struct {
+	TemplateID     uint       `json:"template_id"`
+	ContainerCount int64      `json:"container_count"` // Live containers whose selected template IDs include it, plus for skills those auto-injecting all skills
+	InjectionCount int64      `json:"injection_count"` // Successful injections into any container, ever
+	LastInjectedAt *time.Time `json:"last_injected_at,omitempty"`
+	Unused         bool       `json:"unused"` // No live container uses it
 }
 
 // TableName specifies the table name for ClaudeConfigTemplate
@@ -85,11 +102,12 @@ type SkillMetadata struct {
 // InjectionStatus represents the result of configuration injection into a container
 // This is stored as JSON in the Container's InjectionStatus field
 type InjectionStatus struct {
-	ContainerID string           `json:"container_id"`
-	Successful  []string         `json:"successful"` // Template names that were successfully injected
-	Failed      []FailedTemplate `json:"failed"`     // Templates that failed and why
-	Warnings    []string         `json:"warnings"`   // General warnings during injection
-	InjectedAt  time.Time        `json:"injected_at"`
+	ContainerID   string           `json:"container_id"`
+	Successful    []string         `json:"successful"`               // Template names that were successfully injected
+	SuccessfulIDs []uint           `json:"successful_ids,omitempty"` // IDs of the successfully injected templates
+	Failed        []FailedTemplate `json:"failed"`                   // Templates that failed and why
+	Warnings      []string         `json:"warnings"`                 // General warnings during injection
+	InjectedAt    time.Time        `json:"injected_at"`
 }
 
 // FailedTemplate represents a template that failed to inject with the reason
@@ -131,3 +149,60 @@ func (i InjectionStatus) Value() (driver.Value, error) {
 	}
 	return json.Marshal(i)
 }
+
+// TemplateIDList is an ordered list of template IDs stored as a JSON array
+type TemplateIDList []uint
+
+// Scan implements the sql.Scanner interface for TemplateIDList
+func (l *TemplateIDList) Scan(value interface{}) error {
+	if value == nil {
+		*l = TemplateIDList{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan TemplateIDList: unsupported type %T", value)
+	}
+
+	if len(bytes) == 0 {
+		*l = TemplateIDList{}
+		return nil
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements the driver.Valuer interface for TemplateIDList
+func (l TemplateIDList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]uint(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// TemplateBundle is a named, ordered set of config templates that can be applied together
+type TemplateBundle struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	Name        string         `gorm:"not null;uniqueIndex" json:"name"`
+	Description string         `gorm:"type:text" json:"description,omitempty"`
+	// TemplateIDs are injected in this order when the bundle is applied
+	TemplateIDs TemplateIDList `gorm:"type:text" json:"template_ids"`
+}
+
+// TableName specifies the table name for TemplateBundle
+func (TemplateBundle) TableName() string {
+	return "template_bundles"
+}
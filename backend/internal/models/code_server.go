@@ -0,0 +1,16 @@
+package models
+
+import "database/sql/driver"
+
+// CodeServerExtensionList is a list of VS Code extension IDs stored as JSON in a text column
+type CodeServerExtensionList []string
+
+// Scan implements the sql.Scanner interface for CodeServerExtensionList
+func (l *CodeServerExtensionList) Scan(value interface{}) error {
+	return (*HeadlessFileList)(l).Scan(value)
+}
+
+// Value implements the driver.Valuer interface for CodeServerExtensionList
+func (l CodeServerExtensionList) Value() (driver.Value, error) {
+	return HeadlessFileList(l).Value()
+}
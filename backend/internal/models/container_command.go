@@ -0,0 +1,43 @@
+package models
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// ContainerCommand is a saved command of a container, such as build, test or lint, that is
+// run by name. The outcome of the latest run is kept with it.
+type ContainerCommand struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	ContainerID    uint           `gorm:"not null;uniqueIndex:idx_container_command_name,priority:1" json:"container_id"`
+	Name           string         `gorm:"not null;uniqueIndex:idx_container_command_name,priority:2" json:"name"`
+	Command        CommandArgList `gorm:"type:text;not null" json:"command"`
+	TimeoutSeconds int            `json:"timeout_seconds,omitempty"` // 0 = default exec timeout
+
+	// Latest run
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastExitCode   *int       `json:"last_exit_code,omitempty"`               // Missing when the run did not finish
+	LastError      string     `json:"last_error,omitempty"`                   // Why the run did not finish, e.g. a timeout
+	LastOutput     string     `gorm:"type:text" json:"last_output,omitempty"` // Tail of the combined stdout and stderr
+	LastDurationMs int64      `json:"last_duration_ms,omitempty"`
+}
+
+// TableName specifies the table name for ContainerCommand
+func (ContainerCommand) TableName() string {
+	return "container_commands"
+}
+
+// CommandArgList is a command and its arguments stored as JSON in a text column
+type CommandArgList []string
+
+// Scan implements the sql.Scanner interface for CommandArgList
+func (l *CommandArgList) Scan(value interface{}) error {
+	return (*HeadlessFileList)(l).Scan(value)
+}
+
+// Value implements the driver.Valuer interface for CommandArgList
+func (l CommandArgList) Value() (driver.Value, error) {
+	return HeadlessFileList(l).Value()
+}
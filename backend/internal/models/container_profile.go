@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// ContainerProfile is a named set of creation defaults. A create request naming the profile
+// gets every field it leaves empty filled in from the profile.
+type ContainerProfile struct {
+	ID              uint            `gorm:"primarykey" json:"id"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	Name            string          `gorm:"not null;uniqueIndex" json:"name"`
+	Description     string          `gorm:"type:text" json:"description,omitempty"`
+	Tags            HeadlessTagList `gorm:"type:text" json:"tags,omitempty"`
+	Image           string          `json:"image,omitempty"`
+	ResourceProfile string          `json:"resource_profile,omitempty"`
+	MemoryLimit     int64           `json:"memory_limit,omitempty"` // MB
+	CPULimit        float64         `json:"cpu_limit,omitempty"`    // Cores
+	InitStrategy    string          `json:"init_strategy,omitempty"`
+	PreInitScript   string          `gorm:"type:text" json:"pre_init_script,omitempty"`
+	// TemplateIDs and the templates of BundleID are injected when the request selects none
+	TemplateIDs      TemplateIDList `gorm:"type:text" json:"template_ids,omitempty"`
+	BundleID         *uint          `json:"bundle_id,omitempty"`
+	ProxyEnabled     bool           `json:"proxy_enabled,omitempty"`
+	ProxyServicePort int            `json:"proxy_service_port,omitempty"`
+	EnableCodeServer bool           `json:"enable_code_server,omitempty"`
+	RestartPolicy    string         `json:"restart_policy,omitempty"`
+	TTLMinutes       int            `json:"ttl_minutes,omitempty"`
+}
+
+// TableName specifies the table name for ContainerProfile
+func (ContainerProfile) TableName() string {
+	return "container_profiles"
+}
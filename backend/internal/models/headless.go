@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,11 +14,22 @@ import (
 // HeadlessConversation 表示一个完整的 Headless 对话
 type HeadlessConversation struct {
 	gorm.Model
-	SessionID       string         `gorm:"index;not null" json:"session_id"`         // HeadlessSession.ID
-	ContainerID     uint           `gorm:"index;not null" json:"container_id"`       // 关联的容器 ID
-	ClaudeSessionID string         `gorm:"index" json:"claude_session_id,omitempty"` // Claude 返回的 session_id（用于 --resume）
-	State           string         `gorm:"default:'idle'" json:"state"`              // running | idle | error | closed
-	Turns           []HeadlessTurn `gorm:"foreignKey:ConversationID" json:"turns,omitempty"`
+	SessionID       string          `gorm:"index;not null" json:"session_id"`         // HeadlessSession.ID
+	ContainerID     uint            `gorm:"index;not null" json:"container_id"`       // 关联的容器 ID
+	ClaudeSessionID string          `gorm:"index" json:"claude_session_id,omitempty"` // Claude 返回的 session_id（用于 --resume）
+	State           string          `gorm:"default:'idle'" json:"state"`              // running | idle | error | closed
+	SystemPrompt    string          `gorm:"type:text" json:"system_prompt,omitempty"` // 对话级系统提示词（每轮通过 --append-system-prompt 传入）
+	WorkDir         string          `json:"work_dir,omitempty"`                       // 对话开始时的工作目录
+	BaselineCommit  string          `json:"baseline_commit,omitempty"`                // 对话开始时工作目录的 git HEAD（用于统计本次对话的变更）
+	Tags            HeadlessTagList `gorm:"type:text" json:"tags,omitempty"`          // 用于整理和筛选对话的标签
+	ExtraArgs       HeadlessArgList `gorm:"type:text" json:"extra_args,omitempty"`    // 透传给 Claude CLI 的额外参数（已按白名单校验）
+	// 自动停止条件，0 表示不限制：达到后拒绝新的 prompt，执行中的轮次结束后关闭会话
+	MaxTurns           int `json:"max_turns,omitempty"`            // 最多执行的轮数（不含压缩轮次）
+	MaxDurationMinutes int `json:"max_duration_minutes,omitempty"` // 从对话创建起的最长运行时间
+	// 客户端全部断开且宽限期内无人重连时是否取消执行中的轮次，nil 表示使用平台默认值
+	CancelOnDisconnect *bool `json:"cancel_on_disconnect,omitempty"`
+
+	Turns []HeadlessTurn `gorm:"foreignKey:ConversationID" json:"turns,omitempty"`
 }
 
 // HeadlessTurn 表示一轮对话（用户输入 + Claude 响应）
@@ -26,7 +40,10 @@ type HeadlessTurn struct {
 
 	// 用户输入
 	UserPrompt   string `gorm:"type:text" json:"user_prompt"`
-	PromptSource string `gorm:"default:'user'" json:"prompt_source"` // user | strategy | monitoring
+	PromptSource string `gorm:"default:'user'" json:"prompt_source"` // user | strategy | monitoring | compact
+
+	// 随 prompt 引用的文件（相对于工作目录）
+	AttachedFiles HeadlessFileList `gorm:"type:text" json:"attached_files,omitempty"`
 
 	// Claude 响应（聚合后的完整响应）
 	AssistantResponse string `gorm:"type:text" json:"assistant_response,omitempty"`
@@ -36,6 +53,7 @@ type HeadlessTurn struct {
 	InputTokens  int     `json:"input_tokens"`
 	OutputTokens int     `json:"output_tokens"`
 	CostUSD      float64 `json:"cost_usd"`
+	CostSource   string  `json:"cost_source,omitempty"` // reported（stream 上报）| computed（按定价表计算）
 	DurationMS   int64   `json:"duration_ms"`
 
 	// 状态
@@ -43,6 +61,17 @@ type HeadlessTurn struct {
 	ErrorMessage string     `gorm:"type:text" json:"error_message,omitempty"`
 	CompletedAt  *time.Time `json:"completed_at,omitempty"`
 
+	// 此轮之后重置了 Claude 上下文（后续轮次不再 --resume 之前的会话）
+	ContextResetAfter bool `gorm:"default:false" json:"context_reset_after,omitempty"`
+
+	// 压缩轮次（prompt_source = compact）压缩前后的上下文 token 数
+	ContextTokensBefore int `json:"context_tokens_before,omitempty"`
+	ContextTokensAfter  int `json:"context_tokens_after,omitempty"`
+
+	// 原始事件的留存情况：达到每轮事件上限后不再记录；超过保留期后被清理（聚合响应仍保留）
+	EventsTruncated bool `gorm:"default:false" json:"events_truncated,omitempty"`
+	EventsPruned    bool `gorm:"default:false" json:"events_pruned,omitempty"`
+
 	// 关联的原始事件（用于详细查看）
 	Events []HeadlessEvent `gorm:"foreignKey:TurnID" json:"events,omitempty"`
 }
@@ -57,6 +86,8 @@ type HeadlessEvent struct {
 	EventType    string `gorm:"not null" json:"event_type"`         // system | assistant | user | result
 	EventSubtype string `json:"event_subtype,omitempty"`            // 子类型
 	RawJSON      string `gorm:"type:text;not null" json:"raw_json"` // 原始 JSON
+	Seq          uint64 `json:"seq,omitempty"`                      // 会话内的事件序号（断线重连时用于去重）
+	Encoding     string `gorm:"size:16" json:"-"`                   // RawJSON 的存储编码，读取时自动解码
 }
 
 // HeadlessConversation 状态常量
@@ -80,6 +111,7 @@ const (
 	HeadlessPromptSourceUser       = "user"
 	HeadlessPromptSourceStrategy   = "strategy"
 	HeadlessPromptSourceMonitoring = "monitoring"
+	HeadlessPromptSourceCompact    = "compact" // 上下文压缩（/compact）
 )
 
 // HeadlessEvent 类型常量
@@ -104,3 +136,69 @@ func (HeadlessTurn) TableName() string {
 func (HeadlessEvent) TableName() string {
 	return "headless_events"
 }
+
+// HeadlessFileList 文件路径列表，以 JSON 存储在 text 列中
+type HeadlessFileList []string
+
+// Scan implements the sql.Scanner interface for HeadlessFileList
+func (l *HeadlessFileList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan HeadlessFileList: unsupported type %T", value)
+	}
+
+	if len(bytes) == 0 {
+		*l = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements the driver.Valuer interface for HeadlessFileList
+func (l HeadlessFileList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// HeadlessArgList 命令行参数列表，以 JSON 存储在 text 列中
+type HeadlessArgList []string
+
+// Scan implements the sql.Scanner interface for HeadlessArgList
+func (l *HeadlessArgList) Scan(value interface{}) error {
+	return (*HeadlessFileList)(l).Scan(value)
+}
+
+// Value implements the driver.Valuer interface for HeadlessArgList
+func (l HeadlessArgList) Value() (driver.Value, error) {
+	return HeadlessFileList(l).Value()
+}
+
+// HeadlessTagList 对话标签列表，以 JSON 存储在 text 列中
+type HeadlessTagList []string
+
+// Scan implements the sql.Scanner interface for HeadlessTagList
+func (l *HeadlessTagList) Scan(value interface{}) error {
+	return (*HeadlessFileList)(l).Scan(value)
+}
+
+// Value implements the driver.Valuer interface for HeadlessTagList
+func (l HeadlessTagList) Value() (driver.Value, error) {
+	return HeadlessFileList(l).Value()
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+)
+
+// HeadlessEvent.Encoding 取值
+const (
+	HeadlessEventEncodingPlain = ""     // 原文
+	HeadlessEventEncodingGzip  = "gzip" // gzip 压缩后 base64 编码
+)
+
+// HeadlessEventCompressThreshold 超过该字节数的事件 JSON 压缩存储
+const HeadlessEventCompressThreshold = 2048
+
+// EncodeHeadlessEventJSON 对较大的事件 JSON 做 gzip 压缩并 base64 编码，压缩后不更小时保留原文
+func EncodeHeadlessEventJSON(raw string) (stored, encoding string) {
+	if len(raw) <= HeadlessEventCompressThreshold {
+		return raw, HeadlessEventEncodingPlain
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(raw)); err != nil {
+		return raw, HeadlessEventEncodingPlain
+	}
+	if err := zw.Close(); err != nil {
+		return raw, HeadlessEventEncodingPlain
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	if len(encoded) >= len(raw) {
+		return raw, HeadlessEventEncodingPlain
+	}
+	return encoded, HeadlessEventEncodingGzip
+}
+
+// DecodeHeadlessEventJSON 还原 EncodeHeadlessEventJSON 存储的事件 JSON
+func DecodeHeadlessEventJSON(stored, encoding string) (string, error) {
+	switch encoding {
+	case HeadlessEventEncodingPlain:
+		return stored, nil
+	case HeadlessEventEncodingGzip:
+		data, err := base64.StdEncoding.DecodeString(stored)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode event: %w", err)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress event: %w", err)
+		}
+		defer zr.Close()
+		raw, err := io.ReadAll(zr)
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress event: %w", err)
+		}
+		return string(raw), nil
+	default:
+		return "", fmt.Errorf("unknown event encoding: %q", encoding)
+	}
+}
+
+// AfterFind 查询后还原压缩存储的 RawJSON，调用方始终拿到原始 JSON。
+// 只查询部分列时需包含 encoding 列，否则压缩的事件不会被解码。
+func (e *HeadlessEvent) AfterFind(tx *gorm.DB) error {
+	if e.Encoding == HeadlessEventEncodingPlain {
+		return nil
+	}
+	raw, err := DecodeHeadlessEventJSON(e.RawJSON, e.Encoding)
+	if err != nil {
+		return err
+	}
+	e.RawJSON = raw
+	e.Encoding = HeadlessEventEncodingPlain
+	return nil
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyKey records the container created for a client-supplied Idempotency-Key so a
+// retried create request returns it instead of creating a duplicate. Keys are scoped per user
+// and can be pruned once ExpiresAt has passed. ContainerID is 0 while the creation is running.
+type IdempotencyKey struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	Username    string    `gorm:"uniqueIndex:idx_idempotency_user_key;not null" json:"username"`
+	Key         string    `gorm:"uniqueIndex:idx_idempotency_user_key;not null" json:"key"`
+	RequestHash string    `gorm:"not null" json:"-"`
+	ContainerID uint      `json:"container_id"`
+	ExpiresAt   time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
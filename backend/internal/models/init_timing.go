@@ -0,0 +1,98 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Init timing stage names, recorded in the order they run
+const (
+	InitStageStartup    = "startup"
+	InitStageInject     = "inject"
+	InitStageClone      = "clone"
+	InitStagePreInit    = "pre_init"
+	InitStageClaudeInit = "init"
+	InitStageCodeServer = "code_server"
+)
+
+// Init timing stage outcomes
+const (
+	InitStageStatusRunning   = "running"
+	InitStageStatusSucceeded = "succeeded"
+	InitStageStatusFailed    = "failed"
+	InitStageStatusSkipped   = "skipped"
+)
+
+// InitStageTiming records when a single initialization stage ran and how it ended
+type InitStageTiming struct {
+	Stage      string     `json:"stage"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+	DurationMS int64      `json:"duration_ms"`
+}
+
+// ContainerInitTiming holds per-stage timings of a container's most recent start/initialization.
+// This is stored as JSON in the Container's InitTiming field
+type ContainerInitTiming struct {
+	Stages []InitStageTiming `json:"stages"`
+}
+
+// Begin records the start of a stage and returns its index for End
+func (t *ContainerInitTiming) Begin(stage string) int {
+	t.Stages = append(t.Stages, InitStageTiming{
+		Stage:     stage,
+		Status:    InitStageStatusRunning,
+		StartedAt: time.Now(),
+	})
+	return len(t.Stages) - 1
+}
+
+// End marks a stage started with Begin as finished with the given status
+func (t *ContainerInitTiming) End(index int, status string) {
+	if index < 0 || index >= len(t.Stages) {
+		return
+	}
+	now := time.Now()
+	stage := &t.Stages[index]
+	stage.Status = status
+	stage.EndedAt = &now
+	stage.DurationMS = now.Sub(stage.StartedAt).Milliseconds()
+}
+
+// Scan implements the sql.Scanner interface for ContainerInitTiming
+func (t *ContainerInitTiming) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan ContainerInitTiming: unsupported type %T", value)
+	}
+
+	if len(bytes) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(bytes, t)
+}
+
+// Value implements the driver.Valuer interface for ContainerInitTiming
+func (t ContainerInitTiming) Value() (driver.Value, error) {
+	if len(t.Stages) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
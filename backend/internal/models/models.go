@@ -36,19 +36,42 @@ type Container struct {
 	gorm.Model
 	DockerID       string `gorm:"uniqueIndex" json:"docker_id"`
 	Name           string `gorm:"not null" json:"name"`
+	PoolName       string `json:"-"`           // warm pool name it was pre-created under; its managed volumes keep it
 	Status         string `json:"status"`      // created, running, stopped, deleted
 	InitStatus     string `json:"init_status"` // pending, cloning, initializing, ready, failed
 	InitMessage    string `json:"init_message,omitempty"`
+	InitProgress   int    `json:"init_progress"`                          // 0-100, derived from the finished init stages
 	GitRepoURL     string `json:"git_repo_url,omitempty"`                 // GitHub repo URL to clone
 	GitRepoName    string `json:"git_repo_name,omitempty"`                // GitHub repo name
 	WorkDir        string `json:"work_dir,omitempty" gorm:"default:/app"` // Working directory inside container, default: /app
 	SkipClaudeInit bool   `json:"skip_claude_init"`                       // Skip Claude Code initialization
 	// Claude Config Management fields
-	SkipGitRepo         bool             `json:"skip_git_repo"`                               // Allow creating container without GitHub repository
-	EnableYoloMode      bool             `json:"enable_yolo_mode"`                            // Enable YOLO mode (--dangerously-skip-permissions)
-	RunAsRoot           bool             `json:"run_as_root"`                                 // Run container as root user (default: false, runs as dev user)
-	AutoInjectAllSkills bool             `json:"auto_inject_all_skills"`                      // Inject all skill templates during initialization
-	InjectionStatus     *InjectionStatus `gorm:"type:text" json:"injection_status,omitempty"` // JSON serialized config injection status
+	SkipGitRepo         bool                 `json:"skip_git_repo"`                                    // Allow creating container without GitHub repository
+	EnableYoloMode      bool                 `json:"enable_yolo_mode"`                                 // Enable YOLO mode (--dangerously-skip-permissions)
+	RunAsRoot           bool                 `json:"run_as_root"`                                      // Run container as root user (default: false, runs as dev user)
+	RunAsUser           string               `json:"run_as_user,omitempty"`                            // Username or uid[:gid] the container runs as (empty: image's user)
+	AutoInjectAllSkills bool                 `json:"auto_inject_all_skills"`                           // Inject all skill templates during initialization
+	InjectionStatus     *InjectionStatus     `gorm:"type:text" json:"injection_status,omitempty"`      // JSON serialized config injection status
+	InitTiming          *ContainerInitTiming `gorm:"type:text" json:"init_timing,omitempty"`           // JSON serialized per-stage init timings
+	PreInitScript       string               `gorm:"type:text" json:"pre_init_script,omitempty"`       // Bash script run after clone, before Claude init
+	InitStrategy        string               `json:"init_strategy,omitempty"`                          // claude, script, both or none; empty for containers created before strategies
+	InitCallbackURL     string               `json:"init_callback_url,omitempty"`                      // Notified with a signed POST when init is ready or failed
+	HostMounts          HostMountList        `gorm:"type:text" json:"host_mounts,omitempty"`           // Allowlisted host directories bind-mounted into the container
+	SelectedTemplateIDs TemplateIDList       `gorm:"type:text" json:"selected_template_ids,omitempty"` // Config templates picked at creation or injected later
+	// Custom image from an allowlisted registry; empty uses the platform base image
+	Image string `json:"image,omitempty"`
+	// Claude CLI version last detected in the container, and the version pinned at creation (empty: the image's)
+	ClaudeVersion    string `json:"claude_version,omitempty"`
+	ClaudeVersionPin string `json:"claude_version_pin,omitempty"`
+	// Per-container overrides of the env profile's API base, model and auth token (stored encrypted)
+	ClaudeAPIBase   string `json:"claude_api_base,omitempty"`
+	ClaudeModel     string `json:"claude_model,omitempty"`
+	ClaudeAuthToken string `json:"-"`
+	// Whether Git LFS files are pulled after cloning a repository that uses LFS (nil = yes)
+	CloneLFS *bool `json:"clone_lfs,omitempty"`
+	// User-editable notes and free-form key/value metadata about the container
+	Notes    string            `gorm:"type:text" json:"notes,omitempty"`
+	Metadata ContainerMetadata `gorm:"type:text" json:"metadata,omitempty"`
 	// Resource configuration
 	MemoryLimit     int64   `json:"memory_limit,omitempty"` // Memory limit in bytes (0 = default 2GB or unlimited when MemoryUnlimited=true)
 	MemoryUnlimited bool    `json:"memory_unlimited"`       // Disable Docker memory limits
@@ -56,6 +79,13 @@ type Container struct {
 	CPUUnlimited    bool    `json:"cpu_unlimited"`          // Disable Docker CPU quota limits
 	GPUEnabled      bool    `json:"gpu_enabled"`            // Enable GPU passthrough
 	GPUCount        int     `json:"gpu_count,omitempty"`    // -1 means all GPUs
+	// Restart policy: no, on-failure (with optional max retries) or always
+	RestartPolicy     string `json:"restart_policy,omitempty"`
+	RestartMaxRetries int    `json:"restart_max_retries,omitempty"`
+	// Time-to-live: the container is deleted once ExpiresAt passes (TTLMinutes 0 = never)
+	TTLMinutes     int        `json:"ttl_minutes,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	ExpiryWarnedAt *time.Time `json:"expiry_warned_at,omitempty"` // When the expiry warning callback was sent
 	// Port mapping (legacy direct port binding)
 	ExposedPorts string `json:"exposed_ports,omitempty"` // JSON array of port mappings
 	// Traefik proxy configuration
@@ -63,10 +93,20 @@ type Container struct {
 	ProxyDomain  string `json:"proxy_domain,omitempty"` // Subdomain for domain-based access (e.g., "myapp" -> myapp.containers.domain.com)
 	ProxyPort    int    `json:"proxy_port,omitempty"`   // Direct port access (e.g., 9001)
 	ServicePort  int    `json:"service_port,omitempty"` // Container internal service port (e.g., 3000)
+	// Whether Traefik routes to the container: active, or pending while Traefik is unavailable (empty = no routing)
+	ProxyStatus       string `gorm:"size:20" json:"proxy_status,omitempty"`
+	ProxyStatusReason string `json:"proxy_status_reason,omitempty"` // Why the proxy is pending
 	// code-server configuration
-	EnableCodeServer bool   `json:"enable_code_server"`           // Enable code-server (Web VS Code)
-	CodeServerPort   int    `json:"code_server_port"`             // code-server port (host port for direct access, or internal port 8443)
-	CodeServerDomain string `json:"code_server_domain,omitempty"` // code-server subdomain (e.g., "mycontainer.code.example.com")
+	EnableCodeServer bool `json:"enable_code_server"` // Enable code-server (Web VS Code)
+	CodeServerPort   int  `json:"code_server_port"`   // code-server port (host port for direct access, or internal port 8443)
+	// Host port mapped to code-server without subdomain routing, reserved while the container exists
+	CodeServerHostPort int    `json:"code_server_host_port,omitempty"`
+	CodeServerDomain   string `json:"code_server_domain,omitempty"` // code-server subdomain (e.g., "mycontainer.code.example.com")
+	// code-server auth: "none" (default) or "password"; the password is stored encrypted
+	CodeServerAuth     string `json:"code_server_auth,omitempty"`
+	CodeServerPassword string `json:"-"`
+	// Extensions installed after code-server starts on a new container
+	CodeServerExtensions CodeServerExtensionList `gorm:"type:text" json:"code_server_extensions,omitempty"`
 	// Configuration profile references (nil = use default)
 	GitHubTokenID           *uint      `json:"github_token_id,omitempty"`
 	EnvVarsProfileID        *uint      `json:"env_vars_profile_id,omitempty"`
@@ -94,6 +134,15 @@ type GitHubToken struct {
 	IsDefault bool   `gorm:"default:false" json:"is_default"`
 }
 
+// RegistryCredential holds the login for a private container image registry
+type RegistryCredential struct {
+	gorm.Model
+	Registry string `gorm:"uniqueIndex;not null" json:"registry"` // Registry host, e.g. registry.example.com:5000
+	Username string `gorm:"not null" json:"username"`
+	Password string `gorm:"type:text;not null" json:"-"` // Encrypted, not exposed in JSON
+	Remark   string `gorm:"type:text" json:"remark,omitempty"`
+}
+
 // EnvVarsProfile represents a group of environment variables
 type EnvVarsProfile struct {
 	gorm.Model
@@ -119,9 +168,16 @@ const (
 	ContainerStatusCreated = "created"
 	ContainerStatusRunning = "running"
 	ContainerStatusStopped = "stopped"
+	ContainerStatusPaused  = "paused"
 	ContainerStatusDeleted = "deleted"
 )
 
+// Proxy status constants
+const (
+	ProxyStatusActive  = "active"
+	ProxyStatusPending = "pending" // Traefik was unavailable; activated once it recovers
+)
+
 // ContainerInitStatus constants
 const (
 	InitStatusPending      = "pending"
@@ -129,14 +185,16 @@ const (
 	InitStatusInitializing = "initializing"
 	InitStatusReady        = "ready"
 	InitStatusFailed       = "failed"
+	InitStatusCancelled    = "cancelled" // Stopped or deleted while initializing
 )
 
 // ContainerLog represents a log entry for container operations
 type ContainerLog struct {
 	gorm.Model
 	ContainerID uint   `gorm:"index" json:"container_id"`
-	Level       string `json:"level"` // info, warn, error
-	Stage       string `json:"stage"` // startup, clone, init, ready
+	RequestID   string `gorm:"index" json:"request_id,omitempty"` // X-Request-ID of the API call that triggered the entry
+	Level       string `json:"level"`                             // info, warn, error
+	Stage       string `json:"stage"`                             // startup, clone, init, ready
 	Message     string `gorm:"type:text" json:"message"`
 }
 
@@ -185,6 +243,7 @@ const (
 	LogStageClone   = "clone"
 	LogStageInit    = "init"
 	LogStageReady   = "ready"
+	LogStageMode    = "mode" // TUI / headless mode switches
 )
 
 // ==================== PTY Automation Monitoring Models ====================
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// PromptTemplate is a reusable headless prompt. Content may contain {{VAR}} placeholders
+// that are filled in when the prompt is sent. Unlike ClaudeConfigTemplate it is never
+// injected into containers.
+type PromptTemplate struct {
+	ID          uint            `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Name        string          `gorm:"not null;uniqueIndex" json:"name"`
+	Content     string          `gorm:"type:text;not null" json:"content"`
+	Description string          `gorm:"type:text" json:"description,omitempty"`
+	Tags        HeadlessTagList `gorm:"type:text" json:"tags,omitempty"`
+}
+
+// TableName specifies the table name for PromptTemplate
+func (PromptTemplate) TableName() string {
+	return "prompt_templates"
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RevokedToken records a JWT that was rotated out by a refresh and must no longer be accepted.
+// Only the SHA-256 hash of the token is stored; rows can be pruned once ExpiresAt has passed.
+type RevokedToken struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	TokenHash string    `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
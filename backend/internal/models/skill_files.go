@@ -0,0 +1,47 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// SkillFiles maps the relative paths of a skill's supporting files to their content, stored as JSON in a text column
+type SkillFiles map[string]string
+
+// Scan implements the sql.Scanner interface for SkillFiles
+func (m *SkillFiles) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan SkillFiles: unsupported type %T", value)
+	}
+
+	if len(bytes) == 0 {
+		*m = nil
+		return nil
+	}
+
+	return json.Unmarshal(bytes, m)
+}
+
+// Value implements the driver.Valuer interface for SkillFiles
+func (m SkillFiles) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// WorkspaceArchive is a container's work directory kept on the server, taken when the
+// container was deleted with its workspace preserved. The gzipped tar lives in the
+// retention directory under DATA_DIR and can be restored into another container.
+type WorkspaceArchive struct {
+	gorm.Model
+	ContainerName string `gorm:"not null" json:"container_name"`
+	GitRepoURL    string `json:"git_repo_url,omitempty"`
+	WorkDir       string `json:"work_dir"`                // Work directory the archive was taken from
+	FileName      string `gorm:"not null" json:"-"`       // Archive file in the retention directory
+	Files         int    `json:"files"`                   // Regular files in the archive
+	SizeBytes     int64  `json:"size_bytes"`              // Size of the gzipped archive on disk
+	ContentBytes  int64  `json:"content_bytes,omitempty"` // Total size of the archived files
+}
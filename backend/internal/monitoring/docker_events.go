@@ -21,6 +21,9 @@ type DockerEventListener struct {
 	running      bool
 	closed       bool // Whether Close has been called
 	mu           sync.Mutex
+
+	// pauseHandler is notified when a container is paused or unpaused
+	pauseHandler func(dockerID string, paused bool)
 }
 
 // NewDockerEventListener creates a new Docker event listener.
@@ -40,6 +43,14 @@ func NewDockerEventListener(manager *Manager) (*DockerEventListener, error) {
 	}, nil
 }
 
+// SetPauseHandler registers a callback for container pause and unpause events.
+// It must be called before Start.
+func (l *DockerEventListener) SetPauseHandler(handler func(dockerID string, paused bool)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pauseHandler = handler
+}
+
 // Start begins listening for Docker events.
 func (l *DockerEventListener) Start() error {
 	l.mu.Lock()
@@ -148,6 +159,26 @@ func (l *DockerEventListener) handleEvent(event events.Message) {
 	case "stop":
 		log.Printf("[DockerEventListener] Container stopped: %s (%s)", containerName, containerID[:12])
 		l.onContainerStop(containerID, containerName)
+
+	case "pause":
+		log.Printf("[DockerEventListener] Container paused: %s (%s)", containerName, containerID[:12])
+		l.onContainerPause(containerID, true)
+
+	case "unpause":
+		log.Printf("[DockerEventListener] Container unpaused: %s (%s)", containerName, containerID[:12])
+		l.onContainerPause(containerID, false)
+	}
+}
+
+// onContainerPause handles container pause and unpause events.
+// Monitoring sessions are kept: a paused container resumes with its processes intact.
+func (l *DockerEventListener) onContainerPause(dockerID string, paused bool) {
+	l.mu.Lock()
+	handler := l.pauseHandler
+	l.mu.Unlock()
+
+	if handler != nil {
+		handler(dockerID, paused)
 	}
 }
 
@@ -0,0 +1,74 @@
+package monitoring
+
+import (
+	"sync"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+)
+
+// AutomationLogBroadcaster fans out newly written automation logs to live subscribers.
+type AutomationLogBroadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[*automationLogSubscriber]struct{}
+}
+
+// automationLogSubscriber receives logs for one container, or all containers when containerID is 0.
+type automationLogSubscriber struct {
+	containerID uint
+	ch          chan models.AutomationLog
+}
+
+// NewAutomationLogBroadcaster creates a new broadcaster with no subscribers.
+func NewAutomationLogBroadcaster() *AutomationLogBroadcaster {
+	return &AutomationLogBroadcaster{
+		subscribers: make(map[*automationLogSubscriber]struct{}),
+	}
+}
+
+// Subscribe registers a subscriber for new logs of containerID (0 = all containers).
+// The returned function unsubscribes and closes the channel; it is safe to call more than once.
+func (b *AutomationLogBroadcaster) Subscribe(containerID uint) (<-chan models.AutomationLog, func()) {
+	sub := &automationLogSubscriber{
+		containerID: containerID,
+		ch:          make(chan models.AutomationLog, constants.AutomationLogStreamBuffer),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, sub)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+}
+
+// Publish sends a log to every matching subscriber.
+// It never blocks: subscribers whose buffer is full miss the entry.
+func (b *AutomationLogBroadcaster) Publish(entry models.AutomationLog) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		if sub.containerID != 0 && sub.containerID != entry.ContainerID {
+			continue
+		}
+		select {
+		case sub.ch <- entry:
+		default:
+		}
+	}
+}
+
+// SubscriberCount returns the number of active subscribers.
+func (b *AutomationLogBroadcaster) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
@@ -41,16 +41,18 @@ type StrategyInfo struct {
 
 // DefaultStrategyEngine implements the StrategyEngine interface.
 type DefaultStrategyEngine struct {
-	db         *gorm.DB
-	strategies map[string]Strategy
-	mu         sync.RWMutex
+	db             *gorm.DB
+	strategies     map[string]Strategy
+	mu             sync.RWMutex
+	logBroadcaster *AutomationLogBroadcaster
 }
 
 // NewStrategyEngine creates a new strategy engine.
 func NewStrategyEngine(db *gorm.DB) *DefaultStrategyEngine {
 	engine := &DefaultStrategyEngine{
-		db:         db,
-		strategies: make(map[string]Strategy),
+		db:             db,
+		strategies:     make(map[string]Strategy),
+		logBroadcaster: NewAutomationLogBroadcaster(),
 	}
 
 	// Register default strategies
@@ -164,7 +166,15 @@ func (e *DefaultStrategyEngine) logExecution(session *MonitoringSession, strateg
 
 	if err := e.db.Create(log).Error; err != nil {
 		fmt.Printf("Failed to log automation execution: %v\n", err)
+		return
 	}
+
+	e.logBroadcaster.Publish(*log)
+}
+
+// LogBroadcaster returns the broadcaster that receives every automation log written by the engine.
+func (e *DefaultStrategyEngine) LogBroadcaster() *AutomationLogBroadcaster {
+	return e.logBroadcaster
 }
 
 // ValidateConfig validates the configuration for a specific strategy.
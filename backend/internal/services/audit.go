@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"cc-platform/internal/logging"
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Page sizes of the audit log listing
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 1000
+)
+
+// AuditFilter selects audit log entries. Zero values don't filter.
+type AuditFilter struct {
+	ContainerID *uint
+	Actor       string
+	Action      string
+	From        *time.Time
+	To          *time.Time
+	Page        int
+	PageSize    int
+}
+
+// AuditLogPage is one page of audit log entries, newest first
+type AuditLogPage struct {
+	Entries  []models.AuditLog `json:"entries"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// AuditService stores and queries the audit log of privileged actions
+type AuditService struct {
+	db *gorm.DB
+}
+
+// NewAuditService creates a new AuditService
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Record stores an audit log entry. A failure is logged rather than returned so auditing
+// never fails the action it describes.
+func (s *AuditService) Record(ctx context.Context, entry *models.AuditLog) {
+	if err := s.db.WithContext(context.WithoutCancel(ctx)).Create(entry).Error; err != nil {
+		logging.FromContext(ctx, "Audit").Printf("Failed to record %s by %s: %v", entry.Action, entry.Actor, err)
+	}
+}
+
+// List returns the audit log entries matching the filter, newest first
+func (s *AuditService) List(filter AuditFilter) (*AuditLogPage, error) {
+	query := s.db.Model(&models.AuditLog{})
+	if filter.ContainerID != nil {
+		query = query.Where("container_id = ?", *filter.ContainerID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
+	page := &AuditLogPage{Page: filter.Page, PageSize: filter.PageSize}
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.PageSize < 1 {
+		page.PageSize = defaultAuditPageSize
+	}
+	if page.PageSize > maxAuditPageSize {
+		page.PageSize = maxAuditPageSize
+	}
+
+	if err := query.Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+	page.Entries = []models.AuditLog{}
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((page.Page - 1) * page.PageSize).Limit(page.PageSize).
+		Find(&page.Entries).Error; err != nil {
+		return nil, err
+	}
+	return page, nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"cc-platform/internal/config"
@@ -11,6 +12,7 @@ import (
 	"cc-platform/pkg/crypto"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -18,6 +20,8 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInvalidToken       = errors.New("invalid token")
 	ErrTokenExpired       = errors.New("token expired")
+	ErrTokenRevoked       = errors.New("token revoked")
+	ErrLoginLocked        = errors.New("login temporarily locked")
 	ErrAuthInitFailed     = errors.New("auth service initialization failed")
 )
 
@@ -31,13 +35,25 @@ type Claims struct {
 type AuthService struct {
 	db     *gorm.DB
 	config *config.Config
+
+	// revoked caches hashes of rotated-out tokens (hash -> token expiry) so verification needs no DB read
+	revokedMu sync.RWMutex
+	revoked   map[string]time.Time
+
+	lockout *loginLockout
+
+	// dummyHash is checked against when the username is unknown so both paths cost one bcrypt comparison
+	dummyHashOnce sync.Once
+	dummyHash     string
 }
 
 // NewAuthService creates a new AuthService
 func NewAuthService(db *gorm.DB, cfg *config.Config) (*AuthService, error) {
 	svc := &AuthService{
-		db:     db,
-		config: cfg,
+		db:      db,
+		config:  cfg,
+		revoked: make(map[string]time.Time),
+		lockout: newLoginLockout(cfg),
 	}
 
 	// Ensure admin user exists
@@ -45,6 +61,10 @@ func NewAuthService(db *gorm.DB, cfg *config.Config) (*AuthService, error) {
 		return nil, fmt.Errorf("%w: %v", ErrAuthInitFailed, err)
 	}
 
+	if err := svc.loadRevokedTokens(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAuthInitFailed, err)
+	}
+
 	return svc, nil
 }
 
@@ -79,17 +99,28 @@ func (s *AuthService) ensureAdminUser() error {
 	return nil
 }
 
-// Login authenticates a user and returns a JWT token
-func (s *AuthService) Login(username, password string) (string, error) {
+// Login authenticates a user and returns a JWT token.
+// Repeated failures from the same client IP lock the username for a cooldown (ErrLoginLocked).
+func (s *AuthService) Login(username, password, clientIP string) (string, error) {
+	if s.lockout.lockedFor(username, clientIP) > 0 {
+		return "", ErrLoginLocked
+	}
+
 	var user models.User
 	if err := s.db.Where("username = ?", username).First(&user).Error; err != nil {
+		// Compare against a dummy hash so unknown usernames take as long as wrong passwords
+		crypto.CheckPassword(password, s.getDummyHash())
+		s.lockout.recordFailure(username, clientIP)
 		return "", ErrInvalidCredentials
 	}
 
 	if !crypto.CheckPassword(password, user.PasswordHash) {
+		s.lockout.recordFailure(username, clientIP)
 		return "", ErrInvalidCredentials
 	}
 
+	s.lockout.reset(username, clientIP)
+
 	// Generate JWT token
 	token, err := s.generateToken(username)
 	if err != nil {
@@ -99,6 +130,24 @@ func (s *AuthService) Login(username, password string) (string, error) {
 	return token, nil
 }
 
+// LoginLockedFor returns how long logins for username from clientIP remain locked, or 0
+func (s *AuthService) LoginLockedFor(username, clientIP string) time.Duration {
+	return s.lockout.lockedFor(username, clientIP)
+}
+
+// getDummyHash returns a bcrypt hash of a random value, computed once
+func (s *AuthService) getDummyHash() string {
+	s.dummyHashOnce.Do(func() {
+		hash, err := crypto.HashPassword(uuid.New().String())
+		if err != nil {
+			log.Printf("Warning: failed to compute dummy password hash: %v", err)
+			return
+		}
+		s.dummyHash = hash
+	})
+	return s.dummyHash
+}
+
 // VerifyToken validates a JWT token and returns the claims
 func (s *AuthService) VerifyToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
@@ -117,20 +166,47 @@ func (s *AuthService) VerifyToken(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
+	if s.isRevoked(tokenString) {
+		return nil, ErrTokenRevoked
+	}
+
 	return claims, nil
 }
 
+// TokenTTL returns the lifetime of newly issued tokens
+func (s *AuthService) TokenTTL() time.Duration {
+	if s.config.AccessTokenTTL > 0 {
+		return s.config.AccessTokenTTL
+	}
+	return defaultAccessTokenTTL
+}
+
 // generateToken creates a new JWT token for a user
 func (s *AuthService) generateToken(username string) (string, error) {
+	token, _, err := s.issueToken(username)
+	return token, err
+}
+
+// issueToken creates a new JWT token for a user and returns it with its expiry time.
+// Each token carries a random ID so tokens issued within the same second still differ.
+func (s *AuthService) issueToken(username string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.TokenTTL())
 	claims := &Claims{
 		Username: username,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "cc-platform",
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.config.JWTSecret))
+	signed, err := token.SignedString([]byte(s.config.JWTSecret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	// NumericDate truncates to seconds; report the expiry the token actually carries
+	return signed, claims.ExpiresAt.Time, nil
 }
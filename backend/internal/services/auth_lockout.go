@@ -0,0 +1,110 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"cc-platform/internal/config"
+)
+
+const (
+	// defaultLoginMaxFailures is used when no failed-login threshold is configured
+	defaultLoginMaxFailures = 5
+	// defaultLoginLockoutDuration is used when no lockout cooldown is configured
+	defaultLoginLockoutDuration = 15 * time.Minute
+)
+
+// loginAttempts tracks failed logins for one username+IP pair
+type loginAttempts struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// loginLockout locks a username+IP pair for a cooldown after repeated failed logins.
+// Unknown usernames are tracked the same way so lockout does not reveal which accounts exist.
+type loginLockout struct {
+	mu          sync.Mutex
+	attempts    map[string]*loginAttempts
+	maxFailures int
+	duration    time.Duration
+	now         func() time.Time
+}
+
+// newLoginLockout creates a lockout tracker from the configured thresholds
+func newLoginLockout(cfg *config.Config) *loginLockout {
+	maxFailures := cfg.LoginMaxFailures
+	if maxFailures <= 0 {
+		maxFailures = defaultLoginMaxFailures
+	}
+	duration := cfg.LoginLockoutDuration
+	if duration <= 0 {
+		duration = defaultLoginLockoutDuration
+	}
+	return &loginLockout{
+		attempts:    make(map[string]*loginAttempts),
+		maxFailures: maxFailures,
+		duration:    duration,
+		now:         time.Now,
+	}
+}
+
+// lockoutKey identifies the username+IP pair attempts are counted against
+func lockoutKey(username, clientIP string) string {
+	return username + "|" + clientIP
+}
+
+// lockedFor returns how long the pair remains locked, or 0 if logins are allowed
+func (l *loginLockout) lockedFor(username, clientIP string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a, ok := l.attempts[lockoutKey(username, clientIP)]
+	if !ok {
+		return 0
+	}
+	if remaining := a.lockedUntil.Sub(l.now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// recordFailure counts a failed login and locks the pair once the threshold is reached.
+// Failures older than the lockout duration are forgotten before counting.
+func (l *loginLockout) recordFailure(username, clientIP string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.pruneLocked(now)
+
+	key := lockoutKey(username, clientIP)
+	a, ok := l.attempts[key]
+	if !ok {
+		a = &loginAttempts{}
+		l.attempts[key] = a
+	}
+
+	a.failures++
+	a.lastFailure = now
+	if a.failures >= l.maxFailures {
+		a.lockedUntil = now.Add(l.duration)
+		a.failures = 0
+	}
+}
+
+// reset clears the failure count after a successful login
+func (l *loginLockout) reset(username, clientIP string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.attempts, lockoutKey(username, clientIP))
+}
+
+// pruneLocked drops pairs that are neither locked nor failed recently. The caller must hold mu.
+func (l *loginLockout) pruneLocked(now time.Time) {
+	for key, a := range l.attempts {
+		if now.After(a.lockedUntil) && now.Sub(a.lastFailure) > l.duration {
+			delete(l.attempts, key)
+		}
+	}
+}
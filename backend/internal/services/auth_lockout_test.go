@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeLockoutClock lets tests move the lockout tracker's notion of time
+type fakeLockoutClock struct{ now time.Time }
+
+func (c *fakeLockoutClock) Now() time.Time { return c.now }
+
+func setupLockoutTest(t *testing.T, maxFailures int, duration time.Duration) (*AuthService, *fakeLockoutClock) {
+	svc, _ := setupAuthRefreshTest(t, time.Hour, false)
+	svc.config.LoginMaxFailures = maxFailures
+	svc.config.LoginLockoutDuration = duration
+	svc.lockout = newLoginLockout(svc.config)
+
+	clock := &fakeLockoutClock{now: time.Now()}
+	svc.lockout.now = clock.Now
+	return svc, clock
+}
+
+func TestLoginLocksAfterRepeatedFailures(t *testing.T) {
+	svc, _ := setupLockoutTest(t, 3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if _, err := svc.Login("admin", "wrong", "10.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Attempt %d: expected ErrInvalidCredentials, got %v", i+1, err)
+		}
+	}
+
+	// Even the correct password is refused while locked
+	if _, err := svc.Login("admin", "testpassword123", "10.0.0.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Expected ErrLoginLocked, got %v", err)
+	}
+	if d := svc.LoginLockedFor("admin", "10.0.0.1"); d <= 0 || d > time.Minute {
+		t.Errorf("Expected lock of up to 1m, got %s", d)
+	}
+
+	// Other clients are unaffected
+	if _, err := svc.Login("admin", "testpassword123", "10.0.0.2"); err != nil {
+		t.Errorf("Login from another IP should succeed, got %v", err)
+	}
+}
+
+func TestLoginLockoutCooldownExpires(t *testing.T) {
+	svc, clock := setupLockoutTest(t, 2, time.Minute)
+
+	svc.Login("admin", "wrong", "10.0.0.1")
+	svc.Login("admin", "wrong", "10.0.0.1")
+	if _, err := svc.Login("admin", "testpassword123", "10.0.0.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Expected ErrLoginLocked, got %v", err)
+	}
+
+	clock.now = clock.now.Add(time.Minute + time.Second)
+	if d := svc.LoginLockedFor("admin", "10.0.0.1"); d != 0 {
+		t.Errorf("Lock should have expired, %s remaining", d)
+	}
+	if _, err := svc.Login("admin", "testpassword123", "10.0.0.1"); err != nil {
+		t.Errorf("Login after cooldown should succeed, got %v", err)
+	}
+}
+
+func TestLoginSuccessResetsFailures(t *testing.T) {
+	svc, _ := setupLockoutTest(t, 3, time.Minute)
+
+	svc.Login("admin", "wrong", "10.0.0.1")
+	svc.Login("admin", "wrong", "10.0.0.1")
+	if _, err := svc.Login("admin", "testpassword123", "10.0.0.1"); err != nil {
+		t.Fatalf("Login should succeed, got %v", err)
+	}
+
+	// Two more failures stay below the threshold after the reset
+	svc.Login("admin", "wrong", "10.0.0.1")
+	svc.Login("admin", "wrong", "10.0.0.1")
+	if _, err := svc.Login("admin", "testpassword123", "10.0.0.1"); err != nil {
+		t.Errorf("Counter should reset on success, got %v", err)
+	}
+}
+
+func TestLoginLockoutForgetsOldFailures(t *testing.T) {
+	svc, clock := setupLockoutTest(t, 2, time.Minute)
+
+	svc.Login("admin", "wrong", "10.0.0.1")
+	clock.now = clock.now.Add(2 * time.Minute)
+	svc.Login("admin", "wrong", "10.0.0.1")
+
+	if d := svc.LoginLockedFor("admin", "10.0.0.1"); d != 0 {
+		t.Errorf("Failures further apart than the lockout window should not lock, got %s", d)
+	}
+}
+
+func TestLoginLockoutAppliesToUnknownUsers(t *testing.T) {
+	svc, _ := setupLockoutTest(t, 2, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Login("nobody", "wrong", "10.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Expected ErrInvalidCredentials for unknown user, got %v", err)
+		}
+	}
+	if _, err := svc.Login("nobody", "wrong", "10.0.0.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("Unknown usernames should lock like real ones, got %v", err)
+	}
+}
+
+func TestNewLoginLockoutDefaults(t *testing.T) {
+	svc, _ := setupAuthRefreshTest(t, time.Hour, false)
+	if svc.lockout.maxFailures != defaultLoginMaxFailures || svc.lockout.duration != defaultLoginLockoutDuration {
+		t.Errorf("Unset thresholds should use defaults, got %d / %s", svc.lockout.maxFailures, svc.lockout.duration)
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// defaultAccessTokenTTL is used when no access token TTL is configured
+const defaultAccessTokenTTL = 24 * time.Hour
+
+// RefreshToken exchanges a valid, unexpired token for a new one with a fresh expiry.
+// The old token is revoked, so presenting it again (to refresh or to any protected route) fails with ErrTokenRevoked.
+func (s *AuthService) RefreshToken(tokenString string) (string, time.Time, error) {
+	claims, err := s.VerifyToken(tokenString)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := s.revokeToken(tokenString, expiresAt); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return s.issueToken(claims.Username)
+}
+
+// SlideToken re-issues a token once more than half of its lifetime has passed, when sliding
+// expiration is enabled. It returns an empty token when no re-issue is due. The old token is
+// left valid until it expires so concurrent requests still carrying it are not rejected.
+func (s *AuthService) SlideToken(claims *Claims) (string, time.Time, error) {
+	if !s.config.SlidingExpiration || claims == nil || claims.ExpiresAt == nil {
+		return "", time.Time{}, nil
+	}
+	if time.Until(claims.ExpiresAt.Time) > s.TokenTTL()/2 {
+		return "", time.Time{}, nil
+	}
+	return s.issueToken(claims.Username)
+}
+
+// revokeToken records a token as rotated out until its expiry.
+// Revoking an already revoked token returns ErrTokenRevoked, so a token can be refreshed only once.
+func (s *AuthService) revokeToken(tokenString string, expiresAt time.Time) error {
+	hash := hashToken(tokenString)
+
+	s.revokedMu.Lock()
+	defer s.revokedMu.Unlock()
+
+	if _, ok := s.revoked[hash]; ok {
+		return ErrTokenRevoked
+	}
+
+	// The unique index catches a token revoked by another process sharing the database
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RevokedToken{TokenHash: hash, ExpiresAt: expiresAt})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		s.revoked[hash] = expiresAt
+		return ErrTokenRevoked
+	}
+	s.revoked[hash] = expiresAt
+
+	s.pruneRevokedLocked(time.Now())
+	return nil
+}
+
+// isRevoked reports whether a token has been rotated out by a refresh
+func (s *AuthService) isRevoked(tokenString string) bool {
+	s.revokedMu.RLock()
+	defer s.revokedMu.RUnlock()
+	_, ok := s.revoked[hashToken(tokenString)]
+	return ok
+}
+
+// loadRevokedTokens drops expired revocations and caches the rest
+func (s *AuthService) loadRevokedTokens() error {
+	now := time.Now()
+	if err := s.db.Where("expires_at <= ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+		return fmt.Errorf("failed to prune revoked tokens: %w", err)
+	}
+
+	var tokens []models.RevokedToken
+	if err := s.db.Find(&tokens).Error; err != nil {
+		return fmt.Errorf("failed to load revoked tokens: %w", err)
+	}
+
+	s.revokedMu.Lock()
+	defer s.revokedMu.Unlock()
+	for _, t := range tokens {
+		s.revoked[t.TokenHash] = t.ExpiresAt
+	}
+	return nil
+}
+
+// pruneRevokedLocked forgets revocations of tokens that have expired anyway.
+// The caller must hold revokedMu.
+func (s *AuthService) pruneRevokedLocked(now time.Time) {
+	expired := false
+	for hash, expiresAt := range s.revoked {
+		if !expiresAt.After(now) {
+			delete(s.revoked, hash)
+			expired = true
+		}
+	}
+	if expired {
+		if err := s.db.Where("expires_at <= ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+			log.Printf("Warning: failed to prune revoked tokens: %v", err)
+		}
+	}
+}
+
+// hashToken returns the SHA-256 hex digest under which a revoked token is stored
+func hashToken(tokenString string) string {
+	sum := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(sum[:])
+}
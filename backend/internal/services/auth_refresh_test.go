@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cc-platform/internal/config"
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var authRefreshTestDBCounter int
+
+func setupAuthRefreshTest(t *testing.T, ttl time.Duration, sliding bool) (*AuthService, *gorm.DB) {
+	authRefreshTestDBCounter++
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:authrefreshdb%d?mode=memory&cache=shared", authRefreshTestDBCounter)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.RevokedToken{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	cfg := &config.Config{
+		JWTSecret:         "test-jwt-secret-32-bytes-long!!",
+		AdminUsername:     "admin",
+		AdminPassword:     "testpassword123",
+		AccessTokenTTL:    ttl,
+		SlidingExpiration: sliding,
+	}
+	svc, err := NewAuthService(db, cfg)
+	if err != nil {
+		t.Fatalf("Failed to create auth service: %v", err)
+	}
+	return svc, db
+}
+
+// signTestToken signs a token for admin with the given issue and expiry times
+func signTestToken(t *testing.T, issuedAt, expiresAt time.Time) string {
+	claims := &Claims{
+		Username: "admin",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        fmt.Sprintf("test-%d", expiresAt.UnixNano()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			Issuer:    "cc-platform",
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-jwt-secret-32-bytes-long!!"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestRefreshTokenRotatesToken(t *testing.T) {
+	svc, _ := setupAuthRefreshTest(t, time.Hour, false)
+
+	token, err := svc.Login("admin", "testpassword123", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	newToken, expiresAt, err := svc.RefreshToken(token)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if newToken == token {
+		t.Fatal("Refresh should issue a different token")
+	}
+	if d := time.Until(expiresAt); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("New token should expire in about 1h, got %s", d)
+	}
+
+	claims, err := svc.VerifyToken(newToken)
+	if err != nil {
+		t.Fatalf("New token should verify: %v", err)
+	}
+	if claims.Username != "admin" {
+		t.Errorf("Expected username admin, got %s", claims.Username)
+	}
+
+	if _, err := svc.VerifyToken(token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Rotated token should be rejected with ErrTokenRevoked, got %v", err)
+	}
+}
+
+func TestRefreshTokenRejectsReuse(t *testing.T) {
+	svc, _ := setupAuthRefreshTest(t, time.Hour, false)
+
+	token, err := svc.generateToken("admin")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	second, _, err := svc.RefreshToken(token)
+	if err != nil {
+		t.Fatalf("First refresh failed: %v", err)
+	}
+
+	if _, _, err := svc.RefreshToken(token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Refreshing a rotated token should fail with ErrTokenRevoked, got %v", err)
+	}
+
+	// The token issued by the first refresh can itself be rotated once
+	third, _, err := svc.RefreshToken(second)
+	if err != nil {
+		t.Fatalf("Refreshing the new token failed: %v", err)
+	}
+	if _, err := svc.VerifyToken(third); err != nil {
+		t.Errorf("Latest token should verify: %v", err)
+	}
+}
+
+func TestRefreshTokenExpiryEdges(t *testing.T) {
+	svc, _ := setupAuthRefreshTest(t, time.Hour, false)
+	now := time.Now()
+
+	expired := signTestToken(t, now.Add(-2*time.Hour), now.Add(-time.Second))
+	if _, _, err := svc.RefreshToken(expired); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Expired token should not refresh, got %v", err)
+	}
+
+	almostExpired := signTestToken(t, now.Add(-time.Hour), now.Add(5*time.Second))
+	if _, _, err := svc.RefreshToken(almostExpired); err != nil {
+		t.Errorf("Token expiring in 5s should still refresh, got %v", err)
+	}
+
+	if _, _, err := svc.RefreshToken("not-a-token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Garbage token should fail with ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestRevokedTokensSurviveRestart(t *testing.T) {
+	svc, db := setupAuthRefreshTest(t, time.Hour, false)
+
+	token, err := svc.generateToken("admin")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, _, err := svc.RefreshToken(token); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	var stored models.RevokedToken
+	if err := db.First(&stored).Error; err != nil {
+		t.Fatalf("Revocation should be persisted: %v", err)
+	}
+	if stored.TokenHash == token || stored.TokenHash != hashToken(token) {
+		t.Error("Revoked token should be stored as its SHA-256 hash")
+	}
+
+	restarted, err := NewAuthService(db, svc.config)
+	if err != nil {
+		t.Fatalf("Failed to recreate auth service: %v", err)
+	}
+	if _, err := restarted.VerifyToken(token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Revocation should be loaded on startup, got %v", err)
+	}
+}
+
+func TestLoadRevokedTokensPrunesExpired(t *testing.T) {
+	svc, db := setupAuthRefreshTest(t, time.Hour, false)
+
+	db.Create(&models.RevokedToken{TokenHash: "old", ExpiresAt: time.Now().Add(-time.Minute)})
+	db.Create(&models.RevokedToken{TokenHash: "live", ExpiresAt: time.Now().Add(time.Hour)})
+
+	if err := svc.loadRevokedTokens(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	var count int64
+	db.Model(&models.RevokedToken{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 revocation after pruning, got %d", count)
+	}
+	if _, ok := svc.revoked["old"]; ok {
+		t.Error("Expired revocation should not be cached")
+	}
+}
+
+func TestTokenTTLIsConfigurable(t *testing.T) {
+	svc, _ := setupAuthRefreshTest(t, 15*time.Minute, false)
+
+	token, err := svc.generateToken("admin")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	claims, err := svc.VerifyToken(token)
+	if err != nil {
+		t.Fatalf("Token should verify: %v", err)
+	}
+	if d := claims.ExpiresAt.Sub(claims.IssuedAt.Time); d != 15*time.Minute {
+		t.Errorf("Expected 15m lifetime, got %s", d)
+	}
+
+	defaults, _ := setupAuthRefreshTest(t, 0, false)
+	if defaults.TokenTTL() != 24*time.Hour {
+		t.Errorf("Unset TTL should default to 24h, got %s", defaults.TokenTTL())
+	}
+}
+
+func TestSlideToken(t *testing.T) {
+	now := time.Now()
+	fresh := &Claims{Username: "admin", RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(50 * time.Minute))}}
+	stale := &Claims{Username: "admin", RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(20 * time.Minute))}}
+
+	disabled, _ := setupAuthRefreshTest(t, time.Hour, false)
+	if token, _, err := disabled.SlideToken(stale); err != nil || token != "" {
+		t.Errorf("Sliding disabled should never re-issue, got %q, %v", token, err)
+	}
+
+	enabled, _ := setupAuthRefreshTest(t, time.Hour, true)
+	if token, _, err := enabled.SlideToken(fresh); err != nil || token != "" {
+		t.Errorf("Token with more than half its lifetime left should not slide, got %q, %v", token, err)
+	}
+
+	token, expiresAt, err := enabled.SlideToken(stale)
+	if err != nil || token == "" {
+		t.Fatalf("Token past half its lifetime should slide, got %q, %v", token, err)
+	}
+	if !expiresAt.After(stale.ExpiresAt.Time) {
+		t.Error("Slid token should expire later than the original")
+	}
+	if _, err := enabled.VerifyToken(token); err != nil {
+		t.Errorf("Slid token should verify: %v", err)
+	}
+}
@@ -0,0 +1,478 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BackupVersion is the format version written to new backups
+const BackupVersion = 1
+
+// ErrInvalidBackup is returned when a backup document can't be imported at all
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// credentialConfigTypes are template types that hold credentials and are never exported
+var credentialConfigTypes = map[models.ConfigType]bool{
+	models.ConfigTypeCodexAuth: true,
+	models.ConfigTypeGeminiEnv: true,
+}
+
+// Backup is a portable snapshot of the platform configuration.
+// Records reference each other by name, so it can be restored into a fresh instance.
+type Backup struct {
+	Version          int                    `json:"version"`
+	ExportedAt       time.Time              `json:"exported_at"`
+	Templates        []BackupTemplate       `json:"templates"`
+	Bundles          []BackupBundle         `json:"bundles"`
+	CommandProfiles  []BackupCommandProfile `json:"command_profiles"`
+	Containers       []BackupContainer      `json:"containers"`
+	ResourceProfiles []ResourceProfile      `json:"resource_profiles"` // Server config; checked but not created on import
+	NotExported      []string               `json:"not_exported"`      // What was left out and why
+}
+
+// BackupTemplateRef identifies a template by its unique name and type
+type BackupTemplateRef struct {
+	Name       string            `json:"name"`
+	ConfigType models.ConfigType `json:"config_type"`
+}
+
+// BackupTemplate is an exported config template
+type BackupTemplate struct {
+	Name        string            `json:"name"`
+	ConfigType  models.ConfigType `json:"config_type"`
+	Content     string            `json:"content"`
+	Description string            `json:"description,omitempty"`
+	IsArchive   bool              `json:"is_archive,omitempty"`
+	ArchiveData string            `json:"archive_data,omitempty"`
+	Files       models.SkillFiles `json:"files,omitempty"`
+}
+
+// BackupBundle is an exported template bundle
+type BackupBundle struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Templates   []BackupTemplateRef `json:"templates"`
+}
+
+// BackupCommandProfile is an exported startup command profile
+type BackupCommandProfile struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Command     string `json:"command"`
+	IsDefault   bool   `json:"is_default,omitempty"`
+}
+
+// BackupContainer is the creation spec of a container. Profile and template IDs in the
+// spec are cleared; selections are carried by name instead.
+type BackupContainer struct {
+	Spec                  CreateContainerInput `json:"spec"`
+	Templates             []BackupTemplateRef  `json:"templates,omitempty"`
+	StartupCommandProfile string               `json:"startup_command_profile,omitempty"`
+}
+
+// BackupImportFailure is a backup entry that could not be imported
+type BackupImportFailure struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// BackupSectionResult reports the import outcome of one backup section
+type BackupSectionResult struct {
+	Imported []string              `json:"imported"`
+	Skipped  []string              `json:"skipped"` // Already present in this instance
+	Failed   []BackupImportFailure `json:"failed"`
+	Warnings []string              `json:"warnings"`
+}
+
+// BackupImportResult reports the import outcome per section
+type BackupImportResult struct {
+	Templates        BackupSectionResult `json:"templates"`
+	Bundles          BackupSectionResult `json:"bundles"`
+	CommandProfiles  BackupSectionResult `json:"command_profiles"`
+	Containers       BackupSectionResult `json:"containers"`
+	ResourceProfiles BackupSectionResult `json:"resource_profiles"`
+}
+
+// BackupService exports and restores templates, bundles, command profiles and container specs
+type BackupService struct {
+	db                   *gorm.DB
+	templateService      ConfigTemplateService
+	bundleService        TemplateBundleService
+	configProfileService *ConfigProfileService
+	containerService     *ContainerService
+}
+
+// NewBackupService creates a new BackupService
+func NewBackupService(db *gorm.DB, templateService ConfigTemplateService, bundleService TemplateBundleService,
+	configProfileService *ConfigProfileService, containerService *ContainerService) *BackupService {
+	return &BackupService{
+		db:                   db,
+		templateService:      templateService,
+		bundleService:        bundleService,
+		configProfileService: configProfileService,
+		containerService:     containerService,
+	}
+}
+
+// Export builds a backup of everything except secrets. GitHub tokens, environment
+// profiles and credential templates are left out, and MCP secret values are redacted.
+func (s *BackupService) Export() (*Backup, error) {
+	backup := &Backup{
+		Version:         BackupVersion,
+		ExportedAt:      time.Now().UTC(),
+		Templates:       []BackupTemplate{},
+		Bundles:         []BackupBundle{},
+		CommandProfiles: []BackupCommandProfile{},
+		Containers:      []BackupContainer{},
+		NotExported: []string{
+			"GitHub tokens",
+			"Environment variable profiles (they hold API keys)",
+		},
+	}
+
+	templates, err := s.templateService.List(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	refs := make(map[uint]BackupTemplateRef, len(templates))
+	for _, t := range templates {
+		if credentialConfigTypes[t.ConfigType] {
+			backup.NotExported = append(backup.NotExported, fmt.Sprintf("Template '%s' (%s): credential templates are not exported", t.Name, t.ConfigType))
+			continue
+		}
+		content := t.Content
+		if t.ConfigType == models.ConfigTypeMCP {
+			if redacted, ok := redactMCPTemplate(content); ok {
+				content = redacted
+				backup.NotExported = append(backup.NotExported, fmt.Sprintf("Template '%s' (%s): secret env and header values replaced with %s", t.Name, t.ConfigType, redactedValue))
+			}
+		}
+		refs[t.ID] = BackupTemplateRef{Name: t.Name, ConfigType: t.ConfigType}
+		backup.Templates = append(backup.Templates, BackupTemplate{
+			Name:        t.Name,
+			ConfigType:  t.ConfigType,
+			Content:     content,
+			Description: t.Description,
+			IsArchive:   t.IsArchive,
+			ArchiveData: t.ArchiveData,
+			Files:       t.Files,
+		})
+	}
+	templateRefs := func(ids []uint) []BackupTemplateRef {
+		var out []BackupTemplateRef
+		for _, id := range ids {
+			if ref, ok := refs[id]; ok {
+				out = append(out, ref)
+			}
+		}
+		return out
+	}
+
+	bundles, err := s.bundleService.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bundles: %w", err)
+	}
+	for _, b := range bundles {
+		members := templateRefs(b.TemplateIDs)
+		if members == nil {
+			members = []BackupTemplateRef{}
+		}
+		backup.Bundles = append(backup.Bundles, BackupBundle{Name: b.Name, Description: b.Description, Templates: members})
+	}
+
+	profiles, err := s.configProfileService.ListCommandProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list command profiles: %w", err)
+	}
+	profileNames := make(map[uint]string, len(profiles))
+	for _, p := range profiles {
+		profileNames[p.ID] = p.Name
+		backup.CommandProfiles = append(backup.CommandProfiles, BackupCommandProfile{
+			Name:        p.Name,
+			Description: p.Description,
+			Command:     p.Command,
+			IsDefault:   p.IsDefault,
+		})
+	}
+
+	containers, err := s.containerService.ListContainers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	for i := range containers {
+		c := &containers[i]
+		if c.Status == models.ContainerStatusDeleted {
+			continue
+		}
+		spec, err := recreateInput(c)
+		if err != nil {
+			backup.NotExported = append(backup.NotExported, fmt.Sprintf("Container '%s': %v", c.Name, err))
+			continue
+		}
+		if c.GitHubTokenID != nil || c.EnvVarsProfileID != nil {
+			backup.NotExported = append(backup.NotExported, fmt.Sprintf("Container '%s': GitHub token and environment profile selections", c.Name))
+		}
+		entry := BackupContainer{Templates: templateRefs(spec.templateIDs)}
+		if c.StartupCommandProfileID != nil {
+			entry.StartupCommandProfile = profileNames[*c.StartupCommandProfileID]
+		}
+		spec.GitHubTokenID = nil
+		spec.EnvVarsProfileID = nil
+		spec.StartupCommandProfileID = nil
+		spec.templateIDs = nil
+		entry.Spec = spec
+		backup.Containers = append(backup.Containers, entry)
+	}
+
+	backup.ResourceProfiles = s.containerService.ListResourceProfiles()
+	return backup, nil
+}
+
+// redactMCPTemplate redacts secret env and header values in MCP template content.
+// It reports false when there was nothing to redact or the content isn't a JSON object.
+func redactMCPTemplate(content string) (string, bool) {
+	var original, server map[string]interface{}
+	if json.Unmarshal([]byte(content), &original) != nil || json.Unmarshal([]byte(content), &server) != nil {
+		return content, false
+	}
+	redactMCPServer(server)
+	if reflect.DeepEqual(original, server) {
+		return content, false
+	}
+	data, err := json.MarshalIndent(server, "", "  ")
+	if err != nil {
+		return content, false
+	}
+	return string(data), true
+}
+
+// Import restores a backup. Entries that already exist (by name) are skipped, every other
+// entry is validated and created on its own, so one bad entry doesn't stop the rest.
+// Sections are imported in dependency order: templates, bundles, profiles, then containers.
+func (s *BackupService) Import(ctx context.Context, backup *Backup) (*BackupImportResult, error) {
+	if backup == nil || backup.Version < 1 {
+		return nil, fmt.Errorf("%w: missing format version", ErrInvalidBackup)
+	}
+	if backup.Version > BackupVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidBackup, backup.Version)
+	}
+
+	result := &BackupImportResult{
+		Templates:        newBackupSectionResult(),
+		Bundles:          newBackupSectionResult(),
+		CommandProfiles:  newBackupSectionResult(),
+		Containers:       newBackupSectionResult(),
+		ResourceProfiles: newBackupSectionResult(),
+	}
+
+	templateIDs, err := s.importTemplates(backup.Templates, &result.Templates)
+	if err != nil {
+		return nil, err
+	}
+	resolve := func(owner string, refs []BackupTemplateRef, section *BackupSectionResult) []uint {
+		ids := make([]uint, 0, len(refs))
+		for _, ref := range refs {
+			id, ok := templateIDs[ref]
+			if !ok {
+				section.Warnings = append(section.Warnings, fmt.Sprintf("%s: template '%s' (%s) is not available, skipped", owner, ref.Name, ref.ConfigType))
+				continue
+			}
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	existingBundles, err := s.bundleService.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bundles: %w", err)
+	}
+	bundleNames := make(map[string]bool, len(existingBundles))
+	for _, b := range existingBundles {
+		bundleNames[b.Name] = true
+	}
+	for _, b := range backup.Bundles {
+		if bundleNames[strings.TrimSpace(b.Name)] {
+			result.Bundles.Skipped = append(result.Bundles.Skipped, b.Name)
+			continue
+		}
+		ids := resolve(fmt.Sprintf("Bundle '%s'", b.Name), b.Templates, &result.Bundles)
+		created, err := s.bundleService.Create(CreateTemplateBundleInput{Name: b.Name, Description: b.Description, TemplateIDs: ids})
+		if err != nil {
+			result.Bundles.Failed = append(result.Bundles.Failed, BackupImportFailure{Name: b.Name, Reason: err.Error()})
+			continue
+		}
+		bundleNames[created.Name] = true
+		result.Bundles.Imported = append(result.Bundles.Imported, created.Name)
+	}
+
+	profileIDs, err := s.importCommandProfiles(backup.CommandProfiles, &result.CommandProfiles)
+	if err != nil {
+		return nil, err
+	}
+
+	available := make(map[string]bool)
+	for _, p := range s.containerService.ListResourceProfiles() {
+		available[p.Name] = true
+	}
+	for _, p := range backup.ResourceProfiles {
+		if available[p.Name] {
+			result.ResourceProfiles.Skipped = append(result.ResourceProfiles.Skipped, p.Name)
+			continue
+		}
+		result.ResourceProfiles.Failed = append(result.ResourceProfiles.Failed, BackupImportFailure{
+			Name:   p.Name,
+			Reason: fmt.Sprintf("not configured on this server; add \"%s=%g:%d\" to RESOURCE_PROFILES", p.Name, p.CPULimit, p.MemoryLimit),
+		})
+	}
+
+	containers, err := s.containerService.ListContainers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	containerNames := make(map[string]bool, len(containers))
+	for _, c := range containers {
+		if c.Status != models.ContainerStatusDeleted {
+			containerNames[c.Name] = true
+		}
+	}
+	for _, entry := range backup.Containers {
+		spec := entry.Spec
+		if containerNames[spec.Name] {
+			result.Containers.Skipped = append(result.Containers.Skipped, spec.Name)
+			continue
+		}
+		// IDs from another instance mean nothing here; only name references are restored
+		spec.GitHubTokenID = nil
+		spec.EnvVarsProfileID = nil
+		spec.StartupCommandProfileID = nil
+		spec.SelectedClaudeMD = nil
+		spec.SelectedSkills = nil
+		spec.SelectedMCPs = nil
+		spec.SelectedCommands = nil
+		spec.SelectedCodexConfigs = nil
+		spec.SelectedCodexAuths = nil
+		spec.SelectedGeminiEnvs = nil
+		spec.templateIDs = resolve(fmt.Sprintf("Container '%s'", spec.Name), entry.Templates, &result.Containers)
+		if entry.StartupCommandProfile != "" {
+			if id, ok := profileIDs[entry.StartupCommandProfile]; ok {
+				spec.StartupCommandProfileID = &id
+			} else {
+				result.Containers.Warnings = append(result.Containers.Warnings,
+					fmt.Sprintf("Container '%s': startup command profile '%s' is not available, using the default", spec.Name, entry.StartupCommandProfile))
+			}
+		}
+
+		created, err := s.containerService.CreateContainer(ctx, spec)
+		if err != nil {
+			result.Containers.Failed = append(result.Containers.Failed, BackupImportFailure{Name: spec.Name, Reason: err.Error()})
+			continue
+		}
+		containerNames[created.Name] = true
+		result.Containers.Imported = append(result.Containers.Imported, created.Name)
+	}
+
+	return result, nil
+}
+
+// importTemplates creates the templates missing from this instance and returns the ID of
+// every template a backup reference can resolve to, including ones that already existed
+func (s *BackupService) importTemplates(templates []BackupTemplate, section *BackupSectionResult) (map[BackupTemplateRef]uint, error) {
+	existing, err := s.templateService.List(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	ids := make(map[BackupTemplateRef]uint, len(existing)+len(templates))
+	for _, t := range existing {
+		ids[BackupTemplateRef{Name: t.Name, ConfigType: t.ConfigType}] = t.ID
+	}
+
+	for _, t := range templates {
+		ref := BackupTemplateRef{Name: t.Name, ConfigType: t.ConfigType}
+		label := fmt.Sprintf("%s (%s)", t.Name, t.ConfigType)
+		if _, ok := ids[ref]; ok {
+			section.Skipped = append(section.Skipped, label)
+			continue
+		}
+		if t.ConfigType == models.ConfigTypeMCP && strings.Contains(t.Content, redactedValue) {
+			section.Warnings = append(section.Warnings, fmt.Sprintf("Template %s: redacted secret values must be filled in before use", label))
+		}
+
+		created, err := s.templateService.Create(CreateConfigTemplateInput{
+			Name:        t.Name,
+			ConfigType:  t.ConfigType,
+			Content:     t.Content,
+			Description: t.Description,
+			Files:       t.Files,
+		})
+		if err != nil {
+			section.Failed = append(section.Failed, BackupImportFailure{Name: label, Reason: err.Error()})
+			continue
+		}
+		if t.IsArchive && t.ArchiveData != "" {
+			if err := s.db.Model(created).Updates(map[string]interface{}{"is_archive": true, "archive_data": t.ArchiveData}).Error; err != nil {
+				section.Warnings = append(section.Warnings, fmt.Sprintf("Template %s: failed to restore skill archive: %v", label, err))
+			}
+		}
+		ids[ref] = created.ID
+		section.Imported = append(section.Imported, label)
+	}
+	return ids, nil
+}
+
+// importCommandProfiles creates the startup command profiles missing from this instance and
+// returns the ID of every profile by name. A restored default doesn't replace an existing one.
+func (s *BackupService) importCommandProfiles(profiles []BackupCommandProfile, section *BackupSectionResult) (map[string]uint, error) {
+	existing, err := s.configProfileService.ListCommandProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list command profiles: %w", err)
+	}
+	ids := make(map[string]uint, len(existing)+len(profiles))
+	hasDefault := false
+	for _, p := range existing {
+		ids[p.Name] = p.ID
+		hasDefault = hasDefault || p.IsDefault
+	}
+
+	for _, p := range profiles {
+		if _, ok := ids[p.Name]; ok {
+			section.Skipped = append(section.Skipped, p.Name)
+			continue
+		}
+		if strings.TrimSpace(p.Name) == "" || strings.TrimSpace(p.Command) == "" {
+			section.Failed = append(section.Failed, BackupImportFailure{Name: p.Name, Reason: "name and command are required"})
+			continue
+		}
+		created, err := s.configProfileService.CreateCommandProfile(CreateCommandProfileInput{
+			Name:        p.Name,
+			Description: p.Description,
+			Command:     p.Command,
+			IsDefault:   p.IsDefault && !hasDefault,
+		})
+		if err != nil {
+			section.Failed = append(section.Failed, BackupImportFailure{Name: p.Name, Reason: err.Error()})
+			continue
+		}
+		hasDefault = hasDefault || created.IsDefault
+		ids[created.Name] = created.ID
+		section.Imported = append(section.Imported, created.Name)
+	}
+	return ids, nil
+}
+
+func newBackupSectionResult() BackupSectionResult {
+	return BackupSectionResult{
+		Imported: []string{},
+		Skipped:  []string{},
+		Failed:   []BackupImportFailure{},
+		Warnings: []string{},
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var backupTestDBCounter int
+
+func setupBackupTestService(t *testing.T) *BackupService {
+	t.Helper()
+
+	backupTestDBCounter++
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:backupdb%d?mode=memory&cache=shared", backupTestDBCounter)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.ClaudeConfigTemplate{}, &models.TemplateBundle{}, &models.StartupCommandProfile{},
+		&models.Container{}, &models.ContainerLog{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	templateService := NewConfigTemplateService(db)
+	return NewBackupService(db, templateService, NewTemplateBundleService(db, templateService),
+		NewConfigProfileService(db, nil), &ContainerService{db: db, resourceProfiles: builtinResourceProfiles})
+}
+
+func TestBackup_ExportExcludesSecrets(t *testing.T) {
+	s := setupBackupTestService(t)
+
+	mcp, _ := s.templateService.Create(CreateConfigTemplateInput{Name: "github", ConfigType: models.ConfigTypeMCP,
+		Content: `{"command":"npx","args":["server-github"],"env":{"GITHUB_TOKEN":"ghp_123","LOG_LEVEL":"debug"}}`})
+	s.templateService.Create(CreateConfigTemplateInput{Name: "codex", ConfigType: models.ConfigTypeCodexAuth, Content: `{"OPENAI_API_KEY":"sk-1"}`})
+	tokenID := uint(5)
+	s.db.Create(&models.Container{Name: "web", DockerID: "d1", Status: models.ContainerStatusRunning,
+		GitHubTokenID: &tokenID, SelectedTemplateIDs: models.TemplateIDList{mcp.ID}})
+	s.db.Create(&models.Container{Name: "gone", DockerID: "d2", Status: models.ContainerStatusDeleted})
+
+	backup, err := s.Export()
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	if len(backup.Templates) != 1 || backup.Templates[0].Name != "github" {
+		t.Fatalf("Expected only the MCP template to be exported, got %+v", backup.Templates)
+	}
+	if strings.Contains(backup.Templates[0].Content, "ghp_123") || !strings.Contains(backup.Templates[0].Content, "debug") {
+		t.Errorf("Expected the token to be redacted and other values kept, got %s", backup.Templates[0].Content)
+	}
+	if len(backup.Containers) != 1 || backup.Containers[0].Spec.GitHubTokenID != nil {
+		t.Fatalf("Expected one container without its token reference, got %+v", backup.Containers)
+	}
+	if refs := backup.Containers[0].Templates; len(refs) != 1 || refs[0] != (BackupTemplateRef{Name: "github", ConfigType: models.ConfigTypeMCP}) {
+		t.Errorf("Expected template selections by name, got %+v", refs)
+	}
+	notes := strings.Join(backup.NotExported, "\n")
+	for _, want := range []string{"GitHub tokens", "Template 'codex'", "Template 'github'", "Container 'web'"} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("Expected not_exported to mention %q, got %v", want, backup.NotExported)
+		}
+	}
+}
+
+func TestBackup_RoundTripIntoFreshInstance(t *testing.T) {
+	src := setupBackupTestService(t)
+	md, _ := src.templateService.Create(CreateConfigTemplateInput{Name: "rules", ConfigType: models.ConfigTypeClaudeMD, Content: "# Rules"})
+	cmd, _ := src.templateService.Create(CreateConfigTemplateInput{Name: "deploy", ConfigType: models.ConfigTypeCommand, Content: "Deploy it"})
+	src.bundleService.Create(CreateTemplateBundleInput{Name: "web stack", TemplateIDs: []uint{md.ID, cmd.ID}})
+	src.configProfileService.CreateCommandProfile(CreateCommandProfileInput{Name: "yolo", Command: "claude --dangerously-skip-permissions", IsDefault: true})
+
+	exported, err := src.Export()
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	data, _ := json.Marshal(exported)
+	var backup Backup
+	if err := json.Unmarshal(data, &backup); err != nil {
+		t.Fatalf("Backup is not valid JSON: %v", err)
+	}
+	// An invalid template and a container whose name already exists on the target
+	backup.Templates = append(backup.Templates, BackupTemplate{Name: "broken", ConfigType: models.ConfigTypeMCP, Content: "{"})
+	backup.Containers = append(backup.Containers, BackupContainer{Spec: CreateContainerInput{Name: "web"}})
+	backup.ResourceProfiles = append(backup.ResourceProfiles, ResourceProfile{Name: "huge", CPULimit: 16, MemoryLimit: 65536})
+
+	dst := setupBackupTestService(t)
+	dst.templateService.Create(CreateConfigTemplateInput{Name: "deploy", ConfigType: models.ConfigTypeCommand, Content: "Already here"})
+	dst.db.Create(&models.Container{Name: "web", DockerID: "d1", Status: models.ContainerStatusStopped})
+
+	result, err := dst.Import(context.Background(), &backup)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if len(result.Templates.Imported) != 1 || len(result.Templates.Skipped) != 1 || len(result.Templates.Failed) != 1 {
+		t.Errorf("Expected 1 imported, 1 skipped and 1 failed template, got %+v", result.Templates)
+	}
+	if len(result.Bundles.Imported) != 1 || len(result.CommandProfiles.Imported) != 1 {
+		t.Errorf("Expected the bundle and command profile to be imported, got %+v / %+v", result.Bundles, result.CommandProfiles)
+	}
+	if len(result.Containers.Skipped) != 1 || len(result.ResourceProfiles.Failed) != 1 || result.ResourceProfiles.Failed[0].Name != "huge" {
+		t.Errorf("Expected the existing container skipped and the unknown profile reported, got %+v / %+v", result.Containers, result.ResourceProfiles)
+	}
+
+	// The restored bundle points at this instance's templates, including the pre-existing one
+	bundles, _ := dst.bundleService.List()
+	existing, _ := dst.templateService.List(nil)
+	ids := map[string]uint{}
+	for _, tpl := range existing {
+		ids[tpl.Name] = tpl.ID
+	}
+	if len(bundles) != 1 || len(bundles[0].TemplateIDs) != 2 || bundles[0].TemplateIDs[0] != ids["rules"] || bundles[0].TemplateIDs[1] != ids["deploy"] {
+		t.Errorf("Expected the bundle to be remapped to local template IDs %v, got %+v", ids, bundles)
+	}
+
+	if _, err := dst.Import(context.Background(), &Backup{Version: BackupVersion + 1}); !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("Expected ErrInvalidBackup for a newer format, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	pathpkg "path"
+	"strings"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+
+	"github.com/docker/docker/errdefs"
+)
+
+// claudeSnapshotRoot is the directory every entry of a ~/.claude snapshot lives under
+const claudeSnapshotRoot = ".claude"
+
+var (
+	// ErrInvalidClaudeSnapshot is returned for an archive that isn't a safe ~/.claude snapshot
+	ErrInvalidClaudeSnapshot = errors.New("invalid ~/.claude snapshot")
+	// ErrClaudeSnapshotTooLarge is returned when a snapshot exceeds the size or entry limits
+	ErrClaudeSnapshotTooLarge = errors.New("~/.claude snapshot is too large")
+	// ErrClaudeDirNotFound is returned when the container has no ~/.claude directory
+	ErrClaudeDirNotFound = errors.New("~/.claude does not exist in the container")
+)
+
+// ClaudeSnapshotRestoreResult summarizes a restored ~/.claude snapshot
+type ClaudeSnapshotRestoreResult struct {
+	Files    int   `json:"files"`
+	Dirs     int   `json:"dirs"`
+	Symlinks int   `json:"symlinks"`
+	Bytes    int64 `json:"bytes"`
+	Replaced bool  `json:"replaced"` // The existing ~/.claude was removed before restoring
+}
+
+// repackClaudeSnapshot copies a tar or gzipped tar archive of ~/.claude to w as a plain tar,
+// rejecting anything that could land outside ~/.claude: every entry must be under .claude/
+// without "..", symlinks must point inside the snapshot, and only regular files, directories
+// and symlinks are allowed. Ownership is dropped so files belong to the container user.
+func repackClaudeSnapshot(r io.Reader, w io.Writer) (*ClaudeSnapshotRestoreResult, error) {
+	br := bufio.NewReader(r)
+	var src io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidClaudeSnapshot, err)
+		}
+		defer gz.Close()
+		src = gz
+	}
+
+	tr := tar.NewReader(src)
+	tw := tar.NewWriter(w)
+	result := &ClaudeSnapshotRestoreResult{}
+	symlinks := make(map[string]bool)
+	entries := 0
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidClaudeSnapshot, err)
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		name, err := claudeSnapshotEntryName(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		// Writing below a symlink from the same archive would follow it
+		for dir := pathpkg.Dir(name); dir != "." && dir != claudeSnapshotRoot; dir = pathpkg.Dir(dir) {
+			if symlinks[dir] {
+				return nil, fmt.Errorf("%w: %s is inside the symlink %s", ErrInvalidClaudeSnapshot, name, dir)
+			}
+		}
+
+		entries++
+		if entries > constants.ClaudeSnapshotMaxEntries {
+			return nil, fmt.Errorf("%w: more than %d entries", ErrClaudeSnapshotTooLarge, constants.ClaudeSnapshotMaxEntries)
+		}
+
+		out := &tar.Header{Name: name, Mode: header.Mode & 0o777, ModTime: header.ModTime}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			out.Typeflag = tar.TypeDir
+			out.Name += "/"
+			result.Dirs++
+		case tar.TypeReg, tar.TypeRegA:
+			out.Typeflag = tar.TypeReg
+			out.Size = header.Size
+			if header.Size < 0 || result.Bytes+header.Size > constants.ClaudeSnapshotMaxBytes {
+				return nil, fmt.Errorf("%w: files exceed %d bytes", ErrClaudeSnapshotTooLarge, constants.ClaudeSnapshotMaxBytes)
+			}
+			result.Bytes += header.Size
+			result.Files++
+		case tar.TypeSymlink:
+			if !claudeSnapshotLinkInside(name, header.Linkname) {
+				return nil, fmt.Errorf("%w: symlink %s points outside .claude", ErrInvalidClaudeSnapshot, name)
+			}
+			out.Typeflag = tar.TypeSymlink
+			out.Linkname = header.Linkname
+			symlinks[name] = true
+			result.Symlinks++
+		default:
+			return nil, fmt.Errorf("%w: %s has unsupported type %q", ErrInvalidClaudeSnapshot, name, header.Typeflag)
+		}
+
+		if err := tw.WriteHeader(out); err != nil {
+			return nil, fmt.Errorf("failed to write snapshot entry %s: %w", name, err)
+		}
+		if out.Typeflag == tar.TypeReg {
+			if _, err := io.CopyN(tw, tr, header.Size); err != nil {
+				return nil, fmt.Errorf("%w: failed to read %s: %w", ErrInvalidClaudeSnapshot, name, err)
+			}
+		}
+	}
+	if entries == 0 {
+		return nil, fmt.Errorf("%w: archive is empty", ErrInvalidClaudeSnapshot)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish snapshot archive: %w", err)
+	}
+	return result, nil
+}
+
+// claudeSnapshotEntryName normalizes an archive entry name and checks it is .claude or below it
+func claudeSnapshotEntryName(raw string) (string, error) {
+	name := strings.TrimPrefix(strings.ReplaceAll(raw, "\\", "/"), "./")
+	if strings.HasPrefix(name, "/") || strings.ContainsRune(name, 0) {
+		return "", fmt.Errorf("%w: unsafe entry %q", ErrInvalidClaudeSnapshot, raw)
+	}
+	for _, part := range strings.Split(strings.TrimSuffix(name, "/"), "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: unsafe entry %q", ErrInvalidClaudeSnapshot, raw)
+		}
+	}
+	name = pathpkg.Clean(name)
+	if name != claudeSnapshotRoot && !strings.HasPrefix(name, claudeSnapshotRoot+"/") {
+		return "", fmt.Errorf("%w: entry %q is not under %s/", ErrInvalidClaudeSnapshot, raw, claudeSnapshotRoot)
+	}
+	return name, nil
+}
+
+// claudeSnapshotLinkInside reports whether a relative symlink target stays under .claude
+func claudeSnapshotLinkInside(name, target string) bool {
+	if target == "" || strings.HasPrefix(target, "/") {
+		return false
+	}
+	resolved := pathpkg.Join(pathpkg.Dir(name), target)
+	return resolved == claudeSnapshotRoot || strings.HasPrefix(resolved, claudeSnapshotRoot+"/")
+}
+
+// runningContainerForSnapshot returns a container that can be snapshotted or restored
+func (s *ContainerService) runningContainerForSnapshot(id uint) (*models.Container, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	switch container.Status {
+	case models.ContainerStatusRunning:
+		return container, nil
+	case models.ContainerStatusPaused:
+		return nil, ErrContainerPaused
+	default:
+		return nil, ErrContainerNotRunning
+	}
+}
+
+// claudeConfigHome returns the directory holding ~/.claude inside a container
+func (s *ContainerService) claudeConfigHome(ctx context.Context, dockerID string) (string, error) {
+	output, err := s.dockerClient.ExecInContainer(ctx, dockerID, []string{"sh", "-c", `printf '%s' "${CC_CONFIG_HOME:-$HOME}"`})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+	home := strings.TrimSpace(demuxExecOutput(output))
+	if !strings.HasPrefix(home, "/") {
+		return "", fmt.Errorf("failed to resolve home directory: got %q", home)
+	}
+	return pathpkg.Clean(home), nil
+}
+
+// SnapshotClaudeDir returns a gzipped tar archive of the container's ~/.claude directory.
+// Entries are stored under .claude/, the layout RestoreClaudeDir expects.
+func (s *ContainerService) SnapshotClaudeDir(ctx context.Context, id uint) ([]byte, error) {
+	container, err := s.runningContainerForSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	home, err := s.claudeConfigHome(ctx, container.DockerID)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := s.dockerClient.CopyFromContainer(ctx, container.DockerID, pathpkg.Join(home, claudeSnapshotRoot))
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil, ErrClaudeDirNotFound
+		}
+		return nil, fmt.Errorf("failed to read ~/.claude: %w", err)
+	}
+	defer reader.Close()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := repackClaudeSnapshot(reader, gz); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RestoreClaudeDir extracts a ~/.claude snapshot into the container. The whole archive is
+// validated before anything is written. Existing files are overwritten; with replace the
+// current ~/.claude is removed first so files missing from the snapshot don't survive.
+func (s *ContainerService) RestoreClaudeDir(ctx context.Context, id uint, archive io.Reader, replace bool) (*ClaudeSnapshotRestoreResult, error) {
+	container, err := s.runningContainerForSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	result, err := repackClaudeSnapshot(archive, &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	home, err := s.claudeConfigHome(ctx, container.DockerID)
+	if err != nil {
+		return nil, err
+	}
+	if replace {
+		dir := pathpkg.Join(home, claudeSnapshotRoot)
+		if _, err := s.dockerClient.ExecInContainer(ctx, container.DockerID, []string{"rm", "-rf", dir}); err != nil {
+			return nil, fmt.Errorf("failed to remove ~/.claude: %w", err)
+		}
+		result.Replaced = true
+	}
+	if err := s.dockerClient.CopyToContainer(ctx, container.DockerID, home, &buf); err != nil {
+		return nil, fmt.Errorf("failed to restore ~/.claude: %w", err)
+	}
+
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup,
+		fmt.Sprintf("Restored ~/.claude snapshot (%d files, %d bytes)", result.Files, result.Bytes))
+	return result, nil
+}
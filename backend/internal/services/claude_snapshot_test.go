@@ -0,0 +1,154 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+)
+
+type snapshotEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	linkname string
+}
+
+func buildSnapshotTar(t *testing.T, entries []snapshotEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: 0o644, Linkname: e.linkname, Uid: 1000, Uname: "developer"}
+		if e.typeflag == tar.TypeReg {
+			header.Size = int64(len(e.body))
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("WriteHeader(%s) error: %v", e.name, err)
+		}
+		if e.typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatalf("Write(%s) error: %v", e.name, err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func readSnapshotTar(t *testing.T, data []byte) map[string]*tar.Header {
+	t.Helper()
+
+	headers := make(map[string]*tar.Header)
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return headers
+		}
+		if err != nil {
+			t.Fatalf("reading repacked archive: %v", err)
+		}
+		headers[header.Name] = header
+	}
+}
+
+func TestRepackClaudeSnapshot_RoundTrip(t *testing.T) {
+	// Docker's CopyFromContainer layout: entries under .claude/
+	source := buildSnapshotTar(t, []snapshotEntry{
+		{name: ".claude/", typeflag: tar.TypeDir},
+		{name: ".claude/CLAUDE.md", typeflag: tar.TypeReg, body: "# Rules"},
+		{name: ".claude/commands/", typeflag: tar.TypeDir},
+		{name: ".claude/commands/review.md", typeflag: tar.TypeReg, body: "Review the diff"},
+		{name: ".claude/latest.md", typeflag: tar.TypeSymlink, linkname: "commands/review.md"},
+	})
+
+	// Snapshot: repack into a gzipped archive
+	var snapshot bytes.Buffer
+	gz := gzip.NewWriter(&snapshot)
+	if _, err := repackClaudeSnapshot(bytes.NewReader(source), gz); err != nil {
+		t.Fatalf("snapshot repack error: %v", err)
+	}
+	gz.Close()
+
+	// Restore: the gzipped snapshot is accepted and repacked as a plain tar
+	var restored bytes.Buffer
+	result, err := repackClaudeSnapshot(bytes.NewReader(snapshot.Bytes()), &restored)
+	if err != nil {
+		t.Fatalf("restore repack error: %v", err)
+	}
+	if result.Files != 2 || result.Dirs != 2 || result.Symlinks != 1 || result.Bytes != int64(len("# Rules")+len("Review the diff")) {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	headers := readSnapshotTar(t, restored.Bytes())
+	for _, name := range []string{".claude/", ".claude/CLAUDE.md", ".claude/commands/", ".claude/commands/review.md", ".claude/latest.md"} {
+		if headers[name] == nil {
+			t.Errorf("missing entry %s in %v", name, headers)
+		}
+	}
+	if h := headers[".claude/CLAUDE.md"]; h != nil && (h.Uid != 0 || h.Uname != "") {
+		t.Errorf("ownership should be dropped, got uid %d uname %q", h.Uid, h.Uname)
+	}
+	if h := headers[".claude/latest.md"]; h != nil && h.Linkname != "commands/review.md" {
+		t.Errorf("symlink target = %q", h.Linkname)
+	}
+}
+
+func TestRepackClaudeSnapshot_NormalizesNames(t *testing.T) {
+	source := buildSnapshotTar(t, []snapshotEntry{
+		{name: "./.claude/settings.json", typeflag: tar.TypeReg, body: "{}"},
+		{name: ".claude//agents/a.md", typeflag: tar.TypeReg, body: "agent"},
+	})
+
+	var out bytes.Buffer
+	if _, err := repackClaudeSnapshot(bytes.NewReader(source), &out); err != nil {
+		t.Fatalf("repack error: %v", err)
+	}
+	headers := readSnapshotTar(t, out.Bytes())
+	if headers[".claude/settings.json"] == nil || headers[".claude/agents/a.md"] == nil {
+		t.Errorf("names not normalized: %v", headers)
+	}
+}
+
+func TestRepackClaudeSnapshot_RejectsUnsafeEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []snapshotEntry
+	}{
+		{"absolute path", []snapshotEntry{{name: "/etc/passwd", typeflag: tar.TypeReg, body: "x"}}},
+		{"parent traversal", []snapshotEntry{{name: ".claude/../.bashrc", typeflag: tar.TypeReg, body: "x"}}},
+		{"outside .claude", []snapshotEntry{{name: ".ssh/authorized_keys", typeflag: tar.TypeReg, body: "x"}}},
+		{"prefix lookalike", []snapshotEntry{{name: ".claude-evil/x", typeflag: tar.TypeReg, body: "x"}}},
+		{"absolute symlink", []snapshotEntry{{name: ".claude/link", typeflag: tar.TypeSymlink, linkname: "/etc"}}},
+		{"escaping symlink", []snapshotEntry{{name: ".claude/link", typeflag: tar.TypeSymlink, linkname: "../.ssh"}}},
+		{"write through symlink", []snapshotEntry{
+			{name: ".claude/link", typeflag: tar.TypeSymlink, linkname: "projects"},
+			{name: ".claude/link/file", typeflag: tar.TypeReg, body: "x"},
+		}},
+		{"hard link", []snapshotEntry{{name: ".claude/hard", typeflag: tar.TypeLink, linkname: ".claude/CLAUDE.md"}}},
+		{"device", []snapshotEntry{{name: ".claude/dev", typeflag: tar.TypeChar}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := buildSnapshotTar(t, tt.entries)
+			if _, err := repackClaudeSnapshot(bytes.NewReader(source), io.Discard); !errors.Is(err, ErrInvalidClaudeSnapshot) {
+				t.Fatalf("expected ErrInvalidClaudeSnapshot, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRepackClaudeSnapshot_RejectsEmptyAndGarbage(t *testing.T) {
+	if _, err := repackClaudeSnapshot(bytes.NewReader(buildSnapshotTar(t, nil)), io.Discard); !errors.Is(err, ErrInvalidClaudeSnapshot) {
+		t.Errorf("empty archive: expected ErrInvalidClaudeSnapshot, got %v", err)
+	}
+	if _, err := repackClaudeSnapshot(bytes.NewReader([]byte("definitely not a tar archive, but long enough to need a header block")), io.Discard); !errors.Is(err, ErrInvalidClaudeSnapshot) {
+		t.Errorf("garbage: expected ErrInvalidClaudeSnapshot, got %v", err)
+	}
+}
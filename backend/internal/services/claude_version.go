@@ -0,0 +1,158 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"cc-platform/internal/models"
+)
+
+// claudePinRoot holds pinned Claude CLI installs, one npm prefix per version. It lives outside
+// the shared ~/.npm-global volume so a pin only affects its own container.
+const claudePinRoot = "/opt/claude-code"
+
+// ErrInvalidClaudeVersion is returned for a claude_version that isn't an exact CLI version
+var ErrInvalidClaudeVersion = errors.New("invalid claude_version")
+
+// claudeVersionPattern matches an exact npm version such as 1.0.35 or 2.0.0-beta.1
+var claudeVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.]+)?$`)
+
+// claudeVersionOutputPattern finds the version in `claude --version` output, e.g. "1.0.35 (Claude Code)"
+var claudeVersionOutputPattern = regexp.MustCompile(`\b(\d+\.\d+\.\d+(?:-[0-9A-Za-z.]+)?)\b`)
+
+// ClaudeVersionInfo reports the Claude CLI found in a container
+type ClaudeVersionInfo struct {
+	Installed bool   `json:"installed"`
+	Version   string `json:"version,omitempty"`
+	Pinned    string `json:"pinned,omitempty"` // Version requested at creation; empty when not pinned
+	Output    string `json:"output,omitempty"` // Raw `claude --version` output, or why it failed
+}
+
+// normalizeClaudeVersion validates a version to pin. Empty keeps the image's CLI; a leading
+// "v" is dropped. Ranges and dist-tags are rejected so the pin is reproducible.
+func normalizeClaudeVersion(version string) (string, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" {
+		return "", nil
+	}
+	if !claudeVersionPattern.MatchString(version) {
+		return "", fmt.Errorf("%w: %q (expected an exact version like 1.0.35)", ErrInvalidClaudeVersion, version)
+	}
+	return version, nil
+}
+
+// parseClaudeVersion extracts the version from `claude --version` output
+func parseClaudeVersion(output string) (string, bool) {
+	match := claudeVersionOutputPattern.FindStringSubmatch(output)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// claudePinCommand installs a Claude CLI version into its own prefix as root and links it
+// into /usr/local/bin, which comes before the image's /usr/bin/claude on PATH. A throwaway
+// npm cache keeps root-owned files out of the shared npm cache volume.
+func claudePinCommand(version string) []string {
+	prefix := claudePinRoot + "/" + version
+	cache := "/tmp/claude-pin-npm-cache"
+	script := fmt.Sprintf(`command -v npm >/dev/null 2>&1 || { echo "npm is not installed in this image"; exit 1; }
+npm install -g --no-fund --no-audit --prefix %[1]s --cache %[2]s %[3]s 2>&1
+status=$?
+rm -rf %[2]s
+[ $status -eq 0 ] || exit $status
+ln -sfn %[1]s/bin/claude /usr/local/bin/claude`,
+		shellQuote(prefix), shellQuote(cache), shellQuote("@anthropic-ai/claude-code@"+version))
+	return []string{"bash", "-c", script}
+}
+
+// detectClaudeVersion runs `claude --version` as the container user. Images without the
+// CLI report Installed false rather than an error.
+func (s *ContainerService) detectClaudeVersion(ctx context.Context, container *models.Container) (*ClaudeVersionInfo, error) {
+	var output bytes.Buffer
+	exitCode, err := s.dockerClient.ExecStream(ctx, container.DockerID,
+		[]string{"bash", "-c", "command -v claude >/dev/null 2>&1 || exit 127; claude --version 2>&1"}, &output, &output)
+	if err != nil {
+		return nil, dockerError(fmt.Errorf("failed to run claude --version: %w", err))
+	}
+
+	info := &ClaudeVersionInfo{Pinned: container.ClaudeVersionPin, Output: strings.TrimSpace(output.String())}
+	if exitCode == 127 {
+		info.Output = "claude is not installed in this container"
+		return info, nil
+	}
+	info.Installed = true
+	if exitCode != 0 {
+		return info, nil
+	}
+	info.Version, _ = parseClaudeVersion(info.Output)
+	return info, nil
+}
+
+// GetClaudeVersion reports the Claude CLI version in a running container and stores it
+func (s *ContainerService) GetClaudeVersion(ctx context.Context, id uint) (*ClaudeVersionInfo, error) {
+	container, err := s.runningContainerForSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.detectClaudeVersion(ctx, container)
+	if err != nil {
+		return nil, err
+	}
+	if info.Version != container.ClaudeVersion {
+		if err := s.db.Model(container).Update("claude_version", info.Version).Error; err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// installPinnedClaude installs the container's pinned Claude CLI version and checks that
+// `claude` now resolves to it
+func (s *ContainerService) installPinnedClaude(ctx context.Context, container *models.Container) error {
+	output, err := s.dockerClient.ExecAsRoot(ctx, container.DockerID, claudePinCommand(container.ClaudeVersionPin))
+	if err != nil {
+		return fmt.Errorf("install failed: %v", err)
+	}
+
+	info, err := s.detectClaudeVersion(ctx, container)
+	if err != nil {
+		return err
+	}
+	if info.Version != container.ClaudeVersionPin {
+		found := info.Version
+		if !info.Installed {
+			found = "no claude"
+		}
+		return fmt.Errorf("expected claude %s but found %s: %s", container.ClaudeVersionPin, found, lastLines(output, 5))
+	}
+	return s.db.Model(container).Update("claude_version", info.Version).Error
+}
+
+// recordClaudeVersion stores the CLI version found at the end of initialization. Failures are
+// only logged; the version endpoint detects it again on demand.
+func (s *ContainerService) recordClaudeVersion(ctx context.Context, container *models.Container) {
+	info, err := s.detectClaudeVersion(ctx, container)
+	if err != nil {
+		s.logger(ctx, container.ID).Printf("Failed to detect Claude CLI version: %v", err)
+		return
+	}
+	if !info.Installed {
+		s.addLog(ctx, container.ID, models.LogLevelWarn, models.LogStageInit, "Claude CLI is not installed in this container")
+		return
+	}
+	s.db.Model(container).Update("claude_version", info.Version)
+}
+
+// lastLines returns the last n lines of output
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
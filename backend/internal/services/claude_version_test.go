@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseClaudeVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+		ok     bool
+	}{
+		{"1.0.35 (Claude Code)", "1.0.35", true},
+		{"2.0.14 (Claude Code)\n", "2.0.14", true},
+		{"claude 2.1.0-beta.3", "2.1.0-beta.3", true},
+		{"Warning: update available\n1.0.120 (Claude Code)", "1.0.120", true},
+		{"bash: claude: command not found", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseClaudeVersion(tt.output)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseClaudeVersion(%q) = %q, %v; want %q, %v", tt.output, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNormalizeClaudeVersion(t *testing.T) {
+	valid := map[string]string{
+		"":             "",
+		"1.0.35":       "1.0.35",
+		" v2.0.14 ":    "2.0.14",
+		"2.1.0-beta.3": "2.1.0-beta.3",
+	}
+	for in, want := range valid {
+		got, err := normalizeClaudeVersion(in)
+		if err != nil || got != want {
+			t.Errorf("normalizeClaudeVersion(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"latest", "^1.0.0", "1.0", "1.0.35; rm -rf /", "1.0.35 --registry=http://evil"} {
+		if _, err := normalizeClaudeVersion(in); !errors.Is(err, ErrInvalidClaudeVersion) {
+			t.Errorf("normalizeClaudeVersion(%q) error = %v, want ErrInvalidClaudeVersion", in, err)
+		}
+	}
+}
+
+func TestClaudePinCommand(t *testing.T) {
+	cmd := claudePinCommand("1.0.35")
+	if len(cmd) != 3 || cmd[0] != "bash" || cmd[1] != "-c" {
+		t.Fatalf("unexpected command: %v", cmd)
+	}
+	script := cmd[2]
+	for _, want := range []string{
+		"command -v npm",
+		"--prefix '/opt/claude-code/1.0.35'",
+		"'@anthropic-ai/claude-code@1.0.35'",
+		"ln -sfn '/opt/claude-code/1.0.35'/bin/claude /usr/local/bin/claude",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("pin script missing %q:\n%s", want, script)
+		}
+	}
+	// The pin must not install into the ~/.npm-global volume shared by all containers
+	if strings.Contains(script, ".npm-global") || !strings.Contains(script, "--cache '/tmp/") {
+		t.Errorf("pin script touches shared npm volumes:\n%s", script)
+	}
+}
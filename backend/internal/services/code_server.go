@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"cc-platform/internal/models"
+	"cc-platform/pkg/crypto"
+)
+
+// code-server authentication modes
+const (
+	CodeServerAuthNone     = "none"     // Anyone who can reach the port gets an editor and a shell
+	CodeServerAuthPassword = "password" // A generated password is required to log in
+)
+
+var (
+	// ErrInvalidCodeServerAuth is returned for an unknown code-server auth mode
+	ErrInvalidCodeServerAuth = errors.New("invalid code-server auth mode")
+	// ErrCodeServerDisabled is returned for code-server requests on a container without code-server
+	ErrCodeServerDisabled = errors.New("code-server is not enabled for this container")
+	// ErrCodeServerNoPassword is returned when rotating the password of a container without password auth
+	ErrCodeServerNoPassword = errors.New("code-server runs without password auth")
+	// ErrInvalidCodeServerExtension is returned for a malformed extension ID or too many extensions
+	ErrInvalidCodeServerExtension = errors.New("invalid code-server extension")
+)
+
+// MaxCodeServerExtensions is how many extensions a container may preinstall
+const MaxCodeServerExtensions = 50
+
+// extensionInstalledMarker is printed only when an install succeeds, since exec output carries no exit code
+const extensionInstalledMarker = "__CC_EXTENSION_INSTALLED__"
+
+// codeServerExtensionPattern matches marketplace IDs (publisher.name) with an optional @version
+var codeServerExtensionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*\.[A-Za-z0-9][A-Za-z0-9._-]*(@[A-Za-z0-9.+-]+)?$`)
+
+// CodeServerAccess is how to reach a container's code-server
+type CodeServerAccess struct {
+	URL      string `json:"url"` // Subdomain URL, or the platform proxy path when routed through the API
+	Auth     string `json:"auth"`
+	Password string `json:"password,omitempty"`
+}
+
+// normalizeCodeServerAuth validates an auth mode, defaulting to none for backward compatibility
+func normalizeCodeServerAuth(auth string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(auth)) {
+	case "", CodeServerAuthNone:
+		return CodeServerAuthNone, nil
+	case CodeServerAuthPassword:
+		return CodeServerAuthPassword, nil
+	default:
+		return "", fmt.Errorf("%w: %q (use none or password)", ErrInvalidCodeServerAuth, auth)
+	}
+}
+
+// normalizeCodeServerExtensions trims and de-duplicates extension IDs and checks their format.
+// Extensions are only installed when code-server is enabled.
+func normalizeCodeServerExtensions(ids []string, enableCodeServer bool) ([]string, error) {
+	var extensions []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[strings.ToLower(id)] {
+			continue
+		}
+		if !codeServerExtensionPattern.MatchString(id) {
+			return nil, fmt.Errorf("%w: %q (use publisher.name or publisher.name@version)", ErrInvalidCodeServerExtension, id)
+		}
+		seen[strings.ToLower(id)] = true
+		extensions = append(extensions, id)
+	}
+	if len(extensions) > MaxCodeServerExtensions {
+		return nil, fmt.Errorf("%w: at most %d extensions", ErrInvalidCodeServerExtension, MaxCodeServerExtensions)
+	}
+	if len(extensions) > 0 && !enableCodeServer {
+		return nil, fmt.Errorf("%w: code_server_extensions requires enable_code_server", ErrInvalidCodeServerExtension)
+	}
+	return extensions, nil
+}
+
+// codeServerExtensionCommand builds the command that installs one extension
+func codeServerExtensionCommand(id string) []string {
+	return []string{"sh", "-c", fmt.Sprintf("code-server --install-extension %s 2>&1 && echo %s", shellQuote(id), extensionInstalledMarker)}
+}
+
+// installCodeServerExtensions installs the container's extensions one at a time, logging
+// progress. A failed install is logged and skipped; the IDs that failed are returned.
+func (s *ContainerService) installCodeServerExtensions(ctx context.Context, client containerExecutor, c *models.Container) []string {
+	var failed []string
+	total := len(c.CodeServerExtensions)
+	for i, id := range c.CodeServerExtensions {
+		if ctx.Err() != nil {
+			failed = append(failed, c.CodeServerExtensions[i:]...)
+			break
+		}
+		s.addLog(ctx, c.ID, models.LogLevelInfo, models.LogStageInit,
+			fmt.Sprintf("Installing code-server extension %s (%d/%d)", id, i+1, total))
+		output, err := client.ExecInContainer(ctx, c.DockerID, codeServerExtensionCommand(id))
+		output = demuxExecOutput(output)
+		if err == nil && strings.Contains(output, extensionInstalledMarker) {
+			continue
+		}
+		if err == nil {
+			err = fmt.Errorf("install did not complete: %s", lastLine(output))
+		}
+		failed = append(failed, id)
+		s.addLog(ctx, c.ID, models.LogLevelWarn, models.LogStageInit,
+			fmt.Sprintf("Failed to install code-server extension %s: %v", id, err))
+	}
+	if total > 0 {
+		s.addLog(ctx, c.ID, models.LogLevelInfo, models.LogStageInit,
+			fmt.Sprintf("Installed %d of %d code-server extension(s)", total-len(failed), total))
+	}
+	return failed
+}
+
+// lastLine returns the last non-empty line of command output
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// codeServerCommand builds the command that starts code-server in the background.
+// With password auth the password is passed through code-server's PASSWORD env var.
+func codeServerCommand(workDir, auth, password string) []string {
+	env := ""
+	if auth == CodeServerAuthPassword {
+		env = fmt.Sprintf("PASSWORD=%s ", shellQuote(password))
+	} else {
+		auth = CodeServerAuthNone
+	}
+	return []string{
+		"bash", "-c",
+		fmt.Sprintf("%snohup code-server --bind-addr 0.0.0.0:%d --auth %s %s > /tmp/code-server.log 2>&1 &",
+			env, CodeServerInternalPort, auth, workDir),
+	}
+}
+
+// generateCodeServerPassword returns a random URL-safe password
+func generateCodeServerPassword() (string, error) {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// newCodeServerPassword generates a password and returns it with its encrypted form for storage
+func (s *ContainerService) newCodeServerPassword() (string, string, error) {
+	password, err := generateCodeServerPassword()
+	if err != nil {
+		return "", "", err
+	}
+	encrypted, err := crypto.Encrypt(password, []byte(s.config.EncryptionKey))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt code-server password: %w", err)
+	}
+	return password, encrypted, nil
+}
+
+// codeServerPassword decrypts a container's stored code-server password
+func (s *ContainerService) codeServerPassword(c *models.Container) (string, error) {
+	if c.CodeServerPassword == "" {
+		return "", nil
+	}
+	password, err := crypto.Decrypt(c.CodeServerPassword, []byte(s.config.EncryptionKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt code-server password: %w", err)
+	}
+	return password, nil
+}
+
+// GetCodeServerAccess returns a container's code-server URL and, with password auth, its password
+func (s *ContainerService) GetCodeServerAccess(id uint) (*CodeServerAccess, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	if !container.EnableCodeServer {
+		return nil, ErrCodeServerDisabled
+	}
+
+	access := &CodeServerAccess{Auth: CodeServerAuthNone}
+	if container.CodeServerDomain != "" {
+		access.URL = "http://" + container.CodeServerDomain
+	} else {
+		access.URL = fmt.Sprintf("/api/proxy/%d/%d/", container.ID, CodeServerInternalPort)
+	}
+	if container.CodeServerAuth == CodeServerAuthPassword {
+		access.Auth = CodeServerAuthPassword
+		if access.Password, err = s.codeServerPassword(container); err != nil {
+			return nil, err
+		}
+	}
+	return access, nil
+}
+
+// RotateCodeServerPassword replaces a container's code-server password and, when the container
+// is running, restarts code-server so the old password stops working
+func (s *ContainerService) RotateCodeServerPassword(ctx context.Context, id uint) (*CodeServerAccess, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	if !container.EnableCodeServer {
+		return nil, ErrCodeServerDisabled
+	}
+	if container.CodeServerAuth != CodeServerAuthPassword {
+		return nil, ErrCodeServerNoPassword
+	}
+
+	_, encrypted, err := s.newCodeServerPassword()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(container).Update("code_server_password", encrypted).Error; err != nil {
+		return nil, err
+	}
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup, "code-server password rotated")
+
+	if container.Status == models.ContainerStatusRunning {
+		stopCmd := []string{"bash", "-c", fmt.Sprintf("pkill -f 'code-server --bind-addr 0.0.0.0:%d' || true", CodeServerInternalPort)}
+		if _, err := s.dockerClient.ExecInContainer(ctx, container.DockerID, stopCmd); err != nil {
+			return nil, fmt.Errorf("failed to stop code-server: %w", err)
+		}
+		if err := s.StartCodeServer(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetCodeServerAccess(id)
+}
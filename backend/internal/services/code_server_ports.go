@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"cc-platform/internal/config"
+	"cc-platform/internal/constants"
+)
+
+// ErrCodeServerPortsExhausted is returned when every host port in the code-server range is taken
+var ErrCodeServerPortsExhausted = errors.New("no free code-server host port")
+
+// validateCodeServerPortRange checks a code-server host port range; both ends are inclusive
+func validateCodeServerPortRange(start, end int) error {
+	if start < 1 || end > 65535 {
+		return fmt.Errorf("code-server port range %d-%d must be within 1-65535", start, end)
+	}
+	if start > end {
+		return fmt.Errorf("code-server port range start %d is after end %d", start, end)
+	}
+	return nil
+}
+
+// loadCodeServerPortRange returns the configured code-server host port range, falling back to
+// the default range when it is unset or invalid
+func loadCodeServerPortRange(cfg *config.Config) (start, end int) {
+	if cfg == nil || (cfg.CodeServerPortRangeStart == 0 && cfg.CodeServerPortRangeEnd == 0) {
+		return constants.CodeServerPortStart, constants.CodeServerPortEnd
+	}
+	if err := validateCodeServerPortRange(cfg.CodeServerPortRangeStart, cfg.CodeServerPortRangeEnd); err != nil {
+		log.Printf("Warning: using the default code-server port range %d-%d: %v",
+			constants.CodeServerPortStart, constants.CodeServerPortEnd, err)
+		return constants.CodeServerPortStart, constants.CodeServerPortEnd
+	}
+	return cfg.CodeServerPortRangeStart, cfg.CodeServerPortRangeEnd
+}
+
+// allocateCodeServerPort picks a free host port in the code-server range. Ports mapped or
+// assigned to code-server by other containers are skipped even while those containers are
+// stopped, as are ports handed out to creations that haven't been saved yet. The port stays
+// reserved until releaseCodeServerPort, which the caller calls once the container is saved.
+// exclude is the container being recreated, whose ports may be reused.
+func (s *ContainerService) allocateCodeServerPort(exclude uint) (int, error) {
+	start, end := s.codeServerPortStart, s.codeServerPortEnd
+	if start == 0 && end == 0 {
+		start, end = constants.CodeServerPortStart, constants.CodeServerPortEnd
+	}
+
+	s.portMu.Lock()
+	defer s.portMu.Unlock()
+
+	used := s.usedHostPorts(exclude)
+	for port := start; port <= end; port++ {
+		if used[port] != "" || s.pendingPorts[port] || !isPortFree(port) {
+			continue
+		}
+		if s.pendingPorts == nil {
+			s.pendingPorts = make(map[int]bool)
+		}
+		s.pendingPorts[port] = true
+		return port, nil
+	}
+	return 0, fmt.Errorf("%w: all ports %d-%d are in use", ErrCodeServerPortsExhausted, start, end)
+}
+
+// releaseCodeServerPort ends the reservation of a port from allocateCodeServerPort
+func (s *ContainerService) releaseCodeServerPort(port int) {
+	s.portMu.Lock()
+	defer s.portMu.Unlock()
+	delete(s.pendingPorts, port)
+}
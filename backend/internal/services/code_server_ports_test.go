@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"cc-platform/internal/config"
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+)
+
+func TestLoadCodeServerPortRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int
+		wantStart  int
+		wantEnd    int
+	}{
+		{"configured", 20000, 20010, 20000, 20010},
+		{"single port", 20000, 20000, 20000, 20000},
+		{"unset", 0, 0, constants.CodeServerPortStart, constants.CodeServerPortEnd},
+		{"reversed", 20010, 20000, constants.CodeServerPortStart, constants.CodeServerPortEnd},
+		{"out of range", 60000, 70000, constants.CodeServerPortStart, constants.CodeServerPortEnd},
+		{"zero start", 0, 100, constants.CodeServerPortStart, constants.CodeServerPortEnd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := loadCodeServerPortRange(&config.Config{CodeServerPortRangeStart: tt.start, CodeServerPortRangeEnd: tt.end})
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("Expected %d-%d, got %d-%d", tt.wantStart, tt.wantEnd, start, end)
+			}
+		})
+	}
+}
+
+func TestAllocateCodeServerPort_SkipsReservedPorts(t *testing.T) {
+	s := setupPauseTestService(t)
+	s.codeServerPortStart, s.codeServerPortEnd = 47811, 47814
+
+	// A stopped container keeps its code-server port; another maps the next port itself
+	stopped := createPauseTestContainer(t, s, "stopped", models.ContainerStatusStopped)
+	s.db.Model(stopped).Update("code_server_host_port", 47811)
+	mapped := createPauseTestContainer(t, s, "mapped", models.ContainerStatusRunning)
+	s.db.Model(mapped).Update("exposed_ports", `[{"container_port":3000,"host_port":47812}]`)
+	deleted := createPauseTestContainer(t, s, "deleted", models.ContainerStatusDeleted)
+	s.db.Model(deleted).Update("code_server_host_port", 47813)
+
+	first, err := s.allocateCodeServerPort(0)
+	if err != nil || first != 47813 {
+		t.Fatalf("Expected port 47813, got %d (%v)", first, err)
+	}
+	// The first port is still pending, so a concurrent creation gets the next one
+	second, err := s.allocateCodeServerPort(0)
+	if err != nil || second != 47814 {
+		t.Fatalf("Expected port 47814, got %d (%v)", second, err)
+	}
+
+	if _, err := s.allocateCodeServerPort(0); !errors.Is(err, ErrCodeServerPortsExhausted) {
+		t.Errorf("Expected ErrCodeServerPortsExhausted, got %v", err)
+	}
+
+	// A recreated container may take its own port back
+	if port, err := s.allocateCodeServerPort(stopped.ID); err != nil || port != 47811 {
+		t.Errorf("Expected the recreated container's port 47811, got %d (%v)", port, err)
+	}
+
+	s.releaseCodeServerPort(first)
+	if port, err := s.allocateCodeServerPort(0); err != nil || port != first {
+		t.Errorf("Expected released port %d, got %d (%v)", first, port, err)
+	}
+}
+
+func TestValidatePortAvailability_CodeServerPort(t *testing.T) {
+	s := setupPauseTestService(t)
+	owner := createPauseTestContainer(t, s, "owner", models.ContainerStatusStopped)
+	s.db.Model(owner).Update("code_server_host_port", 47821)
+
+	problems := s.validatePortAvailability(CreateContainerInput{
+		PortMappings: []PortMapping{{ContainerPort: 3000, HostPort: 47821}},
+	})
+	if len(problems) != 1 || problems[0].Code != InputProblemPortUnavailable {
+		t.Errorf("Expected the code-server port to be reported as used, got %+v", problems)
+	}
+}
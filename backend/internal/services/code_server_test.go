@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/config"
+	"cc-platform/internal/models"
+)
+
+func TestCodeServerCommand(t *testing.T) {
+	none := codeServerCommand("/app/web", CodeServerAuthNone, "")
+	if len(none) != 3 || none[0] != "bash" || none[1] != "-c" {
+		t.Fatalf("Expected a bash -c command, got %v", none)
+	}
+	if !strings.HasPrefix(none[2], "nohup code-server --bind-addr 0.0.0.0:8443 --auth none /app/web ") {
+		t.Errorf("Expected --auth none without a password, got %q", none[2])
+	}
+	if strings.Contains(none[2], "PASSWORD=") {
+		t.Errorf("Expected no PASSWORD env with --auth none, got %q", none[2])
+	}
+
+	password := codeServerCommand("/app/web", CodeServerAuthPassword, "it's-secret")
+	want := `PASSWORD='it'"'"'s-secret' nohup code-server --bind-addr 0.0.0.0:8443 --auth password /app/web `
+	if !strings.HasPrefix(password[2], want) {
+		t.Errorf("Expected %q, got %q", want, password[2])
+	}
+
+	// Containers created before auth modes existed have an empty mode and keep --auth none
+	if legacy := codeServerCommand("/app", "", ""); !strings.Contains(legacy[2], "--auth none") {
+		t.Errorf("Expected an empty mode to use --auth none, got %q", legacy[2])
+	}
+}
+
+func TestNormalizeCodeServerAuth(t *testing.T) {
+	for input, want := range map[string]string{"": CodeServerAuthNone, "none": CodeServerAuthNone, " Password ": CodeServerAuthPassword} {
+		got, err := normalizeCodeServerAuth(input)
+		if err != nil || got != want {
+			t.Errorf("normalizeCodeServerAuth(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := normalizeCodeServerAuth("token"); !errors.Is(err, ErrInvalidCodeServerAuth) {
+		t.Errorf("Expected ErrInvalidCodeServerAuth, got %v", err)
+	}
+}
+
+func TestCodeServerAccessAndRotate(t *testing.T) {
+	s := setupPauseTestService(t)
+	s.config = &config.Config{EncryptionKey: "test-encryption-key-32-bytes-ok!"}
+
+	_, encrypted, err := s.newCodeServerPassword()
+	if err != nil {
+		t.Fatalf("newCodeServerPassword failed: %v", err)
+	}
+	c := createPauseTestContainer(t, s, "code", models.ContainerStatusStopped)
+	s.db.Model(c).Updates(map[string]interface{}{
+		"enable_code_server":   true,
+		"code_server_auth":     CodeServerAuthPassword,
+		"code_server_password": encrypted,
+	})
+
+	access, err := s.GetCodeServerAccess(c.ID)
+	if err != nil {
+		t.Fatalf("GetCodeServerAccess failed: %v", err)
+	}
+	if access.Auth != CodeServerAuthPassword || access.Password == "" || access.Password == encrypted {
+		t.Errorf("Expected the decrypted password, got %+v", access)
+	}
+	if access.URL != fmt.Sprintf("/api/proxy/%d/8443/", c.ID) {
+		t.Errorf("Expected the proxy URL, got %q", access.URL)
+	}
+
+	rotated, err := s.RotateCodeServerPassword(t.Context(), c.ID)
+	if err != nil {
+		t.Fatalf("RotateCodeServerPassword failed: %v", err)
+	}
+	if rotated.Password == "" || rotated.Password == access.Password {
+		t.Errorf("Expected a new password, got %q", rotated.Password)
+	}
+
+	// Without password auth there is nothing to rotate and no password to return
+	s.db.Model(c).Update("code_server_auth", CodeServerAuthNone)
+	if _, err := s.RotateCodeServerPassword(t.Context(), c.ID); !errors.Is(err, ErrCodeServerNoPassword) {
+		t.Errorf("Expected ErrCodeServerNoPassword, got %v", err)
+	}
+	if access, _ := s.GetCodeServerAccess(c.ID); access == nil || access.Password != "" {
+		t.Errorf("Expected no password with --auth none, got %+v", access)
+	}
+
+	s.db.Model(c).Update("enable_code_server", false)
+	if _, err := s.GetCodeServerAccess(c.ID); !errors.Is(err, ErrCodeServerDisabled) {
+		t.Errorf("Expected ErrCodeServerDisabled, got %v", err)
+	}
+}
+
+func TestNormalizeCodeServerExtensions(t *testing.T) {
+	got, err := normalizeCodeServerExtensions([]string{" ms-python.python ", "golang.go@0.41.0", "MS-Python.python", ""}, true)
+	if err != nil {
+		t.Fatalf("normalizeCodeServerExtensions failed: %v", err)
+	}
+	if len(got) != 2 || got[0] != "ms-python.python" || got[1] != "golang.go@0.41.0" {
+		t.Errorf("Expected trimmed, de-duplicated IDs, got %v", got)
+	}
+	for _, bad := range [][]string{{"python"}, {"ms-python.python; rm -rf /"}, {"../evil.ext"}} {
+		if _, err := normalizeCodeServerExtensions(bad, true); !errors.Is(err, ErrInvalidCodeServerExtension) {
+			t.Errorf("Expected ErrInvalidCodeServerExtension for %v, got %v", bad, err)
+		}
+	}
+	if _, err := normalizeCodeServerExtensions([]string{"golang.go"}, false); !errors.Is(err, ErrInvalidCodeServerExtension) {
+		t.Errorf("Expected extensions without code-server to be rejected, got %v", err)
+	}
+}
+
+func TestInstallCodeServerExtensions(t *testing.T) {
+	s := setupPauseTestService(t)
+	c := createPauseTestContainer(t, s, "ext", models.ContainerStatusRunning)
+	c.CodeServerExtensions = models.CodeServerExtensionList{"ms-python.python", "bad.missing", "golang.go", "esbenp.prettier-vscode"}
+
+	client := newMockDockerClient()
+	client.setExecResult(c.DockerID, codeServerExtensionCommand("ms-python.python"), "Installing...\n"+extensionInstalledMarker+"\n", nil)
+	client.setExecResult(c.DockerID, codeServerExtensionCommand("bad.missing"), "Extension 'bad.missing' not found.\n", nil)
+	client.setExecResult(c.DockerID, codeServerExtensionCommand("golang.go"), "", errors.New("exec failed"))
+	client.setExecResult(c.DockerID, codeServerExtensionCommand("esbenp.prettier-vscode"), extensionInstalledMarker+"\n", nil)
+
+	failed := s.installCodeServerExtensions(t.Context(), client, c)
+	if len(failed) != 2 || failed[0] != "bad.missing" || failed[1] != "golang.go" {
+		t.Errorf("Expected the two failed installs, got %v", failed)
+	}
+
+	// Every extension is attempted in order, one install per exec
+	if len(client.execCalls) != 4 {
+		t.Fatalf("Expected 4 installs, got %d", len(client.execCalls))
+	}
+	for i, id := range c.CodeServerExtensions {
+		cmd := client.execCalls[i].cmd
+		if !strings.Contains(cmd[2], "code-server --install-extension '"+id+"'") {
+			t.Errorf("Expected install %d to be %s, got %q", i, id, cmd[2])
+		}
+	}
+
+	var warnings int64
+	s.db.Model(&models.ContainerLog{}).Where("container_id = ? AND level = ?", c.ID, models.LogLevelWarn).Count(&warnings)
+	if warnings != 2 {
+		t.Errorf("Expected a warning per failed extension, got %d", warnings)
+	}
+}
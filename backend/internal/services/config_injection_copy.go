@@ -0,0 +1,205 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+// ErrCopyConfigsSameContainer is returned when a container's configs would be copied onto itself
+var ErrCopyConfigsSameContainer = errors.New("source and target container are the same")
+
+// copiedConfigTypeFiles is the config type reported when copying the ~/.claude files fails
+const copiedConfigTypeFiles = "FILES"
+
+// claudeCopyExtractedMarker is printed only when tar extracted the archive, since exec output carries no exit code
+const claudeCopyExtractedMarker = "__CC_EXTRACTED__"
+
+// claudeConfigCopyScript prints a base64-encoded gzipped tar of the ~/.claude entries that are
+// copied between containers, with paths under .claude/. Nothing is printed when none exist.
+var claudeConfigCopyScript = `h="${CC_CONFIG_HOME:-$HOME}"
+cd "$h" 2>/dev/null || exit 0
+set --
+for p in .claude/CLAUDE.md .claude/skills .claude/commands .claude/agents; do [ -e "$p" ] && set -- "$@" "$p"; done
+[ $# -gt 0 ] && tar -czf - "$@" | base64 | tr -d '\n'
+true`
+
+// CopyClaudeConfig copies CLAUDE.md, skills, commands, agents and user-scoped MCP servers from
+// one container to another. Existing files and servers with the same name are overwritten;
+// anything else in the target is kept. Each copied item is listed as successful.
+func (s *configInjectionServiceImpl) CopyClaudeConfig(ctx context.Context, sourceID, targetID string) (*models.InjectionStatus, error) {
+	status := &models.InjectionStatus{
+		ContainerID: targetID,
+		Successful:  []string{},
+		Failed:      []models.FailedTemplate{},
+		Warnings:    []string{},
+		InjectedAt:  time.Now(),
+	}
+
+	output, err := s.dockerClient.ExecInContainer(ctx, sourceID, []string{"sh", "-c", claudeConfigCopyScript})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ~/.claude from the source container: %w", err)
+	}
+	sourceServers, _ := s.readJSONFile(ctx, sourceID, s.configHomePath(".claude.json"))["mcpServers"].(map[string]interface{})
+
+	if encoded := strings.TrimSpace(demuxExecOutput(output)); encoded != "" {
+		items, err := s.copyClaudeFiles(ctx, targetID, encoded)
+		if err != nil {
+			status.Failed = append(status.Failed, models.FailedTemplate{TemplateName: "~/.claude", ConfigType: copiedConfigTypeFiles, Reason: err.Error()})
+		} else {
+			status.Successful = append(status.Successful, items...)
+		}
+	}
+
+	if len(sourceServers) > 0 {
+		if err := s.copyMCPServers(ctx, targetID, sourceServers); err != nil {
+			status.Failed = append(status.Failed, models.FailedTemplate{TemplateName: "mcpServers", ConfigType: string(models.ConfigTypeMCP), Reason: err.Error()})
+		} else {
+			names := make([]string, 0, len(sourceServers))
+			for name := range sourceServers {
+				names = append(names, "mcp:"+name)
+			}
+			sort.Strings(names)
+			status.Successful = append(status.Successful, names...)
+		}
+	}
+
+	if len(status.Successful) == 0 && len(status.Failed) == 0 {
+		status.Warnings = append(status.Warnings, "the source container has no Claude configuration to copy")
+	}
+	return status, nil
+}
+
+// copyClaudeFiles validates the source archive like a ~/.claude snapshot, extracts it into the
+// target's home directory and returns the copied items
+func (s *configInjectionServiceImpl) copyClaudeFiles(ctx context.Context, targetID, encoded string) ([]string, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ~/.claude archive: %w", err)
+	}
+	var archive bytes.Buffer
+	if _, err := repackClaudeSnapshot(bytes.NewReader(data), &archive); err != nil {
+		return nil, err
+	}
+	items, err := copiedClaudeItems(archive.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	tempPath := fmt.Sprintf("/tmp/claude_copy_%d.tar", time.Now().UnixNano())
+	if err := s.writeBinaryFile(ctx, targetID, tempPath, archive.Bytes()); err != nil {
+		return nil, err
+	}
+	extractCmd := []string{"sh", "-c", fmt.Sprintf(`h="${CC_CONFIG_HOME:-$HOME}"; tar -xf %[1]s -C "$h" 2>&1; rc=$?; rm -f %[1]s; [ $rc -eq 0 ] && echo %[2]s; exit $rc`, tempPath, claudeCopyExtractedMarker)}
+	output, err := s.dockerClient.ExecInContainer(ctx, targetID, extractCmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract ~/.claude archive: %w", err)
+	}
+	if output = demuxExecOutput(output); !strings.Contains(output, claudeCopyExtractedMarker) {
+		return nil, fmt.Errorf("failed to extract ~/.claude archive: %s", strings.TrimSpace(output))
+	}
+	return items, nil
+}
+
+// copiedClaudeItems names what a .claude archive holds: CLAUDE.md, skill:<dir>, command:<name>
+// and agent:<name>, in archive order
+func copiedClaudeItems(archive []byte) ([]string, error) {
+	var items []string
+	seen := make(map[string]bool)
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ~/.claude archive: %w", err)
+		}
+		rel := strings.TrimPrefix(strings.TrimSuffix(header.Name, "/"), claudeSnapshotRoot+"/")
+		kind, name, _ := strings.Cut(rel, "/")
+		var item string
+		switch {
+		case kind == "CLAUDE.md":
+			item = kind
+		case kind == "skills" && name != "":
+			dir, _, _ := strings.Cut(name, "/")
+			item = "skill:" + dir
+		case kind == "commands" && header.Typeflag == tar.TypeReg && strings.HasSuffix(name, ".md"):
+			item = "command:" + strings.TrimSuffix(name, ".md")
+		case kind == "agents" && header.Typeflag == tar.TypeReg && strings.HasSuffix(name, ".md"):
+			item = "agent:" + strings.TrimSuffix(name, ".md")
+		}
+		if item != "" && !seen[item] {
+			seen[item] = true
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// copyMCPServers merges the source's mcpServers into the target's ~/.claude.json
+func (s *configInjectionServiceImpl) copyMCPServers(ctx context.Context, targetID string, servers map[string]interface{}) error {
+	path := s.configHomePath(".claude.json")
+	configJSON := s.readJSONFile(ctx, targetID, path)
+	targetServers, ok := configJSON["mcpServers"].(map[string]interface{})
+	if !ok {
+		targetServers = make(map[string]interface{})
+	}
+	for name, server := range servers {
+		targetServers[name] = server
+	}
+	configJSON["mcpServers"] = targetServers
+
+	content, err := json.MarshalIndent(configJSON, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal MCP config: %w", err)
+	}
+	return s.writeFile(ctx, targetID, path, string(content))
+}
+
+// CopyClaudeConfigFrom copies the live Claude configuration of the source container into the
+// target and stores the outcome as the target's injection status. Both must be running.
+func (s *ContainerService) CopyClaudeConfigFrom(ctx context.Context, targetID, sourceID uint) (*models.InjectionStatus, error) {
+	if targetID == sourceID {
+		return nil, ErrCopyConfigsSameContainer
+	}
+	target, err := s.runningContainerForSnapshot(targetID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.runningContainerForSnapshot(sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("source container: %w", err)
+	}
+	if s.configInjectionService == nil {
+		return nil, fmt.Errorf("config injection service not available")
+	}
+
+	status, err := s.configInjectionService.CopyClaudeConfig(ctx, source.DockerID, target.DockerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(&models.Container{}).Where("id = ?", targetID).Update("injection_status", status).Error; err != nil {
+		s.logger(ctx, targetID).Printf("Failed to store injection status: %v", err)
+	}
+	if len(status.Successful) > 0 {
+		s.addLog(ctx, targetID, models.LogLevelInfo, models.LogStageInit,
+			fmt.Sprintf("Copied configs from container '%s': %v", source.Name, status.Successful))
+	}
+	for _, failed := range status.Failed {
+		s.addLog(ctx, targetID, models.LogLevelWarn, models.LogStageInit,
+			fmt.Sprintf("Failed to copy %s from container '%s': %s", failed.TemplateName, source.Name, failed.Reason))
+	}
+	return status, nil
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+// copyFakeExecutor plays a source container with a ~/.claude archive and a target container
+// whose ~/.claude.json and uploaded archive are kept in memory
+type copyFakeExecutor struct {
+	mu           sync.Mutex
+	sourceID     string
+	sourceTar    string // base64 gzipped tar printed by claudeConfigCopyScript
+	sourceJSON   string
+	targetJSON   string
+	targetUpload strings.Builder // base64 chunks written by writeBinaryFile
+	extracted    bool
+	extractFails bool
+}
+
+func (f *copyFakeExecutor) ExecInContainer(ctx context.Context, containerID string, cmd []string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	script := cmd[len(cmd)-1]
+	if containerID == f.sourceID {
+		switch {
+		case script == claudeConfigCopyScript:
+			return f.sourceTar, nil
+		case strings.HasPrefix(script, "cat ") && strings.Contains(script, ".claude.json"):
+			return f.sourceJSON, nil
+		}
+		return "", errors.New("unexpected command in the source container")
+	}
+
+	switch {
+	case strings.HasPrefix(script, "echo -n '"):
+		chunk := strings.TrimPrefix(script, "echo -n '")
+		f.targetUpload.WriteString(chunk[:strings.Index(chunk, "'")])
+	case strings.Contains(script, "tar -xf"):
+		if f.extractFails {
+			return "tar: .claude/skills: Cannot mkdir: Permission denied\n", nil
+		}
+		f.extracted = true
+		return claudeCopyExtractedMarker + "\n", nil
+	case strings.Contains(script, "CONFIGEOF"):
+		start := strings.Index(script, "CONFIGEOF'\n")
+		end := strings.LastIndex(script, "\nCONFIGEOF")
+		f.targetJSON = script[start+len("CONFIGEOF'\n") : end]
+	case strings.HasPrefix(script, "cat ") && strings.Contains(script, ".claude.json"):
+		return f.targetJSON, nil
+	}
+	return "", nil
+}
+
+// claudeCopyArchive builds the base64 gzipped tar the copy script prints for the given files
+func claudeCopyArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{".claude/CLAUDE.md", ".claude/skills/tdd/SKILL.md", ".claude/skills/tdd/ref.md",
+		".claude/commands/deploy.md", ".claude/agents/reviewer.md", ".claude/agents/notes.txt"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("failed to write header: %v", err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestCopyClaudeConfig(t *testing.T) {
+	docker := &copyFakeExecutor{
+		sourceID: "src",
+		sourceTar: claudeCopyArchive(t, map[string]string{
+			".claude/CLAUDE.md":           "# Rules",
+			".claude/skills/tdd/SKILL.md": "---\nname: tdd\n---",
+			".claude/skills/tdd/ref.md":   "ref",
+			".claude/commands/deploy.md":  "deploy",
+			".claude/agents/reviewer.md":  "review",
+			".claude/agents/notes.txt":    "not an agent",
+		}),
+		sourceJSON: `{"numStartups": 9, "mcpServers": {"github": {"command": "npx", "env": {"GITHUB_TOKEN": "ghp_secret"}}}}`,
+		targetJSON: `{"theme": "dark", "mcpServers": {"local": {"command": "node"}, "github": {"command": "old"}}}`,
+	}
+	service := &configInjectionServiceImpl{dockerClient: docker}
+
+	status, err := service.CopyClaudeConfig(context.Background(), "src", "dst")
+	if err != nil {
+		t.Fatalf("CopyClaudeConfig failed: %v", err)
+	}
+	want := []string{"CLAUDE.md", "skill:tdd", "command:deploy", "agent:reviewer", "mcp:github"}
+	if !reflect.DeepEqual(status.Successful, want) {
+		t.Errorf("Successful = %v, want %v", status.Successful, want)
+	}
+	if len(status.Failed) != 0 || status.ContainerID != "dst" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if !docker.extracted {
+		t.Error("Expected the archive to be extracted in the target")
+	}
+
+	// The uploaded archive is the validated plain tar of the source files
+	uploaded, err := base64.StdEncoding.DecodeString(docker.targetUpload.String())
+	if err != nil {
+		t.Fatalf("uploaded archive is not base64: %v", err)
+	}
+	names := []string{}
+	tr := tar.NewReader(bytes.NewReader(uploaded))
+	for header, err := tr.Next(); err == nil; header, err = tr.Next() {
+		names = append(names, header.Name)
+	}
+	if len(names) != 6 || names[0] != ".claude/CLAUDE.md" {
+		t.Errorf("Unexpected uploaded entries: %v", names)
+	}
+
+	// MCP servers are merged unredacted, keeping the target's other settings and servers
+	var target map[string]interface{}
+	if err := json.Unmarshal([]byte(docker.targetJSON), &target); err != nil {
+		t.Fatalf("target ~/.claude.json is not valid JSON: %v", err)
+	}
+	servers := target["mcpServers"].(map[string]interface{})
+	github := servers["github"].(map[string]interface{})
+	if target["theme"] != "dark" || servers["local"] == nil || github["command"] != "npx" ||
+		github["env"].(map[string]interface{})["GITHUB_TOKEN"] != "ghp_secret" {
+		t.Errorf("Unexpected target config: %s", docker.targetJSON)
+	}
+}
+
+func TestCopyClaudeConfig_EmptyAndUnsafeSources(t *testing.T) {
+	docker := &copyFakeExecutor{sourceID: "src"}
+	service := &configInjectionServiceImpl{dockerClient: docker}
+
+	status, err := service.CopyClaudeConfig(context.Background(), "src", "dst")
+	if err != nil {
+		t.Fatalf("CopyClaudeConfig failed: %v", err)
+	}
+	if len(status.Successful) != 0 || len(status.Warnings) != 1 {
+		t.Errorf("Expected a warning for an empty source, got %+v", status)
+	}
+
+	// An archive escaping ~/.claude is rejected before anything is written to the target
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: ".claude/../.bashrc", Mode: 0o644, Size: 1, Typeflag: tar.TypeReg})
+	tw.Write([]byte("x"))
+	tw.Close()
+	gz.Close()
+	docker.sourceTar = base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	status, err = service.CopyClaudeConfig(context.Background(), "src", "dst")
+	if err != nil {
+		t.Fatalf("CopyClaudeConfig failed: %v", err)
+	}
+	if len(status.Failed) != 1 || status.Failed[0].ConfigType != copiedConfigTypeFiles {
+		t.Errorf("Expected the file copy to fail, got %+v", status)
+	}
+	if docker.extracted || docker.targetUpload.Len() != 0 {
+		t.Error("Expected nothing to be written to the target")
+	}
+}
+
+func TestCopyClaudeConfig_ExtractFailure(t *testing.T) {
+	docker := &copyFakeExecutor{
+		sourceID:     "src",
+		sourceTar:    claudeCopyArchive(t, map[string]string{".claude/CLAUDE.md": "# Rules"}),
+		extractFails: true,
+	}
+	service := &configInjectionServiceImpl{dockerClient: docker}
+
+	status, err := service.CopyClaudeConfig(context.Background(), "src", "dst")
+	if err != nil {
+		t.Fatalf("CopyClaudeConfig failed: %v", err)
+	}
+	if len(status.Successful) != 0 || len(status.Failed) != 1 || status.Failed[0].ConfigType != copiedConfigTypeFiles ||
+		!strings.Contains(status.Failed[0].Reason, "Permission denied") {
+		t.Errorf("Expected the failed extraction to be reported, got %+v", status)
+	}
+}
+
+func TestCopyClaudeConfigFrom(t *testing.T) {
+	s := setupPauseTestService(t)
+	ctx := context.Background()
+	source := createPauseTestContainer(t, s, "source", models.ContainerStatusRunning)
+	target := createPauseTestContainer(t, s, "target", models.ContainerStatusRunning)
+	stopped := createPauseTestContainer(t, s, "stopped", models.ContainerStatusStopped)
+
+	docker := &copyFakeExecutor{
+		sourceID:   source.DockerID,
+		sourceTar:  claudeCopyArchive(t, map[string]string{".claude/CLAUDE.md": "# Rules"}),
+		targetJSON: "{}",
+	}
+	s.configInjectionService = &configInjectionServiceImpl{dockerClient: docker}
+
+	if _, err := s.CopyClaudeConfigFrom(ctx, target.ID, target.ID); !errors.Is(err, ErrCopyConfigsSameContainer) {
+		t.Errorf("Expected ErrCopyConfigsSameContainer, got %v", err)
+	}
+	if _, err := s.CopyClaudeConfigFrom(ctx, target.ID, stopped.ID); !errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("Expected ErrContainerNotRunning for a stopped source, got %v", err)
+	}
+	if _, err := s.CopyClaudeConfigFrom(ctx, stopped.ID, source.ID); !errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("Expected ErrContainerNotRunning for a stopped target, got %v", err)
+	}
+	if _, err := s.CopyClaudeConfigFrom(ctx, target.ID, 9999); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+
+	status, err := s.CopyClaudeConfigFrom(ctx, target.ID, source.ID)
+	if err != nil {
+		t.Fatalf("CopyClaudeConfigFrom failed: %v", err)
+	}
+	if !reflect.DeepEqual(status.Successful, []string{"CLAUDE.md"}) {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	stored, err := s.GetContainer(target.ID)
+	if err != nil {
+		t.Fatalf("GetContainer failed: %v", err)
+	}
+	if stored.InjectionStatus == nil || !reflect.DeepEqual(stored.InjectionStatus.Successful, []string{"CLAUDE.md"}) {
+		t.Errorf("Expected the target's injection status to be stored, got %+v", stored.InjectionStatus)
+	}
+}
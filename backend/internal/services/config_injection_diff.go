@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrTemplateDiffUnsupported is returned for templates that aren't written to a single file
+var ErrTemplateDiffUnsupported = errors.New("template cannot be previewed as a file diff")
+
+// Markers in the output of templateFileReadScript
+const (
+	templateFileMarkerFound   = "__CC_FILE__"
+	templateFileMarkerMissing = "__CC_MISSING__"
+)
+
+// templateFileReadScript prints a file below the config home base64-encoded on one line, or
+// the missing marker. The relative path is passed as $1 so it is never parsed by the shell.
+var templateFileReadScript = fmt.Sprintf(`f="${CC_CONFIG_HOME:-$HOME}/$1"
+if [ -f "$f" ]; then echo %s; base64 < "$f" | tr -d '\n'; echo; else echo %s; fi`,
+	templateFileMarkerFound, templateFileMarkerMissing)
+
+// diffContextLines is how many unchanged lines surround each hunk
+const diffContextLines = 3
+
+// maxDiffCells bounds the line-comparison table; larger diffs are shown as a full replacement
+const maxDiffCells = 4 << 20
+
+// TemplateDiff previews what re-injecting a template would change in a container
+type TemplateDiff struct {
+	TemplateID   uint              `json:"template_id"`
+	TemplateName string            `json:"template_name"`
+	ConfigType   models.ConfigType `json:"config_type"`
+	Path         string            `json:"path"`    // Target file, e.g. ~/.claude/CLAUDE.md
+	Exists       bool              `json:"exists"`  // Whether the file is already in the container
+	Changed      bool              `json:"changed"` // Whether injecting would modify the file
+	Diff         string            `json:"diff"`    // Unified diff from the container files to the template
+}
+
+// templateTargetPath returns the file a template is injected into, relative to the config home
+func (s *configInjectionServiceImpl) templateTargetPath(template *models.ClaudeConfigTemplate) (string, error) {
+	switch template.ConfigType {
+	case models.ConfigTypeClaudeMD:
+		return ".claude/CLAUDE.md", nil
+	case models.ConfigTypeCommand:
+		return fmt.Sprintf(".claude/commands/%s.md", template.Name), nil
+	case models.ConfigTypeCodexConf:
+		return ".codex/config.toml", nil
+	case models.ConfigTypeSkill:
+		if template.IsArchive || !s.usesSkillDirectory(template) {
+			return "", fmt.Errorf("%w: skill '%s' is installed from an archive or package", ErrTemplateDiffUnsupported, template.Name)
+		}
+		dirName, err := skillDirName(template.Name)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(".claude/skills/%s/SKILL.md", dirName), nil
+	default:
+		return "", fmt.Errorf("%w: %s templates are merged into existing files", ErrTemplateDiffUnsupported, template.ConfigType)
+	}
+}
+
+// DiffTemplate compares the file a template would overwrite with the template's content.
+// A file that doesn't exist yet is diffed as empty, so every line shows as added. A skill's
+// supporting files are diffed after its SKILL.md.
+func (s *configInjectionServiceImpl) DiffTemplate(ctx context.Context, containerID string, template *models.ClaudeConfigTemplate) (*TemplateDiff, error) {
+	relPath, err := s.templateTargetPath(template)
+	if err != nil {
+		return nil, err
+	}
+
+	current, exists, err := s.readTemplateFile(ctx, containerID, relPath)
+	if err != nil {
+		return nil, err
+	}
+	// writeFile ends the heredoc with a newline, so that is what injection would leave behind
+	diff := fileDiff(relPath, current, exists, template.Content+"\n")
+
+	if template.ConfigType == models.ConfigTypeSkill {
+		files, err := normalizeSkillFiles(template.Files)
+		if err != nil {
+			return nil, err
+		}
+		// Supporting files are written with their exact bytes
+		skillDir := path.Dir(relPath)
+		for _, p := range sortedSkillFilePaths(files) {
+			filePath := skillDir + "/" + p
+			current, fileExists, err := s.readTemplateFile(ctx, containerID, filePath)
+			if err != nil {
+				return nil, err
+			}
+			diff += fileDiff(filePath, current, fileExists, files[p])
+		}
+	}
+
+	return &TemplateDiff{
+		TemplateID:   template.ID,
+		TemplateName: template.Name,
+		ConfigType:   template.ConfigType,
+		Path:         "~/" + relPath,
+		Exists:       exists,
+		Changed:      diff != "",
+		Diff:         diff,
+	}, nil
+}
+
+// readTemplateFile reads a file below the config home of a container
+func (s *configInjectionServiceImpl) readTemplateFile(ctx context.Context, containerID, relPath string) (content string, exists bool, err error) {
+	output, err := s.dockerClient.ExecInContainer(ctx, containerID, []string{"sh", "-c", templateFileReadScript, "sh", relPath})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", relPath, err)
+	}
+	content, exists, err = parseTemplateFile(demuxExecOutput(output))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", relPath, err)
+	}
+	return content, exists, nil
+}
+
+// fileDiff renders the unified diff of one file, diffing a missing file from /dev/null
+func fileDiff(relPath, current string, exists bool, injected string) string {
+	fromName := "a/" + relPath
+	if !exists {
+		fromName = "/dev/null"
+	}
+	return unifiedDiff(fromName, "b/"+relPath, current, injected)
+}
+
+// parseTemplateFile decodes the output of templateFileReadScript
+func parseTemplateFile(output string) (content string, exists bool, err error) {
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		switch strings.TrimRight(line, "\r") {
+		case templateFileMarkerMissing:
+			return "", false, nil
+		case templateFileMarkerFound:
+			encoded := ""
+			if i+1 < len(lines) {
+				encoded = strings.TrimSpace(lines[i+1])
+			}
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return "", false, fmt.Errorf("unexpected file encoding: %w", err)
+			}
+			return string(data), true, nil
+		}
+	}
+	return "", false, fmt.Errorf("unexpected output: %q", strings.TrimSpace(output))
+}
+
+// diffOp is one line of an edit script: ' ' kept, '-' removed, '+' added
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// splitLines splits text into lines that keep their newline; the last may have none
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a shortest edit script turning a into b from their longest common
+// subsequence. Common leading and trailing lines are matched first; when the rest is too
+// large to compare it is reported as removed and re-added.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	n, m := len(midA), len(midB)
+	if (n+1)*(m+1) > maxDiffCells {
+		for _, line := range midA {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range midB {
+			ops = append(ops, diffOp{'+', line})
+		}
+	} else {
+		// lcs[i*(m+1)+j] is the LCS length of midA[i:] and midB[j:]
+		lcs := make([]int32, (n+1)*(m+1))
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				switch {
+				case midA[i] == midB[j]:
+					lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+				case lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]:
+					lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j]
+				default:
+					lcs[i*(m+1)+j] = lcs[i*(m+1)+j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < n || j < m {
+			switch {
+			case i < n && j < m && midA[i] == midB[j]:
+				ops = append(ops, diffOp{' ', midA[i]})
+				i++
+				j++
+			case j == m || (i < n && lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]):
+				ops = append(ops, diffOp{'-', midA[i]})
+				i++
+			default:
+				ops = append(ops, diffOp{'+', midB[j]})
+				j++
+			}
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// unifiedDiff renders the changes from one text to another as a unified diff with
+// diffContextLines of context, or returns "" when they are equal
+func unifiedDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	// Line numbers (0-based) in each text before every op
+	oldLine := make([]int, len(ops)+1)
+	newLine := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if op.kind != '+' {
+			oldLine[i+1]++
+		}
+		if op.kind != '-' {
+			newLine[i+1]++
+		}
+	}
+
+	for start := 0; start < len(ops); {
+		// Find the next change and extend the hunk while changes are close enough to share context
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for next := first + 1; next < len(ops); next++ {
+			if ops[next].kind == ' ' {
+				continue
+			}
+			if next-last-1 > 2*diffContextLines {
+				break
+			}
+			last = next
+		}
+
+		hunkStart := max(first-diffContextLines, start)
+		end := min(last+1+diffContextLines, len(ops))
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(oldLine[hunkStart], oldLine[end]-oldLine[hunkStart]),
+			hunkRange(newLine[hunkStart], newLine[end]-newLine[hunkStart]))
+		for _, op := range ops[hunkStart:end] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = end
+	}
+	return sb.String()
+}
+
+// hunkRange formats a hunk's line range: 1-based start, with the count left out when it is 1
+// and an empty range numbered after the line it follows
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// DiffTemplate previews what re-injecting the latest content of a template into a running
+// container would change
+func (s *ContainerService) DiffTemplate(ctx context.Context, id, templateID uint) (*TemplateDiff, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	switch container.Status {
+	case models.ContainerStatusRunning:
+	case models.ContainerStatusPaused:
+		return nil, ErrContainerPaused
+	default:
+		return nil, ErrContainerNotRunning
+	}
+
+	var template models.ClaudeConfigTemplate
+	if err := s.db.First(&template, templateID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+
+	if s.configInjectionService == nil {
+		return nil, fmt.Errorf("config injection service not available")
+	}
+	return s.configInjectionService.DiffTemplate(ctx, container.DockerID, &template)
+}
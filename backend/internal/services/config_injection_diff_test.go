@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{"no change", "a\nb\n", "a\nb\n", ""},
+		{"new file", "", "a\nb\n", "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"removed everything", "a\n", "", "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n"},
+		{
+			"conflicting edit",
+			"# Rules\nuse tabs\nlocal note\nend\n",
+			"# Rules\nuse spaces\nend\n",
+			"--- old\n+++ new\n@@ -1,4 +1,3 @@\n # Rules\n-use tabs\n-local note\n+use spaces\n end\n",
+		},
+		{
+			"missing final newline",
+			"a\nb",
+			"a\nb\n",
+			"--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			"--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		{
+			"shared context",
+			"1\n2\n3\n4\n5\n6\n7\n8\n",
+			"one\n2\n3\n4\n5\n6\n7\neight\n",
+			"--- old\n+++ new\n@@ -1,8 +1,8 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("old", "new", tt.from, tt.to); got != tt.want {
+				t.Errorf("Unexpected diff:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLines_LargeInputFallsBackToReplacement(t *testing.T) {
+	a := make([]string, 3000)
+	b := make([]string, 3000)
+	for i := range a {
+		a[i] = "old\n"
+		b[i] = "new\n"
+	}
+	ops := diffLines(append([]string{"same\n"}, a...), append([]string{"same\n"}, b...))
+	if len(ops) != 6001 || ops[0].kind != ' ' || ops[1].kind != '-' || ops[3001].kind != '+' {
+		t.Errorf("Expected the kept line followed by a full replacement, got %d ops", len(ops))
+	}
+}
+
+func TestDiffTemplate(t *testing.T) {
+	docker := newMockDockerClient()
+	service := &configInjectionServiceImpl{dockerClient: docker, templateService: newMockConfigTemplateServiceForInjection()}
+	ctx := context.Background()
+	readCmd := func(relPath string) []string {
+		return []string{"sh", "-c", templateFileReadScript, "sh", relPath}
+	}
+	found := func(content string) string {
+		return templateFileMarkerFound + "\n" + base64.StdEncoding.EncodeToString([]byte(content)) + "\n"
+	}
+
+	claudeMD := &models.ClaudeConfigTemplate{Name: "rules", ConfigType: models.ConfigTypeClaudeMD, Content: "# Rules\nuse spaces"}
+	docker.setExecResult("abc", readCmd(".claude/CLAUDE.md"), found("# Rules\nuse spaces\n"), nil)
+	diff, err := service.DiffTemplate(ctx, "abc", claudeMD)
+	if err != nil {
+		t.Fatalf("DiffTemplate failed: %v", err)
+	}
+	if !diff.Exists || diff.Changed || diff.Diff != "" || diff.Path != "~/.claude/CLAUDE.md" {
+		t.Errorf("Expected an unchanged CLAUDE.md, got %+v", diff)
+	}
+
+	docker.setExecResult("abc", readCmd(".claude/CLAUDE.md"), found("# Rules\nuse tabs\n"), nil)
+	diff, _ = service.DiffTemplate(ctx, "abc", claudeMD)
+	if !diff.Changed || !strings.Contains(diff.Diff, "--- a/.claude/CLAUDE.md\n") ||
+		!strings.Contains(diff.Diff, "\n-use tabs\n+use spaces\n") {
+		t.Errorf("Expected the local edit to be replaced, got %+v", diff)
+	}
+
+	skill := &models.ClaudeConfigTemplate{Name: "code review", ConfigType: models.ConfigTypeSkill, Content: "---\nname: review\n---\nReview"}
+	docker.setExecResult("abc", readCmd(".claude/skills/code-review/SKILL.md"), templateFileMarkerMissing+"\n", nil)
+	diff, err = service.DiffTemplate(ctx, "abc", skill)
+	if err != nil {
+		t.Fatalf("DiffTemplate failed: %v", err)
+	}
+	if diff.Exists || !diff.Changed || !strings.HasPrefix(diff.Diff, "--- /dev/null\n+++ b/.claude/skills/code-review/SKILL.md\n@@ -0,0 +1,4 @@\n+---\n") {
+		t.Errorf("Expected an all-add diff for a missing skill, got %+v", diff)
+	}
+
+	// Supporting files are compared too, so an edit there isn't hidden by an unchanged SKILL.md
+	skill.Files = models.SkillFiles{"scripts/run.sh": "#!/bin/sh\necho v2\n", "README.md": "notes\n"}
+	docker.setExecResult("abc", readCmd(".claude/skills/code-review/SKILL.md"), found(skill.Content+"\n"), nil)
+	docker.setExecResult("abc", readCmd(".claude/skills/code-review/README.md"), found("notes\n"), nil)
+	docker.setExecResult("abc", readCmd(".claude/skills/code-review/scripts/run.sh"), found("#!/bin/sh\necho v1\n"), nil)
+	diff, err = service.DiffTemplate(ctx, "abc", skill)
+	if err != nil {
+		t.Fatalf("DiffTemplate failed: %v", err)
+	}
+	if !diff.Exists || !diff.Changed || !strings.HasPrefix(diff.Diff, "--- a/.claude/skills/code-review/scripts/run.sh\n") ||
+		!strings.Contains(diff.Diff, "\n-echo v1\n+echo v2\n") || strings.Contains(diff.Diff, "README.md") {
+		t.Errorf("Expected only the edited supporting file in the diff, got %+v", diff)
+	}
+
+	mcp := &models.ClaudeConfigTemplate{Name: "github", ConfigType: models.ConfigTypeMCP, Content: `{"command": "npx"}`}
+	if _, err := service.DiffTemplate(ctx, "abc", mcp); !errors.Is(err, ErrTemplateDiffUnsupported) {
+		t.Errorf("Expected ErrTemplateDiffUnsupported for MCP, got %v", err)
+	}
+	archive := &models.ClaudeConfigTemplate{Name: "zipped", ConfigType: models.ConfigTypeSkill, IsArchive: true, ArchiveData: "UEsD"}
+	if _, err := service.DiffTemplate(ctx, "abc", archive); !errors.Is(err, ErrTemplateDiffUnsupported) {
+		t.Errorf("Expected ErrTemplateDiffUnsupported for an archive skill, got %v", err)
+	}
+}
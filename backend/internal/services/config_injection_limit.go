@@ -0,0 +1,30 @@
+package services
+
+import "context"
+
+// limitedExecutor caps how many exec calls run at once. One instance is shared by every
+// injection, so concurrent initializations and template propagations can't flood the Docker
+// daemon. Templates of one injection are still processed one after another, in order.
+type limitedExecutor struct {
+	inner containerExecutor
+	slots chan struct{}
+}
+
+// newLimitedExecutor wraps an executor with a concurrency cap; limit <= 0 leaves it unlimited
+func newLimitedExecutor(inner containerExecutor, limit int) containerExecutor {
+	if limit <= 0 {
+		return inner
+	}
+	return &limitedExecutor{inner: inner, slots: make(chan struct{}, limit)}
+}
+
+// ExecInContainer waits for a free slot, giving up when the context ends first
+func (e *limitedExecutor) ExecInContainer(ctx context.Context, containerID string, cmd []string) (string, error) {
+	select {
+	case e.slots <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-e.slots }()
+	return e.inner.ExecInContainer(ctx, containerID, cmd)
+}
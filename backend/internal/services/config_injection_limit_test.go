@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+// inFlightExecutor records the highest number of exec calls running at the same time
+type inFlightExecutor struct {
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+func (e *inFlightExecutor) ExecInContainer(ctx context.Context, containerID string, cmd []string) (string, error) {
+	e.mu.Lock()
+	e.current++
+	if e.current > e.max {
+		e.max = e.current
+	}
+	e.mu.Unlock()
+
+	time.Sleep(2 * time.Millisecond)
+
+	e.mu.Lock()
+	e.current--
+	e.mu.Unlock()
+	return "", nil
+}
+
+func TestInjectConfigs_ExecConcurrencyCap(t *testing.T) {
+	const limit = 2
+	templateService := newMockConfigTemplateServiceForInjection()
+	ids := []uint{}
+	names := []string{}
+	for i := 1; i <= 4; i++ {
+		template := &models.ClaudeConfigTemplate{Name: fmt.Sprintf("cmd-%d", i), ConfigType: models.ConfigTypeCommand, Content: "# Command"}
+		template.ID = uint(i)
+		templateService.addTemplate(template)
+		ids = append(ids, template.ID)
+		names = append(names, template.Name)
+	}
+	executor := &inFlightExecutor{}
+	service := &configInjectionServiceImpl{dockerClient: newLimitedExecutor(executor, limit), templateService: templateService}
+
+	var wg sync.WaitGroup
+	statuses := make([]*models.InjectionStatus, 6)
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			status, err := service.InjectConfigs(context.Background(), fmt.Sprintf("container-%d", i), "/app", ids)
+			if err != nil {
+				t.Errorf("InjectConfigs failed: %v", err)
+			}
+			statuses[i] = status
+		}(i)
+	}
+	wg.Wait()
+
+	if executor.max > limit {
+		t.Errorf("Expected at most %d exec calls at once, saw %d", limit, executor.max)
+	}
+	if executor.max < limit {
+		t.Errorf("Expected the cap to be reached with %d concurrent injections, saw %d", len(statuses), executor.max)
+	}
+	for i, status := range statuses {
+		if status == nil || !reflect.DeepEqual(status.Successful, names) || len(status.Failed) != 0 {
+			t.Errorf("Container %d: unexpected status %+v", i, status)
+		}
+	}
+}
+
+func TestLimitedExecutor_ContextCancelledWhileWaiting(t *testing.T) {
+	executor := newLimitedExecutor(&inFlightExecutor{}, 1).(*limitedExecutor)
+	executor.slots <- struct{}{} // occupy the only slot
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := executor.ExecInContainer(ctx, "c", []string{"true"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	inner := &inFlightExecutor{}
+	if newLimitedExecutor(inner, 0) != containerExecutor(inner) {
+		t.Error("Expected a non-positive limit to leave the executor unwrapped")
+	}
+}
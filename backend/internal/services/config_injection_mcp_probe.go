@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+// ErrMCPServerNotFound is returned when a container has no MCP server with the requested name
+var ErrMCPServerNotFound = errors.New("MCP server not found")
+
+// Bounds of an MCP server test, in seconds
+const (
+	DefaultMCPTestTimeout = 15
+	MaxMCPTestTimeout     = 60
+)
+
+// mcpTestStderrLimit caps the stderr returned from a failed test
+const mcpTestStderrLimit = 8192
+
+// MCP transports as reported by a server test
+const (
+	MCPTransportStdio = "stdio"
+	MCPTransportSSE   = "sse"
+	MCPTransportHTTP  = "http"
+)
+
+// MCPServerTestResult reports whether an MCP server started or responded inside the container
+type MCPServerTestResult struct {
+	Name       string `json:"name"`
+	Scope      string `json:"scope"`     // MCPScopeUser or MCPScopeProject
+	Transport  string `json:"transport"` // stdio, sse or http
+	OK         bool   `json:"ok"`
+	Message    string `json:"message"`
+	ExitCode   *int   `json:"exit_code,omitempty"`   // stdio server that exited during the test
+	HTTPStatus int    `json:"http_status,omitempty"` // sse and http servers
+	Stderr     string `json:"stderr,omitempty"`      // Tail of stderr when the test failed
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Markers in the output of the MCP test scripts
+const (
+	mcpTestMarkerState  = "__CC_MCP_STATE__"
+	mcpTestMarkerStderr = "__CC_MCP_STDERR__"
+)
+
+// mcpInitializeRequest is the JSON-RPC request a stdio server must answer
+const mcpInitializeRequest = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"cc-platform-test","version":"1.0"}}}`
+
+// mcpStdioTestScript starts a stdio server ($3...) in its own process group from directory $2,
+// sends it an initialize request and waits up to $1 seconds for a result, an exit or the timeout.
+// The server and its children are killed afterwards either way.
+var mcpStdioTestScript = fmt.Sprintf(`t="$1"; dir="$2"; shift 2
+[ -n "$dir" ] && cd "$dir" 2>/dev/null
+trap '' PIPE
+d=$(mktemp -d) || exit 1
+mkfifo "$d/in"
+run='d="$1"; shift; "$@" <"$d/in" >"$d/out" 2>"$d/err"; echo $? >"$d/rc"'
+if command -v setsid >/dev/null 2>&1; then setsid sh -c "$run" sh "$d" "$@" & else sh -c "$run" sh "$d" "$@" & fi
+p=$!
+exec 3>"$d/in"
+printf '%%s\n' '%s' >&3
+state=timeout; i=0
+while [ $i -lt $((t * 5)) ]; do
+  if grep -q '"result"' "$d/out" 2>/dev/null; then state=ok; break; fi
+  if [ -s "$d/rc" ]; then state="exited $(cat "$d/rc")"; break; fi
+  sleep 0.2; i=$((i + 1))
+done
+exec 3>&-
+kill -TERM -$p 2>/dev/null || kill -TERM $p 2>/dev/null
+sleep 0.2
+kill -KILL -$p 2>/dev/null || kill -KILL $p 2>/dev/null
+echo %s; echo "$state"
+echo %s; tail -c %d "$d/err" | base64 | tr -d '\n'; echo
+rm -rf "$d"
+true`, mcpInitializeRequest, mcpTestMarkerState, mcpTestMarkerStderr, mcpTestStderrLimit)
+
+// mcpURLTestScript probes an sse or http server at $3 with curl, bounded by $1 seconds.
+// $2 is the method; sse servers are opened with GET and http servers sent an initialize POST.
+// The remaining arguments are passed to curl.
+var mcpURLTestScript = fmt.Sprintf(`t="$1"; method="$2"; url="$3"; shift 3
+if ! command -v curl >/dev/null 2>&1; then echo %s; echo "nocurl"; exit 0; fi
+err=$(mktemp)
+if [ "$method" = POST ]; then
+  code=$(curl -sS -N -o /dev/null -w '%%{http_code}' --max-time "$t" -X POST -H 'Content-Type: application/json' -H 'Accept: application/json, text/event-stream' --data '%s' "$@" "$url" 2>"$err")
+else
+  code=$(curl -sS -N -o /dev/null -w '%%{http_code}' --max-time "$t" -H 'Accept: text/event-stream' "$@" "$url" 2>"$err")
+fi
+rc=$?
+echo %s; echo "curl $rc $code"
+echo %s; tail -c %d "$err" | base64 | tr -d '\n'; echo
+rm -f "$err"
+true`, mcpTestMarkerState, mcpInitializeRequest, mcpTestMarkerState, mcpTestMarkerStderr, mcpTestStderrLimit)
+
+// TestMCPServer launches or probes an MCP server configured in the container. User servers
+// in ~/.claude.json are looked up first, then project servers in <workDir>/.mcp.json.
+func (s *configInjectionServiceImpl) TestMCPServer(ctx context.Context, containerID, workDir, name string, timeout time.Duration) (*MCPServerTestResult, error) {
+	server, scope := s.findMCPServer(ctx, containerID, workDir, name)
+	if server == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMCPServerNotFound, name)
+	}
+	seconds := int(timeout / time.Second)
+	if seconds < 1 {
+		seconds = DefaultMCPTestTimeout
+	}
+
+	result := &MCPServerTestResult{Name: name, Scope: scope, Transport: mcpServerTransport(server)}
+	var cmd []string
+	if result.Transport == MCPTransportStdio {
+		command, _ := server["command"].(string)
+		if strings.TrimSpace(command) == "" {
+			result.Message = "MCP server has neither a command nor a URL"
+			return result, nil
+		}
+		cmd = []string{"sh", "-c", mcpStdioTestScript, "sh", strconv.Itoa(seconds), workDir}
+		if env := mcpStringMap(server["env"]); len(env) > 0 {
+			cmd = append(cmd, "env")
+			for _, key := range sortedKeys(env) {
+				cmd = append(cmd, key+"="+env[key])
+			}
+		}
+		cmd = append(cmd, command)
+		cmd = append(cmd, mcpStringList(server["args"])...)
+	} else {
+		method := "POST"
+		if result.Transport == MCPTransportSSE {
+			method = "GET"
+		}
+		url, _ := server["url"].(string)
+		cmd = []string{"sh", "-c", mcpURLTestScript, "sh", strconv.Itoa(seconds), method, url}
+		headers := mcpStringMap(server["headers"])
+		for _, key := range sortedKeys(headers) {
+			cmd = append(cmd, "-H", key+": "+headers[key])
+		}
+	}
+
+	// The script enforces the timeout itself; the deadline only guards against a hung exec
+	execCtx, cancel := context.WithTimeout(ctx, time.Duration(seconds+10)*time.Second)
+	defer cancel()
+	started := time.Now()
+	output, err := s.dockerClient.ExecInContainer(execCtx, containerID, cmd)
+	result.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run MCP server test: %w", err)
+	}
+
+	state, stderr := parseMCPTestOutput(demuxExecOutput(output))
+	if result.Transport == MCPTransportStdio {
+		interpretStdioTest(result, state, seconds)
+	} else {
+		interpretURLTest(result, state)
+	}
+	if !result.OK {
+		result.Stderr = stderr
+	}
+	return result, nil
+}
+
+// findMCPServer returns the unredacted config of a named MCP server and its scope
+func (s *configInjectionServiceImpl) findMCPServer(ctx context.Context, containerID, workDir, name string) (map[string]interface{}, string) {
+	paths := []struct{ scope, path string }{{MCPScopeUser, s.configHomePath(".claude.json")}}
+	if workDir != "" {
+		paths = append(paths, struct{ scope, path string }{MCPScopeProject, shellQuote(projectMCPPath(workDir))})
+	}
+	for _, p := range paths {
+		servers, _ := s.readJSONFile(ctx, containerID, p.path)["mcpServers"].(map[string]interface{})
+		if server, ok := servers[name].(map[string]interface{}); ok {
+			return server, p.scope
+		}
+	}
+	return nil, ""
+}
+
+// mcpServerTransport returns how a server is reached: by URL (sse or http) or over stdio
+func mcpServerTransport(server map[string]interface{}) string {
+	url, _ := server["url"].(string)
+	if url == "" {
+		return MCPTransportStdio
+	}
+	kind, _ := server["type"].(string)
+	if kind == "" {
+		kind, _ = server["transport"].(string)
+	}
+	if strings.EqualFold(kind, MCPTransportSSE) {
+		return MCPTransportSSE
+	}
+	return MCPTransportHTTP
+}
+
+// parseMCPTestOutput reads the state line and the decoded stderr from a test script's output
+func parseMCPTestOutput(output string) (state, stderr string) {
+	section := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch line {
+		case mcpTestMarkerState, mcpTestMarkerStderr:
+			section = line
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		switch section {
+		case mcpTestMarkerState:
+			if state == "" {
+				state = line
+			}
+		case mcpTestMarkerStderr:
+			if data, err := base64.StdEncoding.DecodeString(line); err == nil {
+				stderr = string(data)
+			}
+		}
+	}
+	return state, stderr
+}
+
+// interpretStdioTest sets the outcome of a stdio launch from the script state
+func interpretStdioTest(result *MCPServerTestResult, state string, seconds int) {
+	switch {
+	case state == "ok":
+		result.OK = true
+		result.Message = "MCP server started and answered the initialize request"
+	case strings.HasPrefix(state, "exited "):
+		code, _ := strconv.Atoi(strings.TrimPrefix(state, "exited "))
+		result.ExitCode = &code
+		switch code {
+		case 127:
+			result.Message = "MCP server command not found"
+		case 126:
+			result.Message = "MCP server command is not executable"
+		default:
+			result.Message = fmt.Sprintf("MCP server exited with code %d before answering", code)
+		}
+	case state == "timeout":
+		result.Message = fmt.Sprintf("MCP server did not answer the initialize request within %ds", seconds)
+	default:
+		result.Message = "MCP server test did not complete"
+	}
+}
+
+// interpretURLTest sets the outcome of an sse or http probe from the script state
+func interpretURLTest(result *MCPServerTestResult, state string) {
+	if state == "nocurl" {
+		result.Message = "curl is not available in the container"
+		return
+	}
+	fields := strings.Fields(state)
+	if len(fields) < 2 || fields[0] != "curl" {
+		result.Message = "MCP server test did not complete"
+		return
+	}
+	rc, _ := strconv.Atoi(fields[1])
+	if len(fields) > 2 {
+		result.HTTPStatus, _ = strconv.Atoi(fields[2])
+	}
+
+	switch {
+	case result.HTTPStatus >= 200 && result.HTTPStatus < 300:
+		// An sse stream stays open until curl's time limit (exit 28), which still means it responded
+		result.OK = true
+		result.Message = fmt.Sprintf("MCP server responded with HTTP %d", result.HTTPStatus)
+	case result.HTTPStatus > 0:
+		result.Message = fmt.Sprintf("MCP server responded with HTTP %d", result.HTTPStatus)
+	case rc == 28:
+		result.Message = "MCP server did not respond before the timeout"
+	default:
+		result.Message = fmt.Sprintf("MCP server could not be reached (curl exit code %d)", rc)
+	}
+}
+
+// mcpStringMap reads a string map field of an MCP server config
+func mcpStringMap(value interface{}) map[string]string {
+	raw, _ := value.(map[string]interface{})
+	values := make(map[string]string, len(raw))
+	for key, v := range raw {
+		if s, ok := v.(string); ok {
+			values[key] = s
+		}
+	}
+	return values
+}
+
+// mcpStringList reads a string list field of an MCP server config
+func mcpStringList(value interface{}) []string {
+	raw, _ := value.([]interface{})
+	values := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// sortedKeys returns the keys of a string map in order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TestMCPServer tests an MCP server configured in a running container
+func (s *ContainerService) TestMCPServer(ctx context.Context, id uint, name string, timeout time.Duration) (*MCPServerTestResult, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	switch container.Status {
+	case models.ContainerStatusRunning:
+	case models.ContainerStatusPaused:
+		return nil, ErrContainerPaused
+	default:
+		return nil, ErrContainerNotRunning
+	}
+
+	if s.configInjectionService == nil {
+		return nil, fmt.Errorf("config injection service not available")
+	}
+	result, err := s.configInjectionService.TestMCPServer(ctx, container.DockerID, container.WorkDir, name, timeout)
+	if err != nil {
+		return nil, err
+	}
+	level := models.LogLevelInfo
+	if !result.OK {
+		level = models.LogLevelWarn
+	}
+	s.addLog(ctx, id, level, models.LogStageStartup, fmt.Sprintf("MCP server '%s' test: %s", name, result.Message))
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mcpTestOutput(state, stderr string) string {
+	return strings.Join([]string{
+		mcpTestMarkerState, state,
+		mcpTestMarkerStderr, base64.StdEncoding.EncodeToString([]byte(stderr)),
+	}, "\n") + "\n"
+}
+
+func setupMCPProbeTest(t *testing.T, claudeJSON, projectJSON string) (*mockDockerClient, *configInjectionServiceImpl) {
+	t.Helper()
+	docker := newMockDockerClient()
+	service := &configInjectionServiceImpl{dockerClient: docker}
+	docker.setExecResult("abc", []string{"sh", "-c", "cat ${CC_CONFIG_HOME:-$HOME}/.claude.json 2>/dev/null || true"}, claudeJSON, nil)
+	docker.setExecResult("abc", []string{"sh", "-c", "cat '/workspace/app/.mcp.json' 2>/dev/null || true"}, projectJSON, nil)
+	return docker, service
+}
+
+func TestTestMCPServer_Stdio(t *testing.T) {
+	docker, service := setupMCPProbeTest(t,
+		`{"mcpServers": {"github": {"command": "npx", "args": ["-y", "server-github"], "env": {"GITHUB_TOKEN": "ghp_1", "A": "b"}}}}`,
+		`{"mcpServers": {"broken": {"command": "missing-bin"}}}`)
+	ctx := context.Background()
+
+	githubCmd := []string{"sh", "-c", mcpStdioTestScript, "sh", "5", "/workspace/app",
+		"env", "A=b", "GITHUB_TOKEN=ghp_1", "npx", "-y", "server-github"}
+	docker.setExecResult("abc", githubCmd, mcpTestOutput("ok", "starting"), nil)
+	result, err := service.TestMCPServer(ctx, "abc", "/workspace/app", "github", 5*time.Second)
+	if err != nil {
+		t.Fatalf("TestMCPServer failed: %v", err)
+	}
+	if !result.OK || result.Transport != MCPTransportStdio || result.Scope != MCPScopeUser || result.Stderr != "" {
+		t.Errorf("Expected a passing user stdio test without stderr, got %+v", result)
+	}
+	calls := docker.getExecCalls()
+	if last := calls[len(calls)-1].cmd; !reflect.DeepEqual(last, githubCmd) {
+		t.Errorf("Unexpected launch command: %v", last)
+	}
+
+	brokenCmd := []string{"sh", "-c", mcpStdioTestScript, "sh", "15", "/workspace/app", "missing-bin"}
+	docker.setExecResult("abc", brokenCmd, mcpTestOutput("exited 127", "sh: 1: missing-bin: not found\n"), nil)
+	result, err = service.TestMCPServer(ctx, "abc", "/workspace/app", "broken", 0)
+	if err != nil {
+		t.Fatalf("TestMCPServer failed: %v", err)
+	}
+	if result.OK || result.Scope != MCPScopeProject || result.ExitCode == nil || *result.ExitCode != 127 ||
+		!strings.Contains(result.Message, "not found") || !strings.Contains(result.Stderr, "missing-bin: not found") {
+		t.Errorf("Expected a failed project stdio test with stderr, got %+v", result)
+	}
+
+	if _, err := service.TestMCPServer(ctx, "abc", "/workspace/app", "unknown", time.Second); !errors.Is(err, ErrMCPServerNotFound) {
+		t.Errorf("Expected ErrMCPServerNotFound, got %v", err)
+	}
+}
+
+func TestTestMCPServer_URLProbe(t *testing.T) {
+	docker, service := setupMCPProbeTest(t, `{"mcpServers": {
+		"events": {"type": "sse", "url": "https://mcp.example.com/sse"},
+		"remote": {"type": "http", "url": "https://mcp.example.com/mcp", "headers": {"Authorization": "Bearer xyz"}}
+	}}`, "")
+	ctx := context.Background()
+
+	// An sse stream stays open until curl's time limit
+	docker.setExecResult("abc", []string{"sh", "-c", mcpURLTestScript, "sh", "3", "GET", "https://mcp.example.com/sse"},
+		mcpTestOutput("curl 28 200", ""), nil)
+	result, err := service.TestMCPServer(ctx, "abc", "", "events", 3*time.Second)
+	if err != nil {
+		t.Fatalf("TestMCPServer failed: %v", err)
+	}
+	if !result.OK || result.Transport != MCPTransportSSE || result.HTTPStatus != 200 {
+		t.Errorf("Expected a passing sse probe, got %+v", result)
+	}
+
+	remoteCmd := []string{"sh", "-c", mcpURLTestScript, "sh", "3", "POST", "https://mcp.example.com/mcp", "-H", "Authorization: Bearer xyz"}
+	docker.setExecResult("abc", remoteCmd, mcpTestOutput("curl 0 401", ""), nil)
+	result, err = service.TestMCPServer(ctx, "abc", "", "remote", 3*time.Second)
+	if err != nil {
+		t.Fatalf("TestMCPServer failed: %v", err)
+	}
+	if result.OK || result.Transport != MCPTransportHTTP || result.HTTPStatus != 401 || !strings.Contains(result.Message, "401") {
+		t.Errorf("Expected a failed http probe with status 401, got %+v", result)
+	}
+
+	docker.setExecResult("abc", remoteCmd, mcpTestOutput("curl 6 000", "curl: (6) Could not resolve host: mcp.example.com\n"), nil)
+	result, _ = service.TestMCPServer(ctx, "abc", "", "remote", 3*time.Second)
+	if result.OK || result.HTTPStatus != 0 || !strings.Contains(result.Message, "could not be reached") || !strings.Contains(result.Stderr, "Could not resolve host") {
+		t.Errorf("Expected an unreachable http probe with stderr, got %+v", result)
+	}
+
+	docker.setExecResult("abc", remoteCmd, mcpTestOutput("nocurl", ""), nil)
+	if result, _ = service.TestMCPServer(ctx, "abc", "", "remote", 3*time.Second); result.OK || !strings.Contains(result.Message, "curl") {
+		t.Errorf("Expected a failure without curl, got %+v", result)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"cc-platform/internal/models"
+)
+
+// ContainerClaudeConfig is the Claude configuration found inside a container.
+// Missing files are reported as empty sections.
+type ContainerClaudeConfig struct {
+	ClaudeMD   string                 `json:"claude_md"`   // ~/.claude/CLAUDE.md
+	MCPServers map[string]interface{} `json:"mcp_servers"` // mcpServers from ~/.claude.json, secrets redacted
+	Settings   map[string]interface{} `json:"settings"`    // ~/.claude/settings.json, secrets redacted
+	Skills     []string               `json:"skills"`      // Directories in ~/.claude/skills
+	Commands   []string               `json:"commands"`    // Commands in ~/.claude/commands, without .md
+	Agents     []string               `json:"agents"`      // Agents in ~/.claude/agents, without .md
+	Warnings   []string               `json:"warnings,omitempty"`
+}
+
+// Section markers in the output of claudeConfigReadScript
+const (
+	claudeConfigMarkerClaudeMD   = "__CC_CLAUDE_MD__"
+	claudeConfigMarkerClaudeJSON = "__CC_CLAUDE_JSON__"
+	claudeConfigMarkerSettings   = "__CC_SETTINGS__"
+	claudeConfigMarkerSkills     = "__CC_SKILLS__"
+	claudeConfigMarkerCommands   = "__CC_COMMANDS__"
+	claudeConfigMarkerAgents     = "__CC_AGENTS__"
+)
+
+// claudeConfigReadScript prints every config file base64-encoded on one line (so file
+// content can't be mistaken for a marker) and lists skills, commands and agents.
+var claudeConfigReadScript = fmt.Sprintf(`set -f
+h="${CC_CONFIG_HOME:-$HOME}"
+f() { [ -f "$1" ] && base64 < "$1" | tr -d '\n'; echo; }
+l() { [ -d "$1" ] && (cd "$1" && find . -mindepth 1 $2 | sed 's|^\./||'); }
+echo %s; f "$h/.claude/CLAUDE.md"
+echo %s; f "$h/.claude.json"
+echo %s; f "$h/.claude/settings.json"
+echo %s; l "$h/.claude/skills" "-maxdepth 1 -type d"
+echo %s; l "$h/.claude/commands" "-type f -name *.md"
+echo %s; l "$h/.claude/agents" "-type f -name *.md"
+true`,
+	claudeConfigMarkerClaudeMD, claudeConfigMarkerClaudeJSON, claudeConfigMarkerSettings,
+	claudeConfigMarkerSkills, claudeConfigMarkerCommands, claudeConfigMarkerAgents)
+
+// secretValuePattern matches values that look like credentials whatever their key is called
+var secretValuePattern = regexp.MustCompile(`^(sk-|ghp_|gho_|ghs_|github_pat_|glpat-|xox[abpr]-|AKIA|Bearer\s)`)
+
+// ReadClaudeConfig reads the Claude configuration files from a container in one exec
+func (s *configInjectionServiceImpl) ReadClaudeConfig(ctx context.Context, containerID string) (*ContainerClaudeConfig, error) {
+	output, err := s.dockerClient.ExecInContainer(ctx, containerID, []string{"sh", "-c", claudeConfigReadScript})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Claude config: %w", err)
+	}
+	return parseClaudeConfig(demuxExecOutput(output)), nil
+}
+
+// parseClaudeConfig assembles the config from the read script output
+func parseClaudeConfig(output string) *ContainerClaudeConfig {
+	config := &ContainerClaudeConfig{
+		MCPServers: map[string]interface{}{},
+		Settings:   map[string]interface{}{},
+		Skills:     []string{},
+		Commands:   []string{},
+		Agents:     []string{},
+	}
+
+	sections := make(map[string][]string)
+	section := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch line {
+		case claudeConfigMarkerClaudeMD, claudeConfigMarkerClaudeJSON, claudeConfigMarkerSettings,
+			claudeConfigMarkerSkills, claudeConfigMarkerCommands, claudeConfigMarkerAgents:
+			section = line
+			continue
+		}
+		if section != "" && strings.TrimSpace(line) != "" {
+			sections[section] = append(sections[section], line)
+		}
+	}
+
+	decode := func(marker, name string) string {
+		lines := sections[marker]
+		if len(lines) == 0 {
+			return ""
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[0]))
+		if err != nil {
+			config.Warnings = append(config.Warnings, fmt.Sprintf("%s could not be read", name))
+			return ""
+		}
+		return string(data)
+	}
+	decodeJSON := func(marker, name string) map[string]interface{} {
+		content := strings.TrimSpace(decode(marker, name))
+		if content == "" {
+			return nil
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(content), &obj); err != nil {
+			config.Warnings = append(config.Warnings, fmt.Sprintf("%s is not valid JSON", name))
+			return nil
+		}
+		return obj
+	}
+
+	config.ClaudeMD = decode(claudeConfigMarkerClaudeMD, "~/.claude/CLAUDE.md")
+
+	if claudeJSON := decodeJSON(claudeConfigMarkerClaudeJSON, "~/.claude.json"); claudeJSON != nil {
+		if servers, ok := claudeJSON["mcpServers"].(map[string]interface{}); ok {
+			for name, server := range servers {
+				config.MCPServers[name] = redactMCPServer(server)
+			}
+		}
+	}
+
+	if settings := decodeJSON(claudeConfigMarkerSettings, "~/.claude/settings.json"); settings != nil {
+		if env, ok := settings["env"].(map[string]interface{}); ok {
+			settings["env"] = redactSecretMap(env)
+		}
+		config.Settings = settings
+	}
+
+	config.Skills = sortedNames(sections[claudeConfigMarkerSkills], "")
+	config.Commands = sortedNames(sections[claudeConfigMarkerCommands], ".md")
+	config.Agents = sortedNames(sections[claudeConfigMarkerAgents], ".md")
+	return config
+}
+
+// redactMCPServer hides env and header values of an MCP server whose names look sensitive
+func redactMCPServer(server interface{}) interface{} {
+	cfg, ok := server.(map[string]interface{})
+	if !ok {
+		return server
+	}
+	for _, field := range []string{"env", "headers"} {
+		if values, ok := cfg[field].(map[string]interface{}); ok {
+			cfg[field] = redactSecretMap(values)
+		}
+	}
+	return cfg
+}
+
+// redactSecretMap replaces values whose key or content looks sensitive
+func redactSecretMap(values map[string]interface{}) map[string]interface{} {
+	for key, value := range values {
+		s, isString := value.(string)
+		if isString && s == "" {
+			continue
+		}
+		if sensitiveKeyPattern.MatchString(key) || (isString && secretValuePattern.MatchString(s)) {
+			values[key] = redactedValue
+		}
+	}
+	return values
+}
+
+// sortedNames trims a suffix from each listed path and sorts the result
+func sortedNames(lines []string, suffix string) []string {
+	names := make([]string, 0, len(lines))
+	for _, line := range lines {
+		names = append(names, strings.TrimSuffix(strings.TrimSpace(line), suffix))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetClaudeConfig returns the effective Claude configuration inside a running container
+func (s *ContainerService) GetClaudeConfig(ctx context.Context, id uint) (*ContainerClaudeConfig, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	switch container.Status {
+	case models.ContainerStatusRunning:
+	case models.ContainerStatusPaused:
+		return nil, ErrContainerPaused
+	default:
+		return nil, ErrContainerNotRunning
+	}
+
+	if s.configInjectionService == nil {
+		return nil, fmt.Errorf("config injection service not available")
+	}
+	return s.configInjectionService.ReadClaudeConfig(ctx, container.DockerID)
+}
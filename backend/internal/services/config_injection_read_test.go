@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func claudeConfigOutput(claudeMD, claudeJSON, settings string, skills, commands, agents []string) string {
+	enc := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	lines := []string{
+		claudeConfigMarkerClaudeMD, enc(claudeMD),
+		claudeConfigMarkerClaudeJSON, enc(claudeJSON),
+		claudeConfigMarkerSettings, enc(settings),
+		claudeConfigMarkerSkills,
+	}
+	lines = append(lines, skills...)
+	lines = append(lines, claudeConfigMarkerCommands)
+	lines = append(lines, commands...)
+	lines = append(lines, claudeConfigMarkerAgents)
+	lines = append(lines, agents...)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestParseClaudeConfig(t *testing.T) {
+	claudeJSON := `{
+		"numStartups": 3,
+		"mcpServers": {
+			"github": {"command": "npx", "args": ["-y", "server-github"], "env": {"GITHUB_TOKEN": "abc", "LOG_LEVEL": "debug", "UPSTREAM": "ghp_123"}},
+			"remote": {"url": "https://mcp.example.com", "headers": {"Authorization": "Bearer xyz", "X-Team": "core"}}
+		}
+	}`
+	settings := `{"permissions": {"allow": ["Bash(npm test)"]}, "env": {"ANTHROPIC_API_KEY": "sk-ant-1", "EDITOR": "vim"}}`
+
+	config := parseClaudeConfig(claudeConfigOutput(
+		"# Project rules\n\n__CC_SKILLS__ is not a marker here\n",
+		claudeJSON, settings,
+		[]string{"tdd", "code-review"},
+		[]string{"deploy.md", "git/commit.md"},
+		[]string{"reviewer.md"},
+	))
+
+	if config.ClaudeMD != "# Project rules\n\n__CC_SKILLS__ is not a marker here\n" {
+		t.Errorf("Unexpected CLAUDE.md: %q", config.ClaudeMD)
+	}
+
+	github := config.MCPServers["github"].(map[string]interface{})
+	env := github["env"].(map[string]interface{})
+	if env["GITHUB_TOKEN"] != redactedValue || env["UPSTREAM"] != redactedValue || env["LOG_LEVEL"] != "debug" {
+		t.Errorf("Expected secret env values to be redacted, got %v", env)
+	}
+	headers := config.MCPServers["remote"].(map[string]interface{})["headers"].(map[string]interface{})
+	if headers["Authorization"] != redactedValue || headers["X-Team"] != "core" {
+		t.Errorf("Expected the auth header to be redacted, got %v", headers)
+	}
+
+	settingsEnv := config.Settings["env"].(map[string]interface{})
+	if settingsEnv["ANTHROPIC_API_KEY"] != redactedValue || settingsEnv["EDITOR"] != "vim" {
+		t.Errorf("Expected settings env secrets to be redacted, got %v", settingsEnv)
+	}
+	if _, ok := config.Settings["permissions"]; !ok {
+		t.Error("Expected settings to be returned")
+	}
+
+	if !reflect.DeepEqual(config.Skills, []string{"code-review", "tdd"}) {
+		t.Errorf("Unexpected skills: %v", config.Skills)
+	}
+	if !reflect.DeepEqual(config.Commands, []string{"deploy", "git/commit"}) {
+		t.Errorf("Unexpected commands: %v", config.Commands)
+	}
+	if !reflect.DeepEqual(config.Agents, []string{"reviewer"}) {
+		t.Errorf("Unexpected agents: %v", config.Agents)
+	}
+	if len(config.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", config.Warnings)
+	}
+}
+
+func TestParseClaudeConfig_MissingAndInvalidFiles(t *testing.T) {
+	config := parseClaudeConfig(claudeConfigOutput("", "", "{not json", nil, nil, nil))
+
+	if config.ClaudeMD != "" || len(config.MCPServers) != 0 || len(config.Settings) != 0 {
+		t.Errorf("Expected empty sections, got %+v", config)
+	}
+	if config.Skills == nil || config.Commands == nil || config.Agents == nil {
+		t.Error("Expected empty lists rather than nil")
+	}
+	if len(config.Warnings) != 1 || !strings.Contains(config.Warnings[0], "settings.json") {
+		t.Errorf("Expected a warning for invalid settings.json, got %v", config.Warnings)
+	}
+}
+
+func TestReadClaudeConfig(t *testing.T) {
+	docker := newMockDockerClient()
+	service := &configInjectionServiceImpl{dockerClient: docker}
+	cmd := []string{"sh", "-c", claudeConfigReadScript}
+
+	docker.setExecResult("abc", cmd, claudeConfigOutput("rules", "", "", []string{"tdd"}, nil, nil), nil)
+	config, err := service.ReadClaudeConfig(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("ReadClaudeConfig failed: %v", err)
+	}
+	if config.ClaudeMD != "rules" || !reflect.DeepEqual(config.Skills, []string{"tdd"}) {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	docker.setExecResult("abc", cmd, "", errors.New("exec failed"))
+	if _, err := service.ReadClaudeConfig(context.Background(), "abc"); err == nil {
+		t.Error("Expected exec errors to be returned")
+	}
+}
@@ -1,16 +1,19 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
 	"cc-platform/internal/docker"
 	"cc-platform/internal/models"
 
+	"github.com/docker/docker/pkg/stdcopy"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -22,29 +25,61 @@ type MCPServerConfig struct {
 	Env       map[string]string `json:"env,omitempty"`
 	Transport string            `json:"transport,omitempty"`
 	URL       string            `json:"url,omitempty"`
+	Scope     string            `json:"scope,omitempty"` // MCPScopeUser (default) or MCPScopeProject
 }
 
+// MCP server scopes: user servers go to ~/.claude.json, project servers to .mcp.json in the work directory
+const (
+	MCPScopeUser    = "user"
+	MCPScopeProject = "project"
+)
+
 // ConfigInjectionService defines the interface for injecting configurations into containers
 type ConfigInjectionService interface {
-	// InjectConfigs injects configurations into container and returns injection status
-	InjectConfigs(ctx context.Context, containerID string, templateIDs []uint) (*models.InjectionStatus, error)
+	// InjectConfigs injects configurations into container and returns injection status.
+	// workDir is the project directory that receives project-scoped MCP servers.
+	InjectConfigs(ctx context.Context, containerID string, workDir string, templateIDs []uint) (*models.InjectionStatus, error)
 
 	// Individual injection methods
 	InjectClaudeMD(ctx context.Context, containerID string, content string) error
-	InjectSkill(ctx context.Context, containerID string, name string, content string) error
+	InjectSkill(ctx context.Context, containerID string, name string, content string, files models.SkillFiles) error
 	InjectSkillArchive(ctx context.Context, containerID string, name string, archiveData string) error
-	InjectMCP(ctx context.Context, containerID string, configs []MCPServerConfig) error
+	InjectMCP(ctx context.Context, containerID string, workDir string, configs []MCPServerConfig) error
 	InjectCommand(ctx context.Context, containerID string, name string, content string) error
 	InjectCodexConfig(ctx context.Context, containerID string, content string) error
 	InjectCodexAuth(ctx context.Context, containerID string, content string) error
 	InjectGeminiEnv(ctx context.Context, containerID string, content string) error
 	InjectSerenaMCP(ctx context.Context, containerID string) error
+
+	// ReadClaudeConfig reads back the Claude configuration present in the container
+	ReadClaudeConfig(ctx context.Context, containerID string) (*ContainerClaudeConfig, error)
+
+	// DiffTemplate previews the changes injecting a template would make to the file it writes
+	DiffTemplate(ctx context.Context, containerID string, template *models.ClaudeConfigTemplate) (*TemplateDiff, error)
+
+	// CopyClaudeConfig copies the live Claude configuration of one container into another
+	CopyClaudeConfig(ctx context.Context, sourceID, targetID string) (*models.InjectionStatus, error)
+
+	// ApplyEnvProfile sets env vars in ~/.claude/settings.json, removing the listed names first,
+	// and terminates running Claude processes so new sessions pick the values up
+	ApplyEnvProfile(ctx context.Context, containerID string, env map[string]string, remove []string) error
+
+	// TestMCPServer launches (stdio) or probes (sse/http) a configured MCP server inside the
+	// container, giving up after timeout
+	TestMCPServer(ctx context.Context, containerID, workDir, name string, timeout time.Duration) (*MCPServerTestResult, error)
+}
+
+// containerExecutor is the subset of the docker client used for injection.
+// *docker.Client satisfies it; tests substitute an in-memory fake.
+type containerExecutor interface {
+	ExecInContainer(ctx context.Context, containerID string, cmd []string) (string, error)
 }
 
 // configInjectionServiceImpl is the implementation of ConfigInjectionService
 type configInjectionServiceImpl struct {
-	dockerClient    *docker.Client
+	dockerClient    containerExecutor
 	templateService ConfigTemplateService
+	toolCheck       string // ToolCheck* mode for skill tools and MCP commands; empty means off
 }
 
 // NewConfigInjectionService creates a new ConfigInjectionService
@@ -56,22 +91,36 @@ func NewConfigInjectionService(dockerClient *docker.Client, templateService Conf
 }
 
 // NewConfigInjectionServiceWithNewClient creates a new ConfigInjectionService with a new docker client
-// This is useful when you don't have an existing docker client to pass
-func NewConfigInjectionServiceWithNewClient(templateService ConfigTemplateService) ConfigInjectionService {
+// This is useful when you don't have an existing docker client to pass.
+// At most maxConcurrentExecs exec calls run at once across all injections; 0 means no limit.
+// toolCheck is the ToolCheck* mode for the tools and commands templates reference.
+func NewConfigInjectionServiceWithNewClient(templateService ConfigTemplateService, maxConcurrentExecs int, toolCheck string) ConfigInjectionService {
 	dockerClient, err := docker.NewClient()
 	if err != nil {
 		log.WithError(err).Error("Failed to create docker client for ConfigInjectionService")
 		return nil
 	}
 	return &configInjectionServiceImpl{
-		dockerClient:    dockerClient,
+		dockerClient:    newLimitedExecutor(dockerClient, maxConcurrentExecs),
 		templateService: templateService,
+		toolCheck:       normalizeToolCheck(toolCheck),
 	}
 }
 
+// injectionResult records the outcome of a single template in input order.
+// MCP templates stay pending until the merged MCP config files have been written.
+type injectionResult struct {
+	templateID   uint
+	templateName string
+	configType   string
+	err          error
+	pendingMCP   bool
+}
+
 // InjectConfigs injects configurations into container and returns injection status
-// This method implements error recovery logic - single config failure doesn't affect others
-func (s *configInjectionServiceImpl) InjectConfigs(ctx context.Context, containerID string, templateIDs []uint) (*models.InjectionStatus, error) {
+// This method implements error recovery logic - single config failure doesn't affect others.
+// Successful and Failed list templates in the order they were requested, MCP entries included.
+func (s *configInjectionServiceImpl) InjectConfigs(ctx context.Context, containerID string, workDir string, templateIDs []uint) (*models.InjectionStatus, error) {
 	status := &models.InjectionStatus{
 		ContainerID: containerID,
 		Successful:  []string{},
@@ -87,55 +136,109 @@ func (s *configInjectionServiceImpl) InjectConfigs(ctx context.Context, containe
 
 	// Collect MCP configs for merging (multiple MCP templates are merged into one file)
 	var mcpConfigs []MCPServerConfig
+	// mcpOwners maps a scope and resolved MCP server name to the template that claimed it
+	mcpOwners := make(map[string]map[string]string)
+	// skillOwners maps a skill directory name to the template that claimed it
+	skillOwners := make(map[string]string)
+	results := make([]injectionResult, 0, len(templateIDs))
 
 	for _, templateID := range templateIDs {
 		// Retrieve template from database
 		template, err := s.templateService.GetByID(templateID)
 		if err != nil {
-			status.Failed = append(status.Failed, models.FailedTemplate{
-				TemplateName: fmt.Sprintf("unknown (ID: %d)", templateID),
-				ConfigType:   "UNKNOWN",
-				Reason:       fmt.Sprintf("failed to retrieve template: %v", err),
+			results = append(results, injectionResult{
+				templateName: fmt.Sprintf("unknown (ID: %d)", templateID),
+				configType:   "UNKNOWN",
+				err:          fmt.Errorf("failed to retrieve template: %v", err),
 			})
 			log.WithError(err).Warnf("Failed to retrieve template ID %d", templateID)
 			continue
 		}
 
+		result := injectionResult{templateID: templateID, templateName: template.Name, configType: string(template.ConfigType)}
+
+		// Skills whose names slugify to the same directory would overwrite each other
+		if template.ConfigType == models.ConfigTypeSkill && s.usesSkillDirectory(template) {
+			dirName := SlugifySkillName(template.Name)
+			if owner, exists := skillOwners[dirName]; exists && dirName != "" {
+				result.err = fmt.Errorf("skill directory '%s' conflicts with template '%s'", dirName, owner)
+				results = append(results, result)
+				status.Warnings = append(status.Warnings, fmt.Sprintf("Skill template '%s' skipped: directory '%s' is already used by '%s'", template.Name, dirName, owner))
+				log.Warnf("Skipping skill template %s: directory %s already used by %s", template.Name, dirName, owner)
+				continue
+			}
+			skillOwners[dirName] = template.Name
+		}
+
+		// Skills naming unknown tools and stdio MCP servers whose command is missing won't work
+		if problems := s.templateToolProblems(ctx, containerID, template); len(problems) > 0 {
+			if s.toolCheck == ToolCheckStrict {
+				result.err = fmt.Errorf("tool check failed: %s", strings.Join(problems, "; "))
+				results = append(results, result)
+				log.Warnf("Skipping %s template %s: %s", toolCheckLabel(template.ConfigType), template.Name, strings.Join(problems, "; "))
+				continue
+			}
+			for _, problem := range problems {
+				status.Warnings = append(status.Warnings, fmt.Sprintf("%s template '%s': %s", toolCheckLabel(template.ConfigType), template.Name, problem))
+			}
+		}
+
 		// Inject based on config type
 		if err := s.injectSingleConfig(ctx, containerID, template, &mcpConfigs); err != nil {
-			status.Failed = append(status.Failed, models.FailedTemplate{
-				TemplateName: template.Name,
-				ConfigType:   string(template.ConfigType),
-				Reason:       err.Error(),
-			})
+			result.err = err
+			results = append(results, result)
 			log.WithError(err).Warnf("Failed to inject config %s (type: %s)", template.Name, template.ConfigType)
 			continue
 		}
 
 		// MCP configs are collected and injected together at the end
 		if template.ConfigType != models.ConfigTypeMCP {
-			status.Successful = append(status.Successful, template.Name)
+			results = append(results, result)
+			continue
 		}
+
+		// Two templates resolving to the same server name in the same scope would
+		// silently overwrite each other in the config file, so keep the first one only
+		serverName := mcpConfigs[len(mcpConfigs)-1].Name
+		scope := mcpConfigs[len(mcpConfigs)-1].Scope
+		if mcpOwners[scope] == nil {
+			mcpOwners[scope] = make(map[string]string)
+		}
+		if owner, exists := mcpOwners[scope][serverName]; exists {
+			mcpConfigs = mcpConfigs[:len(mcpConfigs)-1]
+			result.err = fmt.Errorf("MCP server name '%s' conflicts with template '%s'", serverName, owner)
+			results = append(results, result)
+			status.Warnings = append(status.Warnings, fmt.Sprintf("MCP template '%s' skipped: server '%s' is already provided by '%s'", template.Name, serverName, owner))
+			log.Warnf("Skipping MCP template %s: server name %s already used by %s", template.Name, serverName, owner)
+			continue
+		}
+		mcpOwners[scope][serverName] = template.Name
+		result.pendingMCP = true
+		results = append(results, result)
 	}
 
 	// Inject all collected MCP configs together
+	var mcpErr error
 	if len(mcpConfigs) > 0 {
-		if err := s.InjectMCP(ctx, containerID, mcpConfigs); err != nil {
-			// Mark all MCP templates as failed
-			for _, cfg := range mcpConfigs {
-				status.Failed = append(status.Failed, models.FailedTemplate{
-					TemplateName: cfg.Name,
-					ConfigType:   string(models.ConfigTypeMCP),
-					Reason:       fmt.Sprintf("failed to inject MCP config: %v", err),
-				})
-			}
-			log.WithError(err).Warn("Failed to inject MCP configurations")
-		} else {
-			// Mark all MCP templates as successful
-			for _, cfg := range mcpConfigs {
-				status.Successful = append(status.Successful, cfg.Name)
-			}
+		if mcpErr = s.InjectMCP(ctx, containerID, workDir, mcpConfigs); mcpErr != nil {
+			log.WithError(mcpErr).Warn("Failed to inject MCP configurations")
+		}
+	}
+
+	for _, result := range results {
+		if result.pendingMCP && mcpErr != nil {
+			result.err = fmt.Errorf("failed to inject MCP config: %v", mcpErr)
+		}
+		if result.err != nil {
+			status.Failed = append(status.Failed, models.FailedTemplate{
+				TemplateName: result.templateName,
+				ConfigType:   result.configType,
+				Reason:       result.err.Error(),
+			})
+			continue
 		}
+		status.Successful = append(status.Successful, result.templateName)
+		status.SuccessfulIDs = append(status.SuccessfulIDs, result.templateID)
 	}
 
 	return status, nil
@@ -160,7 +263,7 @@ func (s *configInjectionServiceImpl) injectSingleConfig(ctx context.Context, con
 		if template.IsArchive && template.ArchiveData != "" {
 			return s.InjectSkillArchive(ctx, containerID, template.Name, template.ArchiveData)
 		}
-		return s.InjectSkill(ctx, containerID, template.Name, template.Content)
+		return s.InjectSkill(ctx, containerID, template.Name, template.Content, template.Files)
 
 	case models.ConfigTypeMCP:
 		// Parse MCP config and collect for batch injection
@@ -188,13 +291,44 @@ func (s *configInjectionServiceImpl) injectSingleConfig(ctx context.Context, con
 	}
 }
 
-// parseMCPConfig parses MCP configuration from JSON content
+// usesSkillDirectory reports whether a skill template is written to ~/.claude/skills/{name}.
+// Package-based skills are installed by the skills CLI and pick their own directories.
+func (s *configInjectionServiceImpl) usesSkillDirectory(template *models.ClaudeConfigTemplate) bool {
+	metadata, err := s.templateService.ParseSkillMetadata(template.Content)
+	return err != nil || metadata == nil || metadata.InstallSource == ""
+}
+
+// skillDirName maps a skill name to a safe directory name, rejecting names with no usable characters
+func skillDirName(name string) (string, error) {
+	dirName := SlugifySkillName(name)
+	if dirName == "" {
+		return "", fmt.Errorf("%w: '%s'", ErrInvalidSkillName, name)
+	}
+	if dirName != name {
+		log.Debugf("Skill name '%s' sanitized to '%s'", name, dirName)
+	}
+	return dirName, nil
+}
+
+// parseMCPConfig parses MCP configuration from JSON content.
+// The server name defaults to the template name unless the content sets "name".
 func (s *configInjectionServiceImpl) parseMCPConfig(name string, content string) (*MCPServerConfig, error) {
 	var config MCPServerConfig
 	if err := json.Unmarshal([]byte(content), &config); err != nil {
 		return nil, fmt.Errorf("invalid MCP JSON: %w", err)
 	}
-	config.Name = name
+	if strings.TrimSpace(config.Command) == "" && config.URL == "" {
+		return nil, fmt.Errorf("MCP command cannot be empty")
+	}
+	config.Name = strings.TrimSpace(config.Name)
+	if config.Name == "" {
+		config.Name = name
+	}
+	scope, err := normalizeMCPScope(config.Scope)
+	if err != nil {
+		return nil, err
+	}
+	config.Scope = scope
 	return &config, nil
 }
 
@@ -209,22 +343,59 @@ func (s *configInjectionServiceImpl) InjectClaudeMD(ctx context.Context, contain
 	return s.writeFile(ctx, containerID, s.configHomePath(".claude/CLAUDE.md"), content)
 }
 
-// InjectSkill injects a skill to ~/.claude/skills/{name}/SKILL.md
-func (s *configInjectionServiceImpl) InjectSkill(ctx context.Context, containerID string, name string, content string) error {
+// InjectSkill injects a skill to ~/.claude/skills/{name}/SKILL.md, with its supporting files
+// written under the same directory
+func (s *configInjectionServiceImpl) InjectSkill(ctx context.Context, containerID string, name string, content string, files models.SkillFiles) error {
+	dirName, err := skillDirName(name)
+	if err != nil {
+		return err
+	}
+	// Templates are validated when saved; check again so no path can leave the skill directory
+	files, err = normalizeSkillFiles(files)
+	if err != nil {
+		return err
+	}
+
 	// Create parent directory ~/.claude/skills/{name}/ if it doesn't exist
-	skillDir := s.configHomePath(fmt.Sprintf(".claude/skills/%s", name))
+	skillDir := s.configHomePath(fmt.Sprintf(".claude/skills/%s", dirName))
 	if err := s.ensureDirectory(ctx, containerID, skillDir); err != nil {
 		return fmt.Errorf("failed to create skill directory %s: %w", skillDir, err)
 	}
 
 	// Write content to ~/.claude/skills/{name}/SKILL.md
 	skillPath := fmt.Sprintf("%s/SKILL.md", skillDir)
-	return s.writeFile(ctx, containerID, skillPath, content)
+	if err := s.writeFile(ctx, containerID, skillPath, content); err != nil {
+		return err
+	}
+
+	// Supporting files go through base64 so scripts and reference files keep their exact bytes
+	for _, p := range sortedSkillFilePaths(files) {
+		filePath := skillDir + "/" + p
+		if dir := path.Dir(p); dir != "." {
+			if err := s.ensureDirectory(ctx, containerID, skillDir+"/"+dir); err != nil {
+				return fmt.Errorf("failed to create skill directory %s: %w", dir, err)
+			}
+		}
+		if err := s.writeBinaryFile(ctx, containerID, filePath, []byte(files[p])); err != nil {
+			return fmt.Errorf("failed to write skill file %s: %w", p, err)
+		}
+		if strings.HasPrefix(files[p], "#!") {
+			if _, err := s.dockerClient.ExecInContainer(ctx, containerID, []string{"sh", "-c", fmt.Sprintf("chmod +x %s", filePath)}); err != nil {
+				return fmt.Errorf("failed to make skill file %s executable: %w", p, err)
+			}
+		}
+	}
+	return nil
 }
 
 // InjectSkillArchive injects a multi-file skill from a base64-encoded zip archive
 // The zip file should contain the skill folder structure (SKILL.md + scripts/resources)
 func (s *configInjectionServiceImpl) InjectSkillArchive(ctx context.Context, containerID string, name string, archiveData string) error {
+	dirName, err := skillDirName(name)
+	if err != nil {
+		return err
+	}
+
 	// Decode base64 data
 	zipData, err := base64.StdEncoding.DecodeString(archiveData)
 	if err != nil {
@@ -238,13 +409,13 @@ func (s *configInjectionServiceImpl) InjectSkillArchive(ctx context.Context, con
 	}
 
 	// Create target skill directory
-	skillDir := fmt.Sprintf("%s/%s", skillsDir, name)
+	skillDir := fmt.Sprintf("%s/%s", skillsDir, dirName)
 	if err := s.ensureDirectory(ctx, containerID, skillDir); err != nil {
 		return fmt.Errorf("failed to create skill directory %s: %w", skillDir, err)
 	}
 
 	// Write zip file to a temporary location in container
-	tempZipPath := fmt.Sprintf("/tmp/skill_%s.zip", name)
+	tempZipPath := fmt.Sprintf("/tmp/skill_%s.zip", dirName)
 	if err := s.writeBinaryFile(ctx, containerID, tempZipPath, zipData); err != nil {
 		return fmt.Errorf("failed to write zip file: %w", err)
 	}
@@ -257,19 +428,71 @@ func (s *configInjectionServiceImpl) InjectSkillArchive(ctx context.Context, con
 		return fmt.Errorf("failed to extract skill archive: %w", err)
 	}
 
-	log.Infof("Successfully injected skill archive '%s' to container %s", name, containerID)
+	log.Infof("Successfully injected skill archive '%s' to container %s", dirName, containerID)
 	return nil
 }
 
-// InjectMCP injects MCP configurations into ~/.claude.json
-// Multiple MCP configs are merged into a single file under the mcpServers field
-func (s *configInjectionServiceImpl) InjectMCP(ctx context.Context, containerID string, configs []MCPServerConfig) error {
-	if len(configs) == 0 {
-		return nil
+// normalizeMCPScope validates an MCP scope, defaulting to the user scope
+func normalizeMCPScope(scope string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(scope)) {
+	case "", MCPScopeUser:
+		return MCPScopeUser, nil
+	case MCPScopeProject:
+		return MCPScopeProject, nil
+	default:
+		return "", fmt.Errorf("invalid MCP scope '%s': must be '%s' or '%s'", scope, MCPScopeUser, MCPScopeProject)
+	}
+}
+
+// projectMCPPath returns the .mcp.json path in a container work directory
+func projectMCPPath(workDir string) string {
+	workDir = strings.TrimRight(strings.TrimSpace(workDir), "/")
+	if workDir == "" {
+		workDir = DefaultContainerRootDir
+	}
+	return workDir + "/.mcp.json"
+}
+
+// InjectMCP injects MCP configurations into ~/.claude.json (user scope) and
+// <workDir>/.mcp.json (project scope, shared with everyone working in the repository).
+// Servers are merged into the existing mcpServers field by name, so re-injecting a
+// template replaces its previous entry and unrelated keys in the file are preserved
+func (s *configInjectionServiceImpl) InjectMCP(ctx context.Context, containerID string, workDir string, configs []MCPServerConfig) error {
+	var userConfigs, projectConfigs []MCPServerConfig
+	for _, cfg := range configs {
+		scope, err := normalizeMCPScope(cfg.Scope)
+		if err != nil {
+			return err
+		}
+		if scope == MCPScopeProject {
+			projectConfigs = append(projectConfigs, cfg)
+		} else {
+			userConfigs = append(userConfigs, cfg)
+		}
+	}
+
+	if len(userConfigs) > 0 {
+		if err := s.mergeMCPServers(ctx, containerID, s.configHomePath(".claude.json"), userConfigs); err != nil {
+			return err
+		}
+	}
+	if len(projectConfigs) > 0 {
+		if err := s.mergeMCPServers(ctx, containerID, shellQuote(projectMCPPath(workDir)), projectConfigs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeMCPServers merges servers into the mcpServers field of a JSON file in the container
+func (s *configInjectionServiceImpl) mergeMCPServers(ctx context.Context, containerID string, path string, configs []MCPServerConfig) error {
+	configJSON := s.readJSONFile(ctx, containerID, path)
+
+	mcpServers, ok := configJSON["mcpServers"].(map[string]interface{})
+	if !ok {
+		mcpServers = make(map[string]interface{})
 	}
 
-	// Build the mcpServers map
-	mcpServers := make(map[string]interface{})
 	for _, cfg := range configs {
 		serverConfig := map[string]interface{}{
 			"command": cfg.Command,
@@ -286,20 +509,51 @@ func (s *configInjectionServiceImpl) InjectMCP(ctx context.Context, containerID
 		}
 		mcpServers[cfg.Name] = serverConfig
 	}
-
-	// Build the full claude.json structure
-	claudeJSON := map[string]interface{}{
-		"mcpServers": mcpServers,
-	}
+	configJSON["mcpServers"] = mcpServers
 
 	// Marshal to JSON with indentation for readability
-	jsonContent, err := json.MarshalIndent(claudeJSON, "", "  ")
+	jsonContent, err := json.MarshalIndent(configJSON, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal MCP config: %w", err)
 	}
 
-	// Write to ~/.claude.json
-	return s.writeFile(ctx, containerID, s.configHomePath(".claude.json"), string(jsonContent))
+	return s.writeFile(ctx, containerID, path, string(jsonContent))
+}
+
+// readJSONFile reads a JSON object from the container.
+// A missing, empty or unparsable file yields an empty object.
+func (s *configInjectionServiceImpl) readJSONFile(ctx context.Context, containerID string, path string) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	cmd := []string{"sh", "-c", fmt.Sprintf("cat %s 2>/dev/null || true", path)}
+	output, err := s.dockerClient.ExecInContainer(ctx, containerID, cmd)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to read %s, starting from an empty file", path)
+		return result
+	}
+
+	content := strings.TrimSpace(demuxExecOutput(output))
+	if content == "" {
+		return result
+	}
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		log.WithError(err).Warnf("Existing %s is not valid JSON, it will be replaced", path)
+		return make(map[string]interface{})
+	}
+	return result
+}
+
+// demuxExecOutput strips Docker's stdout/stderr multiplexing headers and returns stdout.
+// Output that is not multiplexed (e.g. from a TTY exec or a test fake) is returned unchanged.
+func demuxExecOutput(output string) string {
+	if len(output) < 8 || output[0] > 2 || output[1] != 0 || output[2] != 0 || output[3] != 0 {
+		return output
+	}
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, strings.NewReader(output)); err != nil {
+		return output
+	}
+	return stdout.String()
 }
 
 // InjectCommand injects a command to ~/.claude/commands/{name}.md
@@ -499,7 +753,7 @@ func (s *configInjectionServiceImpl) InjectSerenaMCP(ctx context.Context, contai
 		Command: "npx",
 		Args:    []string{"-y", "@anthropic/serena-mcp"},
 	}
-	return s.InjectMCP(ctx, containerID, []MCPServerConfig{serenaCfg})
+	return s.InjectMCP(ctx, containerID, "", []MCPServerConfig{serenaCfg})
 }
 
 func (s *configInjectionServiceImpl) configHomePath(relativePath string) string {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 
@@ -214,7 +215,7 @@ func (s *testableConfigInjectionService) injectSingleConfig(ctx context.Context,
 	case models.ConfigTypeClaudeMD:
 		return s.InjectClaudeMD(ctx, containerID, template.Content)
 	case models.ConfigTypeSkill:
-		return s.InjectSkill(ctx, containerID, template.Name, template.Content)
+		return s.InjectSkill(ctx, containerID, template.Name, template.Content, template.Files)
 	case models.ConfigTypeMCP:
 		mcpConfig, err := s.parseMCPConfig(template.Name, template.Content)
 		if err != nil {
@@ -245,7 +246,7 @@ func (s *testableConfigInjectionService) InjectClaudeMD(ctx context.Context, con
 	return s.writeFile(ctx, containerID, "$HOME/.claude/CLAUDE.md", content)
 }
 
-func (s *testableConfigInjectionService) InjectSkill(ctx context.Context, containerID string, name string, content string) error {
+func (s *testableConfigInjectionService) InjectSkill(ctx context.Context, containerID string, name string, content string, files models.SkillFiles) error {
 	skillDir := fmt.Sprintf("$HOME/.claude/skills/%s", name)
 	if err := s.ensureDirectory(ctx, containerID, skillDir); err != nil {
 		return fmt.Errorf("failed to create skill directory %s: %w", skillDir, err)
@@ -787,6 +788,320 @@ func TestInjectConfigs_MCPTemplatesMergedTogether(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// MCP Server Name Conflicts
+// ============================================================================
+
+// newConfigInjectionServiceWithExecutor builds the real service on top of the mock docker client
+func newConfigInjectionServiceWithExecutor(mockDocker *mockDockerClient, templateService ConfigTemplateService) *configInjectionServiceImpl {
+	return &configInjectionServiceImpl{
+		dockerClient:    mockDocker,
+		templateService: templateService,
+	}
+}
+
+func TestInjectConfigs_MCPServerNameConflictReported(t *testing.T) {
+	mockDocker := newMockDockerClient()
+	mockTemplateService := newMockConfigTemplateServiceForInjection()
+
+	template1 := &models.ClaudeConfigTemplate{
+		Name:       "github-readonly",
+		ConfigType: models.ConfigTypeMCP,
+		Content:    `{"name": "github", "command": "npx", "args": ["github-mcp"]}`,
+	}
+	template1.ID = 1
+	mockTemplateService.addTemplate(template1)
+
+	template2 := &models.ClaudeConfigTemplate{
+		Name:       "github-admin",
+		ConfigType: models.ConfigTypeMCP,
+		Content:    `{"name": "github", "command": "npx", "args": ["github-mcp", "--admin"]}`,
+	}
+	template2.ID = 2
+	mockTemplateService.addTemplate(template2)
+
+	service := newConfigInjectionServiceWithExecutor(mockDocker, mockTemplateService)
+
+	status, err := service.InjectConfigs(context.Background(), "test-container", "/app", []uint{1, 2})
+	if err != nil {
+		t.Fatalf("InjectConfigs returned error: %v", err)
+	}
+
+	if len(status.Successful) != 1 || status.Successful[0] != "github-readonly" {
+		t.Errorf("Expected only 'github-readonly' to succeed, got %v", status.Successful)
+	}
+	if len(status.Failed) != 1 {
+		t.Fatalf("Expected 1 failed template, got %d", len(status.Failed))
+	}
+	if status.Failed[0].TemplateName != "github-admin" {
+		t.Errorf("Expected 'github-admin' to fail, got '%s'", status.Failed[0].TemplateName)
+	}
+	if !containsStr(status.Failed[0].Reason, "github-readonly") {
+		t.Errorf("Expected reason to name the conflicting template, got '%s'", status.Failed[0].Reason)
+	}
+	if len(status.Warnings) != 1 {
+		t.Errorf("Expected 1 warning, got %d", len(status.Warnings))
+	}
+
+	// Only the first server definition should reach ~/.claude.json
+	for _, call := range mockDocker.getExecCalls() {
+		if containsStr(call.cmd[2], "--admin") {
+			t.Error("Conflicting MCP template should not be written to ~/.claude.json")
+		}
+	}
+}
+
+// mcpWrites returns the content written to each MCP config file, keyed by the write command's target
+func mcpWrites(calls []execCall) map[string]string {
+	writes := make(map[string]string)
+	for _, call := range calls {
+		if len(call.cmd) != 3 || !strings.HasPrefix(call.cmd[2], "cat > ") {
+			continue
+		}
+		target := strings.SplitN(strings.TrimPrefix(call.cmd[2], "cat > "), " << ", 2)[0]
+		writes[target] = call.cmd[2]
+	}
+	return writes
+}
+
+func TestInjectMCP_ProjectScopeWritesToWorkDir(t *testing.T) {
+	testCases := []struct {
+		workDir string
+		path    string
+	}{
+		{"", "'/app/.mcp.json'"},
+		{"/app", "'/app/.mcp.json'"},
+		{"/workspace/web/", "'/workspace/web/.mcp.json'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			mockDocker := newMockDockerClient()
+			service := newConfigInjectionServiceWithExecutor(mockDocker, newMockConfigTemplateServiceForInjection())
+
+			err := service.InjectMCP(context.Background(), "test-container", tc.workDir, []MCPServerConfig{
+				{Name: "db", Command: "npx", Args: []string{"db-mcp"}, Scope: MCPScopeProject},
+			})
+			if err != nil {
+				t.Fatalf("InjectMCP returned error: %v", err)
+			}
+
+			writes := mcpWrites(mockDocker.getExecCalls())
+			if len(writes) != 1 || !containsStr(writes[tc.path], `"db"`) {
+				t.Errorf("Expected only %s to be written, got %v", tc.path, writes)
+			}
+		})
+	}
+}
+
+func TestInjectConfigs_MCPScopesWrittenSeparately(t *testing.T) {
+	mockDocker := newMockDockerClient()
+	mockTemplateService := newMockConfigTemplateServiceForInjection()
+
+	for i, content := range []string{
+		`{"name": "github", "command": "npx", "args": ["user-github"]}`,
+		`{"name": "github", "command": "npx", "args": ["project-github"], "scope": "project"}`,
+		`{"name": "db", "command": "npx", "args": ["db"], "scope": "workspace"}`,
+	} {
+		template := &models.ClaudeConfigTemplate{
+			Name:       fmt.Sprintf("mcp-%d", i+1),
+			ConfigType: models.ConfigTypeMCP,
+			Content:    content,
+		}
+		template.ID = uint(i + 1)
+		mockTemplateService.addTemplate(template)
+	}
+
+	service := newConfigInjectionServiceWithExecutor(mockDocker, mockTemplateService)
+
+	status, err := service.InjectConfigs(context.Background(), "test-container", "/workspace/web", []uint{1, 2, 3})
+	if err != nil {
+		t.Fatalf("InjectConfigs returned error: %v", err)
+	}
+
+	// The same server name in different scopes is not a conflict
+	if len(status.Successful) != 2 || len(status.Warnings) != 0 {
+		t.Errorf("Expected both scoped templates to succeed without warnings, got %v / %v", status.Successful, status.Warnings)
+	}
+	if len(status.Failed) != 1 || !containsStr(status.Failed[0].Reason, "invalid MCP scope") {
+		t.Errorf("Expected the unknown scope to fail, got %+v", status.Failed)
+	}
+
+	writes := mcpWrites(mockDocker.getExecCalls())
+	user := writes["${CC_CONFIG_HOME:-$HOME}/.claude.json"]
+	project := writes["'/workspace/web/.mcp.json'"]
+	if !containsStr(user, "user-github") || containsStr(user, "project-github") {
+		t.Errorf("Expected ~/.claude.json to hold only the user server, got %q", user)
+	}
+	if !containsStr(project, "project-github") || containsStr(project, "user-github") {
+		t.Errorf("Expected .mcp.json to hold only the project server, got %q", project)
+	}
+}
+
+func TestInjectConfigs_MCPEmptyCommandFails(t *testing.T) {
+	mockDocker := newMockDockerClient()
+	mockTemplateService := newMockConfigTemplateServiceForInjection()
+
+	template1 := &models.ClaudeConfigTemplate{
+		Name:       "blank-command",
+		ConfigType: models.ConfigTypeMCP,
+		Content:    `{"command": "   ", "args": []}`,
+	}
+	template1.ID = 1
+	mockTemplateService.addTemplate(template1)
+
+	service := newConfigInjectionServiceWithExecutor(mockDocker, mockTemplateService)
+
+	status, err := service.InjectConfigs(context.Background(), "test-container", "/app", []uint{1})
+	if err != nil {
+		t.Fatalf("InjectConfigs returned error: %v", err)
+	}
+
+	if len(status.Failed) != 1 || status.Failed[0].TemplateName != "blank-command" {
+		t.Fatalf("Expected 'blank-command' to fail, got %+v", status.Failed)
+	}
+	if len(mockDocker.getExecCalls()) != 0 {
+		t.Error("Expected no exec calls for an MCP template with an empty command")
+	}
+}
+
+// ============================================================================
+// Injection Ordering and Idempotency
+// ============================================================================
+
+// fileBackedDockerClient keeps ~/.claude.json in memory so repeated MCP injections see earlier writes
+type fileBackedDockerClient struct {
+	mu         sync.Mutex
+	claudeJSON string
+}
+
+func (f *fileBackedDockerClient) ExecInContainer(ctx context.Context, containerID string, cmd []string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	script := cmd[len(cmd)-1]
+	if !containsStr(script, ".claude.json") {
+		return "", nil
+	}
+	if len(script) >= 4 && script[:4] == "cat " && !containsStr(script, "CONFIGEOF") {
+		return f.claudeJSON, nil
+	}
+
+	// Extract the heredoc body written by writeFile
+	start := strings.Index(script, "CONFIGEOF'\n")
+	end := strings.LastIndex(script, "\nCONFIGEOF")
+	if start >= 0 && end > start {
+		f.claudeJSON = script[start+len("CONFIGEOF'\n") : end]
+	}
+	return "", nil
+}
+
+func (f *fileBackedDockerClient) mcpServers(t *testing.T) map[string]interface{} {
+	t.Helper()
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(f.claudeJSON), &parsed); err != nil {
+		t.Fatalf("~/.claude.json is not valid JSON: %v\n%s", err, f.claudeJSON)
+	}
+	servers, _ := parsed["mcpServers"].(map[string]interface{})
+	return servers
+}
+
+func TestInjectConfigs_SuccessfulPreservesInputOrder(t *testing.T) {
+	mockDocker := newMockDockerClient()
+	mockTemplateService := newMockConfigTemplateServiceForInjection()
+
+	templates := []*models.ClaudeConfigTemplate{
+		{Name: "review-command", ConfigType: models.ConfigTypeCommand, Content: "# Review"},
+		{Name: "filesystem", ConfigType: models.ConfigTypeMCP, Content: `{"command": "npx", "args": ["fs"]}`},
+		{Name: "project-md", ConfigType: models.ConfigTypeClaudeMD, Content: "# Project"},
+		{Name: "github", ConfigType: models.ConfigTypeMCP, Content: `{"command": "npx", "args": ["gh"]}`},
+	}
+	ids := make([]uint, 0, len(templates))
+	for i, tmpl := range templates {
+		tmpl.ID = uint(10 - i)
+		mockTemplateService.addTemplate(tmpl)
+		ids = append(ids, tmpl.ID)
+	}
+
+	service := newConfigInjectionServiceWithExecutor(mockDocker, mockTemplateService)
+
+	status, err := service.InjectConfigs(context.Background(), "test-container", "/app", ids)
+	if err != nil {
+		t.Fatalf("InjectConfigs returned error: %v", err)
+	}
+
+	expected := []string{"review-command", "filesystem", "project-md", "github"}
+	if fmt.Sprint(status.Successful) != fmt.Sprint(expected) {
+		t.Errorf("Expected successful order %v, got %v", expected, status.Successful)
+	}
+}
+
+func TestInjectConfigs_ReinjectionIsIdempotent(t *testing.T) {
+	docker := &fileBackedDockerClient{claudeJSON: `{"theme": "dark", "mcpServers": {"existing": {"command": "node", "args": []}}}`}
+	mockTemplateService := newMockConfigTemplateServiceForInjection()
+
+	template1 := &models.ClaudeConfigTemplate{
+		Name:       "github",
+		ConfigType: models.ConfigTypeMCP,
+		Content:    `{"command": "npx", "args": ["github-mcp"]}`,
+	}
+	template1.ID = 1
+	mockTemplateService.addTemplate(template1)
+
+	service := &configInjectionServiceImpl{dockerClient: docker, templateService: mockTemplateService}
+
+	for i := 0; i < 3; i++ {
+		status, err := service.InjectConfigs(context.Background(), "test-container", "/app", []uint{1})
+		if err != nil {
+			t.Fatalf("InjectConfigs run %d returned error: %v", i, err)
+		}
+		if len(status.Successful) != 1 || len(status.Failed) != 0 {
+			t.Fatalf("Run %d: expected 1 success and no failures, got %+v", i, status)
+		}
+	}
+
+	servers := docker.mcpServers(t)
+	if len(servers) != 2 {
+		t.Errorf("Expected 2 MCP servers after repeated injection, got %d: %v", len(servers), servers)
+	}
+	if _, ok := servers["existing"]; !ok {
+		t.Error("Expected pre-existing MCP server to be preserved")
+	}
+	if !containsStr(docker.claudeJSON, `"theme": "dark"`) {
+		t.Error("Expected unrelated ~/.claude.json keys to be preserved")
+	}
+
+	// Changing the template replaces the server entry instead of adding another one
+	template1.Content = `{"command": "npx", "args": ["github-mcp", "--read-only"]}`
+	if _, err := service.InjectConfigs(context.Background(), "test-container", "/app", []uint{1}); err != nil {
+		t.Fatalf("InjectConfigs returned error: %v", err)
+	}
+	servers = docker.mcpServers(t)
+	if len(servers) != 2 {
+		t.Errorf("Expected 2 MCP servers after updating template, got %d", len(servers))
+	}
+	if !containsStr(fmt.Sprint(servers["github"]), "--read-only") {
+		t.Errorf("Expected updated github server config, got %v", servers["github"])
+	}
+}
+
+func TestDemuxExecOutput_StripsStreamHeaders(t *testing.T) {
+	frame := func(stream byte, payload string) string {
+		size := len(payload)
+		header := []byte{stream, 0, 0, 0, byte(size >> 24), byte(size >> 16), byte(size >> 8), byte(size)}
+		return string(header) + payload
+	}
+
+	output := frame(1, `{"mcpServers":`) + frame(2, "warning\n") + frame(1, `{}}`)
+	if got := demuxExecOutput(output); got != `{"mcpServers":{}}` {
+		t.Errorf("Expected stdout only, got %q", got)
+	}
+
+	if got := demuxExecOutput(`{"plain": true}`); got != `{"plain": true}` {
+		t.Errorf("Expected plain output unchanged, got %q", got)
+	}
+}
+
 // ============================================================================
 // Additional Test: Docker Exec Failure During Injection
 // ============================================================================
@@ -905,7 +1220,7 @@ func TestInjectSkill_CorrectPath(t *testing.T) {
 	service := newTestableConfigInjectionService(mockDocker, mockTemplateService)
 
 	ctx := context.Background()
-	err := service.InjectSkill(ctx, "test-container", "code-review", "# Code Review Skill")
+	err := service.InjectSkill(ctx, "test-container", "code-review", "# Code Review Skill", nil)
 
 	if err != nil {
 		t.Fatalf("InjectSkill returned error: %v", err)
@@ -935,7 +1250,7 @@ func TestInjectSkill_SkillNameInPath(t *testing.T) {
 
 	ctx := context.Background()
 	skillName := "my-custom-skill"
-	err := service.InjectSkill(ctx, "test-container", skillName, "# Skill Content")
+	err := service.InjectSkill(ctx, "test-container", skillName, "# Skill Content", nil)
 
 	if err != nil {
 		t.Fatalf("InjectSkill returned error: %v", err)
@@ -964,7 +1279,7 @@ func TestInjectSkill_CreatesParentDirectory(t *testing.T) {
 	service := newTestableConfigInjectionService(mockDocker, mockTemplateService)
 
 	ctx := context.Background()
-	err := service.InjectSkill(ctx, "test-container", "test-skill", "# Content")
+	err := service.InjectSkill(ctx, "test-container", "test-skill", "# Content", nil)
 
 	if err != nil {
 		t.Fatalf("InjectSkill returned error: %v", err)
@@ -986,6 +1301,100 @@ func TestInjectSkill_CreatesParentDirectory(t *testing.T) {
 	}
 }
 
+// TestInjectSkill_SanitizesUnsafeNames tests that skill names are slugified before building paths
+func TestInjectSkill_SanitizesUnsafeNames(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expectedDir string
+	}{
+		{name: "My Review Skill", expectedDir: "My-Review-Skill"},
+		{name: "../../etc/cron.d", expectedDir: "etc-cron.d"},
+		{name: "review/日本語", expectedDir: "review"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDocker := newMockDockerClient()
+			service := newConfigInjectionServiceWithExecutor(mockDocker, newMockConfigTemplateServiceForInjection())
+
+			if err := service.InjectSkill(context.Background(), "test-container", tc.name, "# Skill", nil); err != nil {
+				t.Fatalf("InjectSkill returned error: %v", err)
+			}
+
+			expectedPath := fmt.Sprintf("$HOME}/.claude/skills/%s/SKILL.md", tc.expectedDir)
+			found := false
+			for _, call := range mockDocker.getExecCalls() {
+				cmdStr := fmt.Sprintf("%v", call.cmd)
+				if containsStr(cmdStr, "..") && !containsStr(cmdStr, "cron.d") {
+					t.Errorf("Unexpected parent directory reference in command: %s", cmdStr)
+				}
+				if containsStr(cmdStr, expectedPath) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected write to %s", expectedPath)
+			}
+		})
+	}
+}
+
+// TestInjectSkill_RejectsNameWithoutSafeCharacters tests that names with nothing usable are rejected
+func TestInjectSkill_RejectsNameWithoutSafeCharacters(t *testing.T) {
+	mockDocker := newMockDockerClient()
+	service := newConfigInjectionServiceWithExecutor(mockDocker, newMockConfigTemplateServiceForInjection())
+
+	err := service.InjectSkill(context.Background(), "test-container", "技能 / ..", "# Skill", nil)
+	if !errors.Is(err, ErrInvalidSkillName) {
+		t.Fatalf("Expected ErrInvalidSkillName, got %v", err)
+	}
+	if len(mockDocker.getExecCalls()) != 0 {
+		t.Error("Expected no exec calls for a rejected skill name")
+	}
+}
+
+// TestInjectConfigs_SkillDirectoryConflictReported tests that skills mapping to the same directory are reported
+func TestInjectConfigs_SkillDirectoryConflictReported(t *testing.T) {
+	mockDocker := newMockDockerClient()
+	mockTemplateService := newMockConfigTemplateServiceForInjection()
+
+	template1 := &models.ClaudeConfigTemplate{
+		Name:       "code-review",
+		ConfigType: models.ConfigTypeSkill,
+		Content:    "# First",
+	}
+	template1.ID = 1
+	mockTemplateService.addTemplate(template1)
+
+	template2 := &models.ClaudeConfigTemplate{
+		Name:       "code review",
+		ConfigType: models.ConfigTypeSkill,
+		Content:    "# Second",
+	}
+	template2.ID = 2
+	mockTemplateService.addTemplate(template2)
+
+	service := newConfigInjectionServiceWithExecutor(mockDocker, mockTemplateService)
+
+	status, err := service.InjectConfigs(context.Background(), "test-container", "/app", []uint{1, 2})
+	if err != nil {
+		t.Fatalf("InjectConfigs returned error: %v", err)
+	}
+
+	if len(status.Successful) != 1 || status.Successful[0] != "code-review" {
+		t.Errorf("Expected only 'code-review' to succeed, got %v", status.Successful)
+	}
+	if len(status.Failed) != 1 || status.Failed[0].TemplateName != "code review" {
+		t.Fatalf("Expected 'code review' to fail, got %+v", status.Failed)
+	}
+	if !containsStr(status.Failed[0].Reason, "code-review") {
+		t.Errorf("Expected reason to mention the shared directory, got '%s'", status.Failed[0].Reason)
+	}
+	if len(status.Warnings) != 1 {
+		t.Errorf("Expected 1 warning, got %d", len(status.Warnings))
+	}
+}
+
 // ============================================================================
 // InjectMCP Tests
 // ============================================================================
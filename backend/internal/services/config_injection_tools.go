@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"cc-platform/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// How injection checks the tools and commands that skill and MCP templates reference
+const (
+	ToolCheckOff    = "off"    // No checks
+	ToolCheckWarn   = "warn"   // Inject the template and add a warning for each problem
+	ToolCheckStrict = "strict" // Skip the template and record it as failed
+)
+
+// knownClaudeTools are the built-in Claude Code tools a skill's allowed_tools may name.
+// MCP tools (mcp__server__tool) are accepted as they are.
+var knownClaudeTools = map[string]bool{
+	"Agent": true, "Bash": true, "BashOutput": true, "Edit": true, "ExitPlanMode": true,
+	"Glob": true, "Grep": true, "KillShell": true, "LS": true, "MultiEdit": true,
+	"NotebookEdit": true, "NotebookRead": true, "Read": true, "SlashCommand": true, "Skill": true,
+	"Task": true, "TodoRead": true, "TodoWrite": true, "WebFetch": true, "WebSearch": true, "Write": true,
+}
+
+// normalizeToolCheck maps a configured tool check mode to a known one, defaulting to warn
+func normalizeToolCheck(mode string) string {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case ToolCheckOff, ToolCheckWarn, ToolCheckStrict:
+		return mode
+	case "":
+		return ToolCheckWarn
+	default:
+		log.Warnf("Unknown injection tool check mode %q, using %q", mode, ToolCheckWarn)
+		return ToolCheckWarn
+	}
+}
+
+// unknownSkillTools returns the allowed_tools entries that don't name a known tool.
+// Entries may carry a permission pattern, as in "Bash(git status:*)".
+func unknownSkillTools(allowedTools []string) []string {
+	var unknown []string
+	for _, tool := range allowedTools {
+		tool = strings.TrimSpace(tool)
+		name := tool
+		if i := strings.Index(name, "("); i >= 0 {
+			name = strings.TrimSpace(name[:i])
+		}
+		if name == "" || knownClaudeTools[name] || strings.HasPrefix(name, "mcp__") {
+			continue
+		}
+		unknown = append(unknown, tool)
+	}
+	return unknown
+}
+
+// templateToolProblems checks the tools a template depends on: the allowed_tools of a skill and
+// the command of a stdio MCP server, which must be installed in the container. Templates whose
+// content can't be parsed are left to injection to report.
+func (s *configInjectionServiceImpl) templateToolProblems(ctx context.Context, containerID string, template *models.ClaudeConfigTemplate) []string {
+	if s.toolCheck == "" || s.toolCheck == ToolCheckOff {
+		return nil
+	}
+
+	switch template.ConfigType {
+	case models.ConfigTypeSkill:
+		metadata, err := s.templateService.ParseSkillMetadata(template.Content)
+		if err != nil || metadata == nil {
+			return nil
+		}
+		var problems []string
+		for _, tool := range unknownSkillTools(metadata.AllowedTools) {
+			problems = append(problems, fmt.Sprintf("allowed tool '%s' is not a known tool", tool))
+		}
+		return problems
+
+	case models.ConfigTypeMCP:
+		config, err := s.parseMCPConfig(template.Name, template.Content)
+		if err != nil || config.URL != "" || strings.TrimSpace(config.Command) == "" {
+			return nil
+		}
+		missing, err := s.missingCommands(ctx, containerID, config.Command)
+		if err != nil {
+			log.WithError(err).Warnf("Failed to check the command of MCP template %s", template.Name)
+			return nil
+		}
+		var problems []string
+		for _, command := range missing {
+			problems = append(problems, fmt.Sprintf("command '%s' is not installed in the container", command))
+		}
+		return problems
+	}
+	return nil
+}
+
+// toolCheckLabel names a checked template type in warnings
+func toolCheckLabel(configType models.ConfigType) string {
+	if configType == models.ConfigTypeMCP {
+		return "MCP"
+	}
+	return "Skill"
+}
+
+// missingCommands returns the commands that `command -v` can't find in the container
+func (s *configInjectionServiceImpl) missingCommands(ctx context.Context, containerID string, commands ...string) ([]string, error) {
+	cmd := append([]string{"sh", "-c", `for c in "$@"; do command -v "$c" >/dev/null 2>&1 || echo "$c"; done`, "sh"}, commands...)
+	output, err := s.dockerClient.ExecInContainer(ctx, containerID, cmd)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			missing = append(missing, line)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+// frontmatterTemplateService parses real skill frontmatter on top of the injection mock
+type frontmatterTemplateService struct {
+	*mockConfigTemplateServiceForInjection
+}
+
+func (s frontmatterTemplateService) ParseSkillMetadata(content string) (*models.SkillMetadata, error) {
+	return (&configTemplateServiceImpl{}).ParseSkillMetadata(content)
+}
+
+func TestUnknownSkillTools(t *testing.T) {
+	got := unknownSkillTools([]string{"Read", " Bash(git status:*) ", "Frobnicate", "mcp__github__create_issue", "", "WebSearch(foo"})
+	if !reflect.DeepEqual(got, []string{"Frobnicate"}) {
+		t.Errorf("Expected only Frobnicate to be unknown, got %v", got)
+	}
+}
+
+func TestNormalizeToolCheck(t *testing.T) {
+	for input, want := range map[string]string{"": ToolCheckWarn, "OFF": ToolCheckOff, "strict": ToolCheckStrict, "bogus": ToolCheckWarn} {
+		if got := normalizeToolCheck(input); got != want {
+			t.Errorf("normalizeToolCheck(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func setupToolCheckTest(t *testing.T, toolCheck string) (*mockDockerClient, *configInjectionServiceImpl) {
+	t.Helper()
+	docker := newMockDockerClient()
+	templates := newMockConfigTemplateServiceForInjection()
+
+	skill := &models.ClaudeConfigTemplate{
+		Name:       "reviewer",
+		ConfigType: models.ConfigTypeSkill,
+		Content:    "---\nallowed_tools:\n  - Read\n  - Bash(git diff:*)\n  - Frobnicate\n---\n# Reviewer",
+	}
+	skill.ID = 1
+	templates.addTemplate(skill)
+	mcp := &models.ClaudeConfigTemplate{Name: "fetch", ConfigType: models.ConfigTypeMCP, Content: `{"command": "uvx", "args": ["mcp-server-fetch"]}`}
+	mcp.ID = 2
+	templates.addTemplate(mcp)
+	remote := &models.ClaudeConfigTemplate{Name: "remote", ConfigType: models.ConfigTypeMCP, Content: `{"type": "http", "url": "https://mcp.example.com/mcp"}`}
+	remote.ID = 3
+	templates.addTemplate(remote)
+
+	docker.setExecResult("abc", []string{"sh", "-c", `for c in "$@"; do command -v "$c" >/dev/null 2>&1 || echo "$c"; done`, "sh", "uvx"}, "uvx\n", nil)
+	return docker, &configInjectionServiceImpl{dockerClient: docker, templateService: frontmatterTemplateService{templates}, toolCheck: toolCheck}
+}
+
+func TestInjectConfigs_ToolCheckWarn(t *testing.T) {
+	_, service := setupToolCheckTest(t, ToolCheckWarn)
+
+	status, err := service.InjectConfigs(context.Background(), "abc", "/app", []uint{1, 2, 3})
+	if err != nil {
+		t.Fatalf("InjectConfigs failed: %v", err)
+	}
+	if len(status.Successful) != 3 || len(status.Failed) != 0 {
+		t.Fatalf("Expected every template to be injected, got %+v", status)
+	}
+	want := []string{
+		"Skill template 'reviewer': allowed tool 'Frobnicate' is not a known tool",
+		"MCP template 'fetch': command 'uvx' is not installed in the container",
+	}
+	if !reflect.DeepEqual(status.Warnings, want) {
+		t.Errorf("Expected warnings %q, got %q", want, status.Warnings)
+	}
+}
+
+func TestInjectConfigs_ToolCheckStrict(t *testing.T) {
+	_, service := setupToolCheckTest(t, ToolCheckStrict)
+
+	status, err := service.InjectConfigs(context.Background(), "abc", "/app", []uint{1, 2, 3})
+	if err != nil {
+		t.Fatalf("InjectConfigs failed: %v", err)
+	}
+	if !reflect.DeepEqual(status.Successful, []string{"remote"}) || len(status.Failed) != 2 {
+		t.Fatalf("Expected only the remote MCP server to be injected, got %+v", status)
+	}
+	if !strings.Contains(status.Failed[0].Reason, "Frobnicate") || !strings.Contains(status.Failed[1].Reason, "uvx") {
+		t.Errorf("Expected the tool problems as failure reasons, got %+v", status.Failed)
+	}
+}
+
+func TestInjectConfigs_ToolCheckOff(t *testing.T) {
+	docker, service := setupToolCheckTest(t, ToolCheckOff)
+
+	status, err := service.InjectConfigs(context.Background(), "abc", "/app", []uint{1, 2, 3})
+	if err != nil {
+		t.Fatalf("InjectConfigs failed: %v", err)
+	}
+	if len(status.Warnings) != 0 || len(status.Failed) != 0 {
+		t.Errorf("Expected no tool checks, got %+v", status)
+	}
+	for _, call := range docker.getExecCalls() {
+		if strings.Contains(strings.Join(call.cmd, " "), "command -v") {
+			t.Errorf("Expected no command lookups, got %v", call.cmd)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ConfigProfileSwitchResult describes a runtime switch of a container's env profile
+type ConfigProfileSwitchResult struct {
+	ContainerID uint     `json:"container_id"`
+	ProfileID   uint     `json:"profile_id"`
+	ProfileName string   `json:"profile_name"`
+	Applied     []string `json:"applied"` // Env var names now set in ~/.claude/settings.json
+	Removed     []string `json:"removed"` // Names of the previous profile that the new one doesn't set
+	// Headless sessions closed before the restart; their running turns are marked failed
+	ClosedHeadlessSessions int `json:"closed_headless_sessions"`
+}
+
+// HeadlessSessionCloser closes a container's headless sessions, failing their running turns,
+// and returns how many were closed
+type HeadlessSessionCloser func(containerID uint) int
+
+// SetHeadlessSessionCloser sets how a profile switch closes the container's headless sessions
+// before their Claude processes are killed
+func (s *ContainerService) SetHeadlessSessionCloser(closer HeadlessSessionCloser) {
+	s.headlessSessionCloser = closer
+}
+
+// ApplyEnvProfile merges env into the "env" block of ~/.claude/settings.json, which Claude
+// Code applies to every session, then stops the running Claude processes. It fails unless the
+// processes were stopped, since the new env only applies to processes started afterwards.
+func (s *configInjectionServiceImpl) ApplyEnvProfile(ctx context.Context, containerID string, env map[string]string, remove []string) error {
+	if err := s.ensureDirectory(ctx, containerID, s.configHomePath(".claude")); err != nil {
+		return err
+	}
+	path := s.configHomePath(".claude/settings.json")
+	settings := s.readJSONFile(ctx, containerID, path)
+	settingsEnv, ok := settings["env"].(map[string]interface{})
+	if !ok {
+		settingsEnv = make(map[string]interface{})
+	}
+	for _, name := range remove {
+		delete(settingsEnv, name)
+	}
+	for name, value := range env {
+		settingsEnv[name] = value
+	}
+	settings["env"] = settingsEnv
+
+	content, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	if err := s.writeFile(ctx, containerID, path, string(content)); err != nil {
+		return err
+	}
+
+	output, err := s.dockerClient.ExecInContainer(ctx, containerID, []string{"sh", "-c", killClaudeProcessesScript()})
+	if err == nil {
+		err = checkKillClaudeOutput(output)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restart Claude processes: %w", err)
+	}
+	return nil
+}
+
+// SwitchConfigProfile makes an env profile the active one of a running container: its env
+// vars (API base, model, auth) are injected into the Claude settings, vars only the previous
+// profile set are removed, running Claude processes are restarted and the profile is recorded
+// on the container. The container's Docker env keeps the values it was created with.
+func (s *ContainerService) SwitchConfigProfile(ctx context.Context, id, profileID uint) (*ConfigProfileSwitchResult, error) {
+	container, err := s.runningContainerForSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.configProfileService == nil || s.configInjectionService == nil {
+		return nil, fmt.Errorf("config profile switching not available")
+	}
+
+	var profile models.EnvVarsProfile
+	if err := s.db.First(&profile, profileID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProfileNotFound
+		}
+		return nil, err
+	}
+	env, err := s.configProfileService.ParseEnvVars(profile.EnvVars)
+	if err != nil {
+		return nil, err
+	}
+
+	// The previous profile may have been deleted; then there is nothing known to remove
+	var removed []string
+	if container.EnvVarsProfileID != nil && *container.EnvVarsProfileID != profileID {
+		if previous, err := s.configProfileService.GetEnvVars(container.EnvVarsProfileID); err == nil {
+			for name := range previous {
+				if _, kept := env[name]; !kept {
+					removed = append(removed, name)
+				}
+			}
+		}
+	}
+	sort.Strings(removed)
+
+	// Close headless sessions first, so their turns are failed and the sessions dropped instead
+	// of being left running against a killed process
+	closedSessions := 0
+	if s.headlessSessionCloser != nil {
+		closedSessions = s.headlessSessionCloser(id)
+	}
+
+	if err := s.configInjectionService.ApplyEnvProfile(ctx, container.DockerID, env, removed); err != nil {
+		s.addLog(ctx, id, models.LogLevelError, models.LogStageInit,
+			fmt.Sprintf("Failed to switch to config profile '%s': %v", profile.Name, err))
+		return nil, err
+	}
+	if err := s.db.Model(&models.Container{}).Where("id = ?", id).Update("env_vars_profile_id", profileID).Error; err != nil {
+		return nil, fmt.Errorf("failed to record active profile: %w", err)
+	}
+
+	applied := make([]string, 0, len(env))
+	for name := range env {
+		applied = append(applied, name)
+	}
+	sort.Strings(applied)
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageInit,
+		fmt.Sprintf("Switched to config profile '%s' (%d env vars); Claude processes restarted", profile.Name, len(applied)))
+
+	if removed == nil {
+		removed = []string{}
+	}
+	return &ConfigProfileSwitchResult{
+		ContainerID: id,
+		ProfileID:   profileID,
+		ProfileName: profile.Name,
+		Applied:     applied,
+		Removed:     removed,
+
+		ClosedHeadlessSessions: closedSessions,
+	}, nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+// settingsFakeExecutor keeps ~/.claude/settings.json in memory and records process kills
+type settingsFakeExecutor struct {
+	mu        sync.Mutex
+	settings  string
+	killed    int
+	killFails bool
+}
+
+func (f *settingsFakeExecutor) ExecInContainer(ctx context.Context, containerID string, cmd []string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	script := cmd[len(cmd)-1]
+	switch {
+	case strings.Contains(script, "pkill -TERM"):
+		f.killed++
+		if f.killFails {
+			return "pkill exited with 3\n", nil
+		}
+		return killOKMarker + "\n", nil
+	case strings.Contains(script, "CONFIGEOF"):
+		start := strings.Index(script, "CONFIGEOF'\n")
+		end := strings.LastIndex(script, "\nCONFIGEOF")
+		f.settings = script[start+len("CONFIGEOF'\n") : end]
+	case strings.HasPrefix(script, "cat ") && strings.Contains(script, "settings.json"):
+		return f.settings, nil
+	}
+	return "", nil
+}
+
+func (f *settingsFakeExecutor) env(t *testing.T) map[string]interface{} {
+	t.Helper()
+	var settings map[string]interface{}
+	if err := json.Unmarshal([]byte(f.settings), &settings); err != nil {
+		t.Fatalf("settings.json is not valid JSON: %v\n%s", err, f.settings)
+	}
+	env, _ := settings["env"].(map[string]interface{})
+	return env
+}
+
+func TestSwitchConfigProfile(t *testing.T) {
+	s := setupPauseTestService(t)
+	ctx := context.Background()
+	if err := s.db.AutoMigrate(&models.EnvVarsProfile{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	s.configProfileService = NewConfigProfileService(s.db, nil)
+	docker := &settingsFakeExecutor{settings: `{"theme": "dark", "env": {"USER_VAR": "keep", "ANTHROPIC_MODEL": "old"}}`}
+	s.configInjectionService = &configInjectionServiceImpl{dockerClient: docker}
+
+	official := models.EnvVarsProfile{Name: "official", EnvVars: "ANTHROPIC_BASE_URL=https://api.anthropic.com\nANTHROPIC_MODEL=opus"}
+	proxy := models.EnvVarsProfile{Name: "proxy", EnvVars: "ANTHROPIC_BASE_URL=https://proxy.example.com\nANTHROPIC_AUTH_TOKEN=tok"}
+	for _, p := range []*models.EnvVarsProfile{&official, &proxy} {
+		if err := s.db.Create(p).Error; err != nil {
+			t.Fatalf("failed to create profile: %v", err)
+		}
+	}
+	c := createPauseTestContainer(t, s, "switch", models.ContainerStatusRunning)
+	s.db.Model(c).Update("env_vars_profile_id", official.ID)
+
+	var closedFor []uint
+	s.SetHeadlessSessionCloser(func(containerID uint) int {
+		closedFor = append(closedFor, containerID)
+		return 2
+	})
+
+	result, err := s.SwitchConfigProfile(ctx, c.ID, proxy.ID)
+	if err != nil {
+		t.Fatalf("SwitchConfigProfile failed: %v", err)
+	}
+	if result.ClosedHeadlessSessions != 2 || !reflect.DeepEqual(closedFor, []uint{c.ID}) {
+		t.Errorf("Expected the container's headless sessions to be closed, got %d for %v", result.ClosedHeadlessSessions, closedFor)
+	}
+	if result.ProfileName != "proxy" ||
+		!reflect.DeepEqual(result.Applied, []string{"ANTHROPIC_AUTH_TOKEN", "ANTHROPIC_BASE_URL"}) ||
+		!reflect.DeepEqual(result.Removed, []string{"ANTHROPIC_MODEL"}) {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// The new profile's vars replace the old profile's; unrelated settings are kept
+	want := map[string]interface{}{
+		"USER_VAR":             "keep",
+		"ANTHROPIC_BASE_URL":   "https://proxy.example.com",
+		"ANTHROPIC_AUTH_TOKEN": "tok",
+	}
+	if env := docker.env(t); !reflect.DeepEqual(env, want) {
+		t.Errorf("settings env = %v, want %v", env, want)
+	}
+	if !strings.Contains(docker.settings, `"theme": "dark"`) {
+		t.Errorf("Expected other settings to be kept, got %s", docker.settings)
+	}
+	if docker.killed != 1 {
+		t.Errorf("Expected Claude processes to be restarted once, got %d", docker.killed)
+	}
+
+	stored, _ := s.GetContainer(c.ID)
+	if stored.EnvVarsProfileID == nil || *stored.EnvVarsProfileID != proxy.ID {
+		t.Errorf("Expected the active profile to be recorded, got %v", stored.EnvVarsProfileID)
+	}
+
+	// Switching back removes nothing the old profile didn't set
+	result, err = s.SwitchConfigProfile(ctx, c.ID, official.ID)
+	if err != nil {
+		t.Fatalf("SwitchConfigProfile failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Removed, []string{"ANTHROPIC_AUTH_TOKEN"}) || docker.env(t)["ANTHROPIC_MODEL"] != "opus" {
+		t.Errorf("Unexpected switch back: %+v, env %v", result, docker.env(t))
+	}
+}
+
+func TestSwitchConfigProfile_Errors(t *testing.T) {
+	s := setupPauseTestService(t)
+	ctx := context.Background()
+	if err := s.db.AutoMigrate(&models.EnvVarsProfile{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	s.configProfileService = NewConfigProfileService(s.db, nil)
+	docker := &settingsFakeExecutor{}
+	s.configInjectionService = &configInjectionServiceImpl{dockerClient: docker}
+	profile := models.EnvVarsProfile{Name: "p", EnvVars: "A=1"}
+	s.db.Create(&profile)
+
+	running := createPauseTestContainer(t, s, "running", models.ContainerStatusRunning)
+	stopped := createPauseTestContainer(t, s, "stopped", models.ContainerStatusStopped)
+
+	if _, err := s.SwitchConfigProfile(ctx, running.ID, 9999); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Expected ErrProfileNotFound, got %v", err)
+	}
+	if _, err := s.SwitchConfigProfile(ctx, stopped.ID, profile.ID); !errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("Expected ErrContainerNotRunning, got %v", err)
+	}
+	if _, err := s.SwitchConfigProfile(ctx, 9999, profile.ID); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+	if docker.settings != "" || docker.killed != 0 {
+		t.Error("Expected nothing to be injected for a failed switch")
+	}
+
+	// A failed kill fails the switch instead of claiming a restart
+	docker.killFails = true
+	if _, err := s.SwitchConfigProfile(ctx, running.ID, profile.ID); err == nil || !strings.Contains(err.Error(), "failed to restart Claude processes") {
+		t.Errorf("Expected the failed restart to be reported, got %v", err)
+	}
+	if stored, _ := s.GetContainer(running.ID); stored.EnvVarsProfileID != nil {
+		t.Errorf("Expected the profile not to be recorded after a failed restart, got %v", *stored.EnvVarsProfileID)
+	}
+}
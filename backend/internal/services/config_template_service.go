@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"cc-platform/internal/models"
@@ -19,8 +20,46 @@ var (
 	ErrDuplicateTemplateName = errors.New("template with this name already exists for this config type")
 	// ErrInvalidConfigType is returned when an invalid config type is provided
 	ErrInvalidConfigType = errors.New("invalid config_type, must be one of: CLAUDE_MD, SKILL, MCP, COMMAND, CODEX_CONFIG, CODEX_AUTH, GEMINI_ENV")
+	// ErrInvalidSkillName is returned when a skill name cannot be used as a directory name
+	ErrInvalidSkillName = errors.New("invalid skill name, must start with a letter or digit and contain only letters, digits, '.', '_' or '-'")
 )
 
+// skillNamePattern matches names that are safe to use as a single path segment under ~/.claude/skills
+var skillNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateSkillName checks that a skill name is a safe directory slug
+func ValidateSkillName(name string) error {
+	if !skillNamePattern.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("%w: '%s'", ErrInvalidSkillName, name)
+	}
+	return nil
+}
+
+// SlugifySkillName converts an arbitrary name into a safe skill directory name.
+// Runs of unsupported characters collapse into a single '-'; an empty result means
+// the name has no usable characters.
+func SlugifySkillName(name string) string {
+	var b strings.Builder
+	lastDash := false
+	for _, r := range strings.TrimSpace(name) {
+		isSafe := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '.' || r == '-'
+		if !isSafe || r == '-' {
+			if !lastDash {
+				b.WriteRune('-')
+				lastDash = true
+			}
+			continue
+		}
+		b.WriteRune(r)
+		lastDash = false
+	}
+	slug := b.String()
+	for strings.Contains(slug, "..") {
+		slug = strings.ReplaceAll(slug, "..", ".")
+	}
+	return strings.Trim(slug, "-.")
+}
+
 // ConfigTemplateService defines the interface for managing Claude config templates
 type ConfigTemplateService interface {
 	// CRUD operations
@@ -42,6 +81,7 @@ type CreateConfigTemplateInput struct {
 	ConfigType  models.ConfigType `json:"config_type" binding:"required"`
 	Content     string            `json:"content" binding:"required"`
 	Description string            `json:"description"`
+	Files       map[string]string `json:"files,omitempty"` // Supporting files of a SKILL, keyed by relative path
 }
 
 // UpdateConfigTemplateInput represents the input for updating a config template
@@ -49,6 +89,8 @@ type UpdateConfigTemplateInput struct {
 	Name        *string `json:"name,omitempty"`
 	Content     *string `json:"content,omitempty"`
 	Description *string `json:"description,omitempty"`
+	// Files replaces the supporting files of a SKILL; an empty object removes them
+	Files *map[string]string `json:"files,omitempty"`
 }
 
 // configTemplateServiceImpl is the implementation of ConfigTemplateService
@@ -70,10 +112,21 @@ func (s *configTemplateServiceImpl) Create(input CreateConfigTemplateInput) (*mo
 		return nil, ErrInvalidConfigType
 	}
 
+	// Skill names become directory names inside the container
+	if input.ConfigType == models.ConfigTypeSkill {
+		if err := ValidateSkillName(input.Name); err != nil {
+			return nil, err
+		}
+	}
+
 	// Validate content based on config type
 	if err := s.ValidateContent(input.ConfigType, input.Content); err != nil {
 		return nil, err
 	}
+	files, err := validateTemplateFiles(input.ConfigType, input.Files)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create the template
 	template := &models.ClaudeConfigTemplate{
@@ -81,6 +134,7 @@ func (s *configTemplateServiceImpl) Create(input CreateConfigTemplateInput) (*mo
 		ConfigType:  input.ConfigType,
 		Content:     input.Content,
 		Description: input.Description,
+		Files:       files,
 	}
 
 	// Attempt to create - the unique constraint will catch duplicates
@@ -139,6 +193,11 @@ func (s *configTemplateServiceImpl) Update(id uint, input UpdateConfigTemplateIn
 	updates := make(map[string]interface{})
 
 	if input.Name != nil {
+		if template.ConfigType == models.ConfigTypeSkill {
+			if err := ValidateSkillName(*input.Name); err != nil {
+				return nil, err
+			}
+		}
 		updates["name"] = *input.Name
 	}
 
@@ -154,6 +213,14 @@ func (s *configTemplateServiceImpl) Update(id uint, input UpdateConfigTemplateIn
 		updates["description"] = *input.Description
 	}
 
+	if input.Files != nil {
+		files, err := validateTemplateFiles(template.ConfigType, *input.Files)
+		if err != nil {
+			return nil, err
+		}
+		updates["files"] = files
+	}
+
 	// If no updates, return the existing template
 	if len(updates) == 0 {
 		return template, nil
@@ -187,58 +254,25 @@ func (s *configTemplateServiceImpl) Delete(id uint) error {
 	return nil
 }
 
+// validateTemplateFiles checks the supporting files of a template, which only skills can have
+func validateTemplateFiles(configType models.ConfigType, files map[string]string) (models.SkillFiles, error) {
+	if len(files) > 0 && configType != models.ConfigTypeSkill {
+		return nil, fmt.Errorf("%w: only SKILL templates can have supporting files", ErrInvalidSkillFile)
+	}
+	return normalizeSkillFiles(files)
+}
+
 // ValidateContent validates the content based on config type
 func (s *configTemplateServiceImpl) ValidateContent(configType models.ConfigType, content string) error {
 	if content == "" {
 		return errors.New("content cannot be empty")
 	}
 
-	switch configType {
-	case models.ConfigTypeMCP:
-		return s.ValidateMCPConfig(content)
-	case models.ConfigTypeSkill:
-		// Skill content is Markdown with optional YAML frontmatter
-		// Validate that if frontmatter exists, it's valid YAML
-		_, err := s.ParseSkillMetadata(content)
-		return err
-	case models.ConfigTypeClaudeMD:
-		// CLAUDE.MD is Markdown, basic validation (non-empty content is sufficient)
-		return nil
-	case models.ConfigTypeCommand:
-		// Command is Markdown, basic validation (non-empty content is sufficient)
-		return nil
-	case models.ConfigTypeCodexConf:
-		// Codex config is TOML, basic validation (non-empty content is sufficient)
-		return nil
-	case models.ConfigTypeCodexAuth:
-		// Codex auth is JSON, validate it's valid JSON
-		var js json.RawMessage
-		if err := json.Unmarshal([]byte(content), &js); err != nil {
-			return fmt.Errorf("invalid Codex auth JSON: %w", err)
-		}
-		return nil
-	case models.ConfigTypeGeminiEnv:
-		// Gemini env vars, validate format (VAR=value or export VAR=value)
-		lines := strings.Split(content, "\n")
-		hasValidLine := false
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			line = strings.TrimPrefix(line, "export ")
-			if !strings.Contains(line, "=") {
-				return fmt.Errorf("invalid Gemini env var line (missing '='): %s", line)
-			}
-			hasValidLine = true
-		}
-		if !hasValidLine {
-			return errors.New("no valid environment variables found")
-		}
-		return nil
-	default:
+	schema, ok := configTypeSchemas[configType]
+	if !ok {
 		return ErrInvalidConfigType
 	}
+	return schema.validate(s, content)
 }
 
 // ParseSkillMetadata parses skill metadata from Markdown frontmatter
@@ -332,10 +366,14 @@ func (s *configTemplateServiceImpl) ValidateMCPConfig(content string) error {
 		return errors.New("invalid MCP configuration: missing required field 'command'")
 	}
 
-	// Verify "command" is a string
-	if _, ok := commandVal.(string); !ok {
+	// Verify "command" is a non-empty string
+	command, ok := commandVal.(string)
+	if !ok {
 		return errors.New("invalid MCP configuration: 'command' must be a string")
 	}
+	if strings.TrimSpace(command) == "" {
+		return errors.New("invalid MCP configuration: 'command' cannot be empty")
+	}
 
 	// Check for required "args" field
 	argsVal, hasArgs := config["args"]
@@ -348,6 +386,25 @@ func (s *configTemplateServiceImpl) ValidateMCPConfig(content string) error {
 		return errors.New("invalid MCP configuration: 'args' must be an array")
 	}
 
+	// An optional "name" overrides the server name in ~/.claude.json
+	if nameVal, hasName := config["name"]; hasName {
+		name, ok := nameVal.(string)
+		if !ok || strings.TrimSpace(name) == "" {
+			return errors.New("invalid MCP configuration: 'name' must be a non-empty string")
+		}
+	}
+
+	// An optional "scope" selects ~/.claude.json ("user") or the project's .mcp.json ("project")
+	if scopeVal, hasScope := config["scope"]; hasScope {
+		scope, ok := scopeVal.(string)
+		if !ok {
+			return errors.New("invalid MCP configuration: 'scope' must be a string")
+		}
+		if _, err := normalizeMCPScope(scope); err != nil {
+			return fmt.Errorf("invalid MCP configuration: %w", err)
+		}
+	}
+
 	return nil
 }
 
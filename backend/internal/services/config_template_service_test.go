@@ -1132,6 +1132,63 @@ install_target_dir: /workspace/custom-skills
 	}
 }
 
+// ============================================================================
+// Skill Name Tests
+// ============================================================================
+
+// TestValidateSkillName tests the safe slug pattern for skill names
+func TestValidateSkillName(t *testing.T) {
+	valid := []string{"code-review", "skill_1", "v2.0", "Review"}
+	invalid := []string{"", "my skill", "a/b", "../escape", "a..b", "-leading", "技能", "café"}
+
+	for _, name := range valid {
+		if err := ValidateSkillName(name); err != nil {
+			t.Errorf("Expected %q to be valid, got: %v", name, err)
+		}
+	}
+	for _, name := range invalid {
+		if err := ValidateSkillName(name); !errors.Is(err, ErrInvalidSkillName) {
+			t.Errorf("Expected %q to be rejected with ErrInvalidSkillName, got: %v", name, err)
+		}
+	}
+}
+
+// TestSlugifySkillName tests conversion of arbitrary names into directory names
+func TestSlugifySkillName(t *testing.T) {
+	testCases := map[string]string{
+		"code-review":      "code-review",
+		"My Review Skill":  "My-Review-Skill",
+		"  padded  ":       "padded",
+		"a/b\\c":           "a-b-c",
+		"../../etc/passwd": "etc-passwd",
+		"café au lait":     "caf-au-lait",
+		"技能":               "",
+		"..":               "",
+	}
+
+	for input, expected := range testCases {
+		if got := SlugifySkillName(input); got != expected {
+			t.Errorf("SlugifySkillName(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+// TestCreate_UnsafeSkillNameRejected tests that skill templates with unsafe names are rejected before saving
+func TestCreate_UnsafeSkillNameRejected(t *testing.T) {
+	impl := &configTemplateServiceImpl{db: nil}
+
+	for _, name := range []string{"my skill", "skills/../../x", "技能"} {
+		_, err := impl.Create(CreateConfigTemplateInput{
+			Name:       name,
+			ConfigType: models.ConfigTypeSkill,
+			Content:    "# Skill",
+		})
+		if !errors.Is(err, ErrInvalidSkillName) {
+			t.Errorf("Expected ErrInvalidSkillName for %q, got: %v", name, err)
+		}
+	}
+}
+
 // ============================================================================
 // ValidateMCPConfig Method Tests (Task 2.4)
 // ============================================================================
@@ -1227,6 +1284,47 @@ func TestValidateMCPConfig_MissingArgsFieldReturnsError(t *testing.T) {
 	}
 }
 
+// TestValidateMCPConfig_EmptyCommandReturnsError tests that an empty or whitespace "command" returns error
+func TestValidateMCPConfig_EmptyCommandReturnsError(t *testing.T) {
+	impl := &configTemplateServiceImpl{db: nil}
+
+	testCases := []string{
+		`{"command": "", "args": []}`,
+		`{"command": "   ", "args": ["server.js"]}`,
+		`{"command": "\t\n", "args": []}`,
+	}
+
+	for i, content := range testCases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			err := impl.ValidateMCPConfig(content)
+			if err == nil {
+				t.Error("Expected error for empty 'command', got nil")
+			}
+			if err != nil && !containsSubstring(err.Error(), "cannot be empty") {
+				t.Errorf("Expected error message to mention empty command, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestValidateMCPConfig_InvalidNameReturnsError tests that a blank or non-string "name" returns error
+func TestValidateMCPConfig_InvalidNameReturnsError(t *testing.T) {
+	impl := &configTemplateServiceImpl{db: nil}
+
+	testCases := []string{
+		`{"name": "", "command": "node", "args": []}`,
+		`{"name": 42, "command": "node", "args": []}`,
+	}
+
+	for i, content := range testCases {
+		t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
+			if err := impl.ValidateMCPConfig(content); err == nil {
+				t.Error("Expected error for invalid 'name', got nil")
+			}
+		})
+	}
+}
+
 // TestValidateMCPConfig_CommandNotStringReturnsError tests that "command" not being a string returns error
 func TestValidateMCPConfig_CommandNotStringReturnsError(t *testing.T) {
 	impl := &configTemplateServiceImpl{db: nil}
@@ -1286,3 +1384,23 @@ func TestValidateMCPConfig_EmptyJSONObjectReturnsError(t *testing.T) {
 		t.Error("Expected error for empty JSON object, got nil")
 	}
 }
+
+// TestValidateMCPConfig_Scope tests that only the user and project scopes are accepted
+func TestValidateMCPConfig_Scope(t *testing.T) {
+	impl := &configTemplateServiceImpl{db: nil}
+
+	for _, scope := range []string{"user", "project", "Project"} {
+		content := fmt.Sprintf(`{"command": "node", "args": [], "scope": %q}`, scope)
+		if err := impl.ValidateMCPConfig(content); err != nil {
+			t.Errorf("Expected scope %q to be valid, got: %v", scope, err)
+		}
+	}
+	for _, content := range []string{
+		`{"command": "node", "args": [], "scope": "global"}`,
+		`{"command": "node", "args": [], "scope": 1}`,
+	} {
+		if err := impl.ValidateMCPConfig(content); err == nil || !containsSubstring(err.Error(), "scope") {
+			t.Errorf("Expected a scope error for %s, got: %v", content, err)
+		}
+	}
+}
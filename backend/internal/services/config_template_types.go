@@ -0,0 +1,152 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cc-platform/internal/models"
+)
+
+// Content formats reported for each config type
+const (
+	ConfigFormatMarkdown        = "markdown"
+	ConfigFormatYAMLFrontmatter = "yaml-frontmatter" // Markdown with an optional YAML frontmatter block
+	ConfigFormatJSON            = "json"
+	ConfigFormatTOML            = "toml"
+	ConfigFormatEnv             = "env" // VAR=value lines
+)
+
+// ConfigTypeSchema describes a config type to clients: what its content looks like and how
+// the server validates it
+type ConfigTypeSchema struct {
+	Type            models.ConfigType `json:"type"`
+	Label           string            `json:"label"`
+	Format          string            `json:"format"`
+	RequiredFields  []string          `json:"required_fields,omitempty"` // Top-level fields the content must have
+	OptionalFields  []string          `json:"optional_fields,omitempty"` // Recognized fields besides the required ones
+	SupportsArchive bool              `json:"supports_archive"`          // Can be uploaded as a zip archive
+	Hints           []string          `json:"hints"`
+
+	// validate checks content that is already known to be non-empty
+	validate func(s *configTemplateServiceImpl, content string) error
+}
+
+// configTypeSchemas holds the schema and validator of every config type. ValidateContent and
+// ConfigTypeSchemas both read it, so the API can't drift from what the server accepts.
+var configTypeSchemas = map[models.ConfigType]ConfigTypeSchema{
+	models.ConfigTypeClaudeMD: {
+		Label:  "CLAUDE.md",
+		Format: ConfigFormatMarkdown,
+		Hints:  []string{"Any non-empty Markdown", "Written to ~/.claude/CLAUDE.md"},
+		validate: func(*configTemplateServiceImpl, string) error {
+			return nil
+		},
+	},
+	models.ConfigTypeSkill: {
+		Label:  "Skill",
+		Format: ConfigFormatYAMLFrontmatter,
+		OptionalFields: []string{
+			"allowed_tools", "disable_model_invocation", "install_source", "install_global",
+			"install_agents", "install_skills", "install_all", "install_target_dir",
+		},
+		SupportsArchive: true,
+		Hints: []string{
+			"Markdown with an optional YAML frontmatter block between '---' lines",
+			"A frontmatter block must be closed and contain valid YAML",
+			"The name must start with a letter or digit and contain only letters, digits, '.', '_' or '-'",
+			"Supporting files go in 'files', keyed by a path relative to the skill directory (e.g. scripts/run.sh)",
+			"Multi-file skills can also be uploaded as a zip archive containing SKILL.md",
+		},
+		validate: func(s *configTemplateServiceImpl, content string) error {
+			_, err := s.ParseSkillMetadata(content)
+			return err
+		},
+	},
+	models.ConfigTypeMCP: {
+		Label:          "MCP server",
+		Format:         ConfigFormatJSON,
+		RequiredFields: []string{"command", "args"},
+		OptionalFields: []string{"name", "env", "transport", "url", "scope"},
+		Hints: []string{
+			"A JSON object",
+			"'command' must be a non-empty string",
+			"'args' must be an array",
+			"'name' overrides the server name and must be a non-empty string",
+			"'scope' is 'user' (~/.claude.json) or 'project' (.mcp.json)",
+		},
+		validate: (*configTemplateServiceImpl).ValidateMCPConfig,
+	},
+	models.ConfigTypeCommand: {
+		Label:  "Slash command",
+		Format: ConfigFormatMarkdown,
+		Hints:  []string{"Any non-empty Markdown", "Written to ~/.claude/commands/<name>.md"},
+		validate: func(*configTemplateServiceImpl, string) error {
+			return nil
+		},
+	},
+	models.ConfigTypeCodexConf: {
+		Label:  "Codex config",
+		Format: ConfigFormatTOML,
+		Hints:  []string{"Any non-empty TOML", "Written to ~/.codex/config.toml"},
+		validate: func(*configTemplateServiceImpl, string) error {
+			return nil
+		},
+	},
+	models.ConfigTypeCodexAuth: {
+		Label:  "Codex auth",
+		Format: ConfigFormatJSON,
+		Hints:  []string{"Must be valid JSON", "Written to ~/.codex/auth.json"},
+		validate: func(_ *configTemplateServiceImpl, content string) error {
+			var js json.RawMessage
+			if err := json.Unmarshal([]byte(content), &js); err != nil {
+				return fmt.Errorf("invalid Codex auth JSON: %w", err)
+			}
+			return nil
+		},
+	},
+	models.ConfigTypeGeminiEnv: {
+		Label:  "Gemini env",
+		Format: ConfigFormatEnv,
+		Hints: []string{
+			"One VAR=value per line, optionally prefixed with 'export '",
+			"Blank lines and lines starting with '#' are ignored",
+			"At least one variable is required",
+			"Written to ~/.gemini_env and sourced from ~/.bashrc",
+		},
+		validate: func(_ *configTemplateServiceImpl, content string) error {
+			hasValidLine := false
+			for _, line := range strings.Split(content, "\n") {
+				line = strings.TrimSpace(line)
+				if line == "" || strings.HasPrefix(line, "#") {
+					continue
+				}
+				line = strings.TrimPrefix(line, "export ")
+				if !strings.Contains(line, "=") {
+					return fmt.Errorf("invalid Gemini env var line (missing '='): %s", line)
+				}
+				hasValidLine = true
+			}
+			if !hasValidLine {
+				return errors.New("no valid environment variables found")
+			}
+			return nil
+		},
+	},
+}
+
+// ConfigTypeSchemas returns the schema of every valid config type, in ValidConfigTypes order
+func ConfigTypeSchemas() []ConfigTypeSchema {
+	types := models.ValidConfigTypes()
+	schemas := make([]ConfigTypeSchema, 0, len(types))
+	for _, configType := range types {
+		schema, ok := configTypeSchemas[configType]
+		if !ok {
+			continue
+		}
+		schema.Type = configType
+		schemas = append(schemas, schema)
+	}
+	return schemas
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
@@ -9,12 +10,14 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"cc-platform/internal/config"
 	"cc-platform/internal/docker"
+	"cc-platform/internal/logging"
 	"cc-platform/internal/models"
 
 	"github.com/docker/docker/api/types/container"
@@ -112,6 +115,20 @@ func buildManagedContainerBinds(containerName string) []string {
 	}
 }
 
+// repoCacheBinds mounts the cache of the container's own repository read-only when the cache
+// is enabled. Other cached repositories are not mounted, so a container can't read them.
+func (s *ContainerService) repoCacheBinds(repoURL string) []string {
+	if !s.repoCache.Enabled() || repoURL == "" {
+		return nil
+	}
+	source, err := s.repoCache.MountSource(repoURL)
+	if err != nil {
+		log.Printf("Warning: not mounting the repository cache: %v", err)
+		return nil
+	}
+	return []string{fmt.Sprintf("%s:%s:ro", source, s.repoCache.ContainerPath(repoURL))}
+}
+
 func managedPerContainerVolumes(containerName string) []string {
 	return []string{
 		managedWorkspaceVolumeName(containerName),
@@ -119,6 +136,15 @@ func managedPerContainerVolumes(containerName string) []string {
 	}
 }
 
+// volumeContainerName returns the name a container's managed volumes were created under:
+// the pool name for containers claimed from the warm pool, its own name otherwise
+func volumeContainerName(c *models.Container) string {
+	if c.PoolName != "" {
+		return c.PoolName
+	}
+	return c.Name
+}
+
 // ContainerService handles container operations
 type ContainerService struct {
 	db                     *gorm.DB
@@ -128,8 +154,19 @@ type ContainerService struct {
 	githubService          *GitHubService
 	configProfileService   *ConfigProfileService
 	configInjectionService ConfigInjectionService
-	initTasks              sync.Map // map[uint]context.CancelFunc
+	initTasks              sync.Map // map[uint]context.CancelCauseFunc
 	pendingTemplateIDs     sync.Map // map[uint][]uint - stores template IDs for pending container initialization
+	resourceProfiles       []ResourceProfile
+	initConsole            *InitConsoleHub
+	repoCache              *RepoCache
+	activeSessionChecker   ActiveSessionChecker
+	headlessSessionCloser  HeadlessSessionCloser
+	traefik                traefikRouter
+	codeServerPortStart    int
+	codeServerPortEnd      int
+	portMu                 sync.Mutex
+	pendingPorts           map[int]bool // Host ports allocated to creations not saved yet
+	warmPool               *warmPool
 
 	// Goroutine lifecycle management
 	wg     sync.WaitGroup
@@ -146,17 +183,32 @@ func NewContainerService(db *gorm.DB, cfg *config.Config, claudeService *ClaudeC
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &ContainerService{
+	s := &ContainerService{
 		db:                     db,
 		config:                 cfg,
 		dockerClient:           dockerClient,
+		traefik:                dockerClient,
 		claudeService:          claudeService,
 		githubService:          githubService,
 		configProfileService:   configProfileService,
 		configInjectionService: configInjectionService,
+		resourceProfiles:       loadResourceProfiles(cfg),
+		initConsole:            NewInitConsoleHub(),
+		repoCache:              NewRepoCache(cfg.RepoCacheDir, cfg.RepoCacheRefreshInterval),
 		ctx:                    ctx,
 		cancel:                 cancel,
-	}, nil
+	}
+	s.codeServerPortStart, s.codeServerPortEnd = loadCodeServerPortRange(cfg)
+	if s.repoCache.Enabled() && cfg.RepoCacheRefreshInterval > 0 {
+		s.wg.Add(1)
+		go s.refreshRepoCacheLoop(cfg.RepoCacheRefreshInterval)
+	}
+	if cfg.WarmPoolSize > 0 && cfg.WarmPoolRefillInterval > 0 {
+		s.warmPool = newWarmPool(cfg.WarmPoolSize, s.createWarmContainer, s.removeWarmContainer)
+		s.wg.Add(1)
+		go s.warmPoolLoop(cfg.WarmPoolRefillInterval)
+	}
+	return s, nil
 }
 
 // Close closes the container service and waits for all goroutines to finish
@@ -166,8 +218,8 @@ func (s *ContainerService) Close() error {
 
 	// Cancel all init tasks
 	s.initTasks.Range(func(key, value interface{}) bool {
-		if cancel, ok := value.(context.CancelFunc); ok {
-			cancel()
+		if cancel, ok := value.(context.CancelCauseFunc); ok {
+			cancel(errInitCancelled)
 		}
 		return true
 	})
@@ -197,30 +249,42 @@ type PortMapping struct {
 
 // ProxyConfig represents Traefik proxy configuration
 type ProxyConfig struct {
-	Enabled     bool   `json:"enabled"`                // Enable Traefik proxy
+	Enabled     *bool  `json:"enabled,omitempty"`      // Enable Traefik proxy (nil = the container profile's setting, else off)
 	Domain      string `json:"domain,omitempty"`       // Subdomain for domain-based access
 	Port        int    `json:"port,omitempty"`         // Direct port access (9001-9010)
 	ServicePort int    `json:"service_port,omitempty"` // Container internal service port
 }
 
+// enabled reports whether the proxy is turned on
+func (p ProxyConfig) enabled() bool {
+	return p.Enabled != nil && *p.Enabled
+}
+
 // CreateContainerInput represents input for creating a container
 type CreateContainerInput struct {
 	Name             string        `json:"name" binding:"required"`
 	GitRepoURL       string        `json:"git_repo_url,omitempty"`       // GitHub repo URL (optional when SkipGitRepo=true)
 	GitRepoName      string        `json:"git_repo_name,omitempty"`      // Optional: repo name, extracted from URL if not provided
 	SkipGitRepo      bool          `json:"skip_git_repo,omitempty"`      // Allow creating container without GitHub repository
-	SkipClaudeInit   bool          `json:"skip_claude_init,omitempty"`   // Skip Claude Code initialization
+	SkipClaudeInit   bool          `json:"skip_claude_init,omitempty"`   // Deprecated: use InitStrategy; maps to "none" (or "script" with a pre-init script)
+	InitStrategy     string        `json:"init_strategy,omitempty"`      // "claude", "script", "both" or "none"
 	EnableYoloMode   bool          `json:"enable_yolo_mode,omitempty"`   // Enable YOLO mode (--dangerously-skip-permissions)
+	ConfirmYolo      bool          `json:"confirm_yolo,omitempty"`       // Acknowledges the risks of YOLO mode; required with EnableYoloMode or PreInitScript
 	RunAsRoot        bool          `json:"run_as_root,omitempty"`        // Run container as root user (default: false)
+	RunAsUser        string        `json:"run_as_user,omitempty"`        // Run as this username or uid[:gid] (default: the image's user)
 	MemoryLimit      int64         `json:"memory_limit,omitempty"`       // Memory limit in MB (0 = default 2048MB)
 	CPULimit         float64       `json:"cpu_limit,omitempty"`          // CPU limit in cores (0 = default 1)
 	MemoryUnlimited  bool          `json:"memory_unlimited,omitempty"`   // Disable Docker memory limit
 	CPUUnlimited     bool          `json:"cpu_unlimited,omitempty"`      // Disable Docker CPU quota
+	ResourceProfile  string        `json:"resource_profile,omitempty"`   // Named CPU/memory preset; explicit limits override it
 	GPUEnabled       bool          `json:"gpu_enabled,omitempty"`        // Enable GPU passthrough
 	GPUCount         int           `json:"gpu_count,omitempty"`          // -1 means all GPUs
 	PortMappings     []PortMapping `json:"port_mappings,omitempty"`      // Legacy port mappings
-	EnableCodeServer bool          `json:"enable_code_server,omitempty"` // Enable code-server (Web VS Code)
-	Proxy            ProxyConfig   `json:"proxy,omitempty"`              // Traefik proxy configuration
+	EnableCodeServer *bool         `json:"enable_code_server,omitempty"` // Enable code-server (nil = the container profile's setting, else off)
+	CodeServerAuth   string        `json:"code_server_auth,omitempty"`   // "none" (default) or "password"
+	// CodeServerExtensions are extension IDs (publisher.name[@version]) installed after code-server starts
+	CodeServerExtensions []string    `json:"code_server_extensions,omitempty"`
+	Proxy                ProxyConfig `json:"proxy,omitempty"` // Traefik proxy configuration
 	// Configuration profile references (nil = use default)
 	GitHubTokenID           *uint `json:"github_token_id,omitempty"`
 	EnvVarsProfileID        *uint `json:"env_vars_profile_id,omitempty"`
@@ -234,14 +298,132 @@ type CreateContainerInput struct {
 	SelectedCodexAuths   []uint `json:"selected_codex_auths,omitempty"`   // Multiple Codex Auth template IDs (optional)
 	SelectedGeminiEnvs   []uint `json:"selected_gemini_envs,omitempty"`   // Multiple Gemini Env template IDs (optional)
 	AutoInjectAllSkills  bool   `json:"auto_inject_all_skills,omitempty"` // Automatically inject all skill templates
+	// PreInitScript is a bash script run after clone and before Claude init, as the container user
+	PreInitScript string `json:"pre_init_script,omitempty"`
+	// InitCallbackURL receives a signed POST when initialization becomes ready or failed
+	InitCallbackURL string `json:"init_callback_url,omitempty"`
+	// HostMounts are host directories to bind-mount; requires HOST_MOUNTS_ENABLED and the allowlist
+	HostMounts []HostMountInput `json:"host_mounts,omitempty"`
+	// RestartPolicy is "no" (default), "on-failure" or "always"; RestartMaxRetries limits on-failure restarts
+	RestartPolicy     string `json:"restart_policy,omitempty"`
+	RestartMaxRetries int    `json:"restart_max_retries,omitempty"`
+	// TTLMinutes deletes the container this many minutes after creation; 0 keeps it until deleted
+	TTLMinutes int `json:"ttl_minutes,omitempty"`
+	// Image replaces the platform base image; its registry must be in REGISTRY_ALLOWLIST
+	Image string `json:"image,omitempty"`
+	// ClaudeVersion installs this exact Claude CLI version during init instead of the image's
+	ClaudeVersion string `json:"claude_version,omitempty"`
+	// Claude overrides replace the env profile's ANTHROPIC_BASE_URL, ANTHROPIC_MODEL and credential
+	ClaudeAPIBase   string `json:"claude_api_base,omitempty"`
+	ClaudeModel     string `json:"claude_model,omitempty"`
+	ClaudeAuthToken string `json:"claude_auth_token,omitempty"`
+	// CloneLFS pulls Git LFS files after the clone when the repository uses LFS (nil = yes)
+	CloneLFS *bool `json:"clone_lfs,omitempty"`
+	// ProfileID names a container profile whose defaults fill the fields left empty
+	ProfileID *uint `json:"profile_id,omitempty"`
+
+	// templateIDs are template selections restored from an existing container on recreate
+	templateIDs []uint
+	// notes and metadata are the details restored from an existing container on recreate
+	notes    string
+	metadata models.ContainerMetadata
+}
+
+// codeServerEnabled reports whether the container runs code-server
+func (input CreateContainerInput) codeServerEnabled() bool {
+	return input.EnableCodeServer != nil && *input.EnableCodeServer
 }
 
 // CreateContainer creates a new container and automatically starts initialization
 func (s *ContainerService) CreateContainer(ctx context.Context, input CreateContainerInput) (*models.Container, error) {
+	return s.createContainer(ctx, input, nil)
+}
+
+// createContainer creates the Docker container and its record. When replace is set, its
+// database row is overwritten instead of inserting a new one, so the container keeps its ID.
+func (s *ContainerService) createContainer(ctx context.Context, input CreateContainerInput, replace *models.Container) (*models.Container, error) {
+	if err := s.applyContainerProfile(&input); err != nil {
+		return nil, err
+	}
+	if err := validateYoloConfirmation(input); err != nil {
+		return nil, err
+	}
+	if err := validatePreInitScriptConfirmation(input); err != nil {
+		return nil, err
+	}
 	// Validate container name
 	if err := validateContainerName(input.Name); err != nil {
 		return nil, err
 	}
+	if err := validatePreInitScript(input.PreInitScript); err != nil {
+		return nil, err
+	}
+	initStrategy, err := resolveInitStrategy(input.InitStrategy, input.SkipClaudeInit, input.PreInitScript)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkInitCallbackURL(input.InitCallbackURL); err != nil {
+		return nil, err
+	}
+	var hostMountsEnabled bool
+	var hostMountAllowlist []string
+	if s.config != nil {
+		hostMountsEnabled = s.config.HostMountsEnabled
+		hostMountAllowlist = s.config.HostMountAllowlist
+	}
+	hostMounts, err := validateHostMounts(input.HostMounts, hostMountsEnabled, hostMountAllowlist)
+	if err != nil {
+		return nil, err
+	}
+	restartPolicy, restartMaxRetries, err := normalizeRestartPolicy(input.RestartPolicy, input.RestartMaxRetries)
+	if err != nil {
+		return nil, err
+	}
+	runAsUser, err := normalizeRunAsUser(input.RunAsUser, input.RunAsRoot)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateContainerTTL(input.TTLMinutes); err != nil {
+		return nil, err
+	}
+	image, err := normalizeContainerImage(input.Image, s.registryAllowlist())
+	if err != nil {
+		return nil, err
+	}
+	claudeVersion, err := normalizeClaudeVersion(input.ClaudeVersion)
+	if err != nil {
+		return nil, err
+	}
+	input.ClaudeAPIBase, input.ClaudeModel, input.ClaudeAuthToken, err = normalizeClaudeOverrides(input.ClaudeAPIBase, input.ClaudeModel, input.ClaudeAuthToken)
+	if err != nil {
+		return nil, err
+	}
+	claudeAuthToken, err := s.encryptClaudeAuthToken(input.ClaudeAuthToken)
+	if err != nil {
+		return nil, err
+	}
+	codeServerAuth, err := normalizeCodeServerAuth(input.CodeServerAuth)
+	if err != nil {
+		return nil, err
+	}
+	codeServerExtensions, err := normalizeCodeServerExtensions(input.CodeServerExtensions, input.codeServerEnabled())
+	if err != nil {
+		return nil, err
+	}
+	templateWarnings, err := s.precheckTemplateSelections(input)
+	if err != nil {
+		return nil, err
+	}
+	var codeServerPassword string
+	if input.codeServerEnabled() && codeServerAuth == CodeServerAuthPassword {
+		if _, codeServerPassword, err = s.newCodeServerPassword(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.applyResourceProfile(&input); err != nil {
+		return nil, err
+	}
 
 	effectiveCPULimit := input.CPULimit
 	if input.CPUUnlimited {
@@ -262,72 +444,29 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 		return nil, fmt.Errorf("resource validation failed: %w", err)
 	}
 
-	// Validate GitRepoURL is required when SkipGitRepo is false
-	if !input.SkipGitRepo && input.GitRepoURL == "" {
-		return nil, fmt.Errorf("git_repo_url is required when skip_git_repo is false")
-	}
-
-	// Check if GitHub token is configured (only required when not skipping git repo)
-	hasTokens := s.configProfileService.HasGitHubTokens()
-	if !input.SkipGitRepo && !hasTokens && !s.githubService.HasToken() {
-		return nil, ErrNoGitHubTokenConfigured
-	}
-
-	// Extract repo name from URL if not provided (only when not skipping git repo)
+	// Validate GitRepoURL is required when SkipGitRepo is false, and normalize it
 	repoName := input.GitRepoName
-	if !input.SkipGitRepo && repoName == "" {
-		repoName = extractRepoName(input.GitRepoURL)
-	}
-
-	// Get environment variables from profile (or legacy config as fallback)
-	var envVars map[string]string
-	var err error
-	if s.configProfileService != nil {
-		envVars, err = s.configProfileService.GetEnvVars(input.EnvVarsProfileID)
-		if err != nil {
-			// Fallback to legacy config
-			envVars, err = s.claudeService.GetContainerEnvVars()
-			if err != nil {
-				return nil, err
-			}
+	if !input.SkipGitRepo {
+		if input.GitRepoURL == "" {
+			return nil, fmt.Errorf("git_repo_url is required when skip_git_repo is false")
 		}
-	} else {
-		envVars, err = s.claudeService.GetContainerEnvVars()
+		input.GitRepoURL, repoName, err = s.resolveGitRepo(input.GitRepoURL, input.GitRepoName)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// Get GitHub token from profile (or legacy config as fallback) - only when not skipping git repo
-	if !input.SkipGitRepo {
-		var githubToken string
-		if s.configProfileService != nil && hasTokens {
-			githubToken, err = s.configProfileService.GetGitHubTokenForContainer(input.GitHubTokenID)
-			if err != nil {
-				// Fallback to legacy token
-				githubToken, err = s.githubService.GetToken()
-				if err != nil {
-					return nil, err
-				}
-			}
-		} else {
-			githubToken, err = s.githubService.GetToken()
-			if err != nil {
-				return nil, err
-			}
-		}
-		envVars["GITHUB_TOKEN"] = githubToken
+	// Check if GitHub token is configured (only required when not skipping git repo)
+	hasTokens := s.configProfileService.HasGitHubTokens()
+	if !input.SkipGitRepo && !hasTokens && !s.githubService.HasToken() {
+		return nil, ErrNoGitHubTokenConfigured
 	}
 
-	// Convert env vars map to slice
-	envSlice := make([]string, 0, len(envVars))
-	for k, v := range envVars {
-		envSlice = append(envSlice, fmt.Sprintf("%s=%s", k, v))
+	envSlice, err := s.dockerEnv(input, runAsUser, hasTokens)
+	if err != nil {
+		return nil, err
 	}
 
-	// 添加 CLAUDE_CODE_ALLOW_ROOT=1 环境变量，允许 root 用户使用 yolo 模式
-	envSlice = append(envSlice, "CLAUDE_CODE_ALLOW_ROOT=1")
-
 	// Get security config
 	securityConfig := docker.DefaultSecurityConfig()
 
@@ -361,7 +500,7 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 		// So 1 CPU = 100000, 0.5 CPU = 50000, 2 CPU = 200000
 		securityConfig.Resources.CPUQuota = int64(input.CPULimit * 100000)
 		securityConfig.Resources.CPUPeriod = CPUPeriodDefault
-		log.Printf("Applying resource limits: Memory=%dMB, CPUCores=%.2f, CPUQuota=%d, CPUPeriod=%d",
+		logging.FromContext(ctx, "ContainerService").Printf("Applying resource limits: Memory=%dMB, CPUCores=%.2f, CPUQuota=%d, CPUPeriod=%d",
 			input.MemoryLimit, input.CPULimit, securityConfig.Resources.CPUQuota, securityConfig.Resources.CPUPeriod)
 	}
 	if input.MemoryUnlimited || input.CPUUnlimited {
@@ -391,27 +530,28 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 	// 2. Otherwise: use direct port mapping
 	codeServerHostPort := 0
 	codeServerDomain := ""
-	useSubdomainRouting := s.config.CodeServerBaseDomain != "" && input.EnableCodeServer
+	useSubdomainRouting := s.config.CodeServerBaseDomain != "" && input.codeServerEnabled()
 
-	if input.EnableCodeServer {
+	if input.codeServerEnabled() {
 		if useSubdomainRouting {
 			// Subdomain routing: {container-name}.{base-domain}
 			codeServerDomain = fmt.Sprintf("%s.%s", input.Name, s.config.CodeServerBaseDomain)
-			log.Printf("code-server subdomain routing: %s -> container:%d", codeServerDomain, CodeServerInternalPort)
+			logging.FromContext(ctx, "ContainerService").Printf("code-server subdomain routing: %s -> container:%d", codeServerDomain, CodeServerInternalPort)
 		} else {
 			// Direct port mapping fallback
-			for port := 18443; port <= 18543; port++ {
-				if isPortFree(port) {
-					codeServerHostPort = port
-					break
-				}
+			var excludeID uint
+			if replace != nil {
+				excludeID = replace.ID
 			}
-			if codeServerHostPort > 0 {
-				portBindings[fmt.Sprintf("%d/tcp", CodeServerInternalPort)] = fmt.Sprintf("%d", codeServerHostPort)
-				log.Printf("code-server port mapping: container:%d -> host:%d", CodeServerInternalPort, codeServerHostPort)
-			} else {
-				log.Printf("Warning: could not find free port for code-server")
+			port, err := s.allocateCodeServerPort(excludeID)
+			if err != nil {
+				return nil, err
 			}
+			// Reserved until the container record holding the port is saved
+			defer s.releaseCodeServerPort(port)
+			codeServerHostPort = port
+			portBindings[fmt.Sprintf("%d/tcp", CodeServerInternalPort)] = fmt.Sprintf("%d", codeServerHostPort)
+			logging.FromContext(ctx, "ContainerService").Printf("code-server port mapping: container:%d -> host:%d", CodeServerInternalPort, codeServerHostPort)
 		}
 	}
 
@@ -421,8 +561,15 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 	// Add cc-platform identifier label
 	labels["cc-platform.managed"] = "true"
 
-	// Connect to traefik-net if proxy or subdomain routing is enabled
-	useTraefikNet := input.Proxy.Enabled || useSubdomainRouting
+	// Connect to traefik-net if proxy or subdomain routing is enabled. Without Traefik the
+	// container is still created, with its proxy pending until the reconciler activates it.
+	useTraefikNet := input.Proxy.enabled() || useSubdomainRouting
+	var proxyStatus, proxyStatusReason string
+	if useTraefikNet {
+		var networkExists bool
+		proxyStatus, proxyStatusReason, networkExists = s.traefikRouting(ctx)
+		useTraefikNet = networkExists
+	}
 
 	// Add code-server subdomain routing labels
 	if useSubdomainRouting {
@@ -440,7 +587,7 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 	}
 
 	// Add user-defined proxy labels if enabled
-	if input.Proxy.Enabled && input.Proxy.ServicePort > 0 {
+	if input.Proxy.enabled() && input.Proxy.ServicePort > 0 {
 		serviceName := fmt.Sprintf("cc-%s", input.Name)
 		labels["traefik.enable"] = "true"
 
@@ -465,12 +612,17 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 		labels[fmt.Sprintf("traefik.http.services.%s.loadbalancer.server.port", serviceName)] = fmt.Sprintf("%d", input.Proxy.ServicePort)
 	}
 
+	cacheRepoURL := input.GitRepoURL
+	if input.SkipGitRepo {
+		cacheRepoURL = ""
+	}
+
 	// Create container config.
 	// 工作目录和依赖缓存都落到 managed volume，避免重复初始化把数据写爆到容器可写层。
 	containerConfig := &docker.ContainerConfig{
 		Name:          input.Name,
 		EnvVars:       envSlice,
-		Binds:         buildManagedContainerBinds(input.Name),
+		Binds:         append(append(buildManagedContainerBinds(input.Name), hostMountBinds(hostMounts)...), s.repoCacheBinds(cacheRepoURL)...),
 		WorkingDir:    workDir,
 		SecurityOpt:   securityConfig.SecurityOpt,
 		CapDrop:       securityConfig.CapDrop,
@@ -480,14 +632,34 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 		PortBindings:  portBindings,
 		Labels:        labels,
 		UseTraefikNet: useTraefikNet,
-		UseCodeServer: input.EnableCodeServer,
+		UseCodeServer: input.codeServerEnabled(),
 		RunAsRoot:     input.RunAsRoot,
+		RunAsUser:     runAsUser,
+		RestartPolicy: dockerRestartPolicy(restartPolicy, restartMaxRetries),
+		Image:         image,
 	}
 
-	// Create Docker container
-	dockerID, err := s.dockerClient.CreateContainer(ctx, containerConfig)
-	if err != nil {
-		return nil, err
+	// A request with the default settings takes an idle warm pool container when one is ready
+	var warm warmContainer
+	fromPool := false
+	if replace == nil && warmPoolEligible(input, image, hostMounts, restartPolicy, runAsUser) {
+		warm, fromPool = s.claimWarmContainer(ctx, input.Name, envSlice)
+	}
+
+	dockerID := warm.DockerID
+	if !fromPool {
+		// Custom images may live in a private registry, so pull them with the stored login
+		if image != "" {
+			if err := s.pullContainerImage(ctx, image); err != nil {
+				return nil, err
+			}
+		}
+
+		// Create Docker container
+		dockerID, err = s.dockerClient.CreateContainer(ctx, containerConfig)
+		if err != nil {
+			return nil, dockerError(err)
+		}
 	}
 
 	// Serialize port mappings to JSON for storage
@@ -495,7 +667,7 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 	if len(input.PortMappings) > 0 {
 		jsonBytes, err := json.Marshal(input.PortMappings)
 		if err != nil {
-			log.Printf("Warning: failed to marshal port mappings: %v", err)
+			logging.FromContext(ctx, "ContainerService").Printf("Warning: failed to marshal port mappings: %v", err)
 		} else {
 			portMappingsJSON = string(jsonBytes)
 		}
@@ -515,15 +687,18 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 	dbContainer := &models.Container{
 		DockerID:                dockerID,
 		Name:                    input.Name,
+		PoolName:                warm.Name,
 		Status:                  models.ContainerStatusCreated,
 		InitStatus:              models.InitStatusPending,
 		GitRepoURL:              input.GitRepoURL,
 		GitRepoName:             repoName,
 		WorkDir:                 workDir,
-		SkipClaudeInit:          input.SkipClaudeInit,
+		SkipClaudeInit:          initStrategy == InitStrategyScript || initStrategy == InitStrategyNone,
+		InitStrategy:            initStrategy,
 		SkipGitRepo:             input.SkipGitRepo,
 		EnableYoloMode:          input.EnableYoloMode,
 		RunAsRoot:               input.RunAsRoot,
+		RunAsUser:               runAsUser,
 		AutoInjectAllSkills:     input.AutoInjectAllSkills,
 		MemoryLimit:             memoryLimit * 1024 * 1024, // Store in bytes
 		MemoryUnlimited:         input.MemoryUnlimited,
@@ -532,39 +707,86 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 		GPUEnabled:              input.GPUEnabled,
 		GPUCount:                normalizedGPUCount,
 		ExposedPorts:            portMappingsJSON,
-		ProxyEnabled:            input.Proxy.Enabled,
+		ProxyEnabled:            input.Proxy.enabled(),
 		ProxyDomain:             input.Proxy.Domain,
 		ProxyPort:               input.Proxy.Port,
 		ServicePort:             input.Proxy.ServicePort,
-		EnableCodeServer:        input.EnableCodeServer,
+		ProxyStatus:             proxyStatus,
+		ProxyStatusReason:       proxyStatusReason,
+		EnableCodeServer:        input.codeServerEnabled(),
 		CodeServerPort:          CodeServerInternalPort, // Store container internal port (8443)
-		CodeServerDomain:        codeServerDomain,       // Subdomain for code-server (e.g., "mycontainer.code.example.com")
+		CodeServerHostPort:      codeServerHostPort,
+		CodeServerDomain:        codeServerDomain, // Subdomain for code-server (e.g., "mycontainer.code.example.com")
+		CodeServerAuth:          codeServerAuth,
+		CodeServerPassword:      codeServerPassword,
+		CodeServerExtensions:    codeServerExtensions,
 		GitHubTokenID:           input.GitHubTokenID,
 		EnvVarsProfileID:        input.EnvVarsProfileID,
 		StartupCommandProfileID: input.StartupCommandProfileID,
+		PreInitScript:           input.PreInitScript,
+		InitCallbackURL:         input.InitCallbackURL,
+		TTLMinutes:              input.TTLMinutes,
+		ExpiresAt:               containerExpiresAt(time.Now(), input.TTLMinutes),
+		HostMounts:              hostMounts,
+		RestartPolicy:           restartPolicy,
+		RestartMaxRetries:       restartMaxRetries,
+		Image:                   image,
+		ClaudeVersionPin:        claudeVersion,
+		CloneLFS:                input.CloneLFS,
+		ClaudeAPIBase:           input.ClaudeAPIBase,
+		ClaudeModel:             input.ClaudeModel,
+		ClaudeAuthToken:         claudeAuthToken,
+	}
+
+	// Remember explicit template selections so the container can be recreated with them
+	var selectedTemplateIDs []uint
+	if input.SelectedClaudeMD != nil {
+		selectedTemplateIDs = append(selectedTemplateIDs, *input.SelectedClaudeMD)
+	}
+	selectedTemplateIDs = append(selectedTemplateIDs, input.SelectedSkills...)
+	selectedTemplateIDs = append(selectedTemplateIDs, input.SelectedMCPs...)
+	selectedTemplateIDs = append(selectedTemplateIDs, input.SelectedCommands...)
+	selectedTemplateIDs = append(selectedTemplateIDs, input.SelectedCodexConfigs...)
+	selectedTemplateIDs = append(selectedTemplateIDs, input.SelectedCodexAuths...)
+	selectedTemplateIDs = append(selectedTemplateIDs, input.SelectedGeminiEnvs...)
+	selectedTemplateIDs = append(selectedTemplateIDs, input.templateIDs...)
+	dbContainer.SelectedTemplateIDs = dedupeUintSlice(selectedTemplateIDs)
+	dbContainer.Notes = input.notes
+	dbContainer.Metadata = input.metadata
+
+	var saveErr error
+	if replace != nil {
+		dbContainer.ID = replace.ID
+		dbContainer.CreatedAt = replace.CreatedAt
+		saveErr = s.db.Save(dbContainer).Error
+	} else {
+		saveErr = s.db.Create(dbContainer).Error
 	}
-
-	if err := s.db.Create(dbContainer).Error; err != nil {
+	if err := saveErr; err != nil {
 		// Cleanup Docker container on DB error
 		s.dockerClient.RemoveContainer(ctx, dockerID, true)
-		_ = s.dockerClient.RemoveVolumes(ctx, managedPerContainerVolumes(input.Name)...)
+		_ = s.dockerClient.RemoveVolumes(ctx, managedPerContainerVolumes(volumeContainerName(dbContainer))...)
 		return nil, err
 	}
 
+	if fromPool {
+		s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, fmt.Sprintf("Claimed warm pool container %s", warm.Name))
+	}
+
 	// Add initial log
 	if input.SkipGitRepo {
-		s.addLog(dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, "Container created without GitHub repository (empty container)")
+		s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, "Container created without GitHub repository (empty container)")
 	} else {
-		s.addLog(dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, fmt.Sprintf("Container created for repository: %s", input.GitRepoURL))
+		s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, fmt.Sprintf("Container created for repository: %s", input.GitRepoURL))
 	}
 	if len(input.PortMappings) > 0 {
 		portInfo := make([]string, len(input.PortMappings))
 		for i, pm := range input.PortMappings {
 			portInfo[i] = fmt.Sprintf("%d->%d", pm.ContainerPort, pm.HostPort)
 		}
-		s.addLog(dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, fmt.Sprintf("Port mappings: %s", strings.Join(portInfo, ", ")))
+		s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, fmt.Sprintf("Port mappings: %s", strings.Join(portInfo, ", ")))
 	}
-	if input.Proxy.Enabled {
+	if input.Proxy.enabled() {
 		proxyInfo := fmt.Sprintf("Proxy enabled: service port %d", input.Proxy.ServicePort)
 		if input.Proxy.Domain != "" {
 			proxyInfo += fmt.Sprintf(", domain: %s", input.Proxy.Domain)
@@ -572,40 +794,57 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 		if input.Proxy.Port > 0 {
 			proxyInfo += fmt.Sprintf(", direct port: %d", input.Proxy.Port)
 		}
-		s.addLog(dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, proxyInfo)
+		s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, proxyInfo)
+	}
+	if proxyStatus == models.ProxyStatusPending {
+		s.addLog(ctx, dbContainer.ID, models.LogLevelWarn, models.LogStageStartup, "Proxy pending: "+proxyStatusReason)
 	}
-	s.addLog(dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, fmt.Sprintf("Resources: Memory=%dMB, CPU=%.1f cores", memoryLimit, cpuLimit))
+	s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, fmt.Sprintf("Resources: Memory=%dMB, CPU=%.1f cores", memoryLimit, cpuLimit))
 
 	// Log YOLO mode if enabled
 	if input.EnableYoloMode {
-		s.addLog(dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, "YOLO mode enabled (--dangerously-skip-permissions)")
+		s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, "YOLO mode enabled (--dangerously-skip-permissions)")
+	}
+
+	// The pre-init script runs arbitrary commands, so record that one is configured
+	if strings.TrimSpace(input.PreInitScript) != "" {
+		s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup,
+			fmt.Sprintf("Pre-init script configured (%d bytes)", len(input.PreInitScript)))
+	}
+	s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup, fmt.Sprintf("Init strategy: %s", initStrategy))
+	for _, warning := range templateWarnings {
+		s.addLog(ctx, dbContainer.ID, models.LogLevelWarn, models.LogStageStartup, fmt.Sprintf("Template selection: %s", warning))
+	}
+
+	// Log host mounts, since they expose host files to the container
+	for _, m := range hostMounts {
+		mode := "read-write"
+		if m.ReadOnly {
+			mode = "read-only"
+		}
+		s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup,
+			fmt.Sprintf("Host mount: %s -> %s (%s)", m.HostPath, m.ContainerPath, mode))
 	}
 
 	// Log code-server if enabled
-	if input.EnableCodeServer {
+	if input.codeServerEnabled() {
 		if useSubdomainRouting {
 			// Add code-server port to ports table (using internal port for subdomain routing)
 			portService := NewPortService(s.db)
 			portService.AddPort(dbContainer.ID, CodeServerInternalPort, "VS Code", "http", true)
-			s.addLog(dbContainer.ID, models.LogLevelInfo, models.LogStageStartup,
+			s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup,
 				fmt.Sprintf("code-server: http://%s (subdomain routing via Traefik)", codeServerDomain))
-		} else if codeServerHostPort > 0 {
+		} else {
 			// Add code-server port to ports table
 			portService := NewPortService(s.db)
 			portService.AddPort(dbContainer.ID, codeServerHostPort, "VS Code", "http", true)
-			s.addLog(dbContainer.ID, models.LogLevelInfo, models.LogStageStartup,
+			s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup,
 				fmt.Sprintf("code-server: http://server-ip:%d", codeServerHostPort))
-		} else {
-			s.addLog(dbContainer.ID, models.LogLevelWarn, models.LogStageStartup,
-				"code-server enabled but no free port available")
 		}
 	}
 
 	// Collect all template IDs for config injection
-	var templateIDs []uint
-	if input.SelectedClaudeMD != nil {
-		templateIDs = append(templateIDs, *input.SelectedClaudeMD)
-	}
+	templateIDs := append([]uint(nil), dbContainer.SelectedTemplateIDs...)
 	if input.AutoInjectAllSkills {
 		autoSkillIDs, err := s.getTemplateIDsByType(models.ConfigTypeSkill)
 		if err != nil {
@@ -613,19 +852,13 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 		}
 		templateIDs = append(templateIDs, autoSkillIDs...)
 	}
-	templateIDs = append(templateIDs, input.SelectedSkills...)
-	templateIDs = append(templateIDs, input.SelectedMCPs...)
-	templateIDs = append(templateIDs, input.SelectedCommands...)
-	templateIDs = append(templateIDs, input.SelectedCodexConfigs...)
-	templateIDs = append(templateIDs, input.SelectedCodexAuths...)
-	templateIDs = append(templateIDs, input.SelectedGeminiEnvs...)
 
 	templateIDs = dedupeUintSlice(templateIDs)
 
 	// Store template IDs for initialization (will be retrieved during runInitialization)
 	if len(templateIDs) > 0 {
 		s.pendingTemplateIDs.Store(dbContainer.ID, templateIDs)
-		s.addLog(dbContainer.ID, models.LogLevelInfo, models.LogStageStartup,
+		s.addLog(ctx, dbContainer.ID, models.LogLevelInfo, models.LogStageStartup,
 			fmt.Sprintf("Claude config templates selected: %d template(s)", len(templateIDs)))
 	}
 
@@ -635,12 +868,13 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 		defer s.wg.Done()
 		select {
 		case <-s.ctx.Done():
-			log.Printf("Container %d auto-start cancelled: service shutting down", dbContainer.ID)
+			s.logger(ctx, dbContainer.ID).Printf("Container auto-start cancelled: service shutting down")
 			return
 		default:
 		}
-		if err := s.startAndInitialize(dbContainer.ID); err != nil {
-			log.Printf("Failed to auto-start container %d: %v", dbContainer.ID, err)
+		// Detach from the request's cancellation but keep its request ID for logging
+		if err := s.startAndInitialize(context.WithoutCancel(ctx), dbContainer.ID); err != nil {
+			s.logger(ctx, dbContainer.ID).Printf("Failed to auto-start container: %v", err)
 		}
 	}()
 
@@ -648,25 +882,30 @@ func (s *ContainerService) CreateContainer(ctx context.Context, input CreateCont
 }
 
 // startAndInitialize starts the container and runs initialization
-func (s *ContainerService) startAndInitialize(containerID uint) error {
-	ctx := context.Background()
+func (s *ContainerService) startAndInitialize(ctx context.Context, containerID uint) error {
 
 	container, err := s.GetContainer(containerID)
 	if err != nil {
 		return err
 	}
 
+	// Each start records a fresh set of stage timings
+	timing := s.newInitTimingRecorder(containerID)
+
 	// Log startup
-	s.addLog(containerID, models.LogLevelInfo, models.LogStageStartup, "Starting container...")
+	s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageStartup, "Starting container...")
+	startupStage := timing.begin(models.InitStageStartup)
 
 	// Start the container
 	if err := s.dockerClient.StartContainer(ctx, container.DockerID); err != nil {
-		s.addLog(containerID, models.LogLevelError, models.LogStageStartup, fmt.Sprintf("Failed to start container: %v", err))
-		s.updateInitStatus(containerID, models.InitStatusFailed, fmt.Sprintf("Failed to start: %v", err))
+		timing.end(startupStage, models.InitStageStatusFailed)
+		s.addLog(ctx, containerID, models.LogLevelError, models.LogStageStartup, fmt.Sprintf("Failed to start container: %v", err))
+		s.updateInitStatus(ctx, containerID, models.InitStatusFailed, fmt.Sprintf("Failed to start: %v", err))
 		return err
 	}
 
-	s.addLog(containerID, models.LogLevelInfo, models.LogStageStartup, "Container started successfully")
+	timing.end(startupStage, models.InitStageStatusSucceeded)
+	s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageStartup, "Container started successfully")
 
 	// Update status
 	now := time.Now()
@@ -678,56 +917,124 @@ func (s *ContainerService) startAndInitialize(containerID uint) error {
 	}
 
 	// Run initialization
-	s.runInitialization(containerID)
+	s.runInitialization(ctx, containerID, timing)
 	return nil
 }
 
-// runInitialization runs the container initialization process in background
-func (s *ContainerService) runInitialization(containerID uint) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-	defer cancel()
-
-	// Store cancel function for potential cancellation
-	s.initTasks.Store(containerID, cancel)
-	defer s.initTasks.Delete(containerID)
+// runInitialization runs the container initialization process in background,
+// recording the duration of each stage in timing
+func (s *ContainerService) runInitialization(parent context.Context, containerID uint, timing *initTimingRecorder) {
+	// Register the run so stopping or deleting the container cancels it
+	ctx, done, err := s.startInitTask(parent, containerID, 30*time.Minute)
+	if err != nil {
+		s.logger(parent, containerID).Printf("Init skipped: %v", err)
+		return
+	}
+	defer done()
 
 	container, err := s.GetContainer(containerID)
 	if err != nil {
-		s.addLog(containerID, models.LogLevelError, models.LogStageInit, fmt.Sprintf("Failed to get container: %v", err))
-		log.Printf("Init error: failed to get container %d: %v", containerID, err)
+		s.addLog(ctx, containerID, models.LogLevelError, models.LogStageInit, fmt.Sprintf("Failed to get container: %v", err))
+		s.logger(ctx, containerID).Printf("Init error: failed to get container: %v", err)
 		return
 	}
 
-	// Step 0: Inject Claude Code configurations (if any templates were selected)
+	// Give a custom run-as user a home before anything is cloned or injected into it
+	if container.RunAsUser != "" {
+		if _, err := s.dockerClient.ExecAsRoot(ctx, container.DockerID, runAsUserHomeCommand(container.RunAsUser)); err != nil {
+			s.addLog(ctx, containerID, models.LogLevelWarn, models.LogStageInit, fmt.Sprintf("Warning: Failed to prepare home for user %s: %v", container.RunAsUser, err))
+		}
+	}
+
+	// Step 1: Clone repository or create default /app directory
+	cloneStage := timing.begin(models.InitStageClone)
+	if container.SkipGitRepo {
+		// Create default /app working directory for empty containers
+		// Use root user to create directory, then change ownership to the container user
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageClone, "Creating default /app working directory...")
+		s.updateInitStatus(ctx, containerID, models.InitStatusCloning, "Creating working directory...")
+
+		appOwner := "developer:developer"
+		if container.RunAsUser != "" {
+			appOwner = container.RunAsUser
+		}
+		createDirCmd := []string{
+			"bash", "-c",
+			fmt.Sprintf("mkdir -p /app && chown %s /app && chmod 755 /app", appOwner),
+		}
+		// Use ExecAsRoot to ensure we have permission to create directory in /
+		if _, err := s.dockerClient.ExecAsRoot(ctx, container.DockerID, createDirCmd); err != nil {
+			timing.end(cloneStage, models.InitStageStatusFailed)
+			s.addLog(ctx, containerID, models.LogLevelError, models.LogStageClone, fmt.Sprintf("Failed to create /app directory: %v", err))
+			s.updateInitStatus(ctx, containerID, models.InitStatusFailed, fmt.Sprintf("Failed to create /app directory: %v", err))
+			return
+		}
+
+		// Also fix npm cache permissions to prevent cache corruption issues
+		fixNpmCmd := []string{
+			"bash", "-c",
+			"mkdir -p /home/developer/.npm && chown -R developer:developer /home/developer/.npm",
+		}
+		if _, err := s.dockerClient.ExecAsRoot(ctx, container.DockerID, fixNpmCmd); err != nil {
+			s.addLog(ctx, containerID, models.LogLevelWarn, models.LogStageClone, fmt.Sprintf("Warning: Failed to fix npm cache permissions: %v", err))
+			// Don't fail, just log warning
+		}
+
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageClone, "Default /app working directory created successfully")
+	} else {
+		// Clone repository
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageClone, fmt.Sprintf("Cloning repository: %s", container.GitRepoURL))
+		s.updateInitStatus(ctx, containerID, models.InitStatusCloning, "Cloning repository...")
+
+		if err := s.cloneRepository(ctx, container); err != nil {
+			timing.end(cloneStage, models.InitStageStatusFailed)
+			s.addLog(ctx, containerID, models.LogLevelError, models.LogStageClone, fmt.Sprintf("Clone failed: %v", err))
+			s.updateInitStatus(ctx, containerID, models.InitStatusFailed, fmt.Sprintf("Clone failed: %v", err))
+			return
+		}
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageClone, "Repository cloned successfully")
+		s.pullGitLFS(ctx, container, "/workspace/"+container.GitRepoName)
+	}
+	timing.end(cloneStage, models.InitStageStatusSucceeded)
+
+	// Step 1.2: Inject Claude Code configurations (if any templates were selected).
+	// This runs after the clone so project-scoped MCP servers can be written to the work directory.
 	if templateIDsVal, ok := s.pendingTemplateIDs.LoadAndDelete(containerID); ok {
 		templateIDs := templateIDsVal.([]uint)
 		if len(templateIDs) > 0 && s.configInjectionService != nil {
-			s.addLog(containerID, models.LogLevelInfo, models.LogStageInit,
+			s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit,
 				fmt.Sprintf("Injecting %d Claude config template(s)...", len(templateIDs)))
-			s.updateInitStatus(containerID, models.InitStatusInitializing, "Injecting Claude configurations...")
+			s.updateInitStatus(ctx, containerID, models.InitStatusInitializing, "Injecting Claude configurations...")
 
-			injectionStatus, err := s.configInjectionService.InjectConfigs(ctx, container.DockerID, templateIDs)
+			injectStage := timing.begin(models.InitStageInject)
+			injectionStatus, err := s.configInjectionService.InjectConfigs(ctx, container.DockerID, container.WorkDir, templateIDs)
 			if err != nil {
-				s.addLog(containerID, models.LogLevelError, models.LogStageInit,
+				timing.end(injectStage, models.InitStageStatusFailed)
+				s.addLog(ctx, containerID, models.LogLevelError, models.LogStageInit,
 					fmt.Sprintf("Config injection error: %v", err))
 				// Don't fail initialization, just log the error
+			} else {
+				timing.end(injectStage, models.InitStageStatusSucceeded)
 			}
 
 			// Store injection status in container record
 			if injectionStatus != nil {
 				if err := s.db.Model(&models.Container{}).Where("id = ?", containerID).
 					Update("injection_status", injectionStatus).Error; err != nil {
-					log.Printf("Failed to store injection status for container %d: %v", containerID, err)
+					s.logger(ctx, containerID).Printf("Failed to store injection status: %v", err)
+				}
+				if err := recordTemplateInjections(s.db, injectionStatus.SuccessfulIDs); err != nil {
+					s.logger(ctx, containerID).Printf("Failed to record template injections: %v", err)
 				}
 
 				// Log injection results
 				if len(injectionStatus.Successful) > 0 {
-					s.addLog(containerID, models.LogLevelInfo, models.LogStageInit,
+					s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit,
 						fmt.Sprintf("Successfully injected configs: %v", injectionStatus.Successful))
 				}
 				if len(injectionStatus.Failed) > 0 {
 					for _, failed := range injectionStatus.Failed {
-						s.addLog(containerID, models.LogLevelWarn, models.LogStageInit,
+						s.addLog(ctx, containerID, models.LogLevelWarn, models.LogStageInit,
 							fmt.Sprintf("Failed to inject config '%s' (%s): %s",
 								failed.TemplateName, failed.ConfigType, failed.Reason))
 					}
@@ -736,105 +1043,100 @@ func (s *ContainerService) runInitialization(containerID uint) {
 		}
 	}
 
-	// Step 1: Clone repository or create default /app directory
-	if container.SkipGitRepo {
-		// Create default /app working directory for empty containers
-		// Use root user to create directory, then change ownership to developer
-		s.addLog(containerID, models.LogLevelInfo, models.LogStageClone, "Creating default /app working directory...")
-		s.updateInitStatus(containerID, models.InitStatusCloning, "Creating working directory...")
-
-		createDirCmd := []string{
-			"bash", "-c",
-			"mkdir -p /app && chown developer:developer /app && chmod 755 /app",
-		}
-		// Use ExecAsRoot to ensure we have permission to create directory in /
-		if _, err := s.dockerClient.ExecAsRoot(ctx, container.DockerID, createDirCmd); err != nil {
-			s.addLog(containerID, models.LogLevelError, models.LogStageClone, fmt.Sprintf("Failed to create /app directory: %v", err))
-			s.updateInitStatus(containerID, models.InitStatusFailed, fmt.Sprintf("Failed to create /app directory: %v", err))
+	// Step 1.3: Install the pinned Claude CLI, or record the version the image ships
+	if pin := container.ClaudeVersionPin; pin != "" {
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit, fmt.Sprintf("Installing Claude CLI %s...", pin))
+		s.updateInitStatus(ctx, containerID, models.InitStatusInitializing, fmt.Sprintf("Installing Claude CLI %s...", pin))
+		if err := s.installPinnedClaude(ctx, container); err != nil {
+			s.addLog(ctx, containerID, models.LogLevelError, models.LogStageInit, fmt.Sprintf("Failed to install Claude CLI %s: %v", pin, err))
+			s.updateInitStatus(ctx, containerID, models.InitStatusFailed, fmt.Sprintf("Failed to install Claude CLI %s: %v", pin, err))
 			return
 		}
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit, fmt.Sprintf("Claude CLI pinned to %s", pin))
+	} else {
+		s.recordClaudeVersion(ctx, container)
+	}
 
-		// Also fix npm cache permissions to prevent cache corruption issues
-		fixNpmCmd := []string{
-			"bash", "-c",
-			"mkdir -p /home/developer/.npm && chown -R developer:developer /home/developer/.npm",
-		}
-		if _, err := s.dockerClient.ExecAsRoot(ctx, container.DockerID, fixNpmCmd); err != nil {
-			s.addLog(containerID, models.LogLevelWarn, models.LogStageClone, fmt.Sprintf("Warning: Failed to fix npm cache permissions: %v", err))
-			// Don't fail, just log warning
-		}
+	// The init strategy decides whether the pre-init script and Claude Code run
+	strategy := containerInitStrategy(container)
+	steps := initStrategySteps(strategy)
 
-		s.addLog(containerID, models.LogLevelInfo, models.LogStageClone, "Default /app working directory created successfully")
-	} else {
-		// Clone repository
-		s.addLog(containerID, models.LogLevelInfo, models.LogStageClone, fmt.Sprintf("Cloning repository: %s", container.GitRepoURL))
-		s.updateInitStatus(containerID, models.InitStatusCloning, "Cloning repository...")
+	// Step 1.5: Run the pre-init script to prepare the environment for Claude
+	if slices.Contains(steps, models.InitStagePreInit) {
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit, "Running pre-init script...")
+		s.updateInitStatus(ctx, containerID, models.InitStatusInitializing, "Running pre-init script...")
 
-		if err := s.cloneRepository(ctx, container); err != nil {
-			s.addLog(containerID, models.LogLevelError, models.LogStageClone, fmt.Sprintf("Clone failed: %v", err))
-			s.updateInitStatus(containerID, models.InitStatusFailed, fmt.Sprintf("Clone failed: %v", err))
+		preInitStage := timing.begin(models.InitStagePreInit)
+		if err := s.runPreInitScript(ctx, container); err != nil {
+			timing.end(preInitStage, models.InitStageStatusFailed)
+			s.addLog(ctx, containerID, models.LogLevelError, models.LogStageInit, fmt.Sprintf("Pre-init script failed: %v", err))
+			s.updateInitStatus(ctx, containerID, models.InitStatusFailed, fmt.Sprintf("Pre-init script failed: %v", err))
 			return
 		}
-		s.addLog(containerID, models.LogLevelInfo, models.LogStageClone, "Repository cloned successfully")
+		timing.end(preInitStage, models.InitStageStatusSucceeded)
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit, "Pre-init script completed successfully")
 	}
 
-	// Step 2: Run Claude Code initialization (if not skipped)
-	if !container.SkipClaudeInit {
-		s.addLog(containerID, models.LogLevelInfo, models.LogStageInit, "Starting Claude Code initialization...")
-		s.updateInitStatus(containerID, models.InitStatusInitializing, "Initializing project environment...")
+	// Step 2: Run Claude Code initialization
+	if slices.Contains(steps, models.InitStageClaudeInit) {
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit, "Starting Claude Code initialization...")
+		s.updateInitStatus(ctx, containerID, models.InitStatusInitializing, "Initializing project environment...")
 
+		initStage := timing.begin(models.InitStageClaudeInit)
 		if err := s.runClaudeInit(ctx, container); err != nil {
-			s.addLog(containerID, models.LogLevelError, models.LogStageInit, fmt.Sprintf("Initialization failed: %v", err))
-			s.updateInitStatus(containerID, models.InitStatusFailed, fmt.Sprintf("Initialization failed: %v", err))
+			timing.end(initStage, models.InitStageStatusFailed)
+			s.addLog(ctx, containerID, models.LogLevelError, models.LogStageInit, fmt.Sprintf("Initialization failed: %v", err))
+			s.updateInitStatus(ctx, containerID, models.InitStatusFailed, fmt.Sprintf("Initialization failed: %v", err))
 			return
 		}
+		timing.end(initStage, models.InitStageStatusSucceeded)
 	} else {
-		s.addLog(containerID, models.LogLevelInfo, models.LogStageInit, "Skipping Claude Code initialization (user requested)")
+		timing.skip(models.InitStageClaudeInit)
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit,
+			fmt.Sprintf("Skipping Claude Code initialization (init strategy %q)", strategy))
 	}
 
 	// Step 3: Start code-server if enabled
 	if container.EnableCodeServer {
-		s.addLog(containerID, models.LogLevelInfo, models.LogStageInit, "Starting code-server...")
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit, "Starting code-server...")
+		codeServerStage := timing.begin(models.InitStageCodeServer)
 		if err := s.StartCodeServer(ctx, containerID); err != nil {
-			s.addLog(containerID, models.LogLevelWarn, models.LogStageInit, fmt.Sprintf("code-server failed to start: %v", err))
+			timing.end(codeServerStage, models.InitStageStatusFailed)
+			s.addLog(ctx, containerID, models.LogLevelWarn, models.LogStageInit, fmt.Sprintf("code-server failed to start: %v", err))
 			// Don't fail initialization if code-server fails
 		} else {
-			s.addLog(containerID, models.LogLevelInfo, models.LogStageInit,
+			timing.end(codeServerStage, models.InitStageStatusSucceeded)
+			s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit,
 				fmt.Sprintf("code-server started on container port %d", container.CodeServerPort))
+			s.installCodeServerExtensions(ctx, s.dockerClient, container)
 		}
 	}
 
-	// Success
-	now := time.Now()
-	s.db.Model(&models.Container{}).Where("id = ?", containerID).Updates(map[string]interface{}{
-		"init_status":    models.InitStatusReady,
-		"init_message":   "Environment ready",
-		"initialized_at": &now,
-	})
+	// Success, unless the container was stopped or deleted meanwhile
+	if !s.markInitReady(ctx, containerID) {
+		return
+	}
 
-	s.addLog(containerID, models.LogLevelInfo, models.LogStageReady, "Container initialization completed successfully. Environment is ready!")
-	log.Printf("Container %d initialization completed successfully", containerID)
+	s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageReady, "Container initialization completed successfully. Environment is ready!")
+	s.logger(ctx, containerID).Printf("Container initialization completed successfully")
+	s.notifyInitCallback(ctx, containerID)
 }
 
 // cloneRepository clones the GitHub repository inside the container
 func (s *ContainerService) cloneRepository(ctx context.Context, container *models.Container) error {
-	// Get GitHub token from profile or legacy config
-	var token string
-	var err error
-	if s.configProfileService != nil && s.configProfileService.HasGitHubTokens() {
-		token, err = s.configProfileService.GetGitHubTokenForContainer(container.GitHubTokenID)
-		if err != nil {
-			// Fallback to legacy token
-			token, err = s.githubService.GetToken()
-			if err != nil {
-				return err
-			}
-		}
-	} else {
-		token, err = s.githubService.GetToken()
-		if err != nil {
+	token, err := s.gitHubTokenFor(container.GitHubTokenID)
+	if err != nil {
+		return err
+	}
+
+	// Prefer a local clone from the server's repository cache; fall back to the remote.
+	// Warm pool containers were created before their repository was known, so have no cache mount.
+	if s.repoCache.Enabled() && container.PoolName == "" {
+		err := s.cloneFromRepoCache(ctx, container, token)
+		if err == nil || ctx.Err() != nil {
 			return err
 		}
+		s.addLog(ctx, container.ID, models.LogLevelWarn, models.LogStageClone, fmt.Sprintf("Repository cache unavailable, cloning from remote: %v", err))
 	}
 
 	// Build clone URL with token
@@ -846,18 +1148,83 @@ func (s *ContainerService) cloneRepository(ctx context.Context, container *model
 	// Clone command
 	cloneCmd := []string{
 		"bash", "-c",
-		fmt.Sprintf("cd /workspace && git clone %s %s", cloneURL, container.GitRepoName),
+		killableCloneScript(fmt.Sprintf("cd /workspace && git clone %s %s", cloneURL, container.GitRepoName)),
 	}
 
-	output, err := s.dockerClient.ExecInContainer(ctx, container.DockerID, cloneCmd)
+	var output string
+	err = runKillable(ctx, func() error {
+		output, err = s.dockerClient.ExecInContainer(ctx, container.DockerID, cloneCmd)
+		return err
+	}, func() { s.killClone(container) })
 	if err != nil {
 		return fmt.Errorf("git clone failed: %v, output: %s", err, output)
 	}
 
-	log.Printf("Clone output for container %d: %s", container.ID, output)
+	s.logger(ctx, container.ID).Printf("Clone output: %s", output)
 	return nil
 }
 
+// gitHubTokenFor returns the token of a GitHub token profile, or the default token when
+// tokenID is nil, falling back to the legacy token
+func (s *ContainerService) gitHubTokenFor(tokenID *uint) (string, error) {
+	if s.configProfileService != nil && s.configProfileService.HasGitHubTokens() {
+		token, err := s.configProfileService.GetGitHubTokenForContainer(tokenID)
+		if err == nil {
+			return token, nil
+		}
+	}
+	return s.githubService.GetToken()
+}
+
+// cloneFromRepoCache refreshes the server-side cache of the container's repository and clones
+// it from the read-only cache mount. The clone's origin is the plain repository URL, so the
+// token used to fill the cache never reaches the container.
+func (s *ContainerService) cloneFromRepoCache(ctx context.Context, container *models.Container, token string) error {
+	if err := s.repoCache.Ensure(ctx, container.GitRepoURL, token, container.GitHubTokenID); err != nil {
+		return err
+	}
+
+	cachePath := s.repoCache.ContainerPath(container.GitRepoURL)
+	repoDir := shellQuote(container.GitRepoName)
+	script := fmt.Sprintf(`cd /workspace || exit 1
+test -f %[1]s/HEAD || { echo "repository cache is not mounted in this container"; exit 1; }
+git -c safe.directory='*' clone --quiet %[1]s %[2]s && git -C %[2]s remote set-url origin %[3]s || { rm -rf %[2]s; exit 1; }`,
+		shellQuote(cachePath), repoDir, shellQuote(stripURLCredentials(container.GitRepoURL)))
+
+	var output bytes.Buffer
+	var exitCode int
+	err := runKillable(ctx, func() (err error) {
+		exitCode, err = s.dockerClient.ExecStream(ctx, container.DockerID, []string{"bash", "-c", killableCloneScript(script)}, &output, &output)
+		return err
+	}, func() { s.killClone(container) })
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("local clone exited with %d: %s", exitCode, strings.TrimSpace(output.String()))
+	}
+	s.addLog(ctx, container.ID, models.LogLevelInfo, models.LogStageClone, "Cloned from the server repository cache")
+	return nil
+}
+
+// refreshRepoCacheLoop periodically fetches cached repositories, each with the GitHub token
+// profile that filled it
+func (s *ContainerService) refreshRepoCacheLoop(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			for _, err := range s.repoCache.RefreshAll(s.ctx, s.gitHubTokenFor) {
+				log.Printf("Warning: repository cache refresh failed: %v", err)
+			}
+		}
+	}
+}
+
 // runClaudeInit runs Claude Code to initialize the project environment
 func (s *ContainerService) runClaudeInit(ctx context.Context, container *models.Container) error {
 	// Generate system prompt for environment setup
@@ -879,28 +1246,44 @@ SYSPROMPTEOF`, systemPrompt),
 	}
 
 	// Build Claude Code command with optional YOLO mode flag
-	// When EnableYoloMode is true, add --dangerously-skip-permissions to skip all permission prompts
+	// When EnableYoloMode is true, add --dangerously-skip-permissions to skip all permission prompts.
+	// stream-json output lets clients follow the init live through the init console.
 	var claudeCmd []string
 	if container.EnableYoloMode {
 		claudeCmd = []string{
 			"bash", "-c",
-			fmt.Sprintf(`cd %s && claude --dangerously-skip-permissions --system-prompt-file /tmp/system_prompt.txt -p "%s"`,
+			fmt.Sprintf(`cd %s && claude --dangerously-skip-permissions --output-format stream-json --verbose --system-prompt-file /tmp/system_prompt.txt -p "%s"`,
 				container.WorkDir, initPrompt),
 		}
 	} else {
 		claudeCmd = []string{
 			"bash", "-c",
-			fmt.Sprintf(`cd %s && claude --system-prompt-file /tmp/system_prompt.txt -p "%s"`,
+			fmt.Sprintf(`cd %s && claude --output-format stream-json --verbose --system-prompt-file /tmp/system_prompt.txt -p "%s"`,
 				container.WorkDir, initPrompt),
 		}
 	}
 
-	output, err := s.dockerClient.ExecInContainer(ctx, container.DockerID, claudeCmd)
+	hub := s.initConsole
+	if hub == nil {
+		hub = NewInitConsoleHub()
+	}
+	hub.start(container.ID)
+	stdout := &initConsoleWriter{hub: hub, containerID: container.ID}
+	stderr := &initConsoleWriter{hub: hub, containerID: container.ID}
+
+	exitCode, err := s.dockerClient.ExecStream(ctx, container.DockerID, claudeCmd, stdout, stderr)
+	stdout.flush()
+	stderr.flush()
+	result := hub.finish(container.ID, exitCode, err)
+
 	if err != nil {
-		return fmt.Errorf("claude init failed: %v, output: %s", err, output)
+		return fmt.Errorf("claude init failed: %v, output: %s", err, stdout.Tail()+stderr.Tail())
 	}
 
-	log.Printf("Claude init output for container %d: %s", container.ID, output)
+	if result == "" {
+		result = stdout.Tail() + stderr.Tail()
+	}
+	s.logger(ctx, container.ID).Printf("Claude init output (exit code %d): %s", exitCode, result)
 	return nil
 }
 
@@ -943,28 +1326,25 @@ Steps to follow:
 Please proceed with the setup and report the status when done.`, repoName)
 }
 
-// updateInitStatus updates the initialization status
-func (s *ContainerService) updateInitStatus(containerID uint, status, message string) {
-	s.db.Model(&models.Container{}).Where("id = ?", containerID).Updates(map[string]interface{}{
-		"init_status":  status,
-		"init_message": message,
-	})
-	log.Printf("Container %d init status: %s - %s", containerID, status, message)
-}
-
-// addLog adds a log entry for a container
-func (s *ContainerService) addLog(containerID uint, level, stage, message string) {
+// addLog adds a log entry for a container, tagged with the request ID from ctx (if any)
+func (s *ContainerService) addLog(ctx context.Context, containerID uint, level, stage, message string) {
 	logEntry := &models.ContainerLog{
 		ContainerID: containerID,
+		RequestID:   logging.RequestID(ctx),
 		Level:       level,
 		Stage:       stage,
 		Message:     message,
 	}
 	if err := s.db.Create(logEntry).Error; err != nil {
-		log.Printf("Failed to save log for container %d: %v", containerID, err)
+		s.logger(ctx, containerID).Printf("Failed to save container log: %v", err)
 	}
 }
 
+// logger returns a structured logger tagged with the container ID and the request ID from ctx
+func (s *ContainerService) logger(ctx context.Context, containerID uint) logging.Logger {
+	return logging.FromContext(ctx, "ContainerService").With(logging.KeyContainerID, containerID)
+}
+
 // GetContainerLogs retrieves logs for a container
 func (s *ContainerService) GetContainerLogs(containerID uint, limit int) ([]models.ContainerLog, error) {
 	var logs []models.ContainerLog
@@ -991,14 +1371,14 @@ func (s *ContainerService) StartContainer(ctx context.Context, id uint) error {
 	}
 
 	// Add log for restart
-	s.addLog(id, models.LogLevelInfo, models.LogStageStartup, "Restarting container...")
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup, "Restarting container...")
 
 	if err := s.dockerClient.StartContainer(ctx, container.DockerID); err != nil {
-		s.addLog(id, models.LogLevelError, models.LogStageStartup, fmt.Sprintf("Failed to restart: %v", err))
-		return err
+		s.addLog(ctx, id, models.LogLevelError, models.LogStageStartup, fmt.Sprintf("Failed to restart: %v", err))
+		return dockerError(err)
 	}
 
-	s.addLog(id, models.LogLevelInfo, models.LogStageStartup, "Container restarted successfully")
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup, "Container restarted successfully")
 
 	// Update status
 	now := time.Now()
@@ -1018,25 +1398,25 @@ func (s *ContainerService) StartContainer(ctx context.Context, id uint) error {
 			// Wait a moment for container to fully start, but check for shutdown
 			select {
 			case <-s.ctx.Done():
-				log.Printf("code-server start cancelled for container %d: service shutting down", id)
+				s.logger(ctx, id).Printf("code-server start cancelled: service shutting down")
 				return
 			case <-time.After(2 * time.Second):
 			}
 
-			startCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			startCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
 			defer cancel()
 
 			if err := s.StartCodeServer(startCtx, id); err != nil {
-				s.addLog(id, models.LogLevelWarn, models.LogStageStartup, fmt.Sprintf("Failed to start code-server: %v", err))
+				s.addLog(ctx, id, models.LogLevelWarn, models.LogStageStartup, fmt.Sprintf("Failed to start code-server: %v", err))
 			} else {
-				s.addLog(id, models.LogLevelInfo, models.LogStageStartup, "code-server started")
+				s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup, "code-server started")
 
 				// Re-add port record for code-server
 				portService := NewPortService(s.db)
-				// Use subdomain routing port (internal) or host port
+				// Use subdomain routing port (internal) or the host port mapped at creation
 				port := CodeServerInternalPort
-				if container.CodeServerDomain == "" && container.CodeServerPort > 0 {
-					port = container.CodeServerPort
+				if container.CodeServerDomain == "" && container.CodeServerHostPort > 0 {
+					port = container.CodeServerHostPort
 				}
 				portService.AddPort(id, port, "VS Code", "http", true)
 			}
@@ -1054,19 +1434,17 @@ func (s *ContainerService) StopContainer(ctx context.Context, id uint) error {
 	}
 
 	// Cancel any running initialization
-	if cancel, ok := s.initTasks.Load(id); ok {
-		cancel.(context.CancelFunc)()
-	}
+	s.cancelInitTask(ctx, id, "container stopped")
 
-	s.addLog(id, models.LogLevelInfo, models.LogStageStartup, "Stopping container...")
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup, "Stopping container...")
 
 	timeout := 30
 	if err := s.dockerClient.StopContainer(ctx, container.DockerID, &timeout); err != nil {
-		s.addLog(id, models.LogLevelError, models.LogStageStartup, fmt.Sprintf("Failed to stop: %v", err))
-		return err
+		s.addLog(ctx, id, models.LogLevelError, models.LogStageStartup, fmt.Sprintf("Failed to stop: %v", err))
+		return dockerError(err)
 	}
 
-	s.addLog(id, models.LogLevelInfo, models.LogStageStartup, "Container stopped")
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup, "Container stopped")
 
 	// Clean up terminal sessions from database
 	s.db.Model(&models.TerminalSession{}).
@@ -1092,19 +1470,41 @@ func (s *ContainerService) DeleteContainer(ctx context.Context, id uint) error {
 	}
 
 	// Cancel any running initialization
-	if cancel, ok := s.initTasks.Load(id); ok {
-		cancel.(context.CancelFunc)()
+	s.cancelInitTask(ctx, id, "container deleted")
+
+	if err := s.removeDockerResources(ctx, container); err != nil {
+		return err
 	}
+	s.deleteRuntimeRecords(id)
+
+	// Delete container logs
+	s.db.Where("container_id = ?", id).Delete(&models.ContainerLog{})
+
+	// Delete saved commands
+	s.db.Where("container_id = ?", id).Delete(&models.ContainerCommand{})
+
+	// Remove from database
+	return s.db.Delete(&models.Container{}, id).Error
+}
 
-	// Remove Docker container
+// removeDockerResources removes the Docker container and its managed volumes. Failures are
+// only logged, except an unreachable daemon: then nothing was removed and the caller must
+// keep the record so the container isn't orphaned.
+func (s *ContainerService) removeDockerResources(ctx context.Context, container *models.Container) error {
 	if err := s.dockerClient.RemoveContainer(ctx, container.DockerID, true); err != nil {
-		log.Printf("Warning: failed to remove Docker container: %v", err)
+		if err := dockerError(err); errors.Is(err, ErrDockerUnavailable) {
+			return err
+		}
+		s.logger(ctx, container.ID).Printf("Warning: failed to remove Docker container: %v", err)
 	}
-	if err := s.dockerClient.RemoveVolumes(ctx, managedPerContainerVolumes(container.Name)...); err != nil {
-		log.Printf("Warning: failed to remove managed volumes for container %d: %v", id, err)
+	if err := s.dockerClient.RemoveVolumes(ctx, managedPerContainerVolumes(volumeContainerName(container))...); err != nil {
+		s.logger(ctx, container.ID).Printf("Warning: failed to remove managed volumes: %v", err)
 	}
+	return nil
+}
 
-	// Clean up related resources
+// deleteRuntimeRecords deletes records tied to the Docker container: ports and terminal sessions
+func (s *ContainerService) deleteRuntimeRecords(id uint) {
 	// Delete port records (use Unscoped for hard delete since we use raw table query)
 	s.db.Unscoped().Where("container_id = ?", id).Delete(&models.ContainerPort{})
 
@@ -1114,12 +1514,6 @@ func (s *ContainerService) DeleteContainer(ctx context.Context, id uint) error {
 	// Delete terminal history
 	s.db.Where("session_id IN (SELECT session_id FROM terminal_sessions WHERE container_id = ?)", id).
 		Delete(&models.TerminalHistory{})
-
-	// Delete container logs
-	s.db.Where("container_id = ?", id).Delete(&models.ContainerLog{})
-
-	// Remove from database
-	return s.db.Delete(&models.Container{}, id).Error
 }
 
 // GetContainer gets a container by ID
@@ -1188,18 +1582,7 @@ func (s *ContainerService) SyncContainerStatus(ctx context.Context) error {
 			status = models.ContainerStatusDeleted
 		}
 
-		var newStatus string
-		switch status {
-		case "running":
-			newStatus = models.ContainerStatusRunning
-		case "exited", "dead":
-			newStatus = models.ContainerStatusStopped
-		case "created":
-			newStatus = models.ContainerStatusCreated
-		default:
-			newStatus = models.ContainerStatusStopped
-		}
-
+		newStatus := containerStatusFromDocker(status)
 		if container.Status != newStatus {
 			s.db.Model(&container).Update("status", newStatus)
 		}
@@ -1215,7 +1598,8 @@ func (s *ContainerService) ExecInContainer(ctx context.Context, id uint, cmd []s
 		return "", err
 	}
 
-	return s.dockerClient.ExecInContainer(ctx, container.DockerID, cmd)
+	output, err := s.dockerClient.ExecInContainer(ctx, container.DockerID, cmd)
+	return output, dockerError(err)
 }
 
 // GetStartupCommand returns the startup command for Claude Code
@@ -1239,6 +1623,7 @@ type ContainerInfo struct {
 	Status              string                  `json:"status"`
 	InitStatus          string                  `json:"init_status"`
 	InitMessage         string                  `json:"init_message,omitempty"`
+	InitProgress        int                     `json:"init_progress"`
 	GitRepoURL          string                  `json:"git_repo_url,omitempty"`
 	GitRepoName         string                  `json:"git_repo_name,omitempty"`
 	WorkDir             string                  `json:"work_dir,omitempty"`
@@ -1248,20 +1633,40 @@ type ContainerInfo struct {
 	CPUUnlimited        bool                    `json:"cpu_unlimited"`
 	GPUEnabled          bool                    `json:"gpu_enabled"`
 	GPUCount            int                     `json:"gpu_count,omitempty"`
+	RestartPolicy       string                  `json:"restart_policy,omitempty"`
+	RestartMaxRetries   int                     `json:"restart_max_retries,omitempty"`
+	RunAsUser           string                  `json:"run_as_user,omitempty"`
+	TTLMinutes          int                     `json:"ttl_minutes,omitempty"`
+	ExpiresAt           *time.Time              `json:"expires_at,omitempty"`
+	InitStrategy        string                  `json:"init_strategy"`
+	Image               string                  `json:"image,omitempty"`
+	ClaudeVersion       string                  `json:"claude_version,omitempty"`
+	ClaudeVersionPin    string                  `json:"claude_version_pin,omitempty"`
+	CloneLFS            *bool                   `json:"clone_lfs,omitempty"`
+	ClaudeAPIBase       string                  `json:"claude_api_base,omitempty"`
+	ClaudeModel         string                  `json:"claude_model,omitempty"`
+	HasClaudeAuthToken  bool                    `json:"has_claude_auth_token"`
+	Notes               string                  `json:"notes,omitempty"`
+	Metadata            map[string]string       `json:"metadata,omitempty"`
 	AutoInjectAllSkills bool                    `json:"auto_inject_all_skills"`
 	ExposedPorts        string                  `json:"exposed_ports,omitempty"`
 	ProxyEnabled        bool                    `json:"proxy_enabled"`
 	ProxyDomain         string                  `json:"proxy_domain,omitempty"`
 	ProxyPort           int                     `json:"proxy_port,omitempty"`
 	ServicePort         int                     `json:"service_port,omitempty"`
+	ProxyStatus         string                  `json:"proxy_status,omitempty"`
+	ProxyStatusReason   string                  `json:"proxy_status_reason,omitempty"`
 	EnableCodeServer    bool                    `json:"enable_code_server"`
 	CodeServerPort      int                     `json:"code_server_port,omitempty"`
+	CodeServerHostPort  int                     `json:"code_server_host_port,omitempty"`
 	CodeServerDomain    string                  `json:"code_server_domain,omitempty"`
 	CreatedAt           time.Time               `json:"created_at"`
 	StartedAt           *time.Time              `json:"started_at,omitempty"`
 	StoppedAt           *time.Time              `json:"stopped_at,omitempty"`
 	InitializedAt       *time.Time              `json:"initialized_at,omitempty"`
 	InjectionStatus     *models.InjectionStatus `json:"injection_status,omitempty"`
+	SelectedTemplateIDs []uint                  `json:"selected_template_ids,omitempty"`
+	HostMounts          models.HostMountList    `json:"host_mounts,omitempty"`
 }
 
 // ToContainerInfo converts a Container model to ContainerInfo
@@ -1273,6 +1678,7 @@ func ToContainerInfo(c *models.Container) ContainerInfo {
 		Status:              c.Status,
 		InitStatus:          c.InitStatus,
 		InitMessage:         c.InitMessage,
+		InitProgress:        c.InitProgress,
 		GitRepoURL:          c.GitRepoURL,
 		GitRepoName:         c.GitRepoName,
 		WorkDir:             c.WorkDir,
@@ -1282,20 +1688,40 @@ func ToContainerInfo(c *models.Container) ContainerInfo {
 		CPUUnlimited:        c.CPUUnlimited,
 		GPUEnabled:          c.GPUEnabled,
 		GPUCount:            c.GPUCount,
+		RestartPolicy:       c.RestartPolicy,
+		RestartMaxRetries:   c.RestartMaxRetries,
+		RunAsUser:           c.RunAsUser,
+		TTLMinutes:          c.TTLMinutes,
+		ExpiresAt:           c.ExpiresAt,
+		InitStrategy:        containerInitStrategy(c),
+		Image:               c.Image,
+		ClaudeVersion:       c.ClaudeVersion,
+		ClaudeVersionPin:    c.ClaudeVersionPin,
+		CloneLFS:            c.CloneLFS,
+		ClaudeAPIBase:       c.ClaudeAPIBase,
+		ClaudeModel:         c.ClaudeModel,
+		HasClaudeAuthToken:  c.ClaudeAuthToken != "",
+		Notes:               c.Notes,
+		Metadata:            c.Metadata,
 		AutoInjectAllSkills: c.AutoInjectAllSkills,
 		ExposedPorts:        c.ExposedPorts,
 		ProxyEnabled:        c.ProxyEnabled,
 		ProxyDomain:         c.ProxyDomain,
 		ProxyPort:           c.ProxyPort,
 		ServicePort:         c.ServicePort,
+		ProxyStatus:         c.ProxyStatus,
+		ProxyStatusReason:   c.ProxyStatusReason,
 		EnableCodeServer:    c.EnableCodeServer,
 		CodeServerPort:      c.CodeServerPort,
+		CodeServerHostPort:  c.CodeServerHostPort,
 		CodeServerDomain:    c.CodeServerDomain,
 		CreatedAt:           c.CreatedAt,
 		StartedAt:           c.StartedAt,
 		StoppedAt:           c.StoppedAt,
 		InitializedAt:       c.InitializedAt,
 		InjectionStatus:     c.InjectionStatus,
+		SelectedTemplateIDs: c.SelectedTemplateIDs,
+		HostMounts:          c.HostMounts,
 	}
 }
 
@@ -1362,21 +1788,22 @@ func (s *ContainerService) StartCodeServer(ctx context.Context, containerID uint
 		return nil
 	}
 
+	password, err := s.codeServerPassword(container)
+	if err != nil {
+		return err
+	}
+
 	// Always use the fixed internal port (8443), not the host port stored in DB
 	// The host port mapping is handled by Docker port bindings
-	cmd := []string{
-		"bash", "-c",
-		fmt.Sprintf("nohup code-server --bind-addr 0.0.0.0:%d --auth none %s > /tmp/code-server.log 2>&1 &",
-			CodeServerInternalPort, container.WorkDir),
-	}
+	cmd := codeServerCommand(container.WorkDir, container.CodeServerAuth, password)
 
 	_, err = s.dockerClient.ExecInContainer(ctx, container.DockerID, cmd)
 	if err != nil {
-		s.addLog(containerID, models.LogLevelError, models.LogStageInit, fmt.Sprintf("Failed to start code-server: %v", err))
+		s.addLog(ctx, containerID, models.LogLevelError, models.LogStageInit, fmt.Sprintf("Failed to start code-server: %v", err))
 		return err
 	}
 
-	s.addLog(containerID, models.LogLevelInfo, models.LogStageInit, fmt.Sprintf("code-server started on container port %d", CodeServerInternalPort))
+	s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit, fmt.Sprintf("code-server started on container port %d", CodeServerInternalPort))
 	return nil
 }
 
@@ -1392,19 +1819,14 @@ type DockerContainerInfo struct {
 	IsManaged bool     `json:"is_managed"` // true if managed by this platform
 }
 
-// ListDockerContainers lists all Docker containers
-func (s *ContainerService) ListDockerContainers(ctx context.Context) ([]DockerContainerInfo, error) {
+// ListDockerContainers lists the Docker containers that match the filter
+func (s *ContainerService) ListDockerContainers(ctx context.Context, filter DockerContainerFilter) ([]DockerContainerInfo, error) {
 	containers, err := s.dockerClient.ListContainers(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list Docker containers: %w", err)
+		return nil, fmt.Errorf("failed to list Docker containers: %w", dockerError(err))
 	}
 
-	// Get managed container IDs from database
-	managedIDs := make(map[string]bool)
-	dbContainers, _ := s.ListContainers()
-	for _, c := range dbContainers {
-		managedIDs[c.DockerID] = true
-	}
+	managedIDs := s.managedDockerIDs()
 
 	result := make([]DockerContainerInfo, 0, len(containers))
 	for _, c := range containers {
@@ -1424,7 +1846,7 @@ func (s *ContainerService) ListDockerContainers(ctx context.Context) ([]DockerCo
 			name = strings.TrimPrefix(c.Names[0], "/")
 		}
 
-		result = append(result, DockerContainerInfo{
+		info := DockerContainerInfo{
 			ID:        c.ID[:12], // Short ID
 			Name:      name,
 			Image:     c.Image,
@@ -1433,16 +1855,34 @@ func (s *ContainerService) ListDockerContainers(ctx context.Context) ([]DockerCo
 			Created:   c.Created,
 			Ports:     ports,
 			IsManaged: managedIDs[c.ID],
-		})
+		}
+		if filter.Matches(info) {
+			result = append(result, info)
+		}
 	}
 
 	return result, nil
 }
 
+// managedDockerIDs returns the Docker IDs of containers recorded by the platform and of the
+// idle warm pool containers
+func (s *ContainerService) managedDockerIDs() map[string]bool {
+	var ids []string
+	s.db.Model(&models.Container{}).Where("docker_id <> ''").Pluck("docker_id", &ids)
+	if s.warmPool != nil {
+		ids = append(ids, s.warmPool.dockerIDs()...)
+	}
+	managed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		managed[id] = true
+	}
+	return managed
+}
+
 // StopDockerContainer stops a Docker container by ID
 func (s *ContainerService) StopDockerContainer(ctx context.Context, dockerID string) error {
 	timeout := 30
-	return s.dockerClient.StopContainer(ctx, dockerID, &timeout)
+	return dockerError(s.dockerClient.StopContainer(ctx, dockerID, &timeout))
 }
 
 // RemoveDockerContainer removes a Docker container by ID
@@ -1452,12 +1892,24 @@ func (s *ContainerService) RemoveDockerContainer(ctx context.Context, dockerID s
 	if err := s.db.Where("docker_id LIKE ?", dockerID+"%").First(&container).Error; err == nil {
 		s.db.Delete(&container)
 	}
-	return s.dockerClient.RemoveContainer(ctx, dockerID, true)
+	return dockerError(s.dockerClient.RemoveContainer(ctx, dockerID, true))
 }
 
 // InjectConfigs manually injects Claude configurations into a running container
 // This can be called after container is running to inject or re-inject configs
 func (s *ContainerService) InjectConfigs(ctx context.Context, containerID uint, templateIDs []uint) (*models.InjectionStatus, error) {
+	return s.InjectConfigsWithWarnings(ctx, containerID, templateIDs, nil)
+}
+
+// InjectConfigsWithWarnings behaves like InjectConfigs but records caller-side warnings
+// (such as skipped bundle members) in the stored injection status
+func (s *ContainerService) InjectConfigsWithWarnings(ctx context.Context, containerID uint, templateIDs []uint, warnings []string) (*models.InjectionStatus, error) {
+	return s.injectConfigs(ctx, containerID, templateIDs, warnings, false)
+}
+
+// injectConfigs injects templates into a running container. With mergeStatus the stored
+// injection status keeps the entries of other templates instead of being replaced.
+func (s *ContainerService) injectConfigs(ctx context.Context, containerID uint, templateIDs []uint, warnings []string, mergeStatus bool) (*models.InjectionStatus, error) {
 	container, err := s.GetContainer(containerID)
 	if err != nil {
 		return nil, err
@@ -1466,7 +1918,7 @@ func (s *ContainerService) InjectConfigs(ctx context.Context, containerID uint,
 	// Verify container is running
 	status, err := s.dockerClient.GetContainerStatus(ctx, container.DockerID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get container status: %w", err)
+		return nil, fmt.Errorf("failed to get container status: %w", dockerError(err))
 	}
 	if status != "running" {
 		return nil, fmt.Errorf("container must be running to inject configs (current status: %s)", status)
@@ -1478,30 +1930,47 @@ func (s *ContainerService) InjectConfigs(ctx context.Context, containerID uint,
 	}
 
 	// Perform injection
-	injectionStatus, err := s.configInjectionService.InjectConfigs(ctx, container.DockerID, templateIDs)
+	injectionStatus, err := s.configInjectionService.InjectConfigs(ctx, container.DockerID, container.WorkDir, templateIDs)
 	if err != nil {
 		return nil, fmt.Errorf("config injection failed: %w", err)
 	}
 
 	// Update injection status in database
 	if injectionStatus != nil {
-		if err := s.db.Model(&models.Container{}).Where("id = ?", containerID).
-			Update("injection_status", injectionStatus).Error; err != nil {
-			log.Printf("Failed to store injection status for container %d: %v", containerID, err)
+		if len(warnings) > 0 {
+			injectionStatus.Warnings = append(append([]string{}, warnings...), injectionStatus.Warnings...)
+		}
+		// Remember injected templates so they can be propagated to and recreated with the container
+		selected := dedupeUintSlice(append(append([]uint{}, container.SelectedTemplateIDs...), injectionStatus.SuccessfulIDs...))
+		stored := injectionStatus
+		if mergeStatus {
+			stored = mergeInjectionStatus(container.InjectionStatus, injectionStatus, templateIDs)
+		}
+		if err := s.db.Model(&models.Container{}).Where("id = ?", containerID).Updates(map[string]interface{}{
+			"injection_status":      stored,
+			"selected_template_ids": models.TemplateIDList(selected),
+		}).Error; err != nil {
+			s.logger(ctx, containerID).Printf("Failed to store injection status: %v", err)
+		}
+		if err := recordTemplateInjections(s.db, injectionStatus.SuccessfulIDs); err != nil {
+			s.logger(ctx, containerID).Printf("Failed to record template injections: %v", err)
 		}
 
 		// Add log entries
 		if len(injectionStatus.Successful) > 0 {
-			s.addLog(containerID, models.LogLevelInfo, models.LogStageInit,
+			s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit,
 				fmt.Sprintf("Manually injected configs: %v", injectionStatus.Successful))
 		}
 		if len(injectionStatus.Failed) > 0 {
 			for _, failed := range injectionStatus.Failed {
-				s.addLog(containerID, models.LogLevelWarn, models.LogStageInit,
+				s.addLog(ctx, containerID, models.LogLevelWarn, models.LogStageInit,
 					fmt.Sprintf("Failed to inject config '%s' (%s): %s",
 						failed.TemplateName, failed.ConfigType, failed.Reason))
 			}
 		}
+		for _, warning := range warnings {
+			s.addLog(ctx, containerID, models.LogLevelWarn, models.LogStageInit, warning)
+		}
 	}
 
 	return injectionStatus, nil
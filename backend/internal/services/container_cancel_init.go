@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+// ErrInitNotRunning is returned when cancelling the initialization of a container that isn't initializing
+var ErrInitNotRunning = errors.New("container initialization is not running")
+
+const (
+	// clonePIDFile holds the PID of the shell running a clone inside the container, so a
+	// cancelled initialization can kill it; detaching from the exec leaves git running
+	clonePIDFile = "/tmp/cc-clone.pid"
+	// cloneKillTimeout bounds killing a cancelled clone inside the container
+	cloneKillTimeout = 10 * time.Second
+)
+
+// CancelInit stops a container's running initialization. An in-flight clone is killed inside
+// the container and its partial checkout removed; the init status becomes cancelled.
+func (s *ContainerService) CancelInit(ctx context.Context, id uint) (*models.Container, error) {
+	if _, err := s.GetContainer(id); err != nil {
+		return nil, err
+	}
+	if !s.cancelInitTask(ctx, id, "cancelled by user") {
+		return nil, ErrInitNotRunning
+	}
+	s.addLog(ctx, id, models.LogLevelWarn, models.LogStageInit, "Initialization cancelled by user")
+	return s.GetContainer(id)
+}
+
+// killableCloneScript wraps a clone script so its shell records its PID while it runs
+func killableCloneScript(script string) string {
+	return fmt.Sprintf("echo $$ > %[1]s\ntrap 'rm -f %[1]s' EXIT\n%[2]s", clonePIDFile, script)
+}
+
+// killCloneScript stops a clone started by killableCloneScript: TERM to the shell and its
+// children, KILL a second later, then removes the partial checkout in repoDir
+func killCloneScript(repoDir string) string {
+	return fmt.Sprintf(`p=$(cat %[1]s 2>/dev/null) || exit 0
+pkill -TERM -P "$p" 2>/dev/null; kill -TERM "$p" 2>/dev/null
+sleep 1
+pkill -KILL -P "$p" 2>/dev/null; kill -KILL "$p" 2>/dev/null
+rm -rf %[1]s %[2]s
+true`, clonePIDFile, shellQuote(repoDir))
+}
+
+// runKillable runs a clone and calls kill if ctx ends first. Cancelling ctx only detaches
+// from the exec, so kill has to stop the process inside the container.
+func runKillable(ctx context.Context, clone func() error, kill func()) error {
+	stop := context.AfterFunc(ctx, kill)
+	defer stop()
+	return clone()
+}
+
+// killClone kills the clone running in a container. It runs after the init context is done,
+// so it uses its own.
+func (s *ContainerService) killClone(container *models.Container) {
+	ctx, cancel := context.WithTimeout(context.Background(), cloneKillTimeout)
+	defer cancel()
+	cmd := []string{"bash", "-c", killCloneScript("/workspace/" + container.GitRepoName)}
+	if _, err := s.dockerClient.ExecInContainer(ctx, container.DockerID, cmd); err != nil {
+		s.logger(ctx, container.ID).Printf("Warning: failed to kill the cancelled clone: %v", err)
+		return
+	}
+	s.addLog(ctx, container.ID, models.LogLevelWarn, models.LogStageClone, "Clone cancelled; the partial checkout was removed")
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+func TestCancelInit_DuringClone(t *testing.T) {
+	s := setupPauseTestService(t)
+	c := createInitTestContainer(t, s, "cancel-clone", models.InitStatusPending)
+
+	ctx, done, err := s.startInitTask(context.Background(), c.ID, time.Minute)
+	if err != nil {
+		t.Fatalf("startInitTask error: %v", err)
+	}
+	s.updateInitStatus(ctx, c.ID, models.InitStatusCloning, "Cloning repository...")
+
+	// The clone blocks until the run is cancelled; the kill stands in for the exec that stops git
+	killed := make(chan struct{})
+	cloneErr := make(chan error, 1)
+	go func() {
+		cloneErr <- runKillable(ctx, func() error {
+			<-ctx.Done()
+			<-killed
+			return errors.New("git clone failed: signal: terminated")
+		}, func() { close(killed) })
+	}()
+
+	got, err := s.CancelInit(context.Background(), c.ID)
+	if err != nil {
+		t.Fatalf("CancelInit error: %v", err)
+	}
+	if got.InitStatus != models.InitStatusCancelled || got.InitMessage != "Initialization cancelled: cancelled by user" {
+		t.Errorf("after cancel: status %q message %q", got.InitStatus, got.InitMessage)
+	}
+
+	select {
+	case err := <-cloneErr:
+		if err == nil {
+			t.Error("Expected the killed clone to fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("clone was not killed after the cancel")
+	}
+
+	// The run reports its failed clone and ends; the outcome stays cancelled, not failed
+	s.updateInitStatus(ctx, c.ID, models.InitStatusFailed, "Clone failed: signal: terminated")
+	done()
+	if got := initStatusOf(t, s, c.ID); got.InitStatus != models.InitStatusCancelled {
+		t.Errorf("final status = %q, want cancelled", got.InitStatus)
+	}
+
+	// Nothing is left running, so a second cancel is refused and a new run can start
+	if _, err := s.CancelInit(context.Background(), c.ID); !errors.Is(err, ErrInitNotRunning) {
+		t.Errorf("Expected ErrInitNotRunning for a second cancel, got %v", err)
+	}
+	_, done, err = s.startInitTask(context.Background(), c.ID, time.Minute)
+	if err != nil {
+		t.Fatalf("Expected a new run to start after the cancel, got %v", err)
+	}
+	defer done()
+	if !canTransitionInitStatus(models.InitStatusCancelled, models.InitStatusCloning) {
+		t.Error("Expected a cancelled container to be able to clone again")
+	}
+}
+
+func TestCancelInit_NotRunning(t *testing.T) {
+	s := setupPauseTestService(t)
+	c := createInitTestContainer(t, s, "ready", models.InitStatusReady)
+
+	if _, err := s.CancelInit(context.Background(), c.ID); !errors.Is(err, ErrInitNotRunning) {
+		t.Errorf("Expected ErrInitNotRunning, got %v", err)
+	}
+	if got := initStatusOf(t, s, c.ID); got.InitStatus != models.InitStatusReady {
+		t.Errorf("status = %q, want ready to be kept", got.InitStatus)
+	}
+	if _, err := s.CancelInit(context.Background(), 9999); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+}
+
+func TestRunKillable_FinishedCloneIsNotKilled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	killed := false
+	if err := runKillable(ctx, func() error { return nil }, func() { killed = true }); err != nil {
+		t.Fatalf("runKillable error: %v", err)
+	}
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	if killed {
+		t.Error("Expected no kill after the clone finished")
+	}
+}
+
+func TestKillCloneScripts(t *testing.T) {
+	wrapped := killableCloneScript("git clone url repo")
+	if !strings.HasPrefix(wrapped, "echo $$ > "+clonePIDFile+"\n") || !strings.HasSuffix(wrapped, "\ngit clone url repo") {
+		t.Errorf("Unexpected wrapped script: %q", wrapped)
+	}
+	kill := killCloneScript("/workspace/my repo")
+	if !strings.Contains(kill, `pkill -TERM -P "$p"`) || !strings.Contains(kill, "rm -rf "+clonePIDFile+" '/workspace/my repo'") {
+		t.Errorf("Unexpected kill script: %q", kill)
+	}
+}
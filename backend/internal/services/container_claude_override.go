@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"cc-platform/internal/models"
+	"cc-platform/pkg/crypto"
+)
+
+// ErrInvalidClaudeOverride is returned when a container's API base or model override is malformed
+var ErrInvalidClaudeOverride = errors.New("invalid Claude override")
+
+// Env vars set by a container's Claude overrides
+const (
+	claudeAPIBaseEnv   = "ANTHROPIC_BASE_URL"
+	claudeModelEnv     = "ANTHROPIC_MODEL"
+	claudeAuthTokenEnv = "ANTHROPIC_AUTH_TOKEN"
+	// claudeAPIKeyEnv is dropped when an auth token override replaces the profile's credential
+	claudeAPIKeyEnv = "ANTHROPIC_API_KEY"
+)
+
+// claudeModelMaxLength bounds a container's model override
+const claudeModelMaxLength = 200
+
+// normalizeClaudeOverrides trims a container's Claude overrides and validates the API base
+// and model. Empty values keep what the env profile sets.
+func normalizeClaudeOverrides(apiBase, model, authToken string) (string, string, string, error) {
+	apiBase = strings.TrimSpace(apiBase)
+	model = strings.TrimSpace(model)
+	authToken = strings.TrimSpace(authToken)
+
+	if apiBase != "" {
+		u, err := url.Parse(apiBase)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", "", "", fmt.Errorf("%w: claude_api_base must be an http(s) URL", ErrInvalidClaudeOverride)
+		}
+		if u.User != nil {
+			return "", "", "", fmt.Errorf("%w: claude_api_base must not contain credentials; use claude_auth_token", ErrInvalidClaudeOverride)
+		}
+	}
+	if len(model) > claudeModelMaxLength || strings.ContainsAny(model, " \t\r\n") {
+		return "", "", "", fmt.Errorf("%w: claude_model must be a model name without whitespace", ErrInvalidClaudeOverride)
+	}
+	return apiBase, model, authToken, nil
+}
+
+// applyClaudeOverrides writes a container's Claude overrides over the env vars of its profile.
+// An auth token replaces the profile's API key, so Claude doesn't see two credentials.
+func applyClaudeOverrides(env map[string]string, apiBase, model, authToken string) {
+	if apiBase != "" {
+		env[claudeAPIBaseEnv] = apiBase
+	}
+	if model != "" {
+		env[claudeModelEnv] = model
+	}
+	if authToken != "" {
+		env[claudeAuthTokenEnv] = authToken
+		delete(env, claudeAPIKeyEnv)
+	}
+}
+
+// encryptClaudeAuthToken returns the stored form of an auth token override
+func (s *ContainerService) encryptClaudeAuthToken(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+	encrypted, err := crypto.Encrypt(token, []byte(s.config.EncryptionKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt claude_auth_token: %w", err)
+	}
+	return encrypted, nil
+}
+
+// claudeAuthToken decrypts a container's stored auth token override
+func (s *ContainerService) claudeAuthToken(c *models.Container) (string, error) {
+	if c.ClaudeAuthToken == "" {
+		return "", nil
+	}
+	token, err := crypto.Decrypt(c.ClaudeAuthToken, []byte(s.config.EncryptionKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt claude_auth_token: %w", err)
+	}
+	return token, nil
+}
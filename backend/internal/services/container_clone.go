@@ -0,0 +1,191 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+var (
+	// ErrRepoAlreadyPresent is returned when cloning into a container that already has a repository
+	ErrRepoAlreadyPresent = errors.New("container already has a repository")
+	// ErrInitInProgress is returned when an operation needs initialization to be idle
+	ErrInitInProgress = errors.New("container initialization in progress")
+	// ErrInvalidCloneRequest is returned when a clone-repo request is malformed
+	ErrInvalidCloneRequest = errors.New("invalid clone request")
+)
+
+// repoDirPattern restricts repository directory names, which are used in shell commands
+var repoDirPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// gitRefPattern accepts branch, tag and commit names without shell metacharacters
+var gitRefPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// CloneRepoInput describes a repository to clone into an existing empty container
+type CloneRepoInput struct {
+	GitRepoURL    string `json:"git_repo_url"`
+	GitRepoName   string `json:"git_repo_name,omitempty"` // Defaults to the name in the URL
+	Ref           string `json:"ref,omitempty"`           // Branch, tag or commit to check out after cloning
+	RunClaudeInit bool   `json:"run_claude_init,omitempty"`
+	CloneLFS      *bool  `json:"clone_lfs,omitempty"` // Pull Git LFS files when the repository uses LFS (nil = yes)
+}
+
+// CloneRepoIntoContainer clones a repository into a running container that was created without one.
+// The request is validated synchronously; cloning, checkout and the optional Claude init then run
+// in the background, reported through the usual init status and logs.
+func (s *ContainerService) CloneRepoIntoContainer(ctx context.Context, id uint, input CloneRepoInput) (*models.Container, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	if container.Status != models.ContainerStatusRunning {
+		return nil, ErrContainerNotRunning
+	}
+	if !container.SkipGitRepo || container.GitRepoURL != "" {
+		return nil, ErrRepoAlreadyPresent
+	}
+	if _, running := s.initTasks.Load(id); running {
+		return nil, ErrInitInProgress
+	}
+
+	if strings.TrimSpace(input.GitRepoURL) == "" {
+		return nil, fmt.Errorf("%w: git_repo_url is required", ErrInvalidCloneRequest)
+	}
+	repoURL, repoName, err := s.resolveGitRepo(input.GitRepoURL, input.GitRepoName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCloneRequest, err)
+	}
+	input.GitRepoURL = repoURL
+	if input.Ref != "" && (!gitRefPattern.MatchString(input.Ref) || strings.Contains(input.Ref, "..")) {
+		return nil, fmt.Errorf("%w: invalid ref %q", ErrInvalidCloneRequest, input.Ref)
+	}
+
+	if !s.configProfileService.HasGitHubTokens() && !s.githubService.HasToken() {
+		return nil, ErrNoGitHubTokenConfigured
+	}
+
+	// Refuse to clone over something that is already there
+	targetDir := "/workspace/" + repoName
+	exitCode, err := s.dockerClient.ExecStream(ctx, container.DockerID,
+		[]string{"bash", "-c", fmt.Sprintf("test -e %s", shellQuote(targetDir))}, io.Discard, io.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check target directory: %w", err)
+	}
+	if exitCode == 0 {
+		return nil, fmt.Errorf("%w: %s already exists", ErrRepoAlreadyPresent, targetDir)
+	}
+
+	// Register the task before returning so a second request sees it in progress
+	initCtx, done, err := s.startInitTask(context.WithoutCancel(ctx), id, 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(container).Updates(map[string]interface{}{
+		"git_repo_url":  input.GitRepoURL,
+		"git_repo_name": repoName,
+		"clone_lfs":     input.CloneLFS,
+	}).Error; err != nil {
+		done()
+		return nil, err
+	}
+	s.updateInitStatus(ctx, id, models.InitStatusCloning, "Cloning repository...")
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer done()
+		s.runCloneIntoContainer(initCtx, id, input.Ref, input.RunClaudeInit)
+	}()
+
+	return s.GetContainer(id)
+}
+
+// runCloneIntoContainer clones the container's configured repository and moves its work dir there
+func (s *ContainerService) runCloneIntoContainer(ctx context.Context, containerID uint, ref string, runClaudeInit bool) {
+	timing := s.newInitTimingRecorder(containerID)
+
+	container, err := s.GetContainer(containerID)
+	if err != nil {
+		s.addLog(ctx, containerID, models.LogLevelError, models.LogStageClone, fmt.Sprintf("Failed to get container: %v", err))
+		return
+	}
+
+	// On failure the container stays an empty container so the clone can be retried
+	fail := func(stage int, message string) {
+		timing.end(stage, models.InitStageStatusFailed)
+		s.db.Model(&models.Container{}).Where("id = ?", containerID).Updates(map[string]interface{}{
+			"git_repo_url":  "",
+			"git_repo_name": "",
+		})
+		s.addLog(ctx, containerID, models.LogLevelError, models.LogStageClone, message)
+		s.updateInitStatus(ctx, containerID, models.InitStatusFailed, message)
+	}
+
+	s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageClone, fmt.Sprintf("Cloning repository into existing container: %s", container.GitRepoURL))
+	cloneStage := timing.begin(models.InitStageClone)
+	if err := s.cloneRepository(ctx, container); err != nil {
+		fail(cloneStage, fmt.Sprintf("Clone failed: %v", err))
+		return
+	}
+
+	repoDir := "/workspace/" + container.GitRepoName
+	if code, err := s.dockerClient.ExecStream(ctx, container.DockerID,
+		[]string{"bash", "-c", fmt.Sprintf("test -d %s", shellQuote(repoDir+"/.git"))}, io.Discard, io.Discard); err != nil || code != 0 {
+		fail(cloneStage, "Clone failed: repository was not created (check the URL and token)")
+		return
+	}
+
+	if ref != "" {
+		var output bytes.Buffer
+		code, err := s.dockerClient.ExecStream(ctx, container.DockerID,
+			[]string{"bash", "-c", fmt.Sprintf("cd %s && git checkout %s 2>&1", shellQuote(repoDir), shellQuote(ref))}, &output, &output)
+		if err != nil || code != 0 {
+			fail(cloneStage, fmt.Sprintf("Checkout of %s failed: %s", ref, strings.TrimSpace(output.String())))
+			return
+		}
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageClone, fmt.Sprintf("Checked out %s", ref))
+	}
+	s.pullGitLFS(ctx, container, repoDir)
+	timing.end(cloneStage, models.InitStageStatusSucceeded)
+
+	if err := s.db.Model(&models.Container{}).Where("id = ?", containerID).Updates(map[string]interface{}{
+		"work_dir":      repoDir,
+		"skip_git_repo": false,
+	}).Error; err != nil {
+		s.addLog(ctx, containerID, models.LogLevelError, models.LogStageClone, fmt.Sprintf("Failed to update container: %v", err))
+		s.updateInitStatus(ctx, containerID, models.InitStatusFailed, fmt.Sprintf("Failed to update container: %v", err))
+		return
+	}
+	s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageClone, "Repository cloned successfully")
+	container.WorkDir = repoDir
+
+	if runClaudeInit {
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit, "Starting Claude Code initialization...")
+		s.updateInitStatus(ctx, containerID, models.InitStatusInitializing, "Initializing project environment...")
+
+		initStage := timing.begin(models.InitStageClaudeInit)
+		if err := s.runClaudeInit(ctx, container); err != nil {
+			timing.end(initStage, models.InitStageStatusFailed)
+			s.addLog(ctx, containerID, models.LogLevelError, models.LogStageInit, fmt.Sprintf("Initialization failed: %v", err))
+			s.updateInitStatus(ctx, containerID, models.InitStatusFailed, fmt.Sprintf("Initialization failed: %v", err))
+			return
+		}
+		timing.end(initStage, models.InitStageStatusSucceeded)
+	} else {
+		timing.skip(models.InitStageClaudeInit)
+	}
+
+	if !s.markInitReady(ctx, containerID) {
+		return
+	}
+	s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageReady, "Repository is ready in "+repoDir)
+	s.notifyInitCallback(ctx, containerID)
+}
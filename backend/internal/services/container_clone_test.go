@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var cloneTestDBCounter int
+
+func setupCloneTestService(t *testing.T) *ContainerService {
+	t.Helper()
+
+	cloneTestDBCounter++
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:clonedb%d?mode=memory&cache=shared", cloneTestDBCounter)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Container{}, &models.ContainerLog{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return &ContainerService{db: db}
+}
+
+func createCloneTestContainer(t *testing.T, s *ContainerService, c *models.Container) *models.Container {
+	t.Helper()
+	if err := s.db.Create(c).Error; err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	return c
+}
+
+func TestCloneRepoIntoContainer_Guards(t *testing.T) {
+	s := setupCloneTestService(t)
+	ctx := context.Background()
+
+	stopped := createCloneTestContainer(t, s, &models.Container{DockerID: "stopped", Name: "stopped", Status: models.ContainerStatusStopped, SkipGitRepo: true})
+	withRepo := createCloneTestContainer(t, s, &models.Container{DockerID: "repo", Name: "repo", Status: models.ContainerStatusRunning, GitRepoURL: "https://github.com/a/b"})
+	empty := createCloneTestContainer(t, s, &models.Container{DockerID: "empty", Name: "empty", Status: models.ContainerStatusRunning, SkipGitRepo: true})
+
+	input := CloneRepoInput{GitRepoURL: "https://github.com/acme/widgets.git"}
+
+	if _, err := s.CloneRepoIntoContainer(ctx, 9999, input); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+	if _, err := s.CloneRepoIntoContainer(ctx, stopped.ID, input); !errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("Expected ErrContainerNotRunning, got %v", err)
+	}
+	if _, err := s.CloneRepoIntoContainer(ctx, withRepo.ID, input); !errors.Is(err, ErrRepoAlreadyPresent) {
+		t.Errorf("Expected ErrRepoAlreadyPresent, got %v", err)
+	}
+
+	s.initTasks.Store(empty.ID, context.CancelCauseFunc(func(error) {}))
+	if _, err := s.CloneRepoIntoContainer(ctx, empty.ID, input); !errors.Is(err, ErrInitInProgress) {
+		t.Errorf("Expected ErrInitInProgress, got %v", err)
+	}
+	s.initTasks.Delete(empty.ID)
+
+	invalid := []CloneRepoInput{
+		{GitRepoURL: "  "},
+		{GitRepoURL: "https://github.com/acme/widgets", GitRepoName: "../etc"},
+		{GitRepoURL: "https://github.com/acme/widgets", GitRepoName: "a b"},
+		{GitRepoURL: "https://github.com/acme/widgets", Ref: "main; rm -rf /"},
+		{GitRepoURL: "https://github.com/acme/widgets", Ref: "-b evil"},
+		{GitRepoURL: "https://github.com/acme/widgets", Ref: "main..dev"},
+	}
+	for _, in := range invalid {
+		if _, err := s.CloneRepoIntoContainer(ctx, empty.ID, in); !errors.Is(err, ErrInvalidCloneRequest) {
+			t.Errorf("Expected ErrInvalidCloneRequest for %+v, got %v", in, err)
+		}
+	}
+}
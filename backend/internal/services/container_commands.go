@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrContainerCommandNotFound is returned when a container has no saved command with the name
+	ErrContainerCommandNotFound = errors.New("saved command not found")
+	// ErrDuplicateContainerCommand is returned when the container already has a command with the name
+	ErrDuplicateContainerCommand = errors.New("a saved command with this name already exists")
+	// ErrInvalidContainerCommand is returned for an invalid name, an empty command or a bad timeout
+	ErrInvalidContainerCommand = errors.New("invalid saved command")
+)
+
+// maxCommandOutputBytes is how much of the end of a run's output is kept with the command
+const maxCommandOutputBytes = 64 * 1024
+
+// containerCommandNamePattern limits names to what fits in a URL path segment unescaped
+var containerCommandNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// commandExecStreamer runs a command in a container and streams its output
+type commandExecStreamer interface {
+	ExecStream(ctx context.Context, id uint, cmd []string, stdout, stderr io.Writer) (int, error)
+}
+
+// ContainerCommandInput is the body for creating or replacing a saved command
+type ContainerCommandInput struct {
+	Name           string   `json:"name"`
+	Command        []string `json:"command"`
+	TimeoutSeconds int      `json:"timeout_seconds"`
+}
+
+// ContainerCommandService manages the saved commands of containers and runs them by name
+type ContainerCommandService struct {
+	db           *gorm.DB
+	execStreamer commandExecStreamer
+}
+
+// NewContainerCommandService creates a new ContainerCommandService
+func NewContainerCommandService(db *gorm.DB, containerService *ContainerService) *ContainerCommandService {
+	return &ContainerCommandService{db: db, execStreamer: containerService}
+}
+
+// validateContainerCommandInput checks the name, command and timeout of a saved command
+func validateContainerCommandInput(input ContainerCommandInput) error {
+	if !containerCommandNamePattern.MatchString(input.Name) {
+		return fmt.Errorf("%w: name must be 1-64 letters, digits, '.', '_' or '-' and start with a letter or digit", ErrInvalidContainerCommand)
+	}
+	if len(input.Command) == 0 || input.Command[0] == "" {
+		return fmt.Errorf("%w: command cannot be empty", ErrInvalidContainerCommand)
+	}
+	if input.TimeoutSeconds < 0 {
+		return fmt.Errorf("%w: timeout_seconds cannot be negative", ErrInvalidContainerCommand)
+	}
+	if time.Duration(input.TimeoutSeconds)*time.Second > constants.ExecMaxTimeout {
+		return fmt.Errorf("%w: timeout_seconds cannot exceed %d", ErrInvalidContainerCommand, int(constants.ExecMaxTimeout.Seconds()))
+	}
+	return nil
+}
+
+// checkContainer returns ErrContainerNotFound unless the container exists
+func (s *ContainerCommandService) checkContainer(containerID uint) error {
+	var count int64
+	if err := s.db.Model(&models.Container{}).Where("id = ?", containerID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrContainerNotFound
+	}
+	return nil
+}
+
+// List returns the saved commands of a container sorted by name
+func (s *ContainerCommandService) List(containerID uint) ([]models.ContainerCommand, error) {
+	if err := s.checkContainer(containerID); err != nil {
+		return nil, err
+	}
+	commands := []models.ContainerCommand{}
+	if err := s.db.Where("container_id = ?", containerID).Order("name").Find(&commands).Error; err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// Get returns a saved command of a container by name
+func (s *ContainerCommandService) Get(containerID uint, name string) (*models.ContainerCommand, error) {
+	if err := s.checkContainer(containerID); err != nil {
+		return nil, err
+	}
+	var command models.ContainerCommand
+	if err := s.db.Where("container_id = ? AND name = ?", containerID, name).First(&command).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: '%s'", ErrContainerCommandNotFound, name)
+		}
+		return nil, err
+	}
+	return &command, nil
+}
+
+// Create saves a command for a container
+func (s *ContainerCommandService) Create(containerID uint, input ContainerCommandInput) (*models.ContainerCommand, error) {
+	if err := validateContainerCommandInput(input); err != nil {
+		return nil, err
+	}
+	if err := s.checkContainer(containerID); err != nil {
+		return nil, err
+	}
+	command := models.ContainerCommand{
+		ContainerID:    containerID,
+		Name:           input.Name,
+		Command:        input.Command,
+		TimeoutSeconds: input.TimeoutSeconds,
+	}
+	if err := s.db.Create(&command).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, fmt.Errorf("%w: '%s'", ErrDuplicateContainerCommand, input.Name)
+		}
+		return nil, err
+	}
+	return &command, nil
+}
+
+// Update replaces the name, command and timeout of a saved command. The latest run is kept.
+func (s *ContainerCommandService) Update(containerID uint, name string, input ContainerCommandInput) (*models.ContainerCommand, error) {
+	if err := validateContainerCommandInput(input); err != nil {
+		return nil, err
+	}
+	command, err := s.Get(containerID, name)
+	if err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{
+		"name":            input.Name,
+		"command":         models.CommandArgList(input.Command),
+		"timeout_seconds": input.TimeoutSeconds,
+	}
+	if err := s.db.Model(command).Updates(updates).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, fmt.Errorf("%w: '%s'", ErrDuplicateContainerCommand, input.Name)
+		}
+		return nil, err
+	}
+	return s.Get(containerID, input.Name)
+}
+
+// Delete removes a saved command of a container
+func (s *ContainerCommandService) Delete(containerID uint, name string) error {
+	command, err := s.Get(containerID, name)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(command).Error
+}
+
+// Timeout returns how long a saved command may run
+func (s *ContainerCommandService) Timeout(command *models.ContainerCommand) time.Duration {
+	if command.TimeoutSeconds > 0 {
+		return time.Duration(command.TimeoutSeconds) * time.Second
+	}
+	return constants.ExecDefaultTimeout
+}
+
+// Run executes a saved command in its container, streaming output to stdout and stderr, and
+// records the exit code, the tail of the output and how long it took. The caller bounds the
+// run with Timeout through ctx.
+func (s *ContainerCommandService) Run(ctx context.Context, command *models.ContainerCommand, stdout, stderr io.Writer) (int, error) {
+	output := &outputTail{limit: maxCommandOutputBytes}
+	start := time.Now()
+	exitCode, err := s.execStreamer.ExecStream(ctx, command.ContainerID, command.Command,
+		io.MultiWriter(stdout, output), io.MultiWriter(stderr, output))
+
+	// Nothing ran: keep the previous result
+	if err != nil && (errors.Is(err, ErrContainerNotFound) || errors.Is(err, ErrContainerNotRunning) || errors.Is(err, ErrDockerUnavailable)) {
+		return exitCode, err
+	}
+
+	updates := map[string]interface{}{
+		"last_run_at":      start,
+		"last_exit_code":   nil,
+		"last_error":       "",
+		"last_output":      output.String(),
+		"last_duration_ms": time.Since(start).Milliseconds(),
+	}
+	switch {
+	case err == nil:
+		updates["last_exit_code"] = exitCode
+	case errors.Is(err, context.DeadlineExceeded):
+		updates["last_error"] = fmt.Sprintf("timed out after %s", s.Timeout(command))
+	default:
+		updates["last_error"] = err.Error()
+	}
+	if dbErr := s.db.Model(&models.ContainerCommand{}).Where("id = ?", command.ID).Updates(updates).Error; dbErr != nil {
+		log.Printf("Warning: failed to record the run of saved command '%s' of container %d: %v", command.Name, command.ContainerID, dbErr)
+	}
+	return exitCode, err
+}
+
+// outputTail keeps the last limit bytes written to it
+type outputTail struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+}
+
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.limit; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the kept output, dropping a multi-byte character cut off at the start
+func (t *outputTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data := t.buf
+	for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.RuneStart(data[0]); i++ {
+		data = data[1:]
+	}
+	return string(data)
+}
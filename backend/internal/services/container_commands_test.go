@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeCommandExec writes canned output, or blocks until the run's context ends
+type fakeCommandExec struct {
+	stdout, stderr string
+	exitCode       int
+	err            error
+	block          bool
+	gotID          uint
+	gotCmd         []string
+}
+
+func (f *fakeCommandExec) ExecStream(ctx context.Context, id uint, cmd []string, stdout, stderr io.Writer) (int, error) {
+	f.gotID, f.gotCmd = id, cmd
+	if f.err != nil {
+		return -1, f.err
+	}
+	io.WriteString(stdout, f.stdout)
+	io.WriteString(stderr, f.stderr)
+	if f.block {
+		<-ctx.Done()
+		return -1, ctx.Err()
+	}
+	return f.exitCode, nil
+}
+
+func setupContainerCommandTest(t *testing.T) (*ContainerCommandService, *fakeCommandExec, *models.Container) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Container{}, &models.ContainerCommand{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	container := &models.Container{Name: "commands", DockerID: "commands-0123456789ab", Status: models.ContainerStatusRunning}
+	if err := db.Create(container).Error; err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	exec := &fakeCommandExec{}
+	return &ContainerCommandService{db: db, execStreamer: exec}, exec, container
+}
+
+func TestContainerCommands_CRUD(t *testing.T) {
+	s, _, container := setupContainerCommandTest(t)
+
+	test, err := s.Create(container.ID, ContainerCommandInput{Name: "test", Command: []string{"go", "test", "./..."}, TimeoutSeconds: 300})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !reflect.DeepEqual([]string(test.Command), []string{"go", "test", "./..."}) || test.TimeoutSeconds != 300 {
+		t.Errorf("Unexpected command: %+v", test)
+	}
+	if _, err := s.Create(container.ID, ContainerCommandInput{Name: "build", Command: []string{"make"}}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := s.Create(container.ID, ContainerCommandInput{Name: "test", Command: []string{"true"}}); !errors.Is(err, ErrDuplicateContainerCommand) {
+		t.Errorf("Expected ErrDuplicateContainerCommand, got %v", err)
+	}
+
+	invalid := []ContainerCommandInput{
+		{Name: "", Command: []string{"make"}},
+		{Name: "has space", Command: []string{"make"}},
+		{Name: "a/b", Command: []string{"make"}},
+		{Name: "-lint", Command: []string{"make"}},
+		{Name: "lint", Command: nil},
+		{Name: "lint", Command: []string{""}},
+		{Name: "lint", Command: []string{"make"}, TimeoutSeconds: -1},
+		{Name: "lint", Command: []string{"make"}, TimeoutSeconds: 31 * 60},
+	}
+	for _, input := range invalid {
+		if _, err := s.Create(container.ID, input); !errors.Is(err, ErrInvalidContainerCommand) {
+			t.Errorf("Expected ErrInvalidContainerCommand for %+v, got %v", input, err)
+		}
+	}
+	if _, err := s.Create(9999, ContainerCommandInput{Name: "lint", Command: []string{"make"}}); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+
+	commands, err := s.List(container.ID)
+	if err != nil || len(commands) != 2 || commands[0].Name != "build" || commands[1].Name != "test" {
+		t.Fatalf("Expected build and test sorted by name, got %+v (%v)", commands, err)
+	}
+
+	updated, err := s.Update(container.ID, "test", ContainerCommandInput{Name: "unit-test", Command: []string{"go", "test", "-short", "./..."}})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.ID != test.ID || updated.Name != "unit-test" || len(updated.Command) != 4 || updated.TimeoutSeconds != 0 {
+		t.Errorf("Expected the command to be renamed and replaced, got %+v", updated)
+	}
+	if _, err := s.Get(container.ID, "test"); !errors.Is(err, ErrContainerCommandNotFound) {
+		t.Errorf("Expected the old name to be gone, got %v", err)
+	}
+	if _, err := s.Update(container.ID, "unit-test", ContainerCommandInput{Name: "build", Command: []string{"make"}}); !errors.Is(err, ErrDuplicateContainerCommand) {
+		t.Errorf("Expected a rename onto another command to be rejected, got %v", err)
+	}
+
+	if err := s.Delete(container.ID, "build"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := s.Delete(container.ID, "build"); !errors.Is(err, ErrContainerCommandNotFound) {
+		t.Errorf("Expected ErrContainerCommandNotFound, got %v", err)
+	}
+	if commands, _ := s.List(container.ID); len(commands) != 1 {
+		t.Errorf("Expected 1 command left, got %d", len(commands))
+	}
+}
+
+func TestContainerCommands_RunByName(t *testing.T) {
+	s, exec, container := setupContainerCommandTest(t)
+	ctx := context.Background()
+
+	if _, err := s.Create(container.ID, ContainerCommandInput{Name: "lint", Command: []string{"npm", "run", "lint"}, TimeoutSeconds: 1}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	command, err := s.Get(container.ID, "lint")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if s.Timeout(command) != time.Second {
+		t.Errorf("Expected the saved timeout, got %s", s.Timeout(command))
+	}
+
+	exec.stdout, exec.stderr, exec.exitCode = "2 problems\n", "warning: stale cache\n", 1
+	var stdout, stderr strings.Builder
+	exitCode, err := s.Run(ctx, command, &stdout, &stderr)
+	if err != nil || exitCode != 1 {
+		t.Fatalf("Expected exit code 1, got %d (%v)", exitCode, err)
+	}
+	if exec.gotID != container.ID || !reflect.DeepEqual(exec.gotCmd, []string{"npm", "run", "lint"}) {
+		t.Errorf("Expected the saved command to run in its container, got %d %v", exec.gotID, exec.gotCmd)
+	}
+	if stdout.String() != "2 problems\n" || stderr.String() != "warning: stale cache\n" {
+		t.Errorf("Expected output to be streamed, got %q and %q", stdout.String(), stderr.String())
+	}
+	command, _ = s.Get(container.ID, "lint")
+	if command.LastRunAt == nil || command.LastExitCode == nil || *command.LastExitCode != 1 || command.LastError != "" ||
+		!strings.Contains(command.LastOutput, "2 problems") || !strings.Contains(command.LastOutput, "stale cache") {
+		t.Errorf("Expected the run to be recorded, got %+v", command)
+	}
+
+	// A timed out run has no exit code
+	exec.block = true
+	runCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Run(runCtx, command, io.Discard, io.Discard); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+	command, _ = s.Get(container.ID, "lint")
+	if command.LastExitCode != nil || command.LastError != "timed out after 1s" {
+		t.Errorf("Expected a recorded timeout, got exit code %v and error %q", command.LastExitCode, command.LastError)
+	}
+
+	// A run that never started keeps the previous result
+	exec.err = ErrContainerNotRunning
+	if _, err := s.Run(ctx, command, io.Discard, io.Discard); !errors.Is(err, ErrContainerNotRunning) {
+		t.Fatalf("Expected ErrContainerNotRunning, got %v", err)
+	}
+	if again, _ := s.Get(container.ID, "lint"); again.LastError != "timed out after 1s" {
+		t.Errorf("Expected the previous result to be kept, got %+v", again)
+	}
+}
+
+func TestOutputTail(t *testing.T) {
+	tail := &outputTail{limit: 8}
+	io.WriteString(tail, "0123")
+	io.WriteString(tail, "456789")
+	if got := tail.String(); got != "23456789" {
+		t.Errorf("Expected the last 8 bytes, got %q", got)
+	}
+
+	// A character split by the limit is dropped rather than kept half
+	tail = &outputTail{limit: 4}
+	io.WriteString(tail, "aé€")
+	if got := tail.String(); got != "€" {
+		t.Errorf("Expected the cut character to be dropped, got %q", got)
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"cc-platform/internal/models"
+)
+
+var (
+	// ErrNoDetailChanges is returned when a details update sets neither notes nor metadata
+	ErrNoDetailChanges = errors.New("no container details to update")
+	// ErrInvalidContainerDetails is returned when notes or metadata exceed their limits
+	ErrInvalidContainerDetails = errors.New("invalid container details")
+)
+
+// Size limits of a container's notes and metadata
+const (
+	containerNotesMaxLength         = 10000 // characters
+	containerMetadataMaxEntries     = 50
+	containerMetadataKeyMaxLength   = 100 // characters
+	containerMetadataValueMaxLength = 1000
+)
+
+// UpdateContainerDetailsInput changes a container's notes and metadata. Nil fields are left
+// unchanged; metadata replaces the whole map, so an empty object clears it.
+type UpdateContainerDetailsInput struct {
+	Notes    *string            `json:"notes,omitempty"`
+	Metadata *map[string]string `json:"metadata,omitempty"`
+}
+
+// UpdateContainerDetails stores the notes and metadata of a container. Unlike most updates it
+// needs no Docker call, so it works in any container state.
+func (s *ContainerService) UpdateContainerDetails(id uint, input UpdateContainerDetailsInput) (*models.Container, error) {
+	dbContainer, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Notes == nil && input.Metadata == nil {
+		return nil, ErrNoDetailChanges
+	}
+
+	updates := map[string]interface{}{}
+	if input.Notes != nil {
+		notes := strings.TrimSpace(*input.Notes)
+		if utf8.RuneCountInString(notes) > containerNotesMaxLength {
+			return nil, fmt.Errorf("%w: notes must be at most %d characters", ErrInvalidContainerDetails, containerNotesMaxLength)
+		}
+		updates["notes"] = notes
+	}
+	if input.Metadata != nil {
+		metadata, err := normalizeContainerMetadata(*input.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		updates["metadata"] = metadata
+	}
+
+	if err := s.db.Model(dbContainer).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return s.GetContainer(id)
+}
+
+// normalizeContainerMetadata trims metadata keys and checks the entry count and sizes
+func normalizeContainerMetadata(metadata map[string]string) (models.ContainerMetadata, error) {
+	if len(metadata) > containerMetadataMaxEntries {
+		return nil, fmt.Errorf("%w: metadata must have at most %d entries", ErrInvalidContainerDetails, containerMetadataMaxEntries)
+	}
+	normalized := make(models.ContainerMetadata, len(metadata))
+	for key, value := range metadata {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("%w: metadata keys must not be empty", ErrInvalidContainerDetails)
+		}
+		if utf8.RuneCountInString(key) > containerMetadataKeyMaxLength {
+			return nil, fmt.Errorf("%w: metadata key %q is longer than %d characters", ErrInvalidContainerDetails, key, containerMetadataKeyMaxLength)
+		}
+		if utf8.RuneCountInString(value) > containerMetadataValueMaxLength {
+			return nil, fmt.Errorf("%w: metadata value of %q is longer than %d characters", ErrInvalidContainerDetails, key, containerMetadataValueMaxLength)
+		}
+		if _, dup := normalized[key]; dup {
+			return nil, fmt.Errorf("%w: duplicate metadata key %q", ErrInvalidContainerDetails, key)
+		}
+		normalized[key] = value
+	}
+	return normalized, nil
+}
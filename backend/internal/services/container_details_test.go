@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func TestUpdateContainerDetails(t *testing.T) {
+	s := setupPauseTestService(t)
+	c := createPauseTestContainer(t, s, "details", models.ContainerStatusStopped)
+
+	notes := "  Reproduces the login bug\nTODO: bisect  "
+	metadata := map[string]string{" owner ": "alice", "ticket": "OPS-42"}
+	if _, err := s.UpdateContainerDetails(c.ID, UpdateContainerDetailsInput{Notes: &notes, Metadata: &metadata}); err != nil {
+		t.Fatalf("UpdateContainerDetails failed: %v", err)
+	}
+	stored, err := s.GetContainer(c.ID)
+	if err != nil {
+		t.Fatalf("GetContainer failed: %v", err)
+	}
+	info := ToContainerInfo(stored)
+	if info.Notes != "Reproduces the login bug\nTODO: bisect" {
+		t.Errorf("Notes = %q", info.Notes)
+	}
+	if len(info.Metadata) != 2 || info.Metadata["owner"] != "alice" || info.Metadata["ticket"] != "OPS-42" {
+		t.Errorf("Metadata = %v", info.Metadata)
+	}
+
+	// Updating only the metadata keeps the notes; an empty map clears the metadata
+	empty := map[string]string{}
+	updated, err := s.UpdateContainerDetails(c.ID, UpdateContainerDetailsInput{Metadata: &empty})
+	if err != nil {
+		t.Fatalf("UpdateContainerDetails failed: %v", err)
+	}
+	if updated.Notes != "Reproduces the login bug\nTODO: bisect" || len(updated.Metadata) != 0 {
+		t.Errorf("Unexpected details after clearing metadata: notes=%q metadata=%v", updated.Notes, updated.Metadata)
+	}
+
+	if _, err := s.UpdateContainerDetails(c.ID, UpdateContainerDetailsInput{}); !errors.Is(err, ErrNoDetailChanges) {
+		t.Errorf("Expected ErrNoDetailChanges, got %v", err)
+	}
+	if _, err := s.UpdateContainerDetails(9999, UpdateContainerDetailsInput{Notes: &notes}); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+}
+
+func TestUpdateContainerDetails_Limits(t *testing.T) {
+	s := setupPauseTestService(t)
+	c := createPauseTestContainer(t, s, "limits", models.ContainerStatusRunning)
+
+	tooManyEntries := make(map[string]string)
+	for i := 0; i <= containerMetadataMaxEntries; i++ {
+		tooManyEntries[strings.Repeat("k", i+1)] = "v"
+	}
+	longNotes := strings.Repeat("n", containerNotesMaxLength+1)
+	tests := []struct {
+		name  string
+		input UpdateContainerDetailsInput
+	}{
+		{name: "notes too long", input: UpdateContainerDetailsInput{Notes: &longNotes}},
+		{name: "too many entries", input: UpdateContainerDetailsInput{Metadata: &tooManyEntries}},
+		{name: "empty key", input: UpdateContainerDetailsInput{Metadata: &map[string]string{" ": "v"}}},
+		{name: "key too long", input: UpdateContainerDetailsInput{Metadata: &map[string]string{strings.Repeat("k", containerMetadataKeyMaxLength+1): "v"}}},
+		{name: "value too long", input: UpdateContainerDetailsInput{Metadata: &map[string]string{"k": strings.Repeat("v", containerMetadataValueMaxLength+1)}}},
+		{name: "keys equal after trimming", input: UpdateContainerDetailsInput{Metadata: &map[string]string{"k": "a", " k": "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.UpdateContainerDetails(c.ID, tt.input); !errors.Is(err, ErrInvalidContainerDetails) {
+				t.Errorf("Expected ErrInvalidContainerDetails, got %v", err)
+			}
+		})
+	}
+
+	// Limits count characters, not bytes
+	multiByte := strings.Repeat("é", containerNotesMaxLength)
+	if _, err := s.UpdateContainerDetails(c.ID, UpdateContainerDetailsInput{Notes: &multiByte}); err != nil {
+		t.Errorf("Expected notes at the limit to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"cc-platform/internal/constants"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// ErrInvalidLogsOption is returned for a malformed tail or since value
+var ErrInvalidLogsOption = errors.New("invalid docker logs option")
+
+// DockerLogsOptions selects which Docker container output is streamed
+type DockerLogsOptions struct {
+	Follow     bool
+	Tail       string // Number of trailing lines, or "all"; defaults to constants.DockerLogsDefaultTail
+	Since      string // RFC 3339 time, Unix timestamp or duration such as "10m"
+	Timestamps bool
+}
+
+// StreamDockerLogs writes the stdout and stderr of the container's processes to w.
+// Unlike GetContainerLogs, which returns the platform's own log records, this reads the
+// Docker logs. With Follow it streams until the container stops or ctx is cancelled.
+func (s *ContainerService) StreamDockerLogs(ctx context.Context, id uint, opts DockerLogsOptions, w io.Writer) error {
+	logsOptions, err := buildDockerLogsOptions(opts, time.Now())
+	if err != nil {
+		return err
+	}
+
+	c, err := s.GetContainer(id)
+	if err != nil {
+		return err
+	}
+	if c.DockerID == "" {
+		return ErrContainerNotRunning
+	}
+	return dockerError(s.dockerClient.StreamLogs(ctx, c.DockerID, logsOptions, w))
+}
+
+// buildDockerLogsOptions validates the options and converts them for the Docker API
+func buildDockerLogsOptions(opts DockerLogsOptions, now time.Time) (container.LogsOptions, error) {
+	result := container.LogsOptions{
+		Follow:     opts.Follow,
+		Timestamps: opts.Timestamps,
+		Tail:       strconv.Itoa(constants.DockerLogsDefaultTail),
+	}
+
+	tail := strings.TrimSpace(opts.Tail)
+	if tail != "" {
+		if n, err := strconv.Atoi(tail); tail != "all" && (err != nil || n < 0) {
+			return result, fmt.Errorf("%w: tail must be a non-negative number or \"all\"", ErrInvalidLogsOption)
+		}
+		result.Tail = tail
+	}
+
+	since := strings.TrimSpace(opts.Since)
+	if since == "" {
+		return result, nil
+	}
+	if d, err := time.ParseDuration(since); err == nil {
+		if d < 0 {
+			return result, fmt.Errorf("%w: since duration cannot be negative", ErrInvalidLogsOption)
+		}
+		result.Since = strconv.FormatInt(now.Add(-d).Unix(), 10)
+	} else if t, err := time.Parse(time.RFC3339, since); err == nil {
+		result.Since = strconv.FormatInt(t.Unix(), 10)
+	} else if _, err := strconv.ParseInt(since, 10, 64); err == nil {
+		result.Since = since
+	} else {
+		return result, fmt.Errorf("%w: since must be an RFC 3339 time, Unix timestamp or duration", ErrInvalidLogsOption)
+	}
+	return result, nil
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBuildDockerLogsOptions(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	testCases := []struct {
+		opts  DockerLogsOptions
+		tail  string
+		since string
+	}{
+		{DockerLogsOptions{}, "200", ""},
+		{DockerLogsOptions{Tail: "all", Follow: true}, "all", ""},
+		{DockerLogsOptions{Tail: "0"}, "0", ""},
+		{DockerLogsOptions{Tail: "50", Since: "10m"}, "50", "1699999400"},
+		{DockerLogsOptions{Since: "2023-11-14T22:13:20Z"}, "200", "1700000000"},
+		{DockerLogsOptions{Since: "1699990000"}, "200", "1699990000"},
+	}
+	for _, tc := range testCases {
+		got, err := buildDockerLogsOptions(tc.opts, now)
+		if err != nil {
+			t.Errorf("%+v: unexpected error %v", tc.opts, err)
+			continue
+		}
+		if got.Tail != tc.tail || got.Since != tc.since || got.Follow != tc.opts.Follow {
+			t.Errorf("%+v: expected tail %q since %q, got %+v", tc.opts, tc.tail, tc.since, got)
+		}
+	}
+
+	for _, opts := range []DockerLogsOptions{{Tail: "-1"}, {Tail: "ten"}, {Since: "yesterday"}, {Since: "-5m"}} {
+		if _, err := buildDockerLogsOptions(opts, now); !errors.Is(err, ErrInvalidLogsOption) {
+			t.Errorf("%+v: expected ErrInvalidLogsOption, got %v", opts, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maskedEnvValue replaces secret values in env inspection output
+const maskedEnvValue = "****"
+
+// ContainerEnvVar is one environment variable of a container
+type ContainerEnvVar struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Redacted bool   `json:"redacted"`
+}
+
+// containerEnvVars assembles the env vars of an env profile, falling back to the legacy
+// Claude config when there is no profile service or the profile can't be read
+func (s *ContainerService) containerEnvVars(profileID *uint) (map[string]string, error) {
+	if s.configProfileService != nil {
+		if envVars, err := s.configProfileService.GetEnvVars(profileID); err == nil {
+			return envVars, nil
+		}
+	}
+	return s.claudeService.GetContainerEnvVars()
+}
+
+// dockerEnv builds the Docker environment of a new container: its env profile, the GitHub token
+// when a repository is cloned, CLAUDE_CODE_ALLOW_ROOT and the run-as user's home
+func (s *ContainerService) dockerEnv(input CreateContainerInput, runAsUser string, hasTokens bool) ([]string, error) {
+	// Get environment variables from profile (or legacy config as fallback)
+	envVars, err := s.containerEnvVars(input.EnvVarsProfileID)
+	if err != nil {
+		return nil, err
+	}
+	applyClaudeOverrides(envVars, input.ClaudeAPIBase, input.ClaudeModel, input.ClaudeAuthToken)
+
+	// Get GitHub token from profile (or legacy config as fallback) - only when not skipping git repo
+	if !input.SkipGitRepo {
+		var githubToken string
+		if s.configProfileService != nil && hasTokens {
+			githubToken, err = s.configProfileService.GetGitHubTokenForContainer(input.GitHubTokenID)
+			if err != nil {
+				// Fallback to legacy token
+				githubToken, err = s.githubService.GetToken()
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			githubToken, err = s.githubService.GetToken()
+			if err != nil {
+				return nil, err
+			}
+		}
+		envVars["GITHUB_TOKEN"] = githubToken
+	}
+
+	// Convert env vars map to slice
+	envSlice := make([]string, 0, len(envVars))
+	for k, v := range envVars {
+		envSlice = append(envSlice, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	// 添加 CLAUDE_CODE_ALLOW_ROOT=1 环境变量，允许 root 用户使用 yolo 模式
+	envSlice = append(envSlice, "CLAUDE_CODE_ALLOW_ROOT=1")
+	envSlice = append(envSlice, runAsUserEnv(runAsUser)...)
+	return envSlice, nil
+}
+
+// GetContainerEnv lists the environment variables of a container as Docker holds them in its
+// config, with secret values masked. This is the env the container was created with; later
+// profile edits only show up once the container is recreated.
+func (s *ContainerService) GetContainerEnv(ctx context.Context, id uint) ([]ContainerEnvVar, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.dockerClient.InspectContainer(ctx, container.DockerID)
+	if err != nil {
+		return nil, dockerError(err)
+	}
+	var entries []string
+	if info.Config != nil {
+		entries = info.Config.Env
+	}
+	return inspectedContainerEnv(entries), nil
+}
+
+// inspectedContainerEnv redacts the KEY=value entries of Docker's container config; for a
+// repeated name the last entry wins, as in the container
+func inspectedContainerEnv(entries []string) []ContainerEnvVar {
+	env := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name, value, _ := strings.Cut(entry, "=")
+		if name != "" {
+			env[name] = value
+		}
+	}
+	return redactContainerEnv(env)
+}
+
+// redactContainerEnv lists env vars sorted by name, masking values whose name or content
+// looks like a credential
+func redactContainerEnv(env map[string]string) []ContainerEnvVar {
+	result := make([]ContainerEnvVar, 0, len(env))
+	for name, value := range env {
+		v := ContainerEnvVar{Name: name, Value: value}
+		if value != "" && (sensitiveKeyPattern.MatchString(name) || secretValuePattern.MatchString(value)) {
+			v.Value = maskedEnvValue
+			v.Redacted = true
+		}
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
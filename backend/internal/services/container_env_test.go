@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestRedactContainerEnv(t *testing.T) {
+	env := redactContainerEnv(map[string]string{
+		"ANTHROPIC_BASE_URL":   "https://api.example.com",
+		"ANTHROPIC_AUTH_TOKEN": "abc123",
+		"OPENAI_API_KEY":       "sk-live",
+		"DB_PASSWORD":          "hunter2",
+		"CLIENT_SECRET":        "s3cret",
+		"ANTHROPIC_MODEL":      "claude-sonnet",
+		"CUSTOM_HEADER":        "Bearer xyz", // value looks like a credential
+		"EMPTY_TOKEN":          "",
+	})
+
+	want := []ContainerEnvVar{
+		{Name: "ANTHROPIC_AUTH_TOKEN", Value: maskedEnvValue, Redacted: true},
+		{Name: "ANTHROPIC_BASE_URL", Value: "https://api.example.com"},
+		{Name: "ANTHROPIC_MODEL", Value: "claude-sonnet"},
+		{Name: "CLIENT_SECRET", Value: maskedEnvValue, Redacted: true},
+		{Name: "CUSTOM_HEADER", Value: maskedEnvValue, Redacted: true},
+		{Name: "DB_PASSWORD", Value: maskedEnvValue, Redacted: true},
+		{Name: "EMPTY_TOKEN", Value: ""},
+		{Name: "OPENAI_API_KEY", Value: maskedEnvValue, Redacted: true},
+	}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("redactContainerEnv =\n%+v\nwant\n%+v", env, want)
+	}
+}
+
+func TestInspectedContainerEnv(t *testing.T) {
+	env := inspectedContainerEnv([]string{
+		"PATH=/usr/local/bin:/usr/bin",
+		"ANTHROPIC_BASE_URL=https://api.example.com",
+		"ANTHROPIC_API_KEY=sk-ant-123",
+		"GITHUB_TOKEN=ghp_abc",
+		"CLAUDE_CODE_ALLOW_ROOT=1",
+		"EXTRA_HEADER=Bearer xyz",
+		"NO_VALUE",
+		"CLAUDE_CODE_ALLOW_ROOT=0", // the later entry wins
+	})
+
+	want := []ContainerEnvVar{
+		{Name: "ANTHROPIC_API_KEY", Value: maskedEnvValue, Redacted: true},
+		{Name: "ANTHROPIC_BASE_URL", Value: "https://api.example.com"},
+		{Name: "CLAUDE_CODE_ALLOW_ROOT", Value: "0"},
+		{Name: "EXTRA_HEADER", Value: maskedEnvValue, Redacted: true},
+		{Name: "GITHUB_TOKEN", Value: maskedEnvValue, Redacted: true},
+		{Name: "NO_VALUE", Value: ""},
+		{Name: "PATH", Value: "/usr/local/bin:/usr/bin"},
+	}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("inspectedContainerEnv =\n%+v\nwant\n%+v", env, want)
+	}
+}
+
+func TestGetContainerEnv_NotFound(t *testing.T) {
+	s := setupPauseTestService(t)
+	if _, err := s.GetContainerEnv(context.Background(), 9999); err != ErrContainerNotFound {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+)
+
+var (
+	// ErrInvalidIdempotencyKey is returned for an empty-looking or too long Idempotency-Key
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	// ErrIdempotencyKeyMismatch is returned when a key is reused with a different request body
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key was already used with a different request")
+	// ErrIdempotencyKeyInProgress is returned while the first request with a key is still creating its container
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// defaultIdempotencyKeyTTL is how long keys are remembered when no config is loaded
+const defaultIdempotencyKeyTTL = 24 * time.Hour
+
+// CreateContainerIdempotent creates a container like CreateContainer, remembering the result
+// under the user's idempotency key. A repeat of the same request with the same key returns the
+// original container and replayed=true instead of creating another one. An empty key disables it.
+func (s *ContainerService) CreateContainerIdempotent(ctx context.Context, username, key string, input CreateContainerInput) (*models.Container, bool, error) {
+	if key == "" {
+		container, err := s.CreateContainer(ctx, input)
+		return container, false, err
+	}
+	if strings.TrimSpace(key) != key || len(key) > constants.IdempotencyKeyMaxLength {
+		return nil, false, fmt.Errorf("%w: must be 1-%d characters without surrounding spaces", ErrInvalidIdempotencyKey, constants.IdempotencyKeyMaxLength)
+	}
+	hash, err := idempotencyRequestHash(input)
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing, err := s.reserveIdempotencyKey(username, key, hash)
+	if err != nil || existing != nil {
+		return existing, existing != nil, err
+	}
+
+	container, err := s.CreateContainer(ctx, input)
+	if err != nil {
+		// A failed creation doesn't consume the key, so the client can retry it
+		s.db.Where(idempotencyKeyConditions(username, key)).Delete(&models.IdempotencyKey{})
+		return nil, false, err
+	}
+	if err := s.db.Model(&models.IdempotencyKey{}).Where(idempotencyKeyConditions(username, key)).
+		Update("container_id", container.ID).Error; err != nil {
+		s.logger(ctx, container.ID).Printf("Failed to record idempotency key: %v", err)
+	}
+	return container, false, nil
+}
+
+// reserveIdempotencyKey claims the key for a new creation. When the key already belongs to a
+// matching request whose container still exists, that container is returned instead.
+func (s *ContainerService) reserveIdempotencyKey(username, key, hash string) (*models.Container, error) {
+	now := time.Now()
+	// Expired keys are pruned lazily whenever a new one is reserved
+	s.db.Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+
+	record := models.IdempotencyKey{Username: username, Key: key, RequestHash: hash, ExpiresAt: now.Add(s.idempotencyKeyTTL())}
+	err := s.db.Create(&record).Error
+	if err == nil {
+		return nil, nil
+	}
+	if !isDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	var existing models.IdempotencyKey
+	if err := s.db.Where(idempotencyKeyConditions(username, key)).First(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing.RequestHash != hash {
+		return nil, ErrIdempotencyKeyMismatch
+	}
+	if existing.ContainerID == 0 {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	container, err := s.GetContainer(existing.ContainerID)
+	if err != nil && !errors.Is(err, ErrContainerNotFound) {
+		return nil, err
+	}
+	if container != nil && container.Status != models.ContainerStatusDeleted {
+		return container, nil
+	}
+
+	// The original container is gone, so the key is taken over for a fresh creation
+	result := s.db.Model(&models.IdempotencyKey{}).Where("id = ? AND container_id = ?", existing.ID, existing.ContainerID).
+		Updates(map[string]interface{}{"container_id": 0, "expires_at": now.Add(s.idempotencyKeyTTL())})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return nil, nil
+}
+
+// idempotencyKeyTTL returns how long keys are remembered
+func (s *ContainerService) idempotencyKeyTTL() time.Duration {
+	if s.config != nil && s.config.IdempotencyKeyTTL > 0 {
+		return s.config.IdempotencyKeyTTL
+	}
+	return defaultIdempotencyKeyTTL
+}
+
+// idempotencyKeyConditions selects one user's key
+func idempotencyKeyConditions(username, key string) map[string]interface{} {
+	return map[string]interface{}{"username": username, "key": key}
+}
+
+// idempotencyRequestHash fingerprints a create request so a reused key can be matched to its body
+func idempotencyRequestHash(input CreateContainerInput) (string, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash create request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+func setupIdempotencyTestService(t *testing.T) *ContainerService {
+	t.Helper()
+	s := setupPauseTestService(t)
+	if err := s.db.AutoMigrate(&models.IdempotencyKey{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return s
+}
+
+// completeIdempotencyKey reserves a key and records a container for it, like a finished creation
+func completeIdempotencyKey(t *testing.T, s *ContainerService, username, key string, input CreateContainerInput, containerID uint) {
+	t.Helper()
+	hash, err := idempotencyRequestHash(input)
+	if err != nil {
+		t.Fatalf("idempotencyRequestHash failed: %v", err)
+	}
+	if existing, err := s.reserveIdempotencyKey(username, key, hash); err != nil || existing != nil {
+		t.Fatalf("Expected a fresh reservation, got %v, %v", existing, err)
+	}
+	if err := s.db.Model(&models.IdempotencyKey{}).Where(idempotencyKeyConditions(username, key)).
+		Update("container_id", containerID).Error; err != nil {
+		t.Fatalf("failed to record container: %v", err)
+	}
+}
+
+func TestCreateContainerIdempotent_Replay(t *testing.T) {
+	s := setupIdempotencyTestService(t)
+	ctx := context.Background()
+	original := createPauseTestContainer(t, s, "original", models.ContainerStatusRunning)
+	input := CreateContainerInput{Name: "original", GitRepoURL: "https://github.com/org/repo.git"}
+	completeIdempotencyKey(t, s, "alice", "key-1", input, original.ID)
+
+	container, replayed, err := s.CreateContainerIdempotent(ctx, "alice", "key-1", input)
+	if err != nil {
+		t.Fatalf("CreateContainerIdempotent failed: %v", err)
+	}
+	if !replayed || container.ID != original.ID {
+		t.Errorf("Expected the original container to be replayed, got %+v (replayed=%v)", container, replayed)
+	}
+
+	var count int64
+	s.db.Model(&models.Container{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected no new container, found %d", count)
+	}
+}
+
+func TestCreateContainerIdempotent_MismatchedBody(t *testing.T) {
+	s := setupIdempotencyTestService(t)
+	ctx := context.Background()
+	original := createPauseTestContainer(t, s, "original", models.ContainerStatusRunning)
+	completeIdempotencyKey(t, s, "alice", "key-1", CreateContainerInput{Name: "original"}, original.ID)
+
+	_, _, err := s.CreateContainerIdempotent(ctx, "alice", "key-1", CreateContainerInput{Name: "other"})
+	if !errors.Is(err, ErrIdempotencyKeyMismatch) {
+		t.Errorf("Expected ErrIdempotencyKeyMismatch, got %v", err)
+	}
+}
+
+func TestReserveIdempotencyKey(t *testing.T) {
+	s := setupIdempotencyTestService(t)
+
+	// A key whose creation hasn't finished can't be replayed yet
+	if existing, err := s.reserveIdempotencyKey("alice", "pending", "h1"); err != nil || existing != nil {
+		t.Fatalf("Expected a fresh reservation, got %v, %v", existing, err)
+	}
+	if _, err := s.reserveIdempotencyKey("alice", "pending", "h1"); !errors.Is(err, ErrIdempotencyKeyInProgress) {
+		t.Errorf("Expected ErrIdempotencyKeyInProgress, got %v", err)
+	}
+
+	// Keys are scoped per user
+	if existing, err := s.reserveIdempotencyKey("bob", "pending", "h2"); err != nil || existing != nil {
+		t.Errorf("Expected another user's key to be independent, got %v, %v", existing, err)
+	}
+
+	// Expired keys are forgotten, even with a different body
+	s.db.Model(&models.IdempotencyKey{}).Where("username = ?", "alice").Update("expires_at", time.Now().Add(-time.Minute))
+	if existing, err := s.reserveIdempotencyKey("alice", "pending", "h3"); err != nil || existing != nil {
+		t.Errorf("Expected an expired key to be reserved again, got %v, %v", existing, err)
+	}
+
+	// A key whose container was deleted is taken over for a new creation
+	deleted := createPauseTestContainer(t, s, "deleted", models.ContainerStatusDeleted)
+	completeIdempotencyKey(t, s, "alice", "gone", CreateContainerInput{Name: "deleted"}, deleted.ID)
+	hash, _ := idempotencyRequestHash(CreateContainerInput{Name: "deleted"})
+	if existing, err := s.reserveIdempotencyKey("alice", "gone", hash); err != nil || existing != nil {
+		t.Errorf("Expected the key of a deleted container to be reserved again, got %v, %v", existing, err)
+	}
+}
+
+func TestCreateContainerIdempotent_InvalidKey(t *testing.T) {
+	s := setupIdempotencyTestService(t)
+	for _, key := range []string{" padded ", strings.Repeat("k", 256)} {
+		if _, _, err := s.CreateContainerIdempotent(context.Background(), "alice", key, CreateContainerInput{}); !errors.Is(err, ErrInvalidIdempotencyKey) {
+			t.Errorf("key %q: expected ErrInvalidIdempotencyKey, got %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cc-platform/internal/docker"
+)
+
+var (
+	// ErrInvalidImage is returned for a custom image that is not a valid image reference
+	ErrInvalidImage = errors.New("invalid image reference")
+	// ErrRegistryAuthFailed is returned when a registry refuses to serve a custom image
+	ErrRegistryAuthFailed = errors.New("registry refused the image pull")
+)
+
+// normalizeContainerImage validates a custom image and checks its registry against the
+// allowlist. An empty image keeps the platform base image.
+func normalizeContainerImage(image string, allowlist []string) (string, error) {
+	image = strings.TrimSpace(image)
+	if image == "" {
+		return "", nil
+	}
+	registry, err := docker.ImageRegistry(image)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q: %v", ErrInvalidImage, image, err)
+	}
+	if err := checkRegistryAllowed(registry, allowlist); err != nil {
+		return "", err
+	}
+	return image, nil
+}
+
+// registryAllowlist returns the registries custom images may come from
+func (s *ContainerService) registryAllowlist() []string {
+	if s.config == nil {
+		return nil
+	}
+	return s.config.RegistryAllowlist
+}
+
+// pullContainerImage pulls a custom image, logging in with the stored credential for its
+// registry when there is one
+func (s *ContainerService) pullContainerImage(ctx context.Context, image string) error {
+	auth := ""
+	if s.configProfileService != nil {
+		var err error
+		if auth, err = s.configProfileService.RegistryAuthForImage(image); err != nil {
+			return err
+		}
+	}
+
+	err := s.dockerClient.PullImage(ctx, image, auth)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, docker.ErrRegistryAuth) {
+		registry, _ := docker.ImageRegistry(image)
+		if auth == "" {
+			return fmt.Errorf("%w: %s needs a login; add a credential for %s in the registry settings (%v)", ErrRegistryAuthFailed, image, registry, err)
+		}
+		return fmt.Errorf("%w: the stored credential for %s was rejected (%v)", ErrRegistryAuthFailed, registry, err)
+	}
+	return dockerError(fmt.Errorf("failed to pull image %s: %w", image, err))
+}
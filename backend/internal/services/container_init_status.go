@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+// errInitCancelled is the cancel cause of an initialization stopped by a stop, delete or shutdown
+var errInitCancelled = errors.New("initialization cancelled")
+
+// initStatusTransitions lists the init statuses each status may move to. Ready, failed and
+// cancelled end a run; only a new run (a clone into the container or a recreate) or a failed
+// recreate moves a container out of them, so a run that loses a race with a stop can't
+// overwrite the outcome.
+var initStatusTransitions = map[string][]string{
+	models.InitStatusPending: {
+		models.InitStatusPending, models.InitStatusCloning, models.InitStatusInitializing,
+		models.InitStatusReady, models.InitStatusFailed, models.InitStatusCancelled,
+	},
+	models.InitStatusCloning: {
+		models.InitStatusCloning, models.InitStatusInitializing,
+		models.InitStatusReady, models.InitStatusFailed, models.InitStatusCancelled,
+	},
+	models.InitStatusInitializing: {
+		models.InitStatusInitializing,
+		models.InitStatusReady, models.InitStatusFailed, models.InitStatusCancelled,
+	},
+	models.InitStatusReady:     {models.InitStatusPending, models.InitStatusCloning, models.InitStatusFailed},
+	models.InitStatusFailed:    {models.InitStatusPending, models.InitStatusCloning, models.InitStatusFailed},
+	models.InitStatusCancelled: {models.InitStatusPending, models.InitStatusCloning, models.InitStatusFailed},
+}
+
+// canTransitionInitStatus reports whether the init status may move from one status to another
+func canTransitionInitStatus(from, to string) bool {
+	return slices.Contains(initStatusTransitions[from], to)
+}
+
+// initStatusSources returns the statuses that may move to status
+func initStatusSources(status string) []string {
+	var sources []string
+	for from, targets := range initStatusTransitions {
+		if slices.Contains(targets, status) {
+			sources = append(sources, from)
+		}
+	}
+	slices.Sort(sources)
+	return sources
+}
+
+// startInitTask registers an initialization run for a container and returns its context.
+// Stopping or deleting the container cancels the context with errInitCancelled; done must be
+// called when the run ends. Only one run per container can be registered at a time.
+func (s *ContainerService) startInitTask(parent context.Context, containerID uint, timeout time.Duration) (context.Context, func(), error) {
+	runCtx, cancelRun := context.WithCancelCause(parent)
+	if _, running := s.initTasks.LoadOrStore(containerID, cancelRun); running {
+		cancelRun(nil)
+		return nil, nil, ErrInitInProgress
+	}
+	ctx, cancel := context.WithTimeout(runCtx, timeout)
+	done := func() {
+		cancel()
+		cancelRun(nil)
+		s.initTasks.Delete(containerID)
+	}
+	return ctx, done, nil
+}
+
+// cancelInitTask cancels a container's running initialization and records it as cancelled.
+// It reports whether a run was cancelled; a run that already finished keeps its status.
+func (s *ContainerService) cancelInitTask(ctx context.Context, containerID uint, reason string) bool {
+	value, ok := s.initTasks.Load(containerID)
+	if !ok {
+		return false
+	}
+	value.(context.CancelCauseFunc)(errInitCancelled)
+	return s.transitionInitStatus(ctx, containerID, models.InitStatusCancelled, "Initialization cancelled: "+reason, nil) != ""
+}
+
+// transitionInitStatus moves the init status to status, writing updates along with it. The
+// write is a conditional update on the current status, so concurrent writers can't undo a
+// final status. Writes from a run whose context was cancelled by cancelInitTask are recorded
+// as cancelled. It returns the status written, or "" when the transition wasn't allowed.
+func (s *ContainerService) transitionInitStatus(ctx context.Context, containerID uint, status, message string, updates map[string]interface{}) string {
+	if status != models.InitStatusCancelled && errors.Is(context.Cause(ctx), errInitCancelled) {
+		status, message, updates = models.InitStatusCancelled, "Initialization cancelled", nil
+	}
+
+	values := map[string]interface{}{
+		"init_status":  status,
+		"init_message": message,
+	}
+	for k, v := range updates {
+		values[k] = v
+	}
+	result := s.db.Model(&models.Container{}).
+		Where("id = ? AND init_status IN ?", containerID, initStatusSources(status)).
+		Updates(values)
+	if result.Error != nil {
+		s.logger(ctx, containerID).Printf("Failed to update init status to %s: %v", status, result.Error)
+		return ""
+	}
+	if result.RowsAffected == 0 {
+		s.logger(ctx, containerID).Printf("Ignoring init status %s - %s: not allowed from the current status", status, message)
+		return ""
+	}
+	s.logger(ctx, containerID).Printf("Container init status: %s - %s", status, message)
+
+	if status == models.InitStatusFailed || status == models.InitStatusCancelled {
+		s.notifyInitCallback(ctx, containerID)
+	}
+	return status
+}
+
+// updateInitStatus updates the initialization status
+func (s *ContainerService) updateInitStatus(ctx context.Context, containerID uint, status, message string) {
+	s.transitionInitStatus(ctx, containerID, status, message, nil)
+}
+
+// markInitReady records a finished initialization. It reports false when the run was
+// cancelled or its container already reached another final status.
+func (s *ContainerService) markInitReady(ctx context.Context, containerID uint) bool {
+	now := time.Now()
+	return s.transitionInitStatus(ctx, containerID, models.InitStatusReady, "Environment ready", map[string]interface{}{
+		"init_progress":  InitProgressReady,
+		"initialized_at": &now,
+	}) == models.InitStatusReady
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+func initStatusOf(t *testing.T, s *ContainerService, id uint) *models.Container {
+	t.Helper()
+	c, err := s.GetContainer(id)
+	if err != nil {
+		t.Fatalf("GetContainer error: %v", err)
+	}
+	return c
+}
+
+func createInitTestContainer(t *testing.T, s *ContainerService, name, initStatus string) *models.Container {
+	t.Helper()
+	c := createPauseTestContainer(t, s, name, models.ContainerStatusRunning)
+	if err := s.db.Model(c).Update("init_status", initStatus).Error; err != nil {
+		t.Fatalf("failed to set init status: %v", err)
+	}
+	return c
+}
+
+func TestCanTransitionInitStatus(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{models.InitStatusPending, models.InitStatusCloning, true},
+		{models.InitStatusCloning, models.InitStatusReady, true},
+		{models.InitStatusInitializing, models.InitStatusCancelled, true},
+		{models.InitStatusInitializing, models.InitStatusCloning, false},
+		{models.InitStatusFailed, models.InitStatusReady, false},
+		{models.InitStatusCancelled, models.InitStatusReady, false},
+		{models.InitStatusCancelled, models.InitStatusInitializing, false},
+		{models.InitStatusReady, models.InitStatusCancelled, false},
+		{models.InitStatusFailed, models.InitStatusCancelled, false},
+		// A new run may start from any final status
+		{models.InitStatusReady, models.InitStatusCloning, true},
+		{models.InitStatusCancelled, models.InitStatusCloning, true},
+		{models.InitStatusFailed, models.InitStatusPending, true},
+	}
+	for _, tt := range tests {
+		if got := canTransitionInitStatus(tt.from, tt.to); got != tt.want {
+			t.Errorf("canTransitionInitStatus(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestInitStatus_StopDuringInitEndsCancelled(t *testing.T) {
+	s := setupPauseTestService(t)
+	c := createInitTestContainer(t, s, "stop-during-init", models.InitStatusPending)
+
+	ctx, done, err := s.startInitTask(context.Background(), c.ID, time.Minute)
+	if err != nil {
+		t.Fatalf("startInitTask error: %v", err)
+	}
+	defer done()
+	s.updateInitStatus(ctx, c.ID, models.InitStatusInitializing, "Initializing project environment...")
+
+	// StopContainer cancels the run while Claude init is still going
+	if !s.cancelInitTask(context.Background(), c.ID, "container stopped") {
+		t.Fatal("cancelInitTask = false, want the running init to be cancelled")
+	}
+	if got := initStatusOf(t, s, c.ID); got.InitStatus != models.InitStatusCancelled || got.InitMessage != "Initialization cancelled: container stopped" {
+		t.Fatalf("after stop: status %q message %q", got.InitStatus, got.InitMessage)
+	}
+
+	// The run then fails on its cancelled exec, or finishes anyway; neither replaces the outcome
+	s.updateInitStatus(ctx, c.ID, models.InitStatusFailed, "Initialization failed: context canceled")
+	if s.markInitReady(ctx, c.ID) {
+		t.Error("markInitReady succeeded after the run was cancelled")
+	}
+	got := initStatusOf(t, s, c.ID)
+	if got.InitStatus != models.InitStatusCancelled || got.InitializedAt != nil || got.InitProgress == InitProgressReady {
+		t.Errorf("final state: status %q initialized_at %v progress %d", got.InitStatus, got.InitializedAt, got.InitProgress)
+	}
+}
+
+func TestInitStatus_CancelledContextIsRecordedAsCancelled(t *testing.T) {
+	// A stop that lands between the run's last check and its ready write
+	s := setupPauseTestService(t)
+	c := createInitTestContainer(t, s, "late-ready", models.InitStatusInitializing)
+
+	ctx, done, err := s.startInitTask(context.Background(), c.ID, time.Minute)
+	if err != nil {
+		t.Fatalf("startInitTask error: %v", err)
+	}
+	defer done()
+	value, _ := s.initTasks.Load(c.ID)
+	value.(context.CancelCauseFunc)(errInitCancelled)
+
+	if s.markInitReady(ctx, c.ID) {
+		t.Error("markInitReady succeeded with a cancelled run context")
+	}
+	if got := initStatusOf(t, s, c.ID); got.InitStatus != models.InitStatusCancelled {
+		t.Errorf("status = %q, want cancelled", got.InitStatus)
+	}
+}
+
+func TestInitStatus_StopAfterReadyKeepsReady(t *testing.T) {
+	s := setupPauseTestService(t)
+	c := createInitTestContainer(t, s, "ready-then-stop", models.InitStatusInitializing)
+
+	ctx, done, err := s.startInitTask(context.Background(), c.ID, time.Minute)
+	if err != nil {
+		t.Fatalf("startInitTask error: %v", err)
+	}
+	if !s.markInitReady(ctx, c.ID) {
+		t.Fatal("markInitReady = false, want ready")
+	}
+	// The stop arrives before the run has unregistered itself
+	s.cancelInitTask(context.Background(), c.ID, "container stopped")
+	done()
+
+	got := initStatusOf(t, s, c.ID)
+	if got.InitStatus != models.InitStatusReady || got.InitializedAt == nil || got.InitProgress != InitProgressReady {
+		t.Errorf("final state: status %q initialized_at %v progress %d", got.InitStatus, got.InitializedAt, got.InitProgress)
+	}
+	if _, running := s.initTasks.Load(c.ID); running {
+		t.Error("init task still registered after done")
+	}
+}
+
+func TestInitStatus_TimeoutStaysFailed(t *testing.T) {
+	s := setupPauseTestService(t)
+	c := createInitTestContainer(t, s, "timeout", models.InitStatusInitializing)
+
+	ctx, done, err := s.startInitTask(context.Background(), c.ID, time.Nanosecond)
+	if err != nil {
+		t.Fatalf("startInitTask error: %v", err)
+	}
+	defer done()
+	<-ctx.Done()
+
+	s.updateInitStatus(ctx, c.ID, models.InitStatusFailed, "Initialization failed: context deadline exceeded")
+	if got := initStatusOf(t, s, c.ID); got.InitStatus != models.InitStatusFailed {
+		t.Errorf("status = %q, want failed", got.InitStatus)
+	}
+}
+
+func TestInitStatus_OneRunPerContainer(t *testing.T) {
+	s := setupPauseTestService(t)
+	c := createInitTestContainer(t, s, "single-run", models.InitStatusPending)
+
+	_, done, err := s.startInitTask(context.Background(), c.ID, time.Minute)
+	if err != nil {
+		t.Fatalf("startInitTask error: %v", err)
+	}
+	if _, _, err := s.startInitTask(context.Background(), c.ID, time.Minute); err != ErrInitInProgress {
+		t.Errorf("second startInitTask error = %v, want ErrInitInProgress", err)
+	}
+	done()
+	if s.cancelInitTask(context.Background(), c.ID, "container stopped") {
+		t.Error("cancelInitTask cancelled a finished run")
+	}
+	if _, done, err := s.startInitTask(context.Background(), c.ID, time.Minute); err != nil {
+		t.Errorf("startInitTask after done error = %v", err)
+	} else {
+		done()
+	}
+}
+
+func TestInitStatus_ConcurrentStopAndReady(t *testing.T) {
+	s := setupPauseTestService(t)
+
+	for i := 0; i < 20; i++ {
+		c := createInitTestContainer(t, s, fmt.Sprintf("race-%d", i), models.InitStatusInitializing)
+		ctx, done, err := s.startInitTask(context.Background(), c.ID, time.Minute)
+		if err != nil {
+			t.Fatalf("startInitTask error: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		var readyApplied, cancelApplied bool
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			readyApplied = s.markInitReady(ctx, c.ID)
+		}()
+		go func() {
+			defer wg.Done()
+			cancelApplied = s.cancelInitTask(context.Background(), c.ID, "container stopped")
+		}()
+		wg.Wait()
+		done()
+
+		// Exactly one side wins and the stored status matches it
+		got := initStatusOf(t, s, c.ID)
+		switch {
+		case readyApplied && !cancelApplied:
+			if got.InitStatus != models.InitStatusReady {
+				t.Errorf("run %d: ready won but status is %q", i, got.InitStatus)
+			}
+		case cancelApplied && !readyApplied:
+			if got.InitStatus != models.InitStatusCancelled || got.InitializedAt != nil {
+				t.Errorf("run %d: stop won but status is %q (initialized_at %v)", i, got.InitStatus, got.InitializedAt)
+			}
+		default:
+			t.Errorf("run %d: ready applied %v, cancel applied %v", i, readyApplied, cancelApplied)
+		}
+	}
+}
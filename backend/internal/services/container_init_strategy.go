@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cc-platform/internal/models"
+)
+
+// Init strategies: what runs after the repository is cloned and configs are injected
+const (
+	InitStrategyClaude = "claude" // Claude Code sets up the environment
+	InitStrategyScript = "script" // Only the pre-init script runs
+	InitStrategyBoth   = "both"   // The pre-init script runs, then Claude Code
+	InitStrategyNone   = "none"   // Nothing runs
+)
+
+// ErrInvalidInitStrategy is returned for an unknown strategy or one that doesn't match the pre-init script
+var ErrInvalidInitStrategy = errors.New("invalid init strategy")
+
+// resolveInitStrategy validates a requested strategy. An empty strategy is derived from the
+// legacy skip_claude_init flag and whether a pre-init script was given, matching the old behavior.
+func resolveInitStrategy(strategy string, skipClaudeInit bool, preInitScript string) (string, error) {
+	hasScript := strings.TrimSpace(preInitScript) != ""
+	switch strings.ToLower(strings.TrimSpace(strategy)) {
+	case "":
+		return legacyInitStrategy(skipClaudeInit, hasScript), nil
+	case InitStrategyClaude, InitStrategyNone:
+		if hasScript {
+			return "", fmt.Errorf("%w: pre_init_script only runs with the %q or %q strategy", ErrInvalidInitStrategy, InitStrategyScript, InitStrategyBoth)
+		}
+	case InitStrategyScript, InitStrategyBoth:
+		if !hasScript {
+			return "", fmt.Errorf("%w: %q requires a pre_init_script", ErrInvalidInitStrategy, strings.ToLower(strings.TrimSpace(strategy)))
+		}
+	default:
+		return "", fmt.Errorf("%w: %q (use claude, script, both or none)", ErrInvalidInitStrategy, strategy)
+	}
+	return strings.ToLower(strings.TrimSpace(strategy)), nil
+}
+
+// legacyInitStrategy maps the skip_claude_init flag and pre-init script presence to a strategy
+func legacyInitStrategy(skipClaudeInit, hasScript bool) string {
+	switch {
+	case skipClaudeInit && hasScript:
+		return InitStrategyScript
+	case skipClaudeInit:
+		return InitStrategyNone
+	case hasScript:
+		return InitStrategyBoth
+	default:
+		return InitStrategyClaude
+	}
+}
+
+// containerInitStrategy returns a container's stored strategy, deriving it for containers
+// created before strategies were persisted
+func containerInitStrategy(c *models.Container) string {
+	if c.InitStrategy != "" {
+		return c.InitStrategy
+	}
+	return legacyInitStrategy(c.SkipClaudeInit, strings.TrimSpace(c.PreInitScript) != "")
+}
+
+// initStrategySteps returns the init stages a strategy runs after config injection, in order
+func initStrategySteps(strategy string) []string {
+	switch strategy {
+	case InitStrategyScript:
+		return []string{models.InitStagePreInit}
+	case InitStrategyBoth:
+		return []string{models.InitStagePreInit, models.InitStageClaudeInit}
+	case InitStrategyClaude:
+		return []string{models.InitStageClaudeInit}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func TestInitStrategySteps(t *testing.T) {
+	testCases := []struct {
+		strategy string
+		steps    []string
+	}{
+		{InitStrategyClaude, []string{models.InitStageClaudeInit}},
+		{InitStrategyScript, []string{models.InitStagePreInit}},
+		// The script prepares the environment before Claude runs
+		{InitStrategyBoth, []string{models.InitStagePreInit, models.InitStageClaudeInit}},
+		{InitStrategyNone, nil},
+	}
+
+	for _, tc := range testCases {
+		if got := initStrategySteps(tc.strategy); !reflect.DeepEqual(got, tc.steps) {
+			t.Errorf("%q: expected steps %v, got %v", tc.strategy, tc.steps, got)
+		}
+	}
+}
+
+func TestResolveInitStrategy(t *testing.T) {
+	testCases := []struct {
+		strategy string
+		skip     bool
+		script   string
+		expected string
+	}{
+		{"claude", false, "", InitStrategyClaude},
+		{" Script ", false, "make deps", InitStrategyScript},
+		{"both", false, "make deps", InitStrategyBoth},
+		{"none", true, "", InitStrategyNone},
+		// Requests without a strategy keep the skip_claude_init behavior
+		{"", false, "", InitStrategyClaude},
+		{"", false, "make deps", InitStrategyBoth},
+		{"", true, "", InitStrategyNone},
+		{"", true, "make deps", InitStrategyScript},
+	}
+
+	for _, tc := range testCases {
+		got, err := resolveInitStrategy(tc.strategy, tc.skip, tc.script)
+		if err != nil {
+			t.Errorf("%q (skip %v): unexpected error %v", tc.strategy, tc.skip, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("%q (skip %v): expected %q, got %q", tc.strategy, tc.skip, tc.expected, got)
+		}
+	}
+}
+
+func TestResolveInitStrategy_Invalid(t *testing.T) {
+	testCases := []struct {
+		strategy string
+		script   string
+	}{
+		{"sometimes", ""},
+		{"script", ""},
+		{"both", "   "},
+		{"claude", "make deps"},
+		{"none", "make deps"},
+	}
+
+	for _, tc := range testCases {
+		if _, err := resolveInitStrategy(tc.strategy, false, tc.script); !errors.Is(err, ErrInvalidInitStrategy) {
+			t.Errorf("%q with script %q: expected ErrInvalidInitStrategy, got %v", tc.strategy, tc.script, err)
+		}
+	}
+}
+
+func TestContainerInitStrategy(t *testing.T) {
+	if got := containerInitStrategy(&models.Container{InitStrategy: InitStrategyScript, PreInitScript: "make"}); got != InitStrategyScript {
+		t.Errorf("Expected the stored strategy, got %q", got)
+	}
+	// Containers created before strategies were stored derive one from their settings
+	if got := containerInitStrategy(&models.Container{SkipClaudeInit: true, PreInitScript: "make"}); got != InitStrategyScript {
+		t.Errorf("Expected %q for a legacy container with a script, got %q", InitStrategyScript, got)
+	}
+	if got := containerInitStrategy(&models.Container{}); got != InitStrategyClaude {
+		t.Errorf("Expected %q for a legacy container, got %q", InitStrategyClaude, got)
+	}
+}
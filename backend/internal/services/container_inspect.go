@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+// redactedValue replaces sensitive values in inspect output
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeyPattern matches env var and label names whose values must not be returned
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(token|key|secret|passw(or)?d|credential|auth)`)
+
+// ContainerInspectState is the runtime state part of a sanitized inspect
+type ContainerInspectState struct {
+	Status       string `json:"status"`
+	Running      bool   `json:"running"`
+	Paused       bool   `json:"paused"`
+	Restarting   bool   `json:"restarting"`
+	OOMKilled    bool   `json:"oom_killed"`
+	ExitCode     int    `json:"exit_code"`
+	Error        string `json:"error,omitempty"`
+	StartedAt    string `json:"started_at,omitempty"`
+	FinishedAt   string `json:"finished_at,omitempty"`
+	Health       string `json:"health,omitempty"`
+	RestartCount int    `json:"restart_count"`
+}
+
+// ContainerInspectMount describes a mount of the container
+type ContainerInspectMount struct {
+	Type        string `json:"type"`
+	Name        string `json:"name,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination"`
+	ReadOnly    bool   `json:"read_only"`
+}
+
+// ContainerInspectNetwork describes the container's endpoint on one network
+type ContainerInspectNetwork struct {
+	IPAddress string   `json:"ip_address,omitempty"`
+	Gateway   string   `json:"gateway,omitempty"`
+	Aliases   []string `json:"aliases,omitempty"`
+}
+
+// ContainerInspectResources holds the resource limits applied by Docker
+type ContainerInspectResources struct {
+	MemoryBytes   int64  `json:"memory_bytes"`
+	NanoCPUs      int64  `json:"nano_cpus"`
+	CPUQuota      int64  `json:"cpu_quota"`
+	CPUPeriod     int64  `json:"cpu_period"`
+	PidsLimit     *int64 `json:"pids_limit,omitempty"`
+	RestartPolicy string `json:"restart_policy,omitempty"`
+}
+
+// ContainerInspectInfo is a sanitized subset of Docker inspect for diagnostics.
+// Env values and labels whose names look like secrets are redacted.
+type ContainerInspectInfo struct {
+	DockerID   string                             `json:"docker_id"`
+	Name       string                             `json:"name"`
+	Image      string                             `json:"image"`
+	ImageID    string                             `json:"image_id"`
+	Created    string                             `json:"created"`
+	User       string                             `json:"user,omitempty"`
+	WorkingDir string                             `json:"working_dir,omitempty"`
+	State      ContainerInspectState              `json:"state"`
+	Labels     map[string]string                  `json:"labels"`
+	Env        []string                           `json:"env"`
+	Mounts     []ContainerInspectMount            `json:"mounts"`
+	Networks   map[string]ContainerInspectNetwork `json:"networks"`
+	Resources  ContainerInspectResources          `json:"resources"`
+}
+
+// InspectContainer returns sanitized Docker inspect details of a container
+func (s *ContainerService) InspectContainer(ctx context.Context, id uint) (*ContainerInspectInfo, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.dockerClient.InspectContainer(ctx, container.DockerID)
+	if err != nil {
+		return nil, dockerError(err)
+	}
+	return SanitizeInspect(info), nil
+}
+
+// SanitizeInspect converts raw Docker inspect data into the subset returned by the API
+func SanitizeInspect(info types.ContainerJSON) *ContainerInspectInfo {
+	result := &ContainerInspectInfo{
+		Labels:   map[string]string{},
+		Env:      []string{},
+		Mounts:   []ContainerInspectMount{},
+		Networks: map[string]ContainerInspectNetwork{},
+	}
+
+	if base := info.ContainerJSONBase; base != nil {
+		result.DockerID = base.ID
+		result.Name = strings.TrimPrefix(base.Name, "/")
+		result.ImageID = base.Image
+		result.Created = base.Created
+		result.State.RestartCount = base.RestartCount
+
+		if st := base.State; st != nil {
+			result.State.Status = st.Status
+			result.State.Running = st.Running
+			result.State.Paused = st.Paused
+			result.State.Restarting = st.Restarting
+			result.State.OOMKilled = st.OOMKilled
+			result.State.ExitCode = st.ExitCode
+			result.State.Error = st.Error
+			result.State.StartedAt = st.StartedAt
+			result.State.FinishedAt = st.FinishedAt
+			if st.Health != nil {
+				result.State.Health = st.Health.Status
+			}
+		}
+
+		if hc := base.HostConfig; hc != nil {
+			result.Resources = ContainerInspectResources{
+				MemoryBytes:   hc.Memory,
+				NanoCPUs:      hc.NanoCPUs,
+				CPUQuota:      hc.CPUQuota,
+				CPUPeriod:     hc.CPUPeriod,
+				PidsLimit:     hc.PidsLimit,
+				RestartPolicy: string(hc.RestartPolicy.Name),
+			}
+		}
+	}
+
+	if cfg := info.Config; cfg != nil {
+		result.Image = cfg.Image
+		result.User = cfg.User
+		result.WorkingDir = cfg.WorkingDir
+		for name, value := range cfg.Labels {
+			if sensitiveKeyPattern.MatchString(name) {
+				value = redactedValue
+			}
+			result.Labels[name] = value
+		}
+		for _, entry := range cfg.Env {
+			result.Env = append(result.Env, redactEnvEntry(entry))
+		}
+		sort.Strings(result.Env)
+	}
+
+	for _, m := range info.Mounts {
+		result.Mounts = append(result.Mounts, ContainerInspectMount{
+			Type:        string(m.Type),
+			Name:        m.Name,
+			Source:      m.Source,
+			Destination: m.Destination,
+			ReadOnly:    !m.RW,
+		})
+	}
+
+	if ns := info.NetworkSettings; ns != nil {
+		for name, endpoint := range ns.Networks {
+			if endpoint == nil {
+				continue
+			}
+			result.Networks[name] = ContainerInspectNetwork{
+				IPAddress: endpoint.IPAddress,
+				Gateway:   endpoint.Gateway,
+				Aliases:   endpoint.Aliases,
+			}
+		}
+	}
+
+	return result
+}
+
+// redactEnvEntry hides the value of a KEY=value entry when the key looks sensitive
+func redactEnvEntry(entry string) string {
+	name, _, hasValue := strings.Cut(entry, "=")
+	if hasValue && sensitiveKeyPattern.MatchString(name) {
+		return name + "=" + redactedValue
+	}
+	return entry
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+)
+
+func TestSanitizeInspect_RedactsSecrets(t *testing.T) {
+	pids := int64(512)
+	info := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    "abc123",
+			Name:  "/cc-demo",
+			Image: "sha256:deadbeef",
+			State: &types.ContainerState{Status: "running", Running: true, Health: &types.Health{Status: "healthy"}},
+			HostConfig: &container.HostConfig{
+				Resources:     container.Resources{Memory: 2 << 30, NanoCPUs: 1e9, PidsLimit: &pids},
+				RestartPolicy: container.RestartPolicy{Name: "unless-stopped"},
+			},
+		},
+		Config: &container.Config{
+			Image: "cc-base:latest",
+			User:  "developer",
+			Env: []string{
+				"PATH=/usr/bin",
+				"ANTHROPIC_API_KEY=sk-ant-123",
+				"GITHUB_TOKEN=ghp_abc",
+				"DB_PASSWORD=hunter2",
+				"CLIENT_SECRET=shh",
+				"EMPTY_FLAG",
+			},
+			Labels: map[string]string{
+				"cc-platform.managed":                           "true",
+				"traefik.http.middlewares.demo.basicauth.users": "admin:$apr1$hash",
+			},
+		},
+		Mounts: []types.MountPoint{
+			{Type: mount.TypeVolume, Name: "cc-app-1", Destination: "/app", RW: true},
+			{Type: mount.TypeBind, Source: "/srv/shared", Destination: "/shared", RW: false},
+		},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"traefik-net": {IPAddress: "172.20.0.5", Gateway: "172.20.0.1"},
+			},
+		},
+	}
+
+	result := SanitizeInspect(info)
+
+	if result.Name != "cc-demo" || result.Image != "cc-base:latest" || result.ImageID != "sha256:deadbeef" {
+		t.Errorf("Unexpected identity fields: %+v", result)
+	}
+	if result.State.Status != "running" || result.State.Health != "healthy" {
+		t.Errorf("Unexpected state: %+v", result.State)
+	}
+	if result.Resources.MemoryBytes != 2<<30 || result.Resources.RestartPolicy != "unless-stopped" || *result.Resources.PidsLimit != 512 {
+		t.Errorf("Unexpected resources: %+v", result.Resources)
+	}
+
+	env := map[string]bool{}
+	for _, e := range result.Env {
+		env[e] = true
+	}
+	for _, want := range []string{
+		"PATH=/usr/bin",
+		"ANTHROPIC_API_KEY=" + redactedValue,
+		"GITHUB_TOKEN=" + redactedValue,
+		"DB_PASSWORD=" + redactedValue,
+		"CLIENT_SECRET=" + redactedValue,
+		"EMPTY_FLAG",
+	} {
+		if !env[want] {
+			t.Errorf("Expected env entry %q, got %v", want, result.Env)
+		}
+	}
+
+	if result.Labels["cc-platform.managed"] != "true" {
+		t.Errorf("Expected plain label to be kept, got %v", result.Labels)
+	}
+	if result.Labels["traefik.http.middlewares.demo.basicauth.users"] != redactedValue {
+		t.Errorf("Expected auth label to be redacted, got %v", result.Labels)
+	}
+
+	if len(result.Mounts) != 2 || result.Mounts[0].ReadOnly || !result.Mounts[1].ReadOnly {
+		t.Errorf("Unexpected mounts: %+v", result.Mounts)
+	}
+	if result.Networks["traefik-net"].IPAddress != "172.20.0.5" {
+		t.Errorf("Unexpected networks: %+v", result.Networks)
+	}
+}
+
+func TestSanitizeInspect_HandlesMissingSections(t *testing.T) {
+	result := SanitizeInspect(types.ContainerJSON{})
+	if result.Labels == nil || result.Env == nil || result.Mounts == nil || result.Networks == nil {
+		t.Errorf("Expected empty collections instead of nil, got %+v", result)
+	}
+}
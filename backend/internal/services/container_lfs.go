@@ -0,0 +1,111 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"cc-platform/internal/models"
+)
+
+// gitLFSInstallScript installs git-lfs with the image's package manager when it is missing
+const gitLFSInstallScript = `command -v git-lfs >/dev/null 2>&1 && exit 0
+command -v apt-get >/dev/null 2>&1 || exit 1
+apt-get update -qq >/dev/null 2>&1 && apt-get install -y -qq git-lfs >/dev/null 2>&1`
+
+// usesGitLFS reports whether a .gitattributes file routes any path through the LFS filter
+func usesGitLFS(gitattributes string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(gitattributes))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		for _, attr := range fields[1:] {
+			if attr == "filter=lfs" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// gitHubLFSAuthConfig scopes the token header to github.com, so LFS downloads redirected to
+// other hosts never receive it
+const gitHubLFSAuthConfig = "http.https://github.com/.extraheader"
+
+// isGitHubRemote reports whether an https repository URL is hosted on github.com
+func isGitHubRemote(repoURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(repoURL))
+	return err == nil && strings.EqualFold(u.Scheme, "https") && strings.EqualFold(u.Hostname(), "github.com")
+}
+
+// gitLFSPullScript fetches the LFS files of the repository in repoDir. For github.com remotes
+// the container's GITHUB_TOKEN authenticates the download, since a clone from the repository
+// cache has no credentials in its remote URL; other remotes never get the token.
+func gitLFSPullScript(repoDir, repoURL string) string {
+	if !isGitHubRemote(repoURL) {
+		return fmt.Sprintf(`cd %s || exit 1
+git lfs install --local >/dev/null || exit 1
+git lfs pull`, shellQuote(repoDir))
+	}
+	return fmt.Sprintf(`cd %s || exit 1
+git lfs install --local >/dev/null || exit 1
+if [ -n "$GITHUB_TOKEN" ]; then
+  git -c %s="Authorization: Basic $(printf 'x-access-token:%%s' "$GITHUB_TOKEN" | base64 -w0)" lfs pull
+else
+  git lfs pull
+fi`, shellQuote(repoDir), gitHubLFSAuthConfig)
+}
+
+// pullGitLFS fetches the LFS files of a freshly cloned repository that uses LFS, installing
+// git-lfs first when the image lacks it. Without LFS the checkout keeps pointer files, so
+// every problem is logged as a warning instead of failing the initialization.
+func (s *ContainerService) pullGitLFS(ctx context.Context, container *models.Container, repoDir string) {
+	var attributes bytes.Buffer
+	if code, err := s.dockerClient.ExecStream(ctx, container.DockerID,
+		[]string{"bash", "-c", fmt.Sprintf("cat %s", shellQuote(repoDir+"/.gitattributes"))}, &attributes, io.Discard); err != nil || code != 0 {
+		return
+	}
+	if !usesGitLFS(attributes.String()) {
+		return
+	}
+	if container.CloneLFS != nil && !*container.CloneLFS {
+		s.addLog(ctx, container.ID, models.LogLevelWarn, models.LogStageClone,
+			"Repository uses Git LFS but clone_lfs is off; LFS files are left as pointer files")
+		return
+	}
+
+	s.addLog(ctx, container.ID, models.LogLevelInfo, models.LogStageClone, "Repository uses Git LFS, pulling LFS files...")
+	if code, err := s.dockerClient.ExecStream(ctx, container.DockerID,
+		[]string{"bash", "-c", "git lfs version"}, io.Discard, io.Discard); err != nil || code != 0 {
+		s.addLog(ctx, container.ID, models.LogLevelInfo, models.LogStageClone, "git-lfs is not installed, trying to install it...")
+		if _, err := s.dockerClient.ExecAsRoot(ctx, container.DockerID, []string{"bash", "-c", gitLFSInstallScript}); err != nil {
+			s.logger(ctx, container.ID).Printf("Failed to install git-lfs: %v", err)
+		}
+		if code, err := s.dockerClient.ExecStream(ctx, container.DockerID,
+			[]string{"bash", "-c", "git lfs version"}, io.Discard, io.Discard); err != nil || code != 0 {
+			s.addLog(ctx, container.ID, models.LogLevelWarn, models.LogStageClone,
+				"Repository uses Git LFS but git-lfs is not available in this image; LFS files are left as pointer files and builds that need them will fail")
+			return
+		}
+	}
+
+	var output bytes.Buffer
+	code, err := s.dockerClient.ExecStream(ctx, container.DockerID, []string{"bash", "-c", gitLFSPullScript(repoDir, container.GitRepoURL)}, &output, &output)
+	if err != nil || code != 0 {
+		message := strings.TrimSpace(output.String())
+		if err != nil {
+			message = err.Error()
+		}
+		s.addLog(ctx, container.ID, models.LogLevelWarn, models.LogStageClone,
+			fmt.Sprintf("git lfs pull failed; LFS files are left as pointer files: %s", message))
+		return
+	}
+	s.addLog(ctx, container.ID, models.LogLevelInfo, models.LogStageClone, "Pulled Git LFS files")
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUsesGitLFS(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes string
+		want       bool
+	}{
+		{name: "no file", attributes: "", want: false},
+		{name: "lfs pattern", attributes: "*.psd filter=lfs diff=lfs merge=lfs -text\n", want: true},
+		{name: "among other rules", attributes: "* text=auto\n*.sh eol=lf\n\nassets/** filter=lfs diff=lfs merge=lfs -text", want: true},
+		{name: "text rules only", attributes: "* text=auto\n*.png binary\n", want: false},
+		{name: "commented out", attributes: "# *.bin filter=lfs diff=lfs merge=lfs -text\n", want: false},
+		{name: "other filter", attributes: "*.secret filter=git-crypt diff=git-crypt\n", want: false},
+		{name: "lfs as the pattern", attributes: "filter=lfs -text\n", want: false},
+		{name: "CRLF line endings", attributes: "*.zip filter=lfs diff=lfs merge=lfs -text\r\n", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := usesGitLFS(tt.attributes); got != tt.want {
+				t.Errorf("usesGitLFS() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitLFSPullScript(t *testing.T) {
+	script := gitLFSPullScript("/workspace/my repo", "https://github.com/acme/web")
+	if !strings.HasPrefix(script, "cd '/workspace/my repo' || exit 1\n") {
+		t.Errorf("Expected the script to enter the quoted repo dir, got %q", script)
+	}
+	if !strings.Contains(script, `printf 'x-access-token:%s' "$GITHUB_TOKEN"`) || !strings.Contains(script, "git lfs pull") {
+		t.Errorf("Expected an authenticated lfs pull, got %q", script)
+	}
+	if !strings.Contains(script, "git -c http.https://github.com/.extraheader=") || strings.Contains(script, "http.extraHeader") {
+		t.Errorf("Expected the token header to be scoped to github.com, got %q", script)
+	}
+
+	// Other hosts never get the GitHub token
+	for _, remote := range []string{"https://gitlab.com/acme/web", "https://github.com.evil.example/acme/web"} {
+		if script := gitLFSPullScript("/workspace/web", remote); strings.Contains(script, "GITHUB_TOKEN") || !strings.Contains(script, "git lfs pull") {
+			t.Errorf("Expected a plain lfs pull for %s, got %q", remote, script)
+		}
+	}
+}
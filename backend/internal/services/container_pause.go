@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cc-platform/internal/models"
+)
+
+var (
+	// ErrContainerPaused is returned when a paused container is paused again
+	ErrContainerPaused = errors.New("container is paused")
+	// ErrContainerNotPaused is returned when resuming a container that is not paused
+	ErrContainerNotPaused = errors.New("container is not paused")
+)
+
+// PauseContainer freezes all processes of a running container with the Docker pause API.
+// Unlike StopContainer, processes, terminal sessions and code-server keep their state.
+func (s *ContainerService) PauseContainer(ctx context.Context, id uint) error {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return err
+	}
+	if err := checkPausable(container); err != nil {
+		return err
+	}
+
+	if err := s.dockerClient.PauseContainer(ctx, container.DockerID); err != nil {
+		s.addLog(ctx, id, models.LogLevelError, models.LogStageStartup, fmt.Sprintf("Failed to pause: %v", err))
+		return dockerError(err)
+	}
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup, "Container paused")
+
+	return s.db.Model(container).Update("status", models.ContainerStatusPaused).Error
+}
+
+// ResumeContainer unpauses a paused container
+func (s *ContainerService) ResumeContainer(ctx context.Context, id uint) error {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return err
+	}
+	if container.Status != models.ContainerStatusPaused {
+		return ErrContainerNotPaused
+	}
+
+	if err := s.dockerClient.UnpauseContainer(ctx, container.DockerID); err != nil {
+		s.addLog(ctx, id, models.LogLevelError, models.LogStageStartup, fmt.Sprintf("Failed to resume: %v", err))
+		return dockerError(err)
+	}
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup, "Container resumed")
+
+	return s.db.Model(container).Update("status", models.ContainerStatusRunning).Error
+}
+
+// ApplyDockerPauseEvent records a pause or unpause that happened outside the platform,
+// e.g. `docker pause` on the host, as reported by the Docker event stream.
+func (s *ContainerService) ApplyDockerPauseEvent(dockerID string, paused bool) error {
+	container, err := s.GetContainerByDockerID(dockerID)
+	if err != nil {
+		return err
+	}
+
+	from, to := models.ContainerStatusRunning, models.ContainerStatusPaused
+	if !paused {
+		from, to = models.ContainerStatusPaused, models.ContainerStatusRunning
+	}
+	if container.Status != from {
+		return nil
+	}
+	return s.db.Model(container).Update("status", to).Error
+}
+
+// checkPausable returns an error unless the container can be paused
+func checkPausable(container *models.Container) error {
+	switch container.Status {
+	case models.ContainerStatusRunning:
+		return nil
+	case models.ContainerStatusPaused:
+		return ErrContainerPaused
+	default:
+		return ErrContainerNotRunning
+	}
+}
+
+// containerStatusFromDocker maps a Docker container state to the platform status
+func containerStatusFromDocker(state string) string {
+	switch state {
+	case "running":
+		return models.ContainerStatusRunning
+	case "paused":
+		return models.ContainerStatusPaused
+	case "created":
+		return models.ContainerStatusCreated
+	default:
+		// exited, dead, removing and missing containers
+		return models.ContainerStatusStopped
+	}
+}
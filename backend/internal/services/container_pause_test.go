@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var pauseTestDBCounter int
+
+func setupPauseTestService(t *testing.T) *ContainerService {
+	t.Helper()
+
+	pauseTestDBCounter++
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:pausedb%d?mode=memory&cache=shared", pauseTestDBCounter)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Container{}, &models.ContainerLog{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return &ContainerService{db: db}
+}
+
+func createPauseTestContainer(t *testing.T, s *ContainerService, name, status string) *models.Container {
+	t.Helper()
+	c := &models.Container{Name: name, DockerID: name + "-0123456789abcdef", Status: status}
+	if err := s.db.Create(c).Error; err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	return c
+}
+
+func TestPauseResume_Guards(t *testing.T) {
+	s := setupPauseTestService(t)
+	ctx := context.Background()
+
+	stopped := createPauseTestContainer(t, s, "stopped", models.ContainerStatusStopped)
+	paused := createPauseTestContainer(t, s, "paused", models.ContainerStatusPaused)
+	running := createPauseTestContainer(t, s, "running", models.ContainerStatusRunning)
+
+	if err := s.PauseContainer(ctx, 9999); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+	if err := s.PauseContainer(ctx, stopped.ID); !errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("Expected ErrContainerNotRunning, got %v", err)
+	}
+	if err := s.PauseContainer(ctx, paused.ID); !errors.Is(err, ErrContainerPaused) {
+		t.Errorf("Expected ErrContainerPaused, got %v", err)
+	}
+
+	if err := s.ResumeContainer(ctx, 9999); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+	if err := s.ResumeContainer(ctx, running.ID); !errors.Is(err, ErrContainerNotPaused) {
+		t.Errorf("Expected ErrContainerNotPaused, got %v", err)
+	}
+	if err := s.ResumeContainer(ctx, stopped.ID); !errors.Is(err, ErrContainerNotPaused) {
+		t.Errorf("Expected ErrContainerNotPaused, got %v", err)
+	}
+}
+
+func TestApplyDockerPauseEvent_Transitions(t *testing.T) {
+	s := setupPauseTestService(t)
+
+	running := createPauseTestContainer(t, s, "running", models.ContainerStatusRunning)
+	stopped := createPauseTestContainer(t, s, "stopped", models.ContainerStatusStopped)
+
+	status := func(id uint) string {
+		t.Helper()
+		c, err := s.GetContainer(id)
+		if err != nil {
+			t.Fatalf("GetContainer error: %v", err)
+		}
+		return c.Status
+	}
+
+	if err := s.ApplyDockerPauseEvent(running.DockerID, true); err != nil {
+		t.Fatalf("pause event error: %v", err)
+	}
+	if got := status(running.ID); got != models.ContainerStatusPaused {
+		t.Errorf("running + pause = %q, want paused", got)
+	}
+
+	if err := s.ApplyDockerPauseEvent(running.DockerID, false); err != nil {
+		t.Fatalf("unpause event error: %v", err)
+	}
+	if got := status(running.ID); got != models.ContainerStatusRunning {
+		t.Errorf("paused + unpause = %q, want running", got)
+	}
+
+	// Events that do not match the recorded state leave it alone
+	if err := s.ApplyDockerPauseEvent(stopped.DockerID, false); err != nil {
+		t.Fatalf("unpause event error: %v", err)
+	}
+	if got := status(stopped.ID); got != models.ContainerStatusStopped {
+		t.Errorf("stopped + unpause = %q, want stopped", got)
+	}
+
+	if err := s.ApplyDockerPauseEvent("unknown-0123456789", true); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+}
+
+func TestContainerStatusFromDocker(t *testing.T) {
+	tests := map[string]string{
+		"running":    models.ContainerStatusRunning,
+		"paused":     models.ContainerStatusPaused,
+		"created":    models.ContainerStatusCreated,
+		"exited":     models.ContainerStatusStopped,
+		"dead":       models.ContainerStatusStopped,
+		"restarting": models.ContainerStatusStopped,
+		"deleted":    models.ContainerStatusStopped,
+	}
+	for state, want := range tests {
+		if got := containerStatusFromDocker(state); got != want {
+			t.Errorf("containerStatusFromDocker(%q) = %q, want %q", state, got, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+)
+
+// ErrPreInitScriptTooLarge is returned when a pre-init script exceeds constants.PreInitScriptMaxBytes
+var ErrPreInitScriptTooLarge = errors.New("pre-init script too large")
+
+// validatePreInitScript checks the size of a pre-init script
+func validatePreInitScript(script string) error {
+	if len(script) > constants.PreInitScriptMaxBytes {
+		return fmt.Errorf("%w: %d bytes (max %d)", ErrPreInitScriptTooLarge, len(script), constants.PreInitScriptMaxBytes)
+	}
+	return nil
+}
+
+// runPreInitScript runs the container's pre-init script with bash from the working directory.
+// The script runs as the container's configured user, so it only gets root when RunAsRoot is set.
+// Its combined output is copied into the container logs; a non-zero exit is returned as an error.
+func (s *ContainerService) runPreInitScript(ctx context.Context, container *models.Container) error {
+	workDir := container.WorkDir
+	if workDir == "" {
+		workDir = "/app"
+	}
+	script := fmt.Sprintf("cd %s || exit 1\n%s", shellQuote(workDir), container.PreInitScript)
+
+	var output bytes.Buffer
+	exitCode, err := s.dockerClient.ExecStream(ctx, container.DockerID, []string{"bash", "-c", script}, &output, &output)
+
+	text := truncatePreInitOutput(strings.TrimSpace(output.String()))
+	if text != "" {
+		level := models.LogLevelInfo
+		if err != nil || exitCode != 0 {
+			level = models.LogLevelError
+		}
+		s.addLog(ctx, container.ID, level, models.LogStageInit, "Pre-init script output:\n"+text)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to run pre-init script: %w", err)
+	}
+	if exitCode != 0 {
+		if text == "" {
+			return fmt.Errorf("exited with code %d", exitCode)
+		}
+		return fmt.Errorf("exited with code %d: %s", exitCode, text)
+	}
+	return nil
+}
+
+// truncatePreInitOutput keeps the tail of long output, where failures are usually reported
+func truncatePreInitOutput(output string) string {
+	if len(output) <= constants.PreInitScriptMaxLogBytes {
+		return output
+	}
+	return "...(truncated)\n" + output[len(output)-constants.PreInitScriptMaxLogBytes:]
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/constants"
+)
+
+func TestValidatePreInitScript_SizeLimit(t *testing.T) {
+	if err := validatePreInitScript(""); err != nil {
+		t.Errorf("Empty script should be valid, got %v", err)
+	}
+	if err := validatePreInitScript(strings.Repeat("a", constants.PreInitScriptMaxBytes)); err != nil {
+		t.Errorf("Script at the size limit should be valid, got %v", err)
+	}
+
+	err := validatePreInitScript(strings.Repeat("a", constants.PreInitScriptMaxBytes+1))
+	if !errors.Is(err, ErrPreInitScriptTooLarge) {
+		t.Errorf("Expected ErrPreInitScriptTooLarge, got %v", err)
+	}
+}
+
+func TestTruncatePreInitOutput_KeepsTail(t *testing.T) {
+	short := "installed node 20"
+	if got := truncatePreInitOutput(short); got != short {
+		t.Errorf("Short output should be unchanged, got %q", got)
+	}
+
+	long := strings.Repeat("x", constants.PreInitScriptMaxLogBytes) + "error: npm not found"
+	got := truncatePreInitOutput(long)
+	if !strings.HasPrefix(got, "...(truncated)") || !strings.HasSuffix(got, "error: npm not found") {
+		t.Errorf("Expected truncated output to keep the tail, got %q...", got[:40])
+	}
+	if len(got) > constants.PreInitScriptMaxLogBytes+len("...(truncated)\n") {
+		t.Errorf("Truncated output too long: %d bytes", len(got))
+	}
+}
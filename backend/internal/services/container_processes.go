@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+var (
+	// ErrInvalidPID is returned when a process ID is not a positive integer
+	ErrInvalidPID = errors.New("invalid process ID")
+	// ErrInvalidSignal is returned when a kill signal is not supported
+	ErrInvalidSignal = errors.New("invalid signal")
+	// ErrProcessNotFound is returned when kill reports that the process does not exist
+	ErrProcessNotFound = errors.New("process not found")
+)
+
+// noPSMarker is printed by the process listing script when the image has no ps binary
+const noPSMarker = "__CC_NO_PS__"
+
+// killOKMarker is printed only when kill exits successfully, since exec output carries no exit code
+const killOKMarker = "__CC_KILL_OK__"
+
+// execKillTimeout bounds killing a cancelled exec inside the container
+const execKillTimeout = 10 * time.Second
+
+// allowedKillSignals lists the signal names accepted by KillProcess
+var allowedKillSignals = map[string]bool{
+	"HUP": true, "INT": true, "QUIT": true, "KILL": true, "TERM": true,
+	"USR1": true, "USR2": true, "STOP": true, "CONT": true,
+}
+
+// listProcessesScript prefers `ps aux` and falls back to /proc for minimal images
+var listProcessesScript = fmt.Sprintf(`if command -v ps >/dev/null 2>&1 && ps aux >/dev/null 2>&1; then ps aux; else echo %s; for p in /proc/[0-9]*; do pid=${p#/proc/}; cmd=$(tr '\0' ' ' < "$p/cmdline" 2>/dev/null); [ -n "$cmd" ] || cmd="[$(cat "$p/comm" 2>/dev/null)]"; echo "$pid $cmd"; done; fi`, noPSMarker)
+
+// ContainerProcess is a single row of the process list inside a container
+type ContainerProcess struct {
+	PID     int     `json:"pid"`
+	User    string  `json:"user,omitempty"`
+	CPU     float64 `json:"cpu"`
+	Mem     float64 `json:"mem"`
+	Command string  `json:"cmd"`
+}
+
+// ListProcesses lists the processes running inside a container
+func (s *ContainerService) ListProcesses(ctx context.Context, id uint) ([]ContainerProcess, error) {
+	if err := s.ensureContainerRunning(id); err != nil {
+		return nil, err
+	}
+
+	output, err := s.ExecInContainer(ctx, id, []string{"sh", "-c", listProcessesScript})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	return parseProcessList(demuxExecOutput(output)), nil
+}
+
+// KillProcess sends a signal to a process inside a container.
+// An empty signal defaults to TERM; names may be given with or without the SIG prefix.
+func (s *ContainerService) KillProcess(ctx context.Context, id uint, pid int, signal string) error {
+	if pid <= 0 {
+		return ErrInvalidPID
+	}
+	sig, err := normalizeSignal(signal)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureContainerRunning(id); err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf("kill -s %s %d 2>&1 && echo %s", sig, pid, killOKMarker)
+	output, err := s.ExecInContainer(ctx, id, []string{"sh", "-c", script})
+	if err != nil {
+		return fmt.Errorf("failed to kill process %d: %w", pid, err)
+	}
+
+	output = demuxExecOutput(output)
+	if strings.Contains(output, killOKMarker) {
+		return nil
+	}
+	if strings.Contains(strings.ToLower(output), "no such process") {
+		return fmt.Errorf("%w: %d", ErrProcessNotFound, pid)
+	}
+	return fmt.Errorf("failed to kill process %d: %s", pid, strings.TrimSpace(output))
+}
+
+// claudeProcessPattern matches the command line of the Claude Code CLI only: a claude binary,
+// optionally run by node, such as "claude -p ..." or "node /usr/local/bin/claude ...". It is
+// anchored, so the shell running pkill doesn't match, and the [c] keeps its own text from matching.
+const claudeProcessPattern = `^([^ ]*/)?(node [^ ]*/)?[c]laude( |$)`
+
+// KillClaudeProcesses terminates every Claude Code process in a running container, sending TERM
+// and then KILL to any that are still alive a second later. Images without pkill are ignored.
+func (s *ContainerService) KillClaudeProcesses(ctx context.Context, id uint) error {
+	if err := s.ensureContainerRunning(id); err != nil {
+		return err
+	}
+	output, err := s.ExecInContainer(ctx, id, []string{"sh", "-c", killClaudeProcessesScript()})
+	if err != nil {
+		return err
+	}
+	return checkKillClaudeOutput(output)
+}
+
+// killClaudeProcessesScript sends TERM, then KILL a second later, to every Claude Code process.
+// killOKMarker is printed unless pkill fails; finding no process is not a failure.
+func killClaudeProcessesScript() string {
+	pattern := shellQuote(claudeProcessPattern)
+	return fmt.Sprintf(`command -v pkill >/dev/null 2>&1 || { echo %[2]s; exit 0; }
+pkill -TERM -f %[1]s; rc=$?
+[ $rc -le 1 ] || { echo "pkill exited with $rc"; exit $rc; }
+[ $rc -eq 1 ] || { sleep 1; pkill -KILL -f %[1]s; rc=$?; [ $rc -le 1 ] || { echo "pkill exited with $rc"; exit $rc; }; }
+echo %[2]s`, pattern, killOKMarker)
+}
+
+// checkKillClaudeOutput returns an error unless killClaudeProcessesScript reported success
+func checkKillClaudeOutput(output string) error {
+	output = demuxExecOutput(output)
+	if strings.Contains(output, killOKMarker) {
+		return nil
+	}
+	return fmt.Errorf("failed to stop Claude processes: %s", strings.TrimSpace(output))
+}
+
+// ensureContainerRunning returns ErrContainerNotRunning unless the container is marked running
+func (s *ContainerService) ensureContainerRunning(id uint) error {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return err
+	}
+	if container.Status != models.ContainerStatusRunning {
+		return ErrContainerNotRunning
+	}
+	return nil
+}
+
+// normalizeSignal validates a signal name or number and returns the form passed to `kill -s`
+func normalizeSignal(signal string) (string, error) {
+	sig := strings.ToUpper(strings.TrimSpace(signal))
+	if sig == "" {
+		return "TERM", nil
+	}
+	if n, err := strconv.Atoi(sig); err == nil {
+		if n < 1 || n > 64 {
+			return "", fmt.Errorf("%w: %s", ErrInvalidSignal, signal)
+		}
+		return strconv.Itoa(n), nil
+	}
+	sig = strings.TrimPrefix(sig, "SIG")
+	if !allowedKillSignals[sig] {
+		return "", fmt.Errorf("%w: %s", ErrInvalidSignal, signal)
+	}
+	return sig, nil
+}
+
+// parseProcessList parses `ps aux` output, or the "pid cmd" fallback listing from /proc
+func parseProcessList(output string) []ContainerProcess {
+	processes := []ContainerProcess{}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return processes
+	}
+
+	if strings.TrimSpace(lines[0]) == noPSMarker {
+		for _, line := range lines[1:] {
+			fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+			pid, err := strconv.Atoi(fields[0])
+			if err != nil || len(fields) < 2 {
+				continue
+			}
+			processes = append(processes, ContainerProcess{PID: pid, Command: strings.TrimSpace(fields[1])})
+		}
+		return processes
+	}
+
+	// BusyBox ps ignores "aux" and prints: PID USER TIME COMMAND
+	if header := strings.Fields(lines[0]); len(header) > 0 && header[0] == "PID" {
+		for _, line := range lines[1:] {
+			fields := strings.Fields(line)
+			if len(fields) < 4 {
+				continue
+			}
+			pid, err := strconv.Atoi(fields[0])
+			if err != nil {
+				continue
+			}
+			processes = append(processes, ContainerProcess{PID: pid, User: fields[1], Command: strings.Join(fields[3:], " ")})
+		}
+		return processes
+	}
+
+	// ps aux columns: USER PID %CPU %MEM VSZ RSS TTY STAT START TIME COMMAND
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 11 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		cpu, _ := strconv.ParseFloat(fields[2], 64)
+		mem, _ := strconv.ParseFloat(fields[3], 64)
+		processes = append(processes, ContainerProcess{
+			PID:     pid,
+			User:    fields[0],
+			CPU:     cpu,
+			Mem:     mem,
+			Command: strings.Join(fields[10:], " "),
+		})
+	}
+	return processes
+}
+
+// ExecStream runs a command in a running container, streaming its output to stdout and stderr.
+// It returns the command's exit code once it finishes. If ctx ends first, the command is killed
+// inside the container; detaching from the exec alone would leave it running.
+func (s *ContainerService) ExecStream(ctx context.Context, id uint, cmd []string, stdout, stderr io.Writer) (int, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return -1, err
+	}
+	if container.Status != models.ContainerStatusRunning {
+		return -1, ErrContainerNotRunning
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return -1, err
+	}
+	pidFile := "/tmp/cc-exec-" + hex.EncodeToString(suffix) + ".pid"
+
+	exitCode := -1
+	err = runKillable(ctx, func() error {
+		var execErr error
+		exitCode, execErr = s.dockerClient.ExecStream(ctx, container.DockerID, killableExec(pidFile, cmd), stdout, stderr)
+		return execErr
+	}, func() { s.killExec(container, pidFile) })
+	return exitCode, dockerError(err)
+}
+
+// killableExec wraps cmd in a shell that records its PID in pidFile while cmd runs
+func killableExec(pidFile string, cmd []string) []string {
+	script := fmt.Sprintf(`echo $$ > %[1]s; trap 'rm -f %[1]s' EXIT; "$@"`, shellQuote(pidFile))
+	return append([]string{"sh", "-c", script, "sh"}, cmd...)
+}
+
+// killExecScript stops an exec started by killableExec: TERM to the shell and its children,
+// KILL a second later
+func killExecScript(pidFile string) string {
+	return fmt.Sprintf(`p=$(cat %[1]s 2>/dev/null) || exit 0
+pkill -TERM -P "$p" 2>/dev/null; kill -TERM "$p" 2>/dev/null
+sleep 1
+pkill -KILL -P "$p" 2>/dev/null; kill -KILL "$p" 2>/dev/null
+rm -f %[1]s
+true`, shellQuote(pidFile))
+}
+
+// killExec kills an exec whose context ended. It runs after that context is done, so it uses its own.
+func (s *ContainerService) killExec(container *models.Container, pidFile string) {
+	ctx, cancel := context.WithTimeout(context.Background(), execKillTimeout)
+	defer cancel()
+	if _, err := s.dockerClient.ExecInContainer(ctx, container.DockerID, []string{"sh", "-c", killExecScript(pidFile)}); err != nil {
+		s.logger(ctx, container.ID).Printf("Warning: failed to kill the cancelled exec: %v", err)
+	}
+}
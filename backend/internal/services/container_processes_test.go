@@ -0,0 +1,146 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseProcessList_PsAux(t *testing.T) {
+	output := `USER         PID %CPU %MEM    VSZ   RSS TTY      STAT START   TIME COMMAND
+root           1  0.0  0.1   4364  3340 ?        Ss   10:00   0:00 /bin/bash /entrypoint.sh
+developer     42 12.5  3.2 912340 65000 pts/0    Sl+  10:01   1:02 node /usr/bin/claude --print hello world
+`
+	processes := parseProcessList(output)
+
+	if len(processes) != 2 {
+		t.Fatalf("Expected 2 processes, got %d", len(processes))
+	}
+	p := processes[1]
+	if p.PID != 42 || p.User != "developer" || p.CPU != 12.5 || p.Mem != 3.2 {
+		t.Errorf("Unexpected process row: %+v", p)
+	}
+	if p.Command != "node /usr/bin/claude --print hello world" {
+		t.Errorf("Expected full command line, got %q", p.Command)
+	}
+}
+
+func TestParseProcessList_BusyBox(t *testing.T) {
+	output := `PID   USER     TIME  COMMAND
+    1 root      0:00 sh -c sleep infinity
+   17 root      0:00 ps aux
+`
+	processes := parseProcessList(output)
+
+	if len(processes) != 2 {
+		t.Fatalf("Expected 2 processes, got %d", len(processes))
+	}
+	if processes[0].PID != 1 || processes[0].Command != "sh -c sleep infinity" {
+		t.Errorf("Unexpected process row: %+v", processes[0])
+	}
+}
+
+func TestParseProcessList_ProcFallbackWithoutPs(t *testing.T) {
+	output := noPSMarker + "\n1 /sbin/init \n23 [kworker]\nnot-a-pid junk\n"
+	processes := parseProcessList(output)
+
+	if len(processes) != 2 {
+		t.Fatalf("Expected 2 processes, got %d: %+v", len(processes), processes)
+	}
+	if processes[0].PID != 1 || processes[0].Command != "/sbin/init" {
+		t.Errorf("Unexpected process row: %+v", processes[0])
+	}
+	if processes[1].Command != "[kworker]" {
+		t.Errorf("Expected comm fallback, got %q", processes[1].Command)
+	}
+}
+
+func TestParseProcessList_EmptyOutput(t *testing.T) {
+	if processes := parseProcessList(""); len(processes) != 0 {
+		t.Errorf("Expected no processes, got %+v", processes)
+	}
+}
+
+func TestNormalizeSignal(t *testing.T) {
+	valid := map[string]string{
+		"":        "TERM",
+		"kill":    "KILL",
+		"SIGKILL": "KILL",
+		" hup ":   "HUP",
+		"9":       "9",
+	}
+	for input, expected := range valid {
+		got, err := normalizeSignal(input)
+		if err != nil || got != expected {
+			t.Errorf("normalizeSignal(%q) = %q, %v; want %q", input, got, err, expected)
+		}
+	}
+
+	for _, input := range []string{"0", "65", "BOGUS", "TERM; rm -rf /", "-9"} {
+		if _, err := normalizeSignal(input); !errors.Is(err, ErrInvalidSignal) {
+			t.Errorf("Expected ErrInvalidSignal for %q, got %v", input, err)
+		}
+	}
+}
+
+func TestClaudeProcessPattern(t *testing.T) {
+	pattern := regexp.MustCompile(claudeProcessPattern)
+	matches := []string{
+		"claude -p hello --output-format stream-json",
+		"/usr/local/bin/claude --resume abc",
+		"node /usr/local/bin/claude -p hi",
+		"/usr/bin/node /home/developer/.npm-global/bin/claude",
+		"claude",
+	}
+	for _, cmdline := range matches {
+		if !pattern.MatchString(cmdline) {
+			t.Errorf("Expected %q to match", cmdline)
+		}
+	}
+	others := []string{
+		"sh -c " + killClaudeProcessesScript(),
+		"vim /home/developer/.claude/settings.json",
+		"node /app/claude-helper.js",
+		"grep claude notes.txt",
+		"bash /workspace/claude",
+	}
+	for _, cmdline := range others {
+		if pattern.MatchString(cmdline) {
+			t.Errorf("Expected %q not to match", cmdline)
+		}
+	}
+
+	if err := checkKillClaudeOutput(killOKMarker + "\n"); err != nil {
+		t.Errorf("Expected the marker to report success, got %v", err)
+	}
+	if err := checkKillClaudeOutput("pkill exited with 3\n"); err == nil || !strings.Contains(err.Error(), "exited with 3") {
+		t.Errorf("Expected a pkill failure to be reported, got %v", err)
+	}
+}
+
+func TestKillableExec(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "exec.pid")
+	cmd := killableExec(pidFile, []string{"sh", "-c", `test -s "$0" && echo "$1" && exit 3`, pidFile, "two words"})
+
+	// The wrapped command keeps its arguments and exit code, and the PID file is gone afterwards
+	out, err := exec.Command(cmd[0], cmd[1:]...).Output()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("Expected exit code 3, got %v", err)
+	}
+	if string(out) != "two words\n" {
+		t.Errorf("Unexpected output: %q", out)
+	}
+	if _, err := os.Stat(pidFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the PID file to be removed, got %v", err)
+	}
+
+	kill := killExecScript("/tmp/cc-exec-1.pid")
+	if !strings.HasPrefix(kill, "p=$(cat '/tmp/cc-exec-1.pid' 2>/dev/null) || exit 0") || !strings.Contains(kill, `kill -KILL "$p"`) {
+		t.Errorf("Unexpected kill script: %q", kill)
+	}
+}
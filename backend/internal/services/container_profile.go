@@ -0,0 +1,321 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrContainerProfileNotFound is returned when a container profile does not exist
+	ErrContainerProfileNotFound = errors.New("container profile not found")
+	// ErrDuplicateContainerProfile is returned when another container profile has the same name
+	ErrDuplicateContainerProfile = errors.New("a container profile with this name already exists")
+	// ErrInvalidContainerProfile is returned for an empty name or invalid defaults
+	ErrInvalidContainerProfile = errors.New("invalid container profile")
+)
+
+// maxContainerProfileTags is how many tags a container profile may have
+const maxContainerProfileTags = 20
+
+// ContainerProfileInput is the body for creating or replacing a container profile
+type ContainerProfileInput struct {
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	Tags             []string `json:"tags"`
+	Image            string   `json:"image"`
+	ResourceProfile  string   `json:"resource_profile"`
+	MemoryLimit      int64    `json:"memory_limit"`
+	CPULimit         float64  `json:"cpu_limit"`
+	InitStrategy     string   `json:"init_strategy"`
+	PreInitScript    string   `json:"pre_init_script"`
+	TemplateIDs      []uint   `json:"template_ids"`
+	BundleID         *uint    `json:"bundle_id"`
+	ProxyEnabled     bool     `json:"proxy_enabled"`
+	ProxyServicePort int      `json:"proxy_service_port"`
+	EnableCodeServer bool     `json:"enable_code_server"`
+	RestartPolicy    string   `json:"restart_policy"`
+	TTLMinutes       int      `json:"ttl_minutes"`
+}
+
+// ContainerProfileService manages named container creation defaults
+type ContainerProfileService struct {
+	db *gorm.DB
+}
+
+// NewContainerProfileService creates a new ContainerProfileService
+func NewContainerProfileService(db *gorm.DB) *ContainerProfileService {
+	return &ContainerProfileService{db: db}
+}
+
+// normalizeContainerProfileInput trims names and tags and checks the defaults are usable.
+// Checks that depend on server config, like the image registry, run when a container is created.
+func (s *ContainerProfileService) normalizeContainerProfileInput(input ContainerProfileInput) (ContainerProfileInput, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return input, fmt.Errorf("%w: name is required", ErrInvalidContainerProfile)
+	}
+	input.Tags = normalizeTags(input.Tags)
+	if len(input.Tags) > maxContainerProfileTags {
+		return input, fmt.Errorf("%w: at most %d tags", ErrInvalidContainerProfile, maxContainerProfileTags)
+	}
+	input.Image = strings.TrimSpace(input.Image)
+	input.ResourceProfile = strings.TrimSpace(input.ResourceProfile)
+	if input.MemoryLimit < 0 || input.CPULimit < 0 {
+		return input, fmt.Errorf("%w: resource limits cannot be negative", ErrInvalidContainerProfile)
+	}
+
+	input.InitStrategy = strings.ToLower(strings.TrimSpace(input.InitStrategy))
+	switch input.InitStrategy {
+	case "", InitStrategyClaude, InitStrategyScript, InitStrategyBoth, InitStrategyNone:
+	default:
+		return input, fmt.Errorf("%w: %v: %q", ErrInvalidContainerProfile, ErrInvalidInitStrategy, input.InitStrategy)
+	}
+	if err := validatePreInitScript(input.PreInitScript); err != nil {
+		return input, fmt.Errorf("%w: %v", ErrInvalidContainerProfile, err)
+	}
+	if input.RestartPolicy != "" {
+		policy, _, err := normalizeRestartPolicy(input.RestartPolicy, 0)
+		if err != nil {
+			return input, fmt.Errorf("%w: %v", ErrInvalidContainerProfile, err)
+		}
+		input.RestartPolicy = policy
+	}
+	if err := validateContainerTTL(input.TTLMinutes); err != nil {
+		return input, fmt.Errorf("%w: %v", ErrInvalidContainerProfile, err)
+	}
+	if input.ProxyServicePort < 0 || input.ProxyServicePort > 65535 {
+		return input, fmt.Errorf("%w: proxy_service_port is out of range", ErrInvalidContainerProfile)
+	}
+
+	input.TemplateIDs = dedupeUintSlice(input.TemplateIDs)
+	if len(input.TemplateIDs) > 0 {
+		var count int64
+		if err := s.db.Model(&models.ClaudeConfigTemplate{}).Where("id IN ?", input.TemplateIDs).Count(&count).Error; err != nil {
+			return input, err
+		}
+		if int(count) != len(input.TemplateIDs) {
+			return input, fmt.Errorf("%w: %v", ErrInvalidContainerProfile, ErrTemplateNotFound)
+		}
+	}
+	if input.BundleID != nil {
+		if err := s.db.First(&models.TemplateBundle{}, *input.BundleID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return input, fmt.Errorf("%w: %v", ErrInvalidContainerProfile, ErrBundleNotFound)
+			}
+			return input, err
+		}
+	}
+	return input, nil
+}
+
+// containerProfileFromInput builds the stored profile for a normalized input
+func containerProfileFromInput(input ContainerProfileInput) models.ContainerProfile {
+	return models.ContainerProfile{
+		Name:             input.Name,
+		Description:      input.Description,
+		Tags:             input.Tags,
+		Image:            input.Image,
+		ResourceProfile:  input.ResourceProfile,
+		MemoryLimit:      input.MemoryLimit,
+		CPULimit:         input.CPULimit,
+		InitStrategy:     input.InitStrategy,
+		PreInitScript:    input.PreInitScript,
+		TemplateIDs:      input.TemplateIDs,
+		BundleID:         input.BundleID,
+		ProxyEnabled:     input.ProxyEnabled,
+		ProxyServicePort: input.ProxyServicePort,
+		EnableCodeServer: input.EnableCodeServer,
+		RestartPolicy:    input.RestartPolicy,
+		TTLMinutes:       input.TTLMinutes,
+	}
+}
+
+// List returns the container profiles sorted by name, optionally only those with the tag
+func (s *ContainerProfileService) List(tag string) ([]models.ContainerProfile, error) {
+	var profiles []models.ContainerProfile
+	if err := s.db.Order("name").Find(&profiles).Error; err != nil {
+		return nil, err
+	}
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return profiles, nil
+	}
+	filtered := make([]models.ContainerProfile, 0, len(profiles))
+	for _, p := range profiles {
+		if slices.Contains(p.Tags, tag) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered, nil
+}
+
+// GetByID returns a container profile by ID
+func (s *ContainerProfileService) GetByID(id uint) (*models.ContainerProfile, error) {
+	return getContainerProfile(s.db, id)
+}
+
+// getContainerProfile loads a container profile, mapping a missing row to ErrContainerProfileNotFound
+func getContainerProfile(db *gorm.DB, id uint) (*models.ContainerProfile, error) {
+	var p models.ContainerProfile
+	if err := db.First(&p, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContainerProfileNotFound
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Create adds a container profile
+func (s *ContainerProfileService) Create(input ContainerProfileInput) (*models.ContainerProfile, error) {
+	input, err := s.normalizeContainerProfileInput(input)
+	if err != nil {
+		return nil, err
+	}
+	p := containerProfileFromInput(input)
+	if err := s.db.Create(&p).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, fmt.Errorf("%w: '%s'", ErrDuplicateContainerProfile, input.Name)
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Update replaces every field of a container profile
+func (s *ContainerProfileService) Update(id uint, input ContainerProfileInput) (*models.ContainerProfile, error) {
+	input, err := s.normalizeContainerProfileInput(input)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	p := containerProfileFromInput(input)
+	p.ID = existing.ID
+	p.CreatedAt = existing.CreatedAt
+	if err := s.db.Save(&p).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, fmt.Errorf("%w: '%s'", ErrDuplicateContainerProfile, input.Name)
+		}
+		return nil, err
+	}
+	return s.GetByID(id)
+}
+
+// Delete removes a container profile; containers created from it are not affected
+func (s *ContainerProfileService) Delete(id uint) error {
+	result := s.db.Delete(&models.ContainerProfile{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrContainerProfileNotFound
+	}
+	return nil
+}
+
+// applyContainerProfile fills the fields a create request leaves empty from the profile it
+// names. Explicit request values always win, including an explicit false for code-server or the
+// proxy: resource limits are taken from the profile only when the request sets none, and
+// templates only when the request selects none.
+func (s *ContainerService) applyContainerProfile(input *CreateContainerInput) error {
+	if input.ProfileID == nil {
+		return nil
+	}
+	profile, err := getContainerProfile(s.db, *input.ProfileID)
+	if err != nil {
+		if errors.Is(err, ErrContainerProfileNotFound) {
+			return fmt.Errorf("%w: ID %d", ErrContainerProfileNotFound, *input.ProfileID)
+		}
+		return err
+	}
+
+	if input.Image == "" {
+		input.Image = profile.Image
+	}
+	if input.ResourceProfile == "" && input.MemoryLimit == 0 && input.CPULimit == 0 &&
+		!input.MemoryUnlimited && !input.CPUUnlimited {
+		input.ResourceProfile = profile.ResourceProfile
+		input.MemoryLimit = profile.MemoryLimit
+		input.CPULimit = profile.CPULimit
+	}
+	if input.InitStrategy == "" && !input.SkipClaudeInit {
+		input.InitStrategy = profile.InitStrategy
+	}
+	if input.PreInitScript == "" {
+		input.PreInitScript = profile.PreInitScript
+	}
+	if input.Proxy.Enabled == nil {
+		enabled := profile.ProxyEnabled
+		input.Proxy.Enabled = &enabled
+	}
+	if input.Proxy.enabled() && input.Proxy.ServicePort == 0 {
+		input.Proxy.ServicePort = profile.ProxyServicePort
+	}
+	if input.EnableCodeServer == nil {
+		enabled := profile.EnableCodeServer
+		input.EnableCodeServer = &enabled
+	}
+	if input.RestartPolicy == "" {
+		input.RestartPolicy = profile.RestartPolicy
+	}
+	if input.TTLMinutes == 0 {
+		input.TTLMinutes = profile.TTLMinutes
+	}
+
+	if !hasTemplateSelections(*input) {
+		templateIDs, err := s.profileTemplateIDs(profile)
+		if err != nil {
+			return err
+		}
+		input.templateIDs = templateIDs
+	}
+	return nil
+}
+
+// hasTemplateSelections reports whether a create request selects any config template itself
+func hasTemplateSelections(input CreateContainerInput) bool {
+	return input.SelectedClaudeMD != nil || len(input.SelectedSkills) > 0 || len(input.SelectedMCPs) > 0 ||
+		len(input.SelectedCommands) > 0 || len(input.SelectedCodexConfigs) > 0 || len(input.SelectedCodexAuths) > 0 ||
+		len(input.SelectedGeminiEnvs) > 0 || len(input.templateIDs) > 0
+}
+
+// profileTemplateIDs returns the profile's templates followed by its bundle's, skipping any
+// that were deleted since the profile was saved
+func (s *ContainerService) profileTemplateIDs(profile *models.ContainerProfile) ([]uint, error) {
+	ids := append([]uint(nil), profile.TemplateIDs...)
+	if profile.BundleID != nil {
+		var bundle models.TemplateBundle
+		err := s.db.First(&bundle, *profile.BundleID).Error
+		switch {
+		case err == nil:
+			ids = append(ids, bundle.TemplateIDs...)
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+	ids = dedupeUintSlice(ids)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var existing []uint
+	if err := s.db.Model(&models.ClaudeConfigTemplate{}).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+		return nil, err
+	}
+	kept := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if slices.Contains(existing, id) {
+			kept = append(kept, id)
+		}
+	}
+	return kept, nil
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func setupContainerProfileTest(t *testing.T) (*ContainerService, *ContainerProfileService) {
+	t.Helper()
+	s := setupPauseTestService(t)
+	if err := s.db.AutoMigrate(&models.ContainerProfile{}, &models.ClaudeConfigTemplate{}, &models.TemplateBundle{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return s, NewContainerProfileService(s.db)
+}
+
+func createProfileTestTemplate(t *testing.T, s *ContainerService, name string) uint {
+	t.Helper()
+	template := models.ClaudeConfigTemplate{Name: name, ConfigType: models.ConfigTypeClaudeMD, Content: "# " + name}
+	if err := s.db.Create(&template).Error; err != nil {
+		t.Fatalf("failed to create template: %v", err)
+	}
+	return template.ID
+}
+
+func TestContainerProfileService_CRUD(t *testing.T) {
+	s, profiles := setupContainerProfileTest(t)
+	templateID := createProfileTestTemplate(t, s, "rules")
+
+	created, err := profiles.Create(ContainerProfileInput{
+		Name:         "  backend  ",
+		Tags:         []string{"Go", "go", " team "},
+		InitStrategy: "Claude",
+		TemplateIDs:  []uint{templateID, templateID},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if created.Name != "backend" || created.InitStrategy != InitStrategyClaude ||
+		!reflect.DeepEqual([]string(created.Tags), []string{"go", "team"}) ||
+		!reflect.DeepEqual([]uint(created.TemplateIDs), []uint{templateID}) {
+		t.Errorf("Unexpected profile: %+v", created)
+	}
+
+	if _, err := profiles.Create(ContainerProfileInput{Name: "backend"}); !errors.Is(err, ErrDuplicateContainerProfile) {
+		t.Errorf("Expected ErrDuplicateContainerProfile, got %v", err)
+	}
+	invalid := []ContainerProfileInput{
+		{Name: " "},
+		{Name: "a", InitStrategy: "magic"},
+		{Name: "b", MemoryLimit: -1},
+		{Name: "c", RestartPolicy: "sometimes"},
+		{Name: "d", TTLMinutes: -5},
+		{Name: "e", TemplateIDs: []uint{9999}},
+		{Name: "f", BundleID: func() *uint { id := uint(9999); return &id }()},
+	}
+	for _, input := range invalid {
+		if _, err := profiles.Create(input); !errors.Is(err, ErrInvalidContainerProfile) {
+			t.Errorf("Create(%+v): expected ErrInvalidContainerProfile, got %v", input, err)
+		}
+	}
+
+	updated, err := profiles.Update(created.ID, ContainerProfileInput{Name: "backend", Image: "ghcr.io/team/dev:1"})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Image != "ghcr.io/team/dev:1" || len(updated.TemplateIDs) != 0 || len(updated.Tags) != 0 {
+		t.Errorf("Expected Update to replace every field, got %+v", updated)
+	}
+
+	if list, _ := profiles.List("team"); len(list) != 0 {
+		t.Errorf("Expected the replaced tags to be gone, got %+v", list)
+	}
+	if err := profiles.Delete(created.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := profiles.GetByID(created.ID); !errors.Is(err, ErrContainerProfileNotFound) {
+		t.Errorf("Expected ErrContainerProfileNotFound, got %v", err)
+	}
+}
+
+func TestApplyContainerProfile_FillsEmptyFields(t *testing.T) {
+	s, profiles := setupContainerProfileTest(t)
+	rules := createProfileTestTemplate(t, s, "rules")
+	style := createProfileTestTemplate(t, s, "style")
+	deleted := createProfileTestTemplate(t, s, "deleted")
+	bundle := models.TemplateBundle{Name: "team", TemplateIDs: models.TemplateIDList{style, deleted}}
+	if err := s.db.Create(&bundle).Error; err != nil {
+		t.Fatalf("failed to create bundle: %v", err)
+	}
+
+	profile, err := profiles.Create(ContainerProfileInput{
+		Name:             "standard",
+		Image:            "ghcr.io/team/dev:1",
+		ResourceProfile:  "large",
+		InitStrategy:     InitStrategyNone,
+		TemplateIDs:      []uint{rules},
+		BundleID:         &bundle.ID,
+		ProxyEnabled:     true,
+		ProxyServicePort: 8080,
+		EnableCodeServer: true,
+		RestartPolicy:    "always",
+		TTLMinutes:       120,
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	s.db.Delete(&models.ClaudeConfigTemplate{}, deleted)
+
+	input := CreateContainerInput{Name: "dev", ProfileID: &profile.ID}
+	if err := s.applyContainerProfile(&input); err != nil {
+		t.Fatalf("applyContainerProfile failed: %v", err)
+	}
+	if input.Image != "ghcr.io/team/dev:1" || input.ResourceProfile != "large" || input.InitStrategy != InitStrategyNone ||
+		!input.Proxy.enabled() || input.Proxy.ServicePort != 8080 || !input.codeServerEnabled() ||
+		input.RestartPolicy != "always" || input.TTLMinutes != 120 {
+		t.Errorf("Expected the profile defaults, got %+v", input)
+	}
+	// Profile templates come first, then the bundle's; deleted templates are skipped
+	if !reflect.DeepEqual(input.templateIDs, []uint{rules, style}) {
+		t.Errorf("templateIDs = %v, want %v", input.templateIDs, []uint{rules, style})
+	}
+
+	missing := uint(9999)
+	if err := s.applyContainerProfile(&CreateContainerInput{Name: "dev", ProfileID: &missing}); !errors.Is(err, ErrContainerProfileNotFound) {
+		t.Errorf("Expected ErrContainerProfileNotFound, got %v", err)
+	}
+	none := CreateContainerInput{Name: "plain"}
+	if err := s.applyContainerProfile(&none); err != nil || !reflect.DeepEqual(none, CreateContainerInput{Name: "plain"}) {
+		t.Errorf("Expected input without a profile to be unchanged, got %+v (%v)", none, err)
+	}
+}
+
+func TestApplyContainerProfile_ExplicitValuesWin(t *testing.T) {
+	s, profiles := setupContainerProfileTest(t)
+	rules := createProfileTestTemplate(t, s, "rules")
+	chosen := createProfileTestTemplate(t, s, "chosen")
+
+	profile, err := profiles.Create(ContainerProfileInput{
+		Name:             "standard",
+		Image:            "ghcr.io/team/dev:1",
+		ResourceProfile:  "large",
+		MemoryLimit:      8192,
+		InitStrategy:     InitStrategyClaude,
+		TemplateIDs:      []uint{rules},
+		ProxyEnabled:     true,
+		ProxyServicePort: 8080,
+		EnableCodeServer: true,
+		RestartPolicy:    "always",
+		TTLMinutes:       120,
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	input := CreateContainerInput{
+		Name:             "dev",
+		ProfileID:        &profile.ID,
+		Image:            "ghcr.io/team/other:2",
+		CPULimit:         0.5,
+		InitStrategy:     InitStrategyNone,
+		SelectedClaudeMD: &chosen,
+		Proxy:            ProxyConfig{ServicePort: 3000},
+		RestartPolicy:    "no",
+		TTLMinutes:       30,
+	}
+	if err := s.applyContainerProfile(&input); err != nil {
+		t.Fatalf("applyContainerProfile failed: %v", err)
+	}
+	if input.Image != "ghcr.io/team/other:2" || input.InitStrategy != InitStrategyNone ||
+		input.RestartPolicy != "no" || input.TTLMinutes != 30 {
+		t.Errorf("Expected explicit values to be kept, got %+v", input)
+	}
+	// An explicit limit keeps all of the profile's resource settings out
+	if input.CPULimit != 0.5 || input.MemoryLimit != 0 || input.ResourceProfile != "" {
+		t.Errorf("Expected only the explicit CPU limit, got cpu=%v memory=%d profile=%q", input.CPULimit, input.MemoryLimit, input.ResourceProfile)
+	}
+	if !input.Proxy.enabled() || input.Proxy.ServicePort != 3000 {
+		t.Errorf("Expected the profile to enable the proxy on the explicit port, got %+v", input.Proxy)
+	}
+	// Explicit template selections replace the profile's templates
+	if len(input.templateIDs) != 0 {
+		t.Errorf("Expected no profile templates, got %v", input.templateIDs)
+	}
+
+	// An explicit false turns off what the profile enables
+	off := false
+	disabled := CreateContainerInput{Name: "plain", ProfileID: &profile.ID, EnableCodeServer: &off, Proxy: ProxyConfig{Enabled: &off}}
+	if err := s.applyContainerProfile(&disabled); err != nil {
+		t.Fatalf("applyContainerProfile failed: %v", err)
+	}
+	if disabled.Proxy.enabled() || disabled.Proxy.ServicePort != 0 || disabled.codeServerEnabled() {
+		t.Errorf("Expected code-server and the proxy to stay off, got %+v", disabled)
+	}
+
+	// The deprecated skip flag counts as an explicit init choice
+	skip := CreateContainerInput{Name: "skip", ProfileID: &profile.ID, SkipClaudeInit: true}
+	if err := s.applyContainerProfile(&skip); err != nil {
+		t.Fatalf("applyContainerProfile failed: %v", err)
+	}
+	if skip.InitStrategy != "" {
+		t.Errorf("Expected skip_claude_init to keep the profile strategy out, got %q", skip.InitStrategy)
+	}
+}
+
+func TestValidateCreateInput_UnknownProfile(t *testing.T) {
+	s, _ := setupContainerProfileTest(t)
+	missing := uint(42)
+
+	problems := s.ValidateCreateInput(CreateContainerInput{Name: "dev", SkipGitRepo: true, ProfileID: &missing})
+	found := false
+	for _, p := range problems {
+		if p.Field == "profile_id" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a profile_id problem, got %+v", problems)
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Per-container outcomes of a template propagation
+const (
+	PropagationInjected = "injected"
+	PropagationFailed   = "failed"
+	PropagationSkipped  = "skipped"
+)
+
+// TemplatePropagationResult is the outcome of re-injecting a template into one container
+type TemplatePropagationResult struct {
+	ContainerName string `json:"container_name"`
+	Status        string `json:"status"`
+	Message       string `json:"message,omitempty"`
+}
+
+// PropagateTemplate re-injects the latest content of a template into every container using it.
+// A container uses a template when it was selected at creation or injected later, or when the
+// container auto-injects all skills and the template is a skill. Containers that aren't running
+// are skipped. Results are keyed by container ID.
+func (s *ContainerService) PropagateTemplate(ctx context.Context, templateID uint) (map[uint]*TemplatePropagationResult, error) {
+	var template models.ClaudeConfigTemplate
+	if err := s.db.First(&template, templateID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+
+	var containers []models.Container
+	if err := s.db.Find(&containers).Error; err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	results := make(map[uint]*TemplatePropagationResult)
+	var targets []models.Container
+	for _, c := range containers {
+		if !containerUsesTemplate(&c, &template) {
+			continue
+		}
+		if c.Status != models.ContainerStatusRunning {
+			results[c.ID] = &TemplatePropagationResult{
+				ContainerName: c.Name,
+				Status:        PropagationSkipped,
+				Message:       fmt.Sprintf("container is %s", c.Status),
+			}
+			continue
+		}
+		targets = append(targets, c)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, constants.TemplatePropagationConcurrency)
+	for _, c := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(c models.Container) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := s.reinjectTemplate(ctx, &c, &template)
+			mu.Lock()
+			results[c.ID] = result
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// reinjectTemplate injects a single template into a running container
+func (s *ContainerService) reinjectTemplate(ctx context.Context, c *models.Container, template *models.ClaudeConfigTemplate) *TemplatePropagationResult {
+	result := &TemplatePropagationResult{ContainerName: c.Name}
+
+	// Merge into the stored status so other templates' entries survive the re-injection
+	status, err := s.injectConfigs(ctx, c.ID, []uint{template.ID}, nil, true)
+	switch {
+	case err != nil:
+		result.Status = PropagationFailed
+		result.Message = err.Error()
+	case len(status.Failed) > 0:
+		result.Status = PropagationFailed
+		result.Message = status.Failed[0].Reason
+	default:
+		result.Status = PropagationInjected
+	}
+	return result
+}
+
+// mergeInjectionStatus replaces the entries of the re-injected templates in a stored
+// injection status with their new outcome. Entries of other templates are kept and
+// warnings from both runs are combined.
+func mergeInjectionStatus(previous, next *models.InjectionStatus, templateIDs []uint) *models.InjectionStatus {
+	if previous == nil {
+		return next
+	}
+
+	names := make(map[string]bool)
+	for _, name := range next.Successful {
+		names[name] = true
+	}
+	for _, failed := range next.Failed {
+		names[failed.TemplateName] = true
+	}
+	ids := make(map[uint]bool)
+	for _, id := range templateIDs {
+		ids[id] = true
+	}
+
+	merged := &models.InjectionStatus{
+		ContainerID: next.ContainerID,
+		InjectedAt:  next.InjectedAt,
+	}
+	for _, name := range previous.Successful {
+		if !names[name] {
+			merged.Successful = append(merged.Successful, name)
+		}
+	}
+	merged.Successful = append(merged.Successful, next.Successful...)
+	for _, id := range previous.SuccessfulIDs {
+		if !ids[id] {
+			merged.SuccessfulIDs = append(merged.SuccessfulIDs, id)
+		}
+	}
+	merged.SuccessfulIDs = append(merged.SuccessfulIDs, next.SuccessfulIDs...)
+	for _, failed := range previous.Failed {
+		if !names[failed.TemplateName] {
+			merged.Failed = append(merged.Failed, failed)
+		}
+	}
+	merged.Failed = append(merged.Failed, next.Failed...)
+
+	seen := make(map[string]bool)
+	for _, warning := range append(append([]string{}, previous.Warnings...), next.Warnings...) {
+		if !seen[warning] {
+			seen[warning] = true
+			merged.Warnings = append(merged.Warnings, warning)
+		}
+	}
+	return merged
+}
+
+// containerUsesTemplate reports whether a template was injected into a container
+func containerUsesTemplate(c *models.Container, template *models.ClaudeConfigTemplate) bool {
+	if c.AutoInjectAllSkills && template.ConfigType == models.ConfigTypeSkill {
+		return true
+	}
+	for _, id := range c.SelectedTemplateIDs {
+		if id == template.ID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func TestContainerUsesTemplate(t *testing.T) {
+	skill := &models.ClaudeConfigTemplate{ConfigType: models.ConfigTypeSkill}
+	skill.ID = 3
+	mcp := &models.ClaudeConfigTemplate{ConfigType: models.ConfigTypeMCP}
+	mcp.ID = 5
+
+	selected := &models.Container{SelectedTemplateIDs: models.TemplateIDList{5}}
+	autoSkills := &models.Container{AutoInjectAllSkills: true}
+
+	if !containerUsesTemplate(selected, mcp) || containerUsesTemplate(selected, skill) {
+		t.Error("Expected selected templates to be matched by ID")
+	}
+	if !containerUsesTemplate(autoSkills, skill) || containerUsesTemplate(autoSkills, mcp) {
+		t.Error("Expected auto-injected skills to match every skill template only")
+	}
+}
+
+func TestMergeInjectionStatus_ReplacesOnlyReinjectedTemplate(t *testing.T) {
+	previous := &models.InjectionStatus{
+		Successful:    []string{"rules", "deploy"},
+		SuccessfulIDs: []uint{3, 7},
+		Failed:        []models.FailedTemplate{{TemplateName: "github", ConfigType: string(models.ConfigTypeMCP), Reason: "timeout"}},
+		Warnings:      []string{"skipped bundle member"},
+	}
+	next := &models.InjectionStatus{
+		Failed:   []models.FailedTemplate{{TemplateName: "rules", ConfigType: string(models.ConfigTypeClaudeMD), Reason: "write failed"}},
+		Warnings: []string{"skipped bundle member", "CLAUDE.md is large"},
+	}
+
+	merged := mergeInjectionStatus(previous, next, []uint{3})
+	if !reflect.DeepEqual(merged.Successful, []string{"deploy"}) || !reflect.DeepEqual(merged.SuccessfulIDs, []uint{7}) {
+		t.Errorf("Expected only the other template to stay successful, got %v / %v", merged.Successful, merged.SuccessfulIDs)
+	}
+	if len(merged.Failed) != 2 || merged.Failed[0].TemplateName != "github" || merged.Failed[1].TemplateName != "rules" {
+		t.Errorf("Expected both failures to be kept, got %+v", merged.Failed)
+	}
+	if !reflect.DeepEqual(merged.Warnings, []string{"skipped bundle member", "CLAUDE.md is large"}) {
+		t.Errorf("Expected warnings to be combined once, got %v", merged.Warnings)
+	}
+	if mergeInjectionStatus(nil, next, []uint{3}) != next {
+		t.Error("Expected the new status to be stored when there is no previous one")
+	}
+}
+
+func TestPropagateTemplate_SkipsContainersNotRunning(t *testing.T) {
+	s := setupPauseTestService(t)
+	if err := s.db.AutoMigrate(&models.ClaudeConfigTemplate{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := context.Background()
+
+	template := &models.ClaudeConfigTemplate{Name: "rules", ConfigType: models.ConfigTypeClaudeMD, Content: "# Rules"}
+	if err := s.db.Create(template).Error; err != nil {
+		t.Fatalf("failed to create template: %v", err)
+	}
+
+	stopped := createPauseTestContainer(t, s, "stopped", models.ContainerStatusStopped)
+	paused := createPauseTestContainer(t, s, "paused", models.ContainerStatusPaused)
+	unrelated := createPauseTestContainer(t, s, "unrelated", models.ContainerStatusStopped)
+	for _, c := range []*models.Container{stopped, paused} {
+		s.db.Model(c).Update("selected_template_ids", models.TemplateIDList{template.ID})
+	}
+
+	if _, err := s.PropagateTemplate(ctx, 9999); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+	}
+
+	results, err := s.PropagateTemplate(ctx, template.ID)
+	if err != nil {
+		t.Fatalf("PropagateTemplate failed: %v", err)
+	}
+	if len(results) != 2 || results[unrelated.ID] != nil {
+		t.Fatalf("Expected results for the two containers using the template, got %v", results)
+	}
+	for _, c := range []*models.Container{stopped, paused} {
+		result := results[c.ID]
+		if result == nil || result.Status != PropagationSkipped || result.ContainerName != c.Name || result.Message != "container is "+c.Status {
+			t.Errorf("Expected %s to be skipped, got %+v", c.Name, result)
+		}
+	}
+}
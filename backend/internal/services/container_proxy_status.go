@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"cc-platform/internal/docker"
+	"cc-platform/internal/logging"
+	"cc-platform/internal/models"
+)
+
+// traefikRouter checks Traefik and attaches containers to its network
+type traefikRouter interface {
+	TraefikStatus(ctx context.Context) (docker.TraefikStatus, error)
+	ConnectTraefikNetwork(ctx context.Context, containerID string) error
+}
+
+// traefikRouting returns the proxy status of a container that should be routed by Traefik, the
+// reason when it is pending, and whether traefik-net exists for the container to join
+func (s *ContainerService) traefikRouting(ctx context.Context) (status, reason string, networkExists bool) {
+	if s.traefik == nil {
+		return models.ProxyStatusActive, "", true
+	}
+	traefik, err := s.traefik.TraefikStatus(ctx)
+	switch {
+	case err != nil:
+		// Let creation try the network; the reconciler rechecks once Docker answers
+		return models.ProxyStatusPending, "could not check Traefik: " + err.Error(), true
+	case !traefik.NetworkExists:
+		return models.ProxyStatusPending, "Traefik network " + docker.TraefikNetwork + " does not exist; start Traefik or set AUTO_START_TRAEFIK=true", false
+	case !traefik.Running:
+		return models.ProxyStatusPending, "Traefik is not running on " + docker.TraefikNetwork, true
+	}
+	return models.ProxyStatusActive, "", true
+}
+
+// StartProxyReconciler rechecks Traefik every interval until the service is closed,
+// activating pending proxies once it is available
+func (s *ContainerService) StartProxyReconciler(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.ReconcileProxies(s.ctx)
+			}
+		}
+	}()
+}
+
+// ReconcileProxies brings the proxy status of routed containers in line with Traefik. Pending
+// containers are attached to traefik-net and activated once Traefik runs; active ones are
+// marked pending while it is down.
+func (s *ContainerService) ReconcileProxies(ctx context.Context) {
+	if s.traefik == nil {
+		return
+	}
+	var containers []models.Container
+	if err := s.db.Where("proxy_status <> '' AND status <> ?", models.ContainerStatusDeleted).Find(&containers).Error; err != nil {
+		logging.FromContext(ctx, "ContainerService").Printf("Proxy reconciler: failed to list containers: %v", err)
+		return
+	}
+	if len(containers) == 0 {
+		return
+	}
+
+	status, reason, _ := s.traefikRouting(ctx)
+	for i := range containers {
+		c := &containers[i]
+		if status == models.ProxyStatusPending {
+			if c.ProxyStatus == models.ProxyStatusPending && c.ProxyStatusReason == reason {
+				continue
+			}
+			wasActive := c.ProxyStatus == models.ProxyStatusActive
+			s.db.Model(c).Updates(map[string]interface{}{"proxy_status": status, "proxy_status_reason": reason})
+			if wasActive {
+				s.addLog(ctx, c.ID, models.LogLevelWarn, models.LogStageStartup, "Proxy pending: "+reason)
+			}
+			continue
+		}
+
+		if c.ProxyStatus == models.ProxyStatusActive {
+			continue
+		}
+		if c.DockerID != "" {
+			if err := s.traefik.ConnectTraefikNetwork(ctx, c.DockerID); err != nil {
+				s.logger(ctx, c.ID).Printf("Proxy reconciler: failed to connect %s to %s: %v", c.Name, docker.TraefikNetwork, err)
+				s.db.Model(c).Update("proxy_status_reason", "could not join "+docker.TraefikNetwork+": "+err.Error())
+				continue
+			}
+		}
+		s.db.Model(c).Updates(map[string]interface{}{"proxy_status": models.ProxyStatusActive, "proxy_status_reason": ""})
+		s.addLog(ctx, c.ID, models.LogLevelInfo, models.LogStageStartup, "Proxy activated: Traefik is available")
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/docker"
+	"cc-platform/internal/models"
+)
+
+// fakeTraefik reports a fixed Traefik status and records the containers it connects
+type fakeTraefik struct {
+	status     docker.TraefikStatus
+	err        error
+	connectErr error
+	connected  []string
+}
+
+func (f *fakeTraefik) TraefikStatus(ctx context.Context) (docker.TraefikStatus, error) {
+	return f.status, f.err
+}
+
+func (f *fakeTraefik) ConnectTraefikNetwork(ctx context.Context, containerID string) error {
+	if f.connectErr != nil {
+		return f.connectErr
+	}
+	f.connected = append(f.connected, containerID)
+	return nil
+}
+
+func TestTraefikRouting_Unavailable(t *testing.T) {
+	s := setupPauseTestService(t)
+	ctx := context.Background()
+	fake := &fakeTraefik{}
+	s.traefik = fake
+
+	status, reason, networkExists := s.traefikRouting(ctx)
+	if status != models.ProxyStatusPending || networkExists || !strings.Contains(reason, "does not exist") {
+		t.Errorf("Missing network: got %q %q %v", status, reason, networkExists)
+	}
+
+	fake.status = docker.TraefikStatus{NetworkExists: true}
+	status, reason, networkExists = s.traefikRouting(ctx)
+	if status != models.ProxyStatusPending || !networkExists || !strings.Contains(reason, "not running") {
+		t.Errorf("Stopped Traefik: got %q %q %v", status, reason, networkExists)
+	}
+
+	fake.err = errors.New("docker unreachable")
+	if status, reason, _ = s.traefikRouting(ctx); status != models.ProxyStatusPending || !strings.Contains(reason, "docker unreachable") {
+		t.Errorf("Docker error: got %q %q", status, reason)
+	}
+
+	fake.err = nil
+	fake.status.Running = true
+	if status, reason, networkExists = s.traefikRouting(ctx); status != models.ProxyStatusActive || reason != "" || !networkExists {
+		t.Errorf("Running Traefik: got %q %q %v", status, reason, networkExists)
+	}
+}
+
+func TestReconcileProxies(t *testing.T) {
+	s := setupPauseTestService(t)
+	ctx := context.Background()
+	fake := &fakeTraefik{}
+	s.traefik = fake
+
+	pending := createPauseTestContainer(t, s, "pending", models.ContainerStatusRunning)
+	s.db.Model(pending).Updates(map[string]interface{}{"proxy_status": models.ProxyStatusPending, "proxy_status_reason": "old reason"})
+	unrouted := createPauseTestContainer(t, s, "unrouted", models.ContainerStatusRunning)
+
+	// Still down: the pending container keeps waiting with the current reason
+	s.ReconcileProxies(ctx)
+	var got models.Container
+	s.db.First(&got, pending.ID)
+	if got.ProxyStatus != models.ProxyStatusPending || !strings.Contains(got.ProxyStatusReason, "does not exist") {
+		t.Errorf("Expected pending with a fresh reason, got %q %q", got.ProxyStatus, got.ProxyStatusReason)
+	}
+	if len(fake.connected) != 0 {
+		t.Errorf("Expected no network connects while Traefik is down, got %v", fake.connected)
+	}
+
+	// Joining the network fails: stays pending
+	fake.status = docker.TraefikStatus{NetworkExists: true, Running: true}
+	fake.connectErr = errors.New("endpoint exists")
+	s.ReconcileProxies(ctx)
+	s.db.First(&got, pending.ID)
+	if got.ProxyStatus != models.ProxyStatusPending || !strings.Contains(got.ProxyStatusReason, "endpoint exists") {
+		t.Errorf("Expected pending after a failed connect, got %q %q", got.ProxyStatus, got.ProxyStatusReason)
+	}
+
+	// Traefik recovers: the container joins traefik-net and its proxy activates
+	fake.connectErr = nil
+	s.ReconcileProxies(ctx)
+	s.db.First(&got, pending.ID)
+	if got.ProxyStatus != models.ProxyStatusActive || got.ProxyStatusReason != "" {
+		t.Errorf("Expected active proxy, got %q %q", got.ProxyStatus, got.ProxyStatusReason)
+	}
+	if len(fake.connected) != 1 || fake.connected[0] != pending.DockerID {
+		t.Errorf("Expected only %s to be connected, got %v", pending.DockerID, fake.connected)
+	}
+	var other models.Container
+	s.db.First(&other, unrouted.ID)
+	if other.ProxyStatus != "" {
+		t.Errorf("Container without a proxy got status %q", other.ProxyStatus)
+	}
+
+	// Traefik goes down again: the proxy is marked pending
+	fake.status.Running = false
+	s.ReconcileProxies(ctx)
+	s.db.First(&got, pending.ID)
+	if got.ProxyStatus != models.ProxyStatusPending || !strings.Contains(got.ProxyStatusReason, "not running") {
+		t.Errorf("Expected pending after Traefik stopped, got %q %q", got.ProxyStatus, got.ProxyStatusReason)
+	}
+
+	var logs []models.ContainerLog
+	s.db.Where("container_id = ?", pending.ID).Order("id").Find(&logs)
+	if len(logs) != 2 || !strings.HasPrefix(logs[0].Message, "Proxy activated") || !strings.HasPrefix(logs[1].Message, "Proxy pending") {
+		t.Errorf("Unexpected proxy logs: %+v", logs)
+	}
+}
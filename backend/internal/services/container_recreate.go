@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"cc-platform/internal/models"
+)
+
+// ErrRecreateNotConfirmed is returned when a recreate request doesn't set the confirm flag
+var ErrRecreateNotConfirmed = errors.New("recreating a container deletes its data and must be confirmed")
+
+// RecreateContainer replaces a container with a fresh one created from its stored settings.
+// The Docker container, its volumes, ports and terminal sessions are removed; the new container
+// keeps the name and database ID (and with it logs and other records keyed by the ID), then
+// starts initialization like a newly created one.
+func (s *ContainerService) RecreateContainer(ctx context.Context, id uint, confirm bool) (*models.Container, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	if !confirm {
+		return nil, ErrRecreateNotConfirmed
+	}
+	if _, running := s.initTasks.Load(id); running {
+		return nil, ErrInitInProgress
+	}
+
+	input, err := recreateInput(container)
+	if err != nil {
+		return nil, err
+	}
+	if input.ClaudeAuthToken, err = s.claudeAuthToken(container); err != nil {
+		return nil, err
+	}
+
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup, "Recreating container with its stored settings")
+	if err := s.removeDockerResources(ctx, container); err != nil {
+		return nil, err
+	}
+	s.deleteRuntimeRecords(id)
+
+	recreated, err := s.createContainer(ctx, input, container)
+	if err != nil {
+		// The old Docker container is gone; leave the record stopped and failed so it can be retried or deleted
+		s.db.Model(container).Update("status", models.ContainerStatusStopped)
+		s.addLog(ctx, id, models.LogLevelError, models.LogStageStartup, fmt.Sprintf("Failed to recreate container: %v", err))
+		s.updateInitStatus(ctx, id, models.InitStatusFailed, fmt.Sprintf("Recreate failed: %v", err))
+		return nil, err
+	}
+	return recreated, nil
+}
+
+// recreateInput rebuilds the creation input from a container's stored settings
+func recreateInput(c *models.Container) (CreateContainerInput, error) {
+	input := CreateContainerInput{
+		Name:        c.Name,
+		GitRepoURL:  c.GitRepoURL,
+		GitRepoName: c.GitRepoName,
+		// Containers created empty and cloned into later are recreated with their repository
+		SkipGitRepo:             c.GitRepoURL == "",
+		SkipClaudeInit:          c.SkipClaudeInit,
+		InitStrategy:            containerInitStrategy(c),
+		EnableYoloMode:          c.EnableYoloMode,
+		ConfirmYolo:             c.EnableYoloMode || c.PreInitScript != "", // Acknowledged when the container was created
+		RunAsRoot:               c.RunAsRoot,
+		RunAsUser:               c.RunAsUser,
+		MemoryLimit:             c.MemoryLimit / (1024 * 1024), // Stored in bytes
+		CPULimit:                c.CPULimit,
+		MemoryUnlimited:         c.MemoryUnlimited,
+		CPUUnlimited:            c.CPUUnlimited,
+		GPUEnabled:              c.GPUEnabled,
+		GPUCount:                c.GPUCount,
+		RestartPolicy:           c.RestartPolicy,
+		RestartMaxRetries:       c.RestartMaxRetries,
+		TTLMinutes:              c.TTLMinutes,
+		Image:                   c.Image,
+		ClaudeVersion:           c.ClaudeVersionPin,
+		CloneLFS:                c.CloneLFS,
+		ClaudeAPIBase:           c.ClaudeAPIBase,
+		ClaudeModel:             c.ClaudeModel,
+		EnableCodeServer:        &c.EnableCodeServer,
+		CodeServerAuth:          c.CodeServerAuth,
+		CodeServerExtensions:    c.CodeServerExtensions,
+		GitHubTokenID:           c.GitHubTokenID,
+		EnvVarsProfileID:        c.EnvVarsProfileID,
+		StartupCommandProfileID: c.StartupCommandProfileID,
+		AutoInjectAllSkills:     c.AutoInjectAllSkills,
+		PreInitScript:           c.PreInitScript,
+		InitCallbackURL:         c.InitCallbackURL,
+		Proxy: ProxyConfig{
+			Enabled:     &c.ProxyEnabled,
+			Domain:      c.ProxyDomain,
+			Port:        c.ProxyPort,
+			ServicePort: c.ServicePort,
+		},
+		templateIDs: c.SelectedTemplateIDs,
+		notes:       c.Notes,
+		metadata:    c.Metadata,
+	}
+	if c.MemoryUnlimited {
+		input.MemoryLimit = 0
+	}
+	if c.CPUUnlimited {
+		input.CPULimit = 0
+	}
+
+	if c.ExposedPorts != "" {
+		if err := json.Unmarshal([]byte(c.ExposedPorts), &input.PortMappings); err != nil {
+			return input, fmt.Errorf("failed to read stored port mappings: %w", err)
+		}
+	}
+	for _, m := range c.HostMounts {
+		input.HostMounts = append(input.HostMounts, HostMountInput{
+			HostPath:      m.HostPath,
+			ContainerPath: m.ContainerPath,
+			ReadWrite:     !m.ReadOnly,
+		})
+	}
+	return input, nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func TestRecreateInput_FromStoredSettings(t *testing.T) {
+	tokenID := uint(3)
+	c := &models.Container{
+		Name:                "web",
+		GitRepoURL:          "https://github.com/acme/web",
+		GitRepoName:         "web",
+		SkipGitRepo:         true, // created empty, repository cloned in later
+		EnableYoloMode:      true,
+		MemoryLimit:         4096 * 1024 * 1024,
+		CPULimit:            2,
+		GPUEnabled:          true,
+		GPUCount:            -1,
+		RestartPolicy:       RestartPolicyOnFailure,
+		RestartMaxRetries:   3,
+		ExposedPorts:        `[{"container_port":3000,"host_port":13000}]`,
+		ProxyEnabled:        true,
+		ProxyDomain:         "web.example.com",
+		ServicePort:         3000,
+		EnableCodeServer:    true,
+		GitHubTokenID:       &tokenID,
+		AutoInjectAllSkills: true,
+		PreInitScript:       "make deps",
+		InitStrategy:        InitStrategyScript,
+		ClaudeVersionPin:    "1.0.35",
+		HostMounts:          models.HostMountList{{HostPath: "/srv/data", ContainerPath: "/data", ReadOnly: true}},
+		SelectedTemplateIDs: models.TemplateIDList{4, 9},
+		Notes:               "staging mirror",
+		Metadata:            models.ContainerMetadata{"team": "web"},
+	}
+
+	input, err := recreateInput(c)
+	if err != nil {
+		t.Fatalf("recreateInput failed: %v", err)
+	}
+
+	if input.Name != "web" || input.GitRepoURL != c.GitRepoURL || input.SkipGitRepo {
+		t.Errorf("Expected the repository to be recreated, got %+v", input)
+	}
+	if input.MemoryLimit != 4096 || input.CPULimit != 2 || !input.GPUEnabled || input.GPUCount != -1 {
+		t.Errorf("Expected limits 4096MB / 2 cores / all GPUs, got %d / %v / %v %d", input.MemoryLimit, input.CPULimit, input.GPUEnabled, input.GPUCount)
+	}
+	if !reflect.DeepEqual(input.PortMappings, []PortMapping{{ContainerPort: 3000, HostPort: 13000}}) {
+		t.Errorf("Unexpected port mappings: %+v", input.PortMappings)
+	}
+	if input.RestartPolicy != RestartPolicyOnFailure || input.RestartMaxRetries != 3 {
+		t.Errorf("Expected the restart policy to carry over, got %q / %d", input.RestartPolicy, input.RestartMaxRetries)
+	}
+	if !input.Proxy.enabled() || input.Proxy.Domain != "web.example.com" || input.Proxy.Port != 0 || input.Proxy.ServicePort != 3000 {
+		t.Errorf("Unexpected proxy config: %+v", input.Proxy)
+	}
+	if input.GitHubTokenID == nil || *input.GitHubTokenID != 3 || !input.EnableYoloMode || !input.ConfirmYolo || !input.codeServerEnabled() {
+		t.Errorf("Expected profile and feature settings to carry over, got %+v", input)
+	}
+	if !reflect.DeepEqual(input.HostMounts, []HostMountInput{{HostPath: "/srv/data", ContainerPath: "/data"}}) {
+		t.Errorf("Expected a read-only host mount, got %+v", input.HostMounts)
+	}
+	if input.InitStrategy != InitStrategyScript || input.PreInitScript != "make deps" {
+		t.Errorf("Expected the init strategy to carry over, got %q", input.InitStrategy)
+	}
+	if input.ClaudeVersion != "1.0.35" {
+		t.Errorf("Expected the Claude CLI pin to carry over, got %q", input.ClaudeVersion)
+	}
+	if !reflect.DeepEqual(input.templateIDs, []uint{4, 9}) || !input.AutoInjectAllSkills {
+		t.Errorf("Expected template selections to carry over, got %v (auto skills %v)", input.templateIDs, input.AutoInjectAllSkills)
+	}
+	if input.notes != "staging mirror" || !reflect.DeepEqual(input.metadata, models.ContainerMetadata{"team": "web"}) {
+		t.Errorf("Expected notes and metadata to carry over, got %q / %v", input.notes, input.metadata)
+	}
+}
+
+func TestRecreateInput_UnlimitedAndEmpty(t *testing.T) {
+	input, err := recreateInput(&models.Container{
+		Name:            "scratch",
+		SkipGitRepo:     true,
+		MemoryUnlimited: true,
+		CPUUnlimited:    true,
+	})
+	if err != nil {
+		t.Fatalf("recreateInput failed: %v", err)
+	}
+	if !input.SkipGitRepo || input.MemoryLimit != 0 || input.CPULimit != 0 || !input.MemoryUnlimited || !input.CPUUnlimited {
+		t.Errorf("Expected an empty unlimited container, got %+v", input)
+	}
+
+	if _, err := recreateInput(&models.Container{Name: "bad", ExposedPorts: "not json"}); err == nil {
+		t.Error("Expected an error for corrupt stored port mappings")
+	}
+}
+
+func TestRecreateContainer_Guards(t *testing.T) {
+	s := setupPauseTestService(t)
+	ctx := context.Background()
+	c := createPauseTestContainer(t, s, "broken", models.ContainerStatusRunning)
+
+	if _, err := s.RecreateContainer(ctx, 9999, true); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+	if _, err := s.RecreateContainer(ctx, c.ID, false); !errors.Is(err, ErrRecreateNotConfirmed) {
+		t.Errorf("Expected ErrRecreateNotConfirmed, got %v", err)
+	}
+
+	s.initTasks.Store(c.ID, context.CancelCauseFunc(func(error) {}))
+	if _, err := s.RecreateContainer(ctx, c.ID, true); !errors.Is(err, ErrInitInProgress) {
+		t.Errorf("Expected ErrInitInProgress, got %v", err)
+	}
+	s.initTasks.Delete(c.ID)
+
+	// Nothing was removed by the rejected requests
+	if got, err := s.GetContainer(c.ID); err != nil || got.DockerID != c.DockerID {
+		t.Errorf("Expected the container to be untouched, got %+v (err %v)", got, err)
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cc-platform/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+var (
+	// ErrNoResourceChanges is returned when a resource update sets no limit
+	ErrNoResourceChanges = errors.New("no resource limits to update")
+	// ErrResourceUpdateFailed is returned when Docker rejects a resource update,
+	// e.g. a memory limit below the container's current usage
+	ErrResourceUpdateFailed = errors.New("failed to update container resources")
+)
+
+// UpdateResourcesInput represents new limits for an existing container
+type UpdateResourcesInput struct {
+	MemoryLimit  int64   `json:"memory_limit,omitempty"`  // Memory limit in MB (0 = unchanged)
+	CPULimit     float64 `json:"cpu_limit,omitempty"`     // CPU limit in cores (0 = unchanged)
+	CPUUnlimited bool    `json:"cpu_unlimited,omitempty"` // Remove the CPU quota
+}
+
+// UpdateContainerResources changes the CPU/memory limits of a running container
+// with the Docker update API, without recreating it, and persists the new values.
+func (s *ContainerService) UpdateContainerResources(ctx context.Context, id uint, input UpdateResourcesInput) (*models.Container, error) {
+	dbContainer, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	switch dbContainer.Status {
+	case models.ContainerStatusRunning:
+	case models.ContainerStatusPaused:
+		return nil, ErrContainerPaused
+	default:
+		return nil, ErrContainerNotRunning
+	}
+
+	resources, err := buildResourceUpdate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.dockerClient.UpdateContainerResources(ctx, dbContainer.DockerID, resources); err != nil {
+		s.addLog(ctx, id, models.LogLevelError, models.LogStageStartup, fmt.Sprintf("Failed to update resource limits: %v", err))
+		return nil, fmt.Errorf("%w: %v", ErrResourceUpdateFailed, err)
+	}
+
+	if err := s.persistResourceLimits(dbContainer, input); err != nil {
+		return nil, err
+	}
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup, describeResourceUpdate(input))
+
+	return dbContainer, nil
+}
+
+// buildResourceUpdate validates the input and converts it to Docker resources.
+// Zero fields are left unset so Docker keeps the current value.
+func buildResourceUpdate(input UpdateResourcesInput) (container.Resources, error) {
+	var resources container.Resources
+
+	if input.MemoryLimit == 0 && input.CPULimit == 0 && !input.CPUUnlimited {
+		return resources, ErrNoResourceChanges
+	}
+	if input.CPUUnlimited && input.CPULimit != 0 {
+		return resources, fmt.Errorf("%w: cpu_limit cannot be combined with cpu_unlimited", ErrInvalidCPULimit)
+	}
+	if err := validateResourceLimits(input.CPULimit, input.MemoryLimit, CPUPeriodDefault); err != nil {
+		return resources, fmt.Errorf("resource validation failed: %w", err)
+	}
+
+	if input.MemoryLimit > 0 {
+		memoryBytes := input.MemoryLimit * 1024 * 1024 // Convert MB to bytes
+		resources.Memory = memoryBytes
+		resources.MemorySwap = memoryBytes // No swap, same as at creation
+	}
+	if input.CPUUnlimited {
+		// -1 removes the quota; 0 would mean "unchanged" to the update API
+		resources.CPUQuota = -1
+	} else if input.CPULimit > 0 {
+		resources.CPUQuota = int64(input.CPULimit * CPUPeriodDefault)
+		resources.CPUPeriod = CPUPeriodDefault
+	}
+	return resources, nil
+}
+
+// persistResourceLimits stores the limits applied by a successful update
+func (s *ContainerService) persistResourceLimits(dbContainer *models.Container, input UpdateResourcesInput) error {
+	updates := map[string]interface{}{}
+	if input.MemoryLimit > 0 {
+		updates["memory_limit"] = input.MemoryLimit * 1024 * 1024 // Store in bytes
+		updates["memory_unlimited"] = false
+	}
+	if input.CPUUnlimited {
+		updates["cpu_limit"] = 0
+		updates["cpu_unlimited"] = true
+	} else if input.CPULimit > 0 {
+		updates["cpu_limit"] = input.CPULimit
+		updates["cpu_unlimited"] = false
+	}
+	return s.db.Model(dbContainer).Updates(updates).Error
+}
+
+// describeResourceUpdate formats the container log entry for a resource update
+func describeResourceUpdate(input UpdateResourcesInput) string {
+	msg := "Resource limits updated:"
+	if input.MemoryLimit > 0 {
+		msg += fmt.Sprintf(" memory=%dMB", input.MemoryLimit)
+	}
+	if input.CPUUnlimited {
+		msg += " cpu=unlimited"
+	} else if input.CPULimit > 0 {
+		msg += fmt.Sprintf(" cpu=%.2f cores", input.CPULimit)
+	}
+	return msg
+}
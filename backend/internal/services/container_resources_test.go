@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func TestBuildResourceUpdate_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   UpdateResourcesInput
+		wantErr error
+	}{
+		{"nothing to change", UpdateResourcesInput{}, ErrNoResourceChanges},
+		{"negative memory", UpdateResourcesInput{MemoryLimit: -1}, ErrInvalidMemoryLimit},
+		{"memory over maximum", UpdateResourcesInput{MemoryLimit: 128*1024 + 1}, ErrInvalidMemoryLimit},
+		{"cpu over maximum", UpdateResourcesInput{CPULimit: 65}, ErrInvalidCPULimit},
+		{"cpu below minimum quantum", UpdateResourcesInput{CPULimit: 0.005}, ErrInvalidCPULimit},
+		{"cpu limit with unlimited", UpdateResourcesInput{CPULimit: 2, CPUUnlimited: true}, ErrInvalidCPULimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildResourceUpdate(tt.input); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBuildResourceUpdate_Resources(t *testing.T) {
+	resources, err := buildResourceUpdate(UpdateResourcesInput{MemoryLimit: 4096, CPULimit: 1.5})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resources.Memory != 4096*1024*1024 || resources.MemorySwap != resources.Memory {
+		t.Errorf("Expected 4GB memory without swap, got %d / %d", resources.Memory, resources.MemorySwap)
+	}
+	if resources.CPUQuota != 150000 || resources.CPUPeriod != CPUPeriodDefault {
+		t.Errorf("Expected 1.5 cores as quota 150000, got %d / %d", resources.CPUQuota, resources.CPUPeriod)
+	}
+
+	// Unset fields stay zero so Docker keeps their current value
+	resources, err = buildResourceUpdate(UpdateResourcesInput{CPUUnlimited: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resources.Memory != 0 || resources.CPUQuota != -1 {
+		t.Errorf("Expected only the CPU quota removed, got memory %d quota %d", resources.Memory, resources.CPUQuota)
+	}
+}
+
+func TestPersistResourceLimits(t *testing.T) {
+	s := setupPauseTestService(t)
+	c := createPauseTestContainer(t, s, "resized", models.ContainerStatusRunning)
+	s.db.Model(c).Updates(map[string]interface{}{"memory_limit": 2048 * 1024 * 1024, "cpu_limit": 1.0, "memory_unlimited": true})
+
+	if err := s.persistResourceLimits(c, UpdateResourcesInput{MemoryLimit: 8192}); err != nil {
+		t.Fatalf("persistResourceLimits failed: %v", err)
+	}
+	got, _ := s.GetContainer(c.ID)
+	if got.MemoryLimit != 8192*1024*1024 || got.MemoryUnlimited {
+		t.Errorf("Expected 8GB memory stored in bytes, got %d (unlimited=%v)", got.MemoryLimit, got.MemoryUnlimited)
+	}
+	if got.CPULimit != 1.0 {
+		t.Errorf("CPU limit should be unchanged, got %v", got.CPULimit)
+	}
+
+	if err := s.persistResourceLimits(c, UpdateResourcesInput{CPUUnlimited: true}); err != nil {
+		t.Fatalf("persistResourceLimits failed: %v", err)
+	}
+	got, _ = s.GetContainer(c.ID)
+	if got.CPULimit != 0 || !got.CPUUnlimited || got.MemoryLimit != 8192*1024*1024 {
+		t.Errorf("Expected unlimited CPU and unchanged memory, got %+v", got)
+	}
+}
+
+func TestUpdateContainerResources_Guards(t *testing.T) {
+	s := setupPauseTestService(t)
+	ctx := context.Background()
+	input := UpdateResourcesInput{MemoryLimit: 4096}
+
+	stopped := createPauseTestContainer(t, s, "stopped", models.ContainerStatusStopped)
+	paused := createPauseTestContainer(t, s, "paused", models.ContainerStatusPaused)
+	running := createPauseTestContainer(t, s, "running", models.ContainerStatusRunning)
+
+	if _, err := s.UpdateContainerResources(ctx, 9999, input); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+	if _, err := s.UpdateContainerResources(ctx, stopped.ID, input); !errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("Expected ErrContainerNotRunning, got %v", err)
+	}
+	if _, err := s.UpdateContainerResources(ctx, paused.ID, input); !errors.Is(err, ErrContainerPaused) {
+		t.Errorf("Expected ErrContainerPaused, got %v", err)
+	}
+	// Validation runs before Docker is called
+	if _, err := s.UpdateContainerResources(ctx, running.ID, UpdateResourcesInput{}); !errors.Is(err, ErrNoResourceChanges) {
+		t.Errorf("Expected ErrNoResourceChanges, got %v", err)
+	}
+}
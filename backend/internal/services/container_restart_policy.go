@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// Restart policies accepted when creating a container
+const (
+	RestartPolicyNo        = "no"
+	RestartPolicyOnFailure = "on-failure"
+	RestartPolicyAlways    = "always"
+)
+
+// ErrInvalidRestartPolicy is returned for an unknown restart policy or bad retry count
+var ErrInvalidRestartPolicy = errors.New("invalid restart policy")
+
+// normalizeRestartPolicy validates a restart policy, defaulting to "no".
+// Max retries only applies to on-failure, where 0 means retry forever.
+func normalizeRestartPolicy(policy string, maxRetries int) (string, int, error) {
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	if maxRetries < 0 {
+		return "", 0, fmt.Errorf("%w: max retries cannot be negative", ErrInvalidRestartPolicy)
+	}
+	switch policy {
+	case "", RestartPolicyNo:
+		return RestartPolicyNo, 0, nil
+	case RestartPolicyOnFailure:
+		return RestartPolicyOnFailure, maxRetries, nil
+	case RestartPolicyAlways:
+		return RestartPolicyAlways, 0, nil
+	default:
+		return "", 0, fmt.Errorf("%w: '%s' (expected %s, %s or %s)", ErrInvalidRestartPolicy,
+			policy, RestartPolicyNo, RestartPolicyOnFailure, RestartPolicyAlways)
+	}
+}
+
+// dockerRestartPolicy maps a normalized policy to the Docker host config.
+// "always" becomes Docker's unless-stopped: a container stopped through the platform
+// would otherwise be started again by Docker whenever the daemon restarts.
+func dockerRestartPolicy(policy string, maxRetries int) container.RestartPolicy {
+	switch policy {
+	case RestartPolicyOnFailure:
+		return container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: maxRetries}
+	case RestartPolicyAlways:
+		return container.RestartPolicy{Name: container.RestartPolicyUnlessStopped}
+	default:
+		return container.RestartPolicy{Name: container.RestartPolicyDisabled}
+	}
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestRestartPolicyMapping(t *testing.T) {
+	testCases := []struct {
+		policy     string
+		maxRetries int
+		normalized string
+		docker     container.RestartPolicy
+	}{
+		{"", 0, RestartPolicyNo, container.RestartPolicy{Name: container.RestartPolicyDisabled}},
+		{"no", 3, RestartPolicyNo, container.RestartPolicy{Name: container.RestartPolicyDisabled}},
+		{"on-failure", 5, RestartPolicyOnFailure, container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 5}},
+		{"On-Failure", 0, RestartPolicyOnFailure, container.RestartPolicy{Name: container.RestartPolicyOnFailure}},
+		// A platform stop must stick, so "always" never restarts a stopped container
+		{"always", 2, RestartPolicyAlways, container.RestartPolicy{Name: container.RestartPolicyUnlessStopped}},
+	}
+
+	for _, tc := range testCases {
+		policy, maxRetries, err := normalizeRestartPolicy(tc.policy, tc.maxRetries)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tc.policy, err)
+			continue
+		}
+		if policy != tc.normalized {
+			t.Errorf("%q: expected %q, got %q", tc.policy, tc.normalized, policy)
+		}
+		if got := dockerRestartPolicy(policy, maxRetries); got != tc.docker {
+			t.Errorf("%q: expected Docker policy %+v, got %+v", tc.policy, tc.docker, got)
+		}
+	}
+}
+
+func TestRestartPolicyValidation(t *testing.T) {
+	if _, _, err := normalizeRestartPolicy("unless-stopped", 0); !errors.Is(err, ErrInvalidRestartPolicy) {
+		t.Errorf("Expected ErrInvalidRestartPolicy for an unknown policy, got %v", err)
+	}
+	if _, _, err := normalizeRestartPolicy("on-failure", -1); !errors.Is(err, ErrInvalidRestartPolicy) {
+		t.Errorf("Expected ErrInvalidRestartPolicy for negative retries, got %v", err)
+	}
+}
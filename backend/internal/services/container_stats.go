@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"cc-platform/internal/models"
+
+	"github.com/docker/docker/api/types"
+)
+
+// ContainerStatsSample is a single parsed resource usage sample for a container
+type ContainerStatsSample struct {
+	Timestamp     time.Time `json:"timestamp"`
+	CPUPercent    float64   `json:"cpu_percent"`
+	MemoryUsage   uint64    `json:"memory_usage"`
+	MemoryLimit   uint64    `json:"memory_limit"`
+	MemoryPercent float64   `json:"memory_percent"`
+	NetworkRx     uint64    `json:"network_rx_bytes"`
+	NetworkTx     uint64    `json:"network_tx_bytes"`
+}
+
+// ParseStatsSample converts a raw Docker stats frame into a ContainerStatsSample.
+// CPU usage is computed from the delta against the pre-CPU snapshot Docker includes in each frame.
+func ParseStatsSample(stats *types.StatsJSON) ContainerStatsSample {
+	sample := ContainerStatsSample{
+		Timestamp:   stats.Read,
+		MemoryLimit: stats.MemoryStats.Limit,
+	}
+	if sample.Timestamp.IsZero() {
+		sample.Timestamp = time.Now()
+	}
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 && onlineCPUs > 0 {
+		sample.CPUPercent = cpuDelta / systemDelta * onlineCPUs * 100
+	}
+
+	// Page cache is reclaimable, so report usage without it like `docker stats` does
+	// (cgroup v2 exposes it as inactive_file, cgroup v1 as cache)
+	usage := stats.MemoryStats.Usage
+	cache, ok := stats.MemoryStats.Stats["inactive_file"]
+	if !ok {
+		cache = stats.MemoryStats.Stats["cache"]
+	}
+	if cache < usage {
+		usage -= cache
+	}
+	sample.MemoryUsage = usage
+	if sample.MemoryLimit > 0 {
+		sample.MemoryPercent = float64(usage) / float64(sample.MemoryLimit) * 100
+	}
+
+	for _, network := range stats.Networks {
+		sample.NetworkRx += network.RxBytes
+		sample.NetworkTx += network.TxBytes
+	}
+
+	return sample
+}
+
+// OpenStatsStream opens the live Docker stats stream for a running container.
+// The caller owns the returned reader and must close it to release the stream.
+func (s *ContainerService) OpenStatsStream(ctx context.Context, containerID uint) (io.ReadCloser, error) {
+	container, err := s.GetContainer(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if container.Status != models.ContainerStatusRunning {
+		return nil, ErrContainerNotRunning
+	}
+
+	stream, err := s.dockerClient.StreamContainerStats(ctx, container.DockerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stats stream: %w", err)
+	}
+	return stream, nil
+}
@@ -144,7 +144,7 @@ type MockConfigInjectionService struct {
 	InjectConfigsError  error
 }
 
-func (m *MockConfigInjectionService) InjectConfigs(ctx context.Context, containerID string, templateIDs []uint) (*models.InjectionStatus, error) {
+func (m *MockConfigInjectionService) InjectConfigs(ctx context.Context, containerID string, workDir string, templateIDs []uint) (*models.InjectionStatus, error) {
 	m.InjectConfigsCalled = true
 	m.InjectConfigsInput.ContainerID = containerID
 	m.InjectConfigsInput.TemplateIDs = templateIDs
@@ -155,11 +155,11 @@ func (m *MockConfigInjectionService) InjectClaudeMD(ctx context.Context, contain
 	return nil
 }
 
-func (m *MockConfigInjectionService) InjectSkill(ctx context.Context, containerID string, name string, content string) error {
+func (m *MockConfigInjectionService) InjectSkill(ctx context.Context, containerID string, name string, content string, files models.SkillFiles) error {
 	return nil
 }
 
-func (m *MockConfigInjectionService) InjectMCP(ctx context.Context, containerID string, configs []MCPServerConfig) error {
+func (m *MockConfigInjectionService) InjectMCP(ctx context.Context, containerID string, workDir string, configs []MCPServerConfig) error {
 	return nil
 }
 
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+// Timeline event types
+const (
+	TimelineEventCreated             = "created"              // The container record was created
+	TimelineEventStatus              = "status"               // Started, restarted, stopped, paused or resumed
+	TimelineEventInitStage           = "init_stage"           // A stage of the most recent initialization
+	TimelineEventLog                 = "log"                  // Any other container log entry
+	TimelineEventModeSwitch          = "mode_switch"          // Switched between TUI and headless mode
+	TimelineEventConversationStarted = "conversation_started" // A headless conversation was started
+	TimelineEventFilesChanged        = "files_changed"        // A headless turn edited or wrote files
+)
+
+// timelineEventTypes lists every event type, in the order used for documentation and validation
+var timelineEventTypes = []string{
+	TimelineEventCreated, TimelineEventStatus, TimelineEventInitStage, TimelineEventLog,
+	TimelineEventModeSwitch, TimelineEventConversationStarted, TimelineEventFilesChanged,
+}
+
+// Timeline page sizes
+const (
+	DefaultTimelineLimit = 100
+	MaxTimelineLimit     = 1000
+)
+
+// ErrInvalidTimelineType is returned for an unknown timeline event type filter
+var ErrInvalidTimelineType = errors.New("invalid timeline event type")
+
+// containerStatusLogs maps the log messages written on status transitions to the resulting status
+var containerStatusLogs = map[string]string{
+	"Container started successfully":   models.ContainerStatusRunning,
+	"Container restarted successfully": models.ContainerStatusRunning,
+	"Container resumed":                models.ContainerStatusRunning,
+	"Container stopped":                models.ContainerStatusStopped,
+	"Container paused":                 models.ContainerStatusPaused,
+}
+
+// fileChangeTools are the Claude tools that modify files, with the input field naming the file
+var fileChangeTools = map[string]string{
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"Write":        "file_path",
+	"NotebookEdit": "notebook_path",
+}
+
+// TimelineEvent is one entry of a container's activity timeline
+type TimelineEvent struct {
+	Type           string    `json:"type"`
+	Timestamp      time.Time `json:"timestamp"`
+	Message        string    `json:"message"`
+	Level          string    `json:"level,omitempty"`  // Log level of log-based events
+	Stage          string    `json:"stage,omitempty"`  // Log or init stage
+	Status         string    `json:"status,omitempty"` // Container status, init stage outcome or mode
+	ConversationID uint      `json:"conversation_id,omitempty"`
+	TurnID         uint      `json:"turn_id,omitempty"`
+	Files          []string  `json:"files,omitempty"`
+}
+
+// TimelineQuery selects a page of a container's timeline
+type TimelineQuery struct {
+	Types  []string // Only these event types; empty means all
+	Limit  int
+	Offset int
+}
+
+// ContainerTimeline is a page of a container's timeline, oldest event first
+type ContainerTimeline struct {
+	ContainerID uint            `json:"container_id"`
+	Events      []TimelineEvent `json:"events"`
+	Total       int             `json:"total"` // Events matching the type filter across all pages
+	Limit       int             `json:"limit"`
+	Offset      int             `json:"offset"`
+	HasMore     bool            `json:"has_more"`
+}
+
+// ParseTimelineTypes parses a comma-separated type filter
+func ParseTimelineTypes(raw string) ([]string, error) {
+	var types []string
+	for _, t := range strings.Split(raw, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !slices.Contains(timelineEventTypes, t) {
+			return nil, fmt.Errorf("%w: %q (use %s)", ErrInvalidTimelineType, t, strings.Join(timelineEventTypes, ", "))
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// RecordModeSwitch adds a container log entry for a switch between TUI and headless mode,
+// so mode switches show up in the timeline after the in-memory mode is gone
+func (s *ContainerService) RecordModeSwitch(ctx context.Context, containerID uint, mode string, closedSessions int) {
+	s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageMode,
+		fmt.Sprintf("Switched to %s mode (closed %d session(s))", mode, closedSessions))
+}
+
+// GetTimeline merges a container's logs, init timings, mode switches, headless conversations
+// and file changes into one chronological feed
+func (s *ContainerService) GetTimeline(id uint, query TimelineQuery) (*ContainerTimeline, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultTimelineLimit
+	}
+	if query.Limit > MaxTimelineLimit {
+		query.Limit = MaxTimelineLimit
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	events := []TimelineEvent{{
+		Type:      TimelineEventCreated,
+		Timestamp: container.CreatedAt,
+		Message:   fmt.Sprintf("Container '%s' created", container.Name),
+	}}
+
+	var logs []models.ContainerLog
+	if err := s.db.Where("container_id = ?", id).Order("created_at, id").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	for _, l := range logs {
+		events = append(events, containerLogEvent(l))
+	}
+
+	if container.InitTiming != nil {
+		for _, stage := range container.InitTiming.Stages {
+			events = append(events, TimelineEvent{
+				Type:      TimelineEventInitStage,
+				Timestamp: stage.StartedAt,
+				Message:   fmt.Sprintf("Init stage %s %s", stage.Stage, stage.Status),
+				Stage:     stage.Stage,
+				Status:    stage.Status,
+			})
+		}
+	}
+
+	headlessEvents, err := s.headlessTimelineEvents(id)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, headlessEvents...)
+
+	// Stable, so events with the same timestamp keep their source order
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+
+	if len(query.Types) > 0 {
+		filtered := events[:0]
+		for _, e := range events {
+			if slices.Contains(query.Types, e.Type) {
+				filtered = append(filtered, e)
+			}
+		}
+		events = filtered
+	}
+
+	timeline := &ContainerTimeline{
+		ContainerID: id,
+		Events:      []TimelineEvent{},
+		Total:       len(events),
+		Limit:       query.Limit,
+		Offset:      query.Offset,
+	}
+	if query.Offset < len(events) {
+		end := query.Offset + query.Limit
+		if end > len(events) {
+			end = len(events)
+		}
+		timeline.Events = events[query.Offset:end]
+		timeline.HasMore = end < len(events)
+	}
+	return timeline, nil
+}
+
+// containerLogEvent types a container log entry by its stage and, for status transitions, its message
+func containerLogEvent(l models.ContainerLog) TimelineEvent {
+	event := TimelineEvent{
+		Type:      TimelineEventLog,
+		Timestamp: l.CreatedAt,
+		Message:   l.Message,
+		Level:     l.Level,
+		Stage:     l.Stage,
+	}
+	if status, ok := containerStatusLogs[l.Message]; ok {
+		event.Type = TimelineEventStatus
+		event.Status = status
+	} else if l.Stage == models.LogStageMode {
+		event.Type = TimelineEventModeSwitch
+	}
+	return event
+}
+
+// headlessTimelineEvents returns a conversation_started event per conversation and a
+// files_changed event per turn that edited or wrote files
+func (s *ContainerService) headlessTimelineEvents(containerID uint) ([]TimelineEvent, error) {
+	var conversations []models.HeadlessConversation
+	if err := s.db.Select("id", "created_at").Where("container_id = ?", containerID).
+		Order("created_at, id").Find(&conversations).Error; err != nil {
+		return nil, err
+	}
+	if len(conversations) == 0 {
+		return nil, nil
+	}
+
+	var events []TimelineEvent
+	conversationIDs := make([]uint, 0, len(conversations))
+	for _, conv := range conversations {
+		conversationIDs = append(conversationIDs, conv.ID)
+		events = append(events, TimelineEvent{
+			Type:           TimelineEventConversationStarted,
+			Timestamp:      conv.CreatedAt,
+			Message:        fmt.Sprintf("Headless conversation %d started", conv.ID),
+			ConversationID: conv.ID,
+		})
+	}
+
+	var turns []models.HeadlessTurn
+	if err := s.db.Select("id", "conversation_id", "turn_index", "created_at", "completed_at").
+		Where("conversation_id IN ?", conversationIDs).Find(&turns).Error; err != nil {
+		return nil, err
+	}
+	if len(turns) == 0 {
+		return events, nil
+	}
+	turnsByID := make(map[uint]models.HeadlessTurn, len(turns))
+	turnIDs := make([]uint, 0, len(turns))
+	for _, turn := range turns {
+		turnsByID[turn.ID] = turn
+		turnIDs = append(turnIDs, turn.ID)
+	}
+
+	// Only assistant events can carry tool_use blocks; skip the rest without decoding them.
+	// Compressed events can't be searched, so they are all loaded and decoded.
+	var toolEvents []models.HeadlessEvent
+	if err := s.db.Select("turn_id", "event_index", "created_at", "raw_json", "encoding").
+		Where("turn_id IN ? AND event_type = ? AND (raw_json LIKE ? OR encoding = ?)",
+			turnIDs, models.HeadlessEventTypeAssistant, "%tool_use%", models.HeadlessEventEncodingGzip).
+		Order("turn_id, event_index").Find(&toolEvents).Error; err != nil {
+		return nil, err
+	}
+
+	type turnFiles struct {
+		files []string
+		seen  map[string]bool
+		last  time.Time
+	}
+	changed := make(map[uint]*turnFiles)
+	var order []uint
+	for _, ev := range toolEvents {
+		for _, path := range changedFilePaths(ev.RawJSON) {
+			tf := changed[ev.TurnID]
+			if tf == nil {
+				tf = &turnFiles{seen: make(map[string]bool)}
+				changed[ev.TurnID] = tf
+				order = append(order, ev.TurnID)
+			}
+			tf.last = ev.CreatedAt
+			if !tf.seen[path] {
+				tf.seen[path] = true
+				tf.files = append(tf.files, path)
+			}
+		}
+	}
+	for _, turnID := range order {
+		tf := changed[turnID]
+		turn := turnsByID[turnID]
+		timestamp := tf.last
+		if turn.CompletedAt != nil {
+			timestamp = *turn.CompletedAt
+		}
+		events = append(events, TimelineEvent{
+			Type:           TimelineEventFilesChanged,
+			Timestamp:      timestamp,
+			Message:        fmt.Sprintf("Turn %d changed %d file(s)", turn.TurnIndex, len(tf.files)),
+			ConversationID: turn.ConversationID,
+			TurnID:         turnID,
+			Files:          tf.files,
+		})
+	}
+	return events, nil
+}
+
+// changedFilePaths returns the files an assistant stream event's file-editing tool calls touch
+func changedFilePaths(raw string) []string {
+	var event struct {
+		Message *struct {
+			Content []struct {
+				Type  string                 `json:"type"`
+				Name  string                 `json:"name"`
+				Input map[string]interface{} `json:"input"`
+			} `json:"content"`
+		} `json:"message"`
+	}
+	if json.Unmarshal([]byte(raw), &event) != nil || event.Message == nil {
+		return nil
+	}
+	var paths []string
+	for _, block := range event.Message.Content {
+		field, ok := fileChangeTools[block.Name]
+		if block.Type != "tool_use" || !ok {
+			continue
+		}
+		if path, _ := block.Input[field].(string); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cc-platform/internal/models"
+)
+
+func setupTimelineTest(t *testing.T) (*ContainerService, *models.Container, time.Time) {
+	t.Helper()
+	s := setupPauseTestService(t)
+	if err := s.db.AutoMigrate(&models.HeadlessConversation{}, &models.HeadlessTurn{}, &models.HeadlessEvent{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	c := &models.Container{Name: "timeline", DockerID: "timeline-0123456789abcdef", Status: models.ContainerStatusRunning}
+	c.CreatedAt = at(0)
+	c.InitTiming = &models.ContainerInitTiming{Stages: []models.InitStageTiming{
+		{Stage: models.InitStageClone, Status: models.InitStageStatusSucceeded, StartedAt: at(2)},
+	}}
+	if err := s.db.Create(c).Error; err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+
+	// Rows are inserted out of order on purpose; the timeline orders by timestamp, not by source
+	addLog := func(minutes int, stage, message string) {
+		l := &models.ContainerLog{ContainerID: c.ID, Level: models.LogLevelInfo, Stage: stage, Message: message}
+		l.CreatedAt = at(minutes)
+		if err := s.db.Create(l).Error; err != nil {
+			t.Fatalf("failed to create log: %v", err)
+		}
+	}
+	addLog(9, models.LogStageStartup, "Container stopped")
+	addLog(1, models.LogStageStartup, "Container started successfully")
+	addLog(4, models.LogStageMode, "Switched to headless mode (closed 1 session(s))")
+	addLog(3, models.LogStageInit, "Running claude init")
+
+	conv := &models.HeadlessConversation{SessionID: "s1", ContainerID: c.ID}
+	conv.CreatedAt = at(5)
+	if err := s.db.Create(conv).Error; err != nil {
+		t.Fatalf("failed to create conversation: %v", err)
+	}
+	completedAt := at(7)
+	turn := &models.HeadlessTurn{ConversationID: conv.ID, TurnIndex: 0, CompletedAt: &completedAt}
+	turn.CreatedAt = at(6)
+	if err := s.db.Create(turn).Error; err != nil {
+		t.Fatalf("failed to create turn: %v", err)
+	}
+	for i, raw := range []string{
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Edit","input":{"file_path":"main.go"}},{"type":"tool_use","name":"Read","input":{"file_path":"go.mod"}}]}}`,
+		// Large enough to be stored compressed
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Write","input":{"file_path":"util.go","content":"` +
+			strings.Repeat("package main\\n", 400) + `"}},{"type":"tool_use","name":"Edit","input":{"file_path":"main.go"}}]}}`,
+	} {
+		stored, encoding := models.EncodeHeadlessEventJSON(raw)
+		ev := &models.HeadlessEvent{TurnID: turn.ID, EventIndex: i, EventType: models.HeadlessEventTypeAssistant, RawJSON: stored, Encoding: encoding}
+		ev.CreatedAt = at(6)
+		if err := s.db.Create(ev).Error; err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+	}
+	return s, c, base
+}
+
+func TestGetTimeline_OrdersEventsAcrossSources(t *testing.T) {
+	s, c, base := setupTimelineTest(t)
+
+	timeline, err := s.GetTimeline(c.ID, TimelineQuery{})
+	if err != nil {
+		t.Fatalf("GetTimeline returned error: %v", err)
+	}
+	want := []string{
+		TimelineEventCreated,             // 0m
+		TimelineEventStatus,              // 1m
+		TimelineEventInitStage,           // 2m
+		TimelineEventLog,                 // 3m
+		TimelineEventModeSwitch,          // 4m
+		TimelineEventConversationStarted, // 5m
+		TimelineEventFilesChanged,        // 7m
+		TimelineEventStatus,              // 9m
+	}
+	var got []string
+	for i, e := range timeline.Events {
+		got = append(got, e.Type)
+		if i > 0 && e.Timestamp.Before(timeline.Events[i-1].Timestamp) {
+			t.Errorf("event %d (%s) at %v is before the previous event", i, e.Type, e.Timestamp)
+		}
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("event types = %v, want %v", got, want)
+	}
+
+	if status := timeline.Events[7].Status; status != models.ContainerStatusStopped {
+		t.Errorf("last status = %q, want stopped", status)
+	}
+	files := timeline.Events[6]
+	if fmt.Sprint(files.Files) != "[main.go util.go]" || files.TurnID == 0 || !files.Timestamp.Equal(base.Add(7*time.Minute)) {
+		t.Errorf("unexpected files_changed event: %+v", files)
+	}
+	if timeline.Total != len(want) || timeline.HasMore {
+		t.Errorf("total = %d, has_more = %v", timeline.Total, timeline.HasMore)
+	}
+}
+
+func TestGetTimeline_TypeFilterAndPaging(t *testing.T) {
+	s, c, _ := setupTimelineTest(t)
+
+	timeline, err := s.GetTimeline(c.ID, TimelineQuery{Types: []string{TimelineEventStatus, TimelineEventModeSwitch}, Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("GetTimeline returned error: %v", err)
+	}
+	if timeline.Total != 3 || timeline.HasMore || len(timeline.Events) != 2 {
+		t.Fatalf("unexpected page: total %d, has_more %v, %d events", timeline.Total, timeline.HasMore, len(timeline.Events))
+	}
+	if timeline.Events[0].Type != TimelineEventModeSwitch || timeline.Events[1].Type != TimelineEventStatus {
+		t.Errorf("unexpected page events: %+v", timeline.Events)
+	}
+
+	page, err := s.GetTimeline(c.ID, TimelineQuery{Limit: 3})
+	if err != nil {
+		t.Fatalf("GetTimeline returned error: %v", err)
+	}
+	if len(page.Events) != 3 || !page.HasMore {
+		t.Errorf("expected a first page of 3 with more, got %d (has_more %v)", len(page.Events), page.HasMore)
+	}
+	past, err := s.GetTimeline(c.ID, TimelineQuery{Offset: 100})
+	if err != nil || len(past.Events) != 0 || past.HasMore {
+		t.Errorf("expected an empty page past the end, got %+v, %v", past, err)
+	}
+
+	if _, err := ParseTimelineTypes("status, bogus"); !errors.Is(err, ErrInvalidTimelineType) {
+		t.Errorf("Expected ErrInvalidTimelineType, got %v", err)
+	}
+	if _, err := s.GetTimeline(999, TimelineQuery{}); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+}
+
+func TestRecordModeSwitch_AppearsInTimeline(t *testing.T) {
+	s := setupPauseTestService(t)
+	if err := s.db.AutoMigrate(&models.HeadlessConversation{}, &models.HeadlessTurn{}, &models.HeadlessEvent{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	c := createPauseTestContainer(t, s, "modes", models.ContainerStatusRunning)
+
+	s.RecordModeSwitch(context.Background(), c.ID, "headless", 2)
+
+	timeline, err := s.GetTimeline(c.ID, TimelineQuery{Types: []string{TimelineEventModeSwitch}})
+	if err != nil {
+		t.Fatalf("GetTimeline returned error: %v", err)
+	}
+	if len(timeline.Events) != 1 || timeline.Events[0].Message != "Switched to headless mode (closed 2 session(s))" {
+		t.Errorf("unexpected mode switch events: %+v", timeline.Events)
+	}
+}
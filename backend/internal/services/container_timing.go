@@ -0,0 +1,183 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"sync"
+
+	"cc-platform/internal/models"
+)
+
+// InitStageStats summarizes how long a stage took across containers
+type InitStageStats struct {
+	Stage    string  `json:"stage"`
+	Count    int     `json:"count"`
+	Failed   int     `json:"failed"`
+	MinMS    int64   `json:"min_ms"`
+	MaxMS    int64   `json:"max_ms"`
+	AvgMS    float64 `json:"avg_ms"`
+	MedianMS float64 `json:"median_ms"`
+}
+
+// initTimingRecorder collects stage timings for one start/initialization run
+// and writes them to the container record after every change
+type initTimingRecorder struct {
+	mu          sync.Mutex
+	service     *ContainerService
+	containerID uint
+	timing      models.ContainerInitTiming
+	progress    int // highest progress persisted so far; never decreases within a run
+}
+
+func (s *ContainerService) newInitTimingRecorder(containerID uint) *initTimingRecorder {
+	return &initTimingRecorder{service: s, containerID: containerID}
+}
+
+// begin starts timing a stage and returns the handle passed to end
+func (r *initTimingRecorder) begin(stage string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index := r.timing.Begin(stage)
+	r.persistLocked()
+	return index
+}
+
+// end finishes a stage started with begin
+func (r *initTimingRecorder) end(index int, status string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timing.End(index, status)
+	r.persistLocked()
+}
+
+// skip records a stage that was not run
+func (r *initTimingRecorder) skip(stage string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timing.End(r.timing.Begin(stage), models.InitStageStatusSkipped)
+	r.persistLocked()
+}
+
+func (r *initTimingRecorder) persistLocked() {
+	timing := r.timing
+	previous := r.progress
+	r.progress = max(r.progress, initProgressPercent(timing))
+	if err := r.service.db.Model(&models.Container{}).Where("id = ?", r.containerID).Updates(map[string]interface{}{
+		"init_timing":   &timing,
+		"init_progress": r.progress,
+	}).Error; err != nil {
+		log.Printf("Failed to store init timing for container %d: %v", r.containerID, err)
+	}
+	if r.progress != previous && r.service.initConsole != nil {
+		r.service.initConsole.publishProgress(r.containerID, r.progress)
+	}
+}
+
+// InitProgressReady is the progress of a container whose initialization is ready
+const InitProgressReady = 100
+
+// initStageWeights is each stage's share of the init progress percentage.
+// Cloning and Claude init take by far the longest, so they carry most of the weight.
+var initStageWeights = map[string]int{
+	models.InitStageStartup:    5,
+	models.InitStageClone:      35,
+	models.InitStageInject:     5,
+	models.InitStagePreInit:    10,
+	models.InitStageClaudeInit: 35,
+	models.InitStageCodeServer: 10,
+}
+
+// initProgressPercent derives a run's progress from its stages. A stage counts once it has
+// ended, whatever the outcome; stages a run leaves out are only made up for when it is
+// ready, so the result stays below InitProgressReady until then.
+func initProgressPercent(timing models.ContainerInitTiming) int {
+	percent := 0
+	for _, stage := range timing.Stages {
+		if stage.Status != models.InitStageStatusRunning {
+			percent += initStageWeights[stage.Stage]
+		}
+	}
+	return min(percent, InitProgressReady-1)
+}
+
+// GetInitTiming returns the stage timings of a container's most recent initialization
+func (s *ContainerService) GetInitTiming(id uint) (*models.ContainerInitTiming, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	if container.InitTiming == nil {
+		return &models.ContainerInitTiming{Stages: []models.InitStageTiming{}}, nil
+	}
+	return container.InitTiming, nil
+}
+
+// GetInitTimingStats aggregates stage timings across all containers
+func (s *ContainerService) GetInitTimingStats() ([]InitStageStats, error) {
+	var containers []models.Container
+	if err := s.db.Select("id", "init_timing").Where("init_timing IS NOT NULL").Find(&containers).Error; err != nil {
+		return nil, err
+	}
+
+	timings := make([]models.ContainerInitTiming, 0, len(containers))
+	for _, c := range containers {
+		if c.InitTiming != nil {
+			timings = append(timings, *c.InitTiming)
+		}
+	}
+	return aggregateInitTimings(timings), nil
+}
+
+// initStageOrder fixes the order stages are reported in
+var initStageOrder = []string{
+	models.InitStageStartup,
+	models.InitStageClone,
+	models.InitStageInject,
+	models.InitStagePreInit,
+	models.InitStageClaudeInit,
+	models.InitStageCodeServer,
+}
+
+// aggregateInitTimings computes duration statistics per stage.
+// Only succeeded stages contribute durations; failures are counted separately.
+func aggregateInitTimings(timings []models.ContainerInitTiming) []InitStageStats {
+	durations := make(map[string][]int64)
+	failures := make(map[string]int)
+	for _, timing := range timings {
+		for _, stage := range timing.Stages {
+			switch stage.Status {
+			case models.InitStageStatusSucceeded:
+				durations[stage.Stage] = append(durations[stage.Stage], stage.DurationMS)
+			case models.InitStageStatusFailed:
+				failures[stage.Stage]++
+			}
+		}
+	}
+
+	stats := []InitStageStats{}
+	for _, stage := range initStageOrder {
+		values := durations[stage]
+		if len(values) == 0 && failures[stage] == 0 {
+			continue
+		}
+		entry := InitStageStats{Stage: stage, Count: len(values), Failed: failures[stage]}
+		if len(values) > 0 {
+			sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+			var total int64
+			for _, v := range values {
+				total += v
+			}
+			entry.MinMS = values[0]
+			entry.MaxMS = values[len(values)-1]
+			entry.AvgMS = float64(total) / float64(len(values))
+			mid := len(values) / 2
+			if len(values)%2 == 0 {
+				entry.MedianMS = float64(values[mid-1]+values[mid]) / 2
+			} else {
+				entry.MedianMS = float64(values[mid])
+			}
+		}
+		stats = append(stats, entry)
+	}
+	return stats
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"testing"
+
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func stageTiming(stage, status string, durationMS int64) models.InitStageTiming {
+	return models.InitStageTiming{Stage: stage, Status: status, DurationMS: durationMS}
+}
+
+func TestAggregateInitTimings_MedianAndOrder(t *testing.T) {
+	timings := []models.ContainerInitTiming{
+		{Stages: []models.InitStageTiming{
+			stageTiming(models.InitStageClone, models.InitStageStatusSucceeded, 300),
+			stageTiming(models.InitStageStartup, models.InitStageStatusSucceeded, 50),
+		}},
+		{Stages: []models.InitStageTiming{
+			stageTiming(models.InitStageClone, models.InitStageStatusSucceeded, 100),
+			stageTiming(models.InitStageClaudeInit, models.InitStageStatusSkipped, 0),
+		}},
+		{Stages: []models.InitStageTiming{
+			stageTiming(models.InitStageClone, models.InitStageStatusSucceeded, 200),
+			stageTiming(models.InitStageClone, models.InitStageStatusFailed, 5),
+			stageTiming(models.InitStageStartup, models.InitStageStatusSucceeded, 70),
+		}},
+	}
+
+	stats := aggregateInitTimings(timings)
+	if len(stats) != 2 {
+		t.Fatalf("Expected startup and clone stats only, got %+v", stats)
+	}
+	if stats[0].Stage != models.InitStageStartup || stats[1].Stage != models.InitStageClone {
+		t.Fatalf("Expected stages in run order, got %s, %s", stats[0].Stage, stats[1].Stage)
+	}
+
+	startup := stats[0]
+	if startup.Count != 2 || startup.MedianMS != 60 || startup.AvgMS != 60 {
+		t.Errorf("Unexpected startup stats: %+v", startup)
+	}
+
+	clone := stats[1]
+	if clone.Count != 3 || clone.Failed != 1 {
+		t.Errorf("Expected 3 succeeded and 1 failed clone, got %+v", clone)
+	}
+	if clone.MinMS != 100 || clone.MaxMS != 300 || clone.MedianMS != 200 || clone.AvgMS != 200 {
+		t.Errorf("Unexpected clone stats: %+v", clone)
+	}
+}
+
+func TestInitTimingRecorder_PersistsStages(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:inittimingdb?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Container{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	container := &models.Container{DockerID: "timing", Name: "timing", Status: models.ContainerStatusRunning}
+	if err := db.Create(container).Error; err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+
+	s := &ContainerService{db: db}
+	recorder := s.newInitTimingRecorder(container.ID)
+	stage := recorder.begin(models.InitStageClone)
+
+	timing, err := s.GetInitTiming(container.ID)
+	if err != nil {
+		t.Fatalf("GetInitTiming failed: %v", err)
+	}
+	if len(timing.Stages) != 1 || timing.Stages[0].Status != models.InitStageStatusRunning {
+		t.Fatalf("Expected a running clone stage, got %+v", timing.Stages)
+	}
+
+	recorder.end(stage, models.InitStageStatusSucceeded)
+	recorder.skip(models.InitStageCodeServer)
+
+	timing, err = s.GetInitTiming(container.ID)
+	if err != nil {
+		t.Fatalf("GetInitTiming failed: %v", err)
+	}
+	if len(timing.Stages) != 2 {
+		t.Fatalf("Expected 2 stages, got %+v", timing.Stages)
+	}
+	if timing.Stages[0].Status != models.InitStageStatusSucceeded || timing.Stages[0].EndedAt == nil {
+		t.Errorf("Expected finished clone stage, got %+v", timing.Stages[0])
+	}
+	if timing.Stages[1].Stage != models.InitStageCodeServer || timing.Stages[1].Status != models.InitStageStatusSkipped {
+		t.Errorf("Expected skipped code-server stage, got %+v", timing.Stages[1])
+	}
+
+	stats, err := s.GetInitTimingStats()
+	if err != nil {
+		t.Fatalf("GetInitTimingStats failed: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Stage != models.InitStageClone || stats[0].Count != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestInitProgressPercent_StageMapping(t *testing.T) {
+	done := models.InitStageStatusSucceeded
+	tests := []struct {
+		name   string
+		stages []models.InitStageTiming
+		want   int
+	}{
+		{"nothing run", nil, 0},
+		{"startup running", []models.InitStageTiming{stageTiming(models.InitStageStartup, models.InitStageStatusRunning, 0)}, 0},
+		{"started", []models.InitStageTiming{stageTiming(models.InitStageStartup, done, 0)}, 5},
+		{"cloned", []models.InitStageTiming{
+			stageTiming(models.InitStageStartup, done, 0),
+			stageTiming(models.InitStageClone, done, 0),
+		}, 40},
+		{"failed stage still counts", []models.InitStageTiming{
+			stageTiming(models.InitStageStartup, done, 0),
+			stageTiming(models.InitStageClone, done, 0),
+			stageTiming(models.InitStageInject, models.InitStageStatusFailed, 0),
+		}, 45},
+		{"claude init skipped", []models.InitStageTiming{
+			stageTiming(models.InitStageStartup, done, 0),
+			stageTiming(models.InitStageClone, done, 0),
+			stageTiming(models.InitStageClaudeInit, models.InitStageStatusSkipped, 0),
+		}, 75},
+		{"every stage stays below ready", []models.InitStageTiming{
+			stageTiming(models.InitStageStartup, done, 0),
+			stageTiming(models.InitStageClone, done, 0),
+			stageTiming(models.InitStageInject, done, 0),
+			stageTiming(models.InitStagePreInit, done, 0),
+			stageTiming(models.InitStageClaudeInit, done, 0),
+			stageTiming(models.InitStageCodeServer, done, 0),
+		}, InitProgressReady - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := initProgressPercent(models.ContainerInitTiming{Stages: tt.stages})
+			if got != tt.want {
+				t.Errorf("initProgressPercent() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitTimingRecorder_ProgressIsMonotonic(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:initprogressdb?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Container{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	container := &models.Container{DockerID: "progress", Name: "progress", Status: models.ContainerStatusRunning}
+	if err := db.Create(container).Error; err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+
+	hub := NewInitConsoleHub()
+	hub.start(container.ID)
+	_, events, unsubscribe, _ := hub.Subscribe(container.ID)
+	defer unsubscribe()
+
+	s := &ContainerService{db: db, initConsole: hub}
+	progress := func() int {
+		var c models.Container
+		if err := db.First(&c, container.ID).Error; err != nil {
+			t.Fatalf("failed to load container: %v", err)
+		}
+		return c.InitProgress
+	}
+
+	recorder := s.newInitTimingRecorder(container.ID)
+	recorder.end(recorder.begin(models.InitStageStartup), models.InitStageStatusSucceeded)
+	recorder.end(recorder.begin(models.InitStageClone), models.InitStageStatusSucceeded)
+	if got := progress(); got != 40 {
+		t.Fatalf("Expected progress 40 after clone, got %d", got)
+	}
+
+	// A stage that starts again must not pull the progress back
+	recorder.timing.Stages[1].Status = models.InitStageStatusRunning
+	recorder.begin(models.InitStageClaudeInit)
+	if got := progress(); got != 40 {
+		t.Errorf("Expected progress to stay at 40, got %d", got)
+	}
+
+	var published []int
+	for len(events) > 0 {
+		evt := <-events
+		if evt.Type == InitConsoleEventProgress && evt.Progress != nil {
+			published = append(published, *evt.Progress)
+		}
+	}
+	if len(published) != 2 || published[0] != 5 || published[1] != 40 {
+		t.Errorf("Expected progress events [5 40], got %v", published)
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/logging"
+	"cc-platform/internal/models"
+)
+
+// ErrInvalidTTL is returned for a negative or too long container time-to-live
+var ErrInvalidTTL = errors.New("invalid container TTL")
+
+// What the TTL reaper does with an expired container that still has active sessions
+const (
+	TTLActiveSessionsIgnore = "ignore" // Delete it anyway
+	TTLActiveSessionsExtend = "extend" // Push the expiry back by ContainerTTLExtension
+	TTLActiveSessionsBlock  = "block"  // Keep it until its sessions end
+)
+
+// ActiveSessionChecker reports whether a container has terminal or headless sessions in use
+type ActiveSessionChecker func(containerID uint, dockerID string) bool
+
+// ttlAction is what the reaper does with a container on one pass
+type ttlAction int
+
+const (
+	ttlKeep ttlAction = iota
+	ttlWarn
+	ttlExtend
+	ttlDelete
+)
+
+// validateContainerTTL checks a requested time-to-live in minutes; 0 means no TTL
+func validateContainerTTL(minutes int) error {
+	if minutes < 0 || minutes > constants.ContainerTTLMaxMinutes {
+		return fmt.Errorf("%w: ttl_minutes must be between 0 and %d", ErrInvalidTTL, constants.ContainerTTLMaxMinutes)
+	}
+	return nil
+}
+
+// containerExpiresAt returns when a container created at from expires, or nil without a TTL
+func containerExpiresAt(from time.Time, ttlMinutes int) *time.Time {
+	if ttlMinutes <= 0 {
+		return nil
+	}
+	expiresAt := from.Add(time.Duration(ttlMinutes) * time.Minute)
+	return &expiresAt
+}
+
+// decideTTLAction picks the reaper's action for a container. The warning is sent once when the
+// container enters the warning window; active sessions only matter once it has expired.
+func decideTTLAction(c *models.Container, now time.Time, warning time.Duration, policy string, active bool) ttlAction {
+	if c.ExpiresAt == nil {
+		return ttlKeep
+	}
+	if now.Before(*c.ExpiresAt) {
+		if warning > 0 && c.ExpiryWarnedAt == nil && !now.Before(c.ExpiresAt.Add(-warning)) {
+			return ttlWarn
+		}
+		return ttlKeep
+	}
+	if active {
+		switch policy {
+		case TTLActiveSessionsExtend:
+			return ttlExtend
+		case TTLActiveSessionsBlock:
+			return ttlKeep
+		}
+	}
+	return ttlDelete
+}
+
+// SetActiveSessionChecker sets how the TTL reaper finds containers with active sessions.
+// It must be called before StartTTLReaper.
+func (s *ContainerService) SetActiveSessionChecker(checker ActiveSessionChecker) {
+	s.activeSessionChecker = checker
+}
+
+// StartTTLReaper deletes expired containers every interval until the service is closed
+func (s *ContainerService) StartTTLReaper(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.reapExpiredContainers(s.ctx, now)
+			}
+		}
+	}()
+}
+
+// reapExpiredContainers warns about, extends or deletes containers with a TTL
+func (s *ContainerService) reapExpiredContainers(ctx context.Context, now time.Time) {
+	var containers []models.Container
+	if err := s.db.Where("expires_at IS NOT NULL AND status <> ?", models.ContainerStatusDeleted).Find(&containers).Error; err != nil {
+		logging.FromContext(ctx, "ContainerService").Printf("TTL reaper: failed to list containers: %v", err)
+		return
+	}
+
+	var warning time.Duration
+	policy := TTLActiveSessionsIgnore
+	if s.config != nil {
+		warning = s.config.ContainerTTLWarning
+		if s.config.ContainerTTLActiveSessions != "" {
+			policy = s.config.ContainerTTLActiveSessions
+		}
+	}
+
+	for i := range containers {
+		c := &containers[i]
+		active := false
+		if policy != TTLActiveSessionsIgnore && s.activeSessionChecker != nil && !now.Before(*c.ExpiresAt) {
+			active = s.activeSessionChecker(c.ID, c.DockerID)
+		}
+
+		switch decideTTLAction(c, now, warning, policy, active) {
+		case ttlWarn:
+			s.db.Model(c).Update("expiry_warned_at", now)
+			s.addLog(ctx, c.ID, models.LogLevelWarn, models.LogStageStartup,
+				fmt.Sprintf("Container expires at %s and will be deleted", c.ExpiresAt.Format(time.RFC3339)))
+			s.notifyContainerExpiring(ctx, c)
+		case ttlExtend:
+			expiresAt := now.Add(constants.ContainerTTLExtension)
+			s.db.Model(c).Updates(map[string]interface{}{"expires_at": expiresAt, "expiry_warned_at": nil})
+			s.addLog(ctx, c.ID, models.LogLevelInfo, models.LogStageStartup,
+				fmt.Sprintf("Container has active sessions; expiry extended to %s", expiresAt.Format(time.RFC3339)))
+		case ttlDelete:
+			s.logger(ctx, c.ID).Printf("TTL reaper: deleting container %s, expired at %s", c.Name, c.ExpiresAt.Format(time.RFC3339))
+			if err := s.DeleteContainer(ctx, c.ID); err != nil {
+				s.logger(ctx, c.ID).Printf("TTL reaper: failed to delete container: %v", err)
+			}
+		}
+	}
+}
+
+// notifyContainerExpiring sends the expiry warning to the container's callback URL in the background
+func (s *ContainerService) notifyContainerExpiring(ctx context.Context, c *models.Container) {
+	if c.InitCallbackURL == "" {
+		return
+	}
+	payload := &InitCallbackPayload{
+		Event:       ContainerExpiringEvent,
+		ContainerID: c.ID,
+		DockerID:    c.DockerID,
+		Name:        c.Name,
+		Status:      "expiring",
+		ExpiresAt:   c.ExpiresAt,
+		Timestamp:   time.Now(),
+	}
+	sender := s.callbackSender()
+	if sender == nil {
+		s.addLog(ctx, c.ID, models.LogLevelWarn, models.LogStageStartup, "Expiry warning skipped: WEBHOOK_SECRET is not configured")
+		return
+	}
+	callbackURL := c.InitCallbackURL
+	containerID := c.ID
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if attempts, err := sender.deliver(s.ctx, callbackURL, payload); err != nil {
+			s.addLog(ctx, containerID, models.LogLevelWarn, models.LogStageStartup,
+				fmt.Sprintf("Expiry warning to %s failed after %d attempt(s): %v", callbackURL, attempts, err))
+		}
+	}()
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cc-platform/internal/config"
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+)
+
+func TestContainerExpiresAt(t *testing.T) {
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	if got := containerExpiresAt(created, 0); got != nil {
+		t.Errorf("Expected no expiry without a TTL, got %v", got)
+	}
+	if got := containerExpiresAt(created, 90); got == nil || !got.Equal(created.Add(90*time.Minute)) {
+		t.Errorf("Expected expiry 90 minutes after creation, got %v", got)
+	}
+
+	for _, minutes := range []int{-1, constants.ContainerTTLMaxMinutes + 1} {
+		if err := validateContainerTTL(minutes); !errors.Is(err, ErrInvalidTTL) {
+			t.Errorf("Expected ErrInvalidTTL for %d minutes, got %v", minutes, err)
+		}
+	}
+	if err := validateContainerTTL(constants.ContainerTTLMaxMinutes); err != nil {
+		t.Errorf("Expected the maximum TTL to be accepted, got %v", err)
+	}
+}
+
+func TestDecideTTLAction(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		v := now.Add(d)
+		return &v
+	}
+	warning := 10 * time.Minute
+
+	testCases := []struct {
+		name      string
+		container models.Container
+		policy    string
+		active    bool
+		want      ttlAction
+	}{
+		{"no ttl", models.Container{}, TTLActiveSessionsIgnore, false, ttlKeep},
+		{"not yet in warning window", models.Container{ExpiresAt: at(time.Hour)}, TTLActiveSessionsIgnore, false, ttlKeep},
+		{"enters warning window", models.Container{ExpiresAt: at(5 * time.Minute)}, TTLActiveSessionsIgnore, false, ttlWarn},
+		{"already warned", models.Container{ExpiresAt: at(5 * time.Minute), ExpiryWarnedAt: at(-time.Minute)}, TTLActiveSessionsIgnore, false, ttlKeep},
+		{"expired", models.Container{ExpiresAt: at(-time.Second)}, TTLActiveSessionsIgnore, false, ttlDelete},
+		{"expired with sessions, ignored", models.Container{ExpiresAt: at(0)}, TTLActiveSessionsIgnore, true, ttlDelete},
+		{"expired with sessions, extended", models.Container{ExpiresAt: at(0)}, TTLActiveSessionsExtend, true, ttlExtend},
+		{"expired with sessions, blocked", models.Container{ExpiresAt: at(0)}, TTLActiveSessionsBlock, true, ttlKeep},
+		{"expired without sessions, blocked policy", models.Container{ExpiresAt: at(0)}, TTLActiveSessionsBlock, false, ttlDelete},
+	}
+
+	for _, tc := range testCases {
+		if got := decideTTLAction(&tc.container, now, warning, tc.policy, tc.active); got != tc.want {
+			t.Errorf("%s: expected action %d, got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestReapExpiredContainers_ActiveSessions(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+
+	for _, policy := range []string{TTLActiveSessionsExtend, TTLActiveSessionsBlock} {
+		t.Run(policy, func(t *testing.T) {
+			s := setupPauseTestService(t)
+			s.config = &config.Config{ContainerTTLWarning: 10 * time.Minute, ContainerTTLActiveSessions: policy}
+			c := createPauseTestContainer(t, s, "demo", models.ContainerStatusRunning)
+			s.db.Model(c).Updates(map[string]interface{}{"ttl_minutes": 30, "expires_at": expired})
+
+			var checked uint
+			s.SetActiveSessionChecker(func(containerID uint, dockerID string) bool {
+				checked = containerID
+				return true
+			})
+			s.reapExpiredContainers(context.Background(), now)
+
+			if checked != c.ID {
+				t.Fatalf("Expected active sessions to be checked for container %d", c.ID)
+			}
+			got, err := s.GetContainer(c.ID)
+			if err != nil {
+				t.Fatalf("Expected the container to survive, got %v", err)
+			}
+			if policy == TTLActiveSessionsExtend && !got.ExpiresAt.Equal(now.Add(constants.ContainerTTLExtension)) {
+				t.Errorf("Expected the expiry to be extended, got %v", got.ExpiresAt)
+			}
+			if policy == TTLActiveSessionsBlock && !got.ExpiresAt.Equal(expired) {
+				t.Errorf("Expected the expiry to stay unchanged, got %v", got.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestReapExpiredContainers_RecordsWarning(t *testing.T) {
+	s := setupPauseTestService(t)
+	s.config = &config.Config{ContainerTTLWarning: 10 * time.Minute}
+	c := createPauseTestContainer(t, s, "demo", models.ContainerStatusRunning)
+	now := time.Now()
+	s.db.Model(c).Updates(map[string]interface{}{"ttl_minutes": 30, "expires_at": now.Add(5 * time.Minute)})
+
+	s.reapExpiredContainers(context.Background(), now)
+
+	got, _ := s.GetContainer(c.ID)
+	if got.ExpiryWarnedAt == nil {
+		t.Error("Expected the expiry warning to be recorded")
+	}
+}
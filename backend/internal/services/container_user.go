@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cc-platform/internal/docker"
+)
+
+// ErrInvalidRunAsUser is returned for a malformed run_as_user or one combined with run_as_root
+var ErrInvalidRunAsUser = errors.New("invalid run_as_user")
+
+// usernamePattern matches POSIX-style usernames as accepted by useradd
+var usernamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+// normalizeRunAsUser validates the user a container runs as. Empty keeps the image's
+// configured user. Otherwise it must be a username or a numeric uid with an optional gid.
+// Root is rejected, so running as root always goes through run_as_root and its confirmation.
+func normalizeRunAsUser(user string, runAsRoot bool) (string, error) {
+	user = strings.TrimSpace(user)
+	if user == "" {
+		return "", nil
+	}
+	if runAsRoot {
+		return "", fmt.Errorf("%w: cannot be combined with run_as_root", ErrInvalidRunAsUser)
+	}
+	if usernamePattern.MatchString(user) {
+		if user == "root" {
+			return "", fmt.Errorf("%w: use run_as_root to run as root", ErrInvalidRunAsUser)
+		}
+		return user, nil
+	}
+
+	uid, gid, hasGID := strings.Cut(user, ":")
+	if !isValidID(uid) || (hasGID && !isValidID(gid)) {
+		return "", fmt.Errorf("%w: '%s' (expected a username, uid or uid:gid)", ErrInvalidRunAsUser, user)
+	}
+	if n, _ := strconv.ParseUint(uid, 10, 32); n == 0 {
+		return "", fmt.Errorf("%w: use run_as_root to run as root", ErrInvalidRunAsUser)
+	}
+	return user, nil
+}
+
+// isValidID reports whether s is a decimal uid or gid
+func isValidID(s string) bool {
+	if s == "" || strings.HasPrefix(s, "+") {
+		return false
+	}
+	_, err := strconv.ParseUint(s, 10, 32)
+	return err == nil
+}
+
+// runAsUserEnv returns the container env that moves HOME and the config injection home
+// to the run-as user's home, so injected configs land where that user's tools read them
+func runAsUserEnv(user string) []string {
+	if user == "" {
+		return nil
+	}
+	return docker.UserHomeEnv(user)
+}
+
+// runAsUserHomeCommand creates the run-as user's home and hands it to that user.
+// Custom users usually have no home in the base image.
+func runAsUserHomeCommand(user string) []string {
+	home := docker.HomeDirForUser(user)
+	return []string{"bash", "-c", fmt.Sprintf("mkdir -p %s/.claude %s/.npm && chown -R %s %s", home, home, user, home)}
+}
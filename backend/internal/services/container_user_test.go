@@ -0,0 +1,49 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeRunAsUser(t *testing.T) {
+	valid := map[string]string{
+		"":            "",
+		"  alice  ":   "alice",
+		"developer":   "developer",
+		"1001":        "1001",
+		"1001:1001":   "1001:1001",
+		"_svc-user01": "_svc-user01",
+		"1001:0":      "1001:0",
+	}
+	for in, want := range valid {
+		got, err := normalizeRunAsUser(in, false)
+		if err != nil || got != want {
+			t.Errorf("normalizeRunAsUser(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"1001:", ":1001", "-1", "+5", "1001:abc", "99999999999", "Alice", "a b", "alice;rm -rf /", "1:2:3", "root", "0", "0:0", "00:1001"} {
+		if _, err := normalizeRunAsUser(in, false); !errors.Is(err, ErrInvalidRunAsUser) {
+			t.Errorf("Expected ErrInvalidRunAsUser for %q, got %v", in, err)
+		}
+	}
+	if _, err := normalizeRunAsUser("alice", true); !errors.Is(err, ErrInvalidRunAsUser) {
+		t.Errorf("Expected run_as_user to conflict with run_as_root, got %v", err)
+	}
+}
+
+func TestRunAsUserEnv(t *testing.T) {
+	if env := runAsUserEnv(""); len(env) != 0 {
+		t.Errorf("Expected no env for the image's default user, got %v", env)
+	}
+	env := runAsUserEnv("alice")
+	want := map[string]bool{"HOME=/home/alice": true, "CC_CONFIG_HOME=/home/alice": true}
+	if len(env) != len(want) {
+		t.Fatalf("Expected %d env vars, got %v", len(want), env)
+	}
+	for _, entry := range env {
+		if !want[entry] {
+			t.Errorf("Unexpected env entry %q", entry)
+		}
+	}
+}
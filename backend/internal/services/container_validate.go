@@ -0,0 +1,334 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cc-platform/internal/models"
+)
+
+// Problem codes reported by ValidateCreateInput
+const (
+	InputProblemInvalidName      = "invalid_name"
+	InputProblemNameTaken        = "name_taken"
+	InputProblemInvalidOption    = "invalid_option"
+	InputProblemInvalidResources = "invalid_resources"
+	InputProblemMissingRepoURL   = "missing_repo_url"
+	InputProblemMissingToken     = "missing_token"
+	InputProblemUnknownProfile   = "unknown_profile"
+	InputProblemUnknownTemplate  = "unknown_template"
+	InputProblemPortUnavailable  = "port_unavailable"
+	InputProblemYoloNotConfirmed = "yolo_not_confirmed"
+	// InputProblemPreInitNotConfirmed is a pre-init script sent without confirm_yolo or run_as_root
+	InputProblemPreInitNotConfirmed = "pre_init_script_not_confirmed"
+)
+
+// What CreateContainer does when a selected template is missing or has the wrong type
+const (
+	TemplatePrecheckReject = "reject" // Refuse to create the container
+	TemplatePrecheckWarn   = "warn"   // Create it and log a warning; injection records the failure
+)
+
+// ErrInvalidTemplateSelection is returned when a selected template does not exist or has the wrong type
+var ErrInvalidTemplateSelection = errors.New("invalid template selection")
+
+// ErrYoloNotConfirmed is returned when YOLO mode is requested without confirm_yolo
+var ErrYoloNotConfirmed = errors.New("YOLO mode requires explicit confirmation")
+
+// ErrPreInitScriptNotConfirmed is returned when a pre-init script is sent without confirm_yolo or run_as_root
+var ErrPreInitScriptNotConfirmed = errors.New("pre-init script requires explicit confirmation")
+
+// ContainerInputProblem is one reason a create request would be rejected
+type ContainerInputProblem struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidateCreateInput runs the checks CreateContainer would without creating anything and
+// returns every problem found. It only reads the database and probes host ports.
+func (s *ContainerService) ValidateCreateInput(input CreateContainerInput) []ContainerInputProblem {
+	problems := []ContainerInputProblem{}
+	add := func(field, code string, err error) {
+		problems = append(problems, ContainerInputProblem{Field: field, Code: code, Message: err.Error()})
+	}
+
+	if err := s.applyContainerProfile(&input); err != nil {
+		add("profile_id", InputProblemInvalidOption, err)
+	}
+
+	if err := validateYoloConfirmation(input); err != nil {
+		add("confirm_yolo", InputProblemYoloNotConfirmed, err)
+	}
+	if err := validatePreInitScriptConfirmation(input); err != nil {
+		add("pre_init_script", InputProblemPreInitNotConfirmed, err)
+	}
+	if err := validateContainerName(input.Name); err != nil {
+		add("name", InputProblemInvalidName, err)
+	} else if s.containerNameTaken(input.Name) {
+		add("name", InputProblemNameTaken, fmt.Errorf("a container named '%s' already exists", input.Name))
+	}
+
+	if err := validatePreInitScript(input.PreInitScript); err != nil {
+		add("pre_init_script", InputProblemInvalidOption, err)
+	}
+	if _, err := resolveInitStrategy(input.InitStrategy, input.SkipClaudeInit, input.PreInitScript); err != nil {
+		add("init_strategy", InputProblemInvalidOption, err)
+	}
+	if err := s.checkInitCallbackURL(input.InitCallbackURL); err != nil {
+		add("init_callback_url", InputProblemInvalidOption, err)
+	}
+	var hostMountsEnabled bool
+	var hostMountAllowlist []string
+	if s.config != nil {
+		hostMountsEnabled = s.config.HostMountsEnabled
+		hostMountAllowlist = s.config.HostMountAllowlist
+	}
+	if _, err := validateHostMounts(input.HostMounts, hostMountsEnabled, hostMountAllowlist); err != nil {
+		add("host_mounts", InputProblemInvalidOption, err)
+	}
+	if _, _, err := normalizeRestartPolicy(input.RestartPolicy, input.RestartMaxRetries); err != nil {
+		add("restart_policy", InputProblemInvalidOption, err)
+	}
+	if _, err := normalizeRunAsUser(input.RunAsUser, input.RunAsRoot); err != nil {
+		add("run_as_user", InputProblemInvalidOption, err)
+	}
+	if err := validateContainerTTL(input.TTLMinutes); err != nil {
+		add("ttl_minutes", InputProblemInvalidOption, err)
+	}
+	if _, err := normalizeContainerImage(input.Image, s.registryAllowlist()); err != nil {
+		add("image", InputProblemInvalidOption, err)
+	}
+	if _, err := normalizeClaudeVersion(input.ClaudeVersion); err != nil {
+		add("claude_version", InputProblemInvalidOption, err)
+	}
+	if _, _, _, err := normalizeClaudeOverrides(input.ClaudeAPIBase, "", ""); err != nil {
+		add("claude_api_base", InputProblemInvalidOption, err)
+	}
+	if _, _, _, err := normalizeClaudeOverrides("", input.ClaudeModel, ""); err != nil {
+		add("claude_model", InputProblemInvalidOption, err)
+	}
+	if _, err := normalizeCodeServerAuth(input.CodeServerAuth); err != nil {
+		add("code_server_auth", InputProblemInvalidOption, err)
+	}
+	if _, err := normalizeCodeServerExtensions(input.CodeServerExtensions, input.codeServerEnabled()); err != nil {
+		add("code_server_extensions", InputProblemInvalidOption, err)
+	}
+
+	if err := s.applyResourceProfile(&input); err != nil {
+		add("resource_profile", InputProblemInvalidResources, err)
+	}
+	cpuLimit, memoryLimit := input.CPULimit, input.MemoryLimit
+	if input.CPUUnlimited {
+		cpuLimit = 0
+	}
+	if input.MemoryUnlimited {
+		memoryLimit = 0
+	}
+	if err := validateResourceLimits(cpuLimit, memoryLimit, CPUPeriodDefault); err != nil {
+		add("resources", InputProblemInvalidResources, err)
+	}
+	if input.GPUEnabled && input.GPUCount < -1 {
+		add("gpu_count", InputProblemInvalidResources, fmt.Errorf("%w: must be -1, 0, or a positive integer", ErrInvalidGPUCount))
+	}
+
+	if !input.SkipGitRepo {
+		if input.GitRepoURL == "" {
+			add("git_repo_url", InputProblemMissingRepoURL, fmt.Errorf("git_repo_url is required when skip_git_repo is false"))
+		} else if _, _, err := normalizeRepoURL(input.GitRepoURL, s.gitRepoHosts()); err != nil {
+			add("git_repo_url", InputProblemInvalidOption, err)
+		}
+		if name := strings.TrimSpace(input.GitRepoName); name != "" && !repoDirPattern.MatchString(name) {
+			add("git_repo_name", InputProblemInvalidOption, fmt.Errorf("%w: invalid git_repo_name %q", ErrInvalidGitRepo, name))
+		}
+		if !s.recordExists(&models.GitHubToken{}, nil) {
+			add("github_token_id", InputProblemMissingToken, ErrNoGitHubTokenConfigured)
+		}
+	}
+	if input.GitHubTokenID != nil && !s.recordExists(&models.GitHubToken{}, input.GitHubTokenID) {
+		add("github_token_id", InputProblemUnknownProfile, fmt.Errorf("%w: GitHub token %d", ErrTokenNotFound, *input.GitHubTokenID))
+	}
+	if input.EnvVarsProfileID != nil && !s.recordExists(&models.EnvVarsProfile{}, input.EnvVarsProfileID) {
+		add("env_vars_profile_id", InputProblemUnknownProfile, fmt.Errorf("%w: environment profile %d", ErrProfileNotFound, *input.EnvVarsProfileID))
+	}
+	if input.StartupCommandProfileID != nil && !s.recordExists(&models.StartupCommandProfile{}, input.StartupCommandProfileID) {
+		add("startup_command_profile_id", InputProblemUnknownProfile, fmt.Errorf("%w: startup command profile %d", ErrProfileNotFound, *input.StartupCommandProfileID))
+	}
+
+	problems = append(problems, s.validateTemplateSelections(input)...)
+	problems = append(problems, s.validatePortAvailability(input)...)
+	return problems
+}
+
+// containerNameTaken reports whether a live container already uses the name
+func (s *ContainerService) containerNameTaken(name string) bool {
+	var count int64
+	s.db.Model(&models.Container{}).Where("name = ? AND status <> ?", name, models.ContainerStatusDeleted).Count(&count)
+	return count > 0
+}
+
+// recordExists reports whether the record with the given ID exists, or any record when id is nil
+func (s *ContainerService) recordExists(model interface{}, id *uint) bool {
+	query := s.db.Model(model)
+	if id != nil {
+		query = query.Where("id = ?", *id)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// templateSelection is one of the per-type template lists of a create request
+type templateSelection struct {
+	field      string
+	configType models.ConfigType
+	ids        []uint
+}
+
+// validateTemplateSelections checks that every selected template exists and has the type of its list
+func (s *ContainerService) validateTemplateSelections(input CreateContainerInput) []ContainerInputProblem {
+	var claudeMD []uint
+	if input.SelectedClaudeMD != nil {
+		claudeMD = []uint{*input.SelectedClaudeMD}
+	}
+	selections := []templateSelection{
+		{"selected_claude_md", models.ConfigTypeClaudeMD, claudeMD},
+		{"selected_skills", models.ConfigTypeSkill, input.SelectedSkills},
+		{"selected_mcps", models.ConfigTypeMCP, input.SelectedMCPs},
+		{"selected_commands", models.ConfigTypeCommand, input.SelectedCommands},
+		{"selected_codex_configs", models.ConfigTypeCodexConf, input.SelectedCodexConfigs},
+		{"selected_codex_auths", models.ConfigTypeCodexAuth, input.SelectedCodexAuths},
+		{"selected_gemini_envs", models.ConfigTypeGeminiEnv, input.SelectedGeminiEnvs},
+	}
+
+	var ids []uint
+	for _, sel := range selections {
+		ids = append(ids, sel.ids...)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var templates []models.ClaudeConfigTemplate
+	if err := s.db.Select("id", "config_type").Where("id IN ?", dedupeUintSlice(ids)).Find(&templates).Error; err != nil {
+		return []ContainerInputProblem{{Field: "templates", Code: InputProblemUnknownTemplate, Message: fmt.Sprintf("failed to look up templates: %v", err)}}
+	}
+	types := make(map[uint]models.ConfigType, len(templates))
+	for _, t := range templates {
+		types[t.ID] = t.ConfigType
+	}
+
+	var problems []ContainerInputProblem
+	for _, sel := range selections {
+		for _, id := range sel.ids {
+			configType, ok := types[id]
+			switch {
+			case !ok:
+				problems = append(problems, ContainerInputProblem{Field: sel.field, Code: InputProblemUnknownTemplate,
+					Message: fmt.Sprintf("%v: ID %d", ErrTemplateNotFound, id)})
+			case configType != sel.configType:
+				problems = append(problems, ContainerInputProblem{Field: sel.field, Code: InputProblemUnknownTemplate,
+					Message: fmt.Sprintf("template %d is a %s template, expected %s", id, configType, sel.configType)})
+			}
+		}
+	}
+	return problems
+}
+
+// precheckTemplateSelections resolves the selected templates before anything is created.
+// Problems are returned as an error under the reject policy and as warnings under the warn policy.
+func (s *ContainerService) precheckTemplateSelections(input CreateContainerInput) ([]string, error) {
+	problems := s.validateTemplateSelections(input)
+	if len(problems) == 0 {
+		return nil, nil
+	}
+	messages := make([]string, len(problems))
+	for i, p := range problems {
+		messages[i] = fmt.Sprintf("%s: %s", p.Field, p.Message)
+	}
+	if s.config != nil && s.config.TemplatePrecheck == TemplatePrecheckWarn {
+		return messages, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInvalidTemplateSelection, strings.Join(messages, "; "))
+}
+
+// validateYoloConfirmation requires confirm_yolo alongside enable_yolo_mode, since YOLO mode lets
+// Claude run every command without a permission prompt
+func validateYoloConfirmation(input CreateContainerInput) error {
+	if input.EnableYoloMode && !input.ConfirmYolo {
+		return fmt.Errorf("%w: set confirm_yolo to true to run Claude with --dangerously-skip-permissions", ErrYoloNotConfirmed)
+	}
+	return nil
+}
+
+// validatePreInitScriptConfirmation requires confirm_yolo or run_as_root alongside pre_init_script,
+// since the script runs arbitrary commands in the container before anyone can review them
+func validatePreInitScriptConfirmation(input CreateContainerInput) error {
+	if strings.TrimSpace(input.PreInitScript) != "" && !input.ConfirmYolo && !input.RunAsRoot {
+		return fmt.Errorf("%w: set confirm_yolo (or run_as_root) to true to run a pre-init script", ErrPreInitScriptNotConfirmed)
+	}
+	return nil
+}
+
+// validatePortAvailability checks that mapped host ports and the direct proxy port are free
+func (s *ContainerService) validatePortAvailability(input CreateContainerInput) []ContainerInputProblem {
+	var problems []ContainerInputProblem
+	used := s.usedHostPorts(0)
+	requested := make(map[int]bool)
+	for _, pm := range input.PortMappings {
+		field := "port_mappings"
+		switch {
+		case pm.HostPort < 1 || pm.HostPort > 65535 || pm.ContainerPort < 1 || pm.ContainerPort > 65535:
+			problems = append(problems, ContainerInputProblem{Field: field, Code: InputProblemInvalidOption,
+				Message: fmt.Sprintf("port mapping %d:%d is out of range", pm.HostPort, pm.ContainerPort)})
+		case requested[pm.HostPort]:
+			problems = append(problems, ContainerInputProblem{Field: field, Code: InputProblemPortUnavailable,
+				Message: fmt.Sprintf("host port %d is mapped more than once", pm.HostPort)})
+		case used[pm.HostPort] != "":
+			problems = append(problems, ContainerInputProblem{Field: field, Code: InputProblemPortUnavailable,
+				Message: fmt.Sprintf("host port %d is already used by container '%s'", pm.HostPort, used[pm.HostPort])})
+		case !isPortFree(pm.HostPort):
+			problems = append(problems, ContainerInputProblem{Field: field, Code: InputProblemPortUnavailable,
+				Message: fmt.Sprintf("host port %d is already in use on the host", pm.HostPort)})
+		}
+		requested[pm.HostPort] = true
+	}
+
+	if input.Proxy.enabled() && input.Proxy.Port != 0 {
+		var owner models.Container
+		err := s.db.Select("name").Where("proxy_enabled = ? AND proxy_port = ? AND status <> ?", true, input.Proxy.Port, models.ContainerStatusDeleted).
+			First(&owner).Error
+		if err == nil {
+			problems = append(problems, ContainerInputProblem{Field: "proxy.port", Code: InputProblemPortUnavailable,
+				Message: fmt.Sprintf("direct proxy port %d is already used by container '%s'", input.Proxy.Port, owner.Name)})
+		}
+	}
+	return problems
+}
+
+// usedHostPorts maps host ports from the stored port mappings and code-server host ports of
+// containers other than exclude to the container name
+func (s *ContainerService) usedHostPorts(exclude uint) map[int]string {
+	var containers []models.Container
+	s.db.Select("name", "exposed_ports", "code_server_host_port").
+		Where("(exposed_ports <> '' OR code_server_host_port > 0) AND id <> ? AND status <> ?", exclude, models.ContainerStatusDeleted).
+		Find(&containers)
+
+	used := make(map[int]string)
+	for _, c := range containers {
+		if c.CodeServerHostPort > 0 {
+			used[c.CodeServerHostPort] = c.Name
+		}
+		var mappings []PortMapping
+		if json.Unmarshal([]byte(c.ExposedPorts), &mappings) != nil {
+			continue
+		}
+		for _, pm := range mappings {
+			used[pm.HostPort] = c.Name
+		}
+	}
+	return used
+}
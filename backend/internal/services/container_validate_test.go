@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/config"
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+)
+
+func setupValidateTestService(t *testing.T) *ContainerService {
+	t.Helper()
+	s := setupPauseTestService(t)
+	if err := s.db.AutoMigrate(&models.GitHubToken{}, &models.EnvVarsProfile{}, &models.StartupCommandProfile{}, &models.ClaudeConfigTemplate{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	s.resourceProfiles = builtinResourceProfiles
+	return s
+}
+
+// problemCodes returns the problem codes reported for a field
+func problemCodes(problems []ContainerInputProblem, field string) []string {
+	var codes []string
+	for _, p := range problems {
+		if p.Field == field {
+			codes = append(codes, p.Code)
+		}
+	}
+	return codes
+}
+
+func TestValidateCreateInput_Valid(t *testing.T) {
+	s := setupValidateTestService(t)
+	s.db.Create(&models.GitHubToken{Nickname: "main", Token: "enc"})
+
+	problems := s.ValidateCreateInput(CreateContainerInput{Name: "web", GitRepoURL: "https://github.com/acme/web", ResourceProfile: "small"})
+	if len(problems) != 0 {
+		t.Errorf("Expected no problems, got %+v", problems)
+	}
+	if count := len(mustListContainers(t, s)); count != 0 {
+		t.Errorf("Expected nothing to be created, found %d containers", count)
+	}
+}
+
+func TestValidateCreateInput_Problems(t *testing.T) {
+	s := setupValidateTestService(t)
+	existing := createPauseTestContainer(t, s, "taken", models.ContainerStatusStopped)
+	s.db.Model(existing).Updates(map[string]interface{}{"exposed_ports": `[{"container_port":80,"host_port":18080}]`, "proxy_enabled": true, "proxy_port": 30001})
+	md := &models.ClaudeConfigTemplate{Name: "rules", ConfigType: models.ConfigTypeClaudeMD, Content: "# Rules"}
+	s.db.Create(md)
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	busyPort := listener.Addr().(*net.TCPAddr).Port
+
+	missing := uint(99)
+	enabled := true
+	tests := []struct {
+		name  string
+		input CreateContainerInput
+		field string
+		code  string
+	}{
+		{"invalid name", CreateContainerInput{Name: "bad name!", SkipGitRepo: true}, "name", InputProblemInvalidName},
+		{"empty name", CreateContainerInput{SkipGitRepo: true}, "name", InputProblemInvalidName},
+		{"name taken", CreateContainerInput{Name: "taken", SkipGitRepo: true}, "name", InputProblemNameTaken},
+		{"restart policy", CreateContainerInput{Name: "a", SkipGitRepo: true, RestartPolicy: "sometimes"}, "restart_policy", InputProblemInvalidOption},
+		{"run as user", CreateContainerInput{Name: "a", SkipGitRepo: true, RunAsUser: "1001:"}, "run_as_user", InputProblemInvalidOption},
+		{"ttl", CreateContainerInput{Name: "a", SkipGitRepo: true, TTLMinutes: -5}, "ttl_minutes", InputProblemInvalidOption},
+		{"callback url", CreateContainerInput{Name: "a", SkipGitRepo: true, InitCallbackURL: "ftp://example.com"}, "init_callback_url", InputProblemInvalidOption},
+		{"host mounts disabled", CreateContainerInput{Name: "a", SkipGitRepo: true, HostMounts: []HostMountInput{{HostPath: "/srv", ContainerPath: "/srv"}}}, "host_mounts", InputProblemInvalidOption},
+		{"over-limit memory", CreateContainerInput{Name: "a", SkipGitRepo: true, MemoryLimit: 1 << 40}, "resources", InputProblemInvalidResources},
+		{"unknown resource profile", CreateContainerInput{Name: "a", SkipGitRepo: true, ResourceProfile: "huge"}, "resource_profile", InputProblemInvalidResources},
+		{"gpu count", CreateContainerInput{Name: "a", SkipGitRepo: true, GPUEnabled: true, GPUCount: -2}, "gpu_count", InputProblemInvalidResources},
+		{"missing repo url", CreateContainerInput{Name: "a"}, "git_repo_url", InputProblemMissingRepoURL},
+		{"missing token", CreateContainerInput{Name: "a", GitRepoURL: "https://github.com/acme/web"}, "github_token_id", InputProblemMissingToken},
+		{"unknown env profile", CreateContainerInput{Name: "a", SkipGitRepo: true, EnvVarsProfileID: &missing}, "env_vars_profile_id", InputProblemUnknownProfile},
+		{"unknown command profile", CreateContainerInput{Name: "a", SkipGitRepo: true, StartupCommandProfileID: &missing}, "startup_command_profile_id", InputProblemUnknownProfile},
+		{"unknown template", CreateContainerInput{Name: "a", SkipGitRepo: true, SelectedSkills: []uint{missing}}, "selected_skills", InputProblemUnknownTemplate},
+		{"template of another type", CreateContainerInput{Name: "a", SkipGitRepo: true, SelectedMCPs: []uint{md.ID}}, "selected_mcps", InputProblemUnknownTemplate},
+		{"port used by container", CreateContainerInput{Name: "a", SkipGitRepo: true, PortMappings: []PortMapping{{ContainerPort: 80, HostPort: 18080}}}, "port_mappings", InputProblemPortUnavailable},
+		{"port used on host", CreateContainerInput{Name: "a", SkipGitRepo: true, PortMappings: []PortMapping{{ContainerPort: 80, HostPort: busyPort}}}, "port_mappings", InputProblemPortUnavailable},
+		{"proxy port used", CreateContainerInput{Name: "a", SkipGitRepo: true, Proxy: ProxyConfig{Enabled: &enabled, Port: 30001, ServicePort: 3000}}, "proxy.port", InputProblemPortUnavailable},
+		{"yolo not confirmed", CreateContainerInput{Name: "a", SkipGitRepo: true, EnableYoloMode: true}, "confirm_yolo", InputProblemYoloNotConfirmed},
+		{"pre-init script not confirmed", CreateContainerInput{Name: "a", SkipGitRepo: true, PreInitScript: "make deps"}, "pre_init_script", InputProblemPreInitNotConfirmed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := s.ValidateCreateInput(tt.input)
+			codes := problemCodes(problems, tt.field)
+			if len(codes) != 1 || codes[0] != tt.code {
+				t.Errorf("Expected %s problem on %s, got %+v", tt.code, tt.field, problems)
+			}
+		})
+	}
+}
+
+func TestValidateCreateInput_ReportsEveryProblem(t *testing.T) {
+	s := setupValidateTestService(t)
+	missing := uint(7)
+
+	problems := s.ValidateCreateInput(CreateContainerInput{
+		Name:          "bad name!",
+		CPULimit:      -1,
+		SelectedMCPs:  []uint{missing},
+		PreInitScript: strings.Repeat("x", constants.PreInitScriptMaxBytes+1),
+	})
+
+	for _, field := range []string{"name", "resources", "git_repo_url", "github_token_id", "selected_mcps", "pre_init_script"} {
+		if len(problemCodes(problems, field)) == 0 {
+			t.Errorf("Expected a problem for %s, got %+v", field, problems)
+		}
+	}
+}
+
+func TestCreateContainer_RequiresYoloConfirmation(t *testing.T) {
+	s := setupValidateTestService(t)
+
+	_, err := s.CreateContainer(context.Background(), CreateContainerInput{Name: "a", SkipGitRepo: true, EnableYoloMode: true})
+	if !errors.Is(err, ErrYoloNotConfirmed) {
+		t.Fatalf("Expected ErrYoloNotConfirmed, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "confirm_yolo") {
+		t.Errorf("Expected the error to say how to confirm, got %v", err)
+	}
+	if count := len(mustListContainers(t, s)); count != 0 {
+		t.Errorf("Expected nothing to be created, found %d containers", count)
+	}
+
+	if problems := s.ValidateCreateInput(CreateContainerInput{Name: "a", SkipGitRepo: true, EnableYoloMode: true, ConfirmYolo: true}); len(problems) != 0 {
+		t.Errorf("Expected a confirmed YOLO request to be valid, got %+v", problems)
+	}
+}
+
+func TestCreateContainer_RejectsMissingTemplates(t *testing.T) {
+	s := setupValidateTestService(t)
+	missing := uint(42)
+
+	_, err := s.CreateContainer(context.Background(), CreateContainerInput{Name: "a", SkipGitRepo: true, SelectedSkills: []uint{missing}})
+	if !errors.Is(err, ErrInvalidTemplateSelection) {
+		t.Fatalf("Expected ErrInvalidTemplateSelection, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "selected_skills") {
+		t.Errorf("Expected the error to name the field, got %v", err)
+	}
+	if count := len(mustListContainers(t, s)); count != 0 {
+		t.Errorf("Expected nothing to be created, found %d containers", count)
+	}
+}
+
+func TestPrecheckTemplateSelections(t *testing.T) {
+	s := setupValidateTestService(t)
+	skill := &models.ClaudeConfigTemplate{Name: "lint", ConfigType: models.ConfigTypeSkill, Content: "skill"}
+	s.db.Create(skill)
+	missing := uint(42)
+
+	warnings, err := s.precheckTemplateSelections(CreateContainerInput{SelectedSkills: []uint{skill.ID}})
+	if err != nil || len(warnings) != 0 {
+		t.Errorf("Expected existing templates to pass, got warnings %v and error %v", warnings, err)
+	}
+
+	bad := CreateContainerInput{SelectedSkills: []uint{skill.ID, missing}, SelectedMCPs: []uint{skill.ID}}
+	if _, err := s.precheckTemplateSelections(bad); !errors.Is(err, ErrInvalidTemplateSelection) {
+		t.Errorf("Expected ErrInvalidTemplateSelection by default, got %v", err)
+	}
+
+	s.config = &config.Config{TemplatePrecheck: TemplatePrecheckWarn}
+	warnings, err = s.precheckTemplateSelections(bad)
+	if err != nil {
+		t.Fatalf("Expected no error under the warn policy, got %v", err)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("Expected 2 warnings, got %v", warnings)
+	}
+}
+
+func mustListContainers(t *testing.T, s *ContainerService) []models.Container {
+	t.Helper()
+	containers, err := s.ListContainers()
+	if err != nil {
+		t.Fatalf("ListContainers failed: %v", err)
+	}
+	return containers
+}
+
+func TestCreateContainer_RequiresPreInitScriptConfirmation(t *testing.T) {
+	s := setupValidateTestService(t)
+
+	_, err := s.CreateContainer(context.Background(), CreateContainerInput{Name: "a", SkipGitRepo: true, PreInitScript: "curl -fsSL https://example.com/setup.sh | bash"})
+	if !errors.Is(err, ErrPreInitScriptNotConfirmed) {
+		t.Fatalf("Expected ErrPreInitScriptNotConfirmed, got %v", err)
+	}
+	if count := len(mustListContainers(t, s)); count != 0 {
+		t.Errorf("Expected nothing to be created, found %d containers", count)
+	}
+
+	for _, input := range []CreateContainerInput{
+		{Name: "a", SkipGitRepo: true, PreInitScript: "make deps", ConfirmYolo: true},
+		{Name: "a", SkipGitRepo: true, PreInitScript: "make deps", RunAsRoot: true},
+	} {
+		if problems := s.ValidateCreateInput(input); len(problems) != 0 {
+			t.Errorf("Expected an acknowledged pre-init script to be valid, got %+v", problems)
+		}
+	}
+}
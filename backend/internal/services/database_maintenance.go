@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"cc-platform/internal/logging"
+
+	"gorm.io/gorm"
+)
+
+// ErrDatabaseBusy is returned when a vacuum is already running or SQLite can't get the
+// exclusive lock VACUUM needs because other connections are writing
+var ErrDatabaseBusy = errors.New("database is busy")
+
+// VacuumResult reports the outcome of a database vacuum
+type VacuumResult struct {
+	SizeBefore     int64     `json:"size_before"`     // Bytes, from page_count * page_size
+	SizeAfter      int64     `json:"size_after"`      // Bytes
+	ReclaimedBytes int64     `json:"reclaimed_bytes"` // SizeBefore - SizeAfter
+	FreePages      int64     `json:"free_pages"`      // Unused pages before the vacuum
+	DurationMS     int64     `json:"duration_ms"`
+	FinishedAt     time.Time `json:"finished_at"`
+	Scheduled      bool      `json:"scheduled"` // Run by the periodic vacuum rather than on request
+}
+
+// DatabaseMaintenanceService compacts the SQLite database. Vacuums are serialized, and a
+// request that finds one running fails with ErrDatabaseBusy instead of queueing.
+type DatabaseMaintenanceService struct {
+	db     *gorm.DB
+	mu     sync.Mutex // held for the duration of a vacuum
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDatabaseMaintenanceService creates a new DatabaseMaintenanceService
+func NewDatabaseMaintenanceService(db *gorm.DB) *DatabaseMaintenanceService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DatabaseMaintenanceService{db: db, ctx: ctx, cancel: cancel}
+}
+
+// Close stops the periodic vacuum and waits for a running one to finish
+func (s *DatabaseMaintenanceService) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Vacuum rebuilds the database file with VACUUM, returning the space freed by deleted rows
+// to the file system, and reports the size before and after
+func (s *DatabaseMaintenanceService) Vacuum(ctx context.Context) (*VacuumResult, error) {
+	return s.vacuum(ctx, false)
+}
+
+func (s *DatabaseMaintenanceService) vacuum(ctx context.Context, scheduled bool) (*VacuumResult, error) {
+	if !s.mu.TryLock() {
+		return nil, fmt.Errorf("%w: a vacuum is already running", ErrDatabaseBusy)
+	}
+	defer s.mu.Unlock()
+
+	start := time.Now()
+	sizeBefore, err := s.databaseSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	freePages, err := s.pragmaInt(ctx, "freelist_count")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Exec("VACUUM").Error; err != nil {
+		if isSQLiteBusy(err) {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseBusy, err)
+		}
+		return nil, fmt.Errorf("vacuum failed: %w", err)
+	}
+
+	sizeAfter, err := s.databaseSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &VacuumResult{
+		SizeBefore:     sizeBefore,
+		SizeAfter:      sizeAfter,
+		ReclaimedBytes: sizeBefore - sizeAfter,
+		FreePages:      freePages,
+		DurationMS:     time.Since(start).Milliseconds(),
+		FinishedAt:     time.Now(),
+		Scheduled:      scheduled,
+	}
+	logging.FromContext(ctx, "DatabaseMaintenance").Printf("Vacuum finished in %dms: %d -> %d bytes (%d reclaimed)",
+		result.DurationMS, sizeBefore, sizeAfter, result.ReclaimedBytes)
+	return result, nil
+}
+
+// StartScheduledVacuum vacuums the database every interval. Runs are skipped when the
+// database has no free pages to reclaim or another vacuum is in progress, and retried at
+// the next tick when writers hold the database.
+func (s *DatabaseMaintenanceService) StartScheduledVacuum(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.scheduledVacuum()
+			}
+		}
+	}()
+}
+
+// scheduledVacuum runs one periodic vacuum when there is something to reclaim
+func (s *DatabaseMaintenanceService) scheduledVacuum() {
+	logger := logging.New("DatabaseMaintenance")
+	freePages, err := s.pragmaInt(s.ctx, "freelist_count")
+	if err != nil {
+		logger.Printf("Scheduled vacuum: failed to read free pages: %v", err)
+		return
+	}
+	if freePages == 0 {
+		return
+	}
+	if _, err := s.vacuum(s.ctx, true); err != nil {
+		if errors.Is(err, ErrDatabaseBusy) {
+			logger.Printf("Scheduled vacuum skipped: %v", err)
+			return
+		}
+		logger.Printf("Scheduled vacuum failed: %v", err)
+	}
+}
+
+// databaseSize returns the size of the database in bytes
+func (s *DatabaseMaintenanceService) databaseSize(ctx context.Context) (int64, error) {
+	pageCount, err := s.pragmaInt(ctx, "page_count")
+	if err != nil {
+		return 0, err
+	}
+	pageSize, err := s.pragmaInt(ctx, "page_size")
+	if err != nil {
+		return 0, err
+	}
+	return pageCount * pageSize, nil
+}
+
+// pragmaInt reads an integer PRAGMA
+func (s *DatabaseMaintenanceService) pragmaInt(ctx context.Context, name string) (int64, error) {
+	var value int64
+	if err := s.db.WithContext(ctx).Raw("PRAGMA " + name).Scan(&value).Error; err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// isSQLiteBusy reports whether err is SQLite refusing a lock held by another connection
+func isSQLiteBusy(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "sqlite_busy") ||
+		strings.Contains(msg, "database table is locked")
+}
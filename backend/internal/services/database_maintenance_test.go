@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupVacuumTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "vacuum.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.ContainerLog{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestDatabaseMaintenance_VacuumReclaimsSpace(t *testing.T) {
+	db := setupVacuumTestDB(t)
+	s := NewDatabaseMaintenanceService(db)
+	defer s.Close()
+
+	message := strings.Repeat("x", 4096)
+	logs := make([]models.ContainerLog, 200)
+	for i := range logs {
+		logs[i] = models.ContainerLog{ContainerID: 1, Level: models.LogLevelInfo, Stage: models.LogStageStartup, Message: message}
+	}
+	if err := db.CreateInBatches(logs, 50).Error; err != nil {
+		t.Fatalf("failed to insert logs: %v", err)
+	}
+	if err := db.Unscoped().Where("1 = 1").Delete(&models.ContainerLog{}).Error; err != nil {
+		t.Fatalf("failed to delete logs: %v", err)
+	}
+
+	result, err := s.Vacuum(context.Background())
+	if err != nil {
+		t.Fatalf("Vacuum failed: %v", err)
+	}
+	if result.FreePages == 0 {
+		t.Error("Expected free pages before the vacuum")
+	}
+	if result.SizeAfter >= result.SizeBefore || result.ReclaimedBytes != result.SizeBefore-result.SizeAfter {
+		t.Errorf("Expected the vacuum to shrink the database, got %+v", result)
+	}
+	if result.Scheduled || result.FinishedAt.IsZero() {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	// A second vacuum has nothing left to reclaim
+	result, err = s.Vacuum(context.Background())
+	if err != nil {
+		t.Fatalf("Vacuum failed: %v", err)
+	}
+	if result.FreePages != 0 || result.ReclaimedBytes != 0 {
+		t.Errorf("Expected nothing to reclaim, got %+v", result)
+	}
+}
+
+func TestDatabaseMaintenance_VacuumBusy(t *testing.T) {
+	s := NewDatabaseMaintenanceService(setupVacuumTestDB(t))
+	defer s.Close()
+
+	s.mu.Lock()
+	_, err := s.Vacuum(context.Background())
+	s.mu.Unlock()
+	if !errors.Is(err, ErrDatabaseBusy) {
+		t.Errorf("Expected ErrDatabaseBusy while a vacuum runs, got %v", err)
+	}
+
+	if _, err := s.Vacuum(context.Background()); err != nil {
+		t.Errorf("Expected the vacuum to run once the lock is free, got %v", err)
+	}
+}
+
+func TestIsSQLiteBusy(t *testing.T) {
+	if !isSQLiteBusy(errors.New("database is locked (5) (SQLITE_BUSY)")) {
+		t.Error("Expected a locked database to be busy")
+	}
+	if isSQLiteBusy(errors.New("no such table: foo")) {
+		t.Error("Expected other errors not to be busy")
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidDockerFilter is returned for an unknown Docker container list filter value
+var ErrInvalidDockerFilter = errors.New("invalid docker container filter")
+
+// dockerContainerStates are the states Docker reports for a container
+var dockerContainerStates = map[string]bool{
+	"created": true, "restarting": true, "running": true, "removing": true,
+	"paused": true, "exited": true, "dead": true,
+}
+
+// DockerContainerFilter narrows the Docker container list. Zero values match everything.
+type DockerContainerFilter struct {
+	Managed *bool  // Only platform-managed (true) or unmanaged (false) containers
+	State   string // Docker state, e.g. running or exited
+	Name    string // Case-insensitive name substring
+}
+
+// NewDockerContainerFilter validates raw query values into a filter
+func NewDockerContainerFilter(managed, state, name string) (DockerContainerFilter, error) {
+	filter := DockerContainerFilter{Name: strings.TrimSpace(name)}
+	switch strings.ToLower(strings.TrimSpace(managed)) {
+	case "":
+	case "true":
+		v := true
+		filter.Managed = &v
+	case "false":
+		v := false
+		filter.Managed = &v
+	default:
+		return filter, fmt.Errorf("%w: managed must be true or false", ErrInvalidDockerFilter)
+	}
+	if state = strings.ToLower(strings.TrimSpace(state)); state != "" {
+		if !dockerContainerStates[state] {
+			return filter, fmt.Errorf("%w: unknown state %q", ErrInvalidDockerFilter, state)
+		}
+		filter.State = state
+	}
+	return filter, nil
+}
+
+// Matches reports whether a container passes every set filter
+func (f DockerContainerFilter) Matches(c DockerContainerInfo) bool {
+	if f.Managed != nil && c.IsManaged != *f.Managed {
+		return false
+	}
+	if f.State != "" && !strings.EqualFold(c.State, f.State) {
+		return false
+	}
+	if f.Name != "" && !strings.Contains(strings.ToLower(c.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+	return true
+}
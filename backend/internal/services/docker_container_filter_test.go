@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func TestDockerContainerFilter(t *testing.T) {
+	containers := []DockerContainerInfo{
+		{Name: "web", State: "running", IsManaged: true},
+		{Name: "web-old", State: "exited", IsManaged: true},
+		{Name: "orphan-web", State: "exited", IsManaged: false},
+		{Name: "postgres", State: "running", IsManaged: false},
+	}
+
+	testCases := []struct {
+		managed, state, name string
+		want                 []string
+	}{
+		{"", "", "", []string{"web", "web-old", "orphan-web", "postgres"}},
+		{"true", "", "", []string{"web", "web-old"}},
+		{"false", "", "", []string{"orphan-web", "postgres"}},
+		{"", "running", "", []string{"web", "postgres"}},
+		{"", "", "WEB", []string{"web", "web-old", "orphan-web"}},
+		{"false", "exited", "", []string{"orphan-web"}},
+		{"true", "Exited", "old", []string{"web-old"}},
+		{"true", "running", "postgres", nil},
+	}
+
+	for _, tc := range testCases {
+		filter, err := NewDockerContainerFilter(tc.managed, tc.state, tc.name)
+		if err != nil {
+			t.Fatalf("managed=%q state=%q name=%q: unexpected error %v", tc.managed, tc.state, tc.name, err)
+		}
+		var got []string
+		for _, c := range containers {
+			if filter.Matches(c) {
+				got = append(got, c.Name)
+			}
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("managed=%q state=%q name=%q: expected %v, got %v", tc.managed, tc.state, tc.name, tc.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("managed=%q state=%q name=%q: expected %v, got %v", tc.managed, tc.state, tc.name, tc.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestDockerContainerFilter_Validation(t *testing.T) {
+	if _, err := NewDockerContainerFilter("yes", "", ""); !errors.Is(err, ErrInvalidDockerFilter) {
+		t.Errorf("Expected ErrInvalidDockerFilter for managed=yes, got %v", err)
+	}
+	if _, err := NewDockerContainerFilter("", "sleeping", ""); !errors.Is(err, ErrInvalidDockerFilter) {
+		t.Errorf("Expected ErrInvalidDockerFilter for an unknown state, got %v", err)
+	}
+}
+
+func TestManagedDockerIDs(t *testing.T) {
+	s := setupPauseTestService(t)
+	web := createPauseTestContainer(t, s, "web", models.ContainerStatusRunning)
+	s.db.Create(&models.Container{Name: "pending", Status: models.ContainerStatusCreated})
+
+	managed := s.managedDockerIDs()
+	if len(managed) != 1 || !managed[web.DockerID] {
+		t.Errorf("Expected only %s to be managed, got %v", web.DockerID, managed)
+	}
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cc-platform/internal/docker"
+)
+
+// ErrDockerUnavailable is returned by container operations that fail because the Docker
+// daemon cannot be reached. It matches docker.ErrDaemonUnavailable.
+var ErrDockerUnavailable = docker.ErrDaemonUnavailable
+
+// dockerError marks errors caused by an unreachable Docker daemon with ErrDockerUnavailable
+// and returns any other error unchanged
+func dockerError(err error) error {
+	if err == nil || errors.Is(err, ErrDockerUnavailable) || !docker.IsDaemonUnavailable(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrDockerUnavailable, err)
+}
+
+// DockerStatus checks the Docker daemon, reconnecting when it cannot be reached
+func (s *ContainerService) DockerStatus(ctx context.Context) docker.DaemonStatus {
+	return s.dockerClient.CheckDaemon(ctx)
+}
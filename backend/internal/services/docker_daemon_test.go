@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"cc-platform/internal/docker"
+	"cc-platform/internal/models"
+)
+
+// downDockerClient returns a Docker client pointed at an address nothing listens on
+func downDockerClient(t *testing.T) *docker.Client {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	t.Setenv("DOCKER_HOST", "tcp://"+addr)
+	t.Setenv("DOCKER_TLS_VERIFY", "")
+	t.Setenv("DOCKER_CERT_PATH", "")
+	client, err := docker.NewClient()
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestDockerError(t *testing.T) {
+	if dockerError(nil) != nil {
+		t.Error("Expected nil to stay nil")
+	}
+	other := errors.New("Error response from daemon: No such container")
+	if got := dockerError(other); got != other {
+		t.Errorf("Expected daemon errors to pass through, got %v", got)
+	}
+	down := fmt.Errorf("failed to inspect container: %w", errors.New("error during connect: dial unix /var/run/docker.sock: connect: no such file or directory"))
+	if got := dockerError(down); !errors.Is(got, ErrDockerUnavailable) {
+		t.Errorf("Expected ErrDockerUnavailable, got %v", got)
+	}
+}
+
+func TestContainerOperations_DockerUnavailable(t *testing.T) {
+	s := setupPauseTestService(t)
+	s.dockerClient = downDockerClient(t)
+	ctx := context.Background()
+
+	running := createPauseTestContainer(t, s, "running", models.ContainerStatusRunning)
+	if err := s.PauseContainer(ctx, running.ID); !errors.Is(err, ErrDockerUnavailable) {
+		t.Errorf("Expected pause to fail with ErrDockerUnavailable, got %v", err)
+	}
+	if _, err := s.ExecInContainer(ctx, running.ID, []string{"true"}); !errors.Is(err, ErrDockerUnavailable) {
+		t.Errorf("Expected exec to fail with ErrDockerUnavailable, got %v", err)
+	}
+
+	// The record is kept so the container can be deleted once the daemon is back
+	if err := s.DeleteContainer(ctx, running.ID); !errors.Is(err, ErrDockerUnavailable) {
+		t.Fatalf("Expected delete to fail with ErrDockerUnavailable, got %v", err)
+	}
+	if _, err := s.GetContainer(running.ID); err != nil {
+		t.Errorf("Expected the container record to be kept, got %v", err)
+	}
+
+	if status := s.DockerStatus(ctx); status.Available || status.Error == "" {
+		t.Errorf("Expected the daemon to be reported down, got %+v", status)
+	}
+}
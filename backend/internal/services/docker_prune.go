@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+// platformManagedLabel marks containers created by the platform
+const platformManagedLabel = "cc-platform.managed"
+
+// ErrPruneNotConfirmed is returned when a prune that removes containers doesn't set the confirm flag
+var ErrPruneNotConfirmed = errors.New("pruning orphaned containers removes them and must be confirmed")
+
+// orphanPruneClient is the subset of the docker client used to prune orphaned containers.
+// *docker.Client satisfies it; tests substitute an in-memory fake.
+type orphanPruneClient interface {
+	ListAllContainers(ctx context.Context) ([]types.Container, error)
+	StopContainer(ctx context.Context, containerID string, timeout *int) error
+	RemoveContainer(ctx context.Context, containerID string, force bool) error
+}
+
+// PrunedContainer is one orphaned container found by a prune
+type PrunedContainer struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	State   string `json:"state"`
+	Removed bool   `json:"removed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// PruneResult lists the orphaned containers a prune found and what happened to them
+type PruneResult struct {
+	DryRun     bool              `json:"dry_run"`
+	Containers []PrunedContainer `json:"containers"`
+}
+
+// PruneOrphanedContainers stops and removes Docker containers that carry the platform label but
+// have no database record. A dry run only reports them. Named workspace volumes are kept.
+func (s *ContainerService) PruneOrphanedContainers(ctx context.Context, confirm, dryRun bool) (*PruneResult, error) {
+	return s.pruneOrphanedContainers(ctx, s.dockerClient, confirm, dryRun)
+}
+
+func (s *ContainerService) pruneOrphanedContainers(ctx context.Context, client orphanPruneClient, confirm, dryRun bool) (*PruneResult, error) {
+	if !dryRun && !confirm {
+		return nil, ErrPruneNotConfirmed
+	}
+
+	containers, err := client.ListAllContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Docker containers: %w", err)
+	}
+	managedIDs := s.managedDockerIDs()
+
+	result := &PruneResult{DryRun: dryRun, Containers: []PrunedContainer{}}
+	for _, c := range containers {
+		if !isOrphanedPlatformContainer(c, managedIDs) {
+			continue
+		}
+		pruned := PrunedContainer{ID: c.ID[:min(12, len(c.ID))], Name: dockerContainerName(c), State: c.State}
+		if !dryRun {
+			if err := removeOrphanedContainer(ctx, client, c); err != nil {
+				pruned.Error = err.Error()
+			} else {
+				pruned.Removed = true
+			}
+		}
+		result.Containers = append(result.Containers, pruned)
+	}
+	return result, nil
+}
+
+// isOrphanedPlatformContainer reports whether a container was created by the platform but is
+// no longer tracked. Traefik and containers without the platform label are never candidates.
+func isOrphanedPlatformContainer(c types.Container, managedIDs map[string]bool) bool {
+	if c.Labels[platformManagedLabel] != "true" || managedIDs[c.ID] {
+		return false
+	}
+	name := dockerContainerName(c)
+	return name != TraefikContainerName && !strings.HasPrefix(c.Image, "traefik")
+}
+
+func removeOrphanedContainer(ctx context.Context, client orphanPruneClient, c types.Container) error {
+	if c.State == "running" || c.State == "paused" || c.State == "restarting" {
+		timeout := 10
+		if err := client.StopContainer(ctx, c.ID, &timeout); err != nil {
+			return fmt.Errorf("failed to stop: %w", err)
+		}
+	}
+	if err := client.RemoveContainer(ctx, c.ID, true); err != nil {
+		return fmt.Errorf("failed to remove: %w", err)
+	}
+	return nil
+}
+
+// dockerContainerName returns a container's name without the leading slash
+func dockerContainerName(c types.Container) string {
+	if len(c.Names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cc-platform/internal/models"
+
+	"github.com/docker/docker/api/types"
+)
+
+// fakePruneClient records stop and remove calls against a fixed container list
+type fakePruneClient struct {
+	containers []types.Container
+	stopped    []string
+	removed    []string
+	removeErr  map[string]error
+}
+
+func (f *fakePruneClient) ListAllContainers(ctx context.Context) ([]types.Container, error) {
+	return f.containers, nil
+}
+
+func (f *fakePruneClient) StopContainer(ctx context.Context, containerID string, timeout *int) error {
+	f.stopped = append(f.stopped, containerID)
+	return nil
+}
+
+func (f *fakePruneClient) RemoveContainer(ctx context.Context, containerID string, force bool) error {
+	if err := f.removeErr[containerID]; err != nil {
+		return err
+	}
+	f.removed = append(f.removed, containerID)
+	return nil
+}
+
+func pruneTestContainer(id, name, image, state string, platform bool) types.Container {
+	labels := map[string]string{}
+	if platform {
+		labels[platformManagedLabel] = "true"
+	}
+	return types.Container{ID: id, Names: []string{"/" + name}, Image: image, State: state, Labels: labels}
+}
+
+func newFakePruneClient(t *testing.T, s *ContainerService) *fakePruneClient {
+	t.Helper()
+	tracked := createPauseTestContainer(t, s, "web", models.ContainerStatusRunning)
+	return &fakePruneClient{containers: []types.Container{
+		pruneTestContainer(tracked.DockerID, "web", "cc-base:latest", "running", true),
+		pruneTestContainer("orphanrunning0001", "old-api", "cc-base:latest", "running", true),
+		pruneTestContainer("orphanexited00002", "old-docs", "cc-base:with-code-server", "exited", true),
+		pruneTestContainer("traefik000000003", TraefikContainerName, "traefik:latest", "running", true),
+		pruneTestContainer("postgres00000004", "postgres", "postgres:16", "running", false),
+		pruneTestContainer("ccnolabel0000005", "cc-lookalike", "cc-base:latest", "exited", false),
+	}}
+}
+
+func TestPruneOrphanedContainers_DryRun(t *testing.T) {
+	s := setupPauseTestService(t)
+	client := newFakePruneClient(t, s)
+
+	result, err := s.pruneOrphanedContainers(context.Background(), client, false, true)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if !result.DryRun || len(result.Containers) != 2 {
+		t.Fatalf("Expected 2 candidates in a dry run, got %+v", result)
+	}
+	if result.Containers[0].Name != "old-api" || result.Containers[1].Name != "old-docs" {
+		t.Errorf("Expected only the orphaned platform containers, got %+v", result.Containers)
+	}
+	if len(client.stopped) != 0 || len(client.removed) != 0 {
+		t.Errorf("Expected a dry run to touch nothing, stopped %v removed %v", client.stopped, client.removed)
+	}
+}
+
+func TestPruneOrphanedContainers_RemovesOnlyOrphans(t *testing.T) {
+	s := setupPauseTestService(t)
+	client := newFakePruneClient(t, s)
+
+	if _, err := s.pruneOrphanedContainers(context.Background(), client, false, false); !errors.Is(err, ErrPruneNotConfirmed) {
+		t.Fatalf("Expected ErrPruneNotConfirmed without confirm, got %v", err)
+	}
+
+	client.removeErr = map[string]error{"orphanexited00002": errors.New("in use")}
+	result, err := s.pruneOrphanedContainers(context.Background(), client, true, false)
+	if err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	if len(client.stopped) != 1 || client.stopped[0] != "orphanrunning0001" {
+		t.Errorf("Expected only the running orphan to be stopped, got %v", client.stopped)
+	}
+	if len(client.removed) != 1 || client.removed[0] != "orphanrunning0001" {
+		t.Errorf("Expected only orphans to be removed, got %v", client.removed)
+	}
+	if len(result.Containers) != 2 || !result.Containers[0].Removed || result.Containers[1].Removed || result.Containers[1].Error == "" {
+		t.Errorf("Expected one removal and one reported failure, got %+v", result.Containers)
+	}
+}
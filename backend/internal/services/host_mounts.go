@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cc-platform/internal/models"
+)
+
+var (
+	// ErrHostMountsDisabled is returned when host mounts are requested but not enabled in config
+	ErrHostMountsDisabled = errors.New("host mounts are disabled")
+	// ErrInvalidHostMount is returned when a host mount is malformed or outside the allowlist
+	ErrInvalidHostMount = errors.New("invalid host mount")
+)
+
+// reservedMountTargets are container paths managed by the platform that host mounts may not replace
+var reservedMountTargets = []string{
+	"/app",
+	"/workspace",
+	"/home/developer/.npm",
+	"/home/developer/.cache",
+	"/home/developer/.npm-global",
+	"/home/developer/.local/share/pnpm/store",
+}
+
+// HostMountInput requests a host directory to be mounted into a container.
+// Mounts are read-only unless ReadWrite is set.
+type HostMountInput struct {
+	HostPath      string `json:"host_path"`
+	ContainerPath string `json:"container_path"`
+	ReadWrite     bool   `json:"read_write,omitempty"`
+}
+
+// validateHostMounts checks requested mounts against the config flag and allowlist.
+// Host paths must be absolute, free of "..", and inside an allowlisted directory;
+// when a host path exists locally its symlinks are resolved and checked as well.
+func validateHostMounts(inputs []HostMountInput, enabled bool, allowlist []string) (models.HostMountList, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	if !enabled {
+		return nil, ErrHostMountsDisabled
+	}
+
+	mounts := make(models.HostMountList, 0, len(inputs))
+	targets := make(map[string]bool)
+	for _, input := range inputs {
+		hostPath, err := cleanMountPath(input.HostPath, "host_path")
+		if err != nil {
+			return nil, err
+		}
+		containerPath, err := cleanMountPath(input.ContainerPath, "container_path")
+		if err != nil {
+			return nil, err
+		}
+
+		if !isPathAllowed(hostPath, allowlist) {
+			return nil, fmt.Errorf("%w: %s is not in the host mount allowlist", ErrInvalidHostMount, hostPath)
+		}
+		if resolved, err := filepath.EvalSymlinks(hostPath); err == nil && resolved != hostPath {
+			if !isPathAllowed(resolved, resolvedAllowlist(allowlist)) {
+				return nil, fmt.Errorf("%w: %s resolves outside the host mount allowlist", ErrInvalidHostMount, hostPath)
+			}
+		}
+
+		if containerPath == "/" {
+			return nil, fmt.Errorf("%w: cannot mount over /", ErrInvalidHostMount)
+		}
+		for _, reserved := range reservedMountTargets {
+			if isWithin(containerPath, reserved) || isWithin(reserved, containerPath) {
+				return nil, fmt.Errorf("%w: %s conflicts with managed mount %s", ErrInvalidHostMount, containerPath, reserved)
+			}
+		}
+		if targets[containerPath] {
+			return nil, fmt.Errorf("%w: %s is mounted more than once", ErrInvalidHostMount, containerPath)
+		}
+		targets[containerPath] = true
+
+		mounts = append(mounts, models.HostMount{
+			HostPath:      hostPath,
+			ContainerPath: containerPath,
+			ReadOnly:      !input.ReadWrite,
+		})
+	}
+	return mounts, nil
+}
+
+// cleanMountPath validates an absolute mount path and returns it cleaned
+func cleanMountPath(p, field string) (string, error) {
+	if p == "" {
+		return "", fmt.Errorf("%w: %s is required", ErrInvalidHostMount, field)
+	}
+	if !strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("%w: %s must be absolute: %s", ErrInvalidHostMount, field, p)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("%w: %s must not contain '..': %s", ErrInvalidHostMount, field, p)
+		}
+	}
+	// ':' and ',' would break the Docker bind specification
+	if strings.ContainsAny(p, ":,") {
+		return "", fmt.Errorf("%w: %s must not contain ':' or ',': %s", ErrInvalidHostMount, field, p)
+	}
+	return path.Clean(p), nil
+}
+
+// isPathAllowed reports whether p is an allowlisted directory or inside one
+func isPathAllowed(p string, allowlist []string) bool {
+	for _, allowed := range allowlist {
+		if !strings.HasPrefix(allowed, "/") {
+			continue
+		}
+		if isWithin(p, path.Clean(allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvedAllowlist returns the allowlist with symlinks resolved where the directories exist
+func resolvedAllowlist(allowlist []string) []string {
+	resolved := make([]string, 0, len(allowlist))
+	for _, allowed := range allowlist {
+		if r, err := filepath.EvalSymlinks(allowed); err == nil {
+			resolved = append(resolved, r)
+		} else {
+			resolved = append(resolved, allowed)
+		}
+	}
+	return resolved
+}
+
+// isWithin reports whether p equals dir or is below it
+func isWithin(p, dir string) bool {
+	return p == dir || dir == "/" || strings.HasPrefix(p, dir+"/")
+}
+
+// hostMountBinds formats host mounts as Docker bind specifications
+func hostMountBinds(mounts models.HostMountList) []string {
+	binds := make([]string, 0, len(mounts))
+	for _, m := range mounts {
+		mode := "rw"
+		if m.ReadOnly {
+			mode = "ro"
+		}
+		binds = append(binds, fmt.Sprintf("%s:%s:%s", m.HostPath, m.ContainerPath, mode))
+	}
+	return binds
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateHostMounts_DisabledRejectsAnyMount(t *testing.T) {
+	inputs := []HostMountInput{{HostPath: "/srv/shared", ContainerPath: "/shared"}}
+	if _, err := validateHostMounts(inputs, false, []string{"/srv/shared"}); !errors.Is(err, ErrHostMountsDisabled) {
+		t.Errorf("Expected ErrHostMountsDisabled, got %v", err)
+	}
+
+	mounts, err := validateHostMounts(nil, false, nil)
+	if err != nil || mounts != nil {
+		t.Errorf("No mounts should always be valid, got %v, %v", mounts, err)
+	}
+}
+
+func TestValidateHostMounts_Allowlist(t *testing.T) {
+	allowlist := []string{"/srv/shared", "/data/datasets/"}
+
+	tests := []struct {
+		name  string
+		input HostMountInput
+		valid bool
+	}{
+		{"allowlisted dir", HostMountInput{HostPath: "/srv/shared", ContainerPath: "/shared"}, true},
+		{"subdirectory", HostMountInput{HostPath: "/data/datasets/imagenet", ContainerPath: "/datasets"}, true},
+		{"trailing slash is cleaned", HostMountInput{HostPath: "/srv/shared/", ContainerPath: "/shared/"}, true},
+		{"outside allowlist", HostMountInput{HostPath: "/etc", ContainerPath: "/host-etc"}, false},
+		{"sibling prefix", HostMountInput{HostPath: "/srv/shared-private", ContainerPath: "/shared"}, false},
+		{"dot-dot escape", HostMountInput{HostPath: "/srv/shared/../../etc", ContainerPath: "/shared"}, false},
+		{"dot-dot in container path", HostMountInput{HostPath: "/srv/shared", ContainerPath: "/tmp/../etc"}, false},
+		{"relative host path", HostMountInput{HostPath: "srv/shared", ContainerPath: "/shared"}, false},
+		{"bind separator", HostMountInput{HostPath: "/srv/shared:/etc", ContainerPath: "/shared"}, false},
+		{"container root", HostMountInput{HostPath: "/srv/shared", ContainerPath: "/"}, false},
+		{"managed app dir", HostMountInput{HostPath: "/srv/shared", ContainerPath: "/app/shared"}, false},
+		{"parent of managed mount", HostMountInput{HostPath: "/srv/shared", ContainerPath: "/home/developer"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateHostMounts([]HostMountInput{tt.input}, true, allowlist)
+			if tt.valid && err != nil {
+				t.Errorf("Expected valid mount, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidHostMount) {
+				t.Errorf("Expected ErrInvalidHostMount, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateHostMounts_ReadOnlyByDefault(t *testing.T) {
+	mounts, err := validateHostMounts([]HostMountInput{
+		{HostPath: "/srv/shared", ContainerPath: "/shared"},
+		{HostPath: "/srv/shared/out", ContainerPath: "/out", ReadWrite: true},
+	}, true, []string{"/srv/shared"})
+	if err != nil {
+		t.Fatalf("validateHostMounts failed: %v", err)
+	}
+	if !mounts[0].ReadOnly || mounts[1].ReadOnly {
+		t.Errorf("Expected read-only default with explicit read-write, got %+v", mounts)
+	}
+
+	binds := hostMountBinds(mounts)
+	if binds[0] != "/srv/shared:/shared:ro" || binds[1] != "/srv/shared/out:/out:rw" {
+		t.Errorf("Unexpected binds: %v", binds)
+	}
+}
+
+func TestValidateHostMounts_DuplicateTarget(t *testing.T) {
+	_, err := validateHostMounts([]HostMountInput{
+		{HostPath: "/srv/shared/a", ContainerPath: "/shared"},
+		{HostPath: "/srv/shared/b", ContainerPath: "/shared"},
+	}, true, []string{"/srv/shared"})
+	if !errors.Is(err, ErrInvalidHostMount) {
+		t.Errorf("Expected duplicate container path to be rejected, got %v", err)
+	}
+}
+
+func TestValidateHostMounts_SymlinkEscape(t *testing.T) {
+	root := t.TempDir()
+	allowed := filepath.Join(root, "allowed")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{allowed, outside} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(allowed, "escape")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	_, err := validateHostMounts([]HostMountInput{{HostPath: link, ContainerPath: "/escape"}}, true, []string{allowed})
+	if !errors.Is(err, ErrInvalidHostMount) {
+		t.Errorf("Expected symlink escaping the allowlist to be rejected, got %v", err)
+	}
+}
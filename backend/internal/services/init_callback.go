@@ -0,0 +1,282 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+)
+
+const (
+	// InitCallbackSignatureHeader carries "sha256=" + hex HMAC-SHA256 of the request body
+	InitCallbackSignatureHeader = "X-CC-Signature"
+	// InitCallbackEventHeader names the event that triggered the callback
+	InitCallbackEventHeader = "X-CC-Event"
+	// InitCallbackEvent is the event sent when container initialization finishes
+	InitCallbackEvent = "container.init"
+	// ContainerExpiringEvent is the event sent before a container is deleted by its TTL
+	ContainerExpiringEvent = "container.expiring"
+)
+
+var (
+	// ErrInvalidCallbackURL is returned when an init callback URL is not an absolute http(s) URL,
+	// or points at a private, loopback or link-local address that is not allowlisted
+	ErrInvalidCallbackURL = errors.New("invalid init callback URL")
+	// ErrWebhookSecretNotConfigured is returned for a callback URL when WEBHOOK_SECRET is not set,
+	// since receivers could not verify the signature
+	ErrWebhookSecretNotConfigured = errors.New("WEBHOOK_SECRET must be configured to use init callbacks")
+)
+
+// carrierGradeNAT is the shared address space (100.64.0.0/10), internal like the private ranges
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// InitCallbackPayload is the JSON body POSTed to a container's init callback URL
+type InitCallbackPayload struct {
+	Event       string                      `json:"event"`
+	ContainerID uint                        `json:"container_id"`
+	DockerID    string                      `json:"docker_id"`
+	Name        string                      `json:"name"`
+	Status      string                      `json:"status"` // ready | failed, or expiring for container.expiring
+	Message     string                      `json:"message,omitempty"`
+	Timings     *models.ContainerInitTiming `json:"timings,omitempty"`
+	ExpiresAt   *time.Time                  `json:"expires_at,omitempty"`
+	Timestamp   time.Time                   `json:"timestamp"`
+}
+
+// validateInitCallbackURL checks that a callback URL is an absolute http or https URL that
+// doesn't name an internal address. Hosts in allowedHosts may be internal. Hostnames are
+// checked again when the callback is sent, against the addresses they resolve to.
+func validateInitCallbackURL(raw string, allowedHosts []string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: %s", ErrInvalidCallbackURL, raw)
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if callbackHostAllowed(host, allowedHosts) {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s is a loopback host; add it to CALLBACK_ALLOWED_HOSTS to allow it", ErrInvalidCallbackURL, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && isInternalIP(ip) {
+		return fmt.Errorf("%w: %s is an internal address; add it to CALLBACK_ALLOWED_HOSTS to allow it", ErrInvalidCallbackURL, host)
+	}
+	return nil
+}
+
+// callbackHostAllowed reports whether host is in the callback allowlist
+func callbackHostAllowed(host string, allowedHosts []string) bool {
+	for _, allowed := range allowedHosts {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "."), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// isInternalIP reports whether ip is loopback, private, link-local (including cloud metadata
+// endpoints such as 169.254.169.254), multicast or unspecified
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || carrierGradeNAT.Contains(ip)
+}
+
+// callbackDialContext connects only to public addresses unless the host is allowlisted. The
+// host is resolved here and the checked address is dialed, so a DNS answer that changes
+// between validation and delivery can't redirect the callback to an internal service.
+func callbackDialContext(allowedHosts []string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if callbackHostAllowed(strings.ToLower(host), allowedHosts) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range addrs {
+			if isInternalIP(a.IP) {
+				return nil, fmt.Errorf("%w: %s resolves to internal address %s", ErrInvalidCallbackURL, host, a.IP)
+			}
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("no addresses found for %s", host)
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(addrs[0].IP.String(), port))
+	}
+}
+
+// signInitCallback returns the signature header value for body
+func signInitCallback(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// initCallbackSender delivers signed callback payloads with retries
+type initCallbackSender struct {
+	client      *http.Client
+	secret      string
+	maxAttempts int
+	retryDelay  time.Duration
+}
+
+func newInitCallbackSender(secret string, allowedHosts []string) *initCallbackSender {
+	return &initCallbackSender{
+		client: &http.Client{
+			Timeout:   constants.InitCallbackTimeout,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, DialContext: callbackDialContext(allowedHosts)},
+		},
+		secret:      secret,
+		maxAttempts: constants.InitCallbackMaxAttempts,
+		retryDelay:  constants.InitCallbackRetryDelay,
+	}
+}
+
+// deliver POSTs payload to callbackURL until it gets a 2xx response.
+// Network errors, 429 and 5xx responses are retried with exponential backoff; other statuses are final.
+// It returns the number of attempts made.
+func (d *initCallbackSender) deliver(ctx context.Context, callbackURL string, payload *InitCallbackPayload) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode callback payload: %w", err)
+	}
+	signature := signInitCallback(d.secret, body)
+
+	delay := d.retryDelay
+	var lastErr error
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return attempt - 1, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		retry, err := d.post(ctx, callbackURL, payload.Event, body, signature)
+		if err == nil {
+			return attempt, nil
+		}
+		lastErr = err
+		if !retry {
+			return attempt, err
+		}
+	}
+	return d.maxAttempts, lastErr
+}
+
+// post sends one delivery attempt and reports whether a failure is worth retrying
+func (d *initCallbackSender) post(ctx context.Context, callbackURL, event string, body []byte, signature string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(InitCallbackEventHeader, event)
+	req.Header.Set(InitCallbackSignatureHeader, signature)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("callback returned HTTP %d", resp.StatusCode)
+}
+
+// checkInitCallbackURL validates a container's callback URL against the server's callback
+// allowlist and makes sure callbacks can be signed
+func (s *ContainerService) checkInitCallbackURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	var secret string
+	var allowedHosts []string
+	if s.config != nil {
+		secret, allowedHosts = s.config.WebhookSecret, s.config.CallbackAllowedHosts
+	}
+	if err := validateInitCallbackURL(raw, allowedHosts); err != nil {
+		return err
+	}
+	if secret == "" {
+		return ErrWebhookSecretNotConfigured
+	}
+	return nil
+}
+
+// callbackSender returns a sender for the container's callbacks, or nil when WEBHOOK_SECRET is
+// not set, since receivers could not verify an unsigned callback
+func (s *ContainerService) callbackSender() *initCallbackSender {
+	if s.config == nil || s.config.WebhookSecret == "" {
+		return nil
+	}
+	return newInitCallbackSender(s.config.WebhookSecret, s.config.CallbackAllowedHosts)
+}
+
+// notifyInitCallback reports a finished initialization (ready or failed) to the container's
+// callback URL in the background. Delivery results are written to the container logs.
+func (s *ContainerService) notifyInitCallback(ctx context.Context, containerID uint) {
+	container, err := s.GetContainer(containerID)
+	if err != nil || container.InitCallbackURL == "" {
+		return
+	}
+
+	payload := &InitCallbackPayload{
+		Event:       InitCallbackEvent,
+		ContainerID: container.ID,
+		DockerID:    container.DockerID,
+		Name:        container.Name,
+		Status:      container.InitStatus,
+		Message:     container.InitMessage,
+		Timings:     container.InitTiming,
+		Timestamp:   time.Now(),
+	}
+
+	sender := s.callbackSender()
+	if sender == nil {
+		s.addLog(ctx, containerID, models.LogLevelWarn, models.LogStageInit, "Init callback skipped: WEBHOOK_SECRET is not configured")
+		return
+	}
+	callbackURL := container.InitCallbackURL
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		attempts, err := sender.deliver(s.ctx, callbackURL, payload)
+		if err != nil {
+			s.addLog(ctx, containerID, models.LogLevelWarn, models.LogStageInit,
+				fmt.Sprintf("Init callback to %s failed after %d attempt(s): %v", callbackURL, attempts, err))
+			s.logger(ctx, containerID).Printf("Init callback failed: %v", err)
+			return
+		}
+		s.addLog(ctx, containerID, models.LogLevelInfo, models.LogStageInit,
+			fmt.Sprintf("Init callback delivered to %s (%s)", callbackURL, payload.Status))
+	}()
+}
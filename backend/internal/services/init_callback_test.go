@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cc-platform/internal/config"
+	"cc-platform/internal/models"
+)
+
+func newTestCallbackSender(secret string) *initCallbackSender {
+	return &initCallbackSender{
+		client:      &http.Client{Timeout: time.Second},
+		secret:      secret,
+		maxAttempts: 3,
+		retryDelay:  time.Millisecond,
+	}
+}
+
+func TestValidateInitCallbackURL(t *testing.T) {
+	valid := []string{"", "https://ci.example.com/hooks/cc", "http://203.0.113.5:8080/cb"}
+	for _, u := range valid {
+		if err := validateInitCallbackURL(u, nil); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", u, err)
+		}
+	}
+
+	invalid := []string{
+		"ftp://example.com", "/relative/path", "https://", "not a url",
+		"http://10.0.0.5:8080/cb", "http://127.0.0.1/cb", "http://localhost:9000/cb", "http://[::1]/cb",
+		"http://169.254.169.254/latest/meta-data/", "http://[fe80::1]/cb", "http://0.0.0.0/cb",
+	}
+	for _, u := range invalid {
+		if err := validateInitCallbackURL(u, nil); !errors.Is(err, ErrInvalidCallbackURL) {
+			t.Errorf("Expected %q to be rejected, got %v", u, err)
+		}
+	}
+
+	// Allowlisted hosts may be internal
+	allowed := []string{"10.0.0.5", "LOCALHOST"}
+	for _, u := range []string{"http://10.0.0.5:8080/cb", "http://localhost:9000/cb"} {
+		if err := validateInitCallbackURL(u, allowed); err != nil {
+			t.Errorf("Expected allowlisted %q to be valid, got %v", u, err)
+		}
+	}
+}
+
+func TestCheckInitCallbackURL_RequiresWebhookSecret(t *testing.T) {
+	s := &ContainerService{config: &config.Config{}}
+	if err := s.checkInitCallbackURL("https://ci.example.com/hooks/cc"); !errors.Is(err, ErrWebhookSecretNotConfigured) {
+		t.Errorf("Expected ErrWebhookSecretNotConfigured, got %v", err)
+	}
+	if err := s.checkInitCallbackURL(""); err != nil {
+		t.Errorf("Expected no callback URL to need no secret, got %v", err)
+	}
+	if s.callbackSender() != nil {
+		t.Error("Expected no sender without a webhook secret")
+	}
+
+	s.config.WebhookSecret = "s3cret"
+	if err := s.checkInitCallbackURL("https://ci.example.com/hooks/cc"); err != nil {
+		t.Errorf("Expected a public callback URL to be accepted, got %v", err)
+	}
+}
+
+func TestCallbackDialContext_RefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	payload := &InitCallbackPayload{Event: InitCallbackEvent, Status: "ready", Timestamp: time.Now()}
+
+	// The test server listens on loopback, which only an allowlisted host may reach
+	sender := newInitCallbackSender("s3cret", nil)
+	sender.maxAttempts, sender.retryDelay = 1, time.Millisecond
+	if _, err := sender.deliver(context.Background(), server.URL, payload); !errors.Is(err, ErrInvalidCallbackURL) {
+		t.Errorf("Expected the loopback callback to be refused, got %v", err)
+	}
+
+	sender = newInitCallbackSender("s3cret", []string{"127.0.0.1"})
+	if _, err := sender.deliver(context.Background(), server.URL, payload); err != nil {
+		t.Errorf("Expected the allowlisted callback to be delivered, got %v", err)
+	}
+}
+
+func TestInitCallbackSender_SignsPayload(t *testing.T) {
+	var received InitCallbackPayload
+	var signature, event string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get(InitCallbackSignatureHeader)
+		event = r.Header.Get(InitCallbackEventHeader)
+		if signature != signInitCallback("s3cret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	timing := &models.ContainerInitTiming{}
+	timing.End(timing.Begin(models.InitStageClone), models.InitStageStatusSucceeded)
+	payload := &InitCallbackPayload{
+		Event:       InitCallbackEvent,
+		ContainerID: 7,
+		Name:        "demo",
+		Status:      models.InitStatusReady,
+		Timings:     timing,
+	}
+
+	attempts, err := newTestCallbackSender("s3cret").deliver(context.Background(), server.URL, payload)
+	if err != nil {
+		t.Fatalf("deliver failed: %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+	if event != InitCallbackEvent {
+		t.Errorf("Expected event header %q, got %q", InitCallbackEvent, event)
+	}
+	if received.ContainerID != 7 || received.Status != models.InitStatusReady {
+		t.Errorf("Unexpected payload: %+v", received)
+	}
+	if received.Timings == nil || len(received.Timings.Stages) != 1 {
+		t.Errorf("Expected timings in payload, got %+v", received.Timings)
+	}
+}
+
+func TestInitCallbackSender_RetriesServerErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	attempts, err := newTestCallbackSender("x").deliver(context.Background(), server.URL, &InitCallbackPayload{})
+	if err != nil {
+		t.Fatalf("Expected delivery to succeed on third attempt, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestInitCallbackSender_DoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := newTestCallbackSender("x").deliver(context.Background(), server.URL, &InitCallbackPayload{})
+	if err == nil {
+		t.Fatal("Expected delivery to fail on 404")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected a single attempt for 404, got %d", got)
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"cc-platform/internal/constants"
+)
+
+// Init console event types
+const (
+	// InitConsoleEventClaude carries one stream-json event from the init Claude process
+	InitConsoleEventClaude = "claude"
+	// InitConsoleEventOutput carries a line of output that is not a stream-json event
+	InitConsoleEventOutput = "output"
+	// InitConsoleEventDone is the last event of a console, sent when Claude exits
+	InitConsoleEventDone = "done"
+	// InitConsoleEventProgress carries the container's init progress percentage
+	InitConsoleEventProgress = "progress"
+)
+
+// InitConsoleEvent is a single event of a container's Claude init output
+type InitConsoleEvent struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"` // claude | output | progress | done
+	Data      json.RawMessage `json:"data,omitempty"`
+	Text      string          `json:"text,omitempty"`
+	ExitCode  *int            `json:"exit_code,omitempty"` // done only: Claude's exit code, if it ran
+	Error     string          `json:"error,omitempty"`     // done only: why Claude could not be run
+	Progress  *int            `json:"progress,omitempty"`  // progress only: init progress, 0-100
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// initConsole is the live output of one running Claude init
+type initConsole struct {
+	events      []InitConsoleEvent // most recent events, replayed to new subscribers
+	seq         int
+	result      string // text of the stream-json result event
+	subscribers map[chan InitConsoleEvent]struct{}
+}
+
+// InitConsoleHub forwards Claude init output to clients attached to the container's console.
+// Consoles exist only while init is running; afterwards clients fall back to container logs.
+type InitConsoleHub struct {
+	mu       sync.Mutex
+	consoles map[uint]*initConsole
+}
+
+// NewInitConsoleHub creates a hub with no running consoles
+func NewInitConsoleHub() *InitConsoleHub {
+	return &InitConsoleHub{consoles: make(map[uint]*initConsole)}
+}
+
+// start opens the console of a container, replacing any previous one
+func (h *InitConsoleHub) start(containerID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if old := h.consoles[containerID]; old != nil {
+		closeInitConsole(old)
+	}
+	h.consoles[containerID] = &initConsole{subscribers: make(map[chan InitConsoleEvent]struct{})}
+}
+
+// publishLine forwards one line of Claude output; stream-json lines are sent as-is
+func (h *InitConsoleHub) publishLine(containerID uint, line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+
+	evt := InitConsoleEvent{Type: InitConsoleEventOutput, Text: line}
+	var parsed struct {
+		Type   string          `json:"type"`
+		Result json.RawMessage `json:"result"`
+	}
+	if json.Unmarshal([]byte(line), &parsed) == nil && parsed.Type != "" {
+		evt = InitConsoleEvent{Type: InitConsoleEventClaude, Data: json.RawMessage(line)}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	console := h.consoles[containerID]
+	if console == nil {
+		return
+	}
+	if parsed.Type == "result" {
+		json.Unmarshal(parsed.Result, &console.result)
+	}
+	publishInitConsoleEvent(console, evt)
+}
+
+// publishProgress forwards a change of init progress while the container's console is open
+func (h *InitConsoleHub) publishProgress(containerID uint, percent int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if console := h.consoles[containerID]; console != nil {
+		publishInitConsoleEvent(console, InitConsoleEvent{Type: InitConsoleEventProgress, Progress: &percent})
+	}
+}
+
+// finish sends the done event, disconnects subscribers and returns the Claude result text
+func (h *InitConsoleHub) finish(containerID uint, exitCode int, initErr error) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	console := h.consoles[containerID]
+	if console == nil {
+		return ""
+	}
+	done := InitConsoleEvent{Type: InitConsoleEventDone}
+	if initErr != nil {
+		done.Error = initErr.Error()
+	} else {
+		done.ExitCode = &exitCode
+	}
+	publishInitConsoleEvent(console, done)
+	closeInitConsole(console)
+	delete(h.consoles, containerID)
+	return console.result
+}
+
+// Subscribe attaches to a container's running init console.
+// It returns the events sent so far and a channel of new events, closed after the done event.
+// ok is false when no init is running for the container.
+func (h *InitConsoleHub) Subscribe(containerID uint) (replay []InitConsoleEvent, events <-chan InitConsoleEvent, unsubscribe func(), ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	console := h.consoles[containerID]
+	if console == nil {
+		return nil, nil, func() {}, false
+	}
+
+	ch := make(chan InitConsoleEvent, constants.InitConsoleStreamBuffer)
+	console.subscribers[ch] = struct{}{}
+	replay = append([]InitConsoleEvent(nil), console.events...)
+
+	return replay, ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := console.subscribers[ch]; ok {
+			delete(console.subscribers, ch)
+			close(ch)
+		}
+	}, true
+}
+
+// publishInitConsoleEvent records an event and sends it to subscribers without blocking;
+// subscribers whose buffer is full miss the event. Callers hold the hub lock.
+func publishInitConsoleEvent(console *initConsole, evt InitConsoleEvent) {
+	console.seq++
+	evt.Seq = console.seq
+	evt.Timestamp = time.Now()
+
+	console.events = append(console.events, evt)
+	if len(console.events) > constants.InitConsoleReplayEvents {
+		console.events = console.events[len(console.events)-constants.InitConsoleReplayEvents:]
+	}
+
+	for ch := range console.subscribers {
+		select {
+		case ch <- evt:
+		default:
+		}
+	}
+}
+
+// closeInitConsole closes every subscriber channel. Callers hold the hub lock.
+func closeInitConsole(console *initConsole) {
+	for ch := range console.subscribers {
+		delete(console.subscribers, ch)
+		close(ch)
+	}
+}
+
+// SubscribeInitConsole attaches to the live Claude init output of a container; see InitConsoleHub.Subscribe
+func (s *ContainerService) SubscribeInitConsole(containerID uint) ([]InitConsoleEvent, <-chan InitConsoleEvent, func(), bool) {
+	if s.initConsole == nil {
+		return nil, nil, func() {}, false
+	}
+	return s.initConsole.Subscribe(containerID)
+}
+
+// initConsoleWriter splits Claude output into lines and publishes them to the console.
+// It also keeps the tail of the raw output so failures can be reported in the container logs.
+type initConsoleWriter struct {
+	hub         *InitConsoleHub
+	containerID uint
+	mu          sync.Mutex
+	pending     []byte
+	tail        []byte
+}
+
+func (w *initConsoleWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.tail = append(w.tail, p...)
+	if len(w.tail) > constants.InitConsoleMaxLogBytes {
+		w.tail = w.tail[len(w.tail)-constants.InitConsoleMaxLogBytes:]
+	}
+
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.hub.publishLine(w.containerID, string(w.pending[:i]))
+		w.pending = w.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush publishes a trailing line that has no newline
+func (w *initConsoleWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) > 0 {
+		w.hub.publishLine(w.containerID, string(w.pending))
+		w.pending = nil
+	}
+}
+
+// Tail returns the last constants.InitConsoleMaxLogBytes of raw output
+func (w *initConsoleWriter) Tail() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.tail)
+}
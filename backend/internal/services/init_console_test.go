@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"cc-platform/internal/constants"
+)
+
+func TestInitConsoleWriter_ForwardsLines(t *testing.T) {
+	hub := NewInitConsoleHub()
+	hub.start(1)
+	_, events, unsubscribe, ok := hub.Subscribe(1)
+	if !ok {
+		t.Fatal("Expected a running console")
+	}
+	defer unsubscribe()
+
+	w := &initConsoleWriter{hub: hub, containerID: 1}
+	// A stream-json line split across writes is forwarded once it is complete
+	w.Write([]byte(`{"type":"system","subtype":"in`))
+	w.Write([]byte("it\"}\nnpm WARN deprecated\n\n"))
+	w.Write([]byte(`{"type":"result","result":"Environment ready"}`))
+	w.flush()
+
+	want := []struct{ typ, content string }{
+		{InitConsoleEventClaude, `{"type":"system","subtype":"init"}`},
+		{InitConsoleEventOutput, "npm WARN deprecated"},
+		{InitConsoleEventClaude, `{"type":"result","result":"Environment ready"}`},
+	}
+	for i, exp := range want {
+		evt := <-events
+		if evt.Seq != i+1 || evt.Type != exp.typ {
+			t.Fatalf("Event %d: expected seq %d type %q, got %+v", i, i+1, exp.typ, evt)
+		}
+		content := evt.Text
+		if evt.Type == InitConsoleEventClaude {
+			content = string(evt.Data)
+		}
+		if content != exp.content {
+			t.Errorf("Event %d: expected %q, got %q", i, exp.content, content)
+		}
+	}
+
+	if result := hub.finish(1, 0, nil); result != "Environment ready" {
+		t.Errorf("Expected result text from the result event, got %q", result)
+	}
+	done := <-events
+	if done.Type != InitConsoleEventDone || done.ExitCode == nil || *done.ExitCode != 0 {
+		t.Errorf("Expected done event with exit code 0, got %+v", done)
+	}
+	if _, ok := <-events; ok {
+		t.Error("Expected the channel to be closed after done")
+	}
+}
+
+func TestInitConsoleHub_ReplayAndFinish(t *testing.T) {
+	hub := NewInitConsoleHub()
+
+	if _, _, _, ok := hub.Subscribe(7); ok {
+		t.Fatal("Expected no console before init starts")
+	}
+
+	hub.start(7)
+	for i := 0; i < constants.InitConsoleReplayEvents+5; i++ {
+		hub.publishLine(7, fmt.Sprintf("line %d", i))
+	}
+	// Output for other containers isn't mixed in
+	hub.publishLine(8, "other container")
+
+	replay, events, unsubscribe, ok := hub.Subscribe(7)
+	if !ok {
+		t.Fatal("Expected a running console")
+	}
+	if len(replay) != constants.InitConsoleReplayEvents {
+		t.Fatalf("Expected %d replayed events, got %d", constants.InitConsoleReplayEvents, len(replay))
+	}
+	if replay[0].Seq != 6 || replay[0].Text != "line 5" {
+		t.Errorf("Expected the oldest events to be dropped, first replayed is %+v", replay[0])
+	}
+
+	hub.finish(7, -1, errors.New("exec failed"))
+	done := <-events
+	if done.Type != InitConsoleEventDone || done.Error != "exec failed" || done.ExitCode != nil {
+		t.Errorf("Expected done event with the error, got %+v", done)
+	}
+	unsubscribe() // safe after the console closed the channel
+
+	if _, _, _, ok := hub.Subscribe(7); ok {
+		t.Error("Expected no console after init finished")
+	}
+}
+
+func TestInitConsoleHub_UnsubscribeStopsDelivery(t *testing.T) {
+	hub := NewInitConsoleHub()
+	hub.start(1)
+	_, events, unsubscribe, _ := hub.Subscribe(1)
+	unsubscribe()
+	unsubscribe()
+
+	hub.publishLine(1, "after unsubscribe")
+	if _, ok := <-events; ok {
+		t.Error("Expected the channel to be closed after unsubscribe")
+	}
+	hub.finish(1, 0, nil)
+}
+
+func TestInitConsoleWriter_TailIsBounded(t *testing.T) {
+	hub := NewInitConsoleHub()
+	w := &initConsoleWriter{hub: hub, containerID: 1}
+
+	chunk := make([]byte, 1024)
+	for i := range chunk {
+		chunk[i] = 'x'
+	}
+	for i := 0; i < 20; i++ {
+		w.Write(chunk)
+	}
+	w.Write([]byte("END"))
+
+	tail := w.Tail()
+	if len(tail) != constants.InitConsoleMaxLogBytes || tail[len(tail)-3:] != "END" {
+		t.Errorf("Expected the last %d bytes, got %d ending in %q", constants.InitConsoleMaxLogBytes, len(tail), tail[len(tail)-3:])
+	}
+}
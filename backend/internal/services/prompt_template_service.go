@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrPromptTemplateNotFound is returned when a prompt template does not exist
+	ErrPromptTemplateNotFound = errors.New("prompt template not found")
+	// ErrDuplicatePromptTemplate is returned when another prompt template has the same name
+	ErrDuplicatePromptTemplate = errors.New("a prompt template with this name already exists")
+	// ErrInvalidPromptTemplate is returned for an empty name or content, or invalid tags
+	ErrInvalidPromptTemplate = errors.New("invalid prompt template")
+	// ErrMissingTemplateVariable is returned when rendering without a value for every placeholder
+	ErrMissingTemplateVariable = errors.New("missing template variable")
+)
+
+// maxPromptTemplateTags is how many tags a prompt template may have
+const maxPromptTemplateTags = 20
+
+// templateVariablePattern matches {{VAR}} placeholders; whitespace inside the braces is allowed
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// TemplateVariables returns the placeholder names used in content, in order of first use
+func TemplateVariables(content string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range templateVariablePattern.FindAllStringSubmatch(content, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// RenderTemplate replaces every {{VAR}} placeholder with its value. A placeholder without
+// a value is an error naming all missing variables; unused values are ignored. Values are
+// inserted as is, so a value containing {{...}} is not expanded again.
+func RenderTemplate(content string, vars map[string]string) (string, error) {
+	var missing []string
+	for _, name := range TemplateVariables(content) {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingTemplateVariable, strings.Join(missing, ", "))
+	}
+	return templateVariablePattern.ReplaceAllStringFunc(content, func(placeholder string) string {
+		return vars[templateVariablePattern.FindStringSubmatch(placeholder)[1]]
+	}), nil
+}
+
+// PromptTemplateInput is the body for creating or replacing a prompt template
+type PromptTemplateInput struct {
+	Name        string   `json:"name"`
+	Content     string   `json:"content"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// PromptTemplateInfo is a prompt template with the variables its content uses
+type PromptTemplateInfo struct {
+	models.PromptTemplate
+	Variables []string `json:"variables"`
+}
+
+// PromptTemplateService manages the reusable headless prompt library
+type PromptTemplateService struct {
+	db *gorm.DB
+}
+
+// NewPromptTemplateService creates a new PromptTemplateService
+func NewPromptTemplateService(db *gorm.DB) *PromptTemplateService {
+	return &PromptTemplateService{db: db}
+}
+
+func promptTemplateInfo(t models.PromptTemplate) PromptTemplateInfo {
+	variables := TemplateVariables(t.Content)
+	if variables == nil {
+		variables = []string{}
+	}
+	return PromptTemplateInfo{PromptTemplate: t, Variables: variables}
+}
+
+// normalizePromptTemplateInput trims the name, checks the content and lowercases and de-duplicates tags
+func normalizePromptTemplateInput(input PromptTemplateInput) (PromptTemplateInput, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return input, fmt.Errorf("%w: name is required", ErrInvalidPromptTemplate)
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		return input, fmt.Errorf("%w: content is required", ErrInvalidPromptTemplate)
+	}
+	tags := normalizeTags(input.Tags)
+	if len(tags) > maxPromptTemplateTags {
+		return input, fmt.Errorf("%w: at most %d tags", ErrInvalidPromptTemplate, maxPromptTemplateTags)
+	}
+	input.Tags = tags
+	return input, nil
+}
+
+// normalizeTags lowercases and trims tags, dropping empty and duplicate ones
+func normalizeTags(input []string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range input {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// List returns the prompt templates sorted by name, optionally only those with the tag
+func (s *PromptTemplateService) List(tag string) ([]PromptTemplateInfo, error) {
+	var templates []models.PromptTemplate
+	if err := s.db.Order("name").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	infos := make([]PromptTemplateInfo, 0, len(templates))
+	for _, t := range templates {
+		if tag != "" && !slices.Contains(t.Tags, tag) {
+			continue
+		}
+		infos = append(infos, promptTemplateInfo(t))
+	}
+	return infos, nil
+}
+
+// GetByID returns a prompt template by ID
+func (s *PromptTemplateService) GetByID(id uint) (*PromptTemplateInfo, error) {
+	var t models.PromptTemplate
+	if err := s.db.First(&t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPromptTemplateNotFound
+		}
+		return nil, err
+	}
+	info := promptTemplateInfo(t)
+	return &info, nil
+}
+
+// Create adds a prompt template
+func (s *PromptTemplateService) Create(input PromptTemplateInput) (*PromptTemplateInfo, error) {
+	input, err := normalizePromptTemplateInput(input)
+	if err != nil {
+		return nil, err
+	}
+	t := models.PromptTemplate{Name: input.Name, Content: input.Content, Description: input.Description, Tags: input.Tags}
+	if err := s.db.Create(&t).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, fmt.Errorf("%w: '%s'", ErrDuplicatePromptTemplate, input.Name)
+		}
+		return nil, err
+	}
+	info := promptTemplateInfo(t)
+	return &info, nil
+}
+
+// Update replaces a prompt template's name, content, description and tags
+func (s *PromptTemplateService) Update(id uint, input PromptTemplateInput) (*PromptTemplateInfo, error) {
+	input, err := normalizePromptTemplateInput(input)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetByID(id); err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{
+		"name":        input.Name,
+		"content":     input.Content,
+		"description": input.Description,
+		"tags":        models.HeadlessTagList(input.Tags),
+	}
+	if err := s.db.Model(&models.PromptTemplate{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, fmt.Errorf("%w: '%s'", ErrDuplicatePromptTemplate, input.Name)
+		}
+		return nil, err
+	}
+	return s.GetByID(id)
+}
+
+// Delete removes a prompt template
+func (s *PromptTemplateService) Delete(id uint) error {
+	result := s.db.Delete(&models.PromptTemplate{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPromptTemplateNotFound
+	}
+	return nil
+}
+
+// Render renders a prompt template with the given variable values
+func (s *PromptTemplateService) Render(id uint, vars map[string]string) (string, error) {
+	t, err := s.GetByID(id)
+	if err != nil {
+		return "", err
+	}
+	return RenderTemplate(t.Content, vars)
+}
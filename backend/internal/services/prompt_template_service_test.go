@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var promptTemplateTestDBCounter int
+
+func setupPromptTemplateTestService(t *testing.T) *PromptTemplateService {
+	t.Helper()
+
+	promptTemplateTestDBCounter++
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:prompttpldb%d?mode=memory&cache=shared", promptTemplateTestDBCounter)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.PromptTemplate{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return NewPromptTemplateService(db)
+}
+
+func TestRenderTemplate_SubstitutesVariables(t *testing.T) {
+	content := "Review {{FILE}} for {{ issue }}; then fix {{FILE}}."
+	got, err := RenderTemplate(content, map[string]string{
+		"FILE":   "main.go",
+		"issue":  "{{FILE}} leaks",
+		"unused": "x",
+	})
+	if err != nil {
+		t.Fatalf("RenderTemplate returned error: %v", err)
+	}
+	want := "Review main.go for {{FILE}} leaks; then fix main.go."
+	if got != want {
+		t.Errorf("RenderTemplate = %q, want %q", got, want)
+	}
+
+	if vars := TemplateVariables(content); fmt.Sprint(vars) != "[FILE issue]" {
+		t.Errorf("TemplateVariables = %v, want [FILE issue]", vars)
+	}
+}
+
+func TestRenderTemplate_MissingVariables(t *testing.T) {
+	_, err := RenderTemplate("{{B}} and {{A}} and {{C}}", map[string]string{"C": ""})
+	if !errors.Is(err, ErrMissingTemplateVariable) {
+		t.Fatalf("Expected ErrMissingTemplateVariable, got %v", err)
+	}
+	if !strings.HasSuffix(err.Error(), ": B, A") {
+		t.Errorf("Expected error to name B and A in order of use, got %q", err.Error())
+	}
+
+	// An empty value counts as provided
+	if got, err := RenderTemplate("[{{C}}]", map[string]string{"C": ""}); err != nil || got != "[]" {
+		t.Errorf("RenderTemplate with empty value = %q, %v", got, err)
+	}
+}
+
+func TestPromptTemplateService_CRUD(t *testing.T) {
+	service := setupPromptTemplateTestService(t)
+
+	created, err := service.Create(PromptTemplateInput{
+		Name:    "  review  ",
+		Content: "Review {{PATH}}",
+		Tags:    []string{"Code", " code ", "", "review"},
+	})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if created.Name != "review" {
+		t.Errorf("Expected trimmed name 'review', got %q", created.Name)
+	}
+	if fmt.Sprint([]string(created.Tags)) != "[code review]" {
+		t.Errorf("Expected tags [code review], got %v", created.Tags)
+	}
+	if fmt.Sprint(created.Variables) != "[PATH]" {
+		t.Errorf("Expected variables [PATH], got %v", created.Variables)
+	}
+
+	if _, err := service.Create(PromptTemplateInput{Name: "review", Content: "x"}); !errors.Is(err, ErrDuplicatePromptTemplate) {
+		t.Errorf("Expected ErrDuplicatePromptTemplate, got %v", err)
+	}
+	if _, err := service.Create(PromptTemplateInput{Name: "empty", Content: "  "}); !errors.Is(err, ErrInvalidPromptTemplate) {
+		t.Errorf("Expected ErrInvalidPromptTemplate, got %v", err)
+	}
+	if _, err := service.Create(PromptTemplateInput{Name: "tests", Content: "Add tests"}); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	tagged, err := service.List("CODE")
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(tagged) != 1 || tagged[0].ID != created.ID {
+		t.Errorf("Expected only 'review' to have tag code, got %+v", tagged)
+	}
+
+	updated, err := service.Update(created.ID, PromptTemplateInput{Name: "review", Content: "Review {{PATH}} for {{FOCUS}}"})
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if len(updated.Tags) != 0 || fmt.Sprint(updated.Variables) != "[PATH FOCUS]" {
+		t.Errorf("Unexpected update result: tags %v, variables %v", updated.Tags, updated.Variables)
+	}
+
+	prompt, err := service.Render(created.ID, map[string]string{"PATH": "api/", "FOCUS": "errors"})
+	if err != nil || prompt != "Review api/ for errors" {
+		t.Errorf("Render = %q, %v", prompt, err)
+	}
+	if _, err := service.Render(created.ID, map[string]string{"PATH": "api/"}); !errors.Is(err, ErrMissingTemplateVariable) {
+		t.Errorf("Expected ErrMissingTemplateVariable, got %v", err)
+	}
+
+	if err := service.Delete(created.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := service.GetByID(created.ID); !errors.Is(err, ErrPromptTemplateNotFound) {
+		t.Errorf("Expected ErrPromptTemplateNotFound after delete, got %v", err)
+	}
+	if err := service.Delete(created.ID); !errors.Is(err, ErrPromptTemplateNotFound) {
+		t.Errorf("Expected ErrPromptTemplateNotFound deleting twice, got %v", err)
+	}
+	// The name is free again once deleted
+	if _, err := service.Create(PromptTemplateInput{Name: "review", Content: "again"}); err != nil {
+		t.Errorf("Create after delete returned error: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cc-platform/internal/docker"
+	"cc-platform/internal/models"
+	"cc-platform/pkg/crypto"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRegistryNotAllowed is returned for a registry missing from REGISTRY_ALLOWLIST
+	ErrRegistryNotAllowed = errors.New("registry is not allowlisted")
+	// ErrRegistryCredentialNotFound is returned for an unknown registry credential
+	ErrRegistryCredentialNotFound = errors.New("registry credential not found")
+	// ErrInvalidRegistryCredential is returned for a credential without a registry, username or password
+	ErrInvalidRegistryCredential = errors.New("invalid registry credential")
+)
+
+// RegistryCredentialResponse represents a registry credential without the password
+type RegistryCredentialResponse struct {
+	ID        uint   `json:"id"`
+	Registry  string `json:"registry"`
+	Username  string `json:"username"`
+	Remark    string `json:"remark,omitempty"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// SaveRegistryCredentialInput represents input for storing a registry login.
+// Saving a registry that already has a credential replaces it.
+type SaveRegistryCredentialInput struct {
+	Registry string `json:"registry" binding:"required"`
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Remark   string `json:"remark"`
+}
+
+// normalizeRegistryHost lowercases a registry host and drops a scheme or trailing slash
+func normalizeRegistryHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	return strings.TrimSuffix(host, "/")
+}
+
+// checkRegistryAllowed reports ErrRegistryNotAllowed unless host is on the allowlist
+func checkRegistryAllowed(host string, allowlist []string) error {
+	host = normalizeRegistryHost(host)
+	for _, allowed := range allowlist {
+		if normalizeRegistryHost(allowed) == host {
+			return nil
+		}
+	}
+	if len(allowlist) == 0 {
+		return fmt.Errorf("%w: %s (REGISTRY_ALLOWLIST is empty, so custom images are disabled)", ErrRegistryNotAllowed, host)
+	}
+	return fmt.Errorf("%w: %s", ErrRegistryNotAllowed, host)
+}
+
+func (s *ConfigProfileService) registryAllowlist() []string {
+	if s.config == nil {
+		return nil
+	}
+	return s.config.RegistryAllowlist
+}
+
+func toRegistryCredentialResponse(c *models.RegistryCredential) RegistryCredentialResponse {
+	return RegistryCredentialResponse{
+		ID:        c.ID,
+		Registry:  c.Registry,
+		Username:  c.Username,
+		Remark:    c.Remark,
+		CreatedAt: c.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: c.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// ListRegistryCredentials returns all registry credentials (without passwords)
+func (s *ConfigProfileService) ListRegistryCredentials() ([]RegistryCredentialResponse, error) {
+	var creds []models.RegistryCredential
+	if err := s.db.Order("registry").Find(&creds).Error; err != nil {
+		return nil, err
+	}
+	responses := make([]RegistryCredentialResponse, len(creds))
+	for i := range creds {
+		responses[i] = toRegistryCredentialResponse(&creds[i])
+	}
+	return responses, nil
+}
+
+// SaveRegistryCredential stores the login for an allowlisted registry, replacing an existing one
+func (s *ConfigProfileService) SaveRegistryCredential(input SaveRegistryCredentialInput) (*RegistryCredentialResponse, error) {
+	registry := normalizeRegistryHost(input.Registry)
+	username := strings.TrimSpace(input.Username)
+	if registry == "" || username == "" || input.Password == "" {
+		return nil, fmt.Errorf("%w: registry, username and password are required", ErrInvalidRegistryCredential)
+	}
+	if strings.Contains(registry, "/") {
+		return nil, fmt.Errorf("%w: registry must be a host, not %q", ErrInvalidRegistryCredential, input.Registry)
+	}
+	if err := checkRegistryAllowed(registry, s.registryAllowlist()); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := crypto.Encrypt(input.Password, []byte(s.config.EncryptionKey))
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows soft-deleted before deletes became permanent still hold the registry; restore them
+	var cred models.RegistryCredential
+	err = s.db.Unscoped().Where("registry = ?", registry).First(&cred).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		cred = models.RegistryCredential{Registry: registry, Username: username, Password: encrypted, Remark: input.Remark}
+		err = s.db.Create(&cred).Error
+	case err == nil:
+		err = s.db.Unscoped().Model(&cred).Updates(map[string]interface{}{
+			"username":   username,
+			"password":   encrypted,
+			"remark":     input.Remark,
+			"deleted_at": nil,
+		}).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	response := toRegistryCredentialResponse(&cred)
+	return &response, nil
+}
+
+// DeleteRegistryCredential deletes a registry credential. The row is removed for good, since
+// a soft-deleted row would keep its registry in the unique index and block saving it again.
+func (s *ConfigProfileService) DeleteRegistryCredential(id uint) error {
+	result := s.db.Unscoped().Delete(&models.RegistryCredential{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRegistryCredentialNotFound
+	}
+	return nil
+}
+
+// RegistryAuthForImage returns the X-Registry-Auth value for pulling image, or "" when its
+// registry has no stored credential
+func (s *ConfigProfileService) RegistryAuthForImage(image string) (string, error) {
+	registry, err := docker.ImageRegistry(image)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	var cred models.RegistryCredential
+	if err := s.db.Where("registry = ?", registry).First(&cred).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	password, err := crypto.Decrypt(cred.Password, []byte(s.config.EncryptionKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt credential for registry %s: %w", registry, err)
+	}
+	return docker.RegistryAuth(registry, cred.Username, password)
+}
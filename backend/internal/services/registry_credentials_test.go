@@ -0,0 +1,111 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/config"
+	"cc-platform/internal/models"
+)
+
+func TestNormalizeContainerImage(t *testing.T) {
+	allowlist := []string{"registry.example.com:5000", "GHCR.io"}
+
+	for _, image := range []string{"", "  "} {
+		if got, err := normalizeContainerImage(image, nil); err != nil || got != "" {
+			t.Errorf("normalizeContainerImage(%q) = %q, %v; want the base image", image, got, err)
+		}
+	}
+	for _, image := range []string{"registry.example.com:5000/team/dev:1.2", " ghcr.io/org/dev "} {
+		if _, err := normalizeContainerImage(image, allowlist); err != nil {
+			t.Errorf("Expected %q to be allowed, got %v", image, err)
+		}
+	}
+	if _, err := normalizeContainerImage("ubuntu:22.04", allowlist); !errors.Is(err, ErrRegistryNotAllowed) {
+		t.Errorf("Expected docker.io to need allowlisting, got %v", err)
+	}
+	if _, err := normalizeContainerImage("ghcr.io/org/dev", nil); !errors.Is(err, ErrRegistryNotAllowed) {
+		t.Errorf("Expected custom images to be refused without an allowlist, got %v", err)
+	}
+	if _, err := normalizeContainerImage("ghcr.io/org/dev image", allowlist); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("Expected ErrInvalidImage, got %v", err)
+	}
+}
+
+func TestRegistryCredentials_StoredEncryptedAndUsedForPulls(t *testing.T) {
+	db := setupPauseTestService(t).db
+	if err := db.AutoMigrate(&models.RegistryCredential{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	s := NewConfigProfileService(db, &config.Config{
+		EncryptionKey:     "test-encryption-key-32-bytes-ok!",
+		RegistryAllowlist: []string{"registry.example.com"},
+	})
+
+	_, err := s.SaveRegistryCredential(SaveRegistryCredentialInput{Registry: "other.example.com", Username: "u", Password: "p"})
+	if !errors.Is(err, ErrRegistryNotAllowed) {
+		t.Fatalf("Expected ErrRegistryNotAllowed, got %v", err)
+	}
+
+	if _, err := s.SaveRegistryCredential(SaveRegistryCredentialInput{Registry: "https://Registry.example.com/", Username: "old", Password: "old-pass"}); err != nil {
+		t.Fatalf("SaveRegistryCredential failed: %v", err)
+	}
+	// Saving the same registry again replaces the login
+	cred, err := s.SaveRegistryCredential(SaveRegistryCredentialInput{Registry: "registry.example.com", Username: "deploy", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("SaveRegistryCredential failed: %v", err)
+	}
+	if cred.Registry != "registry.example.com" || cred.Username != "deploy" {
+		t.Errorf("Unexpected credential: %+v", cred)
+	}
+
+	var stored []models.RegistryCredential
+	db.Find(&stored)
+	if len(stored) != 1 || stored[0].Password == "" || strings.Contains(stored[0].Password, "hunter2") {
+		t.Fatalf("Expected one credential with an encrypted password, got %+v", stored)
+	}
+	if data, _ := json.Marshal(stored[0]); strings.Contains(string(data), stored[0].Password) {
+		t.Error("Expected the password to be left out of JSON")
+	}
+
+	header, err := s.RegistryAuthForImage("registry.example.com/team/dev:latest")
+	if err != nil {
+		t.Fatalf("RegistryAuthForImage failed: %v", err)
+	}
+	data, err := base64.URLEncoding.DecodeString(header)
+	if err != nil {
+		t.Fatalf("Expected a base64 auth header: %v", err)
+	}
+	var auth map[string]string
+	json.Unmarshal(data, &auth)
+	if auth["username"] != "deploy" || auth["password"] != "hunter2" || auth["serveraddress"] != "registry.example.com" {
+		t.Errorf("Unexpected auth header contents: %v", auth)
+	}
+
+	if header, err := s.RegistryAuthForImage("ubuntu:22.04"); err != nil || header != "" {
+		t.Errorf("Expected an anonymous pull for a registry without credentials, got %q, %v", header, err)
+	}
+
+	if err := s.DeleteRegistryCredential(cred.ID); err != nil {
+		t.Fatalf("DeleteRegistryCredential failed: %v", err)
+	}
+	if err := s.DeleteRegistryCredential(cred.ID); !errors.Is(err, ErrRegistryCredentialNotFound) {
+		t.Errorf("Expected ErrRegistryCredentialNotFound, got %v", err)
+	}
+
+	// A deleted registry can be saved again, including one soft-deleted by an older version
+	cred, err = s.SaveRegistryCredential(SaveRegistryCredentialInput{Registry: "registry.example.com", Username: "deploy", Password: "hunter3"})
+	if err != nil {
+		t.Fatalf("Expected a deleted registry to be saved again, got %v", err)
+	}
+	db.Delete(&models.RegistryCredential{}, cred.ID)
+	if _, err := s.SaveRegistryCredential(SaveRegistryCredentialInput{Registry: "registry.example.com", Username: "ci", Password: "hunter4"}); err != nil {
+		t.Fatalf("Expected a soft-deleted registry to be restored, got %v", err)
+	}
+	if list, _ := s.ListRegistryCredentials(); len(list) != 1 || list[0].Username != "ci" {
+		t.Errorf("Expected the restored credential to be listed, got %+v", list)
+	}
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RepoCacheMountPath is where a container's repository cache is mounted, read-only
+const RepoCacheMountPath = "/repo-cache"
+
+// repoCacheTokenIDSuffix names the file next to a cached repository that records which GitHub
+// token profile filled it. It stays outside the repository, so containers never see it.
+const repoCacheTokenIDSuffix = ".token-id"
+
+// repoCacheFetchRefspecs keep the bare cache in step with the remote's branches and tags
+var repoCacheFetchRefspecs = []string{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"}
+
+// RepoCache keeps bare clones of repositories on the server so containers can clone them
+// locally instead of fetching from the remote every time. Credentials are only passed to
+// git for the server-side clone and fetch; the cached repositories store the plain URL.
+type RepoCache struct {
+	dir             string
+	refreshInterval time.Duration
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex // per-repository locks, keyed by cache name
+}
+
+// NewRepoCache creates a cache rooted at dir. An empty dir disables the cache. Cached
+// repositories are bind-mounted into containers, so it must be the same path on the Docker host.
+func NewRepoCache(dir string, refreshInterval time.Duration) *RepoCache {
+	if dir != "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+	}
+	return &RepoCache{
+		dir:             dir,
+		refreshInterval: refreshInterval,
+		locks:           make(map[string]*sync.Mutex),
+	}
+}
+
+// Enabled reports whether a cache directory is configured
+func (c *RepoCache) Enabled() bool {
+	return c != nil && c.dir != ""
+}
+
+// Dir returns the cache directory on the host
+func (c *RepoCache) Dir() string {
+	return c.dir
+}
+
+// repoCacheName returns the cache directory name for a repository URL. The hash keeps URLs
+// that share a repository name apart; credentials and a trailing .git don't change it.
+func repoCacheName(repoURL string) string {
+	normalized := strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(stripURLCredentials(strings.TrimSpace(repoURL)), "/"), ".git"))
+	sum := sha256.Sum256([]byte(normalized))
+	name := extractRepoName(normalized)
+	if !repoDirPattern.MatchString(name) {
+		name = "repo"
+	}
+	return fmt.Sprintf("%s-%s.git", name, hex.EncodeToString(sum[:6]))
+}
+
+// stripURLCredentials removes any user info from an https URL
+func stripURLCredentials(repoURL string) string {
+	rest, ok := strings.CutPrefix(repoURL, "https://")
+	if !ok {
+		return repoURL
+	}
+	host, path, _ := strings.Cut(rest, "/")
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	if path == "" {
+		return "https://" + host
+	}
+	return "https://" + host + "/" + path
+}
+
+// ContainerPath returns where a repository's cache is found inside a container
+func (c *RepoCache) ContainerPath(repoURL string) string {
+	return RepoCacheMountPath + "/" + repoCacheName(repoURL)
+}
+
+// MountSource returns the host directory of a repository's cache, creating it when missing so
+// it can be bind-mounted before the repository is first cloned. Only this directory is mounted
+// into the container, so other repositories in the cache stay out of its reach.
+func (c *RepoCache) MountSource(repoURL string) (string, error) {
+	if !c.Enabled() {
+		return "", fmt.Errorf("repository cache is disabled")
+	}
+	path := filepath.Join(c.dir, repoCacheName(repoURL))
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", fmt.Errorf("failed to create repository cache: %w", err)
+	}
+	return path, nil
+}
+
+// Ensure makes sure a fresh bare clone of repoURL is in the cache: it clones on a miss and
+// fetches when the last refresh is older than the refresh interval. The token authenticates
+// https remotes and is never written to the cached repository; tokenID, the GitHub token
+// profile it came from (nil for the default), is recorded so RefreshAll fetches with it too.
+func (c *RepoCache) Ensure(ctx context.Context, repoURL, token string, tokenID *uint) error {
+	if !c.Enabled() {
+		return fmt.Errorf("repository cache is disabled")
+	}
+	name := repoCacheName(repoURL)
+	lock := c.repoLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	path := filepath.Join(c.dir, name)
+	if _, err := os.Stat(filepath.Join(path, "HEAD")); err != nil {
+		if err := c.clone(ctx, repoURL, token, path); err != nil {
+			return err
+		}
+	} else if !c.isFresh(path) {
+		if err := c.fetch(ctx, path, token); err != nil {
+			return err
+		}
+	}
+	c.writeTokenID(path, tokenID)
+	return nil
+}
+
+// RefreshAll fetches every cached repository whose last refresh is older than the interval.
+// tokenFor resolves the token of the GitHub token profile recorded for each repository.
+func (c *RepoCache) RefreshAll(ctx context.Context, tokenFor func(tokenID *uint) (string, error)) []error {
+	if !c.Enabled() {
+		return nil
+	}
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return []error{err}
+	}
+	var errs []error
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), ".git") {
+			continue
+		}
+		lock := c.repoLock(entry.Name())
+		lock.Lock()
+		if err := c.refresh(ctx, filepath.Join(c.dir, entry.Name()), tokenFor); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+		}
+		lock.Unlock()
+	}
+	return errs
+}
+
+// refresh fetches a stale cached repository with its recorded token. Mount sources that were
+// never filled are skipped.
+func (c *RepoCache) refresh(ctx context.Context, path string, tokenFor func(tokenID *uint) (string, error)) error {
+	if _, err := os.Stat(filepath.Join(path, "HEAD")); err != nil || c.isFresh(path) {
+		return nil
+	}
+	token, err := tokenFor(c.readTokenID(path))
+	if err != nil {
+		return fmt.Errorf("failed to get GitHub token: %w", err)
+	}
+	return c.fetch(ctx, path, token)
+}
+
+// writeTokenID records the GitHub token profile of a cached repository; nil is the default
+func (c *RepoCache) writeTokenID(path string, tokenID *uint) {
+	file := path + repoCacheTokenIDSuffix
+	if tokenID == nil {
+		os.Remove(file)
+		return
+	}
+	os.WriteFile(file, []byte(strconv.FormatUint(uint64(*tokenID), 10)), 0600)
+}
+
+// readTokenID returns the GitHub token profile recorded for a cached repository
+func (c *RepoCache) readTokenID(path string) *uint {
+	data, err := os.ReadFile(path + repoCacheTokenIDSuffix)
+	if err != nil {
+		return nil
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 0)
+	if err != nil {
+		return nil
+	}
+	tokenID := uint(id)
+	return &tokenID
+}
+
+func (c *RepoCache) repoLock(name string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lock, ok := c.locks[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.locks[name] = lock
+	}
+	return lock
+}
+
+// isFresh reports whether the cached repository was refreshed within the interval.
+// The refresh time is kept as the modification time of the cache's refresh marker.
+func (c *RepoCache) isFresh(path string) bool {
+	info, err := os.Stat(filepath.Join(path, "cc-refreshed"))
+	if err != nil {
+		return false
+	}
+	return c.refreshInterval > 0 && time.Since(info.ModTime()) < c.refreshInterval
+}
+
+func (c *RepoCache) markRefreshed(path string) {
+	marker := filepath.Join(path, "cc-refreshed")
+	now := time.Now()
+	if err := os.Chtimes(marker, now, now); err != nil {
+		os.WriteFile(marker, nil, 0644)
+	}
+}
+
+func (c *RepoCache) clone(ctx context.Context, repoURL, token, path string) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create repository cache: %w", err)
+	}
+	// Clone into a temporary directory so a failed clone never looks like a cache hit
+	tmp, err := os.MkdirTemp(c.dir, ".clone-")
+	if err != nil {
+		return fmt.Errorf("failed to create repository cache: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	plainURL := stripURLCredentials(repoURL)
+	if err := runGit(ctx, token, plainURL, "clone", "--bare", "--quiet", plainURL, tmp); err != nil {
+		return err
+	}
+	// Containers may run as any user, so the cache has to be world-readable
+	if err := filepath.WalkDir(tmp, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		mode := os.FileMode(0644)
+		if d.IsDir() {
+			mode = 0755
+		}
+		return os.Chmod(p, mode)
+	}); err != nil {
+		return fmt.Errorf("failed to prepare repository cache: %w", err)
+	}
+	if err := replaceDirContents(tmp, path); err != nil {
+		return fmt.Errorf("failed to store repository cache: %w", err)
+	}
+	c.markRefreshed(path)
+	return nil
+}
+
+// replaceDirContents moves the entries of src into dst, removing what dst held before. dst
+// itself is kept, so bind mounts of it in running containers see the new contents.
+func replaceDirContents(src, dst string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	old, err := os.ReadDir(dst)
+	if err != nil {
+		return err
+	}
+	for _, entry := range old {
+		if err := os.RemoveAll(filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Rename(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *RepoCache) fetch(ctx context.Context, path, token string) error {
+	out, err := exec.CommandContext(ctx, "git", "--git-dir", path, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return fmt.Errorf("failed to read cached remote: %w", err)
+	}
+	remote := strings.TrimSpace(string(out))
+	args := append([]string{"--git-dir", path, "fetch", "--quiet", "--prune", "origin"}, repoCacheFetchRefspecs...)
+	if err := runGit(ctx, token, remote, args...); err != nil {
+		return err
+	}
+	c.markRefreshed(path)
+	return nil
+}
+
+// runGit runs git on the server. For https remotes the token is passed as an HTTP
+// authorization header on the command line, so it never lands in a git config file.
+func runGit(ctx context.Context, token, remote string, args ...string) error {
+	gitArgs := []string{}
+	if token != "" && strings.HasPrefix(remote, "https://") {
+		auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+		gitArgs = append(gitArgs, "-c", "http.extraHeader=Authorization: Basic "+auth)
+	}
+	cmd := exec.CommandContext(ctx, "git", append(gitArgs, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(output))
+		if token != "" {
+			msg = strings.ReplaceAll(msg, token, "***")
+		}
+		return fmt.Errorf("git failed: %v: %s", err, msg)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidGitRepo is returned when a repository URL or name is malformed or its host is not supported
+var ErrInvalidGitRepo = errors.New("invalid git repository")
+
+// defaultGitRepoHosts are the git providers repositories may be cloned from when GIT_REPO_HOSTS is empty
+var defaultGitRepoHosts = []string{"github.com", "gitlab.com", "bitbucket.org"}
+
+// scpRepoURLPattern matches the scp-like SSH form user@host:path
+var scpRepoURLPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+@([A-Za-z0-9.-]+):([^/].*)$`)
+
+// repoPathSegmentPattern restricts the owner, group and repository parts of a repository path
+var repoPathSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9._~-]+$`)
+
+// normalizeRepoURL validates a repository URL and returns its canonical https form, without
+// a trailing slash or .git, and the repository name derived from it. SSH URLs
+// (ssh://git@host/owner/repo and git@host:owner/repo) are converted to https, since clones
+// authenticate with the configured token. The host must be one of hosts, and the path needs
+// an owner and a repository; nested groups such as GitLab subgroups are kept.
+func normalizeRepoURL(rawURL string, hosts []string) (string, string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", "", fmt.Errorf("%w: git_repo_url is empty", ErrInvalidGitRepo)
+	}
+
+	var host, hostname, path string
+	if m := scpRepoURLPattern.FindStringSubmatch(rawURL); m != nil && !strings.Contains(rawURL, "://") {
+		host, hostname, path = m[1], m[1], m[2]
+	} else {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: git_repo_url is not a valid URL", ErrInvalidGitRepo)
+		}
+		switch strings.ToLower(u.Scheme) {
+		case "https":
+			if u.User != nil {
+				return "", "", fmt.Errorf("%w: git_repo_url must not contain credentials; configure a GitHub token instead", ErrInvalidGitRepo)
+			}
+			host = u.Host
+		case "ssh":
+			// The SSH port means nothing for the https clone
+			host = u.Hostname()
+		default:
+			return "", "", fmt.Errorf("%w: git_repo_url must be an https or ssh URL", ErrInvalidGitRepo)
+		}
+		if u.RawQuery != "" || u.Fragment != "" {
+			return "", "", fmt.Errorf("%w: git_repo_url must not have a query or fragment", ErrInvalidGitRepo)
+		}
+		hostname, path = u.Hostname(), u.Path
+	}
+
+	host, hostname = strings.ToLower(host), strings.ToLower(hostname)
+	if hostname == "" {
+		return "", "", fmt.Errorf("%w: git_repo_url has no host", ErrInvalidGitRepo)
+	}
+	if !gitRepoHostAllowed(hostname, hosts) {
+		return "", "", fmt.Errorf("%w: git host %s is not supported (allowed: %s)", ErrInvalidGitRepo, hostname, strings.Join(hosts, ", "))
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	segments := strings.Split(path, "/")
+	if len(segments) < 2 {
+		return "", "", fmt.Errorf("%w: git_repo_url must name an owner and a repository", ErrInvalidGitRepo)
+	}
+	for _, segment := range segments {
+		if !repoPathSegmentPattern.MatchString(segment) || segment == "." || segment == ".." {
+			return "", "", fmt.Errorf("%w: git_repo_url has an invalid path segment %q", ErrInvalidGitRepo, segment)
+		}
+	}
+
+	name := sanitizeRepoName(segments[len(segments)-1])
+	if name == "" {
+		return "", "", fmt.Errorf("%w: cannot derive a repository name from git_repo_url", ErrInvalidGitRepo)
+	}
+	return "https://" + host + "/" + strings.Join(segments, "/"), name, nil
+}
+
+// sanitizeRepoName turns the last path segment of a repository URL into a directory name
+// accepted by repoDirPattern, or returns "" when nothing usable is left
+func sanitizeRepoName(segment string) string {
+	name := strings.TrimLeft(strings.ReplaceAll(segment, "~", "-"), "._-")
+	if !repoDirPattern.MatchString(name) {
+		return ""
+	}
+	return name
+}
+
+// gitRepoHostAllowed reports whether hostname is one of the supported git hosts
+func gitRepoHostAllowed(hostname string, hosts []string) bool {
+	for _, allowed := range hosts {
+		if strings.EqualFold(hostname, strings.TrimSpace(allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// gitRepoHosts returns the git hosts repositories may be cloned from
+func (s *ContainerService) gitRepoHosts() []string {
+	if s.config != nil && len(s.config.GitRepoHosts) > 0 {
+		return s.config.GitRepoHosts
+	}
+	return defaultGitRepoHosts
+}
+
+// resolveGitRepo normalizes a container's repository URL and picks its directory name: the
+// requested name when set, otherwise the one derived from the URL
+func (s *ContainerService) resolveGitRepo(repoURL, repoName string) (string, string, error) {
+	normalized, derived, err := normalizeRepoURL(repoURL, s.gitRepoHosts())
+	if err != nil {
+		return "", "", err
+	}
+	repoName = strings.TrimSpace(repoName)
+	if repoName == "" {
+		return normalized, derived, nil
+	}
+	if !repoDirPattern.MatchString(repoName) {
+		return "", "", fmt.Errorf("%w: invalid git_repo_name %q", ErrInvalidGitRepo, repoName)
+	}
+	return normalized, repoName, nil
+}
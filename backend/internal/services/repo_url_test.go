@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeRepoURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		wantURL  string
+		wantName string
+	}{
+		{name: "https", url: "https://github.com/acme/widgets", wantURL: "https://github.com/acme/widgets", wantName: "widgets"},
+		{name: "https with .git", url: "https://github.com/acme/widgets.git", wantURL: "https://github.com/acme/widgets", wantName: "widgets"},
+		{name: "trailing slash", url: " https://github.com/acme/widgets/ ", wantURL: "https://github.com/acme/widgets", wantName: "widgets"},
+		{name: ".git and trailing slash", url: "https://github.com/acme/widgets.git/", wantURL: "https://github.com/acme/widgets", wantName: "widgets"},
+		{name: "host case", url: "https://GitHub.com/acme/widgets", wantURL: "https://github.com/acme/widgets", wantName: "widgets"},
+		{name: "scp-like ssh", url: "git@github.com:acme/widgets.git", wantURL: "https://github.com/acme/widgets", wantName: "widgets"},
+		{name: "ssh URL", url: "ssh://git@gitlab.com/acme/widgets.git", wantURL: "https://gitlab.com/acme/widgets", wantName: "widgets"},
+		{name: "ssh URL with port", url: "ssh://git@gitlab.com:2222/acme/widgets", wantURL: "https://gitlab.com/acme/widgets", wantName: "widgets"},
+		{name: "nested groups", url: "https://gitlab.com/acme/platform/tools/widgets.git", wantURL: "https://gitlab.com/acme/platform/tools/widgets", wantName: "widgets"},
+		{name: "nested groups over ssh", url: "git@gitlab.com:acme/platform/widgets", wantURL: "https://gitlab.com/acme/platform/widgets", wantName: "widgets"},
+		{name: "name is sanitized", url: "https://github.com/acme/.dotfiles", wantURL: "https://github.com/acme/.dotfiles", wantName: "dotfiles"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotURL, gotName, err := normalizeRepoURL(tt.url, defaultGitRepoHosts)
+			if err != nil {
+				t.Fatalf("normalizeRepoURL(%q) failed: %v", tt.url, err)
+			}
+			if gotURL != tt.wantURL || gotName != tt.wantName {
+				t.Errorf("normalizeRepoURL(%q) = %q, %q, want %q, %q", tt.url, gotURL, gotName, tt.wantURL, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestNormalizeRepoURL_Invalid(t *testing.T) {
+	for _, url := range []string{
+		"",
+		"   ",
+		"github.com/acme/widgets",
+		"http://github.com/acme/widgets",
+		"git://github.com/acme/widgets",
+		"file:///srv/git/widgets",
+		"https://example.com/acme/widgets",
+		"https://github.com.evil.com/acme/widgets",
+		"https://token@github.com/acme/widgets",
+		"https://github.com/widgets",
+		"https://github.com/",
+		"https://github.com/acme//widgets",
+		"https://github.com/acme/../widgets",
+		"https://github.com/acme/widgets;rm -rf",
+		"https://github.com/acme/$(id)",
+		"https://github.com/acme/widgets?ref=main",
+		"https://github.com/acme/widgets#readme",
+		"https://github.com/acme/___",
+		"git@example.com:acme/widgets",
+		"git@github.com:/acme/widgets",
+	} {
+		if _, _, err := normalizeRepoURL(url, defaultGitRepoHosts); !errors.Is(err, ErrInvalidGitRepo) {
+			t.Errorf("normalizeRepoURL(%q) = %v, want ErrInvalidGitRepo", url, err)
+		}
+	}
+}
+
+func TestNormalizeRepoURL_CustomHosts(t *testing.T) {
+	hosts := []string{"git.example.com"}
+	if got, _, err := normalizeRepoURL("https://git.example.com:8443/team/app", hosts); err != nil || got != "https://git.example.com:8443/team/app" {
+		t.Errorf("Expected the custom host with its port, got %q (%v)", got, err)
+	}
+	if _, _, err := normalizeRepoURL("https://github.com/acme/widgets", hosts); !errors.Is(err, ErrInvalidGitRepo) {
+		t.Errorf("Expected github.com to be refused when not configured, got %v", err)
+	}
+}
+
+func TestResolveGitRepo(t *testing.T) {
+	s := &ContainerService{}
+	if url, name, err := s.resolveGitRepo("git@github.com:acme/widgets.git", ""); err != nil || url != "https://github.com/acme/widgets" || name != "widgets" {
+		t.Errorf("Unexpected result %q %q (%v)", url, name, err)
+	}
+	if _, name, err := s.resolveGitRepo("https://github.com/acme/widgets", " app "); err != nil || name != "app" {
+		t.Errorf("Expected the requested name, got %q (%v)", name, err)
+	}
+	for _, name := range []string{"../etc", "a b", "x;id"} {
+		if _, _, err := s.resolveGitRepo("https://github.com/acme/widgets", name); !errors.Is(err, ErrInvalidGitRepo) {
+			t.Errorf("resolveGitRepo with name %q = %v, want ErrInvalidGitRepo", name, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"cc-platform/internal/config"
+)
+
+// ErrUnknownResourceProfile is returned when a container asks for a profile that is not configured
+var ErrUnknownResourceProfile = errors.New("unknown resource profile")
+
+// ResourceProfile is a named CPU/memory preset for new containers
+type ResourceProfile struct {
+	Name        string  `json:"name"`
+	CPULimit    float64 `json:"cpu_limit"`    // CPU limit in cores
+	MemoryLimit int64   `json:"memory_limit"` // Memory limit in MB
+	Default     bool    `json:"default"`      // Applied when a container names no profile
+}
+
+// builtinResourceProfiles are used when RESOURCE_PROFILES is not set
+var builtinResourceProfiles = []ResourceProfile{
+	{Name: "small", CPULimit: 1, MemoryLimit: 2048},
+	{Name: "medium", CPULimit: 2, MemoryLimit: 4096},
+	{Name: "large", CPULimit: 4, MemoryLimit: 8192},
+}
+
+// loadResourceProfiles builds the profile list from server config.
+// Invalid entries are logged and skipped so a typo doesn't keep the server from starting.
+func loadResourceProfiles(cfg *config.Config) []ResourceProfile {
+	var entries []string
+	var defaultName string
+	if cfg != nil {
+		entries = cfg.ResourceProfiles
+		defaultName = cfg.DefaultResourceProfile
+	}
+
+	var profiles []ResourceProfile
+	if len(entries) == 0 {
+		profiles = append(profiles, builtinResourceProfiles...)
+	}
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		profile, err := parseResourceProfile(entry)
+		if err != nil {
+			log.Printf("Warning: ignoring resource profile %q: %v", entry, err)
+			continue
+		}
+		if seen[profile.Name] {
+			log.Printf("Warning: ignoring duplicate resource profile %q", profile.Name)
+			continue
+		}
+		seen[profile.Name] = true
+		profiles = append(profiles, profile)
+	}
+
+	if defaultName != "" {
+		found := false
+		for i := range profiles {
+			if profiles[i].Name == defaultName {
+				profiles[i].Default = true
+				found = true
+			}
+		}
+		if !found {
+			log.Printf("Warning: default resource profile %q is not defined; using built-in limits", defaultName)
+		}
+	}
+	return profiles
+}
+
+// parseResourceProfile parses a "name=cpu:memoryMB" entry, e.g. "large=4:8192"
+func parseResourceProfile(entry string) (ResourceProfile, error) {
+	name, limits, ok := strings.Cut(entry, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return ResourceProfile{}, fmt.Errorf("expected name=cpu:memory")
+	}
+	cpuStr, memStr, ok := strings.Cut(limits, ":")
+	if !ok {
+		return ResourceProfile{}, fmt.Errorf("expected name=cpu:memory")
+	}
+
+	cpu, err := strconv.ParseFloat(strings.TrimSpace(cpuStr), 64)
+	if err != nil {
+		return ResourceProfile{}, fmt.Errorf("%w: %q", ErrInvalidCPULimit, cpuStr)
+	}
+	memory, err := strconv.ParseInt(strings.TrimSpace(memStr), 10, 64)
+	if err != nil {
+		return ResourceProfile{}, fmt.Errorf("%w: %q", ErrInvalidMemoryLimit, memStr)
+	}
+	if err := validateResourceLimits(cpu, memory, CPUPeriodDefault); err != nil {
+		return ResourceProfile{}, err
+	}
+
+	return ResourceProfile{Name: name, CPULimit: cpu, MemoryLimit: memory}, nil
+}
+
+// ListResourceProfiles returns the resource profiles available for new containers
+func (s *ContainerService) ListResourceProfiles() []ResourceProfile {
+	profiles := make([]ResourceProfile, len(s.resourceProfiles))
+	copy(profiles, s.resourceProfiles)
+	return profiles
+}
+
+// applyResourceProfile fills unset limits from the named profile, or from the default
+// profile when none is named. Explicit limits and unlimited flags always win.
+func (s *ContainerService) applyResourceProfile(input *CreateContainerInput) error {
+	var profile *ResourceProfile
+	for i := range s.resourceProfiles {
+		p := &s.resourceProfiles[i]
+		if (input.ResourceProfile != "" && p.Name == input.ResourceProfile) ||
+			(input.ResourceProfile == "" && p.Default) {
+			profile = p
+			break
+		}
+	}
+	if profile == nil {
+		if input.ResourceProfile != "" {
+			return fmt.Errorf("%w: %s", ErrUnknownResourceProfile, input.ResourceProfile)
+		}
+		return nil
+	}
+
+	if input.CPULimit == 0 && !input.CPUUnlimited {
+		input.CPULimit = profile.CPULimit
+	}
+	if input.MemoryLimit == 0 && !input.MemoryUnlimited {
+		input.MemoryLimit = profile.MemoryLimit
+	}
+	return nil
+}
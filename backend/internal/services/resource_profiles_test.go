@@ -0,0 +1,95 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"cc-platform/internal/config"
+)
+
+func TestLoadResourceProfiles_BuiltinsWhenUnset(t *testing.T) {
+	profiles := loadResourceProfiles(&config.Config{})
+	if len(profiles) != len(builtinResourceProfiles) {
+		t.Fatalf("Expected %d built-in profiles, got %d", len(builtinResourceProfiles), len(profiles))
+	}
+	for _, p := range profiles {
+		if p.Default {
+			t.Errorf("No profile should be default without DEFAULT_RESOURCE_PROFILE, got %q", p.Name)
+		}
+	}
+
+	if profiles := loadResourceProfiles(nil); len(profiles) != len(builtinResourceProfiles) {
+		t.Errorf("Nil config should use built-in profiles, got %d", len(profiles))
+	}
+}
+
+func TestLoadResourceProfiles_SkipsInvalidEntries(t *testing.T) {
+	profiles := loadResourceProfiles(&config.Config{
+		ResourceProfiles: []string{
+			"tiny=0.5:512",
+			"huge=128:2048",       // CPU over the maximum
+			"bad",                 // missing limits
+			"nocpu=x:1024",        // CPU not a number
+			"tiny=1:1024",         // duplicate name
+			"gpu-box = 8 : 16384", // whitespace is trimmed
+		},
+		DefaultResourceProfile: "gpu-box",
+	})
+
+	if len(profiles) != 2 {
+		t.Fatalf("Expected 2 valid profiles, got %+v", profiles)
+	}
+	if profiles[0] != (ResourceProfile{Name: "tiny", CPULimit: 0.5, MemoryLimit: 512}) {
+		t.Errorf("Unexpected first profile: %+v", profiles[0])
+	}
+	if profiles[1] != (ResourceProfile{Name: "gpu-box", CPULimit: 8, MemoryLimit: 16384, Default: true}) {
+		t.Errorf("Unexpected second profile: %+v", profiles[1])
+	}
+}
+
+func TestApplyResourceProfile(t *testing.T) {
+	s := &ContainerService{resourceProfiles: loadResourceProfiles(&config.Config{
+		ResourceProfiles:       []string{"small=1:2048", "large=4:8192"},
+		DefaultResourceProfile: "small",
+	})}
+
+	tests := []struct {
+		name       string
+		input      CreateContainerInput
+		wantCPU    float64
+		wantMemory int64
+	}{
+		{"default profile", CreateContainerInput{}, 1, 2048},
+		{"named profile", CreateContainerInput{ResourceProfile: "large"}, 4, 8192},
+		{"explicit memory overrides profile", CreateContainerInput{ResourceProfile: "large", MemoryLimit: 1024}, 4, 1024},
+		{"explicit cpu overrides default", CreateContainerInput{CPULimit: 2}, 2, 2048},
+		{"unlimited flags win", CreateContainerInput{ResourceProfile: "large", CPUUnlimited: true, MemoryUnlimited: true}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			if err := s.applyResourceProfile(&input); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if input.CPULimit != tt.wantCPU || input.MemoryLimit != tt.wantMemory {
+				t.Errorf("Expected %v cores / %d MB, got %v / %d", tt.wantCPU, tt.wantMemory, input.CPULimit, input.MemoryLimit)
+			}
+		})
+	}
+}
+
+func TestApplyResourceProfile_UnknownProfile(t *testing.T) {
+	s := &ContainerService{resourceProfiles: loadResourceProfiles(nil)}
+
+	input := CreateContainerInput{ResourceProfile: "xlarge"}
+	if err := s.applyResourceProfile(&input); !errors.Is(err, ErrUnknownResourceProfile) {
+		t.Errorf("Expected ErrUnknownResourceProfile, got %v", err)
+	}
+
+	// Without a default profile, unset limits are left for the built-in defaults
+	input = CreateContainerInput{}
+	if err := s.applyResourceProfile(&input); err != nil || input.CPULimit != 0 || input.MemoryLimit != 0 {
+		t.Errorf("Expected limits untouched, got %v / %d (err %v)", input.CPULimit, input.MemoryLimit, err)
+	}
+}
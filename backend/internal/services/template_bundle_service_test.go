@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"cc-platform/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var bundleTestDBCounter int
+
+func setupBundleTestService(t *testing.T) (TemplateBundleService, *mockConfigTemplateService) {
+	t.Helper()
+
+	bundleTestDBCounter++
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:bundledb%d?mode=memory&cache=shared", bundleTestDBCounter)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.TemplateBundle{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	templateService := newMockConfigTemplateService()
+	return NewTemplateBundleService(db, templateService), templateService
+}
+
+func TestTemplateBundle_CreateKeepsOrderAndDedupes(t *testing.T) {
+	service, _ := setupBundleTestService(t)
+
+	bundle, err := service.Create(CreateTemplateBundleInput{
+		Name:        "  frontend  ",
+		TemplateIDs: []uint{3, 1, 3, 2},
+	})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	loaded, err := service.GetByID(bundle.ID)
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if loaded.Name != "frontend" {
+		t.Errorf("Expected trimmed name 'frontend', got %q", loaded.Name)
+	}
+	if fmt.Sprint([]uint(loaded.TemplateIDs)) != "[3 1 2]" {
+		t.Errorf("Expected template IDs [3 1 2], got %v", loaded.TemplateIDs)
+	}
+}
+
+func TestTemplateBundle_DuplicateAndBlankNames(t *testing.T) {
+	service, _ := setupBundleTestService(t)
+
+	if _, err := service.Create(CreateTemplateBundleInput{Name: "backend"}); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if _, err := service.Create(CreateTemplateBundleInput{Name: "backend"}); !errors.Is(err, ErrDuplicateBundleName) {
+		t.Errorf("Expected ErrDuplicateBundleName, got %v", err)
+	}
+	if _, err := service.Create(CreateTemplateBundleInput{Name: "   "}); !errors.Is(err, ErrInvalidBundleName) {
+		t.Errorf("Expected ErrInvalidBundleName, got %v", err)
+	}
+}
+
+func TestTemplateBundle_UpdateAndDelete(t *testing.T) {
+	service, _ := setupBundleTestService(t)
+
+	bundle, err := service.Create(CreateTemplateBundleInput{Name: "ops", TemplateIDs: []uint{1}})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	ids := []uint{2, 1}
+	updated, err := service.Update(bundle.ID, UpdateTemplateBundleInput{TemplateIDs: &ids})
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if fmt.Sprint([]uint(updated.TemplateIDs)) != "[2 1]" {
+		t.Errorf("Expected template IDs [2 1], got %v", updated.TemplateIDs)
+	}
+
+	if err := service.Delete(bundle.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := service.GetByID(bundle.ID); !errors.Is(err, ErrBundleNotFound) {
+		t.Errorf("Expected ErrBundleNotFound after delete, got %v", err)
+	}
+	if err := service.Delete(bundle.ID); !errors.Is(err, ErrBundleNotFound) {
+		t.Errorf("Expected ErrBundleNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestTemplateBundle_ResolveSkipsMissingTemplates(t *testing.T) {
+	service, templateService := setupBundleTestService(t)
+
+	first, _ := templateService.Create(CreateConfigTemplateInput{Name: "first", ConfigType: models.ConfigTypeClaudeMD, Content: "# First"})
+	second, _ := templateService.Create(CreateConfigTemplateInput{Name: "second", ConfigType: models.ConfigTypeCommand, Content: "# Second"})
+
+	bundle := &models.TemplateBundle{
+		Name:        "mixed",
+		TemplateIDs: models.TemplateIDList{second.ID, 999, first.ID},
+	}
+
+	templateIDs, warnings := service.ResolveTemplateIDs(bundle)
+
+	if fmt.Sprint(templateIDs) != fmt.Sprint([]uint{second.ID, first.ID}) {
+		t.Errorf("Expected existing IDs in bundle order, got %v", templateIDs)
+	}
+	if len(warnings) != 1 || !containsStr(warnings[0], "999") {
+		t.Errorf("Expected one warning about template 999, got %v", warnings)
+	}
+}
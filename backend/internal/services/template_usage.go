@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// templateContainerCountQuery counts, per template, the live containers using it: containers whose
+// selected template IDs contain it (chosen at creation or injected later), and for skills containers
+// that auto-inject all skills. Live means neither soft-deleted nor in the deleted status.
+const templateContainerCountQuery = `
+SELECT t.id AS template_id, COUNT(DISTINCT c.id) AS container_count
+FROM claude_config_templates t
+JOIN containers c ON c.deleted_at IS NULL AND c.status <> ? AND (
+	(t.config_type = ? AND c.auto_inject_all_skills)
+	OR EXISTS (
+		SELECT 1 FROM json_each(CASE WHEN json_valid(c.selected_template_ids) THEN c.selected_template_ids ELSE '[]' END) j
+		WHERE j.value = t.id
+	)
+)
+WHERE t.deleted_at IS NULL`
+
+// TemplateUsageService reports how config templates are used by containers
+type TemplateUsageService struct {
+	db *gorm.DB
+}
+
+// NewTemplateUsageService creates a new TemplateUsageService
+func NewTemplateUsageService(db *gorm.DB) *TemplateUsageService {
+	return &TemplateUsageService{db: db}
+}
+
+// GetUsage returns the usage of one template
+func (s *TemplateUsageService) GetUsage(id uint) (*models.TemplateUsage, error) {
+	var template models.ClaudeConfigTemplate
+	if err := s.db.Select("id", "injection_count", "last_injected_at").First(&template, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+
+	counts, err := s.containerCounts(&id)
+	if err != nil {
+		return nil, err
+	}
+	return templateUsage(&template, counts[id]), nil
+}
+
+// ListUsage returns the usage of every template keyed by template ID, using one aggregate
+// query over the container associations
+func (s *TemplateUsageService) ListUsage() (map[uint]*models.TemplateUsage, error) {
+	var templates []models.ClaudeConfigTemplate
+	if err := s.db.Select("id", "injection_count", "last_injected_at").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	counts, err := s.containerCounts(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[uint]*models.TemplateUsage, len(templates))
+	for i := range templates {
+		usage[templates[i].ID] = templateUsage(&templates[i], counts[templates[i].ID])
+	}
+	return usage, nil
+}
+
+// containerCounts runs templateContainerCountQuery for one template, or all when id is nil
+func (s *TemplateUsageService) containerCounts(id *uint) (map[uint]int64, error) {
+	query := templateContainerCountQuery
+	args := []interface{}{models.ContainerStatusDeleted, models.ConfigTypeSkill}
+	if id != nil {
+		query += " AND t.id = ?"
+		args = append(args, *id)
+	}
+	query += " GROUP BY t.id"
+
+	var rows []struct {
+		TemplateID     uint
+		ContainerCount int64
+	}
+	if err := s.db.Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count template usage: %w", err)
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.TemplateID] = row.ContainerCount
+	}
+	return counts, nil
+}
+
+// templateUsage combines a template's injection counters with its container count
+func templateUsage(template *models.ClaudeConfigTemplate, containers int64) *models.TemplateUsage {
+	return &models.TemplateUsage{
+		TemplateID:     template.ID,
+		ContainerCount: containers,
+		InjectionCount: template.InjectionCount,
+		LastInjectedAt: template.LastInjectedAt,
+		Unused:         containers == 0,
+	}
+}
+
+// recordTemplateInjections counts one successful injection for each template. UpdateColumns
+// leaves updated_at alone, since injecting a template doesn't edit it.
+func recordTemplateInjections(db *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return db.Model(&models.ClaudeConfigTemplate{}).Where("id IN ?", ids).UpdateColumns(map[string]interface{}{
+		"injection_count":  gorm.Expr("injection_count + 1"),
+		"last_injected_at": time.Now(),
+	}).Error
+}
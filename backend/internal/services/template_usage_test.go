@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"cc-platform/internal/models"
+)
+
+func setupTemplateUsageTest(t *testing.T) (*ContainerService, *TemplateUsageService, []models.ClaudeConfigTemplate) {
+	t.Helper()
+	s := setupPauseTestService(t)
+	if err := s.db.AutoMigrate(&models.ClaudeConfigTemplate{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	templates := []models.ClaudeConfigTemplate{
+		{Name: "rules", ConfigType: models.ConfigTypeClaudeMD, Content: "# Rules"},
+		{Name: "tdd", ConfigType: models.ConfigTypeSkill, Content: "skill"},
+		{Name: "github", ConfigType: models.ConfigTypeMCP, Content: "{}"},
+	}
+	for i := range templates {
+		if err := s.db.Create(&templates[i]).Error; err != nil {
+			t.Fatalf("failed to create template: %v", err)
+		}
+	}
+	return s, NewTemplateUsageService(s.db), templates
+}
+
+func TestTemplateUsage_ContainerCounts(t *testing.T) {
+	s, usage, templates := setupTemplateUsageTest(t)
+	rules, skill, mcp := templates[0].ID, templates[1].ID, templates[2].ID
+
+	selectTemplates := func(c *models.Container, ids ...uint) {
+		if err := s.db.Model(c).Update("selected_template_ids", models.TemplateIDList(ids)).Error; err != nil {
+			t.Fatalf("failed to select templates: %v", err)
+		}
+	}
+	a := createPauseTestContainer(t, s, "a", models.ContainerStatusRunning)
+	selectTemplates(a, rules, skill)
+	b := createPauseTestContainer(t, s, "b", models.ContainerStatusStopped)
+	selectTemplates(b, rules)
+	// Auto-injecting all skills counts once, even when the skill is also selected
+	c := createPauseTestContainer(t, s, "c", models.ContainerStatusRunning)
+	selectTemplates(c, skill)
+	s.db.Model(c).Update("auto_inject_all_skills", true)
+	// Deleted containers do not count
+	d := createPauseTestContainer(t, s, "d", models.ContainerStatusDeleted)
+	selectTemplates(d, mcp)
+	e := createPauseTestContainer(t, s, "e", models.ContainerStatusRunning)
+	selectTemplates(e, mcp)
+	s.db.Delete(e)
+
+	all, err := usage.ListUsage()
+	if err != nil {
+		t.Fatalf("ListUsage failed: %v", err)
+	}
+	want := map[uint]int64{rules: 2, skill: 2, mcp: 0}
+	for id, count := range want {
+		if all[id] == nil || all[id].ContainerCount != count || all[id].Unused != (count == 0) {
+			t.Errorf("template %d: got %+v, want %d containers", id, all[id], count)
+		}
+	}
+
+	one, err := usage.GetUsage(rules)
+	if err != nil {
+		t.Fatalf("GetUsage failed: %v", err)
+	}
+	if one.ContainerCount != 2 || one.Unused {
+		t.Errorf("Unexpected usage: %+v", one)
+	}
+	if _, err := usage.GetUsage(9999); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+	}
+}
+
+func TestTemplateUsage_InjectionCounts(t *testing.T) {
+	s, usage, templates := setupTemplateUsageTest(t)
+	rules, skill := templates[0].ID, templates[1].ID
+
+	if err := recordTemplateInjections(s.db, []uint{rules, skill}); err != nil {
+		t.Fatalf("recordTemplateInjections failed: %v", err)
+	}
+	if err := recordTemplateInjections(s.db, []uint{rules}); err != nil {
+		t.Fatalf("recordTemplateInjections failed: %v", err)
+	}
+	if err := recordTemplateInjections(s.db, nil); err != nil {
+		t.Fatalf("recordTemplateInjections failed: %v", err)
+	}
+
+	all, err := usage.ListUsage()
+	if err != nil {
+		t.Fatalf("ListUsage failed: %v", err)
+	}
+	if all[rules].InjectionCount != 2 || all[skill].InjectionCount != 1 || all[templates[2].ID].InjectionCount != 0 {
+		t.Errorf("Unexpected injection counts: %+v %+v %+v", all[rules], all[skill], all[templates[2].ID])
+	}
+	if all[rules].LastInjectedAt == nil || all[templates[2].ID].LastInjectedAt != nil {
+		t.Error("Expected LastInjectedAt only for injected templates")
+	}
+	// Injected templates with no live container are still reported as unused
+	if !all[rules].Unused {
+		t.Error("Expected a template without containers to be unused")
+	}
+
+	// Counting an injection isn't an edit of the template
+	var stored models.ClaudeConfigTemplate
+	if err := s.db.First(&stored, rules).Error; err != nil {
+		t.Fatalf("failed to load template: %v", err)
+	}
+	if !stored.UpdatedAt.Equal(templates[0].UpdatedAt) {
+		t.Errorf("Expected updated_at to stay %v, got %v", templates[0].UpdatedAt, stored.UpdatedAt)
+	}
+}
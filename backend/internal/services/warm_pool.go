@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cc-platform/internal/docker"
+	"cc-platform/internal/models"
+
+	"github.com/docker/docker/api/types"
+)
+
+const (
+	// warmPoolLabel marks containers pre-created for the warm pool
+	warmPoolLabel = "cc-platform.warm-pool"
+	// warmPoolNamePrefix starts the Docker name of an idle pool container
+	warmPoolNamePrefix = "cc-warm-"
+	// warmPoolWorkDir is the working directory pool containers are created with; a claimed
+	// container's terminals change to its real work directory
+	warmPoolWorkDir = "/workspace"
+	// warmPoolCleanupTimeout bounds removing the idle containers when the server stops
+	warmPoolCleanupTimeout = 30 * time.Second
+)
+
+// warmContainer is an idle, running pool container
+type warmContainer struct {
+	DockerID string
+	Name     string
+	// EnvHash fingerprints the environment the container was created with
+	EnvHash string
+}
+
+// warmPool keeps idle containers booted from the base image with the default settings, so a
+// create request using those settings can claim one instead of creating and starting its own.
+// create and remove do the Docker work; the claim and refill logic doesn't touch Docker.
+type warmPool struct {
+	mu     sync.Mutex
+	size   int
+	idle   []warmContainer
+	refill chan struct{}
+	create func(ctx context.Context, env []string) (warmContainer, error)
+	remove func(ctx context.Context, c warmContainer)
+}
+
+func newWarmPool(size int, create func(context.Context, []string) (warmContainer, error), remove func(context.Context, warmContainer)) *warmPool {
+	return &warmPool{size: size, refill: make(chan struct{}, 1), create: create, remove: remove}
+}
+
+// claim takes an idle container created with the given environment and asks for a refill
+func (p *warmPool) claim(envHash string) (warmContainer, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, c := range p.idle {
+		if c.EnvHash != envHash {
+			continue
+		}
+		p.idle = append(p.idle[:i], p.idle[i+1:]...)
+		select {
+		case p.refill <- struct{}{}:
+		default:
+		}
+		return c, true
+	}
+	return warmContainer{}, false
+}
+
+// replenish removes idle containers whose environment is outdated, then creates containers
+// with env until the pool is full again. It returns how many were created.
+func (p *warmPool) replenish(ctx context.Context, env []string) (int, error) {
+	envHash := envFingerprint(env)
+
+	p.mu.Lock()
+	var stale []warmContainer
+	current := p.idle[:0]
+	for _, c := range p.idle {
+		if c.EnvHash == envHash {
+			current = append(current, c)
+		} else {
+			stale = append(stale, c)
+		}
+	}
+	p.idle = current
+	missing := p.size - len(p.idle)
+	p.mu.Unlock()
+
+	for _, c := range stale {
+		p.remove(ctx, c)
+	}
+
+	created := 0
+	for ; created < missing; created++ {
+		if ctx.Err() != nil {
+			return created, ctx.Err()
+		}
+		c, err := p.create(ctx, env)
+		if err != nil {
+			return created, err
+		}
+		c.EnvHash = envHash
+		p.mu.Lock()
+		p.idle = append(p.idle, c)
+		p.mu.Unlock()
+	}
+	return created, nil
+}
+
+// drain removes every idle container
+func (p *warmPool) drain(ctx context.Context) {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+	for _, c := range idle {
+		p.remove(ctx, c)
+	}
+}
+
+// dockerIDs returns the Docker IDs of the idle containers
+func (p *warmPool) dockerIDs() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ids := make([]string, len(p.idle))
+	for i, c := range p.idle {
+		ids[i] = c.DockerID
+	}
+	return ids
+}
+
+// envFingerprint identifies an environment regardless of variable order
+func envFingerprint(env []string) string {
+	sorted := append([]string(nil), env...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// warmPoolEligible reports whether a create request uses only the settings pool containers
+// are created with. Anything fixed when Docker creates a container must be at its default.
+func warmPoolEligible(input CreateContainerInput, image string, hostMounts []models.HostMount, restartPolicy, runAsUser string) bool {
+	return image == "" && len(hostMounts) == 0 && len(input.PortMappings) == 0 &&
+		!input.Proxy.enabled() && !input.codeServerEnabled() && !input.GPUEnabled &&
+		input.MemoryLimit == 0 && input.CPULimit == 0 && !input.MemoryUnlimited && !input.CPUUnlimited &&
+		restartPolicy == RestartPolicyNo && runAsUser == "" && !input.RunAsRoot
+}
+
+// warmPoolEnv is the environment of a default create request: the default env profile and,
+// when one is configured, the default GitHub token
+func (s *ContainerService) warmPoolEnv() ([]string, error) {
+	hasTokens := s.configProfileService.HasGitHubTokens()
+	input := CreateContainerInput{SkipGitRepo: !hasTokens && !s.githubService.HasToken()}
+	return s.dockerEnv(input, "", hasTokens)
+}
+
+// claimWarmContainer takes an idle pool container created with env and renames it to name.
+// It returns false when the pool is disabled, has no matching container or the rename fails.
+func (s *ContainerService) claimWarmContainer(ctx context.Context, name string, env []string) (warmContainer, bool) {
+	if s.warmPool == nil {
+		return warmContainer{}, false
+	}
+	c, ok := s.warmPool.claim(envFingerprint(env))
+	if !ok {
+		return warmContainer{}, false
+	}
+	if err := s.dockerClient.RenameContainer(ctx, c.DockerID, name); err != nil {
+		log.Printf("Warning: failed to rename warm pool container %s: %v", c.Name, err)
+		s.removeWarmContainer(ctx, c)
+		return warmContainer{}, false
+	}
+	return c, true
+}
+
+// createWarmContainer creates and starts an idle pool container with the default settings
+func (s *ContainerService) createWarmContainer(ctx context.Context, env []string) (warmContainer, error) {
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return warmContainer{}, err
+	}
+	name := warmPoolNamePrefix + hex.EncodeToString(suffix)
+	securityConfig := docker.DefaultSecurityConfig()
+
+	dockerID, err := s.dockerClient.CreateContainer(ctx, &docker.ContainerConfig{
+		Name:          name,
+		EnvVars:       env,
+		Binds:         buildManagedContainerBinds(name),
+		WorkingDir:    warmPoolWorkDir,
+		SecurityOpt:   securityConfig.SecurityOpt,
+		CapDrop:       securityConfig.CapDrop,
+		CapAdd:        securityConfig.CapAdd,
+		Resources:     securityConfig.Resources,
+		NetworkMode:   "bridge",
+		Labels:        map[string]string{platformManagedLabel: "true", warmPoolLabel: "true"},
+		RestartPolicy: dockerRestartPolicy(RestartPolicyNo, 0),
+	})
+	if err != nil {
+		return warmContainer{}, fmt.Errorf("failed to create warm pool container: %w", dockerError(err))
+	}
+	c := warmContainer{DockerID: dockerID, Name: name}
+	if err := s.dockerClient.StartContainer(ctx, dockerID); err != nil {
+		s.removeWarmContainer(ctx, c)
+		return warmContainer{}, fmt.Errorf("failed to start warm pool container: %w", dockerError(err))
+	}
+	return c, nil
+}
+
+// removeWarmContainer removes an idle pool container and its managed volumes
+func (s *ContainerService) removeWarmContainer(ctx context.Context, c warmContainer) {
+	if err := s.dockerClient.RemoveContainer(ctx, c.DockerID, true); err != nil {
+		log.Printf("Warning: failed to remove warm pool container %s: %v", c.Name, err)
+	}
+	if err := s.dockerClient.RemoveVolumes(ctx, managedPerContainerVolumes(c.Name)...); err != nil {
+		log.Printf("Warning: failed to remove volumes of warm pool container %s: %v", c.Name, err)
+	}
+}
+
+// removeLeftoverWarmContainers removes idle pool containers left behind by a previous run.
+// Claimed containers carry the label too, but they are recorded in the database.
+func (s *ContainerService) removeLeftoverWarmContainers(ctx context.Context) {
+	containers, err := s.dockerClient.ListAllContainers(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to list leftover warm pool containers: %v", err)
+		return
+	}
+	managedIDs := s.managedDockerIDs()
+	for _, c := range leftoverWarmContainers(containers, managedIDs) {
+		s.removeWarmContainer(ctx, c)
+	}
+}
+
+// leftoverWarmContainers picks the pool containers that no database record owns
+func leftoverWarmContainers(containers []types.Container, managedIDs map[string]bool) []warmContainer {
+	var leftovers []warmContainer
+	for _, c := range containers {
+		name := dockerContainerName(c)
+		if c.Labels[warmPoolLabel] != "true" || managedIDs[c.ID] || !strings.HasPrefix(name, warmPoolNamePrefix) {
+			continue
+		}
+		leftovers = append(leftovers, warmContainer{DockerID: c.ID, Name: name})
+	}
+	return leftovers
+}
+
+// warmPoolLoop keeps the pool full, refilling after every claim and on each interval tick,
+// and removes the idle containers when the service shuts down
+func (s *ContainerService) warmPoolLoop(interval time.Duration) {
+	defer s.wg.Done()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), warmPoolCleanupTimeout)
+		defer cancel()
+		s.warmPool.drain(ctx)
+	}()
+
+	s.removeLeftoverWarmContainers(s.ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		env, err := s.warmPoolEnv()
+		if err != nil {
+			log.Printf("Warning: warm pool environment unavailable: %v", err)
+		} else if _, err := s.warmPool.replenish(s.ctx, env); err != nil && s.ctx.Err() == nil {
+			log.Printf("Warning: warm pool refill failed: %v", err)
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.warmPool.refill:
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"cc-platform/internal/models"
+
+	"github.com/docker/docker/api/types"
+)
+
+// fakeWarmDocker records the pool containers created and removed in place of Docker
+type fakeWarmDocker struct {
+	mu      sync.Mutex
+	created int
+	removed []string
+	failAt  int // creation number that fails, 0 for none
+}
+
+func (f *fakeWarmDocker) create(ctx context.Context, env []string) (warmContainer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created++
+	if f.created == f.failAt {
+		return warmContainer{}, errors.New("docker create failed")
+	}
+	return warmContainer{DockerID: fmt.Sprintf("docker-%d", f.created), Name: fmt.Sprintf("cc-warm-%d", f.created)}, nil
+}
+
+func (f *fakeWarmDocker) remove(ctx context.Context, c warmContainer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.removed = append(f.removed, c.Name)
+}
+
+func TestWarmPoolReplenishAndClaim(t *testing.T) {
+	docker := &fakeWarmDocker{}
+	pool := newWarmPool(2, docker.create, docker.remove)
+	ctx := context.Background()
+	env := []string{"A=1", "B=2"}
+
+	created, err := pool.replenish(ctx, env)
+	if err != nil || created != 2 || len(pool.dockerIDs()) != 2 {
+		t.Fatalf("Expected 2 containers created, got %d (%v), pool %v", created, err, pool.dockerIDs())
+	}
+	// A full pool creates nothing more
+	if created, _ := pool.replenish(ctx, env); created != 0 {
+		t.Errorf("Expected a full pool to create nothing, created %d", created)
+	}
+
+	// The environment matches regardless of variable order
+	c, ok := pool.claim(envFingerprint([]string{"B=2", "A=1"}))
+	if !ok || c.DockerID != "docker-1" {
+		t.Fatalf("Expected to claim docker-1, got %+v (%v)", c, ok)
+	}
+	select {
+	case <-pool.refill:
+	default:
+		t.Error("Expected a claim to request a refill")
+	}
+	if ids := pool.dockerIDs(); len(ids) != 1 || ids[0] != "docker-2" {
+		t.Errorf("Expected docker-2 to stay idle, got %v", ids)
+	}
+
+	if _, ok := pool.claim(envFingerprint([]string{"A=1"})); ok {
+		t.Error("Expected no container for a different environment")
+	}
+
+	if created, _ := pool.replenish(ctx, env); created != 1 || len(pool.dockerIDs()) != 2 {
+		t.Errorf("Expected the claimed container to be replaced, created %d", created)
+	}
+}
+
+func TestWarmPoolReplenishReplacesStaleContainers(t *testing.T) {
+	docker := &fakeWarmDocker{}
+	pool := newWarmPool(2, docker.create, docker.remove)
+	ctx := context.Background()
+
+	pool.replenish(ctx, []string{"TOKEN=old"})
+	created, err := pool.replenish(ctx, []string{"TOKEN=new"})
+	if err != nil || created != 2 {
+		t.Fatalf("Expected 2 containers created for the new environment, got %d (%v)", created, err)
+	}
+	if len(docker.removed) != 2 || docker.removed[0] != "cc-warm-1" || docker.removed[1] != "cc-warm-2" {
+		t.Errorf("Expected the outdated containers to be removed, got %v", docker.removed)
+	}
+	if _, ok := pool.claim(envFingerprint([]string{"TOKEN=old"})); ok {
+		t.Error("Expected no container left for the old environment")
+	}
+}
+
+func TestWarmPoolReplenishStopsOnError(t *testing.T) {
+	docker := &fakeWarmDocker{failAt: 2}
+	pool := newWarmPool(3, docker.create, docker.remove)
+
+	created, err := pool.replenish(context.Background(), nil)
+	if err == nil || created != 1 || len(pool.dockerIDs()) != 1 {
+		t.Errorf("Expected one container before the failure, got %d (%v)", created, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if created, err := pool.replenish(ctx, nil); !errors.Is(err, context.Canceled) || created != 0 {
+		t.Errorf("Expected a cancelled refill to stop, got %d (%v)", created, err)
+	}
+}
+
+func TestWarmPoolDrain(t *testing.T) {
+	docker := &fakeWarmDocker{}
+	pool := newWarmPool(2, docker.create, docker.remove)
+	pool.replenish(context.Background(), nil)
+
+	pool.drain(context.Background())
+	if len(pool.dockerIDs()) != 0 || len(docker.removed) != 2 {
+		t.Errorf("Expected every idle container removed, pool %v, removed %v", pool.dockerIDs(), docker.removed)
+	}
+}
+
+func TestWarmPoolEligible(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name          string
+		input         CreateContainerInput
+		image         string
+		hostMounts    []models.HostMount
+		restartPolicy string
+		runAsUser     string
+		want          bool
+	}{
+		{name: "default settings", input: CreateContainerInput{GitRepoURL: "https://github.com/o/r"}, restartPolicy: RestartPolicyNo, want: true},
+		{name: "custom image", image: "node:20", restartPolicy: RestartPolicyNo},
+		{name: "host mount", hostMounts: []models.HostMount{{HostPath: "/data", ContainerPath: "/data"}}, restartPolicy: RestartPolicyNo},
+		{name: "port mapping", input: CreateContainerInput{PortMappings: []PortMapping{{ContainerPort: 3000, HostPort: 3000}}}, restartPolicy: RestartPolicyNo},
+		{name: "code-server", input: CreateContainerInput{EnableCodeServer: &enabled}, restartPolicy: RestartPolicyNo},
+		{name: "memory limit", input: CreateContainerInput{MemoryLimit: 4096}, restartPolicy: RestartPolicyNo},
+		{name: "unlimited CPU", input: CreateContainerInput{CPUUnlimited: true}, restartPolicy: RestartPolicyNo},
+		{name: "GPU", input: CreateContainerInput{GPUEnabled: true}, restartPolicy: RestartPolicyNo},
+		{name: "restart policy", restartPolicy: RestartPolicyAlways},
+		{name: "root", input: CreateContainerInput{RunAsRoot: true}, restartPolicy: RestartPolicyNo},
+		{name: "custom user", restartPolicy: RestartPolicyNo, runAsUser: "1000:1000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := warmPoolEligible(tt.input, tt.image, tt.hostMounts, tt.restartPolicy, tt.runAsUser); got != tt.want {
+				t.Errorf("warmPoolEligible() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeftoverWarmContainers(t *testing.T) {
+	containers := []types.Container{
+		{ID: "idle", Names: []string{"/cc-warm-1"}, Labels: map[string]string{platformManagedLabel: "true", warmPoolLabel: "true"}},
+		{ID: "claimed", Names: []string{"/my-project"}, Labels: map[string]string{platformManagedLabel: "true", warmPoolLabel: "true"}},
+		{ID: "recorded", Names: []string{"/cc-warm-2"}, Labels: map[string]string{platformManagedLabel: "true", warmPoolLabel: "true"}},
+		{ID: "other", Names: []string{"/cc-warm-3"}, Labels: map[string]string{platformManagedLabel: "true"}},
+	}
+
+	leftovers := leftoverWarmContainers(containers, map[string]bool{"recorded": true})
+	if len(leftovers) != 1 || leftovers[0].DockerID != "idle" || leftovers[0].Name != "cc-warm-1" {
+		t.Errorf("Expected only the unrecorded idle container, got %+v", leftovers)
+	}
+}
+
+func TestVolumeContainerName(t *testing.T) {
+	if got := volumeContainerName(&models.Container{Name: "app"}); got != "app" {
+		t.Errorf("Expected the container name, got %q", got)
+	}
+	if got := volumeContainerName(&models.Container{Name: "app", PoolName: "cc-warm-1"}); got != "cc-warm-1" {
+		t.Errorf("Expected the pool name, got %q", got)
+	}
+}
@@ -0,0 +1,349 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrWorkspaceArchiveNotFound is returned for an unknown workspace archive or a missing archive file
+	ErrWorkspaceArchiveNotFound = errors.New("workspace archive not found")
+	// ErrInvalidWorkspaceArchive is returned for an archive that isn't a single work directory
+	ErrInvalidWorkspaceArchive = errors.New("invalid workspace archive")
+	// ErrWorkspaceTooLarge is returned when a work directory exceeds constants.WorkspaceArchiveMaxBytes
+	ErrWorkspaceTooLarge = errors.New("workspace is too large to archive")
+)
+
+// WorkspaceArchiveResponse represents a preserved workspace
+type WorkspaceArchiveResponse struct {
+	ID            uint   `json:"id"`
+	ContainerName string `json:"container_name"`
+	GitRepoURL    string `json:"git_repo_url,omitempty"`
+	WorkDir       string `json:"work_dir"`
+	Files         int    `json:"files"`
+	SizeBytes     int64  `json:"size_bytes"`
+	ContentBytes  int64  `json:"content_bytes"`
+	CreatedAt     string `json:"created_at"`
+}
+
+func toWorkspaceArchiveResponse(a *models.WorkspaceArchive) *WorkspaceArchiveResponse {
+	return &WorkspaceArchiveResponse{
+		ID:            a.ID,
+		ContainerName: a.ContainerName,
+		GitRepoURL:    a.GitRepoURL,
+		WorkDir:       a.WorkDir,
+		Files:         a.Files,
+		SizeBytes:     a.SizeBytes,
+		ContentBytes:  a.ContentBytes,
+		CreatedAt:     a.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// WorkspaceRestoreResult summarizes a workspace archive restored into a container
+type WorkspaceRestoreResult struct {
+	ArchiveID uint   `json:"archive_id"`
+	WorkDir   string `json:"work_dir"`
+	Files     int    `json:"files"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// workspaceArchiveStats counts what rebaseWorkspaceArchive copied
+type workspaceArchiveStats struct {
+	files int
+	bytes int64
+}
+
+// rebaseWorkspaceArchive copies a tar of a work directory to w, renaming its top-level
+// directory to base. Every entry must be inside that one directory, without absolute
+// paths or "..". Ownership is dropped so restored files belong to the container user.
+func rebaseWorkspaceArchive(r io.Reader, w io.Writer, base string) (*workspaceArchiveStats, error) {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	stats := &workspaceArchiveStats{}
+	root := ""
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidWorkspaceArchive, err)
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		top, rest, err := splitWorkspaceEntry(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if root == "" {
+			root = top
+		} else if top != root {
+			return nil, fmt.Errorf("%w: entries under both %s and %s", ErrInvalidWorkspaceArchive, root, top)
+		}
+
+		out := &tar.Header{
+			Typeflag: header.Typeflag,
+			Name:     pathpkg.Join(base, rest),
+			Mode:     header.Mode & 0o7777,
+			ModTime:  header.ModTime,
+			Linkname: header.Linkname,
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			out.Name += "/"
+		case tar.TypeReg, tar.TypeRegA:
+			out.Typeflag = tar.TypeReg
+			out.Size = header.Size
+			if header.Size < 0 || stats.bytes+header.Size > constants.WorkspaceArchiveMaxBytes {
+				return nil, fmt.Errorf("%w: files exceed %d bytes", ErrWorkspaceTooLarge, int64(constants.WorkspaceArchiveMaxBytes))
+			}
+			stats.bytes += header.Size
+			stats.files++
+		case tar.TypeSymlink:
+			// Symlinks only resolve inside the container, so their targets are kept as they are
+		case tar.TypeLink:
+			linkTop, linkRest, err := splitWorkspaceEntry(header.Linkname)
+			if err != nil || linkTop != root {
+				return nil, fmt.Errorf("%w: hard link %s points outside the workspace", ErrInvalidWorkspaceArchive, header.Name)
+			}
+			out.Linkname = pathpkg.Join(base, linkRest)
+		default:
+			// Devices, FIFOs and sockets can't be meaningfully preserved
+			continue
+		}
+
+		if err := tw.WriteHeader(out); err != nil {
+			return nil, fmt.Errorf("failed to write workspace entry %s: %w", out.Name, err)
+		}
+		if out.Typeflag == tar.TypeReg {
+			if _, err := io.CopyN(tw, tr, header.Size); err != nil {
+				return nil, fmt.Errorf("%w: failed to read %s: %w", ErrInvalidWorkspaceArchive, header.Name, err)
+			}
+		}
+	}
+	if root == "" {
+		return nil, fmt.Errorf("%w: archive is empty", ErrInvalidWorkspaceArchive)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish workspace archive: %w", err)
+	}
+	return stats, nil
+}
+
+// splitWorkspaceEntry splits an entry name into its top-level directory and the path below it
+func splitWorkspaceEntry(raw string) (string, string, error) {
+	name := strings.TrimPrefix(raw, "./")
+	if name == "" || strings.HasPrefix(name, "/") || strings.ContainsRune(name, 0) {
+		return "", "", fmt.Errorf("%w: unsafe entry %q", ErrInvalidWorkspaceArchive, raw)
+	}
+	for _, part := range strings.Split(strings.TrimSuffix(name, "/"), "/") {
+		if part == ".." {
+			return "", "", fmt.Errorf("%w: unsafe entry %q", ErrInvalidWorkspaceArchive, raw)
+		}
+	}
+	top, rest, _ := strings.Cut(pathpkg.Clean(name), "/")
+	return top, rest, nil
+}
+
+// workspaceArchiveDir returns the retention directory for preserved workspaces
+func (s *ContainerService) workspaceArchiveDir() string {
+	dataDir := "./data"
+	if s.config != nil && s.config.DataDirectory != "" {
+		dataDir = s.config.DataDirectory
+	}
+	return filepath.Join(dataDir, constants.WorkspaceArchiveDirName)
+}
+
+// ExportWorkspace archives a container's work directory into the retention area
+func (s *ContainerService) ExportWorkspace(ctx context.Context, c *models.Container) (*models.WorkspaceArchive, error) {
+	reader, err := s.dockerClient.CopyFromContainer(ctx, c.DockerID, c.WorkDir)
+	if err != nil {
+		return nil, dockerError(fmt.Errorf("failed to read workspace %s: %w", c.WorkDir, err))
+	}
+	defer reader.Close()
+	return s.storeWorkspaceArchive(c, reader)
+}
+
+// storeWorkspaceArchive gzips a tar of a container's work directory into the retention
+// directory and records it. A partial file is removed when anything fails.
+func (s *ContainerService) storeWorkspaceArchive(c *models.Container, r io.Reader) (*models.WorkspaceArchive, error) {
+	dir := s.workspaceArchiveDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create workspace archive directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".export-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	gz := gzip.NewWriter(tmp)
+	stats, err := rebaseWorkspaceArchive(r, gz, pathpkg.Base(c.WorkDir))
+	if err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress workspace archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write workspace archive: %w", err)
+	}
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to write workspace archive: %w", err)
+	}
+
+	fileName := fmt.Sprintf("%s-%s.tar.gz", c.Name, time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, fileName)); err != nil {
+		return nil, fmt.Errorf("failed to store workspace archive: %w", err)
+	}
+
+	archive := &models.WorkspaceArchive{
+		ContainerName: c.Name,
+		GitRepoURL:    c.GitRepoURL,
+		WorkDir:       c.WorkDir,
+		FileName:      fileName,
+		Files:         stats.files,
+		SizeBytes:     info.Size(),
+		ContentBytes:  stats.bytes,
+	}
+	if err := s.db.Create(archive).Error; err != nil {
+		os.Remove(filepath.Join(dir, fileName))
+		return nil, err
+	}
+	return archive, nil
+}
+
+// DeleteContainerPreservingWorkspace archives the container's work directory and then deletes
+// the container. When the workspace can't be archived the container is left untouched.
+func (s *ContainerService) DeleteContainerPreservingWorkspace(ctx context.Context, id uint) (*WorkspaceArchiveResponse, error) {
+	container, err := s.GetContainer(id)
+	if err != nil {
+		return nil, err
+	}
+
+	exportCtx, cancel := context.WithTimeout(ctx, constants.WorkspaceExportTimeout)
+	archive, err := s.ExportWorkspace(exportCtx, container)
+	cancel()
+	if err != nil {
+		s.addLog(ctx, id, models.LogLevelError, models.LogStageStartup, fmt.Sprintf("Failed to preserve workspace, container not deleted: %v", err))
+		return nil, err
+	}
+	s.logger(ctx, id).Printf("Preserved workspace %s as archive %d (%d bytes)", container.WorkDir, archive.ID, archive.SizeBytes)
+
+	if err := s.DeleteContainer(ctx, id); err != nil {
+		return toWorkspaceArchiveResponse(archive), err
+	}
+	return toWorkspaceArchiveResponse(archive), nil
+}
+
+// ListWorkspaceArchives returns preserved workspaces, newest first
+func (s *ContainerService) ListWorkspaceArchives() ([]*WorkspaceArchiveResponse, error) {
+	var archives []models.WorkspaceArchive
+	if err := s.db.Order("created_at DESC, id DESC").Find(&archives).Error; err != nil {
+		return nil, err
+	}
+	responses := make([]*WorkspaceArchiveResponse, len(archives))
+	for i := range archives {
+		responses[i] = toWorkspaceArchiveResponse(&archives[i])
+	}
+	return responses, nil
+}
+
+// GetWorkspaceArchive returns a preserved workspace by ID
+func (s *ContainerService) GetWorkspaceArchive(id uint) (*models.WorkspaceArchive, error) {
+	var archive models.WorkspaceArchive
+	if err := s.db.First(&archive, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWorkspaceArchiveNotFound
+		}
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// OpenWorkspaceArchive opens the gzipped tar of a preserved workspace
+func (s *ContainerService) OpenWorkspaceArchive(id uint) (*models.WorkspaceArchive, *os.File, error) {
+	archive, err := s.GetWorkspaceArchive(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(filepath.Join(s.workspaceArchiveDir(), archive.FileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("%w: archive file %s is missing", ErrWorkspaceArchiveNotFound, archive.FileName)
+		}
+		return nil, nil, err
+	}
+	return archive, f, nil
+}
+
+// DeleteWorkspaceArchive removes a preserved workspace and its archive file
+func (s *ContainerService) DeleteWorkspaceArchive(id uint) error {
+	archive, err := s.GetWorkspaceArchive(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.workspaceArchiveDir(), archive.FileName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove workspace archive: %w", err)
+	}
+	return s.db.Delete(archive).Error
+}
+
+// RestoreWorkspace extracts a preserved workspace into a running container's work directory.
+// Files from the archive overwrite existing ones; other files are left alone.
+func (s *ContainerService) RestoreWorkspace(ctx context.Context, id, archiveID uint) (*WorkspaceRestoreResult, error) {
+	container, err := s.runningContainerForSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	_, f, err := s.OpenWorkspaceArchive(archiveID)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWorkspaceArchive, err)
+	}
+	defer gz.Close()
+
+	// Stream the rebased archive into the container instead of holding a whole workspace in memory
+	pr, pw := io.Pipe()
+	statsCh := make(chan *workspaceArchiveStats, 1)
+	go func() {
+		stats, err := rebaseWorkspaceArchive(gz, pw, pathpkg.Base(container.WorkDir))
+		statsCh <- stats
+		pw.CloseWithError(err)
+	}()
+	copyErr := s.dockerClient.CopyToContainer(ctx, container.DockerID, pathpkg.Dir(container.WorkDir), pr)
+	pr.CloseWithError(io.ErrClosedPipe)
+	stats := <-statsCh
+	if copyErr != nil {
+		return nil, fmt.Errorf("failed to restore workspace: %w", copyErr)
+	}
+	if stats == nil {
+		return nil, fmt.Errorf("%w: archive could not be read", ErrInvalidWorkspaceArchive)
+	}
+
+	s.addLog(ctx, id, models.LogLevelInfo, models.LogStageStartup,
+		fmt.Sprintf("Restored workspace archive %d into %s (%d files, %d bytes)", archiveID, container.WorkDir, stats.files, stats.bytes))
+	return &WorkspaceRestoreResult{ArchiveID: archiveID, WorkDir: container.WorkDir, Files: stats.files, Bytes: stats.bytes}, nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cc-platform/internal/config"
+	"cc-platform/internal/constants"
+	"cc-platform/internal/models"
+)
+
+func setupWorkspaceArchiveTestService(t *testing.T) *ContainerService {
+	t.Helper()
+	s := setupPauseTestService(t)
+	if err := s.db.AutoMigrate(&models.WorkspaceArchive{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	s.config = &config.Config{DataDirectory: t.TempDir()}
+	return s
+}
+
+func TestRebaseWorkspaceArchive_RenamesWorkDir(t *testing.T) {
+	// Docker's CopyFromContainer layout for /app: entries under app/
+	source := buildSnapshotTar(t, []snapshotEntry{
+		{name: "app/", typeflag: tar.TypeDir},
+		{name: "app/main.go", typeflag: tar.TypeReg, body: "package main"},
+		{name: "app/.git/", typeflag: tar.TypeDir},
+		{name: "app/.git/HEAD", typeflag: tar.TypeReg, body: "ref: refs/heads/main"},
+		{name: "app/latest", typeflag: tar.TypeSymlink, linkname: "main.go"},
+		{name: "app/copy.go", typeflag: tar.TypeLink, linkname: "app/main.go"},
+	})
+
+	var out bytes.Buffer
+	stats, err := rebaseWorkspaceArchive(bytes.NewReader(source), &out, "workspace")
+	if err != nil {
+		t.Fatalf("rebaseWorkspaceArchive error: %v", err)
+	}
+	if stats.files != 2 || stats.bytes != int64(len("package main")+len("ref: refs/heads/main")) {
+		t.Errorf("stats = %+v, want 2 files", *stats)
+	}
+
+	headers := readSnapshotTar(t, out.Bytes())
+	for _, name := range []string{"workspace/", "workspace/main.go", "workspace/.git/HEAD", "workspace/latest", "workspace/copy.go"} {
+		if headers[name] == nil {
+			t.Errorf("missing %s in rebased archive", name)
+		}
+	}
+	if got := headers["workspace/copy.go"].Linkname; got != "workspace/main.go" {
+		t.Errorf("hard link target = %q, want workspace/main.go", got)
+	}
+	if got := headers["workspace/latest"].Linkname; got != "main.go" {
+		t.Errorf("symlink target = %q, want main.go", got)
+	}
+	if h := headers["workspace/main.go"]; h.Uid != 0 || h.Uname != "" {
+		t.Errorf("ownership not dropped: uid=%d uname=%q", h.Uid, h.Uname)
+	}
+}
+
+func TestRebaseWorkspaceArchive_RejectsUnsafeEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []snapshotEntry
+	}{
+		{"parent traversal", []snapshotEntry{{name: "app/../etc/passwd", typeflag: tar.TypeReg, body: "x"}}},
+		{"absolute path", []snapshotEntry{{name: "/etc/passwd", typeflag: tar.TypeReg, body: "x"}}},
+		{"second top-level dir", []snapshotEntry{
+			{name: "app/a", typeflag: tar.TypeReg, body: "x"},
+			{name: "other/b", typeflag: tar.TypeReg, body: "x"},
+		}},
+		{"hard link outside", []snapshotEntry{
+			{name: "app/a", typeflag: tar.TypeReg, body: "x"},
+			{name: "app/b", typeflag: tar.TypeLink, linkname: "etc/shadow"},
+		}},
+		{"empty", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := buildSnapshotTar(t, tt.entries)
+			_, err := rebaseWorkspaceArchive(bytes.NewReader(source), io.Discard, "app")
+			if !errors.Is(err, ErrInvalidWorkspaceArchive) {
+				t.Errorf("error = %v, want ErrInvalidWorkspaceArchive", err)
+			}
+		})
+	}
+}
+
+func TestStoreWorkspaceArchive_Lifecycle(t *testing.T) {
+	s := setupWorkspaceArchiveTestService(t)
+	c := createPauseTestContainer(t, s, "demo", models.ContainerStatusRunning)
+	c.WorkDir = "/app"
+	c.GitRepoURL = "https://github.com/example/demo"
+
+	source := buildSnapshotTar(t, []snapshotEntry{
+		{name: "app/", typeflag: tar.TypeDir},
+		{name: "app/README.md", typeflag: tar.TypeReg, body: "uncommitted change"},
+	})
+	archive, err := s.storeWorkspaceArchive(c, bytes.NewReader(source))
+	if err != nil {
+		t.Fatalf("storeWorkspaceArchive error: %v", err)
+	}
+	if archive.ContainerName != "demo" || archive.WorkDir != "/app" || archive.Files != 1 || archive.SizeBytes == 0 {
+		t.Errorf("unexpected archive record: %+v", archive)
+	}
+
+	// The temp file was renamed into place, nothing else is left behind
+	entries, err := os.ReadDir(filepath.Join(s.config.DataDirectory, constants.WorkspaceArchiveDirName))
+	if err != nil || len(entries) != 1 || entries[0].Name() != archive.FileName {
+		t.Fatalf("retention directory = %v (err %v), want only %s", entries, err, archive.FileName)
+	}
+
+	list, err := s.ListWorkspaceArchives()
+	if err != nil || len(list) != 1 || list[0].ID != archive.ID {
+		t.Fatalf("ListWorkspaceArchives = %v, %v", list, err)
+	}
+
+	_, f, err := s.OpenWorkspaceArchive(archive.ID)
+	if err != nil {
+		t.Fatalf("OpenWorkspaceArchive error: %v", err)
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("archive is not gzipped: %v", err)
+	}
+	data, _ := io.ReadAll(gz)
+	f.Close()
+	if headers := readSnapshotTar(t, data); headers["app/README.md"] == nil {
+		t.Errorf("stored archive is missing app/README.md: %v", headers)
+	}
+
+	if err := s.DeleteWorkspaceArchive(archive.ID); err != nil {
+		t.Fatalf("DeleteWorkspaceArchive error: %v", err)
+	}
+	if _, _, err := s.OpenWorkspaceArchive(archive.ID); !errors.Is(err, ErrWorkspaceArchiveNotFound) {
+		t.Errorf("OpenWorkspaceArchive after delete = %v, want ErrWorkspaceArchiveNotFound", err)
+	}
+	entries, _ = os.ReadDir(filepath.Join(s.config.DataDirectory, constants.WorkspaceArchiveDirName))
+	if len(entries) != 0 {
+		t.Errorf("archive file left behind: %v", entries)
+	}
+}
+
+func TestStoreWorkspaceArchive_InvalidArchiveLeavesNothing(t *testing.T) {
+	s := setupWorkspaceArchiveTestService(t)
+	c := createPauseTestContainer(t, s, "demo", models.ContainerStatusRunning)
+	c.WorkDir = "/app"
+
+	source := buildSnapshotTar(t, []snapshotEntry{{name: "../escape", typeflag: tar.TypeReg, body: "x"}})
+	if _, err := s.storeWorkspaceArchive(c, bytes.NewReader(source)); !errors.Is(err, ErrInvalidWorkspaceArchive) {
+		t.Fatalf("error = %v, want ErrInvalidWorkspaceArchive", err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(s.config.DataDirectory, constants.WorkspaceArchiveDirName))
+	if len(entries) != 0 {
+		t.Errorf("partial archive left behind: %v", entries)
+	}
+	var count int64
+	s.db.Model(&models.WorkspaceArchive{}).Count(&count)
+	if count != 0 {
+		t.Errorf("archive records = %d, want 0", count)
+	}
+}
+
+func TestDeleteContainerPreservingWorkspace_UnknownContainer(t *testing.T) {
+	s := setupWorkspaceArchiveTestService(t)
+	if _, err := s.DeleteContainerPreservingWorkspace(t.Context(), 999); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("error = %v, want ErrContainerNotFound", err)
+	}
+}
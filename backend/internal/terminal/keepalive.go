@@ -0,0 +1,60 @@
+package terminal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketSettings holds the keepalive timings and read limit of a WebSocket connection
+type WebSocketSettings struct {
+	WriteWait      time.Duration // Time allowed to write a message
+	PongWait       time.Duration // Time allowed between pongs (or any client message) before the connection is dropped
+	PingPeriod     time.Duration // How often pings are sent; must be shorter than PongWait
+	MaxMessageSize int64         // Largest message accepted from the client
+}
+
+// DefaultWebSocketSettings returns the default keepalive timings with the given read limit
+func DefaultWebSocketSettings(readLimit int64) WebSocketSettings {
+	return WebSocketSettings{
+		WriteWait:      writeWait,
+		PongWait:       pongWait,
+		PingPeriod:     pingPeriod,
+		MaxMessageSize: readLimit,
+	}
+}
+
+// Validate checks that every value is positive and pings are sent before the pong wait runs out
+func (s WebSocketSettings) Validate() error {
+	if s.WriteWait <= 0 || s.PongWait <= 0 || s.PingPeriod <= 0 {
+		return fmt.Errorf("write wait, pong wait and ping period must be positive")
+	}
+	if s.PingPeriod >= s.PongWait {
+		return fmt.Errorf("ping period %s must be less than pong wait %s", s.PingPeriod, s.PongWait)
+	}
+	if s.MaxMessageSize <= 0 {
+		return fmt.Errorf("max message size must be positive, got %d", s.MaxMessageSize)
+	}
+	return nil
+}
+
+// Apply sets the read limit and read deadline of a connection and extends the deadline on every pong
+func (s WebSocketSettings) Apply(conn *websocket.Conn) {
+	conn.SetReadLimit(s.MaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(s.PongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(s.PongWait))
+		return nil
+	})
+}
+
+// SetWebSocketSettings replaces the keepalive timings and read limit of new terminal connections;
+// invalid settings are rejected and the current ones kept
+func (s *TerminalService) SetWebSocketSettings(settings WebSocketSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	s.wsSettings = settings
+	return nil
+}
@@ -0,0 +1,134 @@
+package terminal
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketSettings_Validate(t *testing.T) {
+	valid := WebSocketSettings{WriteWait: time.Second, PongWait: 30 * time.Second, PingPeriod: 20 * time.Second, MaxMessageSize: 1024}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected valid settings, got %v", err)
+	}
+	if err := DefaultWebSocketSettings(maxMessageSize).Validate(); err != nil {
+		t.Errorf("Expected the defaults to be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*WebSocketSettings)
+	}{
+		{"ping period equals pong wait", func(s *WebSocketSettings) { s.PingPeriod = s.PongWait }},
+		{"ping period above pong wait", func(s *WebSocketSettings) { s.PingPeriod = time.Minute }},
+		{"zero write wait", func(s *WebSocketSettings) { s.WriteWait = 0 }},
+		{"negative pong wait", func(s *WebSocketSettings) { s.PongWait = -time.Second }},
+		{"zero max message size", func(s *WebSocketSettings) { s.MaxMessageSize = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := valid
+			tt.modify(&settings)
+			if err := settings.Validate(); err == nil {
+				t.Errorf("Expected %+v to be rejected", settings)
+			}
+		})
+	}
+
+	service := &TerminalService{wsSettings: DefaultWebSocketSettings(maxMessageSize)}
+	if err := service.SetWebSocketSettings(WebSocketSettings{WriteWait: time.Second, PongWait: time.Second, PingPeriod: 2 * time.Second, MaxMessageSize: 1}); err == nil {
+		t.Error("Expected SetWebSocketSettings to reject a ping period above the pong wait")
+	}
+	if service.wsSettings != DefaultWebSocketSettings(maxMessageSize) {
+		t.Errorf("Expected rejected settings to keep the current ones, got %+v", service.wsSettings)
+	}
+	if err := service.SetWebSocketSettings(valid); err != nil || service.wsSettings != valid {
+		t.Errorf("Expected valid settings to be applied, got %+v (%v)", service.wsSettings, err)
+	}
+}
+
+// serveWithSettings applies settings to every upgraded connection and reports how its first read ends
+func serveWithSettings(t *testing.T, settings WebSocketSettings) (*websocket.Conn, <-chan error) {
+	t.Helper()
+	readErr := make(chan error, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			readErr <- err
+			return
+		}
+		defer conn.Close()
+		settings.Apply(conn)
+		_, _, err = conn.ReadMessage()
+		readErr <- err
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, readErr
+}
+
+func TestWebSocketSettings_ApplyReadDeadline(t *testing.T) {
+	settings := WebSocketSettings{WriteWait: time.Second, PongWait: 150 * time.Millisecond, PingPeriod: 100 * time.Millisecond, MaxMessageSize: 1024}
+
+	// A silent client is dropped once the pong wait runs out
+	_, readErr := serveWithSettings(t, settings)
+	start := time.Now()
+	select {
+	case err := <-readErr:
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Fatalf("Expected a read timeout, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("Expected the read to time out after the configured pong wait, took %s", elapsed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the configured pong wait to end the read")
+	}
+
+	// Each pong pushes the deadline out by the pong wait
+	conn, readErr := serveWithSettings(t, settings)
+	start = time.Now()
+	for time.Since(start) < 4*settings.PongWait {
+		if err := conn.WriteControl(websocket.PongMessage, nil, time.Now().Add(time.Second)); err != nil {
+			t.Fatalf("WriteControl failed: %v", err)
+		}
+		select {
+		case err := <-readErr:
+			t.Fatalf("Expected pongs to keep the connection open, read ended after %s: %v", time.Since(start), err)
+		case <-time.After(settings.PongWait / 3):
+		}
+	}
+}
+
+func TestWebSocketSettings_ApplyReadLimit(t *testing.T) {
+	settings := WebSocketSettings{WriteWait: time.Second, PongWait: 5 * time.Second, PingPeriod: time.Second, MaxMessageSize: 16}
+
+	conn, readErr := serveWithSettings(t, settings)
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("x", 17))); err != nil {
+		t.Fatalf("WriteMessage failed: %v", err)
+	}
+	if err := <-readErr; !errors.Is(err, websocket.ErrReadLimit) {
+		t.Errorf("Expected a message above the limit to be refused, got %v", err)
+	}
+
+	conn, readErr = serveWithSettings(t, settings)
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("x", 16))); err != nil {
+		t.Fatalf("WriteMessage failed: %v", err)
+	}
+	if err := <-readErr; err != nil {
+		t.Errorf("Expected a message at the limit to be read, got %v", err)
+	}
+}
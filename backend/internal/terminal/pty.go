@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"time"
 
@@ -235,11 +236,23 @@ func (m *PTYManager) Close() error {
 }
 
 
+// shellCommand returns the terminal shell for a container. Containers claimed from the warm
+// pool were created with a generic working directory, so their shell changes to the real one.
+func (m *PTYManager) shellCommand(containerID uint) []string {
+	var c models.Container
+	if m.db == nil || m.db.Select("pool_name", "work_dir").First(&c, containerID).Error != nil ||
+		c.PoolName == "" || c.WorkDir == "" {
+		return []string{"/bin/bash"}
+	}
+	dir := "'" + strings.ReplaceAll(c.WorkDir, "'", `'\''`) + "'"
+	return []string{"/bin/bash", "-c", fmt.Sprintf("cd %s 2>/dev/null; exec /bin/bash", dir)}
+}
+
 // CreateSession creates a new PTY session for a container
 func (m *PTYManager) CreateSession(ctx context.Context, dockerID string, containerID uint, cols, rows uint) (*PTYSession, error) {
 	// Create exec instance with PTY
 	execConfig := types.ExecConfig{
-		Cmd:          []string{"/bin/bash"},
+		Cmd:          m.shellCommand(containerID),
 		AttachStdin:  true,
 		AttachStdout: true,
 		AttachStderr: true,
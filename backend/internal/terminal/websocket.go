@@ -41,7 +41,7 @@ const (
 	MessageTypeTaskRemove  = "task_remove"
 	MessageTypeTaskReorder = "task_reorder"
 
-	// WebSocket settings
+	// Default WebSocket settings
 	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
@@ -107,6 +107,7 @@ type StrategyTriggeredData struct {
 // TerminalService handles WebSocket terminal connections
 type TerminalService struct {
 	ptyManager *PTYManager
+	wsSettings WebSocketSettings
 	mu         sync.RWMutex
 }
 
@@ -119,6 +120,7 @@ func NewTerminalService(db *gorm.DB) (*TerminalService, error) {
 
 	return &TerminalService{
 		ptyManager: ptyManager,
+		wsSettings: DefaultWebSocketSettings(maxMessageSize),
 	}, nil
 }
 
@@ -153,12 +155,7 @@ func (s *TerminalService) HandleConnection(ctx context.Context, conn *websocket.
 	var writeMu sync.Mutex
 
 	// Configure WebSocket
-	conn.SetReadLimit(maxMessageSize)
-	conn.SetReadDeadline(time.Now().Add(pongWait))
-	conn.SetPongHandler(func(string) error {
-		conn.SetReadDeadline(time.Now().Add(pongWait))
-		return nil
-	})
+	s.wsSettings.Apply(conn)
 
 	// Default terminal size
 	cols := uint(80)
@@ -172,7 +169,7 @@ func (s *TerminalService) HandleConnection(ctx context.Context, conn *websocket.
 			Type:  MessageTypeError,
 			Error: fmt.Sprintf("Failed to create terminal session: %v", err),
 		}
-		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		conn.SetWriteDeadline(time.Now().Add(s.wsSettings.WriteWait))
 		conn.WriteJSON(msg)
 		return err
 	}
@@ -258,7 +255,7 @@ func (s *TerminalService) sendHistoryInChunks(conn *websocket.Conn, writeMu *syn
 
 		// Protect write with mutex
 		writeMu.Lock()
-		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		conn.SetWriteDeadline(time.Now().Add(s.wsSettings.WriteWait))
 		err := conn.WriteJSON(TerminalMessage{
 			Type:        MessageTypeHistory,
 			Data:        string(history[i:end]),
@@ -302,7 +299,7 @@ func (s *TerminalService) forwardOutputToWebSocket(conn *websocket.Conn, writeMu
 				Data: string(data),
 			}
 			writeMu.Lock()
-			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			conn.SetWriteDeadline(time.Now().Add(s.wsSettings.WriteWait))
 			err := conn.WriteJSON(msg)
 			writeMu.Unlock()
 			if err != nil {
@@ -375,7 +372,7 @@ func (s *TerminalService) readFromWebSocket(ctx context.Context, conn *websocket
 
 // pingLoop sends periodic ping messages
 func (s *TerminalService) pingLoop(conn *websocket.Conn, writeMu *sync.Mutex, done chan struct{}) {
-	ticker := time.NewTicker(pingPeriod)
+	ticker := time.NewTicker(s.wsSettings.PingPeriod)
 	defer ticker.Stop()
 
 	for {
@@ -384,7 +381,7 @@ func (s *TerminalService) pingLoop(conn *websocket.Conn, writeMu *sync.Mutex, do
 			return
 		case <-ticker.C:
 			writeMu.Lock()
-			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			conn.SetWriteDeadline(time.Now().Add(s.wsSettings.WriteWait))
 			err := conn.WriteMessage(websocket.PingMessage, nil)
 			writeMu.Unlock()
 			if err != nil {
@@ -396,7 +393,7 @@ func (s *TerminalService) pingLoop(conn *websocket.Conn, writeMu *sync.Mutex, do
 
 // sendMessage sends a message to the WebSocket
 func (s *TerminalService) sendMessage(conn *websocket.Conn, msg TerminalMessage) {
-	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	conn.SetWriteDeadline(time.Now().Add(s.wsSettings.WriteWait))
 	conn.WriteJSON(msg)
 }
 
@@ -404,7 +401,7 @@ func (s *TerminalService) sendMessage(conn *websocket.Conn, msg TerminalMessage)
 func (s *TerminalService) sendMessageWithLock(conn *websocket.Conn, writeMu *sync.Mutex, msg TerminalMessage) {
 	writeMu.Lock()
 	defer writeMu.Unlock()
-	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	conn.SetWriteDeadline(time.Now().Add(s.wsSettings.WriteWait))
 	conn.WriteJSON(msg)
 }
 
@@ -414,7 +411,7 @@ func (s *TerminalService) sendError(conn *websocket.Conn, errMsg string) {
 		Type:  MessageTypeError,
 		Error: errMsg,
 	}
-	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	conn.SetWriteDeadline(time.Now().Add(s.wsSettings.WriteWait))
 	conn.WriteJSON(msg)
 }
 
@@ -426,7 +423,7 @@ func (s *TerminalService) sendErrorWithLock(conn *websocket.Conn, writeMu *sync.
 		Type:  MessageTypeError,
 		Error: errMsg,
 	}
-	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	conn.SetWriteDeadline(time.Now().Add(s.wsSettings.WriteWait))
 	conn.WriteJSON(msg)
 }
 
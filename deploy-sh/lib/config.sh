@@ -166,7 +166,13 @@ run_config_wizard() {
     # CORS 允许的 Origin
     log_info "CORS 配置 (WebSocket 和 API 跨域访问)"
     echo ""
-    ALLOWED_ORIGINS=$(read_input "允许的 Origin (* 表示允许所有)" "${ALLOWED_ORIGINS:-*}")
+    ALLOWED_ORIGINS=$(read_input "允许的 Origin (逗号分隔，留空仅允许同源，* 表示允许所有)" "${ALLOWED_ORIGINS:-}")
+    CORS_ALLOW_ALL_ORIGINS=false
+    if [ "$ALLOWED_ORIGINS" = "*" ]; then
+        log_warn "允许所有 Origin 仅建议用于本地开发"
+        ALLOWED_ORIGINS=""
+        CORS_ALLOW_ALL_ORIGINS=true
+    fi
 
     echo ""
 
@@ -285,6 +291,7 @@ run_config_wizard() {
         printf 'JWT_SECRET=%s\n' "$JWT_SECRET"
         printf 'ENCRYPTION_KEY=%s\n' "$ENCRYPTION_KEY"
         printf 'ALLOWED_ORIGINS=%s\n' "$ALLOWED_ORIGINS"
+        printf 'CORS_ALLOW_ALL_ORIGINS=%s\n' "$CORS_ALLOW_ALL_ORIGINS"
         printf '\n'
 
         printf '# ============================================\n'
@@ -3,8 +3,9 @@
  */
 
 import { useState, useEffect, useCallback } from 'react'
-import { Loader2, Download, AlertCircle, CheckCircle2 } from 'lucide-react'
+import { Loader2, Download, AlertCircle, CheckCircle2, FileDiff } from 'lucide-react'
 import { Button } from '@/components/ui/button'
+import { apiErrorMessage } from '@/utils/errorHandler'
 import {
   Dialog,
   DialogContent,
@@ -16,7 +17,7 @@ import {
 import { Checkbox } from '@/components/ui/checkbox'
 import { claudeConfigApi } from '@/services/claudeConfigApi'
 import { containerApi } from '@/services/api'
-import type { ClaudeConfigTemplate, ConfigType, InjectionStatus } from '@/types/claudeConfig'
+import type { ClaudeConfigTemplate, ConfigType, InjectionStatus, TemplateDiff } from '@/types/claudeConfig'
 
 interface ConfigInjectionDialogProps {
   containerId: number
@@ -36,6 +37,9 @@ const configTypeLabels: Record<ConfigType, string> = {
   GEMINI_ENV: 'Gemini Environment',
 }
 
+// Types written to a single file, whose re-injection can be previewed as a diff
+const diffableTypes: ConfigType[] = ['CLAUDE_MD', 'SKILL', 'COMMAND', 'CODEX_CONFIG']
+
 const configTypeOrder: ConfigType[] = ['CLAUDE_MD', 'SKILL', 'MCP', 'COMMAND', 'CODEX_CONFIG', 'CODEX_AUTH', 'GEMINI_ENV']
 
 export function ConfigInjectionDialog({
@@ -50,6 +54,8 @@ export function ConfigInjectionDialog({
   const [injecting, setInjecting] = useState(false)
   const [error, setError] = useState<string | null>(null)
   const [result, setResult] = useState<InjectionStatus | null>(null)
+  const [preview, setPreview] = useState<{ id: number; diff?: TemplateDiff; error?: string } | null>(null)
+  const [previewLoading, setPreviewLoading] = useState(false)
 
   // Load configs when dialog opens
   useEffect(() => {
@@ -58,6 +64,7 @@ export function ConfigInjectionDialog({
       setSelectedIds(new Set())
       setError(null)
       setResult(null)
+      setPreview(null)
     }
   }, [open])
 
@@ -114,12 +121,30 @@ export function ConfigInjectionDialog({
       const response = await containerApi.injectConfigs(containerId, Array.from(selectedIds))
       setResult(response.data.status as InjectionStatus)
     } catch (err: any) {
-      setError(err.response?.data?.error || err.message || 'Failed to inject configurations')
+      setError(apiErrorMessage(err.response?.data) || err.message || 'Failed to inject configurations')
     } finally {
       setInjecting(false)
     }
   }
 
+  // Show what re-injecting a template would change, or hide the preview when it is open
+  const handlePreview = async (id: number) => {
+    if (preview?.id === id) {
+      setPreview(null)
+      return
+    }
+    setPreviewLoading(true)
+    setPreview({ id })
+    try {
+      const response = await containerApi.diffTemplate(containerId, id)
+      setPreview({ id, diff: response.data })
+    } catch (err: any) {
+      setPreview({ id, error: apiErrorMessage(err.response?.data) || err.message || 'Failed to load the diff' })
+    } finally {
+      setPreviewLoading(false)
+    }
+  }
+
   // Group configs by type
   const groupedConfigs = configTypeOrder.reduce((acc, type) => {
     const typeConfigs = configs.filter(c => c.config_type === type)
@@ -224,23 +249,68 @@ export function ConfigInjectionDialog({
                     </div>
                     <div className="ml-6 space-y-1">
                       {typeConfigs.map(config => (
-                        <div key={config.id} className="flex items-start gap-2">
-                          <Checkbox
-                            id={`config-${config.id}`}
-                            checked={selectedIds.has(config.id)}
-                            onCheckedChange={() => handleToggle(config.id)}
-                          />
-                          <label
-                            htmlFor={`config-${config.id}`}
-                            className="text-sm cursor-pointer flex-1"
-                          >
-                            <span>{config.name}</span>
-                            {config.description && (
-                              <span className="text-muted-foreground ml-2">
-                                - {config.description}
-                              </span>
+                        <div key={config.id}>
+                          <div className="flex items-start gap-2">
+                            <Checkbox
+                              id={`config-${config.id}`}
+                              checked={selectedIds.has(config.id)}
+                              onCheckedChange={() => handleToggle(config.id)}
+                            />
+                            <label
+                              htmlFor={`config-${config.id}`}
+                              className="text-sm cursor-pointer flex-1"
+                            >
+                              <span>{config.name}</span>
+                              {config.description && (
+                                <span className="text-muted-foreground ml-2">
+                                  - {config.description}
+                                </span>
+                              )}
+                            </label>
+                            {diffableTypes.includes(config.config_type) && !config.is_archive && (
+                              <Button
+                                variant="ghost"
+                                size="sm"
+                                className="h-6 px-2 text-xs"
+                                onClick={() => handlePreview(config.id)}
+                                disabled={previewLoading && preview?.id !== config.id}
+                              >
+                                <FileDiff className="h-3 w-3 mr-1" />
+                                Diff
+                              </Button>
                             )}
-                          </label>
+                          </div>
+                          {preview?.id === config.id && (
+                            <div className="mt-1 mb-2 ml-6 text-xs">
+                              {previewLoading ? (
+                                <Loader2 className="h-4 w-4 animate-spin text-muted-foreground" />
+                              ) : preview.error ? (
+                                <div className="text-destructive">{preview.error}</div>
+                              ) : preview.diff && !preview.diff.changed ? (
+                                <div className="text-muted-foreground">{preview.diff.path} already matches this template</div>
+                              ) : preview.diff && (
+                                <>
+                                  <div className="text-muted-foreground mb-1">
+                                    {preview.diff.exists ? `Changes to ${preview.diff.path}` : `${preview.diff.path} will be created`}
+                                  </div>
+                                  <pre className="max-h-64 overflow-auto rounded bg-muted p-2 font-mono">
+                                    {preview.diff.diff.split('\n').map((line, i) => (
+                                      <div
+                                        key={i}
+                                        className={
+                                          line.startsWith('+') && !line.startsWith('+++') ? 'text-green-500'
+                                            : line.startsWith('-') && !line.startsWith('---') ? 'text-red-500'
+                                              : line.startsWith('@@') ? 'text-blue-500' : ''
+                                        }
+                                      >
+                                        {line || ' '}
+                                      </div>
+                                    ))}
+                                  </pre>
+                                </>
+                              )}
+                            </div>
+                          )}
                         </div>
                       ))}
                     </div>
@@ -120,6 +120,9 @@ const validateMCPContent = (content: string): string | null => {
     if (!parsed.args || !Array.isArray(parsed.args)) {
       return 'MCP configuration must have an "args" field (array)'
     }
+    if (parsed.scope !== undefined && parsed.scope !== 'user' && parsed.scope !== 'project') {
+      return 'MCP "scope" must be "user" or "project"'
+    }
     return null
   } catch {
     return 'Invalid JSON format'
@@ -71,6 +71,9 @@ interface Container {
   status: string
   init_status: string
   init_message?: string
+  init_progress?: number
+  claude_version?: string
+  claude_version_pin?: string
   git_repo_url?: string
   git_repo_name?: string
   created_at: string
@@ -139,6 +142,7 @@ export default function Dashboard() {
     // New fields for claude config management
     skipGitRepo: false,
     enableYoloMode: false,
+    confirmYolo: false,
     runAsRoot: false,
     autoInjectAllSkills: true,
     selectedClaudeMD: undefined as number | undefined,
@@ -269,6 +273,10 @@ export default function Dashboard() {
   }
 
   const handleCreate = async () => {
+    // The server rejects YOLO mode unless its risks are explicitly acknowledged
+    if (formData.enableYoloMode && !formData.confirmYolo) {
+      return
+    }
     setCreating(true)
     try {
       let gitRepoUrl = ''
@@ -351,6 +359,7 @@ export default function Dashboard() {
         commandProfileId: undefined,
         skipGitRepo: false,
         enableYoloMode: false,
+        confirmYolo: false,
         runAsRoot: false,
         autoInjectAllSkills: true,
         selectedClaudeMD: undefined,
@@ -388,6 +397,15 @@ export default function Dashboard() {
     }
   }
 
+  const handleCancelInit = async (id: number) => {
+    try {
+      await containerApi.cancelInit(id)
+      fetchContainers()
+    } catch (err) {
+      console.error('Failed to cancel initialization', err)
+    }
+  }
+
   const handleDelete = async (id: number) => {
     try {
       await containerApi.delete(id)
@@ -431,7 +449,7 @@ export default function Dashboard() {
               <Loader2 className="h-4 w-4 animate-spin" />
               Starting...
             </div>
-            <Progress value={10} className="h-1" />
+            <Progress value={container.init_progress ?? 10} className="h-1" />
           </div>
         )
       case 'cloning':
@@ -440,8 +458,16 @@ export default function Dashboard() {
             <div className="flex items-center gap-2 text-sm text-muted-foreground">
               <Loader2 className="h-4 w-4 animate-spin" />
               Cloning repository...
+              <Button
+                variant="ghost"
+                size="sm"
+                className="ml-auto h-6 px-2 text-xs"
+                onClick={() => handleCancelInit(container.id)}
+              >
+                Cancel
+              </Button>
             </div>
-            <Progress value={40} className="h-1" />
+            <Progress value={container.init_progress ?? 40} className="h-1" />
           </div>
         )
       case 'initializing':
@@ -451,7 +477,7 @@ export default function Dashboard() {
               <Loader2 className="h-4 w-4 animate-spin" />
               Initializing environment...
             </div>
-            <Progress value={70} className="h-1" />
+            <Progress value={container.init_progress ?? 70} className="h-1" />
           </div>
         )
       case 'ready':
@@ -475,6 +501,20 @@ export default function Dashboard() {
             )}
           </div>
         )
+      case 'cancelled':
+        return (
+          <div className="space-y-1">
+            <div className="flex items-center gap-2 text-sm text-muted-foreground">
+              <XCircle className="h-4 w-4" />
+              Cancelled
+            </div>
+            {container.init_message && (
+              <p className="text-xs text-muted-foreground truncate">
+                {container.init_message}
+              </p>
+            )}
+          </div>
+        )
       default:
         return null
     }
@@ -948,7 +988,7 @@ export default function Dashboard() {
                           id="enableYoloMode"
                           checked={formData.enableYoloMode}
                           onCheckedChange={(checked) =>
-                            setFormData({ ...formData, enableYoloMode: checked === true })
+                            setFormData({ ...formData, enableYoloMode: checked === true, confirmYolo: false })
                           }
                           data-testid="yolo-mode-checkbox"
                         />
@@ -965,6 +1005,19 @@ export default function Dashboard() {
                             YOLO mode (--dangerously-skip-permissions) allows Claude Code to execute commands without permission prompts.
                             This can be dangerous as it bypasses all safety checks. Only enable this if you trust the code being executed.
                           </AlertDescription>
+                          <div className="mt-2 flex items-center space-x-2">
+                            <Checkbox
+                              id="confirmYolo"
+                              checked={formData.confirmYolo}
+                              onCheckedChange={(checked) =>
+                                setFormData({ ...formData, confirmYolo: checked === true })
+                              }
+                              data-testid="yolo-mode-confirm"
+                            />
+                            <label htmlFor="confirmYolo" className="text-sm leading-none">
+                              I understand the risks and want to skip all permission prompts
+                            </label>
+                          </div>
                         </Alert>
                       )}
                     </div>
@@ -1651,7 +1704,7 @@ export default function Dashboard() {
             <Button variant="outline" onClick={() => setCreateDialogOpen(false)}>
               Cancel
             </Button>
-            <Button onClick={handleCreate} disabled={creating || !formData.name}>
+            <Button onClick={handleCreate} disabled={creating || !formData.name || (formData.enableYoloMode && !formData.confirmYolo)}>
               {creating && <Loader2 className="h-4 w-4 mr-2 animate-spin" />}
               Create
             </Button>
@@ -15,6 +15,7 @@ import { Label } from '@/components/ui/label'
 import { Card, CardContent, CardDescription, CardHeader, CardTitle } from '@/components/ui/card'
 import { authApi } from '@/services/api'
 import { ServerAddressInput } from '@/components/ServerAddressInput'
+import { apiErrorMessage } from '@/utils/errorHandler'
 import {
   getServerAddress,
   setServerAddress,
@@ -120,7 +121,7 @@ export default function Login() {
       navigate('/')
     } catch (err: unknown) {
       const error = err as { response?: { data?: { error?: string } } }
-      setError(error.response?.data?.error || 'Login failed')
+      setError(apiErrorMessage(error.response?.data) || 'Login failed')
     } finally {
       setLoading(false)
     }
@@ -1,7 +1,9 @@
 import axios, { AxiosError, AxiosResponse } from 'axios'
 import { toast } from '@/components/ui/toast'
 import { getApiBaseUrl } from './serverAddressManager'
+import { apiErrorMessage, type ApiErrorBody } from '@/utils/errorHandler'
 import type { ConversationInfo, TerminalSessionInfo } from '@/types/conversation'
+import type { TemplateDiff } from '@/types/claudeConfig'
 
 // ==================== Base Axios Instance ====================
 
@@ -31,7 +33,7 @@ api.interceptors.request.use((config) => {
 // Response interceptor to handle auth errors and show error toasts
 api.interceptors.response.use(
   (response: AxiosResponse) => response,
-  (error: AxiosError<{ error?: string }>) => {
+  (error: AxiosError<{ error?: string | ApiErrorBody }>) => {
     // Handle auth errors
     if (error.response?.status === 401) {
       if (window.location.pathname !== '/login') {
@@ -40,7 +42,7 @@ api.interceptors.response.use(
       return Promise.reject(error)
     }
 
-    const errorMessage = error.response?.data?.error || error.message || 'Request failed'
+    const errorMessage = apiErrorMessage(error.response?.data) || error.message || 'Request failed'
     const statusCode = error.response?.status
 
     if (axios.isCancel(error)) {
@@ -78,6 +80,7 @@ export const authApi = {
     api.post('/auth/login', { username, password }),
   logout: () => api.post('/auth/logout'),
   verify: () => api.get('/auth/verify'),
+  refresh: () => api.post('/auth/refresh'),
 }
 
 // Settings API (legacy)
@@ -122,6 +125,26 @@ export interface StartupCommandProfile {
   updated_at: string
 }
 
+export interface RegistryCredentialItem {
+  id: number
+  registry: string
+  username: string
+  remark?: string
+  created_at: string
+  updated_at: string
+}
+
+export interface WorkspaceArchive {
+  id: number
+  container_name: string
+  git_repo_url?: string
+  work_dir: string
+  files: number
+  size_bytes: number
+  content_bytes: number
+  created_at: string
+}
+
 // Config Profile API (new multi-config)
 export const configProfileApi = {
   // GitHub Tokens
@@ -133,6 +156,12 @@ export const configProfileApi = {
   deleteGitHubToken: (id: number) => api.delete(`/settings/github-tokens/${id}`),
   setDefaultGitHubToken: (id: number) => api.put(`/settings/github-tokens/${id}/default`),
 
+  // Private registry logins for custom container images
+  listRegistryCredentials: () => api.get<RegistryCredentialItem[]>('/settings/registry-credentials'),
+  saveRegistryCredential: (data: { registry: string; username: string; password: string; remark?: string }) =>
+    api.post<RegistryCredentialItem>('/settings/registry-credentials', data),
+  deleteRegistryCredential: (id: number) => api.delete(`/settings/registry-credentials/${id}`),
+
   // Env Profiles
   listEnvProfiles: () => api.get<EnvVarsProfile[]>('/settings/env-profiles'),
   createEnvProfile: (data: { name: string; description?: string; env_vars: string; api_url_var_name?: string; api_token_var_name?: string; is_default?: boolean }) =>
@@ -167,6 +196,13 @@ export interface PortMapping {
   host_port: number
 }
 
+export interface ResourceProfile {
+  name: string
+  cpu_limit: number
+  memory_limit: number // MB
+  default: boolean
+}
+
 export interface ProxyConfig {
   enabled: boolean
   domain?: string
@@ -196,6 +232,49 @@ export interface DockerContainerInfo {
   is_managed: boolean
 }
 
+// Result of pruning orphaned platform containers
+export interface PruneContainersResult {
+  dry_run: boolean
+  containers: { id: string; name: string; state: string; removed: boolean; error?: string }[]
+}
+
+// How to reach a container's code-server; password is only set with password auth
+export interface CodeServerAccess {
+  url: string
+  auth: 'none' | 'password'
+  password?: string
+}
+
+// Outcome of launching or probing an MCP server inside a container
+export interface MCPServerTestResult {
+  name: string
+  scope: 'user' | 'project'
+  transport: 'stdio' | 'sse' | 'http'
+  ok: boolean
+  message: string
+  exit_code?: number
+  http_status?: number
+  stderr?: string
+  duration_ms: number
+}
+
+// Current mode and active sessions of a container
+export interface ContainerModeInventory {
+  container_id: number
+  mode: 'tui' | 'headless'
+  mode_recorded: boolean
+  terminal_session_ids: string[]
+  terminal_session_count: number
+  headless_sessions: {
+    session_id: string
+    conversation_id: number
+    state: string
+    current_turn_id?: number
+  }[]
+  headless_session_count: number
+  running_conversation_id?: number
+}
+
 // Claude Config Selection for container creation
 export interface ClaudeConfigSelection {
   selected_claude_md?: number      // Single CLAUDE.MD template ID
@@ -234,6 +313,7 @@ async function parseConversationError(response: Response): Promise<string> {
 interface ConversationInfoPayload {
   id: number | string
   title?: string | null
+  tags?: string[] | null
   state?: string | null
   is_running?: boolean | null
   total_turns?: number | null
@@ -250,6 +330,7 @@ function parseConversationInfo(payload: ConversationInfoPayload): ConversationIn
   return {
     id: conversationId,
     title: typeof payload.title === 'string' ? payload.title : '',
+    tags: Array.isArray(payload.tags) ? payload.tags.filter((tag): tag is string => typeof tag === 'string') : [],
     state: typeof payload.state === 'string' ? payload.state : 'idle',
     is_running: Boolean(payload.is_running),
     total_turns: typeof payload.total_turns === 'number' ? payload.total_turns : 0,
@@ -258,12 +339,13 @@ function parseConversationInfo(payload: ConversationInfoPayload): ConversationIn
   }
 }
 
-export async function getContainerConversations(containerId: number): Promise<ConversationInfo[]> {
+export async function getContainerConversations(containerId: number, tag?: string): Promise<ConversationInfo[]> {
   const controller = new AbortController()
   const timeoutId = setTimeout(() => controller.abort(), CONVERSATION_REQUEST_TIMEOUT_MS)
+  const query = tag ? `?tag=${encodeURIComponent(tag)}` : ''
 
   try {
-    const response = await fetch(`${getBaseUrl()}/containers/${containerId}/headless/conversations`, {
+    const response = await fetch(`${getBaseUrl()}/containers/${containerId}/headless/conversations${query}`, {
       method: 'GET',
       credentials: 'include',
       signal: controller.signal,
@@ -311,6 +393,46 @@ export async function getContainerConversations(containerId: number): Promise<Co
   }
 }
 
+// Returns the most recently active conversation, or null when the container has none.
+// Uses fetch so a missing conversation doesn't raise the global "Not Found" toast.
+export async function getLatestConversation(containerId: number): Promise<ConversationInfo | null> {
+  const controller = new AbortController()
+  const timeoutId = setTimeout(() => controller.abort(), CONVERSATION_REQUEST_TIMEOUT_MS)
+
+  try {
+    const response = await fetch(`${getBaseUrl()}/containers/${containerId}/headless/conversations/latest`, {
+      method: 'GET',
+      credentials: 'include',
+      signal: controller.signal,
+      headers: {
+        Accept: 'application/json',
+      },
+    })
+
+    if (response.status === 404) {
+      return null
+    }
+    if (!response.ok) {
+      const errorMessage = await parseConversationError(response)
+      throw new Error(errorMessage || `Failed to fetch the latest conversation (${response.status})`)
+    }
+
+    return parseConversationInfo(await response.json() as ConversationInfoPayload)
+  } catch (error) {
+    const errorName = typeof error === 'object' && error !== null && 'name' in error
+      ? String((error as { name?: unknown }).name)
+      : ''
+
+    if (errorName === 'AbortError') {
+      throw new Error('Request timed out while fetching the latest conversation')
+    }
+
+    throw error instanceof Error ? error : new Error('Failed to fetch the latest conversation')
+  } finally {
+    clearTimeout(timeoutId)
+  }
+}
+
 export async function getTerminalSessions(containerId: number): Promise<TerminalSessionInfo[]> {
   const response = await api.get<TerminalSessionInfo[]>(`/terminals/${containerId}/sessions`)
   return response.data
@@ -373,8 +495,14 @@ export const containerApi = {
   deleteConversation: deleteContainerConversation,
   getLogs: (id: number, limit?: number) =>
     api.get(`/containers/${id}/logs`, { params: { limit: limit || 100 } }),
+  // Activity timeline, oldest first; type is a comma-separated list of event types
+  getTimeline: (id: number, params?: { type?: string; limit?: number; offset?: number }) =>
+    api.get(`/containers/${id}/timeline`, { params }),
   getApiConfig: (id: number) => api.get<{ api_url: string; api_token: string }>(`/containers/${id}/api-config`),
   getModels: (id: number) => api.get<{ data: Array<{ id: string; type?: string; created_at?: string }> }>(`/containers/${id}/models`),
+  // Dry-run the create checks; takes the same body as POST /containers
+  validate: (input: Record<string, unknown>) =>
+    api.post<{ valid: boolean; problems: Array<{ field: string; code: string; message: string }> }>('/containers/validate', input),
   create: (
     name: string,
     gitRepoUrl: string,
@@ -398,7 +526,16 @@ export const containerApi = {
     claudeConfigSelection?: ClaudeConfigSelection,
     autoInjectAllSkills?: boolean,
     // Permission option
-    runAsRoot?: boolean
+    runAsRoot?: boolean,
+    resourceProfile?: string,
+    restartPolicy?: { policy: 'no' | 'on-failure' | 'always'; maxRetries?: number },
+    initStrategy?: 'claude' | 'script' | 'both' | 'none',
+    runAsUser?: string,
+    ttlMinutes?: number,
+    codeServerAuth?: 'none' | 'password',
+    codeServerExtensions?: string[],
+    image?: string,
+    claudeVersion?: string
   ) =>
     api.post('/containers', {
       name,
@@ -414,12 +551,16 @@ export const containerApi = {
       port_mappings: portMappings || [],
       proxy: proxy || { enabled: false },
       enable_code_server: enableCodeServer || false,
+      code_server_auth: enableCodeServer ? codeServerAuth : undefined,
+      code_server_extensions: enableCodeServer && codeServerExtensions?.length ? codeServerExtensions : undefined,
       github_token_id: githubTokenId,
       env_vars_profile_id: envVarsProfileId,
       startup_command_profile_id: startupCommandProfileId,
       // New fields for claude config management
       skip_git_repo: skipGitRepo || false,
       enable_yolo_mode: enableYoloMode || false,
+      // Callers only enable YOLO mode after the user has acknowledged its risks
+      confirm_yolo: enableYoloMode || undefined,
       selected_claude_md: claudeConfigSelection?.selected_claude_md,
       selected_skills: claudeConfigSelection?.selected_skills || [],
       selected_mcps: claudeConfigSelection?.selected_mcps || [],
@@ -430,17 +571,67 @@ export const containerApi = {
       auto_inject_all_skills: autoInjectAllSkills ?? true,
       // Permission option
       run_as_root: runAsRoot || false,
+      run_as_user: runAsUser || undefined,
+      ttl_minutes: ttlMinutes || undefined,
+      resource_profile: resourceProfile || undefined,
+      restart_policy: restartPolicy?.policy,
+      restart_max_retries: restartPolicy?.maxRetries,
+      init_strategy: initStrategy,
+      image: image || undefined,
+      claude_version: claudeVersion || undefined,
     }),
+  listResourceProfiles: () => api.get<ResourceProfile[]>('/resource-profiles'),
   start: (id: number) => api.post(`/containers/${id}/start`),
   stop: (id: number) => api.post(`/containers/${id}/stop`),
-  delete: (id: number) => api.delete(`/containers/${id}`),
+  pause: (id: number) => api.post(`/containers/${id}/pause`),
+  resume: (id: number) => api.post(`/containers/${id}/resume`),
+  recreate: (id: number) => api.post(`/containers/${id}/recreate`, { confirm: true }),
+  cancelInit: (id: number) => api.post(`/containers/${id}/cancel-init`),
+  getCodeServerAccess: (id: number) => api.get<CodeServerAccess>(`/containers/${id}/code-server`),
+  rotateCodeServerPassword: (id: number) =>
+    api.post<CodeServerAccess>(`/containers/${id}/code-server/rotate-password`),
+  getMode: (id: number) => api.get<ContainerModeInventory>(`/containers/${id}/mode`),
+  resetMode: (id: number) => api.post(`/containers/${id}/mode/reset`),
+  getClaudeConfig: (id: number) => api.get(`/containers/${id}/claude-config`),
+  testMCPServer: (id: number, name: string, timeout?: number) =>
+    api.post<MCPServerTestResult>(`/containers/${id}/mcp/${encodeURIComponent(name)}/test`, null, { params: { timeout } }),
+  getClaudeVersion: (id: number) =>
+    api.get<{ installed: boolean; version?: string; pinned?: string; output?: string }>(`/containers/${id}/claude-version`),
+  downloadClaudeSnapshot: (id: number) => api.get(`/containers/${id}/claude-snapshot`, { responseType: 'blob' }),
+  restoreClaudeSnapshot: (id: number, archive: File, replace = false) => {
+    const formData = new FormData()
+    formData.append('file', archive)
+    return api.post(`/containers/${id}/claude-snapshot`, formData, {
+      params: replace ? { replace: true } : undefined,
+      headers: { 'Content-Type': 'multipart/form-data' },
+    })
+  },
+  updateResources: (id: number, resources: { memory_limit?: number; cpu_limit?: number; cpu_unlimited?: boolean }) =>
+    api.put(`/containers/${id}/resources`, resources),
+  updateDetails: (id: number, details: { notes?: string; metadata?: Record<string, string> }) =>
+    api.patch(`/containers/${id}`, details),
+  delete: (id: number, preserveWorkspace = false) =>
+    api.delete<{ message: string; archive?: WorkspaceArchive }>(`/containers/${id}`, {
+      params: preserveWorkspace ? { preserve_workspace: true } : undefined,
+    }),
+  restoreWorkspace: (id: number, archiveId: number) =>
+    api.post(`/containers/${id}/workspace/restore`, { archive_id: archiveId }),
+  listWorkspaceArchives: () => api.get<WorkspaceArchive[]>('/workspace-archives'),
+  downloadWorkspaceArchive: (archiveId: number) =>
+    api.get(`/workspace-archives/${archiveId}/download`, { responseType: 'blob' }),
+  deleteWorkspaceArchive: (archiveId: number) => api.delete(`/workspace-archives/${archiveId}`),
   injectConfigs: (id: number, templateIds: number[]) =>
     api.post(`/containers/${id}/inject-configs`, { template_ids: templateIds }),
+  diffTemplate: (id: number, templateId: number) =>
+    api.get<TemplateDiff>(`/containers/${id}/configs/${templateId}/diff`),
 }
 
 // Docker API
 export const dockerApi = {
-  listContainers: () => api.get<DockerContainerInfo[]>('/docker/containers'),
+  listContainers: (filter?: { managed?: boolean; state?: string; name?: string }) =>
+    api.get<DockerContainerInfo[]>('/docker/containers', { params: filter }),
+  pruneContainers: (dryRun = false) =>
+    api.post<PruneContainersResult>('/docker/containers/prune', dryRun ? { dry_run: true } : { confirm: true }),
   stopContainer: (dockerId: string) => api.post(`/docker/containers/${dockerId}/stop`),
   removeContainer: (dockerId: string) => api.delete(`/docker/containers/${dockerId}`),
 }
@@ -478,3 +669,9 @@ export const fileApi = {
   createDirectory: (containerId: number, path: string) =>
     api.post(`/files/${containerId}/mkdir`, { path }),
 }
+
+// Backup API (templates, bundles, command profiles and container specs; secrets excluded)
+export const backupApi = {
+  export: () => api.get('/export/backup', { responseType: 'blob' }),
+  import: (backup: unknown) => api.post('/import/backup', backup),
+}
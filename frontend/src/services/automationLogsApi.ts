@@ -28,6 +28,7 @@ export interface LogsFilter {
   containerId?: number
   strategy?: string
   result?: string
+  level?: 'info' | 'warn' | 'error'
   from?: number
   to?: number
   page?: number
@@ -55,6 +56,7 @@ export const automationLogsApi = {
     if (filter.containerId) params.append('container_id', filter.containerId.toString())
     if (filter.strategy) params.append('strategy', filter.strategy)
     if (filter.result) params.append('result', filter.result)
+    if (filter.level) params.append('level', filter.level)
     if (filter.from) params.append('from', filter.from.toString())
     if (filter.to) params.append('to', filter.to.toString())
     if (filter.page) params.append('page', filter.page.toString())
@@ -87,9 +89,19 @@ export const automationLogsApi = {
     return response.data
   },
 
-  exportLogs: async (filter: { containerId?: number; from?: number; to?: number } = {}): Promise<Blob> => {
+  exportLogs: async (
+    filter: {
+      containerId?: number
+      level?: 'info' | 'warn' | 'error'
+      from?: number
+      to?: number
+      format?: 'json' | 'csv'
+    } = {}
+  ): Promise<Blob> => {
     const params = new URLSearchParams()
     if (filter.containerId) params.append('container_id', filter.containerId.toString())
+    if (filter.level) params.append('level', filter.level)
+    if (filter.format) params.append('format', filter.format)
     if (filter.from) params.append('from', filter.from.toString())
     if (filter.to) params.append('to', filter.to.toString())
     const response = await api.get(`/logs/automation/export?${params.toString()}`, {
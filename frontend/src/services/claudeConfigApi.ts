@@ -9,6 +9,8 @@ import type {
   ClaudeConfigTemplate,
   CreateConfigInput,
   UpdateConfigInput,
+  TemplatePropagationResult,
+  ConfigTypeSchema,
 } from '@/types/claudeConfig'
 
 // API response types
@@ -90,6 +92,20 @@ export const claudeConfigApi = {
     }
     return api.delete(`/claude-configs/${id}`)
   },
+
+  /**
+   * Re-inject a template into every running container that uses it
+   * @param id - Template ID
+   * @returns Promise with per-container results keyed by container ID
+   */
+  propagate: (id: number) =>
+    api.post<{ results: Record<string, TemplatePropagationResult> }>(`/claude-configs/${id}/propagate`),
+
+  /**
+   * List every config type with its content format and validation hints
+   * @returns Promise with array of ConfigTypeSchema
+   */
+  listTypes: () => api.get<ConfigTypeSchema[]>('/claude-configs/types'),
 }
 
 export default claudeConfigApi
@@ -1,5 +1,5 @@
 import api from './api'
-import { normalizeTurnInfo, type TurnInfo } from '../types/headless'
+import { normalizeTurnInfo, type EventInfo, type TurnInfo } from '../types/headless'
 
 export type { TurnInfo } from '../types/headless'
 
@@ -11,6 +11,11 @@ export interface Conversation {
   session_id: string
   claude_session_id?: string
   title?: string
+  system_prompt?: string
+  extra_args?: string[]
+  max_turns?: number
+  max_duration_minutes?: number
+  tags?: string[]
   state: string
   is_running: boolean  // 后端会话是否正在运行
   total_turns: number
@@ -18,11 +23,72 @@ export interface Conversation {
   updated_at: string
 }
 
+// 模型定价（美元 / 百万 token），stream 未上报费用时后端按此计算
+export interface ModelPrice {
+  model: string
+  input_per_mtok: number
+  output_per_mtok: number
+}
+
+export interface ModelPricing {
+  unit: 'usd_per_million_tokens'
+  default_model: string
+  prices: ModelPrice[]
+}
+
+export interface PromptTemplate {
+  id: number
+  name: string
+  content: string
+  description?: string
+  tags?: string[]
+  variables: string[]
+  created_at: string
+  updated_at: string
+}
+
+export interface PromptTemplateInput {
+  name: string
+  content: string
+  description?: string
+  tags?: string[]
+}
+
+export interface ChangedFile {
+  path: string
+  status: 'added' | 'modified' | 'deleted' | 'untracked'
+  added: number
+  deleted: number
+  binary?: boolean
+}
+
+export interface ConversationChanges {
+  conversation_id: number
+  work_dir: string
+  is_git_repo: boolean
+  scope?: 'conversation' | 'uncommitted'  // conversation: 相对于对话开始时的基线提交
+  baseline_commit?: string
+  baseline_missing?: boolean
+  files: ChangedFile[]
+  total_added: number
+  total_deleted: number
+}
+
 export interface TurnsResponse {
   turns: TurnInfo[]
   has_more: boolean
 }
 
+// 轮次原始事件回放的一页
+export interface TurnEventsResponse {
+  turn_id: number
+  state: TurnInfo['state']
+  events_truncated: boolean
+  events_pruned: boolean
+  events: EventInfo[]
+  has_more: boolean
+}
+
 function normalizeTurnsResponse(data: TurnsResponse): TurnsResponse {
   return {
     ...data,
@@ -33,15 +99,49 @@ function normalizeTurnsResponse(data: TurnsResponse): TurnsResponse {
 // ==================== Headless API ====================
 
 export const headlessApi = {
-  listConversations: (containerId: number) =>
-    api.get<Conversation[]>(`/containers/${containerId}/headless/conversations`),
+  listConversations: (containerId: number, tag?: string) =>
+    api.get<Conversation[]>(`/containers/${containerId}/headless/conversations`, {
+      params: tag ? { tag } : undefined,
+    }),
+
+  listConversationTags: (containerId: number) =>
+    api.get<{ tags: string[] }>(`/containers/${containerId}/headless/conversations/tags`),
 
   getConversation: (containerId: number, conversationId: number) =>
     api.get<Conversation>(`/containers/${containerId}/headless/conversations/${conversationId}`),
 
+  updateSystemPrompt: (containerId: number, conversationId: number, systemPrompt: string) =>
+    api.put(`/containers/${containerId}/headless/conversations/${conversationId}/system-prompt`, {
+      system_prompt: systemPrompt,
+    }),
+
+  updateTags: (containerId: number, conversationId: number, tags: string[]) =>
+    api.put<{ conversation_id: number; tags: string[] }>(`/containers/${containerId}/headless/conversations/${conversationId}/tags`, {
+      tags,
+    }),
+
+  getConversationChanges: (containerId: number, conversationId: number) =>
+    api.get<ConversationChanges>(`/containers/${containerId}/headless/conversations/${conversationId}/changes`),
+
   deleteConversation: (containerId: number, conversationId: number) =>
     api.delete(`/containers/${containerId}/headless/conversations/${conversationId}`),
 
+  getModelPricing: () => api.get<ModelPricing>('/headless/pricing'),
+
+  listPromptTemplates: (tag?: string) =>
+    api.get<PromptTemplate[]>('/prompt-templates', { params: { tag } }),
+
+  createPromptTemplate: (data: PromptTemplateInput) =>
+    api.post<PromptTemplate>('/prompt-templates', data),
+
+  updatePromptTemplate: (id: number, data: PromptTemplateInput) =>
+    api.put<PromptTemplate>(`/prompt-templates/${id}`, data),
+
+  deletePromptTemplate: (id: number) => api.delete(`/prompt-templates/${id}`),
+
+  renderPromptTemplate: (id: number, variables: Record<string, string>) =>
+    api.post<{ prompt: string }>(`/prompt-templates/${id}/render`, { variables }),
+
   getConversationTurns: (containerId: number, conversationId: number, limit?: number, before?: number) =>
     api.get<TurnsResponse>(`/containers/${containerId}/headless/conversations/${conversationId}/turns`, {
       params: { limit, before },
@@ -49,6 +149,12 @@ export const headlessApi = {
       ...response,
       data: normalizeTurnsResponse(response.data),
     })),
+
+  // after 为上一页最后一个事件的 event_index
+  getTurnEvents: (containerId: number, conversationId: number, turnId: number, after?: number, limit?: number) =>
+    api.get<TurnEventsResponse>(`/containers/${containerId}/headless/conversations/${conversationId}/turns/${turnId}/events`, {
+      params: { after, limit },
+    }),
 }
 
 export default headlessApi
@@ -44,6 +44,10 @@ export class HeadlessWebSocketService {
   private reconnectTimer: ReturnType<typeof setTimeout> | null = null;
   private pingInterval: ReturnType<typeof setInterval> | null = null;
   private isClosing = false;
+  // 断线重连：当前会话及已收到的事件序号，重连时只补发 last_seq 之后的事件
+  private sessionId: string | null = null;
+  private lastSeq = 0;
+  private seenSeqs = new Set<number>();
 
   constructor(options: { containerId: number } | { conversationId: number }) {
     if ('containerId' in options) {
@@ -86,10 +90,16 @@ export class HeadlessWebSocketService {
       }
 
       // Try to attach token if cookie is readable (same-origin non-httpOnly)
+      const params = new URLSearchParams();
       const token = getCookie('cc_token');
       if (token) {
-        const params = new URLSearchParams();
         params.set('token', token);
+      }
+      if (this.sessionId && this.lastSeq > 0) {
+        params.set('session_id', this.sessionId);
+        params.set('last_seq', String(this.lastSeq));
+      }
+      if (params.toString()) {
         url += `?${params.toString()}`;
       }
 
@@ -181,10 +191,20 @@ export class HeadlessWebSocketService {
   }
 
   // 创建会话
-  startSession(workDir?: string, forceNew?: boolean): void {
+  startSession(
+    workDir?: string,
+    forceNew?: boolean,
+    systemPrompt?: string,
+    extraArgs?: string[],
+    limits?: { maxTurns?: number; maxDurationMinutes?: number },
+  ): void {
     const payload: Record<string, unknown> = {};
     if (workDir) payload.work_dir = workDir;
     if (forceNew) payload.force_new = true;
+    if (systemPrompt !== undefined) payload.system_prompt = systemPrompt;
+    if (extraArgs !== undefined) payload.extra_args = extraArgs;
+    if (limits?.maxTurns !== undefined) payload.max_turns = limits.maxTurns;
+    if (limits?.maxDurationMinutes !== undefined) payload.max_duration_minutes = limits.maxDurationMinutes;
     this.send({
       type: 'headless_start',
       payload,
@@ -192,8 +212,27 @@ export class HeadlessWebSocketService {
   }
 
   // 发送 prompt
-  sendPrompt(prompt: string, source: string = 'user', model?: string): void {
-    const payload: { prompt: string; source: string; model?: string } = { prompt, source };
+  sendPrompt(prompt: string, source: string = 'user', model?: string, filePaths?: string[]): void {
+    const payload: { prompt: string; source: string; model?: string; file_paths?: string[] } = { prompt, source };
+    if (model) {
+      payload.model = model;
+    }
+    if (filePaths && filePaths.length > 0) {
+      payload.file_paths = filePaths;
+    }
+    this.send({
+      type: 'headless_prompt',
+      payload,
+    });
+  }
+
+  // 发送 prompt 模板，由服务端用 variables 渲染
+  sendTemplatePrompt(templateId: number, variables: Record<string, string>, source: string = 'user', model?: string): void {
+    const payload: { template_id: number; variables: Record<string, string>; source: string; model?: string } = {
+      template_id: templateId,
+      variables,
+      source,
+    };
     if (model) {
       payload.model = model;
     }
@@ -211,6 +250,14 @@ export class HeadlessWebSocketService {
     });
   }
 
+  // 取消指定轮次（仅当该轮次正在执行时生效）
+  cancelTurn(turnId: number): void {
+    this.send({
+      type: 'cancel_turn',
+      payload: { turn_id: turnId },
+    });
+  }
+
   // 停止整个会话
   stopSession(sessionId?: string): void {
     const payload: Record<string, unknown> = {};
@@ -256,6 +303,22 @@ export class HeadlessWebSocketService {
     });
   }
 
+  // 重置 Claude 上下文（保留对话历史）
+  resetContext(): void {
+    this.send({
+      type: 'reset_context',
+      payload: {},
+    });
+  }
+
+  // 压缩 Claude 上下文（以摘要替换之前的上下文，会话忙时排队）
+  compact(): void {
+    this.send({
+      type: 'compact',
+      payload: {},
+    });
+  }
+
   // 检查连接状态
   isConnected(): boolean {
     return this.ws?.readyState === WebSocket.OPEN;
@@ -263,6 +326,25 @@ export class HeadlessWebSocketService {
 
   // 处理消息
   private handleMessage(response: HeadlessResponse): void {
+    if (response.type === 'session_info') {
+      const sessionId = (response.payload as { session_id?: string })?.session_id || null;
+      if (sessionId !== this.sessionId) {
+        this.sessionId = sessionId;
+        this.lastSeq = 0;
+        this.seenSeqs.clear();
+      }
+    }
+    // 完整历史加载时，补发的事件可能与订阅后收到的实时事件重复
+    if (response.seq) {
+      if (this.seenSeqs.has(response.seq)) {
+        return;
+      }
+      this.seenSeqs.add(response.seq);
+      if (this.seenSeqs.size > 5000) {
+        this.seenSeqs.delete(this.seenSeqs.values().next().value as number);
+      }
+      this.lastSeq = Math.max(this.lastSeq, response.seq);
+    }
     if (this.messageHandler) {
       this.messageHandler(response.type, response.payload);
     }
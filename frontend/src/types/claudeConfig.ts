@@ -29,6 +29,8 @@ export interface ClaudeConfigTemplate {
   // For archive-based skills (multi-file skills with folder structure)
   is_archive?: boolean
   archive_data?: string // Base64-encoded zip file
+  // Supporting files of a single-file skill, keyed by path relative to the skill directory
+  files?: Record<string, string>
 }
 
 // Input type for creating a new config template
@@ -40,6 +42,7 @@ export interface CreateConfigInput {
   // For archive-based skills
   is_archive?: boolean
   archive_data?: string // Base64-encoded zip file
+  files?: Record<string, string>
 }
 
 // Input type for updating an existing config template
@@ -48,6 +51,7 @@ export interface UpdateConfigInput {
   config_type?: ConfigType
   content?: string
   description?: string
+  files?: Record<string, string> // Replaces the skill's supporting files; {} removes them
 }
 
 // FailedTemplate represents a template that failed to inject
@@ -61,11 +65,41 @@ export interface FailedTemplate {
 export interface InjectionStatus {
   container_id: string
   successful: string[]
+  successful_ids?: number[]
   failed: FailedTemplate[]
   warnings: string[]
   injected_at: string
 }
 
+// TemplatePropagationResult is the outcome of re-injecting a template into one container
+export interface TemplatePropagationResult {
+  container_name: string
+  status: 'injected' | 'failed' | 'skipped'
+  message?: string
+}
+
+// TemplateDiff previews what re-injecting a template would change in a container
+export interface TemplateDiff {
+  template_id: number
+  template_name: string
+  config_type: ConfigType
+  path: string
+  exists: boolean
+  changed: boolean
+  diff: string
+}
+
+// ConfigTypeSchema describes a config type's content format and server-side validation
+export interface ConfigTypeSchema {
+  type: ConfigType
+  label: string
+  format: 'markdown' | 'yaml-frontmatter' | 'json' | 'toml' | 'env'
+  required_fields?: string[]
+  optional_fields?: string[]
+  supports_archive: boolean
+  hints: string[]
+}
+
 // SkillMetadata parsed from Markdown frontmatter (runtime only)
 export interface SkillMetadata {
   allowed_tools?: string[]
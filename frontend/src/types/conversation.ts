@@ -1,6 +1,7 @@
 export interface ConversationInfo {
   id: number
   title?: string
+  tags: string[]
   state: string
   is_running: boolean
   total_turns: number
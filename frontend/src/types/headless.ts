@@ -56,17 +56,24 @@ export interface TurnInfo {
   turn_id?: number;
   turn_index: number;
   user_prompt: string;
-  prompt_source: 'user' | 'strategy' | 'monitoring';
+  prompt_source: 'user' | 'strategy' | 'monitoring' | 'compact';
+  attached_files?: string[];
   assistant_response?: AssistantResponse;
   model?: string;
   input_tokens: number;
   output_tokens: number;
   cost_usd: number;
+  cost_source?: 'reported' | 'computed';
   duration_ms: number;
   state: 'pending' | 'running' | 'completed' | 'error';
   error_message?: string;
   created_at: string;
   completed_at?: string;
+  context_reset_after?: boolean;
+  context_tokens_before?: number; // 压缩轮次：压缩前的上下文 token 数
+  context_tokens_after?: number; // 压缩轮次：压缩后（摘要）的 token 数
+  events_truncated?: boolean; // 达到每轮事件上限，之后的事件未保存
+  events_pruned?: boolean; // 事件已超过保留期被清理
   events?: EventInfo[];
 }
 
@@ -206,6 +213,9 @@ export interface SessionInfo {
   state: HeadlessState;
   conversation_id: number;
   current_turn_id?: number;
+  compact_supported?: boolean; // 当前模型是否支持上下文压缩
+  last_seq?: number; // 已广播的最后一个事件序号
+  extra_args?: string[]; // 透传给 Claude CLI 的额外参数
 }
 
 // 历史记录负载
@@ -222,6 +232,7 @@ export interface TurnCompletePayload {
   input_tokens: number;
   output_tokens: number;
   cost_usd: number;
+  cost_source?: 'reported' | 'computed';
   duration_ms: number;
   state: string;
   error_message?: string;
@@ -245,6 +256,7 @@ export interface QueuedTurnInfo {
   turn_index: number;
   prompt: string;
   source: string;
+  attached_files?: string[];
   state: 'pending' | 'running';
 }
 
@@ -253,16 +265,36 @@ export interface QueueUpdatePayload {
   queued_turns: QueuedTurnInfo[];
 }
 
+// Claude 进程意外终止的通知
+export interface SessionDeadPayload {
+  session_id: string;
+  turn_id?: number;
+  reason: string;
+  detected_at: string;
+}
+
+// 对话达到停止条件、会话自动关闭的通知
+export interface SessionStoppedPayload {
+  session_id: string;
+  conversation_id: number;
+  reason: 'max_turns' | 'max_duration';
+  message: string;
+  stopped_at: string;
+}
+
 // WebSocket 请求类型
 export type HeadlessRequestType =
   | 'headless_start'
   | 'headless_prompt'
   | 'headless_cancel'
+  | 'cancel_turn'
   | 'headless_stop_session'
   | 'load_more'
   | 'mode_switch'
   | 'delete_queued'
   | 'edit_queued'
+  | 'reset_context'
+  | 'compact'
   | 'ping';
 
 // WebSocket 响应类型
@@ -277,7 +309,28 @@ export type HeadlessResponseType =
   | 'mode_switched'
   | 'pty_closed'
   | 'pong'
-  | 'queue_update';
+  | 'queue_update'
+  | 'cancel_turn_result'
+  | 'context_reset'
+  | 'session_dead'
+  | 'session_stopped';
+
+// 取消指定轮次的结果
+export interface CancelTurnResultPayload {
+  turn_id: number;
+  cancelled: boolean;
+  current_turn_id?: number;
+  reason?: 'not_running' | 'turn_mismatch';
+}
+
+// 上下文重置结果，after_turn_id 为边界前的最后一轮
+export interface ContextResetPayload {
+  conversation_id: number;
+  after_turn_id?: number;
+  after_turn_index: number;
+  cancelled: boolean;
+  reset_at: string;
+}
 
 // WebSocket 请求
 export interface HeadlessRequest {
@@ -289,6 +342,7 @@ export interface HeadlessRequest {
 export interface HeadlessResponse {
   type: HeadlessResponseType;
   payload: unknown;
+  seq?: number; // 会话事件序号，重连时作为 last_seq 传回
 }
 
 // 前端状态
@@ -1,21 +1,40 @@
+/** Error envelope returned by the API: { error: { code, message, details } } */
+export interface ApiErrorBody {
+  code: string
+  message: string
+  details?: Record<string, unknown>
+}
+
 interface ApiError {
   response?: {
     status?: number
     data?: {
-      error?: string
+      error?: string | ApiErrorBody
       message?: string
     }
   }
   message?: string
 }
 
+/** Message of an error response, for both the error envelope and plain { error: "..." } bodies */
+export function apiErrorMessage(data?: { error?: string | ApiErrorBody; message?: string }): string | undefined {
+  const error = data?.error
+  if (typeof error === 'string') return error || undefined
+  return error?.message || data?.message
+}
+
+/** Stable code of an error response, when the endpoint uses the error envelope */
+export function apiErrorCode(data?: { error?: string | ApiErrorBody }): string | undefined {
+  const error = data?.error
+  return typeof error === 'object' && error ? error.code : undefined
+}
+
 export function handleApiError(error: unknown, defaultMessage = 'An error occurred'): string {
   const apiError = error as ApiError
   
   // Get error message from response
   const errorMessage = 
-    apiError.response?.data?.error ||
-    apiError.response?.data?.message ||
+    apiErrorMessage(apiError.response?.data) ||
     apiError.message ||
     defaultMessage
 